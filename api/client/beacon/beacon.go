@@ -0,0 +1,359 @@
+package beacon
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/theQRL/qrysm/v4/api/client"
+	"github.com/theQRL/qrysm/v4/beacon-chain/rpc/apimiddleware"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+)
+
+const (
+	getGenesisPath             = "/eth/v1/beacon/genesis"
+	getStateRootPath           = "/eth/v1/beacon/states/{{.Id}}/root"
+	getFinalityCheckpointsPath = "/eth/v1/beacon/states/{{.Id}}/finality_checkpoints"
+	getValidatorsPath          = "/eth/v1/beacon/states/{{.Id}}/validators"
+	getValidatorBalancesPath   = "/eth/v1/beacon/states/{{.Id}}/validator_balances"
+	getCommitteesPath          = "/eth/v1/beacon/states/{{.Id}}/committees"
+	getSyncCommitteesPath      = "/eth/v1/beacon/states/{{.Id}}/sync_committees"
+	getRandaoPath              = "/eth/v1/beacon/states/{{.Id}}/randao"
+	getBlockHeadersPath        = "/eth/v1/beacon/headers"
+	getBlockHeaderPath         = "/eth/v1/beacon/headers/{{.Id}}"
+	getBlindedBlockPath        = "/eth/v1/beacon/blinded_blocks/{{.Id}}"
+	getBlockAttestationsPath   = "/eth/v1/beacon/blocks/{{.Id}}/attestations"
+	publishBlockPath           = "/eth/v1/beacon/blocks"
+	publishBlindedBlockPath    = "/eth/v1/beacon/blinded_blocks"
+	attestationsPoolPath       = "/eth/v1/beacon/pool/attestations"
+	attesterSlashingsPoolPath  = "/eth/v1/beacon/pool/attester_slashings"
+	proposerSlashingsPoolPath  = "/eth/v1/beacon/pool/proposer_slashings"
+	voluntaryExitsPoolPath     = "/eth/v1/beacon/pool/voluntary_exits"
+	syncCommitteesPoolPath     = "/eth/v1/beacon/pool/sync_committees"
+
+	octetStreamMediaType = "application/octet-stream"
+	versionHeader        = "Eth-Consensus-Version"
+)
+
+var (
+	getStateRootTpl           = idTemplate(getStateRootPath)
+	getFinalityCheckpointsTpl = idTemplate(getFinalityCheckpointsPath)
+	getValidatorsTpl          = idTemplate(getValidatorsPath)
+	getValidatorBalancesTpl   = idTemplate(getValidatorBalancesPath)
+	getCommitteesTpl          = idTemplate(getCommitteesPath)
+	getSyncCommitteesTpl      = idTemplate(getSyncCommitteesPath)
+	getRandaoTpl              = idTemplate(getRandaoPath)
+	getBlockHeaderTpl         = idTemplate(getBlockHeaderPath)
+	getBlindedBlockTpl        = idTemplate(getBlindedBlockPath)
+	getBlockAttestationsTpl   = idTemplate(getBlockAttestationsPath)
+)
+
+// SSZResponse is an ssz-encoded API response, along with the consensus version of the encoded value.
+type SSZResponse struct {
+	Version string
+	Data    []byte
+}
+
+// getSSZ requests the ssz encoding of the value at the path, and reads its consensus version from the response headers.
+func (c *Client) getSSZ(ctx context.Context, p string, opts ...client.ReqOption) (*SSZResponse, error) {
+	resp, err := c.Send(ctx, http.MethodGet, p, nil, append(opts, client.WithSSZEncoding())...)
+	if err != nil {
+		return nil, err
+	}
+	return &SSZResponse{Version: resp.Header.Get(versionHeader), Data: resp.Body}, nil
+}
+
+// postSSZ posts an ssz-encoded value of the given consensus version to the path.
+func (c *Client) postSSZ(ctx context.Context, p string, ver string, ssz []byte) error {
+	opts := []client.ReqOption{client.WithContentType(octetStreamMediaType)}
+	if ver != "" {
+		opts = append(opts, client.WithHeader(versionHeader, ver))
+	}
+	_, err := c.Send(ctx, http.MethodPost, p, ssz, opts...)
+	return err
+}
+
+// GetGenesis retrieves the genesis time, genesis validators root and genesis fork version of the chain.
+func (c *Client) GetGenesis(ctx context.Context) (*apimiddleware.GenesisResponseJson, error) {
+	resp := &apimiddleware.GenesisResponseJson{}
+	if err := c.getJSON(ctx, getGenesisPath, resp); err != nil {
+		return nil, errors.Wrap(err, "error requesting genesis")
+	}
+	return resp, nil
+}
+
+// GetStateRoot retrieves the hash_tree_root of the BeaconState identified by stateId.
+func (c *Client) GetStateRoot(ctx context.Context, stateId StateOrBlockId) (*apimiddleware.StateRootResponseJson, error) {
+	resp := &apimiddleware.StateRootResponseJson{}
+	if err := c.getJSON(ctx, getStateRootTpl(stateId), resp); err != nil {
+		return nil, errors.Wrapf(err, "error requesting state root by id = %s", stateId)
+	}
+	return resp, nil
+}
+
+// GetFinalityCheckpoints retrieves the finality checkpoints of the BeaconState identified by stateId.
+func (c *Client) GetFinalityCheckpoints(ctx context.Context, stateId StateOrBlockId) (*apimiddleware.StateFinalityCheckpointResponseJson, error) {
+	resp := &apimiddleware.StateFinalityCheckpointResponseJson{}
+	if err := c.getJSON(ctx, getFinalityCheckpointsTpl(stateId), resp); err != nil {
+		return nil, errors.Wrapf(err, "error requesting finality checkpoints by state id = %s", stateId)
+	}
+	return resp, nil
+}
+
+// GetValidators retrieves the validators of the BeaconState identified by stateId. Validators can be filtered
+// by index or hex encoded public key with ids, and by status with statuses. Empty filters match every validator.
+func (c *Client) GetValidators(ctx context.Context, stateId StateOrBlockId, ids []string, statuses []string) (*apimiddleware.StateValidatorsResponseJson, error) {
+	query := url.Values{}
+	for _, id := range ids {
+		query.Add("id", id)
+	}
+	for _, status := range statuses {
+		query.Add("status", status)
+	}
+	resp := &apimiddleware.StateValidatorsResponseJson{}
+	if err := c.getJSON(ctx, getValidatorsTpl(stateId), resp, client.WithQuery(query)); err != nil {
+		return nil, errors.Wrapf(err, "error requesting validators by state id = %s", stateId)
+	}
+	return resp, nil
+}
+
+// GetValidator retrieves the validator identified by its index or hex encoded public key from the BeaconState
+// identified by stateId.
+func (c *Client) GetValidator(ctx context.Context, stateId StateOrBlockId, validatorId string) (*apimiddleware.StateValidatorResponseJson, error) {
+	resp := &apimiddleware.StateValidatorResponseJson{}
+	p := getValidatorsTpl(stateId) + "/" + url.PathEscape(validatorId)
+	if err := c.getJSON(ctx, p, resp); err != nil {
+		return nil, errors.Wrapf(err, "error requesting validator %s by state id = %s", validatorId, stateId)
+	}
+	return resp, nil
+}
+
+// GetValidatorBalances retrieves the balances of the validators of the BeaconState identified by stateId. Validators
+// can be filtered by index or hex encoded public key with ids. An empty filter matches every validator.
+func (c *Client) GetValidatorBalances(ctx context.Context, stateId StateOrBlockId, ids []string) (*apimiddleware.ValidatorBalancesResponseJson, error) {
+	query := url.Values{}
+	for _, id := range ids {
+		query.Add("id", id)
+	}
+	resp := &apimiddleware.ValidatorBalancesResponseJson{}
+	if err := c.getJSON(ctx, getValidatorBalancesTpl(stateId), resp, client.WithQuery(query)); err != nil {
+		return nil, errors.Wrapf(err, "error requesting validator balances by state id = %s", stateId)
+	}
+	return resp, nil
+}
+
+// GetCommittees retrieves the committees of the BeaconState identified by stateId. Committees can be filtered
+// by epoch, committee index and slot. Nil filters match every committee.
+func (c *Client) GetCommittees(ctx context.Context, stateId StateOrBlockId, epoch *primitives.Epoch, index *primitives.CommitteeIndex, slot *primitives.Slot) (*apimiddleware.StateCommitteesResponseJson, error) {
+	query := url.Values{}
+	if epoch != nil {
+		query.Set("epoch", strconv.FormatUint(uint64(*epoch), 10))
+	}
+	if index != nil {
+		query.Set("index", strconv.FormatUint(uint64(*index), 10))
+	}
+	if slot != nil {
+		query.Set("slot", strconv.FormatUint(uint64(*slot), 10))
+	}
+	resp := &apimiddleware.StateCommitteesResponseJson{}
+	if err := c.getJSON(ctx, getCommitteesTpl(stateId), resp, client.WithQuery(query)); err != nil {
+		return nil, errors.Wrapf(err, "error requesting committees by state id = %s", stateId)
+	}
+	return resp, nil
+}
+
+// GetSyncCommittees retrieves the sync committee of the given epoch, or of the epoch of the BeaconState identified
+// by stateId if epoch is nil.
+func (c *Client) GetSyncCommittees(ctx context.Context, stateId StateOrBlockId, epoch *primitives.Epoch) (*apimiddleware.SyncCommitteesResponseJson, error) {
+	resp := &apimiddleware.SyncCommitteesResponseJson{}
+	if err := c.getJSON(ctx, getSyncCommitteesTpl(stateId), resp, client.WithQuery(epochQuery(epoch))); err != nil {
+		return nil, errors.Wrapf(err, "error requesting sync committees by state id = %s", stateId)
+	}
+	return resp, nil
+}
+
+// GetRandao retrieves the RANDAO mix of the given epoch, or of the epoch of the BeaconState identified by stateId
+// if epoch is nil.
+func (c *Client) GetRandao(ctx context.Context, stateId StateOrBlockId, epoch *primitives.Epoch) (*apimiddleware.RandaoResponseJson, error) {
+	resp := &apimiddleware.RandaoResponseJson{}
+	if err := c.getJSON(ctx, getRandaoTpl(stateId), resp, client.WithQuery(epochQuery(epoch))); err != nil {
+		return nil, errors.Wrapf(err, "error requesting randao by state id = %s", stateId)
+	}
+	return resp, nil
+}
+
+func epochQuery(epoch *primitives.Epoch) url.Values {
+	query := url.Values{}
+	if epoch != nil {
+		query.Set("epoch", strconv.FormatUint(uint64(*epoch), 10))
+	}
+	return query
+}
+
+// GetBlockHeaders retrieves the block headers matching the slot and parent root filters, or the canonical head
+// header if both filters are nil.
+func (c *Client) GetBlockHeaders(ctx context.Context, slot *primitives.Slot, parentRoot *[32]byte) (*apimiddleware.BlockHeadersResponseJson, error) {
+	query := url.Values{}
+	if slot != nil {
+		query.Set("slot", strconv.FormatUint(uint64(*slot), 10))
+	}
+	if parentRoot != nil {
+		query.Set("parent_root", fmt.Sprintf("%#x", *parentRoot))
+	}
+	resp := &apimiddleware.BlockHeadersResponseJson{}
+	if err := c.getJSON(ctx, getBlockHeadersPath, resp, client.WithQuery(query)); err != nil {
+		return nil, errors.Wrap(err, "error requesting block headers")
+	}
+	return resp, nil
+}
+
+// GetBlockHeader retrieves the header of the block identified by blockId.
+func (c *Client) GetBlockHeader(ctx context.Context, blockId StateOrBlockId) (*apimiddleware.BlockHeaderResponseJson, error) {
+	resp := &apimiddleware.BlockHeaderResponseJson{}
+	if err := c.getJSON(ctx, getBlockHeaderTpl(blockId), resp); err != nil {
+		return nil, errors.Wrapf(err, "error requesting block header by id = %s", blockId)
+	}
+	return resp, nil
+}
+
+// GetBlockV2 retrieves the JSON encoding of the SignedBeaconBlock identified by blockId.
+// The version of the response tells which fork the block container of the data belongs to.
+func (c *Client) GetBlockV2(ctx context.Context, blockId StateOrBlockId) (*apimiddleware.BlockV2ResponseJson, error) {
+	resp := &apimiddleware.BlockV2ResponseJson{}
+	if err := c.getJSON(ctx, renderGetBlockPath(blockId), resp); err != nil {
+		return nil, errors.Wrapf(err, "error requesting block by id = %s", blockId)
+	}
+	return resp, nil
+}
+
+// GetBlockSSZ retrieves the ssz encoding of the SignedBeaconBlock identified by blockId, along with its version.
+func (c *Client) GetBlockSSZ(ctx context.Context, blockId StateOrBlockId) (*SSZResponse, error) {
+	resp, err := c.getSSZ(ctx, renderGetBlockPath(blockId))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error requesting block by id = %s", blockId)
+	}
+	return resp, nil
+}
+
+// GetBlindedBlock retrieves the JSON encoding of the blinded SignedBeaconBlock identified by blockId.
+func (c *Client) GetBlindedBlock(ctx context.Context, blockId StateOrBlockId) (*apimiddleware.BlindedBlockResponseJson, error) {
+	resp := &apimiddleware.BlindedBlockResponseJson{}
+	if err := c.getJSON(ctx, getBlindedBlockTpl(blockId), resp); err != nil {
+		return nil, errors.Wrapf(err, "error requesting blinded block by id = %s", blockId)
+	}
+	return resp, nil
+}
+
+// GetBlindedBlockSSZ retrieves the ssz encoding of the blinded SignedBeaconBlock identified by blockId, along with
+// its version.
+func (c *Client) GetBlindedBlockSSZ(ctx context.Context, blockId StateOrBlockId) (*SSZResponse, error) {
+	resp, err := c.getSSZ(ctx, getBlindedBlockTpl(blockId))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error requesting blinded block by id = %s", blockId)
+	}
+	return resp, nil
+}
+
+// GetBlockAttestations retrieves the attestations included in the block identified by blockId.
+func (c *Client) GetBlockAttestations(ctx context.Context, blockId StateOrBlockId) (*apimiddleware.BlockAttestationsResponseJson, error) {
+	resp := &apimiddleware.BlockAttestationsResponseJson{}
+	if err := c.getJSON(ctx, getBlockAttestationsTpl(blockId), resp); err != nil {
+		return nil, errors.Wrapf(err, "error requesting block attestations by id = %s", blockId)
+	}
+	return resp, nil
+}
+
+// PublishBlock submits a signed block to the beacon node for broadcast. The block must be one of the signed
+// block containers of the apimiddleware package, such as SignedBeaconBlockCapellaContainerJson.
+func (c *Client) PublishBlock(ctx context.Context, block interface{}) error {
+	return errors.Wrap(c.postJSON(ctx, publishBlockPath, block, nil), "error publishing block")
+}
+
+// PublishBlockSSZ submits an ssz-encoded signed block of the given consensus version to the beacon node for broadcast.
+func (c *Client) PublishBlockSSZ(ctx context.Context, ver string, ssz []byte) error {
+	return errors.Wrap(c.postSSZ(ctx, publishBlockPath, ver, ssz), "error publishing block")
+}
+
+// PublishBlindedBlock submits a signed blinded block to the beacon node, which retrieves the execution payload
+// from the builder and broadcasts the block. The block must be one of the signed blinded block containers of
+// the apimiddleware package, such as SignedBlindedBeaconBlockCapellaContainerJson.
+func (c *Client) PublishBlindedBlock(ctx context.Context, block interface{}) error {
+	return errors.Wrap(c.postJSON(ctx, publishBlindedBlockPath, block, nil), "error publishing blinded block")
+}
+
+// PublishBlindedBlockSSZ submits an ssz-encoded signed blinded block of the given consensus version to the beacon node.
+func (c *Client) PublishBlindedBlockSSZ(ctx context.Context, ver string, ssz []byte) error {
+	return errors.Wrap(c.postSSZ(ctx, publishBlindedBlockPath, ver, ssz), "error publishing blinded block")
+}
+
+// GetAttestationsPool retrieves the attestations known by the beacon node but not yet included in a block.
+// Attestations can be filtered by slot and committee index. Nil filters match every attestation.
+func (c *Client) GetAttestationsPool(ctx context.Context, slot *primitives.Slot, committeeIndex *primitives.CommitteeIndex) (*apimiddleware.AttestationsPoolResponseJson, error) {
+	query := url.Values{}
+	if slot != nil {
+		query.Set("slot", strconv.FormatUint(uint64(*slot), 10))
+	}
+	if committeeIndex != nil {
+		query.Set("committee_index", strconv.FormatUint(uint64(*committeeIndex), 10))
+	}
+	resp := &apimiddleware.AttestationsPoolResponseJson{}
+	if err := c.getJSON(ctx, attestationsPoolPath, resp, client.WithQuery(query)); err != nil {
+		return nil, errors.Wrap(err, "error requesting attestations pool")
+	}
+	return resp, nil
+}
+
+// SubmitAttestations submits attestations to the beacon node for inclusion in the pool and broadcast.
+func (c *Client) SubmitAttestations(ctx context.Context, atts []*apimiddleware.AttestationJson) error {
+	return errors.Wrap(c.postJSON(ctx, attestationsPoolPath, atts, nil), "error submitting attestations")
+}
+
+// GetAttesterSlashingsPool retrieves the attester slashings known by the beacon node but not yet included in a block.
+func (c *Client) GetAttesterSlashingsPool(ctx context.Context) (*apimiddleware.AttesterSlashingsPoolResponseJson, error) {
+	resp := &apimiddleware.AttesterSlashingsPoolResponseJson{}
+	if err := c.getJSON(ctx, attesterSlashingsPoolPath, resp); err != nil {
+		return nil, errors.Wrap(err, "error requesting attester slashings pool")
+	}
+	return resp, nil
+}
+
+// SubmitAttesterSlashing submits an attester slashing to the beacon node for inclusion in the pool and broadcast.
+func (c *Client) SubmitAttesterSlashing(ctx context.Context, slashing *apimiddleware.AttesterSlashingJson) error {
+	return errors.Wrap(c.postJSON(ctx, attesterSlashingsPoolPath, slashing, nil), "error submitting attester slashing")
+}
+
+// GetProposerSlashingsPool retrieves the proposer slashings known by the beacon node but not yet included in a block.
+func (c *Client) GetProposerSlashingsPool(ctx context.Context) (*apimiddleware.ProposerSlashingsPoolResponseJson, error) {
+	resp := &apimiddleware.ProposerSlashingsPoolResponseJson{}
+	if err := c.getJSON(ctx, proposerSlashingsPoolPath, resp); err != nil {
+		return nil, errors.Wrap(err, "error requesting proposer slashings pool")
+	}
+	return resp, nil
+}
+
+// SubmitProposerSlashing submits a proposer slashing to the beacon node for inclusion in the pool and broadcast.
+func (c *Client) SubmitProposerSlashing(ctx context.Context, slashing *apimiddleware.ProposerSlashingJson) error {
+	return errors.Wrap(c.postJSON(ctx, proposerSlashingsPoolPath, slashing, nil), "error submitting proposer slashing")
+}
+
+// GetVoluntaryExitsPool retrieves the voluntary exits known by the beacon node but not yet included in a block.
+func (c *Client) GetVoluntaryExitsPool(ctx context.Context) (*apimiddleware.VoluntaryExitsPoolResponseJson, error) {
+	resp := &apimiddleware.VoluntaryExitsPoolResponseJson{}
+	if err := c.getJSON(ctx, voluntaryExitsPoolPath, resp); err != nil {
+		return nil, errors.Wrap(err, "error requesting voluntary exits pool")
+	}
+	return resp, nil
+}
+
+// SubmitVoluntaryExit submits a voluntary exit to the beacon node for inclusion in the pool and broadcast.
+func (c *Client) SubmitVoluntaryExit(ctx context.Context, exit *apimiddleware.SignedVoluntaryExitJson) error {
+	return errors.Wrap(c.postJSON(ctx, voluntaryExitsPoolPath, exit, nil), "error submitting voluntary exit")
+}
+
+// SubmitSyncCommitteeMessages submits sync committee messages to the beacon node for broadcast.
+func (c *Client) SubmitSyncCommitteeMessages(ctx context.Context, msgs []*apimiddleware.SyncCommitteeMessageJson) error {
+	return errors.Wrap(c.postJSON(ctx, syncCommitteesPoolPath, msgs, nil), "error submitting sync committee messages")
+}
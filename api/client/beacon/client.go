@@ -17,6 +17,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/theQRL/go-zond/common/hexutil"
 	"github.com/theQRL/qrysm/v4/api/client"
+	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositsnapshot"
 	"github.com/theQRL/qrysm/v4/beacon-chain/rpc/apimiddleware"
 	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
 	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
@@ -30,8 +31,10 @@ const (
 	getBlockRootPath               = "/eth/v1/beacon/blocks/{{.Id}}/root"
 	getForkForStatePath            = "/eth/v1/beacon/states/{{.Id}}/fork"
 	getWeakSubjectivityPath        = "/eth/v1/beacon/weak_subjectivity"
+	getDepositSnapshotPath         = "/eth/v1/beacon/deposit_snapshot"
 	getForkSchedulePath            = "/eth/v1/config/fork_schedule"
 	getConfigSpecPath              = "/eth/v1/config/spec"
+	getDepositContractPath         = "/eth/v1/config/deposit_contract"
 	getStatePath                   = "/eth/v2/debug/beacon/states"
 	getNodeVersionPath             = "/eth/v1/node/version"
 	changeDilithiumtoExecutionPath = "/eth/v1/beacon/pool/dilithium_to_execution_changes"
@@ -189,6 +192,15 @@ func (c *Client) GetConfigSpec(ctx context.Context) (*v1.SpecResponse, error) {
 	return fsr, nil
 }
 
+// GetDepositContract retrieves the chain id and the address of the deposit contract used by the beacon node.
+func (c *Client) GetDepositContract(ctx context.Context) (*apimiddleware.DepositContractResponseJson, error) {
+	resp := &apimiddleware.DepositContractResponseJson{}
+	if err := c.getJSON(ctx, getDepositContractPath, resp); err != nil {
+		return nil, errors.Wrap(err, "error requesting deposit contract")
+	}
+	return resp, nil
+}
+
 type NodeVersion struct {
 	implementation string
 	semver         string
@@ -280,6 +292,15 @@ func (c *Client) GetWeakSubjectivity(ctx context.Context) (*WeakSubjectivityData
 	}, nil
 }
 
+// GetDepositSnapshot retrieves the EIP-4881 snapshot of the finalized deposit tree.
+func (c *Client) GetDepositSnapshot(ctx context.Context) (*depositsnapshot.DepositTreeSnapshot, error) {
+	body, err := c.Get(ctx, getDepositSnapshotPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "error requesting deposit snapshot")
+	}
+	return depositsnapshot.DecodeSnapshot(body)
+}
+
 // SubmitChangeDilithiumtoExecution calls a beacon API endpoint to set the withdrawal addresses based on the given signed messages.
 // If the API responds with something other than OK there will be failure messages associated to the corresponding request message.
 func (c *Client) SubmitChangeDilithiumtoExecution(ctx context.Context, request []*apimiddleware.SignedDilithiumToExecutionChangeJson) error {
@@ -334,6 +355,31 @@ func (c *Client) GetDilithiumtoExecutionChanges(ctx context.Context) (*apimiddle
 	return poolResponse, nil
 }
 
+// getJSON requests the path and decodes the JSON response into v.
+func (c *Client) getJSON(ctx context.Context, p string, v interface{}, opts ...client.ReqOption) error {
+	b, err := c.Get(ctx, p, append(opts, client.WithJSONEncoding())...)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(json.Unmarshal(b, v), "error decoding json response from %s", p)
+}
+
+// postJSON posts the JSON encoding of req to the path and, unless v is nil, decodes the JSON response into v.
+func (c *Client) postJSON(ctx context.Context, p string, req interface{}, v interface{}, opts ...client.ReqOption) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal JSON")
+	}
+	b, err := c.Post(ctx, p, body, opts...)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return nil
+	}
+	return errors.Wrapf(json.Unmarshal(b, v), "error decoding json response from %s", p)
+}
+
 type forkResponse struct {
 	PreviousVersion string `json:"previous_version"`
 	CurrentVersion  string `json:"current_version"`
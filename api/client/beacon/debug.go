@@ -0,0 +1,91 @@
+package beacon
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/theQRL/qrysm/v4/api/client"
+	"github.com/theQRL/qrysm/v4/beacon-chain/rpc/apimiddleware"
+	"github.com/theQRL/qrysm/v4/beacon-chain/rpc/eth/debug"
+)
+
+const (
+	getForkChoiceHeadsPath   = "/eth/v2/debug/beacon/heads"
+	getForkChoicePath        = "/eth/v1/debug/fork_choice"
+	getForkChoiceHistoryPath = "/eth/v1/debug/fork_choice/history"
+	getStateProofPath        = "/eth/v1/debug/beacon/states/{{.Id}}/proof"
+)
+
+var getStateProofTpl = idTemplate(getStateProofPath)
+
+// GetStateV2 retrieves the JSON encoding of the BeaconState identified by stateId.
+// The version of the response tells which fork the state of the data belongs to.
+func (c *Client) GetStateV2(ctx context.Context, stateId StateOrBlockId) (*apimiddleware.BeaconStateV2ResponseJson, error) {
+	resp := &apimiddleware.BeaconStateV2ResponseJson{}
+	if err := c.getJSON(ctx, renderGetStatePath(stateId), resp); err != nil {
+		return nil, errors.Wrapf(err, "error requesting state by id = %s", stateId)
+	}
+	return resp, nil
+}
+
+// GetStateSSZ retrieves the ssz encoding of the BeaconState identified by stateId, along with its version.
+func (c *Client) GetStateSSZ(ctx context.Context, stateId StateOrBlockId) (*SSZResponse, error) {
+	resp, err := c.getSSZ(ctx, path.Join(getStatePath, string(stateId)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error requesting state by id = %s", stateId)
+	}
+	return resp, nil
+}
+
+// GetForkChoiceHeads retrieves the leaves of the fork choice tree of the beacon node.
+func (c *Client) GetForkChoiceHeads(ctx context.Context) (*apimiddleware.V2ForkChoiceHeadsResponseJson, error) {
+	resp := &apimiddleware.V2ForkChoiceHeadsResponseJson{}
+	if err := c.getJSON(ctx, getForkChoiceHeadsPath, resp); err != nil {
+		return nil, errors.Wrap(err, "error requesting fork choice heads")
+	}
+	return resp, nil
+}
+
+// GetForkChoice retrieves a dump of the fork choice store of the beacon node.
+func (c *Client) GetForkChoice(ctx context.Context) (*apimiddleware.ForkChoiceDumpJson, error) {
+	resp := &apimiddleware.ForkChoiceDumpJson{}
+	if err := c.getJSON(ctx, getForkChoicePath, resp); err != nil {
+		return nil, errors.Wrap(err, "error requesting fork choice")
+	}
+	return resp, nil
+}
+
+// GetForkChoiceHistory retrieves the recorded fork choice decisions of the beacon node, in the "json" or "dot"
+// format. The response is returned as is.
+func (c *Client) GetForkChoiceHistory(ctx context.Context, format string) ([]byte, error) {
+	query := url.Values{}
+	if format != "" {
+		query.Set("format", format)
+	}
+	b, err := c.Get(ctx, getForkChoiceHistoryPath, client.WithQuery(query))
+	if err != nil {
+		return nil, errors.Wrap(err, "error requesting fork choice history")
+	}
+	return b, nil
+}
+
+// GetStateProof retrieves the Merkle multiproof of the fields of the BeaconState identified by stateId. Fields are
+// identified by dot separated paths of field names and indices, such as validators.5.effective_balance, or by
+// their generalized indices.
+func (c *Client) GetStateProof(ctx context.Context, stateId StateOrBlockId, paths []string, gindices []uint64) (*debug.StateProofResponse, error) {
+	query := url.Values{}
+	for _, p := range paths {
+		query.Add("path", p)
+	}
+	for _, g := range gindices {
+		query.Add("gindex", strconv.FormatUint(g, 10))
+	}
+	resp := &debug.StateProofResponse{}
+	if err := c.getJSON(ctx, getStateProofTpl(stateId), resp, client.WithQuery(query)); err != nil {
+		return nil, errors.Wrapf(err, "error requesting state proof by id = %s", stateId)
+	}
+	return resp, nil
+}
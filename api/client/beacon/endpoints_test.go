@@ -0,0 +1,225 @@
+package beacon
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/theQRL/qrysm/v4/api/client"
+	"github.com/theQRL/qrysm/v4/beacon-chain/rpc/apimiddleware"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+)
+
+// testServer returns a client of an httptest stand-in for the beacon API serving the handlers by path.
+func testServer(t *testing.T, handlers map[string]http.HandlerFunc) *Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, ok := handlers[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		h(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+	return c
+}
+
+func writeBody(t *testing.T, w http.ResponseWriter, body string) {
+	_, err := w.Write([]byte(body))
+	require.NoError(t, err)
+}
+
+func readBody(t *testing.T, r *http.Request) string {
+	b, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestGetValidators(t *testing.T) {
+	c := testServer(t, map[string]http.HandlerFunc{
+		"/eth/v1/beacon/states/head/validators": func(w http.ResponseWriter, r *http.Request) {
+			assert.DeepEqual(t, []string{"1", "0xabcd"}, r.URL.Query()["id"])
+			assert.DeepEqual(t, []string{"active_ongoing"}, r.URL.Query()["status"])
+			writeBody(t, w, `{"data":[{"index":"1","balance":"32000000000","status":"active_ongoing"}],"finalized":true}`)
+		},
+	})
+	resp, err := c.GetValidators(context.Background(), IdHead, []string{"1", "0xabcd"}, []string{"active_ongoing"})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(resp.Data))
+	assert.Equal(t, "1", resp.Data[0].Index)
+	assert.Equal(t, "32000000000", resp.Data[0].Balance)
+	assert.Equal(t, true, resp.Finalized)
+}
+
+func TestGetCommittees(t *testing.T) {
+	c := testServer(t, map[string]http.HandlerFunc{
+		"/eth/v1/beacon/states/finalized/committees": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "epoch=3&slot=100", r.URL.RawQuery)
+			writeBody(t, w, `{"data":[{"index":"0","slot":"100","validators":["4","7"]}]}`)
+		},
+	})
+	epoch, slot := primitives.Epoch(3), primitives.Slot(100)
+	resp, err := c.GetCommittees(context.Background(), IdFinalized, &epoch, nil, &slot)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(resp.Data))
+	assert.DeepEqual(t, []string{"4", "7"}, resp.Data[0].Validators)
+}
+
+func TestGetBlockSSZ(t *testing.T) {
+	c := testServer(t, map[string]http.HandlerFunc{
+		"/eth/v2/beacon/blocks/12": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, octetStreamMediaType, r.Header.Get("Accept"))
+			w.Header().Set(versionHeader, "capella")
+			writeBody(t, w, "ssz")
+		},
+	})
+	resp, err := c.GetBlockSSZ(context.Background(), IdFromSlot(12))
+	require.NoError(t, err)
+	assert.Equal(t, "capella", resp.Version)
+	assert.Equal(t, "ssz", string(resp.Data))
+
+	_, err = c.GetBlockSSZ(context.Background(), IdFromSlot(13))
+	require.ErrorIs(t, err, client.ErrNotFound)
+}
+
+func TestGetBlockV2(t *testing.T) {
+	c := testServer(t, map[string]http.HandlerFunc{
+		"/eth/v2/beacon/blocks/head": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "application/json", r.Header.Get("Accept"))
+			writeBody(t, w, `{"version":"capella","execution_optimistic":true,"data":{}}`)
+		},
+	})
+	resp, err := c.GetBlockV2(context.Background(), IdHead)
+	require.NoError(t, err)
+	assert.Equal(t, "capella", resp.Version)
+	assert.Equal(t, true, resp.ExecutionOptimistic)
+}
+
+func TestPublishBlockSSZ(t *testing.T) {
+	c := testServer(t, map[string]http.HandlerFunc{
+		"/eth/v1/beacon/blocks": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, octetStreamMediaType, r.Header.Get("Content-Type"))
+			assert.Equal(t, "capella", r.Header.Get(versionHeader))
+			assert.Equal(t, "ssz", readBody(t, r))
+			w.WriteHeader(http.StatusAccepted)
+		},
+	})
+	require.NoError(t, c.PublishBlockSSZ(context.Background(), "capella", []byte("ssz")))
+}
+
+func TestSubmitAttestations(t *testing.T) {
+	c := testServer(t, map[string]http.HandlerFunc{
+		"/eth/v1/beacon/pool/attestations": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.Equal(t, `[{"aggregation_bits":"0x01","data":null,"signature":""}]`, readBody(t, r))
+			w.WriteHeader(http.StatusBadRequest)
+			writeBody(t, w, `{"code":400,"message":"invalid attestation"}`)
+		},
+	})
+	err := c.SubmitAttestations(context.Background(), []*apimiddleware.AttestationJson{{AggregationBits: "0x01"}})
+	require.ErrorIs(t, err, client.ErrNotOK)
+	require.ErrorContains(t, "invalid attestation", err)
+}
+
+func TestGetHealth(t *testing.T) {
+	c := testServer(t, map[string]http.HandlerFunc{
+		"/eth/v1/node/health": func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusPartialContent)
+		},
+	})
+	code, err := c.GetHealth(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusPartialContent, code)
+}
+
+func TestGetPeers(t *testing.T) {
+	c := testServer(t, map[string]http.HandlerFunc{
+		"/eth/v1/node/peers": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "direction=inbound&state=connected", r.URL.RawQuery)
+			writeBody(t, w, `{"data":[{"peer_id":"peer1","state":"connected","direction":"inbound"}]}`)
+		},
+	})
+	resp, err := c.GetPeers(context.Background(), []string{"connected"}, []string{"inbound"})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(resp.Data))
+	assert.Equal(t, "peer1", resp.Data[0].PeerId)
+}
+
+func TestGetAttesterDuties(t *testing.T) {
+	c := testServer(t, map[string]http.HandlerFunc{
+		"/eth/v1/validator/duties/attester/5": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, `["1","2"]`, readBody(t, r))
+			writeBody(t, w, `{"dependent_root":"0x01","data":[{"validator_index":"1","slot":"160"},{"validator_index":"2","slot":"161"}]}`)
+		},
+	})
+	resp, err := c.GetAttesterDuties(context.Background(), 5, []primitives.ValidatorIndex{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, "0x01", resp.DependentRoot)
+	require.Equal(t, 2, len(resp.Data))
+	assert.Equal(t, "161", resp.Data[1].Slot)
+}
+
+func TestProduceBlockSSZ(t *testing.T) {
+	randao := []byte{1, 2}
+	c := testServer(t, map[string]http.HandlerFunc{
+		"/eth/v2/validator/blocks/33": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "0x0102", r.URL.Query().Get("randao_reveal"))
+			assert.Equal(t, "0x03", r.URL.Query().Get("graffiti"))
+			w.Header().Set(versionHeader, "bellatrix")
+			writeBody(t, w, "block")
+		},
+	})
+	resp, err := c.ProduceBlockSSZ(context.Background(), 33, randao, []byte{3})
+	require.NoError(t, err)
+	assert.Equal(t, "bellatrix", resp.Version)
+	assert.Equal(t, "block", string(resp.Data))
+}
+
+func TestGetAggregateAttestation(t *testing.T) {
+	root := [32]byte{0xaa}
+	c := testServer(t, map[string]http.HandlerFunc{
+		"/eth/v1/validator/aggregate_attestation": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "7", r.URL.Query().Get("slot"))
+			assert.Equal(t, fmt.Sprintf("%#x", root), r.URL.Query().Get("attestation_data_root"))
+			writeBody(t, w, `{"data":{"aggregation_bits":"0x03"}}`)
+		},
+	})
+	resp, err := c.GetAggregateAttestation(context.Background(), 7, root)
+	require.NoError(t, err)
+	assert.Equal(t, "0x03", resp.Data.AggregationBits)
+}
+
+func TestGetAttestationRewards(t *testing.T) {
+	c := testServer(t, map[string]http.HandlerFunc{
+		"/eth/v1/beacon/rewards/attestations/2": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, `[]`, readBody(t, r))
+			writeBody(t, w, `{"data":{"total_rewards":[{"validator_index":"0","head":"10"}]}}`)
+		},
+	})
+	resp, err := c.GetAttestationRewards(context.Background(), 2, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(resp.Data.TotalRewards))
+	assert.Equal(t, "10", resp.Data.TotalRewards[0].Head)
+}
+
+func TestGetStateProof(t *testing.T) {
+	c := testServer(t, map[string]http.HandlerFunc{
+		"/eth/v1/debug/beacon/states/head/proof": func(w http.ResponseWriter, r *http.Request) {
+			assert.DeepEqual(t, []string{"slot"}, r.URL.Query()["path"])
+			assert.DeepEqual(t, []string{"34"}, r.URL.Query()["gindex"])
+			writeBody(t, w, `{"data":{"state_root":"0x01","gindices":["34","34"]}}`)
+		},
+	})
+	resp, err := c.GetStateProof(context.Background(), IdHead, []string{"slot"}, []uint64{34})
+	require.NoError(t, err)
+	assert.Equal(t, "0x01", resp.Data.StateRoot)
+	assert.DeepEqual(t, []string{"34", "34"}, resp.Data.Gindices)
+}
@@ -0,0 +1,125 @@
+package beacon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/theQRL/qrysm/v4/api/client"
+	"github.com/theQRL/qrysm/v4/beacon-chain/rpc/apimiddleware"
+	"github.com/theQRL/qrysm/v4/runtime/version"
+)
+
+const (
+	eventsPath = "/eth/v1/events"
+
+	// maxEventSize is the maximum size of a line of the event stream.
+	maxEventSize = 16 << 20
+)
+
+// Event is an event received from the event stream of the beacon node.
+type Event struct {
+	Topic string
+	Data  []byte
+}
+
+// Decode decodes the data of the event into the apimiddleware type of its topic, such as EventHeadJson for
+// the "head" topic, and returns a pointer to it.
+func (e *Event) Decode() (interface{}, error) {
+	var data interface{}
+	switch e.Topic {
+	case "head":
+		data = &apimiddleware.EventHeadJson{}
+	case "block":
+		data = &apimiddleware.ReceivedBlockDataJson{}
+	case "attestation":
+		data = &apimiddleware.AttestationJson{}
+	case "voluntary_exit":
+		data = &apimiddleware.SignedVoluntaryExitJson{}
+	case "finalized_checkpoint":
+		data = &apimiddleware.EventFinalizedCheckpointJson{}
+	case "chain_reorg":
+		data = &apimiddleware.EventChainReorgJson{}
+	case "contribution_and_proof":
+		data = &apimiddleware.SignedContributionAndProofJson{}
+	case "dilithium_to_execution_change":
+		data = &apimiddleware.SignedDilithiumToExecutionChangeJson{}
+	case "payload_attributes":
+		v := &struct {
+			Version string `json:"version"`
+		}{}
+		if err := json.Unmarshal(e.Data, v); err != nil {
+			return nil, errors.Wrap(err, "could not decode payload attributes version")
+		}
+		switch v.Version {
+		case version.String(version.Bellatrix):
+			data = &apimiddleware.EventPayloadAttributeStreamV1Json{}
+		case version.String(version.Capella):
+			data = &apimiddleware.EventPayloadAttributeStreamV2Json{}
+		default:
+			return nil, errors.Errorf("unsupported payload attributes version %s", v.Version)
+		}
+	case "error":
+		data = &apimiddleware.EventErrorJson{}
+	default:
+		return nil, errors.Errorf("unsupported event topic %s", e.Topic)
+	}
+	if err := json.Unmarshal(e.Data, data); err != nil {
+		return nil, errors.Wrapf(err, "could not decode %s event", e.Topic)
+	}
+	return data, nil
+}
+
+// StreamEvents subscribes to the events of the given topics and calls handler with each received event, in order.
+// It returns when the context is canceled, when the beacon node ends the stream, or with the first error returned
+// by the handler.
+func (c *Client) StreamEvents(ctx context.Context, topics []string, handler func(*Event) error) error {
+	query := url.Values{}
+	for _, t := range topics {
+		query.Add("topics", t)
+	}
+	body, err := c.Stream(ctx, eventsPath, client.WithQuery(query), client.WithHeader("Accept", "text/event-stream"))
+	if err != nil {
+		return errors.Wrap(err, "error subscribing to events")
+	}
+	defer func() {
+		if err := body.Close(); err != nil {
+			log.WithError(err).Debug("Could not close event stream")
+		}
+	}()
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+	event := &Event{}
+	var data [][]byte
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// A blank line dispatches the event.
+			if event.Topic != "" || len(data) > 0 {
+				event.Data = bytes.Join(data, []byte("\n"))
+				if err := handler(event); err != nil {
+					return err
+				}
+			}
+			event = &Event{}
+			data = nil
+		case strings.HasPrefix(line, ":"):
+			// Comments keep the connection alive.
+		case strings.HasPrefix(line, "event:"):
+			event.Topic = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, []byte(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")))
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return errors.Wrap(scanner.Err(), "error reading event stream")
+}
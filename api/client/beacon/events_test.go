@@ -0,0 +1,60 @@
+package beacon
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/theQRL/qrysm/v4/beacon-chain/rpc/apimiddleware"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+)
+
+func TestStreamEvents(t *testing.T) {
+	c := testServer(t, map[string]http.HandlerFunc{
+		"/eth/v1/events": func(w http.ResponseWriter, r *http.Request) {
+			assert.DeepEqual(t, []string{"head", "finalized_checkpoint"}, r.URL.Query()["topics"])
+			w.Header().Set("Content-Type", "text/event-stream")
+			writeBody(t, w, ": keepalive\n\n")
+			writeBody(t, w, "event: head\ndata: {\"slot\":\"10\",\"epoch_transition\":true}\n\n")
+			w.(http.Flusher).Flush()
+			writeBody(t, w, "event: finalized_checkpoint\ndata: {\"epoch\":\"2\"}\n\n")
+		},
+	})
+
+	var events []*Event
+	err := c.StreamEvents(context.Background(), []string{"head", "finalized_checkpoint"}, func(e *Event) error {
+		events = append(events, e)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(events))
+
+	head, err := events[0].Decode()
+	require.NoError(t, err)
+	require.DeepEqual(t, &apimiddleware.EventHeadJson{Slot: "10", EpochTransition: true}, head)
+	finalized, err := events[1].Decode()
+	require.NoError(t, err)
+	require.DeepEqual(t, &apimiddleware.EventFinalizedCheckpointJson{Epoch: "2"}, finalized)
+}
+
+func TestStreamEvents_HandlerError(t *testing.T) {
+	c := testServer(t, map[string]http.HandlerFunc{
+		"/eth/v1/events": func(w http.ResponseWriter, r *http.Request) {
+			writeBody(t, w, "event: block\ndata: {\"slot\":\"1\"}\n\nevent: block\ndata: {\"slot\":\"2\"}\n\n")
+		},
+	})
+	calls := 0
+	err := c.StreamEvents(context.Background(), []string{"block"}, func(e *Event) error {
+		calls++
+		return errors.New("stop")
+	})
+	require.ErrorContains(t, "stop", err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestEvent_DecodeUnsupportedTopic(t *testing.T) {
+	_, err := (&Event{Topic: "unknown", Data: []byte("{}")}).Decode()
+	require.ErrorContains(t, "unsupported event topic", err)
+}
@@ -0,0 +1,82 @@
+package beacon
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+	"github.com/theQRL/qrysm/v4/api/client"
+	"github.com/theQRL/qrysm/v4/beacon-chain/rpc/apimiddleware"
+)
+
+const (
+	getNodeIdentityPath  = "/eth/v1/node/identity"
+	getNodePeersPath     = "/eth/v1/node/peers"
+	getNodePeerCountPath = "/eth/v1/node/peer_count"
+	getNodeSyncingPath   = "/eth/v1/node/syncing"
+	getNodeHealthPath    = "/eth/v1/node/health"
+)
+
+// GetNodeIdentity retrieves the network identity of the beacon node.
+func (c *Client) GetNodeIdentity(ctx context.Context) (*apimiddleware.IdentityResponseJson, error) {
+	resp := &apimiddleware.IdentityResponseJson{}
+	if err := c.getJSON(ctx, getNodeIdentityPath, resp); err != nil {
+		return nil, errors.Wrap(err, "error requesting node identity")
+	}
+	return resp, nil
+}
+
+// GetPeers retrieves the peers of the beacon node. Peers can be filtered by connection state and direction.
+// Empty filters match every peer.
+func (c *Client) GetPeers(ctx context.Context, states []string, directions []string) (*apimiddleware.PeersResponseJson, error) {
+	query := url.Values{}
+	for _, s := range states {
+		query.Add("state", s)
+	}
+	for _, d := range directions {
+		query.Add("direction", d)
+	}
+	resp := &apimiddleware.PeersResponseJson{}
+	if err := c.getJSON(ctx, getNodePeersPath, resp, client.WithQuery(query)); err != nil {
+		return nil, errors.Wrap(err, "error requesting peers")
+	}
+	return resp, nil
+}
+
+// GetPeer retrieves the peer of the beacon node with the given peer id.
+func (c *Client) GetPeer(ctx context.Context, peerId string) (*apimiddleware.PeerResponseJson, error) {
+	resp := &apimiddleware.PeerResponseJson{}
+	if err := c.getJSON(ctx, getNodePeersPath+"/"+url.PathEscape(peerId), resp); err != nil {
+		return nil, errors.Wrapf(err, "error requesting peer %s", peerId)
+	}
+	return resp, nil
+}
+
+// GetPeerCount retrieves the number of peers of the beacon node in each connection state.
+func (c *Client) GetPeerCount(ctx context.Context) (*apimiddleware.PeerCountResponseJson, error) {
+	resp := &apimiddleware.PeerCountResponseJson{}
+	if err := c.getJSON(ctx, getNodePeerCountPath, resp); err != nil {
+		return nil, errors.Wrap(err, "error requesting peer count")
+	}
+	return resp, nil
+}
+
+// GetSyncStatus retrieves the sync status of the beacon node.
+func (c *Client) GetSyncStatus(ctx context.Context) (*apimiddleware.SyncingResponseJson, error) {
+	resp := &apimiddleware.SyncingResponseJson{}
+	if err := c.getJSON(ctx, getNodeSyncingPath, resp); err != nil {
+		return nil, errors.Wrap(err, "error requesting sync status")
+	}
+	return resp, nil
+}
+
+// GetHealth returns the HTTP status code of the health endpoint of the beacon node: 200 when the node is ready,
+// 206 while it is syncing. Any other status is returned as an error.
+func (c *Client) GetHealth(ctx context.Context) (int, error) {
+	resp, err := c.Send(ctx, http.MethodGet, getNodeHealthPath, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "error requesting node health")
+	}
+	return resp.StatusCode, nil
+}
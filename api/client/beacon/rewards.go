@@ -0,0 +1,56 @@
+package beacon
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/theQRL/qrysm/v4/beacon-chain/rpc/eth/rewards"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+)
+
+const (
+	getBlockRewardsPath         = "/eth/v1/beacon/rewards/blocks/{{.Id}}"
+	getSyncCommitteeRewardsPath = "/eth/v1/beacon/rewards/sync_committee/{{.Id}}"
+	getAttestationRewardsPath   = "/eth/v1/beacon/rewards/attestations/"
+)
+
+var (
+	getBlockRewardsTpl         = idTemplate(getBlockRewardsPath)
+	getSyncCommitteeRewardsTpl = idTemplate(getSyncCommitteeRewardsPath)
+)
+
+// GetBlockRewards retrieves the rewards of the proposer of the block identified by blockId.
+func (c *Client) GetBlockRewards(ctx context.Context, blockId StateOrBlockId) (*rewards.BlockRewardsResponse, error) {
+	resp := &rewards.BlockRewardsResponse{}
+	if err := c.getJSON(ctx, getBlockRewardsTpl(blockId), resp); err != nil {
+		return nil, errors.Wrapf(err, "error requesting block rewards by id = %s", blockId)
+	}
+	return resp, nil
+}
+
+// GetAttestationRewards retrieves the attestation rewards of the epoch for the validators identified by their
+// index or hex encoded public key. An empty list of validators requests the rewards of every validator.
+func (c *Client) GetAttestationRewards(ctx context.Context, epoch primitives.Epoch, validators []string) (*rewards.AttestationRewardsResponse, error) {
+	resp := &rewards.AttestationRewardsResponse{}
+	if validators == nil {
+		validators = []string{}
+	}
+	if err := c.postJSON(ctx, epochPath(getAttestationRewardsPath, epoch), validators, resp); err != nil {
+		return nil, errors.Wrapf(err, "error requesting attestation rewards for epoch %d", epoch)
+	}
+	return resp, nil
+}
+
+// GetSyncCommitteeRewards retrieves the sync committee rewards of the block identified by blockId for the
+// validators identified by their index or hex encoded public key. An empty list of validators requests the
+// rewards of every sync committee member.
+func (c *Client) GetSyncCommitteeRewards(ctx context.Context, blockId StateOrBlockId, validators []string) (*rewards.SyncCommitteeRewardsResponse, error) {
+	resp := &rewards.SyncCommitteeRewardsResponse{}
+	if validators == nil {
+		validators = []string{}
+	}
+	if err := c.postJSON(ctx, getSyncCommitteeRewardsTpl(blockId), validators, resp); err != nil {
+		return nil, errors.Wrapf(err, "error requesting sync committee rewards by block id = %s", blockId)
+	}
+	return resp, nil
+}
@@ -0,0 +1,202 @@
+package beacon
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/theQRL/go-zond/common/hexutil"
+	"github.com/theQRL/qrysm/v4/api/client"
+	"github.com/theQRL/qrysm/v4/beacon-chain/rpc/apimiddleware"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+)
+
+const (
+	attesterDutiesPath               = "/eth/v1/validator/duties/attester/"
+	proposerDutiesPath               = "/eth/v1/validator/duties/proposer/"
+	syncCommitteeDutiesPath          = "/eth/v1/validator/duties/sync/"
+	produceBlockPath                 = "/eth/v2/validator/blocks/"
+	produceBlindedBlockPath          = "/eth/v1/validator/blinded_blocks/"
+	attestationDataPath              = "/eth/v1/validator/attestation_data"
+	aggregateAttestationPath         = "/eth/v1/validator/aggregate_attestation"
+	beaconCommitteeSubscriptionsPath = "/eth/v1/validator/beacon_committee_subscriptions"
+	syncCommitteeSubscriptionsPath   = "/eth/v1/validator/sync_committee_subscriptions"
+	aggregateAndProofsPath           = "/eth/v1/validator/aggregate_and_proofs"
+	syncCommitteeContributionPath    = "/eth/v1/validator/sync_committee_contribution"
+	contributionAndProofsPath        = "/eth/v1/validator/contribution_and_proofs"
+	prepareBeaconProposerPath        = "/eth/v1/validator/prepare_beacon_proposer"
+	registerValidatorPath            = "/eth/v1/validator/register_validator"
+	livenessPath                     = "/eth/v1/validator/liveness/"
+)
+
+func epochPath(p string, epoch primitives.Epoch) string {
+	return p + strconv.FormatUint(uint64(epoch), 10)
+}
+
+func slotPath(p string, slot primitives.Slot) string {
+	return p + strconv.FormatUint(uint64(slot), 10)
+}
+
+func indicesRequest(indices []primitives.ValidatorIndex) []string {
+	req := make([]string, len(indices))
+	for i, idx := range indices {
+		req[i] = strconv.FormatUint(uint64(idx), 10)
+	}
+	return req
+}
+
+// GetAttesterDuties retrieves the attester duties of the validators with the given indices in the epoch.
+func (c *Client) GetAttesterDuties(ctx context.Context, epoch primitives.Epoch, indices []primitives.ValidatorIndex) (*apimiddleware.AttesterDutiesResponseJson, error) {
+	resp := &apimiddleware.AttesterDutiesResponseJson{}
+	if err := c.postJSON(ctx, epochPath(attesterDutiesPath, epoch), indicesRequest(indices), resp); err != nil {
+		return nil, errors.Wrapf(err, "error requesting attester duties for epoch %d", epoch)
+	}
+	return resp, nil
+}
+
+// GetProposerDuties retrieves the block proposers of the epoch.
+func (c *Client) GetProposerDuties(ctx context.Context, epoch primitives.Epoch) (*apimiddleware.ProposerDutiesResponseJson, error) {
+	resp := &apimiddleware.ProposerDutiesResponseJson{}
+	if err := c.getJSON(ctx, epochPath(proposerDutiesPath, epoch), resp); err != nil {
+		return nil, errors.Wrapf(err, "error requesting proposer duties for epoch %d", epoch)
+	}
+	return resp, nil
+}
+
+// GetSyncCommitteeDuties retrieves the sync committee duties of the validators with the given indices in the epoch.
+func (c *Client) GetSyncCommitteeDuties(ctx context.Context, epoch primitives.Epoch, indices []primitives.ValidatorIndex) (*apimiddleware.SyncCommitteeDutiesResponseJson, error) {
+	resp := &apimiddleware.SyncCommitteeDutiesResponseJson{}
+	if err := c.postJSON(ctx, epochPath(syncCommitteeDutiesPath, epoch), indicesRequest(indices), resp); err != nil {
+		return nil, errors.Wrapf(err, "error requesting sync committee duties for epoch %d", epoch)
+	}
+	return resp, nil
+}
+
+func produceBlockQuery(randaoReveal []byte, graffiti []byte) url.Values {
+	query := url.Values{}
+	query.Set("randao_reveal", hexutil.Encode(randaoReveal))
+	if len(graffiti) > 0 {
+		query.Set("graffiti", hexutil.Encode(graffiti))
+	}
+	return query
+}
+
+// ProduceBlock requests an unsigned block for the slot, built by the beacon node with the given RANDAO reveal
+// and optional graffiti.
+func (c *Client) ProduceBlock(ctx context.Context, slot primitives.Slot, randaoReveal []byte, graffiti []byte) (*apimiddleware.ProduceBlockResponseV2Json, error) {
+	resp := &apimiddleware.ProduceBlockResponseV2Json{}
+	q := client.WithQuery(produceBlockQuery(randaoReveal, graffiti))
+	if err := c.getJSON(ctx, slotPath(produceBlockPath, slot), resp, q); err != nil {
+		return nil, errors.Wrapf(err, "error producing block for slot %d", slot)
+	}
+	return resp, nil
+}
+
+// ProduceBlockSSZ requests the ssz encoding of an unsigned block for the slot, along with its version.
+func (c *Client) ProduceBlockSSZ(ctx context.Context, slot primitives.Slot, randaoReveal []byte, graffiti []byte) (*SSZResponse, error) {
+	resp, err := c.getSSZ(ctx, slotPath(produceBlockPath, slot), client.WithQuery(produceBlockQuery(randaoReveal, graffiti)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error producing block for slot %d", slot)
+	}
+	return resp, nil
+}
+
+// ProduceBlindedBlock requests an unsigned blinded block for the slot, whose execution payload header is
+// built by the builder network when the beacon node is configured to use one.
+func (c *Client) ProduceBlindedBlock(ctx context.Context, slot primitives.Slot, randaoReveal []byte, graffiti []byte) (*apimiddleware.ProduceBlindedBlockResponseJson, error) {
+	resp := &apimiddleware.ProduceBlindedBlockResponseJson{}
+	q := client.WithQuery(produceBlockQuery(randaoReveal, graffiti))
+	if err := c.getJSON(ctx, slotPath(produceBlindedBlockPath, slot), resp, q); err != nil {
+		return nil, errors.Wrapf(err, "error producing blinded block for slot %d", slot)
+	}
+	return resp, nil
+}
+
+// ProduceBlindedBlockSSZ requests the ssz encoding of an unsigned blinded block for the slot, along with its version.
+func (c *Client) ProduceBlindedBlockSSZ(ctx context.Context, slot primitives.Slot, randaoReveal []byte, graffiti []byte) (*SSZResponse, error) {
+	resp, err := c.getSSZ(ctx, slotPath(produceBlindedBlockPath, slot), client.WithQuery(produceBlockQuery(randaoReveal, graffiti)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error producing blinded block for slot %d", slot)
+	}
+	return resp, nil
+}
+
+// GetAttestationData requests the attestation data to sign for the committee index at the slot.
+func (c *Client) GetAttestationData(ctx context.Context, slot primitives.Slot, committeeIndex primitives.CommitteeIndex) (*apimiddleware.ProduceAttestationDataResponseJson, error) {
+	query := url.Values{}
+	query.Set("slot", strconv.FormatUint(uint64(slot), 10))
+	query.Set("committee_index", strconv.FormatUint(uint64(committeeIndex), 10))
+	resp := &apimiddleware.ProduceAttestationDataResponseJson{}
+	if err := c.getJSON(ctx, attestationDataPath, resp, client.WithQuery(query)); err != nil {
+		return nil, errors.Wrapf(err, "error requesting attestation data for slot %d", slot)
+	}
+	return resp, nil
+}
+
+// GetAggregateAttestation requests the aggregate of the attestations of the slot whose attestation data has the
+// given hash_tree_root.
+func (c *Client) GetAggregateAttestation(ctx context.Context, slot primitives.Slot, attDataRoot [32]byte) (*apimiddleware.AggregateAttestationResponseJson, error) {
+	query := url.Values{}
+	query.Set("slot", strconv.FormatUint(uint64(slot), 10))
+	query.Set("attestation_data_root", fmt.Sprintf("%#x", attDataRoot))
+	resp := &apimiddleware.AggregateAttestationResponseJson{}
+	if err := c.getJSON(ctx, aggregateAttestationPath, resp, client.WithQuery(query)); err != nil {
+		return nil, errors.Wrapf(err, "error requesting aggregate attestation for slot %d", slot)
+	}
+	return resp, nil
+}
+
+// SubmitBeaconCommitteeSubscriptions subscribes the beacon node to the attestation subnets of the committees.
+func (c *Client) SubmitBeaconCommitteeSubscriptions(ctx context.Context, subs []*apimiddleware.BeaconCommitteeSubscribeJson) error {
+	return errors.Wrap(c.postJSON(ctx, beaconCommitteeSubscriptionsPath, subs, nil), "error submitting beacon committee subscriptions")
+}
+
+// SubmitSyncCommitteeSubscriptions subscribes the beacon node to the sync committee subnets of the validators.
+func (c *Client) SubmitSyncCommitteeSubscriptions(ctx context.Context, subs []*apimiddleware.SyncCommitteeSubscriptionJson) error {
+	return errors.Wrap(c.postJSON(ctx, syncCommitteeSubscriptionsPath, subs, nil), "error submitting sync committee subscriptions")
+}
+
+// SubmitAggregateAndProofs submits signed aggregate attestations to the beacon node for broadcast.
+func (c *Client) SubmitAggregateAndProofs(ctx context.Context, aggs []*apimiddleware.SignedAggregateAttestationAndProofJson) error {
+	return errors.Wrap(c.postJSON(ctx, aggregateAndProofsPath, aggs, nil), "error submitting aggregate and proofs")
+}
+
+// ProduceSyncCommitteeContribution requests the aggregate of the sync committee messages of the subcommittee
+// for the block root at the slot.
+func (c *Client) ProduceSyncCommitteeContribution(ctx context.Context, slot primitives.Slot, subcommitteeIndex uint64, blockRoot [32]byte) (*apimiddleware.ProduceSyncCommitteeContributionResponseJson, error) {
+	query := url.Values{}
+	query.Set("slot", strconv.FormatUint(uint64(slot), 10))
+	query.Set("subcommittee_index", strconv.FormatUint(subcommitteeIndex, 10))
+	query.Set("beacon_block_root", fmt.Sprintf("%#x", blockRoot))
+	resp := &apimiddleware.ProduceSyncCommitteeContributionResponseJson{}
+	if err := c.getJSON(ctx, syncCommitteeContributionPath, resp, client.WithQuery(query)); err != nil {
+		return nil, errors.Wrapf(err, "error requesting sync committee contribution for slot %d", slot)
+	}
+	return resp, nil
+}
+
+// SubmitContributionAndProofs submits signed sync committee contributions to the beacon node for broadcast.
+func (c *Client) SubmitContributionAndProofs(ctx context.Context, contributions []*apimiddleware.SignedContributionAndProofJson) error {
+	return errors.Wrap(c.postJSON(ctx, contributionAndProofsPath, contributions, nil), "error submitting contribution and proofs")
+}
+
+// PrepareBeaconProposer submits the fee recipients of the validators to the beacon node.
+func (c *Client) PrepareBeaconProposer(ctx context.Context, recipients []*apimiddleware.FeeRecipientJson) error {
+	return errors.Wrap(c.postJSON(ctx, prepareBeaconProposerPath, recipients, nil), "error preparing beacon proposer")
+}
+
+// RegisterValidators submits signed validator registrations to the beacon node, which forwards them to the builder.
+func (c *Client) RegisterValidators(ctx context.Context, registrations []*apimiddleware.SignedValidatorRegistrationJson) error {
+	return errors.Wrap(c.postJSON(ctx, registerValidatorPath, registrations, nil), "error registering validators")
+}
+
+// GetLiveness retrieves whether the validators with the given indices were seen active in the epoch.
+func (c *Client) GetLiveness(ctx context.Context, epoch primitives.Epoch, indices []primitives.ValidatorIndex) (*apimiddleware.LivenessResponseJson, error) {
+	resp := &apimiddleware.LivenessResponseJson{}
+	if err := c.postJSON(ctx, epochPath(livenessPath, epoch), indicesRequest(indices), resp); err != nil {
+		return nil, errors.Wrapf(err, "error requesting liveness for epoch %d", epoch)
+	}
+	return resp, nil
+}
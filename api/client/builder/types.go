@@ -813,14 +813,22 @@ type Attestation struct {
 
 // MarshalJSON returns a JSON byte array representation of Attestation.
 func (a *Attestation) MarshalJSON() ([]byte, error) {
+	// The indices of the signers are part of the hash tree root of the attestation, so a
+	// relay needs them to verify the signature of the block.
+	sigValidatorIndices := make([]string, len(a.Attestation.SignatureValidatorIndex))
+	for i := range a.Attestation.SignatureValidatorIndex {
+		sigValidatorIndices[i] = fmt.Sprintf("%d", a.Attestation.SignatureValidatorIndex[i])
+	}
 	return json.Marshal(struct {
-		AggregationBits hexutil.Bytes    `json:"aggregation_bits"`
-		Data            *AttestationData `json:"data"`
-		Signature       hexutil.Bytes    `json:"signature" ssz-size:"96"`
+		AggregationBits         hexutil.Bytes    `json:"aggregation_bits"`
+		Data                    *AttestationData `json:"data"`
+		Signature               hexutil.Bytes    `json:"signature" ssz-size:"96"`
+		SignatureValidatorIndex []string         `json:"signature_validator_index,omitempty"`
 	}{
-		AggregationBits: hexutil.Bytes(a.Attestation.AggregationBits),
-		Data:            &AttestationData{a.Attestation.Data},
-		Signature:       a.Attestation.Signature,
+		AggregationBits:         hexutil.Bytes(a.Attestation.AggregationBits),
+		Data:                    &AttestationData{a.Attestation.Data},
+		Signature:               a.Attestation.Signature,
+		SignatureValidatorIndex: sigValidatorIndices,
 	})
 }
 
@@ -899,6 +899,12 @@ func TestAttestationMarshal(t *testing.T) {
 	require.NoError(t, err)
 	expected := `{"aggregation_bits":"0x01","data":{"slot":"1","index":"1","beacon_block_root":"0xcf8e0d4e9587369b2301d0790347320302cc0943d5a1884560367e8208d920f2","source":{"epoch":"1","root":"0xcf8e0d4e9587369b2301d0790347320302cc0943d5a1884560367e8208d920f2"},"target":{"epoch":"1","root":"0xcf8e0d4e9587369b2301d0790347320302cc0943d5a1884560367e8208d920f2"}},"signature":"0x1b66ac1fb663c9bc59509846d6ec05345bd908eda73e670af888da41af171505cc411d61252fb6cb3fa0017b679f8bb2305b26a285fa2737f175668d0dff91cc1b66ac1fb663c9bc59509846d6ec05345bd908eda73e670af888da41af171505"}`
 	require.Equal(t, expected, string(b))
+
+	a.SignatureValidatorIndex = []uint64{1, 4}
+	b, err = json.Marshal(a)
+	require.NoError(t, err)
+	expected = expected[:len(expected)-1] + `,"signature_validator_index":["1","4"]}`
+	require.Equal(t, expected, string(b))
 }
 
 func pbAttesterSlashing(t *testing.T) *zond.AttesterSlashing {
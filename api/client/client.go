@@ -1,20 +1,24 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
 // Client is a wrapper object around the HTTP client.
 type Client struct {
-	hc      *http.Client
-	baseURL *url.URL
-	token   string
+	hc           *http.Client
+	baseURL      *url.URL
+	token        string
+	retries      int
+	retryBackoff time.Duration
 }
 
 // NewClient constructs a new client with the provided options (ex WithTimeout).
@@ -71,27 +75,104 @@ func (c *Client) NodeURL() string {
 
 // Get is a generic, opinionated GET function to reduce boilerplate amongst the getters in this package.
 func (c *Client) Get(ctx context.Context, path string, opts ...ReqOption) ([]byte, error) {
-	u := c.baseURL.ResolveReference(&url.URL{Path: path})
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	resp, err := c.Send(ctx, http.MethodGet, path, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Post is a generic, opinionated POST function sending a JSON body, unless a request option sets another
+// content type.
+func (c *Client) Post(ctx context.Context, path string, body []byte, opts ...ReqOption) ([]byte, error) {
+	opts = append([]ReqOption{WithContentType(jsonMediaType)}, opts...)
+	resp, err := c.Send(ctx, http.MethodPost, path, body, opts...)
 	if err != nil {
 		return nil, err
 	}
+	return resp.Body, nil
+}
+
+// Response is the status code, the body and the headers of a successful API response.
+type Response struct {
+	StatusCode int
+	Body       []byte
+	Header     http.Header
+}
+
+// Send executes a request with the method, path and body, and returns the response if it has a 2xx status.
+// Requests failing because of a network error, a 429 or a 5xx status are retried as configured by WithRetries.
+func (c *Client) Send(ctx context.Context, method, path string, body []byte, opts ...ReqOption) (*Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, status, err := c.send(ctx, method, path, body, opts...)
+		if err == nil {
+			return resp, nil
+		}
+		retryable := status == 0 || status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+		if attempt >= c.retries || !retryable || ctx.Err() != nil {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(c.retryBackoff * time.Duration(attempt+1)):
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, path string, body []byte, opts ...ReqOption) (*Response, int, error) {
+	u := c.baseURL.ResolveReference(&url.URL{Path: path})
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+	if err != nil {
+		return nil, 0, err
+	}
 	for _, o := range opts {
 		o(req)
 	}
 	r, err := c.hc.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer func() {
 		err = r.Body.Close()
 	}()
-	if r.StatusCode != http.StatusOK {
-		return nil, Non200Err(r)
+	if r.StatusCode < 200 || r.StatusCode > 299 {
+		return nil, r.StatusCode, Non200Err(r)
 	}
 	b, err := io.ReadAll(r.Body)
 	if err != nil {
-		return nil, errors.Wrap(err, "error reading http response body")
+		return nil, r.StatusCode, errors.Wrap(err, "error reading http response body")
+	}
+	return &Response{StatusCode: r.StatusCode, Body: b, Header: r.Header}, r.StatusCode, nil
+}
+
+// Stream executes a GET request and returns the body of the response as it is received, for endpoints such as
+// server-sent events. The client timeout does not apply to streams, which end when the context is canceled.
+// The caller must close the returned body.
+func (c *Client) Stream(ctx context.Context, path string, opts ...ReqOption) (io.ReadCloser, error) {
+	u := c.baseURL.ResolveReference(&url.URL{Path: path})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range opts {
+		o(req)
+	}
+	hc := *c.hc
+	hc.Timeout = 0
+	r, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.StatusCode != http.StatusOK {
+		defer func() {
+			err = r.Body.Close()
+		}()
+		return nil, Non200Err(r)
 	}
-	return b, nil
+	return r.Body, nil
 }
@@ -1,8 +1,14 @@
 package client
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/theQRL/qrysm/v4/testing/require"
 )
@@ -46,3 +52,72 @@ func TestBaseURL(t *testing.T) {
 	require.Equal(t, "www.offchainlabs.com", cl.BaseURL().Hostname())
 	require.Equal(t, "3500", cl.BaseURL().Port())
 }
+
+func TestSend_Retries(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			_, err := w.Write([]byte("ok"))
+			require.NoError(t, err)
+		}
+	}))
+	defer srv.Close()
+
+	cl, err := NewClient(srv.URL, WithRetries(2, time.Millisecond))
+	require.NoError(t, err)
+	b, err := cl.Get(context.Background(), "/path")
+	require.NoError(t, err)
+	require.Equal(t, "ok", string(b))
+	require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	// Without retries, the first failure is returned.
+	atomic.StoreInt32(&calls, 0)
+	cl, err = NewClient(srv.URL)
+	require.NoError(t, err)
+	_, err = cl.Get(context.Background(), "/path")
+	require.ErrorIs(t, err, ErrNotOK)
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestSend_DoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	cl, err := NewClient(srv.URL, WithRetries(3, time.Millisecond))
+	require.NoError(t, err)
+	_, err = cl.Get(context.Background(), "/path")
+	require.ErrorIs(t, err, ErrNotFound)
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestPost(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/path", r.URL.Path)
+		require.Equal(t, "a=1&a=2", r.URL.RawQuery)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.Equal(t, "value", r.Header.Get("X-Custom"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, `["1"]`, string(body))
+		w.WriteHeader(http.StatusAccepted)
+		_, err = w.Write([]byte("accepted"))
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	cl, err := NewClient(srv.URL)
+	require.NoError(t, err)
+	b, err := cl.Post(context.Background(), "/path", []byte(`["1"]`), WithQuery(url.Values{"a": []string{"1", "2"}}), WithHeader("X-Custom", "value"))
+	require.NoError(t, err)
+	require.Equal(t, "accepted", string(b))
+}
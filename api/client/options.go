@@ -3,9 +3,12 @@ package client
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 )
 
+const jsonMediaType = "application/json"
+
 // ReqOption is a request functional option.
 type ReqOption func(*http.Request)
 
@@ -16,6 +19,34 @@ func WithSSZEncoding() ReqOption {
 	}
 }
 
+// WithJSONEncoding is a request functional option that adds JSON encoding header.
+func WithJSONEncoding() ReqOption {
+	return func(req *http.Request) {
+		req.Header.Set("Accept", jsonMediaType)
+	}
+}
+
+// WithContentType is a request functional option that sets the content type of the request body.
+func WithContentType(contentType string) ReqOption {
+	return func(req *http.Request) {
+		req.Header.Set("Content-Type", contentType)
+	}
+}
+
+// WithQuery is a request functional option that sets the query parameters of the request.
+func WithQuery(query url.Values) ReqOption {
+	return func(req *http.Request) {
+		req.URL.RawQuery = query.Encode()
+	}
+}
+
+// WithHeader is a request functional option that sets a header of the request.
+func WithHeader(key, value string) ReqOption {
+	return func(req *http.Request) {
+		req.Header.Set(key, value)
+	}
+}
+
 // WithAuthorizationToken is a request functional option that adds header for authorization token.
 func WithAuthorizationToken(token string) ReqOption {
 	return func(req *http.Request) {
@@ -46,3 +77,12 @@ func WithAuthenticationToken(token string) ClientOpt {
 		c.token = token
 	}
 }
+
+// WithRetries retries the requests failing because of a network error, a 429 or a 5xx status up to retries
+// times, waiting backoff before the first retry and a further backoff before each following retry.
+func WithRetries(retries int, backoff time.Duration) ClientOpt {
+	return func(c *Client) {
+		c.retries = retries
+		c.retryBackoff = backoff
+	}
+}
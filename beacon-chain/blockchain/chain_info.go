@@ -8,6 +8,7 @@ import (
 	"github.com/pkg/errors"
 	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
 	"github.com/theQRL/qrysm/v4/beacon-chain/core/helpers"
+	"github.com/theQRL/qrysm/v4/beacon-chain/forkchoice"
 	doublylinkedtree "github.com/theQRL/qrysm/v4/beacon-chain/forkchoice/doubly-linked-tree"
 	forkchoicetypes "github.com/theQRL/qrysm/v4/beacon-chain/forkchoice/types"
 	"github.com/theQRL/qrysm/v4/beacon-chain/state"
@@ -46,6 +47,7 @@ type ForkchoiceFetcher interface {
 	ReceivedBlocksLastEpoch() (uint64, error)
 	InsertNode(context.Context, state.BeaconState, [32]byte) error
 	ForkChoiceDump(context.Context) (*zondpbv1.ForkChoiceDump, error)
+	ForkChoiceHistory() forkchoice.HistoryExporter
 	NewSlot(context.Context, primitives.Slot) error
 	ProposerBoost() [32]byte
 }
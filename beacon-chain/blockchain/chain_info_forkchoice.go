@@ -3,6 +3,7 @@ package blockchain
 import (
 	"context"
 
+	"github.com/theQRL/qrysm/v4/beacon-chain/forkchoice"
 	"github.com/theQRL/qrysm/v4/beacon-chain/state"
 	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
 	zondpbv1 "github.com/theQRL/qrysm/v4/proto/zond/v1"
@@ -57,6 +58,13 @@ func (s *Service) ForkChoiceDump(ctx context.Context) (*zondpbv1.ForkChoiceDump,
 	return s.cfg.ForkChoiceStore.ForkChoiceDump(ctx)
 }
 
+// ForkChoiceHistory returns the corresponding value from forkchoice
+func (s *Service) ForkChoiceHistory() forkchoice.HistoryExporter {
+	s.cfg.ForkChoiceStore.RLock()
+	defer s.cfg.ForkChoiceStore.RUnlock()
+	return s.cfg.ForkChoiceStore.History()
+}
+
 // NewSlot returns the corresponding value from forkchoice
 func (s *Service) NewSlot(ctx context.Context, slot primitives.Slot) error {
 	s.cfg.ForkChoiceStore.Lock()
@@ -157,6 +157,14 @@ func WithSlasherAttestationsFeed(f *event.Feed) Option {
 	}
 }
 
+// WithNower sets the source of the current time of the service, which is the system time by default.
+func WithNower(now startup.Nower) Option {
+	return func(s *Service) error {
+		s.cfg.Nower = now
+		return nil
+	}
+}
+
 // WithFinalizedStateAtStartUp to store finalized state at start up.
 func WithFinalizedStateAtStartUp(st state.BeaconState) Option {
 	return func(s *Service) error {
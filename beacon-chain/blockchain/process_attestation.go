@@ -62,7 +62,7 @@ func (s *Service) OnAttestation(ctx context.Context, a *zondpb.Attestation, disp
 	genesisTime := uint64(s.genesisTime.Unix())
 
 	// Verify attestation target is from current epoch or previous epoch.
-	if err := verifyAttTargetEpoch(ctx, genesisTime, uint64(time.Now().Add(disparity).Unix()), tgt); err != nil {
+	if err := verifyAttTargetEpoch(ctx, genesisTime, uint64(s.now().Add(disparity).Unix()), tgt); err != nil {
 		return err
 	}
 
@@ -75,7 +75,7 @@ func (s *Service) OnAttestation(ctx context.Context, a *zondpb.Attestation, disp
 	// validate_aggregate_proof.go and validate_beacon_attestation.go
 
 	// Verify attestations can only affect the fork choice of subsequent slots.
-	if err := slots.VerifyTime(genesisTime, a.Data.Slot+1, disparity); err != nil {
+	if err := slots.VerifyTimeAt(genesisTime, a.Data.Slot+1, disparity, s.now()); err != nil {
 		return err
 	}
 
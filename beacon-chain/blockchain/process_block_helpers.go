@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/pkg/errors"
+	"github.com/theQRL/go-zond/common"
+	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositsnapshot"
 	doublylinkedtree "github.com/theQRL/qrysm/v4/beacon-chain/forkchoice/doubly-linked-tree"
 	forkchoicetypes "github.com/theQRL/qrysm/v4/beacon-chain/forkchoice/types"
 	"github.com/theQRL/qrysm/v4/beacon-chain/state"
@@ -21,7 +23,7 @@ import (
 
 // CurrentSlot returns the current slot based on time.
 func (s *Service) CurrentSlot() primitives.Slot {
-	return slots.CurrentSlot(uint64(s.genesisTime.Unix()))
+	return slots.Duration(s.genesisTime, s.now())
 }
 
 // getBlockPreState returns the pre state of an incoming block. It uses the parent root of the block
@@ -45,7 +47,7 @@ func (s *Service) getBlockPreState(ctx context.Context, b interfaces.ReadOnlyBea
 	}
 
 	// Verify block slot time is not from the future.
-	if err := slots.VerifyTime(uint64(s.genesisTime.Unix()), b.Slot(), params.BeaconNetworkConfig().MaximumGossipClockDisparity); err != nil {
+	if err := slots.VerifyTimeAt(uint64(s.genesisTime.Unix()), b.Slot(), params.BeaconNetworkConfig().MaximumGossipClockDisparity, s.now()); err != nil {
 		return nil, err
 	}
 
@@ -246,10 +248,51 @@ func (s *Service) insertFinalizedDeposits(ctx context.Context, fRoot [32]byte) {
 	// Prune deposits which have already been finalized, the below method prunes all pending deposits (non-inclusive) up
 	// to the provided eth1 deposit index.
 	s.cfg.DepositCache.PrunePendingDeposits(ctx, int64(eth1DepositIndex)) // lint:ignore uintcast -- Deposit index should not exceed int64 in your lifetime.
+	if err = s.saveDepositSnapshot(ctx, finalizedState); err != nil {
+		log.WithError(err).Warn("could not save deposit snapshot")
+	}
 
 	log.WithField("duration", time.Since(startTime).String()).Debug("Finalized deposit insertion completed")
 }
 
+// saveDepositSnapshot saves the snapshot of the finalized deposits trie, once every deposit of the
+// finalized state's eth1 data is finalized. The snapshot is then at the eth1 data's execution block.
+func (s *Service) saveDepositSnapshot(ctx context.Context, finalizedState state.ReadOnlyBeaconState) error {
+	eth1Data := finalizedState.Eth1Data()
+	if eth1Data == nil || eth1Data.DepositCount == 0 || finalizedState.Eth1DepositIndex() != eth1Data.DepositCount {
+		return nil
+	}
+	if s.cfg.ExecutionEngineCaller == nil {
+		return nil
+	}
+	saved, err := s.cfg.BeaconDB.DepositSnapshot(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not retrieve deposit snapshot")
+	}
+	if saved != nil && saved.DepositCount() >= eth1Data.DepositCount {
+		return nil
+	}
+	finalized := s.cfg.DepositCache.FinalizedDeposits(ctx)
+	if finalized.MerkleTrieIndex+1 != int64(eth1Data.DepositCount) { // lint:ignore uintcast -- Deposit count should not exceed int64 in your lifetime.
+		return nil
+	}
+	blk, err := s.cfg.ExecutionEngineCaller.ExecutionBlockByHash(ctx, common.BytesToHash(eth1Data.BlockHash), false /* no txs */)
+	if err != nil {
+		return errors.Wrapf(err, "could not get execution block %#x", eth1Data.BlockHash)
+	}
+	if blk == nil || blk.Number == nil {
+		return errors.Errorf("execution block %#x not found", eth1Data.BlockHash)
+	}
+	snapshot, err := depositsnapshot.SnapshotFromTrie(finalized.Deposits, eth1Data.DepositCount, bytesutil.ToBytes32(eth1Data.BlockHash), blk.Number.Uint64())
+	if err != nil {
+		return errors.Wrap(err, "could not build deposit snapshot")
+	}
+	if root := snapshot.DepositRoot(); root != bytesutil.ToBytes32(eth1Data.DepositRoot) {
+		return errors.Errorf("deposit snapshot root %#x does not match finalized deposit root %#x", root, eth1Data.DepositRoot)
+	}
+	return s.cfg.BeaconDB.SaveDepositSnapshot(ctx, snapshot)
+}
+
 // This ensures that the input root defaults to using genesis root instead of zero hashes. This is needed for handling
 // fork choice justification routine.
 func (s *Service) ensureRootNotZeros(root [32]byte) [32]byte {
@@ -31,6 +31,7 @@ import (
 	consensusblocks "github.com/theQRL/qrysm/v4/consensus-types/blocks"
 	"github.com/theQRL/qrysm/v4/consensus-types/interfaces"
 	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/container/trie"
 	"github.com/theQRL/qrysm/v4/crypto/bls"
 	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
 	enginev1 "github.com/theQRL/qrysm/v4/proto/engine/v1"
@@ -812,6 +813,56 @@ func TestInsertFinalizedDeposits_MultipleFinalizedRoutines(t *testing.T) {
 	}
 }
 
+func TestInsertFinalizedDeposits_SavesDepositSnapshot(t *testing.T) {
+	service, tr := minimalTestService(t)
+	ctx, depositCache := tr.ctx, tr.dc
+
+	var items [][]byte
+	for i := int64(0); i < 10; i++ {
+		data := &zondpb.Deposit_Data{
+			PublicKey:             bytesutil.PadTo([]byte{byte(i)}, dilithium2.CryptoPublicKeyBytes),
+			WithdrawalCredentials: params.BeaconConfig().ZeroHash[:],
+			Signature:             make([]byte, dilithium2.CryptoBytes),
+		}
+		h, err := data.HashTreeRoot()
+		require.NoError(t, err)
+		items = append(items, h[:])
+		require.NoError(t, depositCache.InsertDeposit(ctx, &zondpb.Deposit{Data: data}, 100+uint64(i), i, [32]byte{}))
+	}
+	depositTrie, err := trie.GenerateTrieFromItems(items, params.BeaconConfig().DepositContractTreeDepth)
+	require.NoError(t, err)
+	depositRoot, err := depositTrie.HashTreeRoot()
+	require.NoError(t, err)
+	blockHash := common.BytesToHash([]byte("eth1 block"))
+	e := &mockExecution.EngineClient{BlockByHashMap: map[[32]byte]*enginev1.ExecutionBlock{}}
+	e.BlockByHashMap[blockHash] = &enginev1.ExecutionBlock{Header: zondtypes.Header{Number: big.NewInt(109)}}
+	service.cfg.ExecutionEngineCaller = e
+
+	gs, _ := util.DeterministicGenesisState(t, 32)
+	require.NoError(t, service.saveGenesisData(ctx, gs))
+	// No snapshot is saved while some deposits of the eth1 data are not included.
+	gs = gs.Copy()
+	require.NoError(t, gs.SetEth1Data(&zondpb.Eth1Data{DepositRoot: depositRoot[:], DepositCount: 10, BlockHash: blockHash[:]}))
+	require.NoError(t, gs.SetEth1DepositIndex(8))
+	require.NoError(t, service.cfg.StateGen.SaveState(ctx, [32]byte{'m', 'o', 'c', 'k'}, gs))
+	service.insertFinalizedDeposits(ctx, [32]byte{'m', 'o', 'c', 'k'})
+	snapshot, err := service.cfg.BeaconDB.DepositSnapshot(ctx)
+	require.NoError(t, err)
+	require.Equal(t, true, snapshot == nil)
+
+	gs = gs.Copy()
+	require.NoError(t, gs.SetEth1DepositIndex(10))
+	require.NoError(t, service.cfg.StateGen.SaveState(ctx, [32]byte{'m', 'o', 'c', 'k', '2'}, gs))
+	service.insertFinalizedDeposits(ctx, [32]byte{'m', 'o', 'c', 'k', '2'})
+	snapshot, err = service.cfg.BeaconDB.DepositSnapshot(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, snapshot)
+	assert.Equal(t, uint64(10), snapshot.DepositCount())
+	assert.Equal(t, depositRoot, snapshot.DepositRoot())
+	assert.Equal(t, [32]byte(blockHash), snapshot.ExecutionBlockHash())
+	assert.Equal(t, uint64(109), snapshot.ExecutionBlockHeight())
+}
+
 func TestRemoveBlockAttestationsInPool(t *testing.T) {
 	genesis, keys := util.DeterministicGenesisState(t, 64)
 	b, err := util.GenerateFullBlock(genesis, keys, util.DefaultBlockGenConfig(), 1)
@@ -86,6 +86,7 @@ type config struct {
 	BlockFetcher            execution.POWBlockFetcher
 	FinalizedStateAtStartUp state.BeaconState
 	ExecutionEngineCaller   execution.EngineCaller
+	Nower                   startup.Nower
 }
 
 var ErrMissingClockSetter = errors.New("blockchain Service initialized without a startup.ClockSetter")
@@ -247,7 +248,7 @@ func (s *Service) StartFromSavedState(saved state.BeaconState) error {
 	}
 
 	vr := bytesutil.ToBytes32(saved.GenesisValidatorsRoot())
-	if err := s.clockSetter.SetClock(startup.NewClock(s.genesisTime, vr)); err != nil {
+	if err := s.clockSetter.SetClock(startup.NewClock(s.genesisTime, vr, startup.WithNower(s.now))); err != nil {
 		return errors.Wrap(err, "failed to initialize blockchain service")
 	}
 
@@ -373,7 +374,7 @@ func (s *Service) onExecutionChainStart(ctx context.Context, genesisTime time.Ti
 	go slots.CountdownToGenesis(ctx, genesisTime, uint64(initializedState.NumValidators()), gRoot)
 
 	vr := bytesutil.ToBytes32(initializedState.GenesisValidatorsRoot())
-	if err := s.clockSetter.SetClock(startup.NewClock(genesisTime, vr)); err != nil {
+	if err := s.clockSetter.SetClock(startup.NewClock(genesisTime, vr, startup.WithNower(s.now))); err != nil {
 		log.WithError(err).Fatal("failed to initialize blockchain service from execution start event")
 	}
 }
@@ -472,6 +473,14 @@ func (s *Service) hasBlock(ctx context.Context, root [32]byte) bool {
 	return s.cfg.BeaconDB.HasBlock(ctx, root)
 }
 
+// now returns the current time of the service.
+func (s *Service) now() time.Time {
+	if s.cfg.Nower != nil {
+		return s.cfg.Nower()
+	}
+	return prysmTime.Now()
+}
+
 func spawnCountdownIfPreGenesis(ctx context.Context, genesisTime time.Time, db db.HeadAccessDatabase) {
 	currentTime := prysmTime.Now()
 	if currentTime.After(genesisTime) {
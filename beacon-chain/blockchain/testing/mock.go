@@ -564,6 +564,14 @@ func (s *ChainService) InsertNode(ctx context.Context, st state.BeaconState, roo
 	return nil
 }
 
+// ForkChoiceHistory mocks the same method in the chain service
+func (s *ChainService) ForkChoiceHistory() forkchoice.HistoryExporter {
+	if s.ForkChoiceStore != nil {
+		return s.ForkChoiceStore.History()
+	}
+	return nil
+}
+
 // ForkChoiceDump mocks the same method in the chain service
 func (s *ChainService) ForkChoiceDump(ctx context.Context) (*zondpbv1.ForkChoiceDump, error) {
 	if s.ForkChoiceStore != nil {
@@ -27,4 +27,33 @@ var (
 			Buckets: []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000},
 		},
 	)
+	relayGetHeaderLatency = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "relay_get_header_latency_milliseconds",
+			Help:    "Captures RPC latency for get header of each relay in milliseconds",
+			Buckets: []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000},
+		},
+		[]string{"relay"},
+	)
+	relayErrors = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "relay_errors_total",
+			Help: "The number of failed calls to each relay, including invalid bids",
+		},
+		[]string{"relay", "method"},
+	)
+	relayBidWins = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "relay_bid_wins_total",
+			Help: "The number of times the bid of each relay had the highest value",
+		},
+		[]string{"relay"},
+	)
+	relayDisabledCount = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "relay_disabled_total",
+			Help: "The number of times each relay was disabled for failing to reveal a payload",
+		},
+		[]string{"relay"},
+	)
 )
@@ -13,25 +13,32 @@ type Option func(s *Service) error
 
 // FlagOptions for builder service flag configurations.
 func FlagOptions(c *cli.Context) ([]Option, error) {
-	endpoint := c.String(flags.MevRelayEndpoint.Name)
-	var client *builder.Client
-	if endpoint != "" {
-		var err error
-		client, err = builder.NewClient(endpoint)
+	var clients []builder.BuilderClient
+	for _, endpoint := range c.StringSlice(flags.MevRelayEndpoint.Name) {
+		if endpoint == "" {
+			continue
+		}
+		client, err := builder.NewClient(endpoint)
 		if err != nil {
 			return nil, err
 		}
+		clients = append(clients, client)
 	}
 	opts := []Option{
-		WithBuilderClient(client),
+		WithBuilderClients(clients...),
 	}
 	return opts, nil
 }
 
-// WithBuilderClient sets the builder client for the beacon chain builder service.
+// WithBuilderClient adds a builder client for the beacon chain builder service.
 func WithBuilderClient(client builder.BuilderClient) Option {
+	return WithBuilderClients(client)
+}
+
+// WithBuilderClients adds several builder clients, one per relay, for the beacon chain builder service.
+func WithBuilderClients(clients ...builder.BuilderClient) Option {
 	return func(s *Service) error {
-		s.cfg.builderClient = client
+		s.cfg.builderClients = append(s.cfg.builderClients, clients...)
 		return nil
 	}
 }
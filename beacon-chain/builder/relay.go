@@ -0,0 +1,217 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/api/client/builder"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/signing"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+)
+
+// misbehavingRelayCooldown is how long a relay is left out of block building after it failed to
+// reveal the payload of one of its winning bids.
+const misbehavingRelayCooldown = time.Hour
+
+// getHeaderTimeout bounds the time spent waiting for bids when the caller sets no deadline.
+const getHeaderTimeout = time.Second
+
+// relay is a builder relay along with the statistics of its bids.
+type relay struct {
+	c builder.BuilderClient
+
+	sync.RWMutex
+	bids          uint64
+	wins          uint64
+	errors        uint64
+	totalLatency  time.Duration
+	disabledUntil time.Time
+}
+
+// RelayStatus is a snapshot of the statistics of a relay.
+type RelayStatus struct {
+	Endpoint string
+	// Bids is the number of headers requested from the relay.
+	Bids uint64
+	// Wins is the number of valid bids of the relay that had the highest value.
+	Wins uint64
+	// Errors is the number of failed requests and invalid bids.
+	Errors         uint64
+	AverageLatency time.Duration
+	Disabled       bool
+}
+
+// WinRate is the fraction of the requested headers for which the relay had the winning bid.
+func (r RelayStatus) WinRate() float64 {
+	if r.Bids == 0 {
+		return 0
+	}
+	return float64(r.Wins) / float64(r.Bids)
+}
+
+func (r *relay) endpoint() string {
+	return r.c.NodeURL()
+}
+
+func (r *relay) enabled(now time.Time) bool {
+	r.RLock()
+	defer r.RUnlock()
+	return !now.Before(r.disabledUntil)
+}
+
+func (r *relay) disable(now time.Time) {
+	r.Lock()
+	defer r.Unlock()
+	r.disabledUntil = now.Add(misbehavingRelayCooldown)
+}
+
+func (r *relay) recordBid(latency time.Duration, err error) {
+	relayGetHeaderLatency.WithLabelValues(r.endpoint()).Observe(float64(latency.Milliseconds()))
+	if err != nil {
+		relayErrors.WithLabelValues(r.endpoint(), "get_header").Inc()
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.bids++
+	r.totalLatency += latency
+	if err != nil {
+		r.errors++
+	}
+}
+
+func (r *relay) recordWin() {
+	relayBidWins.WithLabelValues(r.endpoint()).Inc()
+	r.Lock()
+	defer r.Unlock()
+	r.wins++
+}
+
+func (r *relay) status(now time.Time) RelayStatus {
+	r.RLock()
+	defer r.RUnlock()
+	s := RelayStatus{
+		Endpoint: r.endpoint(),
+		Bids:     r.bids,
+		Wins:     r.wins,
+		Errors:   r.errors,
+		Disabled: now.Before(r.disabledUntil),
+	}
+	if r.bids > 0 {
+		s.AverageLatency = r.totalLatency / time.Duration(r.bids)
+	}
+	return s
+}
+
+// relayBid is a bid received from a relay, with its value.
+type relayBid struct {
+	relay *relay
+	bid   builder.SignedBid
+	value *big.Int
+	err   error
+}
+
+// getHeader requests a header from the relay and validates the returned bid against the parent hash.
+func (r *relay) getHeader(ctx context.Context, slot primitives.Slot, parentHash [32]byte, pubKey [dilithium2.CryptoPublicKeyBytes]byte) *relayBid {
+	start := time.Now()
+	signedBid, err := r.c.GetHeader(ctx, slot, parentHash, pubKey)
+	var v *big.Int
+	if err == nil {
+		v, err = validateBid(signedBid, parentHash)
+	}
+	r.recordBid(time.Since(start), err)
+	return &relayBid{relay: r, bid: signedBid, value: v, err: err}
+}
+
+// validateBid checks the signature and the parent hash of a bid, and returns its value.
+func validateBid(signedBid builder.SignedBid, parentHash [32]byte) (*big.Int, error) {
+	if signedBid == nil || signedBid.IsNil() {
+		return nil, errors.New("builder returned nil bid")
+	}
+	bid, err := signedBid.Message()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get bid")
+	}
+	if bid == nil || bid.IsNil() {
+		return nil, errors.New("builder returned nil bid")
+	}
+	v := bytesutil.LittleEndianBytesToBigInt(bid.Value())
+	if v.Sign() == 0 {
+		return nil, errors.New("builder returned header with 0 bid amount")
+	}
+	header, err := bid.Header()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get bid header")
+	}
+	if !bytes.Equal(header.ParentHash(), parentHash[:]) {
+		return nil, fmt.Errorf("incorrect parent hash %#x != %#x", header.ParentHash(), parentHash)
+	}
+	d, err := signing.ComputeDomain(params.BeaconConfig().DomainApplicationBuilder,
+		nil, /* fork version */
+		nil /* genesis val root */)
+	if err != nil {
+		return nil, err
+	}
+	if err := signing.VerifySigningRoot(bid, bid.Pubkey(), signedBid.Signature(), d); err != nil {
+		return nil, errors.Wrap(err, "could not validate builder signature")
+	}
+	return v, nil
+}
+
+// bestBid returns the valid bid with the highest value. Ties go to the relay configured first.
+func bestBid(bids []*relayBid) *relayBid {
+	var best *relayBid
+	for _, b := range bids {
+		if b == nil || b.err != nil {
+			continue
+		}
+		if best == nil || b.value.Cmp(best.value) > 0 {
+			best = b
+		}
+	}
+	return best
+}
+
+// winningRelays remembers which relay provided the header of each recent winning bid, so that
+// the blinded block is submitted to the relay that holds its payload.
+type winningRelays struct {
+	sync.Mutex
+	bySlot map[primitives.Slot]map[[32]byte]*relay
+}
+
+func (w *winningRelays) add(slot primitives.Slot, blockHash [32]byte, r *relay) {
+	w.Lock()
+	defer w.Unlock()
+	if w.bySlot == nil {
+		w.bySlot = make(map[primitives.Slot]map[[32]byte]*relay)
+	}
+	// Winners older than an epoch can no longer be proposed.
+	for s := range w.bySlot {
+		if s+params.BeaconConfig().SlotsPerEpoch < slot {
+			delete(w.bySlot, s)
+		}
+	}
+	if w.bySlot[slot] == nil {
+		w.bySlot[slot] = make(map[[32]byte]*relay)
+	}
+	w.bySlot[slot][blockHash] = r
+}
+
+func (w *winningRelays) get(slot primitives.Slot, blockHash [32]byte) *relay {
+	w.Lock()
+	defer w.Unlock()
+	return w.bySlot[slot][blockHash]
+}
+
+func logRelayError(r *relay, method string, err error) {
+	relayErrors.WithLabelValues(r.endpoint(), method).Inc()
+	log.WithError(err).WithField("endpoint", r.endpoint()).Warnf("Relay call %s failed", method)
+}
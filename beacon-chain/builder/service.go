@@ -3,6 +3,7 @@ package builder
 import (
 	"context"
 	"reflect"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -34,15 +35,17 @@ type BlockBuilder interface {
 
 // config defines a config struct for dependencies into the service.
 type config struct {
-	builderClient builder.BuilderClient
-	beaconDB      db.HeadAccessDatabase
-	headFetcher   blockchain.HeadFetcher
+	builderClients []builder.BuilderClient
+	beaconDB       db.HeadAccessDatabase
+	headFetcher    blockchain.HeadFetcher
 }
 
 // Service defines a service that provides a client for interacting with the beacon chain and MEV relay network.
+// Headers are requested from every configured relay, and the valid bid with the highest value wins.
 type Service struct {
 	cfg               *config
-	c                 builder.BuilderClient
+	relays            []*relay
+	winners           winningRelays
 	ctx               context.Context
 	cancel            context.CancelFunc
 	registrationCache *cache.RegistrationCache
@@ -61,18 +64,23 @@ func NewService(ctx context.Context, opts ...Option) (*Service, error) {
 			return nil, err
 		}
 	}
-	if s.cfg.builderClient != nil && !reflect.ValueOf(s.cfg.builderClient).IsNil() {
-		s.c = s.cfg.builderClient
+	for _, c := range s.cfg.builderClients {
+		if c == nil || reflect.ValueOf(c).IsNil() {
+			continue
+		}
+		s.relays = append(s.relays, &relay{c: c})
 
 		// Is the builder up?
-		if err := s.c.Status(ctx); err != nil {
-			log.WithError(err).Error("Failed to check builder status")
+		if err := c.Status(ctx); err != nil {
+			log.WithError(err).WithField("endpoint", c.NodeURL()).Error("Failed to check builder status")
 		} else {
-			log.WithField("endpoint", s.c.NodeURL()).Info("Builder has been configured")
-			log.Warn("Outsourcing block construction to external builders adds non-trivial delay to block propagation time.  " +
-				"Builder-constructed blocks or fallback blocks may get orphaned. Use at your own risk!")
+			log.WithField("endpoint", c.NodeURL()).Info("Builder has been configured")
 		}
 	}
+	if len(s.relays) > 0 {
+		log.Warn("Outsourcing block construction to external builders adds non-trivial delay to block propagation time.  " +
+			"Builder-constructed blocks or fallback blocks may get orphaned. Use at your own risk!")
+	}
 	return s, nil
 }
 
@@ -87,7 +95,9 @@ func (s *Service) Stop() error {
 	return nil
 }
 
-// SubmitBlindedBlock submits a blinded block to the builder relay network.
+// SubmitBlindedBlock submits a blinded block to the relay whose bid provided its header. A relay that
+// fails to reveal the payload of its own winning bid is disabled for a while. If the relay of the
+// header is unknown, the block is submitted to each enabled relay until one reveals the payload.
 func (s *Service) SubmitBlindedBlock(ctx context.Context, b interfaces.ReadOnlySignedBeaconBlock) (interfaces.ExecutionData, error) {
 	ctx, span := trace.StartSpan(ctx, "builder.SubmitBlindedBlock")
 	defer span.End()
@@ -95,14 +105,47 @@ func (s *Service) SubmitBlindedBlock(ctx context.Context, b interfaces.ReadOnlyS
 	defer func() {
 		submitBlindedBlockLatency.Observe(float64(time.Since(start).Milliseconds()))
 	}()
-	if s.c == nil {
+	if !s.Configured() {
 		return nil, ErrNoBuilder
 	}
+	if b == nil || b.IsNil() {
+		return nil, errors.New("nil blinded block")
+	}
+	h, err := b.Block().Body().Execution()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get execution header")
+	}
 
-	return s.c.SubmitBlindedBlock(ctx, b)
+	if r := s.winners.get(b.Block().Slot(), bytesutil.ToBytes32(h.BlockHash())); r != nil {
+		payload, err := r.c.SubmitBlindedBlock(ctx, b)
+		if err != nil {
+			logRelayError(r, "submit_blinded_block", err)
+			s.disableRelay(r)
+			return nil, err
+		}
+		return payload, nil
+	}
+
+	var lastErr error
+	for _, r := range s.enabledRelays() {
+		payload, err := r.c.SubmitBlindedBlock(ctx, b)
+		if err != nil {
+			logRelayError(r, "submit_blinded_block", err)
+			lastErr = err
+			continue
+		}
+		return payload, nil
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no enabled relay")
+	}
+	return nil, lastErr
 }
 
 // GetHeader retrieves the header for a given slot and parent hash from the builder relay network.
+// Every enabled relay is queried concurrently until the deadline of the context, and the bid with
+// the highest value among those with a valid signature and parent hash is returned. The proposer
+// then weighs it against the local payload with the local block value boost.
 func (s *Service) GetHeader(ctx context.Context, slot primitives.Slot, parentHash [32]byte, pubKey [dilithium2.CryptoPublicKeyBytes]byte) (builder.SignedBid, error) {
 	ctx, span := trace.StartSpan(ctx, "builder.GetHeader")
 	defer span.End()
@@ -110,28 +153,82 @@ func (s *Service) GetHeader(ctx context.Context, slot primitives.Slot, parentHas
 	defer func() {
 		getHeaderLatency.Observe(float64(time.Since(start).Milliseconds()))
 	}()
-	if s.c == nil {
+	if !s.Configured() {
 		tracing.AnnotateError(span, ErrNoBuilder)
 		return nil, ErrNoBuilder
 	}
+	relays := s.enabledRelays()
+	if len(relays) == 0 {
+		err := errors.New("all relays are disabled")
+		tracing.AnnotateError(span, err)
+		return nil, err
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, getHeaderTimeout)
+		defer cancel()
+	}
+
+	results := make(chan *relayBid, len(relays))
+	for _, r := range relays {
+		go func(r *relay) {
+			results <- r.getHeader(ctx, slot, parentHash, pubKey)
+		}(r)
+	}
+	bids := make([]*relayBid, len(relays))
+	index := make(map[*relay]int, len(relays))
+	for i, r := range relays {
+		index[r] = i
+	}
+	var lastErr error
+wait:
+	for received := 0; received < len(relays); received++ {
+		select {
+		case b := <-results:
+			bids[index[b.relay]] = b
+			if b.err != nil {
+				lastErr = b.err
+				log.WithError(b.err).WithField("endpoint", b.relay.endpoint()).Debug("Could not get header from relay")
+			}
+		case <-ctx.Done():
+			break wait
+		}
+	}
 
-	h, err := s.c.GetHeader(ctx, slot, parentHash, pubKey)
-	tracing.AnnotateError(span, err)
-	return h, err
+	best := bestBid(bids)
+	if best == nil {
+		if lastErr == nil {
+			lastErr = errors.Wrap(ctx.Err(), "no relay returned a bid in time")
+		}
+		tracing.AnnotateError(span, lastErr)
+		return nil, lastErr
+	}
+	best.relay.recordWin()
+	bid, err := best.bid.Message()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get bid")
+	}
+	header, err := bid.Header()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get bid header")
+	}
+	s.winners.add(slot, bytesutil.ToBytes32(header.BlockHash()), best.relay)
+	span.AddAttributes(trace.StringAttribute("relay", best.relay.endpoint()))
+	return best.bid, nil
 }
 
 // Status retrieves the status of the builder relay network.
 func (s *Service) Status() error {
 	// Return early if builder isn't initialized in service.
-	if s.c == nil {
+	if !s.Configured() {
 		return nil
 	}
 
 	return nil
 }
 
-// RegisterValidator registers a validator with the builder relay network.
-// It also saves the registration object to the DB.
+// RegisterValidator registers a validator with every relay of the builder relay network.
+// It also saves the registration object to the DB. It fails only if no relay accepted the registrations.
 func (s *Service) RegisterValidator(ctx context.Context, reg []*zondpb.SignedValidatorRegistrationV1) error {
 	ctx, span := trace.StartSpan(ctx, "builder.RegisterValidator")
 	defer span.End()
@@ -139,7 +236,7 @@ func (s *Service) RegisterValidator(ctx context.Context, reg []*zondpb.SignedVal
 	defer func() {
 		registerValidatorLatency.Observe(float64(time.Since(start).Milliseconds()))
 	}()
-	if s.c == nil {
+	if !s.Configured() {
 		return ErrNoBuilder
 	}
 
@@ -164,7 +261,7 @@ func (s *Service) RegisterValidator(ctx context.Context, reg []*zondpb.SignedVal
 		valid = append(valid, r)
 		indexToRegistration[nx] = r.Message
 	}
-	if err := s.c.RegisterValidator(ctx, valid); err != nil {
+	if err := s.registerWithRelays(ctx, valid); err != nil {
 		return errors.Wrap(err, "could not register validator(s)")
 	}
 
@@ -193,7 +290,59 @@ func (s *Service) RegistrationByValidatorID(ctx context.Context, id primitives.V
 
 // Configured returns true if the user has configured a builder client.
 func (s *Service) Configured() bool {
-	return s.c != nil && !reflect.ValueOf(s.c).IsNil()
+	return len(s.relays) > 0
+}
+
+// Relays returns the statistics of every configured relay.
+func (s *Service) Relays() []RelayStatus {
+	now := time.Now()
+	statuses := make([]RelayStatus, len(s.relays))
+	for i, r := range s.relays {
+		statuses[i] = r.status(now)
+	}
+	return statuses
+}
+
+func (s *Service) enabledRelays() []*relay {
+	now := time.Now()
+	enabled := make([]*relay, 0, len(s.relays))
+	for _, r := range s.relays {
+		if r.enabled(now) {
+			enabled = append(enabled, r)
+		}
+	}
+	return enabled
+}
+
+func (s *Service) disableRelay(r *relay) {
+	r.disable(time.Now())
+	relayDisabledCount.WithLabelValues(r.endpoint()).Inc()
+	log.WithFields(log.Fields{
+		"endpoint": r.endpoint(),
+		"cooldown": misbehavingRelayCooldown,
+	}).Error("Relay failed to reveal the payload of its winning bid, disabling it")
+}
+
+func (s *Service) registerWithRelays(ctx context.Context, reg []*zondpb.SignedValidatorRegistrationV1) error {
+	errs := make([]error, len(s.relays))
+	var wg sync.WaitGroup
+	for i, r := range s.relays {
+		wg.Add(1)
+		go func(i int, r *relay) {
+			defer wg.Done()
+			if err := r.c.RegisterValidator(ctx, reg); err != nil {
+				logRelayError(r, "register_validator", err)
+				errs[i] = err
+			}
+		}(i, r)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err == nil {
+			return nil
+		}
+	}
+	return errs[len(errs)-1]
 }
 
 func (s *Service) pollRelayerStatus(ctx context.Context) {
@@ -202,9 +351,9 @@ func (s *Service) pollRelayerStatus(ctx context.Context) {
 	for {
 		select {
 		case <-ticker.C:
-			if s.c != nil {
-				if err := s.c.Status(ctx); err != nil {
-					log.WithError(err).Error("Failed to call relayer status endpoint, perhaps mev-boost or relayers are down")
+			for _, r := range s.relays {
+				if err := r.c.Status(ctx); err != nil {
+					log.WithError(err).WithField("endpoint", r.endpoint()).Error("Failed to call relayer status endpoint, perhaps mev-boost or relayers are down")
 				}
 			}
 		case <-ctx.Done():
@@ -2,17 +2,28 @@ package builder
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/api/client/builder"
 	buildertesting "github.com/theQRL/qrysm/v4/api/client/builder/testing"
 	blockchainTesting "github.com/theQRL/qrysm/v4/beacon-chain/blockchain/testing"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/signing"
 	dbtesting "github.com/theQRL/qrysm/v4/beacon-chain/db/testing"
+	fieldparams "github.com/theQRL/qrysm/v4/config/fieldparams"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/blocks"
+	"github.com/theQRL/qrysm/v4/consensus-types/interfaces"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/crypto/dilithium"
 	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	v1 "github.com/theQRL/qrysm/v4/proto/engine/v1"
 	zond "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
 	"github.com/theQRL/qrysm/v4/testing/assert"
 	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/util"
 )
 
 func Test_NewServiceWithBuilder(t *testing.T) {
@@ -69,3 +80,140 @@ func Test_BuilderMethodsWithouClient(t *testing.T) {
 	err = s.RegisterValidator(context.Background(), nil)
 	assert.ErrorContains(t, ErrNoBuilder.Error(), err)
 }
+
+type relayClient struct {
+	buildertesting.MockClient
+	url       string
+	bid       *zond.SignedBuilderBidCapella
+	submitErr error
+	submitted int
+}
+
+func (c *relayClient) NodeURL() string {
+	return c.url
+}
+
+func (c *relayClient) GetHeader(_ context.Context, _ primitives.Slot, _ [32]byte, _ [dilithium2.CryptoPublicKeyBytes]byte) (builder.SignedBid, error) {
+	if c.bid == nil {
+		return nil, errors.New("no bid")
+	}
+	return builder.WrappedSignedBuilderBidCapella(c.bid)
+}
+
+func (c *relayClient) SubmitBlindedBlock(_ context.Context, _ interfaces.ReadOnlySignedBeaconBlock) (interfaces.ExecutionData, error) {
+	c.submitted++
+	if c.submitErr != nil {
+		return nil, c.submitErr
+	}
+	return blocks.WrappedExecutionPayloadCapella(&v1.ExecutionPayloadCapella{}, 0)
+}
+
+func signedBid(t *testing.T, parentHash, blockHash [32]byte, value uint64) *zond.SignedBuilderBidCapella {
+	sk, err := dilithium.RandKey()
+	require.NoError(t, err)
+	bid := &zond.BuilderBidCapella{
+		Header: &v1.ExecutionPayloadHeaderCapella{
+			ParentHash:       parentHash[:],
+			FeeRecipient:     make([]byte, fieldparams.FeeRecipientLength),
+			StateRoot:        make([]byte, fieldparams.RootLength),
+			ReceiptsRoot:     make([]byte, fieldparams.RootLength),
+			LogsBloom:        make([]byte, fieldparams.LogsBloomLength),
+			PrevRandao:       make([]byte, fieldparams.RootLength),
+			BaseFeePerGas:    make([]byte, fieldparams.RootLength),
+			BlockHash:        blockHash[:],
+			TransactionsRoot: make([]byte, fieldparams.RootLength),
+			WithdrawalsRoot:  make([]byte, fieldparams.RootLength),
+		},
+		Pubkey: sk.PublicKey().Marshal(),
+		Value:  bytesutil.PadTo(bytesutil.Uint64ToBytesLittleEndian(value), 32),
+	}
+	domain, err := signing.ComputeDomain(params.BeaconConfig().DomainApplicationBuilder, nil, nil)
+	require.NoError(t, err)
+	sr, err := signing.ComputeSigningRoot(bid, domain)
+	require.NoError(t, err)
+	return &zond.SignedBuilderBidCapella{Message: bid, Signature: sk.Sign(sr[:]).Marshal()}
+}
+
+func Test_GetHeader_MultipleRelays(t *testing.T) {
+	ctx := context.Background()
+	parentHash := [32]byte{'p'}
+	low := &relayClient{url: "low", bid: signedBid(t, parentHash, [32]byte{'l'}, 1)}
+	high := &relayClient{url: "high", bid: signedBid(t, parentHash, [32]byte{'h'}, 3)}
+	wrongParent := &relayClient{url: "wrong-parent", bid: signedBid(t, [32]byte{'x'}, [32]byte{'w'}, 5)}
+	badSignature := &relayClient{url: "bad-signature", bid: signedBid(t, parentHash, [32]byte{'s'}, 7)}
+	badSignature.bid.Message.Value = bytesutil.PadTo([]byte{8}, 32)
+	failing := &relayClient{url: "failing"}
+	s, err := NewService(ctx, WithBuilderClients(low, high, wrongParent, badSignature, failing))
+	require.NoError(t, err)
+
+	sBid, err := s.GetHeader(ctx, 1, parentHash, [dilithium2.CryptoPublicKeyBytes]byte{})
+	require.NoError(t, err)
+	bid, err := sBid.Message()
+	require.NoError(t, err)
+	assert.DeepEqual(t, high.bid.Message.Value, bid.Value())
+
+	statuses := s.Relays()
+	require.Equal(t, 5, len(statuses))
+	for _, st := range statuses {
+		assert.Equal(t, uint64(1), st.Bids, st.Endpoint)
+		switch st.Endpoint {
+		case "high":
+			assert.Equal(t, uint64(1), st.Wins)
+			assert.Equal(t, float64(1), st.WinRate())
+			assert.Equal(t, uint64(0), st.Errors)
+		case "low":
+			assert.Equal(t, uint64(0), st.Wins)
+			assert.Equal(t, uint64(0), st.Errors)
+		default:
+			assert.Equal(t, uint64(0), st.Wins, st.Endpoint)
+			assert.Equal(t, uint64(1), st.Errors, st.Endpoint)
+		}
+	}
+}
+
+func Test_SubmitBlindedBlock_DisablesMisbehavingRelay(t *testing.T) {
+	ctx := context.Background()
+	parentHash := [32]byte{'p'}
+	low := &relayClient{url: "low", bid: signedBid(t, parentHash, [32]byte{'l'}, 1)}
+	high := &relayClient{url: "high", bid: signedBid(t, parentHash, [32]byte{'h'}, 3), submitErr: errors.New("withheld")}
+	s, err := NewService(ctx, WithBuilderClients(low, high))
+	require.NoError(t, err)
+
+	_, err = s.GetHeader(ctx, 1, parentHash, [dilithium2.CryptoPublicKeyBytes]byte{})
+	require.NoError(t, err)
+	b := util.NewBlindedBeaconBlockCapella()
+	b.Block.Slot = 1
+	b.Block.Body.ExecutionPayloadHeader.BlockHash = high.bid.Message.Header.BlockHash
+	blk, err := blocks.NewSignedBeaconBlock(b)
+	require.NoError(t, err)
+	_, err = s.SubmitBlindedBlock(ctx, blk)
+	require.ErrorContains(t, "withheld", err)
+	assert.Equal(t, 1, high.submitted)
+	assert.Equal(t, 0, low.submitted)
+
+	statuses := s.Relays()
+	assert.Equal(t, false, statuses[0].Disabled)
+	assert.Equal(t, true, statuses[1].Disabled)
+
+	// The disabled relay is no longer queried.
+	sBid, err := s.GetHeader(ctx, 2, parentHash, [dilithium2.CryptoPublicKeyBytes]byte{})
+	require.NoError(t, err)
+	bid, err := sBid.Message()
+	require.NoError(t, err)
+	assert.DeepEqual(t, low.bid.Message.Value, bid.Value())
+	assert.Equal(t, uint64(1), s.Relays()[1].Bids)
+}
+
+func Test_RegisterValidator_MultipleRelays(t *testing.T) {
+	ctx := context.Background()
+	headFetcher := &blockchainTesting.ChainService{}
+	first := buildertesting.NewClient()
+	second := buildertesting.NewClient()
+	s, err := NewService(ctx, WithRegistrationCache(), WithHeadFetcher(headFetcher), WithBuilderClients(&first, &second))
+	require.NoError(t, err)
+	pubkey := bytesutil.ToBytes48([]byte("pubkey"))
+	var feeRecipient [20]byte
+	require.NoError(t, s.RegisterValidator(ctx, []*zond.SignedValidatorRegistrationV1{{Message: &zond.ValidatorRegistrationV1{Pubkey: pubkey[:], FeeRecipient: feeRecipient[:]}}}))
+	assert.Equal(t, true, first.RegisteredVals[pubkey])
+	assert.Equal(t, true, second.RegisteredVals[pubkey])
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/sirupsen/logrus"
 	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositsnapshot"
 	"github.com/theQRL/qrysm/v4/config/params"
 	"github.com/theQRL/qrysm/v4/container/trie"
 	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
@@ -55,6 +56,10 @@ type DepositCache struct {
 	finalizedDeposits *FinalizedDeposits
 	depositsByKey     map[[dilithium2.CryptoPublicKeyBytes]byte][]*zondpb.DepositContainer
 	depositsLock      sync.RWMutex
+	// firstIndex is the index of the first deposit in the cache. It is only non-zero when the cache
+	// was started from a deposit snapshot, whose deposits are not held by the cache.
+	firstIndex int64
+	snapshot   *depositsnapshot.DepositTreeSnapshot
 }
 
 // New instantiates a new deposit cache
@@ -91,8 +96,8 @@ func (dc *DepositCache) InsertDeposit(ctx context.Context, d *zondpb.Deposit, bl
 	dc.depositsLock.Lock()
 	defer dc.depositsLock.Unlock()
 
-	if int(index) != len(dc.deposits) {
-		return errors.Errorf("wanted deposit with index %d to be inserted but received %d", len(dc.deposits), index)
+	if wanted := dc.firstIndex + int64(len(dc.deposits)); index != wanted {
+		return errors.Errorf("wanted deposit with index %d to be inserted but received %d", wanted, index)
 	}
 	// Keep the slice sorted on insertion in order to avoid costly sorting on retrieval.
 	heightIdx := sort.Search(len(dc.deposits), func(i int) bool { return dc.deposits[i].Index >= index })
@@ -128,6 +133,47 @@ func (dc *DepositCache) InsertDepositContainers(ctx context.Context, ctrs []*zon
 	historicalDepositsCount.Add(float64(len(ctrs)))
 }
 
+// InsertDepositSnapshot starts the finalized deposits trie from a deposit snapshot. The deposits covered
+// by the snapshot are dropped from the cache, which then only holds the deposits following them.
+func (dc *DepositCache) InsertDepositSnapshot(ctx context.Context, snapshot *depositsnapshot.DepositTreeSnapshot) error {
+	ctx, span := trace.StartSpan(ctx, "DepositsCache.InsertDepositSnapshot")
+	defer span.End()
+	if snapshot == nil {
+		return errors.New("nil deposit snapshot inserted into the cache")
+	}
+	dc.depositsLock.Lock()
+	defer dc.depositsLock.Unlock()
+
+	if dc.finalizedDeposits.MerkleTrieIndex >= 0 {
+		return errors.Errorf("cannot start from a deposit snapshot after finalizing deposits up to index %d",
+			dc.finalizedDeposits.MerkleTrieIndex)
+	}
+	depositTrie, err := snapshot.ToTrie()
+	if err != nil {
+		return errors.Wrap(err, "could not build deposit trie from snapshot")
+	}
+	count := int64(snapshot.DepositCount()) // lint:ignore uintcast -- Deposit count should not exceed int64 in your lifetime.
+	deposits := make([]*zondpb.DepositContainer, 0, len(dc.deposits))
+	for _, d := range dc.deposits {
+		if d.Index >= count {
+			deposits = append(deposits, d)
+		}
+	}
+	dc.deposits = deposits
+	dc.depositsByKey = make(map[[dilithium2.CryptoPublicKeyBytes]byte][]*zondpb.DepositContainer, len(deposits))
+	for _, d := range deposits {
+		pubkey := bytesutil.ToBytes2592(d.Deposit.Data.PublicKey)
+		dc.depositsByKey[pubkey] = append(dc.depositsByKey[pubkey], d)
+	}
+	dc.firstIndex = count
+	dc.snapshot = snapshot
+	dc.finalizedDeposits = &FinalizedDeposits{
+		Deposits:        depositTrie,
+		MerkleTrieIndex: count - 1,
+	}
+	return nil
+}
+
 // InsertFinalizedDeposits inserts deposits up to eth1DepositIndex (inclusive) into the finalized deposits cache.
 func (dc *DepositCache) InsertFinalizedDeposits(ctx context.Context, eth1DepositIndex int64) error {
 	ctx, span := trace.StartSpan(ctx, "DepositsCache.InsertFinalizedDeposits")
@@ -145,8 +191,8 @@ func (dc *DepositCache) InsertFinalizedDeposits(ctx context.Context, eth1Deposit
 	}
 	// In the event we have less deposits than we need to
 	// finalize we finalize till the index on which we do have it.
-	if len(dc.deposits) <= int(eth1DepositIndex) {
-		eth1DepositIndex = int64(len(dc.deposits)) - 1
+	if dc.firstIndex+int64(len(dc.deposits)) <= eth1DepositIndex {
+		eth1DepositIndex = dc.firstIndex + int64(len(dc.deposits)) - 1
 	}
 	// If we finalize to some lower deposit index, we
 	// ignore it.
@@ -233,9 +279,13 @@ func (dc *DepositCache) DepositsNumberAndRootAtHeight(ctx context.Context, block
 	// send the deposit root of the empty trie, if eth1follow distance is greater than the time of the earliest
 	// deposit.
 	if heightIdx == 0 {
+		// Deposits before the first one in the cache are only known from the snapshot the cache was started from.
+		if dc.snapshot != nil && blockHeight.Uint64() >= dc.snapshot.ExecutionBlockHeight() {
+			return dc.snapshot.DepositCount(), dc.snapshot.DepositRoot()
+		}
 		return 0, [32]byte{}
 	}
-	return uint64(heightIdx), bytesutil.ToBytes32(dc.deposits[heightIdx-1].DepositRoot)
+	return uint64(dc.firstIndex) + uint64(heightIdx), bytesutil.ToBytes32(dc.deposits[heightIdx-1].DepositRoot)
 }
 
 // DepositByPubkey looks through historical deposits and finds one which contains
@@ -302,6 +352,8 @@ func (dc *DepositCache) PruneProofs(ctx context.Context, untilDepositIndex int64
 	dc.depositsLock.Lock()
 	defer dc.depositsLock.Unlock()
 
+	// Deposits are indexed from the first one in the cache.
+	untilDepositIndex -= dc.firstIndex
 	if untilDepositIndex >= int64(len(dc.deposits)) {
 		untilDepositIndex = int64(len(dc.deposits) - 1)
 	}
@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	logTest "github.com/sirupsen/logrus/hooks/test"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositsnapshot"
 	"github.com/theQRL/qrysm/v4/config/params"
 	"github.com/theQRL/qrysm/v4/container/trie"
 	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
@@ -1056,3 +1058,80 @@ func makeDepositProof() [][]byte {
 	}
 	return proof
 }
+
+func TestInsertDepositSnapshot(t *testing.T) {
+	ctx := context.Background()
+	dc, err := New()
+	require.NoError(t, err)
+
+	var ctrs []*zondpb.DepositContainer
+	var items [][]byte
+	for i := int64(0); i < 7; i++ {
+		ctr := &zondpb.DepositContainer{
+			Deposit: &zondpb.Deposit{
+				Proof: makeDepositProof(),
+				Data: &zondpb.Deposit_Data{
+					PublicKey:             bytesutil.PadTo([]byte{byte(i)}, dilithium2.CryptoPublicKeyBytes),
+					WithdrawalCredentials: make([]byte, 32),
+					Signature:             make([]byte, dilithium2.CryptoBytes),
+				},
+			},
+			Eth1BlockHeight: uint64(10 + i),
+			Index:           i,
+		}
+		depHash, err := ctr.Deposit.Data.HashTreeRoot()
+		require.NoError(t, err)
+		items = append(items, depHash[:])
+		depositTrie, err := trie.GenerateTrieFromItems(items, params.BeaconConfig().DepositContractTreeDepth)
+		require.NoError(t, err)
+		root, err := depositTrie.HashTreeRoot()
+		require.NoError(t, err)
+		ctr.DepositRoot = root[:]
+		ctrs = append(ctrs, ctr)
+	}
+	snapshotTrie, err := trie.GenerateTrieFromItems(items[:4], params.BeaconConfig().DepositContractTreeDepth)
+	require.NoError(t, err)
+	snapshot, err := depositsnapshot.SnapshotFromTrie(snapshotTrie, 4, [32]byte{'a'}, 13)
+	require.NoError(t, err)
+
+	dc.InsertDepositContainers(ctx, ctrs[:6])
+	require.NoError(t, dc.InsertDepositSnapshot(ctx, snapshot))
+	require.ErrorContains(t, "cannot start from a deposit snapshot", dc.InsertDepositSnapshot(ctx, snapshot))
+
+	// Only the deposits after the snapshot are kept.
+	all := dc.AllDepositContainers(ctx)
+	require.Equal(t, 2, len(all))
+	assert.Equal(t, int64(4), all[0].Index)
+	dep, _ := dc.DepositByPubkey(ctx, bytesutil.PadTo([]byte{0}, dilithium2.CryptoPublicKeyBytes))
+	assert.Equal(t, (*zondpb.Deposit)(nil), dep)
+	finalized := dc.FinalizedDeposits(ctx)
+	assert.Equal(t, int64(3), finalized.MerkleTrieIndex)
+	root, err := finalized.Deposits.HashTreeRoot()
+	require.NoError(t, err)
+	assert.Equal(t, snapshot.DepositRoot(), root)
+
+	count, root := dc.DepositsNumberAndRootAtHeight(ctx, big.NewInt(5))
+	assert.Equal(t, uint64(0), count)
+	assert.Equal(t, [32]byte{}, root)
+	count, root = dc.DepositsNumberAndRootAtHeight(ctx, big.NewInt(13))
+	assert.Equal(t, uint64(4), count)
+	assert.Equal(t, snapshot.DepositRoot(), root)
+	count, root = dc.DepositsNumberAndRootAtHeight(ctx, big.NewInt(14))
+	assert.Equal(t, uint64(5), count)
+	assert.DeepEqual(t, ctrs[4].DepositRoot, root[:])
+
+	// Later deposits follow the snapshot.
+	require.ErrorContains(t, "wanted deposit with index 6", dc.InsertDeposit(ctx, ctrs[3].Deposit, 13, 3, [32]byte{}))
+	require.NoError(t, dc.InsertDeposit(ctx, ctrs[6].Deposit, ctrs[6].Eth1BlockHeight, 6, bytesutil.ToBytes32(ctrs[6].DepositRoot)))
+	require.NoError(t, dc.InsertFinalizedDeposits(ctx, 5))
+	finalized = dc.FinalizedDeposits(ctx)
+	assert.Equal(t, int64(5), finalized.MerkleTrieIndex)
+	root, err = finalized.Deposits.HashTreeRoot()
+	require.NoError(t, err)
+	assert.DeepEqual(t, ctrs[5].DepositRoot, root[:])
+
+	require.NoError(t, dc.PruneProofs(ctx, 4))
+	all = dc.AllDepositContainers(ctx)
+	assert.Equal(t, 0, len(all[0].Deposit.Proof))
+	assert.NotEqual(t, 0, len(all[1].Deposit.Proof))
+}
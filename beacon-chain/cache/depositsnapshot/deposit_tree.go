@@ -65,8 +65,6 @@ func (d *DepositTree) getSnapshot() (DepositTreeSnapshot, error) {
 }
 
 // fromSnapshot returns a deposit tree from a deposit tree snapshot.
-//
-//nolint:unused
 func fromSnapshot(snapshot DepositTreeSnapshot) (DepositTree, error) {
 	root, err := snapshot.CalculateRoot()
 	if err != nil {
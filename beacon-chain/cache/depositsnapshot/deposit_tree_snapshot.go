@@ -1,21 +1,24 @@
 package depositsnapshot
 
 import (
+	"bytes"
 	"crypto/sha256"
 
 	"github.com/pkg/errors"
+	"github.com/theQRL/qrysm/v4/container/trie"
 	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
 )
 
 var (
 	// ErrZeroIndex occurs when the value of index is 0.
 	ErrZeroIndex = errors.New("index should be greater than 0")
+	// ErrEth1DataMismatch occurs when a snapshot is not the deposit tree voted for in an eth1 data.
+	ErrEth1DataMismatch = errors.New("snapshot does not match eth1 data")
 )
 
 // DepositTreeSnapshot represents the data used to create a
 // deposit tree given a snapshot.
-//
-//nolint:unused
 type DepositTreeSnapshot struct {
 	finalized      [][32]byte
 	depositRoot    [32]byte
@@ -44,8 +47,6 @@ func (ds *DepositTreeSnapshot) CalculateRoot() ([32]byte, error) {
 }
 
 // fromTreeParts constructs the deposit tree from pre-existing data.
-//
-//nolint:unused
 func fromTreeParts(finalised [][32]byte, depositCount uint64, executionBlock executionBlock) (DepositTreeSnapshot, error) {
 	snapshot := DepositTreeSnapshot{
 		finalized:      finalised,
@@ -60,3 +61,88 @@ func fromTreeParts(finalised [][32]byte, depositCount uint64, executionBlock exe
 	snapshot.depositRoot = root
 	return snapshot, nil
 }
+
+// NewDepositTreeSnapshot returns the snapshot of a deposit tree of depositCount deposits, finalized at the
+// given execution block, from the roots of its finalized subtrees.
+func NewDepositTreeSnapshot(finalized [][32]byte, depositCount uint64, blockHash [32]byte, blockHeight uint64) (*DepositTreeSnapshot, error) {
+	snapshot, err := fromTreeParts(finalized, depositCount, executionBlock{Hash: blockHash, Depth: blockHeight})
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// SnapshotFromTrie returns the snapshot of the first depositCount deposits of a deposit trie, finalized
+// at the given execution block.
+func SnapshotFromTrie(t *trie.SparseMerkleTrie, depositCount uint64, blockHash [32]byte, blockHeight uint64) (*DepositTreeSnapshot, error) {
+	roots, err := t.CompleteSubtreeRoots(depositCount)
+	if err != nil {
+		return nil, err
+	}
+	finalized := make([][32]byte, len(roots))
+	for i, r := range roots {
+		finalized[i] = bytesutil.ToBytes32(r)
+	}
+	return NewDepositTreeSnapshot(finalized, depositCount, blockHash, blockHeight)
+}
+
+// ToTrie returns a deposit trie holding the deposits of the snapshot, to which the deposits after the
+// snapshot can be added. Only these later deposits can be proven.
+func (ds *DepositTreeSnapshot) ToTrie() (*trie.SparseMerkleTrie, error) {
+	roots := make([][]byte, len(ds.finalized))
+	for i := range ds.finalized {
+		roots[i] = bytesutil.SafeCopyBytes(ds.finalized[i][:])
+	}
+	return trie.GenerateTrieFromCompleteSubtreeRoots(roots, ds.depositCount, DepositContractDepth)
+}
+
+// Verify checks that the snapshot is well formed and that its deposit root matches its finalized subtrees.
+func (ds *DepositTreeSnapshot) Verify() error {
+	_, err := fromSnapshot(*ds)
+	return err
+}
+
+// VerifyEth1Data checks that the snapshot is the deposit tree voted for in the given eth1 data, with
+// the same deposit count, deposit root and execution block hash.
+func (ds *DepositTreeSnapshot) VerifyEth1Data(eth1Data *zondpb.Eth1Data) error {
+	if eth1Data == nil {
+		return errors.Wrap(ErrEth1DataMismatch, "nil eth1 data")
+	}
+	if ds.depositCount != eth1Data.DepositCount {
+		return errors.Wrapf(ErrEth1DataMismatch, "snapshot has %d deposits, eth1 data has %d", ds.depositCount, eth1Data.DepositCount)
+	}
+	if !bytes.Equal(ds.depositRoot[:], eth1Data.DepositRoot) {
+		return errors.Wrapf(ErrEth1DataMismatch, "snapshot deposit root %#x, eth1 data deposit root %#x", ds.depositRoot, eth1Data.DepositRoot)
+	}
+	if !bytes.Equal(ds.executionBlock.Hash[:], eth1Data.BlockHash) {
+		return errors.Wrapf(ErrEth1DataMismatch, "snapshot execution block hash %#x, eth1 data block hash %#x", ds.executionBlock.Hash, eth1Data.BlockHash)
+	}
+	return nil
+}
+
+// Finalized returns the roots of the finalized subtrees of the deposit tree.
+func (ds *DepositTreeSnapshot) Finalized() [][32]byte {
+	finalized := make([][32]byte, len(ds.finalized))
+	copy(finalized, ds.finalized)
+	return finalized
+}
+
+// DepositRoot returns the root of the deposit tree.
+func (ds *DepositTreeSnapshot) DepositRoot() [32]byte {
+	return ds.depositRoot
+}
+
+// DepositCount returns the number of deposits in the deposit tree.
+func (ds *DepositTreeSnapshot) DepositCount() uint64 {
+	return ds.depositCount
+}
+
+// ExecutionBlockHash returns the hash of the execution block at which the deposit tree was finalized.
+func (ds *DepositTreeSnapshot) ExecutionBlockHash() [32]byte {
+	return ds.executionBlock.Hash
+}
+
+// ExecutionBlockHeight returns the height of the execution block at which the deposit tree was finalized.
+func (ds *DepositTreeSnapshot) ExecutionBlockHeight() uint64 {
+	return ds.executionBlock.Depth
+}
@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"testing"
 
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
 	"github.com/theQRL/qrysm/v4/testing/require"
 )
 
@@ -52,3 +53,45 @@ func TestDepositTreeSnapshot_CalculateRoot(t *testing.T) {
 		})
 	}
 }
+
+func TestDepositTreeSnapshot_VerifyEth1Data(t *testing.T) {
+	blockHash := hexString(t, fmt.Sprintf("%064d", 7))
+	ds, err := NewDepositTreeSnapshot([][32]byte{hexString(t, fmt.Sprintf("%064d", 1))}, 2, blockHash, 10)
+	require.NoError(t, err)
+	root := ds.DepositRoot()
+	eth1Data := func() *zondpb.Eth1Data {
+		return &zondpb.Eth1Data{DepositRoot: root[:], DepositCount: 2, BlockHash: blockHash[:]}
+	}
+	require.NoError(t, ds.VerifyEth1Data(eth1Data()))
+
+	tests := []struct {
+		name    string
+		modify  func(*zondpb.Eth1Data)
+		wantErr string
+	}{
+		{
+			name:    "deposit count",
+			modify:  func(d *zondpb.Eth1Data) { d.DepositCount = 3 },
+			wantErr: "snapshot has 2 deposits, eth1 data has 3",
+		},
+		{
+			name:    "deposit root",
+			modify:  func(d *zondpb.Eth1Data) { d.DepositRoot = make([]byte, 32) },
+			wantErr: "eth1 data deposit root",
+		},
+		{
+			name:    "block hash",
+			modify:  func(d *zondpb.Eth1Data) { d.BlockHash = make([]byte, 32) },
+			wantErr: "eth1 data block hash",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := eth1Data()
+			tt.modify(d)
+			err := ds.VerifyEth1Data(d)
+			require.ErrorIs(t, err, ErrEth1DataMismatch)
+			require.ErrorContains(t, tt.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package depositsnapshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/theQRL/go-zond/common/hexutil"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+)
+
+// snapshotFixedSize is the size of the fixed part of an SSZ encoded snapshot: the offset of the
+// finalized roots, the deposit root, the deposit count, the execution block hash and height.
+const snapshotFixedSize = 4 + 32 + 8 + 32 + 8
+
+// SizeSSZ returns the size of the SSZ encoded snapshot.
+func (ds *DepositTreeSnapshot) SizeSSZ() int {
+	return snapshotFixedSize + 32*len(ds.finalized)
+}
+
+// MarshalSSZ encodes the snapshot as the DepositTreeSnapshot SSZ container of EIP-4881.
+func (ds *DepositTreeSnapshot) MarshalSSZ() ([]byte, error) {
+	if len(ds.finalized) > DepositContractDepth {
+		return nil, fmt.Errorf("snapshot has %d finalized roots, more than %d", len(ds.finalized), DepositContractDepth)
+	}
+	enc := make([]byte, 0, ds.SizeSSZ())
+	enc = binary.LittleEndian.AppendUint32(enc, snapshotFixedSize)
+	enc = append(enc, ds.depositRoot[:]...)
+	enc = binary.LittleEndian.AppendUint64(enc, ds.depositCount)
+	enc = append(enc, ds.executionBlock.Hash[:]...)
+	enc = binary.LittleEndian.AppendUint64(enc, ds.executionBlock.Depth)
+	for _, f := range ds.finalized {
+		enc = append(enc, f[:]...)
+	}
+	return enc, nil
+}
+
+// UnmarshalSSZ decodes a snapshot encoded as the DepositTreeSnapshot SSZ container of EIP-4881.
+func (ds *DepositTreeSnapshot) UnmarshalSSZ(enc []byte) error {
+	if len(enc) < snapshotFixedSize {
+		return fmt.Errorf("snapshot of %d bytes is shorter than %d bytes", len(enc), snapshotFixedSize)
+	}
+	if offset := binary.LittleEndian.Uint32(enc[:4]); offset != snapshotFixedSize {
+		return fmt.Errorf("invalid offset of finalized roots %d", offset)
+	}
+	rest := enc[snapshotFixedSize:]
+	if len(rest)%32 != 0 {
+		return fmt.Errorf("finalized roots of %d bytes are not a multiple of 32 bytes", len(rest))
+	}
+	if len(rest)/32 > DepositContractDepth {
+		return fmt.Errorf("snapshot has %d finalized roots, more than %d", len(rest)/32, DepositContractDepth)
+	}
+	ds.depositRoot = bytesutil.ToBytes32(enc[4:36])
+	ds.depositCount = binary.LittleEndian.Uint64(enc[36:44])
+	ds.executionBlock = executionBlock{
+		Hash:  bytesutil.ToBytes32(enc[44:76]),
+		Depth: binary.LittleEndian.Uint64(enc[76:84]),
+	}
+	ds.finalized = make([][32]byte, len(rest)/32)
+	for i := range ds.finalized {
+		ds.finalized[i] = bytesutil.ToBytes32(rest[32*i : 32*(i+1)])
+	}
+	return nil
+}
+
+// snapshotJson is the JSON representation of a snapshot in the beacon API.
+type snapshotJson struct {
+	Finalized            []string `json:"finalized"`
+	DepositRoot          string   `json:"deposit_root"`
+	DepositCount         string   `json:"deposit_count"`
+	ExecutionBlockHash   string   `json:"execution_block_hash"`
+	ExecutionBlockHeight string   `json:"execution_block_height"`
+}
+
+// MarshalJSON encodes the snapshot as in the beacon API.
+func (ds *DepositTreeSnapshot) MarshalJSON() ([]byte, error) {
+	finalized := make([]string, len(ds.finalized))
+	for i := range ds.finalized {
+		finalized[i] = hexutil.Encode(ds.finalized[i][:])
+	}
+	return json.Marshal(&snapshotJson{
+		Finalized:            finalized,
+		DepositRoot:          hexutil.Encode(ds.depositRoot[:]),
+		DepositCount:         strconv.FormatUint(ds.depositCount, 10),
+		ExecutionBlockHash:   hexutil.Encode(ds.executionBlock.Hash[:]),
+		ExecutionBlockHeight: strconv.FormatUint(ds.executionBlock.Depth, 10),
+	})
+}
+
+// UnmarshalJSON decodes a snapshot encoded as in the beacon API.
+func (ds *DepositTreeSnapshot) UnmarshalJSON(enc []byte) error {
+	var s snapshotJson
+	if err := json.Unmarshal(enc, &s); err != nil {
+		return err
+	}
+	decodeRoot := func(name, v string) ([32]byte, error) {
+		b, err := hexutil.Decode(v)
+		if err != nil {
+			return [32]byte{}, errors.Wrapf(err, "invalid %s", name)
+		}
+		if len(b) != 32 {
+			return [32]byte{}, fmt.Errorf("invalid %s length %d", name, len(b))
+		}
+		return bytesutil.ToBytes32(b), nil
+	}
+	var err error
+	if len(s.Finalized) > DepositContractDepth {
+		return fmt.Errorf("snapshot has %d finalized roots, more than %d", len(s.Finalized), DepositContractDepth)
+	}
+	ds.finalized = make([][32]byte, len(s.Finalized))
+	for i, f := range s.Finalized {
+		if ds.finalized[i], err = decodeRoot("finalized root", f); err != nil {
+			return err
+		}
+	}
+	if ds.depositRoot, err = decodeRoot("deposit root", s.DepositRoot); err != nil {
+		return err
+	}
+	if ds.depositCount, err = strconv.ParseUint(s.DepositCount, 10, 64); err != nil {
+		return errors.Wrap(err, "invalid deposit count")
+	}
+	if ds.executionBlock.Hash, err = decodeRoot("execution block hash", s.ExecutionBlockHash); err != nil {
+		return err
+	}
+	if ds.executionBlock.Depth, err = strconv.ParseUint(s.ExecutionBlockHeight, 10, 64); err != nil {
+		return errors.Wrap(err, "invalid execution block height")
+	}
+	return nil
+}
+
+// DecodeSnapshot decodes a snapshot encoded either in SSZ, or in JSON as served by the beacon API with
+// or without its data envelope, and verifies it.
+func DecodeSnapshot(enc []byte) (*DepositTreeSnapshot, error) {
+	ds := &DepositTreeSnapshot{}
+	trimmed := bytes.TrimSpace(enc)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		envelope := struct {
+			Data json.RawMessage `json:"data"`
+		}{}
+		if err := json.Unmarshal(trimmed, &envelope); err != nil {
+			return nil, errors.Wrap(err, "could not decode JSON deposit snapshot")
+		}
+		data := []byte(envelope.Data)
+		if len(data) == 0 {
+			data = trimmed
+		}
+		if err := ds.UnmarshalJSON(data); err != nil {
+			return nil, errors.Wrap(err, "could not decode JSON deposit snapshot")
+		}
+	} else if err := ds.UnmarshalSSZ(enc); err != nil {
+		return nil, errors.Wrap(err, "could not decode SSZ deposit snapshot")
+	}
+	if err := ds.Verify(); err != nil {
+		return nil, errors.Wrap(err, "invalid deposit snapshot")
+	}
+	return ds, nil
+}
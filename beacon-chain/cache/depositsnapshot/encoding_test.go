@@ -0,0 +1,113 @@
+package depositsnapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/theQRL/qrysm/v4/container/trie"
+	zond "github.com/theQRL/qrysm/v4/proto/zond/v1"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+)
+
+// finalizedTree returns a deposit tree of count deposits, all of them finalized, and the same
+// deposits in a sparse Merkle trie.
+func finalizedTree(t *testing.T, count uint64) (*DepositTree, *trie.SparseMerkleTrie) {
+	tree := New()
+	items := make([][]byte, count)
+	for i := uint64(0); i < count; i++ {
+		leaf := hexString(t, fmt.Sprintf("%064x", i+1))
+		require.NoError(t, tree.pushLeaf(leaf))
+		items[i] = leaf[:]
+	}
+	root := tree.getRoot()
+	blockHash := hexString(t, fmt.Sprintf("%064x", 0xaa))
+	require.NoError(t, tree.finalize(&zond.Eth1Data{
+		DepositRoot:  root[:],
+		DepositCount: count,
+		BlockHash:    blockHash[:],
+	}, 100))
+	depositTrie, err := trie.GenerateTrieFromItems(items, DepositContractDepth)
+	require.NoError(t, err)
+	return tree, depositTrie
+}
+
+func TestSnapshotFromTrie(t *testing.T) {
+	for _, count := range []uint64{1, 2, 5, 16, 21} {
+		t.Run(fmt.Sprintf("%d deposits", count), func(t *testing.T) {
+			tree, depositTrie := finalizedTree(t, count)
+			want, err := tree.getSnapshot()
+			require.NoError(t, err)
+
+			got, err := SnapshotFromTrie(depositTrie, count, want.ExecutionBlockHash(), want.ExecutionBlockHeight())
+			require.NoError(t, err)
+			assert.DeepEqual(t, want, *got)
+			trieRoot, err := depositTrie.HashTreeRoot()
+			require.NoError(t, err)
+			assert.Equal(t, trieRoot, got.DepositRoot())
+
+			// The trie rebuilt from the snapshot has the same root, and grows like the original trie.
+			rebuilt, err := got.ToTrie()
+			require.NoError(t, err)
+			rebuiltRoot, err := rebuilt.HashTreeRoot()
+			require.NoError(t, err)
+			assert.Equal(t, trieRoot, rebuiltRoot)
+			leaf := hexString(t, fmt.Sprintf("%064x", 0xff))
+			require.NoError(t, depositTrie.Insert(leaf[:], int(count)))
+			require.NoError(t, rebuilt.Insert(leaf[:], int(count)))
+			trieRoot, err = depositTrie.HashTreeRoot()
+			require.NoError(t, err)
+			rebuiltRoot, err = rebuilt.HashTreeRoot()
+			require.NoError(t, err)
+			assert.Equal(t, trieRoot, rebuiltRoot)
+		})
+	}
+}
+
+func TestDepositTreeSnapshot_EncodingRoundTrip(t *testing.T) {
+	tree, _ := finalizedTree(t, 21)
+	snapshot, err := tree.getSnapshot()
+	require.NoError(t, err)
+
+	enc, err := snapshot.MarshalSSZ()
+	require.NoError(t, err)
+	assert.Equal(t, snapshot.SizeSSZ(), len(enc))
+	decoded, err := DecodeSnapshot(enc)
+	require.NoError(t, err)
+	assert.DeepEqual(t, snapshot, *decoded)
+
+	enc, err = json.Marshal(&snapshot)
+	require.NoError(t, err)
+	decoded, err = DecodeSnapshot(enc)
+	require.NoError(t, err)
+	assert.DeepEqual(t, snapshot, *decoded)
+
+	enc, err = json.Marshal(map[string]interface{}{"data": &snapshot})
+	require.NoError(t, err)
+	decoded, err = DecodeSnapshot(enc)
+	require.NoError(t, err)
+	assert.DeepEqual(t, snapshot, *decoded)
+}
+
+func TestDecodeSnapshot_Invalid(t *testing.T) {
+	tree, _ := finalizedTree(t, 5)
+	snapshot, err := tree.getSnapshot()
+	require.NoError(t, err)
+
+	_, err = DecodeSnapshot([]byte{0x01, 0x02})
+	require.ErrorContains(t, "could not decode SSZ deposit snapshot", err)
+
+	enc, err := snapshot.MarshalSSZ()
+	require.NoError(t, err)
+	_, err = DecodeSnapshot(enc[:len(enc)-1])
+	require.ErrorContains(t, "not a multiple of 32 bytes", err)
+
+	// A snapshot whose deposit root does not match its finalized roots is rejected.
+	enc[4] ^= 0xff
+	_, err = DecodeSnapshot(enc)
+	require.ErrorIs(t, err, ErrInvalidSnapshotRoot)
+
+	_, err = DecodeSnapshot([]byte(`{"data":{"deposit_count":"x"}}`))
+	require.ErrorContains(t, "could not decode JSON deposit snapshot", err)
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 
 	"github.com/theQRL/go-zond/common"
+	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositsnapshot"
 	"github.com/theQRL/qrysm/v4/beacon-chain/db/filters"
 	slashertypes "github.com/theQRL/qrysm/v4/beacon-chain/slasher/types"
 	"github.com/theQRL/qrysm/v4/beacon-chain/state"
@@ -51,9 +52,12 @@ type ReadOnlyDatabase interface {
 	DepositContractAddress(ctx context.Context) ([]byte, error)
 	// ExecutionChainData operations.
 	ExecutionChainData(ctx context.Context) (*zondpb.ETH1ChainData, error)
+	DepositSnapshot(ctx context.Context) (*depositsnapshot.DepositTreeSnapshot, error)
 	// Fee recipients operations.
 	FeeRecipientByValidatorID(ctx context.Context, id primitives.ValidatorIndex) (common.Address, error)
 	RegistrationByValidatorID(ctx context.Context, id primitives.ValidatorIndex) (*zondpb.ValidatorRegistrationV1, error)
+	// Validator monitor operations.
+	MonitoredValidators(ctx context.Context) ([]primitives.ValidatorIndex, [][]byte, error)
 	// origin checkpoint sync support
 	OriginCheckpointBlockRoot(ctx context.Context) ([32]byte, error)
 	BackfillBlockRoot(ctx context.Context) ([32]byte, error)
@@ -75,6 +79,8 @@ type NoHeadAccessDatabase interface {
 	DeleteStates(ctx context.Context, blockRoots [][32]byte) error
 	SaveStateSummary(ctx context.Context, summary *zondpb.StateSummary) error
 	SaveStateSummaries(ctx context.Context, summaries []*zondpb.StateSummary) error
+	SaveStateDiff(ctx context.Context, blockRoot [32]byte, st state.ReadOnlyBeaconState, baseRoot [32]byte, base state.ReadOnlyBeaconState) error
+	MigrateStatesToDiffs(ctx context.Context, slotsPerArchivedPoint primitives.Slot) error
 	// Checkpoint operations.
 	SaveJustifiedCheckpoint(ctx context.Context, checkpoint *zondpb.Checkpoint) error
 	SaveFinalizedCheckpoint(ctx context.Context, checkpoint *zondpb.Checkpoint) error
@@ -83,11 +89,14 @@ type NoHeadAccessDatabase interface {
 	SaveDepositContractAddress(ctx context.Context, addr common.Address) error
 	// SaveExecutionChainData operations.
 	SaveExecutionChainData(ctx context.Context, data *zondpb.ETH1ChainData) error
+	SaveDepositSnapshot(ctx context.Context, snapshot *depositsnapshot.DepositTreeSnapshot) error
 	// Run any required database migrations.
 	RunMigrations(ctx context.Context) error
 	// Fee recipients operations.
 	SaveFeeRecipientsByValidatorIDs(ctx context.Context, ids []primitives.ValidatorIndex, addrs []common.Address) error
 	SaveRegistrationsByValidatorIDs(ctx context.Context, ids []primitives.ValidatorIndex, regs []*zondpb.ValidatorRegistrationV1) error
+	// Validator monitor operations.
+	SaveMonitoredValidators(ctx context.Context, indices []primitives.ValidatorIndex, pubkeys [][]byte) error
 
 	CleanUpDirtyStates(ctx context.Context, slotsPerArchivedPoint primitives.Slot) error
 }
@@ -0,0 +1,100 @@
+package kv
+
+import (
+	"context"
+
+	"github.com/golang/snappy"
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+	"go.opencensus.io/trace"
+)
+
+// blindBlocksBatchSize is the number of blocks converted per database transaction.
+const blindBlocksBatchSize = 256
+
+// BlindFullBlocks converts the full post-merge blocks stored in the database to blinded blocks, and
+// switches the database to blinded block storage. Full payloads are then reconstructed from the
+// execution client on demand. It returns the number of blocks converted.
+func (s *Store) BlindFullBlocks(ctx context.Context) (int, error) {
+	ctx, span := trace.StartSpan(ctx, "BeaconDB.BlindFullBlocks")
+	defer span.End()
+
+	converted := 0
+	var next []byte
+	for {
+		if ctx.Err() != nil {
+			return converted, ctx.Err()
+		}
+		keys := make([][]byte, 0, blindBlocksBatchSize)
+		encoded := make([][]byte, 0, blindBlocksBatchSize)
+		if err := s.db.View(func(tx *bolt.Tx) error {
+			c := tx.Bucket(blocksBucket).Cursor()
+			k, v := c.First()
+			if next != nil {
+				k, v = c.Seek(next)
+			}
+			for ; k != nil && len(keys) < blindBlocksBatchSize; k, v = c.Next() {
+				full, err := isFullPostMergeBlock(v)
+				if err != nil {
+					return errors.Wrapf(err, "could not decode block %#x", k)
+				}
+				if !full {
+					continue
+				}
+				blk, err := unmarshalBlock(ctx, v)
+				if err != nil {
+					return errors.Wrapf(err, "could not unmarshal block %#x", k)
+				}
+				enc, err := marshalBlockBlinded(ctx, blk)
+				if err != nil {
+					return errors.Wrapf(err, "could not blind block %#x", k)
+				}
+				keys = append(keys, append([]byte{}, k...))
+				encoded = append(encoded, enc)
+			}
+			next = nil
+			if k != nil {
+				next = append([]byte{}, k...)
+			}
+			return nil
+		}); err != nil {
+			return converted, err
+		}
+		if len(keys) > 0 {
+			if err := s.db.Update(func(tx *bolt.Tx) error {
+				bkt := tx.Bucket(blocksBucket)
+				for i, k := range keys {
+					if err := bkt.Put(k, encoded[i]); err != nil {
+						return err
+					}
+				}
+				return nil
+			}); err != nil {
+				return converted, err
+			}
+			converted += len(keys)
+			log.WithField("converted", converted).Debug("Blinded stored blocks")
+		}
+		if next == nil {
+			break
+		}
+	}
+
+	if err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(chainMetadataBucket).Put(saveBlindedBeaconBlocksKey, []byte{1})
+	}); err != nil {
+		return converted, err
+	}
+	// Cached blocks may still be full.
+	s.blockCache.Clear()
+	return converted, nil
+}
+
+// isFullPostMergeBlock returns whether an encoded block is a full block of a fork that supports blinding.
+func isFullPostMergeBlock(enc []byte) (bool, error) {
+	dec, err := snappy.Decode(nil, enc)
+	if err != nil {
+		return false, err
+	}
+	return hasBellatrixKey(dec) || hasCapellaKey(dec), nil
+}
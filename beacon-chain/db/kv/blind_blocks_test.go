@@ -0,0 +1,71 @@
+package kv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/theQRL/qrysm/v4/config/features"
+	"github.com/theQRL/qrysm/v4/consensus-types/blocks"
+	"github.com/theQRL/qrysm/v4/consensus-types/interfaces"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/util"
+)
+
+func TestStore_BlindFullBlocks(t *testing.T) {
+	resetFn := features.InitWithReset(&features.Flags{
+		SaveFullExecutionPayloads: true,
+	})
+	defer resetFn()
+	ctx := context.Background()
+	db := setupDB(t)
+
+	var blks []interfaces.ReadOnlySignedBeaconBlock
+	phase0, err := blocks.NewSignedBeaconBlock(util.NewBeaconBlock())
+	require.NoError(t, err)
+	blks = append(blks, phase0)
+	for i := 1; i <= blindBlocksBatchSize+2; i++ {
+		b := util.NewBeaconBlockCapella()
+		b.Block.Slot = 1
+		b.Block.ProposerIndex = 1
+		b.Block.Body.ExecutionPayload.BlockNumber = uint64(i)
+		blk, err := blocks.NewSignedBeaconBlock(b)
+		require.NoError(t, err)
+		blks = append(blks, blk)
+	}
+	bellatrix, err := blocks.NewSignedBeaconBlock(util.NewBeaconBlockBellatrix())
+	require.NoError(t, err)
+	blks = append(blks, bellatrix)
+	require.NoError(t, db.SaveBlocks(ctx, blks))
+	saveBlinded, err := db.shouldSaveBlinded(ctx)
+	require.NoError(t, err)
+	require.Equal(t, false, saveBlinded)
+
+	converted, err := db.BlindFullBlocks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, blindBlocksBatchSize+3, converted)
+	saveBlinded, err = db.shouldSaveBlinded(ctx)
+	require.NoError(t, err)
+	require.Equal(t, true, saveBlinded)
+
+	for _, blk := range blks {
+		root, err := blk.Block().HashTreeRoot()
+		require.NoError(t, err)
+		got, err := db.Block(ctx, root)
+		require.NoError(t, err)
+		gotRoot, err := got.Block().HashTreeRoot()
+		require.NoError(t, err)
+		assert.Equal(t, root, gotRoot)
+		assert.Equal(t, blk.Version(), got.Version())
+		if blk == phase0 {
+			assert.Equal(t, false, got.IsBlinded())
+		} else {
+			assert.Equal(t, true, got.IsBlinded())
+		}
+	}
+
+	// Running it again has nothing left to convert.
+	converted, err = db.BlindFullBlocks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, converted)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositsnapshot"
 	"github.com/theQRL/qrysm/v4/monitoring/tracing"
 	v2 "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
 	bolt "go.etcd.io/bbolt"
@@ -51,3 +52,43 @@ func (s *Store) ExecutionChainData(ctx context.Context) (*v2.ETH1ChainData, erro
 	})
 	return data, err
 }
+
+// SaveDepositSnapshot saves the snapshot of the finalized deposit tree.
+func (s *Store) SaveDepositSnapshot(ctx context.Context, snapshot *depositsnapshot.DepositTreeSnapshot) error {
+	ctx, span := trace.StartSpan(ctx, "BeaconDB.SaveDepositSnapshot")
+	defer span.End()
+
+	if snapshot == nil {
+		err := errors.New("cannot save nil deposit snapshot")
+		tracing.AnnotateError(span, err)
+		return err
+	}
+	enc, err := snapshot.MarshalSSZ()
+	if err != nil {
+		tracing.AnnotateError(span, err)
+		return err
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(powchainBucket).Put(depositSnapshotKey, enc)
+	})
+	tracing.AnnotateError(span, err)
+	return err
+}
+
+// DepositSnapshot retrieves the snapshot of the finalized deposit tree. It returns nil if no snapshot
+// was saved.
+func (s *Store) DepositSnapshot(ctx context.Context) (*depositsnapshot.DepositTreeSnapshot, error) {
+	ctx, span := trace.StartSpan(ctx, "BeaconDB.DepositSnapshot")
+	defer span.End()
+
+	var snapshot *depositsnapshot.DepositTreeSnapshot
+	err := s.db.View(func(tx *bolt.Tx) error {
+		enc := tx.Bucket(powchainBucket).Get(depositSnapshotKey)
+		if len(enc) == 0 {
+			return nil
+		}
+		snapshot = &depositsnapshot.DepositTreeSnapshot{}
+		return snapshot.UnmarshalSSZ(enc)
+	})
+	return snapshot, err
+}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"testing"
 
+	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositsnapshot"
+	"github.com/theQRL/qrysm/v4/container/trie"
 	v2 "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
 )
 
 func TestStore_SavePowchainData(t *testing.T) {
@@ -33,3 +37,22 @@ func TestStore_SavePowchainData(t *testing.T) {
 		})
 	}
 }
+
+func TestStore_DepositSnapshot(t *testing.T) {
+	ctx := context.Background()
+	store := setupDB(t)
+	snapshot, err := store.DepositSnapshot(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, (*depositsnapshot.DepositTreeSnapshot)(nil), snapshot)
+	require.ErrorContains(t, "cannot save nil deposit snapshot", store.SaveDepositSnapshot(ctx, nil))
+
+	items := [][]byte{{'a'}, {'b'}, {'c'}, {'d'}, {'e'}}
+	depositTrie, err := trie.GenerateTrieFromItems(items, 32)
+	require.NoError(t, err)
+	want, err := depositsnapshot.SnapshotFromTrie(depositTrie, uint64(len(items)), [32]byte{'h'}, 100)
+	require.NoError(t, err)
+	require.NoError(t, store.SaveDepositSnapshot(ctx, want))
+	snapshot, err = store.DepositSnapshot(ctx)
+	require.NoError(t, err)
+	assert.DeepEqual(t, want, snapshot)
+}
@@ -129,6 +129,12 @@ var Buckets = [][]byte{
 
 	feeRecipientBucket,
 	registrationBucket,
+
+	monitoredValidatorIndicesBucket,
+	monitoredValidatorPubkeysBucket,
+
+	stateDiffBucket,
+	stateDiffBasesBucket,
 }
 
 // NewKVStore initializes a new boltDB key-value store at the directory
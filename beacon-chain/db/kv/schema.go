@@ -21,6 +21,14 @@ var (
 	feeRecipientBucket      = []byte("fee-recipient")
 	registrationBucket      = []byte("registration")
 
+	// States stored as a difference to a full state, and the full states they are based on.
+	stateDiffBucket      = []byte("state-diff")
+	stateDiffBasesBucket = []byte("state-diff-bases")
+
+	// Validators tracked by the validator monitor.
+	monitoredValidatorIndicesBucket = []byte("monitored-validator-indices")
+	monitoredValidatorPubkeysBucket = []byte("monitored-validator-pubkeys")
+
 	// Deprecated: This bucket was migrated in PR 6461. Do not use, except for migrations.
 	slotsHasObjectBucket = []byte("slots-has-objects")
 	// Deprecated: This bucket was migrated in PR 6461. Do not use, except for migrations.
@@ -45,6 +53,7 @@ var (
 	justifiedCheckpointKey     = []byte("justified-checkpoint")
 	finalizedCheckpointKey     = []byte("finalized-checkpoint")
 	powchainDataKey            = []byte("powchain-data")
+	depositSnapshotKey         = []byte("deposit-snapshot")
 	lastValidatedCheckpointKey = []byte("last-validated-checkpoint")
 
 	// Below keys are used to identify objects are to be fork compatible.
@@ -35,7 +35,7 @@ func (s *Store) State(ctx context.Context, blockRoot [32]byte) (state.BeaconStat
 	}
 
 	if len(enc) == 0 {
-		return nil, nil
+		return s.stateFromDiff(ctx, blockRoot)
 	}
 	// get the validator entries of the state
 	valEntries, valErr := s.validatorEntries(ctx, blockRoot)
@@ -356,7 +356,7 @@ func (s *Store) HasState(ctx context.Context, blockRoot [32]byte) bool {
 	err := s.db.View(func(tx *bolt.Tx) error {
 		bkt := tx.Bucket(stateBucket)
 		stBytes := bkt.Get(blockRoot[:])
-		if len(stBytes) > 0 {
+		if len(stBytes) > 0 || tx.Bucket(stateDiffBucket).Get(blockRoot[:]) != nil {
 			hasState = true
 		}
 		return nil
@@ -399,6 +399,14 @@ func (s *Store) DeleteState(ctx context.Context, blockRoot [32]byte) error {
 			return ErrDeleteJustifiedAndFinalized
 		}
 
+		// Keep the full states which state diffs are based on.
+		if tx.Bucket(stateDiffBasesBucket).Get(blockRoot[:]) != nil {
+			return nil
+		}
+		if tx.Bucket(stateDiffBucket).Get(blockRoot[:]) != nil {
+			return s.deleteStateDiff(ctx, tx, blockRoot)
+		}
+
 		// Nothing to delete if state doesn't exist.
 		enc = bkt.Get(blockRoot[:])
 		if enc == nil {
@@ -414,41 +422,51 @@ func (s *Store) DeleteState(ctx context.Context, blockRoot [32]byte) error {
 			return errors.Wrap(err, "could not delete root for DB indices")
 		}
 
-		ok, err := s.isStateValidatorMigrationOver()
-		if err != nil {
+		if err := s.deleteValidatorHashes(tx, blockRoot); err != nil {
 			return err
 		}
-		if ok {
-			// remove the validator entry keys for the corresponding state.
-			idxBkt := tx.Bucket(blockRootValidatorHashesBucket)
-			compressedValidatorHashes := idxBkt.Get(blockRoot[:])
-			err = idxBkt.Delete(blockRoot[:])
-			if err != nil {
-				return err
-			}
-
-			// remove the respective validator entries from the cache.
-			if len(compressedValidatorHashes) == 0 {
-				return errors.Errorf("invalid compressed validator keys length")
-			}
-			validatorHashes, sErr := snappy.Decode(nil, compressedValidatorHashes)
-			if sErr != nil {
-				return errors.Wrap(sErr, "failed to uncompress validator keys")
-			}
-			if len(validatorHashes)%hashLength != 0 {
-				return errors.Errorf("invalid validator keys length: %d", len(validatorHashes))
-			}
-			for i := 0; i < len(validatorHashes); i += hashLength {
-				key := validatorHashes[i : i+hashLength]
-				s.validatorEntryCache.Del(key)
-				validatorEntryCacheDelete.Inc()
-			}
-		}
 
 		return bkt.Delete(blockRoot[:])
 	})
 }
 
+// deleteValidatorHashes removes the validator entry keys of the state of the block root, once the
+// validators of states are stored separately.
+func (s *Store) deleteValidatorHashes(tx *bolt.Tx, blockRoot [32]byte) error {
+	ok, err := s.isStateValidatorMigrationOver()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	// remove the validator entry keys for the corresponding state.
+	idxBkt := tx.Bucket(blockRootValidatorHashesBucket)
+	compressedValidatorHashes := idxBkt.Get(blockRoot[:])
+	err = idxBkt.Delete(blockRoot[:])
+	if err != nil {
+		return err
+	}
+
+	// remove the respective validator entries from the cache.
+	if len(compressedValidatorHashes) == 0 {
+		return errors.Errorf("invalid compressed validator keys length")
+	}
+	validatorHashes, sErr := snappy.Decode(nil, compressedValidatorHashes)
+	if sErr != nil {
+		return errors.Wrap(sErr, "failed to uncompress validator keys")
+	}
+	if len(validatorHashes)%hashLength != 0 {
+		return errors.Errorf("invalid validator keys length: %d", len(validatorHashes))
+	}
+	for i := 0; i < len(validatorHashes); i += hashLength {
+		key := validatorHashes[i : i+hashLength]
+		s.validatorEntryCache.Del(key)
+		validatorEntryCacheDelete.Inc()
+	}
+	return nil
+}
+
 // DeleteStates by block roots.
 func (s *Store) DeleteStates(ctx context.Context, blockRoots [][32]byte) error {
 	ctx, span := trace.StartSpan(ctx, "BeaconDB.DeleteStates")
@@ -805,6 +823,7 @@ func createStateIndicesFromStateSlot(ctx context.Context, slot primitives.Slot)
 //
 // 3.) state with current finalized root
 // 4.) unfinalized States
+// 5.) state diffs, and the full states they are based on
 func (s *Store) CleanUpDirtyStates(ctx context.Context, slotsPerArchivedPoint primitives.Slot) error {
 	ctx, span := trace.StartSpan(ctx, "BeaconDB. CleanUpDirtyStates")
 	defer span.End()
@@ -821,10 +840,15 @@ func (s *Store) CleanUpDirtyStates(ctx context.Context, slotsPerArchivedPoint pr
 
 	err = s.db.View(func(tx *bolt.Tx) error {
 		bkt := tx.Bucket(stateSlotIndicesBucket)
+		diffBkt := tx.Bucket(stateDiffBucket)
 		return bkt.ForEach(func(k, v []byte) error {
 			if ctx.Err() != nil {
 				return ctx.Err()
 			}
+			// State diffs are only saved between archived points on purpose.
+			if diffBkt.Get(v) != nil {
+				return nil
+			}
 
 			finalizedChkpt := bytesutil.ToBytes32(f.Root) == bytesutil.ToBytes32(v)
 			slot := bytesutil.BytesToSlotBigEndian(k)
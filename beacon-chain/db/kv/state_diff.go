@@ -0,0 +1,202 @@
+package kv
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state/statediff"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	"github.com/theQRL/qrysm/v4/time/slots"
+	bolt "go.etcd.io/bbolt"
+	"go.opencensus.io/trace"
+)
+
+// SaveStateDiff saves the state of the block root as a difference to the full state of the base
+// block root, which is much smaller than a full state. The state is then retrieved with State like
+// any other state. The base state is kept in the DB as long as there are state diffs based on it.
+func (s *Store) SaveStateDiff(
+	ctx context.Context,
+	blockRoot [32]byte,
+	st state.ReadOnlyBeaconState,
+	baseRoot [32]byte,
+	base state.ReadOnlyBeaconState,
+) error {
+	ctx, span := trace.StartSpan(ctx, "BeaconDB.SaveStateDiff")
+	defer span.End()
+
+	diff, err := statediff.Diff(base, st)
+	if err != nil {
+		return errors.Wrap(err, "could not compute state diff")
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return s.saveStateDiff(ctx, tx, blockRoot, st.Slot(), baseRoot, diff)
+	})
+}
+
+// MigrateStatesToDiffs converts the finalized full states of the DB to state diffs. The first state
+// of each archived point interval is kept as a full state, the following states of the interval are
+// saved as a difference to it. The genesis, justified and finalized states are kept as full states.
+func (s *Store) MigrateStatesToDiffs(ctx context.Context, slotsPerArchivedPoint primitives.Slot) error {
+	ctx, span := trace.StartSpan(ctx, "BeaconDB.MigrateStatesToDiffs")
+	defer span.End()
+
+	if slotsPerArchivedPoint == 0 {
+		return errors.New("slots per archived point must be greater than 0")
+	}
+	finalized, err := s.FinalizedCheckpoint(ctx)
+	if err != nil {
+		return err
+	}
+	justified, err := s.JustifiedCheckpoint(ctx)
+	if err != nil {
+		return err
+	}
+	finalizedSlot, err := slots.EpochStart(finalized.Epoch)
+	if err != nil {
+		return err
+	}
+	kept := map[[32]byte]bool{
+		bytesutil.ToBytes32(finalized.Root): true,
+		bytesutil.ToBytes32(justified.Root): true,
+	}
+
+	type stateEntry struct {
+		slot primitives.Slot
+		root [32]byte
+	}
+	var entries []stateEntry
+	if err := s.db.View(func(tx *bolt.Tx) error {
+		kept[bytesutil.ToBytes32(tx.Bucket(blocksBucket).Get(genesisBlockRootKey))] = true
+		stBkt := tx.Bucket(stateBucket)
+		c := tx.Bucket(stateSlotIndicesBucket).Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			slot := bytesutil.BytesToSlotBigEndian(k)
+			if slot >= finalizedSlot {
+				break
+			}
+			// Only full states are migrated.
+			if len(v) == 0 || len(stBkt.Get(v)) == 0 {
+				continue
+			}
+			entries = append(entries, stateEntry{slot: slot, root: bytesutil.ToBytes32(v)})
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var base state.BeaconState
+	var baseRoot [32]byte
+	var baseSlot primitives.Slot
+	migrated := 0
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		st, err := s.State(ctx, e.root)
+		if err != nil {
+			return errors.Wrapf(err, "could not get state of block root %#x", e.root)
+		}
+		if st == nil || st.IsNil() {
+			continue
+		}
+		if base == nil || kept[e.root] || e.slot/slotsPerArchivedPoint != baseSlot/slotsPerArchivedPoint || st.Version() != base.Version() {
+			base, baseRoot, baseSlot = st, e.root, e.slot
+			continue
+		}
+		diff, err := statediff.Diff(base, st)
+		if err != nil {
+			return errors.Wrapf(err, "could not compute state diff of block root %#x", e.root)
+		}
+		if err := s.db.Update(func(tx *bolt.Tx) error {
+			if err := s.deleteValidatorHashes(tx, e.root); err != nil {
+				return err
+			}
+			if err := tx.Bucket(stateBucket).Delete(e.root[:]); err != nil {
+				return err
+			}
+			return s.saveStateDiff(ctx, tx, e.root, st.Slot(), baseRoot, diff)
+		}); err != nil {
+			return err
+		}
+		migrated++
+	}
+	if migrated > 0 {
+		log.WithField("count", migrated).Info("Migrated states to state diffs")
+	}
+	return nil
+}
+
+// saveStateDiff saves the state diff of the block root, which is stored as the base block root
+// followed by the diff.
+func (s *Store) saveStateDiff(ctx context.Context, tx *bolt.Tx, blockRoot [32]byte, slot primitives.Slot, baseRoot [32]byte, diff []byte) error {
+	// A full state is not replaced by a state diff.
+	if len(tx.Bucket(stateBucket).Get(blockRoot[:])) > 0 {
+		return nil
+	}
+	if len(tx.Bucket(stateBucket).Get(baseRoot[:])) == 0 {
+		return errors.Wrapf(ErrNotFoundState, "base state %#x of state diff", baseRoot)
+	}
+	indicesByBucket := createStateIndicesFromStateSlot(ctx, slot)
+	if err := updateValueForIndices(ctx, indicesByBucket, blockRoot[:], tx); err != nil {
+		return errors.Wrap(err, "could not update DB indices")
+	}
+	enc := make([]byte, 0, len(baseRoot)+len(diff))
+	enc = append(enc, baseRoot[:]...)
+	enc = append(enc, diff...)
+	if err := tx.Bucket(stateDiffBucket).Put(blockRoot[:], enc); err != nil {
+		return err
+	}
+	return tx.Bucket(stateDiffBasesBucket).Put(baseRoot[:], []byte{})
+}
+
+// stateFromDiff reconstructs the state of the block root from its state diff and base state. It
+// returns nil if there is no state diff for the block root.
+func (s *Store) stateFromDiff(ctx context.Context, blockRoot [32]byte) (state.BeaconState, error) {
+	ctx, span := trace.StartSpan(ctx, "BeaconDB.stateFromDiff")
+	defer span.End()
+
+	var baseRoot [32]byte
+	var diff []byte
+	if err := s.db.View(func(tx *bolt.Tx) error {
+		enc := tx.Bucket(stateDiffBucket).Get(blockRoot[:])
+		if enc == nil {
+			return nil
+		}
+		if len(enc) < len(baseRoot) {
+			return errors.Errorf("invalid state diff length %d", len(enc))
+		}
+		copy(baseRoot[:], enc[:len(baseRoot)])
+		diff = make([]byte, len(enc)-len(baseRoot))
+		copy(diff, enc[len(baseRoot):])
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if diff == nil {
+		return nil, nil
+	}
+	base, err := s.State(ctx, baseRoot)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get base state %#x of state diff", baseRoot)
+	}
+	if base == nil || base.IsNil() {
+		return nil, errors.Wrapf(ErrNotFoundState, "base state %#x of state diff", baseRoot)
+	}
+	return statediff.Apply(base, diff)
+}
+
+// deleteStateDiff removes the state diff of the block root and its indices.
+func (s *Store) deleteStateDiff(ctx context.Context, tx *bolt.Tx, blockRoot [32]byte) error {
+	slot, err := s.slotByBlockRoot(ctx, tx, blockRoot[:])
+	if err != nil {
+		return err
+	}
+	indicesByBucket := createStateIndicesFromStateSlot(ctx, slot)
+	if err := deleteValueForIndices(ctx, indicesByBucket, blockRoot[:], tx); err != nil {
+		return errors.Wrap(err, "could not delete root for DB indices")
+	}
+	return tx.Bucket(stateDiffBucket).Delete(blockRoot[:])
+}
@@ -0,0 +1,118 @@
+package kv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/blocks"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/util"
+	bolt "go.etcd.io/bbolt"
+)
+
+func stateWithBalance(t *testing.T, slot primitives.Slot, balance uint64) state.BeaconState {
+	st, _ := util.DeterministicGenesisState(t, 32)
+	require.NoError(t, st.SetSlot(slot))
+	require.NoError(t, st.UpdateBalancesAtIndex(1, balance))
+	return st
+}
+
+func saveBlockAtSlot(t *testing.T, db *Store, slot primitives.Slot, parentRoot [32]byte) [32]byte {
+	b := util.NewBeaconBlock()
+	b.Block.Slot = slot
+	b.Block.ParentRoot = parentRoot[:]
+	r, err := b.Block.HashTreeRoot()
+	require.NoError(t, err)
+	wsb, err := blocks.NewSignedBeaconBlock(b)
+	require.NoError(t, err)
+	require.NoError(t, db.SaveBlock(context.Background(), wsb))
+	return r
+}
+
+func TestStore_SaveStateDiff(t *testing.T) {
+	db := setupDB(t)
+	ctx := context.Background()
+
+	base := stateWithBalance(t, 64, 1)
+	baseRoot := saveBlockAtSlot(t, db, 64, [32]byte{})
+	st := stateWithBalance(t, 96, 2)
+	root := saveBlockAtSlot(t, db, 96, baseRoot)
+
+	// The base state must be saved in full first.
+	require.ErrorContains(t, "state not found", db.SaveStateDiff(ctx, root, st, baseRoot, base))
+	require.NoError(t, db.SaveState(ctx, base, baseRoot))
+	require.NoError(t, db.SaveStateDiff(ctx, root, st, baseRoot, base))
+
+	require.Equal(t, true, db.HasState(ctx, root))
+	got, err := db.State(ctx, root)
+	require.NoError(t, err)
+	assert.DeepSSZEqual(t, st.ToProtoUnsafe(), got.ToProtoUnsafe())
+	highest, err := db.HighestSlotStatesBelow(ctx, 97)
+	require.NoError(t, err)
+	assert.Equal(t, primitives.Slot(96), highest[0].Slot())
+
+	// The base state is kept as long as state diffs are based on it.
+	require.NoError(t, db.DeleteState(ctx, baseRoot))
+	require.Equal(t, true, db.HasState(ctx, baseRoot))
+
+	require.NoError(t, db.DeleteState(ctx, root))
+	require.Equal(t, false, db.HasState(ctx, root))
+	got, err = db.State(ctx, root)
+	require.NoError(t, err)
+	assert.Equal(t, nil, got)
+}
+
+func TestStore_MigrateStatesToDiffs(t *testing.T) {
+	db := setupDB(t)
+	ctx := context.Background()
+	slotsPerEpoch := params.BeaconConfig().SlotsPerEpoch
+	slotsPerArchivedPoint := 2 * slotsPerEpoch
+
+	genesisRoot := saveBlockAtSlot(t, db, 0, [32]byte{})
+	require.NoError(t, db.SaveGenesisBlockRoot(ctx, genesisRoot))
+	states := make(map[[32]byte]state.BeaconState)
+	var roots [][32]byte
+	for i := primitives.Slot(1); i <= 6; i++ {
+		slot := i * slotsPerEpoch
+		parentRoot := genesisRoot
+		if len(roots) > 0 {
+			parentRoot = roots[len(roots)-1]
+		}
+		root := saveBlockAtSlot(t, db, slot, parentRoot)
+		st := stateWithBalance(t, slot, uint64(i))
+		require.NoError(t, db.SaveState(ctx, st, root))
+		states[root] = st
+		roots = append(roots, root)
+	}
+	// The state of slot 160 is finalized, the state of slot 192 is not.
+	require.NoError(t, db.SaveFinalizedCheckpoint(ctx, &zondpb.Checkpoint{Epoch: 6, Root: roots[4][:]}))
+
+	require.NoError(t, db.MigrateStatesToDiffs(ctx, slotsPerArchivedPoint))
+	// Running the migration again is a no-op.
+	require.NoError(t, db.MigrateStatesToDiffs(ctx, slotsPerArchivedPoint))
+
+	// Slots 32, 64 and 128 start an archived point interval, 160 is finalized and 192 is not finalized.
+	wantDiffs := map[[32]byte]bool{roots[2]: true}
+	require.NoError(t, db.db.View(func(tx *bolt.Tx) error {
+		for _, root := range roots {
+			isDiff := tx.Bucket(stateDiffBucket).Get(root[:]) != nil
+			assert.Equal(t, wantDiffs[root], isDiff)
+			assert.Equal(t, !wantDiffs[root], len(tx.Bucket(stateBucket).Get(root[:])) > 0)
+		}
+		return nil
+	}))
+	for _, root := range roots {
+		got, err := db.State(ctx, root)
+		require.NoError(t, err)
+		assert.DeepSSZEqual(t, states[root].ToProtoUnsafe(), got.ToProtoUnsafe())
+	}
+
+	// State diffs are not cleaned up as dirty states.
+	require.NoError(t, db.CleanUpDirtyStates(ctx, slotsPerArchivedPoint))
+	require.Equal(t, true, db.HasState(ctx, roots[2]))
+}
@@ -0,0 +1,65 @@
+package kv
+
+import (
+	"context"
+
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	bolt "go.etcd.io/bbolt"
+	"go.opencensus.io/trace"
+)
+
+// MonitoredValidators returns the validator indices and the public keys tracked by the validator monitor.
+func (s *Store) MonitoredValidators(ctx context.Context) ([]primitives.ValidatorIndex, [][]byte, error) {
+	_, span := trace.StartSpan(ctx, "BeaconDB.MonitoredValidators")
+	defer span.End()
+
+	var indices []primitives.ValidatorIndex
+	var pubkeys [][]byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(monitoredValidatorIndicesBucket).ForEach(func(k, _ []byte) error {
+			indices = append(indices, primitives.ValidatorIndex(bytesutil.BytesToUint64BigEndian(k)))
+			return nil
+		}); err != nil {
+			return err
+		}
+		return tx.Bucket(monitoredValidatorPubkeysBucket).ForEach(func(k, _ []byte) error {
+			pubkeys = append(pubkeys, bytesutil.SafeCopyBytes(k))
+			return nil
+		})
+	})
+	return indices, pubkeys, err
+}
+
+// SaveMonitoredValidators replaces the validator indices and the public keys tracked by the validator monitor.
+func (s *Store) SaveMonitoredValidators(ctx context.Context, indices []primitives.ValidatorIndex, pubkeys [][]byte) error {
+	_, span := trace.StartSpan(ctx, "BeaconDB.SaveMonitoredValidators")
+	defer span.End()
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{monitoredValidatorIndicesBucket, monitoredValidatorPubkeysBucket} {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+		}
+		indicesBkt, err := tx.CreateBucket(monitoredValidatorIndicesBucket)
+		if err != nil {
+			return err
+		}
+		for _, idx := range indices {
+			if err := indicesBkt.Put(bytesutil.Uint64ToBytesBigEndian(uint64(idx)), []byte{}); err != nil {
+				return err
+			}
+		}
+		pubkeysBkt, err := tx.CreateBucket(monitoredValidatorPubkeysBucket)
+		if err != nil {
+			return err
+		}
+		for _, pubkey := range pubkeys {
+			if err := pubkeysBkt.Put(pubkey, []byte{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
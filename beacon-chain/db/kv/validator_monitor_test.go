@@ -0,0 +1,33 @@
+package kv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+)
+
+func TestStore_MonitoredValidators(t *testing.T) {
+	db := setupDB(t)
+	ctx := context.Background()
+
+	indices, pubkeys, err := db.MonitoredValidators(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(indices))
+	assert.Equal(t, 0, len(pubkeys))
+
+	require.NoError(t, db.SaveMonitoredValidators(ctx, []primitives.ValidatorIndex{300, 2, 1}, [][]byte{{'b'}, {'a'}}))
+	indices, pubkeys, err = db.MonitoredValidators(ctx)
+	require.NoError(t, err)
+	assert.DeepEqual(t, []primitives.ValidatorIndex{1, 2, 300}, indices)
+	assert.DeepEqual(t, [][]byte{{'a'}, {'b'}}, pubkeys)
+
+	// Saving replaces the previously tracked validators.
+	require.NoError(t, db.SaveMonitoredValidators(ctx, []primitives.ValidatorIndex{5}, nil))
+	indices, pubkeys, err = db.MonitoredValidators(ctx)
+	require.NoError(t, err)
+	assert.DeepEqual(t, []primitives.ValidatorIndex{5}, indices)
+	assert.Equal(t, 0, len(pubkeys))
+}
@@ -0,0 +1,184 @@
+package execution
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/theQRL/qrysm/v4/config/features"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/runtime/version"
+)
+
+// engineMethod is a kind of engine API call, which may be served by several method versions.
+type engineMethod int
+
+const (
+	newPayloadCall engineMethod = iota
+	forkchoiceUpdatedCall
+	getPayloadCall
+)
+
+func (m engineMethod) String() string {
+	switch m {
+	case newPayloadCall:
+		return "newPayload"
+	case forkchoiceUpdatedCall:
+		return "forkchoiceUpdated"
+	case getPayloadCall:
+		return "getPayload"
+	default:
+		return "unknown"
+	}
+}
+
+// forkMethods lists, for each fork, the method versions able to serve each kind of call, in order
+// of preference. The newPayloadV2 and forkchoiceUpdatedV2 methods also accept the V1 payloads
+// and payload attributes of Bellatrix, so they are preferred when the execution client has them.
+var forkMethods = map[int]map[engineMethod][]string{
+	version.Bellatrix: {
+		newPayloadCall:        {NewPayloadMethodV2, NewPayloadMethod},
+		forkchoiceUpdatedCall: {ForkchoiceUpdatedMethodV2, ForkchoiceUpdatedMethod},
+		getPayloadCall:        {GetPayloadMethod},
+	},
+	version.Capella: {
+		newPayloadCall:        {NewPayloadMethodV2},
+		forkchoiceUpdatedCall: {ForkchoiceUpdatedMethodV2},
+		getPayloadCall:        {GetPayloadMethodV2},
+	},
+}
+
+// defaultMethods are the method versions used when capabilities could not be negotiated, as
+// with execution clients predating engine_exchangeCapabilities.
+var defaultMethods = map[int]map[engineMethod]string{
+	version.Bellatrix: {
+		newPayloadCall:        NewPayloadMethod,
+		forkchoiceUpdatedCall: ForkchoiceUpdatedMethod,
+		getPayloadCall:        GetPayloadMethod,
+	},
+	version.Capella: {
+		newPayloadCall:        NewPayloadMethodV2,
+		forkchoiceUpdatedCall: ForkchoiceUpdatedMethodV2,
+		getPayloadCall:        GetPayloadMethodV2,
+	},
+}
+
+// engineCapabilities is the set of engine methods supported by the execution client, as
+// negotiated with engine_exchangeCapabilities. Its zero value has not been negotiated.
+type engineCapabilities struct {
+	sync.RWMutex
+	negotiated bool
+	supported  map[string]bool
+}
+
+func (c *engineCapabilities) set(methods []string) {
+	c.Lock()
+	defer c.Unlock()
+	c.supported = make(map[string]bool, len(methods))
+	for _, m := range methods {
+		c.supported[m] = true
+	}
+	c.negotiated = true
+}
+
+func (c *engineCapabilities) reset() {
+	c.Lock()
+	defer c.Unlock()
+	c.supported = nil
+	c.negotiated = false
+}
+
+// method returns the method version to use for a call at the given fork.
+func (c *engineCapabilities) method(m engineMethod, fork int) (string, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if !c.negotiated {
+		name, ok := defaultMethods[fork][m]
+		if !ok {
+			return "", errors.Errorf("no %s method for fork %s", m, version.String(fork))
+		}
+		return name, nil
+	}
+	candidates, ok := forkMethods[fork][m]
+	if !ok {
+		return "", errors.Errorf("no %s method for fork %s", m, version.String(fork))
+	}
+	for _, name := range candidates {
+		if c.supported[name] {
+			return name, nil
+		}
+	}
+	return "", errors.Wrapf(
+		ErrMissingCapability,
+		"%s at fork %s requires one of %v, please update your execution client",
+		m, version.String(fork), candidates,
+	)
+}
+
+// missing returns, for each fork scheduled in the beacon config, the calls the execution client
+// supports no method version for.
+func (c *engineCapabilities) missing() map[string][]string {
+	missing := make(map[string][]string)
+	for _, fork := range scheduledExecutionForks() {
+		for m := range forkMethods[fork] {
+			if _, err := c.method(m, fork); err != nil {
+				missing[version.String(fork)] = append(missing[version.String(fork)], m.String())
+			}
+		}
+		sort.Strings(missing[version.String(fork)])
+	}
+	return missing
+}
+
+// scheduledExecutionForks returns the forks with an execution payload scheduled in the beacon config.
+func scheduledExecutionForks() []int {
+	cfg := params.BeaconConfig()
+	var forks []int
+	if cfg.BellatrixForkEpoch != cfg.FarFutureEpoch {
+		forks = append(forks, version.Bellatrix)
+	}
+	if cfg.CapellaForkEpoch != cfg.FarFutureEpoch {
+		forks = append(forks, version.Capella)
+	}
+	return forks
+}
+
+// negotiateCapabilities exchanges capabilities with the given execution client, so that method
+// versions are picked from the set it supports. Execution clients which do not implement
+// engine_exchangeCapabilities are called with the default method versions. An execution client
+// supporting no method version for a call of a scheduled fork is rejected, and the capabilities
+// negotiated with the previous client are kept.
+func (s *Service) negotiateCapabilities(ctx context.Context, client RPCClient) error {
+	methods, err := exchangeCapabilities(ctx, client)
+	if err != nil {
+		s.capabilities.reset()
+		if errors.Is(err, ErrMethodNotFound) {
+			log.Warn("Execution client does not support engine_exchangeCapabilities, using default engine method versions")
+			return nil
+		}
+		return errors.Wrap(err, "could not exchange capabilities with execution client")
+	}
+	negotiated := &engineCapabilities{}
+	negotiated.set(methods)
+	if missing := negotiated.missing(); len(missing) != 0 {
+		return errors.Wrapf(
+			ErrMissingCapability,
+			"no engine method for %v required by scheduled forks, please update your execution client",
+			missing,
+		)
+	}
+	s.capabilities.set(methods)
+	log.WithField("methods", len(methods)).Debug("Negotiated engine API capabilities")
+	return nil
+}
+
+// payloadBodiesSupported returns whether the engine_getPayloadBodiesBy* methods can be called.
+func (s *Service) payloadBodiesSupported(method string) bool {
+	if features.Get().EnableOptionalEngineMethods {
+		return true
+	}
+	s.capabilities.RLock()
+	defer s.capabilities.RUnlock()
+	return s.capabilities.negotiated && s.capabilities.supported[method]
+}
@@ -0,0 +1,164 @@
+package execution
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/theQRL/go-zond/rpc"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/blocks"
+	pb "github.com/theQRL/qrysm/v4/proto/engine/v1"
+	"github.com/theQRL/qrysm/v4/runtime/version"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+)
+
+// engineServer serves the results of the given engine methods, and answers method not found
+// to every other method.
+func engineServer(t *testing.T, results map[string]interface{}) *Service {
+	s, _ := countingEngineServer(t, results)
+	return s
+}
+
+// countingEngineServer is an engineServer which also returns the number of calls to each method.
+func countingEngineServer(t *testing.T, results map[string]interface{}) (*Service, func(method string) int) {
+	var lock sync.Mutex
+	calls := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		defer func() {
+			require.NoError(t, r.Body.Close())
+		}()
+		req := struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		lock.Lock()
+		calls[req.Method]++
+		lock.Unlock()
+		resp := map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+		}
+		if res, ok := results[req.Method]; ok {
+			resp["result"] = res
+		} else {
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "method not found"}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	t.Cleanup(srv.Close)
+	rpcClient, err := rpc.DialHTTP(srv.URL)
+	require.NoError(t, err)
+	t.Cleanup(rpcClient.Close)
+	return &Service{rpcClient: rpcClient}, func(method string) int {
+		lock.Lock()
+		defer lock.Unlock()
+		return calls[method]
+	}
+}
+
+// forkEngineMethods are the engine methods serving every call of the execution forks.
+var forkEngineMethods = []string{
+	NewPayloadMethod, NewPayloadMethodV2,
+	ForkchoiceUpdatedMethod, ForkchoiceUpdatedMethodV2,
+	GetPayloadMethod, GetPayloadMethodV2,
+}
+
+func TestEngineCapabilities_Method(t *testing.T) {
+	c := &engineCapabilities{}
+	m, err := c.method(newPayloadCall, version.Bellatrix)
+	require.NoError(t, err)
+	assert.Equal(t, NewPayloadMethod, m)
+	m, err = c.method(getPayloadCall, version.Capella)
+	require.NoError(t, err)
+	assert.Equal(t, GetPayloadMethodV2, m)
+	_, err = c.method(newPayloadCall, version.Phase0)
+	require.ErrorContains(t, "no newPayload method for fork phase0", err)
+
+	c.set([]string{NewPayloadMethod, NewPayloadMethodV2, ForkchoiceUpdatedMethod})
+	m, err = c.method(newPayloadCall, version.Bellatrix)
+	require.NoError(t, err)
+	assert.Equal(t, NewPayloadMethodV2, m)
+	m, err = c.method(forkchoiceUpdatedCall, version.Bellatrix)
+	require.NoError(t, err)
+	assert.Equal(t, ForkchoiceUpdatedMethod, m)
+	_, err = c.method(forkchoiceUpdatedCall, version.Capella)
+	require.ErrorIs(t, err, ErrMissingCapability)
+	require.ErrorContains(t, "forkchoiceUpdated at fork capella requires one of [engine_forkchoiceUpdatedV2]", err)
+
+	c.reset()
+	m, err = c.method(forkchoiceUpdatedCall, version.Capella)
+	require.NoError(t, err)
+	assert.Equal(t, ForkchoiceUpdatedMethodV2, m)
+}
+
+func TestEngineCapabilities_Missing(t *testing.T) {
+	params.SetupTestConfigCleanup(t)
+	cfg := params.BeaconConfig().Copy()
+	cfg.BellatrixForkEpoch = 0
+	cfg.CapellaForkEpoch = 1
+	params.OverrideBeaconConfig(cfg)
+
+	c := &engineCapabilities{}
+	assert.Equal(t, 0, len(c.missing()))
+	c.set([]string{NewPayloadMethod, ForkchoiceUpdatedMethod, GetPayloadMethod, GetPayloadMethodV2})
+	assert.DeepEqual(t, map[string][]string{"capella": {"forkchoiceUpdated", "newPayload"}}, c.missing())
+}
+
+func TestService_NegotiateCapabilities(t *testing.T) {
+	ctx := context.Background()
+	t.Run("negotiated", func(t *testing.T) {
+		s := engineServer(t, map[string]interface{}{
+			ExchangeCapabilities: append([]string{GetPayloadBodiesByHashV1}, forkEngineMethods...),
+		})
+		require.NoError(t, s.negotiateCapabilities(ctx, s.rpcClient))
+		m, err := s.capabilities.method(newPayloadCall, version.Bellatrix)
+		require.NoError(t, err)
+		assert.Equal(t, NewPayloadMethodV2, m)
+		assert.Equal(t, true, s.payloadBodiesSupported(GetPayloadBodiesByHashV1))
+		assert.Equal(t, false, s.payloadBodiesSupported(GetPayloadBodiesByRangeV1))
+	})
+	t.Run("execution client without exchangeCapabilities", func(t *testing.T) {
+		s := engineServer(t, map[string]interface{}{})
+		s.capabilities.set([]string{NewPayloadMethodV2})
+		require.NoError(t, s.negotiateCapabilities(ctx, s.rpcClient))
+		m, err := s.capabilities.method(newPayloadCall, version.Bellatrix)
+		require.NoError(t, err)
+		assert.Equal(t, NewPayloadMethod, m)
+		assert.Equal(t, false, s.payloadBodiesSupported(GetPayloadBodiesByHashV1))
+	})
+	t.Run("missing capability", func(t *testing.T) {
+		params.SetupTestConfigCleanup(t)
+		cfg := params.BeaconConfig().Copy()
+		cfg.BellatrixForkEpoch = 0
+		cfg.CapellaForkEpoch = 1
+		params.OverrideBeaconConfig(cfg)
+
+		s := engineServer(t, map[string]interface{}{
+			ExchangeCapabilities: []string{NewPayloadMethod, ForkchoiceUpdatedMethod, GetPayloadMethod},
+		})
+		s.capabilities.set([]string{NewPayloadMethodV2})
+		err := s.negotiateCapabilities(ctx, s.rpcClient)
+		require.ErrorIs(t, err, ErrMissingCapability)
+		require.ErrorContains(t, "capella:[forkchoiceUpdated getPayload newPayload]", err)
+		// The execution client is rejected, so the previous capabilities are kept.
+		m, err := s.capabilities.method(newPayloadCall, version.Bellatrix)
+		require.NoError(t, err)
+		assert.Equal(t, NewPayloadMethodV2, m)
+	})
+}
+
+func TestService_NewPayload_MissingCapability(t *testing.T) {
+	s := engineServer(t, map[string]interface{}{})
+	s.capabilities.set([]string{NewPayloadMethod})
+	payload, err := blocks.WrappedExecutionPayloadCapella(&pb.ExecutionPayloadCapella{}, 0)
+	require.NoError(t, err)
+	_, err = s.NewPayload(context.Background(), payload)
+	require.ErrorIs(t, err, ErrMissingCapability)
+}
@@ -0,0 +1,111 @@
+package execution
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+)
+
+// initDepositSnapshot starts the deposit trie from a finalized deposit snapshot when no deposit has
+// been processed yet, so that only the deposit logs after the snapshot's execution block are
+// processed. The snapshot given at startup is preferred over the one saved in the database. The
+// snapshot must be the deposit tree voted for in the eth1 data of the finalized state, and is
+// rejected otherwise.
+func (s *Service) initDepositSnapshot(ctx context.Context) error {
+	if s.depositTrie.NumOfItems() > 0 {
+		return nil
+	}
+	snapshot := s.cfg.depositSnapshot
+	if snapshot == nil {
+		var err error
+		snapshot, err = s.cfg.beaconDB.DepositSnapshot(ctx)
+		if err != nil {
+			return errors.Wrap(err, "could not retrieve deposit snapshot")
+		}
+	}
+	if snapshot == nil || snapshot.DepositCount() == 0 {
+		return nil
+	}
+	genState, err := s.cfg.beaconDB.GenesisState(ctx)
+	if err != nil {
+		return err
+	}
+	if genState == nil || genState.IsNil() {
+		log.Warn("Ignoring deposit snapshot as no genesis state is saved, deposit logs will be processed from the deposit contract deployment")
+		return nil
+	}
+	finalizedState := genState
+	if fState := s.cfg.finalizedStateAtStartup; fState != nil && !fState.IsNil() {
+		finalizedState = fState
+	}
+	eth1Data := finalizedState.Eth1Data()
+	if eth1Data == nil || snapshot.DepositCount() != eth1Data.DepositCount {
+		var eth1DataDeposits uint64
+		if eth1Data != nil {
+			eth1DataDeposits = eth1Data.DepositCount
+		}
+		log.WithFields(logrus.Fields{
+			"snapshotDeposits": snapshot.DepositCount(),
+			"eth1DataDeposits": eth1DataDeposits,
+		}).Warn("Ignoring deposit snapshot which cannot be verified against the eth1 data of the finalized state, deposit logs will be processed from the deposit contract deployment")
+		return nil
+	}
+	if err := snapshot.VerifyEth1Data(eth1Data); err != nil {
+		return errors.Wrap(err, "deposit snapshot does not match the eth1 data of the finalized state")
+	}
+	// Deposits in the snapshot cannot be proven, so all of them must already be included in the chain.
+	includedDeposits := finalizedState.Eth1DepositIndex()
+	if snapshot.DepositCount() > includedDeposits {
+		log.WithFields(logrus.Fields{
+			"snapshotDeposits": snapshot.DepositCount(),
+			"includedDeposits": includedDeposits,
+		}).Warn("Ignoring deposit snapshot with deposits not yet included in the finalized state, deposit logs will be processed from the deposit contract deployment")
+		return nil
+	}
+
+	depositTrie, err := snapshot.ToTrie()
+	if err != nil {
+		return errors.Wrap(err, "could not build deposit trie from snapshot")
+	}
+	if err := s.cfg.depositCache.InsertDepositSnapshot(ctx, snapshot); err != nil {
+		return errors.Wrap(err, "could not insert deposit snapshot into cache")
+	}
+	s.depositTrie = depositTrie
+	s.lastReceivedMerkleIndex = int64(snapshot.DepositCount()) - 1 // lint:ignore uintcast -- Deposit count should not exceed int64 in your lifetime.
+	s.latestEth1DataLock.Lock()
+	s.latestEth1Data.LastRequestedBlock = snapshot.ExecutionBlockHeight()
+	s.latestEth1DataLock.Unlock()
+	s.chainStartData = &zondpb.ChainStartData{
+		Chainstarted:       true,
+		GenesisTime:        genState.GenesisTime(),
+		GenesisBlock:       0,
+		Eth1Data:           genState.Eth1Data(),
+		ChainstartDeposits: make([]*zondpb.Deposit, 0),
+	}
+	if err := s.savePowchainData(ctx); err != nil {
+		return errors.Wrap(err, "could not save execution chain data")
+	}
+	if err := s.cfg.beaconDB.SaveDepositSnapshot(ctx, snapshot); err != nil {
+		return errors.Wrap(err, "could not save deposit snapshot")
+	}
+	log.WithFields(logrus.Fields{
+		"depositCount":         snapshot.DepositCount(),
+		"executionBlockHeight": snapshot.ExecutionBlockHeight(),
+	}).Info("Started deposit tree from deposit snapshot")
+	return nil
+}
+
+// initDepositCacheFromSnapshot starts the deposit cache from the deposit snapshot saved in the
+// database, for a deposit trie which was itself started from a snapshot.
+func (s *Service) initDepositCacheFromSnapshot(ctx context.Context) error {
+	snapshot, err := s.cfg.beaconDB.DepositSnapshot(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not retrieve deposit snapshot")
+	}
+	if snapshot == nil {
+		return nil
+	}
+	return s.cfg.depositCache.InsertDepositSnapshot(ctx, snapshot)
+}
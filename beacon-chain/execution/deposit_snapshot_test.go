@@ -0,0 +1,181 @@
+package execution
+
+import (
+	"context"
+	"testing"
+
+	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositcache"
+	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositsnapshot"
+	dbutil "github.com/theQRL/qrysm/v4/beacon-chain/db/testing"
+	mockExecution "github.com/theQRL/qrysm/v4/beacon-chain/execution/testing"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/container/trie"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/util"
+)
+
+func testDepositSnapshot(t *testing.T, count int) *depositsnapshot.DepositTreeSnapshot {
+	items := make([][]byte, count)
+	for i := range items {
+		items[i] = []byte{byte(i + 1)}
+	}
+	depositTrie, err := trie.GenerateTrieFromItems(items, params.BeaconConfig().DepositContractTreeDepth)
+	require.NoError(t, err)
+	snapshot, err := depositsnapshot.SnapshotFromTrie(depositTrie, uint64(count), [32]byte{'a'}, 1000)
+	require.NoError(t, err)
+	return snapshot
+}
+
+// eth1DataOf returns the eth1 data voting for the deposit tree of a snapshot.
+func eth1DataOf(snapshot *depositsnapshot.DepositTreeSnapshot) *zondpb.Eth1Data {
+	root, hash := snapshot.DepositRoot(), snapshot.ExecutionBlockHash()
+	return &zondpb.Eth1Data{DepositRoot: root[:], DepositCount: snapshot.DepositCount(), BlockHash: hash[:]}
+}
+
+func TestNewService_DepositSnapshot(t *testing.T) {
+	ctx := context.Background()
+	beaconDB := dbutil.SetupDB(t)
+	srv, endpoint, err := mockExecution.SetupRPCServer()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+	genState, err := util.NewBeaconState()
+	require.NoError(t, err)
+	require.NoError(t, genState.SetEth1DepositIndex(5))
+	snapshot := testDepositSnapshot(t, 5)
+	require.NoError(t, genState.SetEth1Data(eth1DataOf(snapshot)))
+	require.NoError(t, beaconDB.SaveGenesisData(ctx, genState))
+
+	cache, err := depositcache.New()
+	require.NoError(t, err)
+	s, err := NewService(ctx,
+		WithHttpEndpoint(endpoint),
+		WithDatabase(beaconDB),
+		WithDepositCache(cache),
+		WithDepositSnapshot(snapshot),
+	)
+	require.NoError(t, err)
+	root, err := s.depositTrie.HashTreeRoot()
+	require.NoError(t, err)
+	assert.Equal(t, snapshot.DepositRoot(), root)
+	assert.Equal(t, int64(4), s.lastReceivedMerkleIndex)
+	assert.Equal(t, uint64(1000), s.latestEth1Data.LastRequestedBlock)
+	assert.Equal(t, true, s.chainStartData.Chainstarted)
+	assert.Equal(t, int64(4), cache.FinalizedDeposits(ctx).MerkleTrieIndex)
+	saved, err := beaconDB.DepositSnapshot(ctx)
+	require.NoError(t, err)
+	assert.DeepEqual(t, snapshot, saved)
+
+	// On restart, the deposit trie and cache are restored without a snapshot option.
+	cache, err = depositcache.New()
+	require.NoError(t, err)
+	s, err = NewService(ctx,
+		WithHttpEndpoint(endpoint),
+		WithDatabase(beaconDB),
+		WithDepositCache(cache),
+	)
+	require.NoError(t, err)
+	root, err = s.depositTrie.HashTreeRoot()
+	require.NoError(t, err)
+	assert.Equal(t, snapshot.DepositRoot(), root)
+	assert.Equal(t, int64(4), s.lastReceivedMerkleIndex)
+	assert.Equal(t, uint64(1000), s.latestEth1Data.LastRequestedBlock)
+	finalized := cache.FinalizedDeposits(ctx)
+	assert.Equal(t, int64(4), finalized.MerkleTrieIndex)
+	root, err = finalized.Deposits.HashTreeRoot()
+	require.NoError(t, err)
+	assert.Equal(t, snapshot.DepositRoot(), root)
+}
+
+func TestNewService_DepositSnapshotNotIncluded(t *testing.T) {
+	ctx := context.Background()
+	beaconDB := dbutil.SetupDB(t)
+	srv, endpoint, err := mockExecution.SetupRPCServer()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+	genState, err := util.NewBeaconState()
+	require.NoError(t, err)
+	require.NoError(t, genState.SetEth1DepositIndex(3))
+	snapshot := testDepositSnapshot(t, 5)
+	require.NoError(t, genState.SetEth1Data(eth1DataOf(snapshot)))
+	require.NoError(t, beaconDB.SaveGenesisData(ctx, genState))
+
+	cache, err := depositcache.New()
+	require.NoError(t, err)
+	s, err := NewService(ctx,
+		WithHttpEndpoint(endpoint),
+		WithDatabase(beaconDB),
+		WithDepositCache(cache),
+		WithDepositSnapshot(snapshot),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 0, s.depositTrie.NumOfItems())
+	assert.Equal(t, int64(-1), s.lastReceivedMerkleIndex)
+	saved, err := beaconDB.DepositSnapshot(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, (*depositsnapshot.DepositTreeSnapshot)(nil), saved)
+}
+
+func TestNewService_DepositSnapshotEth1DataMismatch(t *testing.T) {
+	ctx := context.Background()
+	srv, endpoint, err := mockExecution.SetupRPCServer()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+	snapshot := testDepositSnapshot(t, 5)
+
+	t.Run("other deposit count", func(t *testing.T) {
+		beaconDB := dbutil.SetupDB(t)
+		genState, err := util.NewBeaconState()
+		require.NoError(t, err)
+		require.NoError(t, genState.SetEth1DepositIndex(6))
+		require.NoError(t, genState.SetEth1Data(eth1DataOf(testDepositSnapshot(t, 6))))
+		require.NoError(t, beaconDB.SaveGenesisData(ctx, genState))
+		cache, err := depositcache.New()
+		require.NoError(t, err)
+		s, err := NewService(ctx,
+			WithHttpEndpoint(endpoint),
+			WithDatabase(beaconDB),
+			WithDepositCache(cache),
+			WithDepositSnapshot(snapshot),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, 0, s.depositTrie.NumOfItems())
+		saved, err := beaconDB.DepositSnapshot(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, (*depositsnapshot.DepositTreeSnapshot)(nil), saved)
+	})
+	for name, modify := range map[string]func(*zondpb.Eth1Data){
+		"deposit root": func(d *zondpb.Eth1Data) { d.DepositRoot = make([]byte, 32) },
+		"block hash":   func(d *zondpb.Eth1Data) { d.BlockHash = make([]byte, 32) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			beaconDB := dbutil.SetupDB(t)
+			genState, err := util.NewBeaconState()
+			require.NoError(t, err)
+			require.NoError(t, genState.SetEth1DepositIndex(5))
+			eth1Data := eth1DataOf(snapshot)
+			modify(eth1Data)
+			require.NoError(t, genState.SetEth1Data(eth1Data))
+			require.NoError(t, beaconDB.SaveGenesisData(ctx, genState))
+			cache, err := depositcache.New()
+			require.NoError(t, err)
+			_, err = NewService(ctx,
+				WithHttpEndpoint(endpoint),
+				WithDatabase(beaconDB),
+				WithDepositCache(cache),
+				WithDepositSnapshot(snapshot),
+			)
+			require.ErrorIs(t, err, depositsnapshot.ErrEth1DataMismatch)
+			saved, err := beaconDB.DepositSnapshot(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, (*depositsnapshot.DepositTreeSnapshot)(nil), saved)
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package execution
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/theQRL/go-zond/zondclient"
+	payloadattribute "github.com/theQRL/qrysm/v4/consensus-types/payload-attribute"
+	"github.com/theQRL/qrysm/v4/io/logs"
+	"github.com/theQRL/qrysm/v4/network"
+	pb "github.com/theQRL/qrysm/v4/proto/engine/v1"
+)
+
+var (
+	// defaultHealthCheckInterval is how often every execution endpoint is probed.
+	defaultHealthCheckInterval = 12 * time.Second
+	// defaultMaxSyncingDuration is how long the active execution endpoint may stay syncing
+	// before we fail over to a synced one.
+	defaultMaxSyncingDuration = 2 * time.Minute
+	// endpointProbeTimeout bounds a single health probe of an execution endpoint.
+	endpointProbeTimeout = 5 * time.Second
+	// endpointFailureThreshold is how many consecutive probes of the active execution endpoint
+	// must fail before we fail over to another one.
+	endpointFailureThreshold = 3
+	// endpointRecoveryThreshold is how many consecutive probes an execution endpoint must find it
+	// reachable and synced before we fail over to it, so that a flapping endpoint is not picked.
+	endpointRecoveryThreshold = 3
+)
+
+// endpointHealth is the last known health of an execution endpoint.
+type endpointHealth struct {
+	endpoint network.Endpoint
+	// failures is the number of consecutive failed probes of the endpoint, and healthy the number
+	// of consecutive probes which found it reachable and synced.
+	failures int
+	healthy  int
+	// latency of the last successful probe.
+	latency time.Duration
+	// syncingSince is when the endpoint was first reported syncing by its sync status, and
+	// engineSyncingSince when it first answered SYNCING to an engine API call. They are zero
+	// while the endpoint is synced. engineSyncingSince is only kept while the endpoint is active,
+	// as no engine API calls are made to the others to clear it.
+	syncingSince       time.Time
+	engineSyncingSince time.Time
+}
+
+// syncingFor returns for how long the endpoint has been syncing.
+func (h *endpointHealth) syncingFor(now time.Time) time.Duration {
+	var d time.Duration
+	for _, since := range []time.Time{h.syncingSince, h.engineSyncingSince} {
+		if !since.IsZero() && now.Sub(since) > d {
+			d = now.Sub(since)
+		}
+	}
+	return d
+}
+
+func (h *endpointHealth) syncing() bool {
+	return !h.syncingSince.IsZero() || !h.engineSyncingSince.IsZero()
+}
+
+// endpointMonitor tracks the health of the configured execution endpoints, and which of them
+// is currently in use.
+type endpointMonitor struct {
+	sync.RWMutex
+	endpoints []*endpointHealth
+	active    int
+}
+
+// init sets the endpoints to monitor. The first one is active.
+func (m *endpointMonitor) init(endpoints []network.Endpoint) {
+	m.Lock()
+	defer m.Unlock()
+	m.endpoints = make([]*endpointHealth, len(endpoints))
+	for i, e := range endpoints {
+		m.endpoints[i] = &endpointHealth{endpoint: e}
+	}
+	m.active = 0
+}
+
+func (m *endpointMonitor) count() int {
+	m.RLock()
+	defer m.RUnlock()
+	return len(m.endpoints)
+}
+
+func (m *endpointMonitor) all() []network.Endpoint {
+	m.RLock()
+	defer m.RUnlock()
+	endpoints := make([]network.Endpoint, len(m.endpoints))
+	for i, h := range m.endpoints {
+		endpoints[i] = h.endpoint
+	}
+	return endpoints
+}
+
+func (m *endpointMonitor) index(e network.Endpoint) int {
+	for i, h := range m.endpoints {
+		if h.endpoint.Equals(e) {
+			return i
+		}
+	}
+	return -1
+}
+
+// activeEndpoint returns the endpoint in use, if any endpoint is monitored.
+func (m *endpointMonitor) activeEndpoint() (network.Endpoint, bool) {
+	m.RLock()
+	defer m.RUnlock()
+	if len(m.endpoints) == 0 {
+		return network.Endpoint{}, false
+	}
+	return m.endpoints[m.active].endpoint, true
+}
+
+// setActive marks the given endpoint as the one in use. It is a no-op for an unknown endpoint.
+func (m *endpointMonitor) setActive(e network.Endpoint) {
+	m.Lock()
+	defer m.Unlock()
+	if i := m.index(e); i >= 0 && i != m.active {
+		m.endpoints[m.active].engineSyncingSince = time.Time{}
+		m.active = i
+	}
+}
+
+// next returns the endpoint following the given one, wrapping around. The given endpoint is
+// returned when it is the only one, or is unknown.
+func (m *endpointMonitor) next(e network.Endpoint) network.Endpoint {
+	m.RLock()
+	defer m.RUnlock()
+	i := m.index(e)
+	if i < 0 {
+		return e
+	}
+	return m.endpoints[(i+1)%len(m.endpoints)].endpoint
+}
+
+// update records the result of a probe of an endpoint.
+func (m *endpointMonitor) update(e network.Endpoint, syncing bool, latency time.Duration, err error, now time.Time) {
+	m.Lock()
+	defer m.Unlock()
+	i := m.index(e)
+	if i < 0 {
+		return
+	}
+	h := m.endpoints[i]
+	if err != nil {
+		h.failures++
+		h.healthy = 0
+		return
+	}
+	h.failures = 0
+	h.latency = latency
+	switch {
+	case !syncing:
+		h.syncingSince = time.Time{}
+		// The engine API status of the active endpoint is more accurate than its sync status, but
+		// that of an inactive endpoint comes from calls made before we failed over from it.
+		if i != m.active {
+			h.engineSyncingSince = time.Time{}
+		}
+		h.healthy++
+	case h.syncingSince.IsZero():
+		h.syncingSince = now
+		h.healthy = 0
+	default:
+		h.healthy = 0
+	}
+}
+
+// updateEngineStatus records whether the given endpoint answered SYNCING to an engine API call.
+func (m *endpointMonitor) updateEngineStatus(e network.Endpoint, syncing bool, now time.Time) {
+	m.Lock()
+	defer m.Unlock()
+	i := m.index(e)
+	if i < 0 {
+		return
+	}
+	h := m.endpoints[i]
+	switch {
+	case !syncing:
+		h.engineSyncingSince = time.Time{}
+	case h.engineSyncingSince.IsZero():
+		h.engineSyncingSince = now
+	}
+}
+
+// failoverCandidate returns the endpoint to switch to, if the probes of the active endpoint failed
+// endpointFailureThreshold times in a row or it has been syncing for longer than maxSyncing. The
+// candidate is the endpoint with the lowest latency among those found reachable and synced by the
+// last endpointRecoveryThreshold probes.
+func (m *endpointMonitor) failoverCandidate(now time.Time, maxSyncing time.Duration) (network.Endpoint, bool) {
+	m.RLock()
+	defer m.RUnlock()
+	if len(m.endpoints) < 2 {
+		return network.Endpoint{}, false
+	}
+	active := m.endpoints[m.active]
+	if active.failures < endpointFailureThreshold && active.syncingFor(now) <= maxSyncing {
+		return network.Endpoint{}, false
+	}
+	best := -1
+	for i, h := range m.endpoints {
+		if i == m.active || h.healthy < endpointRecoveryThreshold || h.syncing() {
+			continue
+		}
+		if best < 0 || h.latency < m.endpoints[best].latency {
+			best = i
+		}
+	}
+	if best < 0 {
+		return network.Endpoint{}, false
+	}
+	return m.endpoints[best].endpoint, true
+}
+
+// activeEndpoint returns the execution endpoint in use.
+func (s *Service) activeEndpoint() network.Endpoint {
+	if e, ok := s.endpoints.activeEndpoint(); ok {
+		return e
+	}
+	return s.cfg.currHttpEndpoint
+}
+
+// monitorEndpoints probes the execution endpoints at every health check interval, and fails over
+// to another endpoint when the active one is unhealthy.
+func (s *Service) monitorEndpoints(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.checkEndpoints(ctx)
+		case <-ctx.Done():
+			log.Debug("Context closed, exiting execution endpoint monitor")
+			return
+		}
+	}
+}
+
+// checkEndpoints probes every execution endpoint, and switches to a healthier endpoint if needed.
+func (s *Service) checkEndpoints(ctx context.Context) {
+	endpoints := s.endpoints.all()
+	var wg sync.WaitGroup
+	for _, e := range endpoints {
+		wg.Add(1)
+		go func(e network.Endpoint) {
+			defer wg.Done()
+			start := time.Now()
+			syncing, err := s.probeEndpoint(ctx, e)
+			latency := time.Since(start)
+			s.endpoints.update(e, syncing, latency, err, time.Now())
+
+			label := logs.MaskCredentialsLogging(e.Url)
+			if err != nil {
+				log.WithError(err).WithField("endpoint", label).Debug("Execution endpoint health check failed")
+				executionEndpointHealthy.WithLabelValues(label).Set(0)
+				return
+			}
+			healthy := float64(1)
+			if syncing {
+				healthy = 0
+			}
+			executionEndpointHealthy.WithLabelValues(label).Set(healthy)
+			executionEndpointLatency.WithLabelValues(label).Set(float64(latency.Milliseconds()))
+		}(e)
+	}
+	wg.Wait()
+
+	candidate, ok := s.endpoints.failoverCandidate(time.Now(), s.cfg.maxSyncingDuration)
+	if !ok {
+		return
+	}
+	if err := s.switchEndpoint(ctx, candidate); err != nil {
+		log.WithError(err).WithField("endpoint", logs.MaskCredentialsLogging(candidate.Url)).
+			Error("Could not fail over to execution endpoint")
+	}
+}
+
+// probeEndpoint returns whether the execution endpoint is syncing.
+func (s *Service) probeEndpoint(ctx context.Context, e network.Endpoint) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, endpointProbeTimeout)
+	defer cancel()
+	client, err := s.newRPCClientWithAuth(ctx, e)
+	if err != nil {
+		return false, err
+	}
+	defer client.Close()
+	progress, err := zondclient.NewClient(client).SyncProgress(ctx)
+	if err != nil {
+		return false, err
+	}
+	return progress != nil, nil
+}
+
+// switchEndpoint connects to the given execution endpoint in place of the active one, and sends it
+// the latest forkchoice state so that it follows the same head. Calls in flight on the previous
+// execution client complete before it is closed.
+func (s *Service) switchEndpoint(ctx context.Context, e network.Endpoint) error {
+	prev := s.activeEndpoint()
+	if err := s.setupExecutionClientConnections(ctx, e); err != nil {
+		return errors.Wrap(err, "could not connect to execution endpoint")
+	}
+	s.endpoints.setActive(e)
+	executionEndpointFailovers.Inc()
+	log.WithFields(logrus.Fields{
+		"from": logs.MaskCredentialsLogging(prev.Url),
+		"to":   logs.MaskCredentialsLogging(e.Url),
+	}).Warn("Switched execution endpoint")
+	s.resendForkchoiceState(ctx)
+	return nil
+}
+
+// setLatestForkchoiceState records the latest forkchoice state sent to the execution client.
+func (s *Service) setLatestForkchoiceState(state *pb.ForkchoiceState, v int) {
+	s.forkchoiceLock.Lock()
+	defer s.forkchoiceLock.Unlock()
+	s.latestForkchoiceState = state
+	s.latestForkchoiceVersion = v
+}
+
+// resendForkchoiceState sends the latest forkchoice state, without payload attributes, to the
+// active execution endpoint.
+func (s *Service) resendForkchoiceState(ctx context.Context) {
+	s.forkchoiceLock.RLock()
+	state, v := s.latestForkchoiceState, s.latestForkchoiceVersion
+	s.forkchoiceLock.RUnlock()
+	if state == nil {
+		return
+	}
+	_, _, err := s.ForkchoiceUpdated(ctx, state, payloadattribute.EmptyWithVersion(v))
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrAcceptedSyncingPayloadStatus):
+		log.Debug("Execution endpoint is syncing to the latest forkchoice state")
+	default:
+		log.WithError(err).Warn("Could not send the latest forkchoice state to execution endpoint")
+	}
+}
@@ -0,0 +1,311 @@
+package execution
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/theQRL/go-zond/common/hexutil"
+	"github.com/theQRL/qrysm/v4/config/params"
+	payloadattribute "github.com/theQRL/qrysm/v4/consensus-types/payload-attribute"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	"github.com/theQRL/qrysm/v4/network"
+	pb "github.com/theQRL/qrysm/v4/proto/engine/v1"
+	"github.com/theQRL/qrysm/v4/runtime/version"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+)
+
+// stubEngine is an execution client which only serves the methods needed to connect to it, check
+// its health and update its forkchoice.
+type stubEngine struct {
+	*httptest.Server
+	lock    sync.Mutex
+	syncing bool
+	heads   [][]byte
+}
+
+func newStubEngine(t *testing.T, syncing bool) *stubEngine {
+	e := &stubEngine{syncing: syncing}
+	e.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		defer func() {
+			require.NoError(t, r.Body.Close())
+		}()
+		req := struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		e.lock.Lock()
+		defer e.lock.Unlock()
+		resp := map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+		}
+		status := "VALID"
+		if e.syncing {
+			status = "SYNCING"
+		}
+		switch req.Method {
+		case "zond_chainId":
+			resp["result"] = hexutil.EncodeUint64(params.BeaconConfig().DepositChainID)
+		case "zond_syncing":
+			if e.syncing {
+				resp["result"] = map[string]string{"startingBlock": "0x0", "currentBlock": "0x1", "highestBlock": "0x10"}
+			} else {
+				resp["result"] = false
+			}
+		case ExchangeCapabilities:
+			resp["result"] = forkEngineMethods
+		case ForkchoiceUpdatedMethodV2:
+			state := &pb.ForkchoiceState{}
+			require.NoError(t, json.Unmarshal(req.Params[0], state))
+			e.heads = append(e.heads, state.HeadBlockHash)
+			resp["result"] = map[string]interface{}{
+				"payloadStatus": map[string]interface{}{"status": status},
+			}
+		default:
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "method not found"}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	t.Cleanup(e.Close)
+	return e
+}
+
+func (e *stubEngine) setSyncing(syncing bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.syncing = syncing
+}
+
+func (e *stubEngine) forkchoiceHeads() [][]byte {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.heads
+}
+
+// failoverService returns a service connected to the first of the given engines.
+func failoverService(t *testing.T, maxSyncing time.Duration, engines ...*stubEngine) *Service {
+	endpoints := make([]network.Endpoint, len(engines))
+	for i, e := range engines {
+		endpoints[i] = network.HttpEndpoint(e.URL)
+	}
+	s := &Service{
+		cfg: &config{
+			beaconNodeStatsUpdater: &NopBeaconNodeStatsUpdater{},
+			currHttpEndpoint:       endpoints[0],
+			fallbackHttpEndpoints:  endpoints[1:],
+			maxSyncingDuration:     maxSyncing,
+		},
+		rpcClient: RPCClientEmpty{},
+	}
+	s.endpoints.init(endpoints)
+	require.NoError(t, s.setupExecutionClientConnections(context.Background(), s.activeEndpoint()))
+	t.Cleanup(s.rpcClient.Close)
+	return s
+}
+
+func TestService_CheckEndpoints_FailoverWhenSyncingTooLong(t *testing.T) {
+	ctx := context.Background()
+	primary := newStubEngine(t, true)
+	fallback := newStubEngine(t, false)
+	s := failoverService(t, 100*time.Millisecond, primary, fallback)
+
+	head := bytesutil.PadTo([]byte{0x01}, 32)
+	state := &pb.ForkchoiceState{HeadBlockHash: head, SafeBlockHash: head, FinalizedBlockHash: head}
+	_, _, err := s.ForkchoiceUpdated(ctx, state, payloadattribute.EmptyWithVersion(version.Capella))
+	require.ErrorIs(t, err, ErrAcceptedSyncingPayloadStatus)
+
+	// The primary endpoint has only just started syncing.
+	for i := 0; i < endpointRecoveryThreshold; i++ {
+		s.checkEndpoints(ctx)
+	}
+	assert.Equal(t, primary.URL, s.ExecutionClientEndpoint())
+	assert.Equal(t, 0, len(fallback.forkchoiceHeads()))
+
+	time.Sleep(200 * time.Millisecond)
+	s.checkEndpoints(ctx)
+	assert.Equal(t, fallback.URL, s.ExecutionClientEndpoint())
+	require.Equal(t, 1, len(fallback.forkchoiceHeads()))
+	assert.DeepEqual(t, head, fallback.forkchoiceHeads()[0])
+}
+
+func TestService_CheckEndpoints_FailbackAfterEngineSyncing(t *testing.T) {
+	ctx := context.Background()
+	primary := newStubEngine(t, true)
+	fallback := newStubEngine(t, false)
+	s := failoverService(t, 100*time.Millisecond, primary, fallback)
+
+	head := bytesutil.PadTo([]byte{0x03}, 32)
+	state := &pb.ForkchoiceState{HeadBlockHash: head, SafeBlockHash: head, FinalizedBlockHash: head}
+	_, _, err := s.ForkchoiceUpdated(ctx, state, payloadattribute.EmptyWithVersion(version.Capella))
+	require.ErrorIs(t, err, ErrAcceptedSyncingPayloadStatus)
+	for i := 0; i < endpointRecoveryThreshold; i++ {
+		s.checkEndpoints(ctx)
+	}
+	time.Sleep(200 * time.Millisecond)
+	s.checkEndpoints(ctx)
+	require.Equal(t, fallback.URL, s.ExecutionClientEndpoint())
+
+	// The primary endpoint catches up, and is a candidate again once its probes find it synced.
+	primary.setSyncing(false)
+	for i := 0; i < endpointRecoveryThreshold; i++ {
+		s.checkEndpoints(ctx)
+	}
+	assert.Equal(t, fallback.URL, s.ExecutionClientEndpoint())
+	fallback.Close()
+	for i := 0; i < endpointFailureThreshold; i++ {
+		s.checkEndpoints(ctx)
+	}
+	assert.Equal(t, primary.URL, s.ExecutionClientEndpoint())
+}
+
+func TestService_CheckEndpoints_FailoverWhenUnreachable(t *testing.T) {
+	ctx := context.Background()
+	primary := newStubEngine(t, false)
+	slow := newStubEngine(t, false)
+	fallback := newStubEngine(t, false)
+	s := failoverService(t, time.Minute, primary, slow, fallback)
+
+	s.checkEndpoints(ctx)
+	assert.Equal(t, primary.URL, s.ExecutionClientEndpoint())
+
+	// A few failed probes of the primary endpoint are tolerated.
+	primary.Close()
+	for i := 0; i < endpointFailureThreshold-1; i++ {
+		s.checkEndpoints(ctx)
+		assert.Equal(t, primary.URL, s.ExecutionClientEndpoint())
+	}
+	s.checkEndpoints(ctx)
+	assert.NotEqual(t, primary.URL, s.ExecutionClientEndpoint())
+	// Requests go to the new endpoint.
+	head := bytesutil.PadTo([]byte{0x02}, 32)
+	_, _, err := s.ForkchoiceUpdated(ctx, &pb.ForkchoiceState{
+		HeadBlockHash:      head,
+		SafeBlockHash:      head,
+		FinalizedBlockHash: head,
+	}, payloadattribute.EmptyWithVersion(version.Capella))
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(slow.forkchoiceHeads())+len(fallback.forkchoiceHeads()))
+}
+
+func TestService_CheckEndpoints_NoSyncedFallback(t *testing.T) {
+	ctx := context.Background()
+	primary := newStubEngine(t, true)
+	fallback := newStubEngine(t, true)
+	s := failoverService(t, 0, primary, fallback)
+
+	s.checkEndpoints(ctx)
+	time.Sleep(10 * time.Millisecond)
+	s.checkEndpoints(ctx)
+	assert.Equal(t, primary.URL, s.ExecutionClientEndpoint())
+}
+
+// probe records n identical probes of an endpoint.
+func probe(m *endpointMonitor, e network.Endpoint, n int, syncing bool, latency time.Duration, err error, now time.Time) {
+	for i := 0; i < n; i++ {
+		m.update(e, syncing, latency, err, now)
+	}
+}
+
+func TestEndpointMonitor_FailoverCandidate(t *testing.T) {
+	a, b, c := network.HttpEndpoint("http://a"), network.HttpEndpoint("http://b"), network.HttpEndpoint("http://c")
+	now := time.Now()
+	m := &endpointMonitor{}
+	m.init([]network.Endpoint{a, b, c})
+	_, ok := m.failoverCandidate(now, time.Minute)
+	assert.Equal(t, false, ok)
+
+	probe(m, b, endpointRecoveryThreshold, false, 20*time.Millisecond, nil, now)
+	probe(m, c, endpointRecoveryThreshold, false, 10*time.Millisecond, nil, now)
+	probe(m, a, endpointFailureThreshold-1, false, 0, errProbe, now)
+	_, ok = m.failoverCandidate(now, time.Minute)
+	assert.Equal(t, false, ok, "Failed over before the failure threshold")
+	probe(m, a, 1, false, 0, errProbe, now)
+	e, ok := m.failoverCandidate(now, time.Minute)
+	require.Equal(t, true, ok)
+	assert.Equal(t, true, e.Equals(c))
+
+	// A syncing endpoint is never a candidate.
+	probe(m, c, 1, true, 10*time.Millisecond, nil, now)
+	e, ok = m.failoverCandidate(now, time.Minute)
+	require.Equal(t, true, ok)
+	assert.Equal(t, true, e.Equals(b))
+
+	// An endpoint is a candidate again only once synced for endpointRecoveryThreshold probes.
+	probe(m, b, 1, false, 0, errProbe, now)
+	probe(m, c, endpointRecoveryThreshold-1, false, 10*time.Millisecond, nil, now)
+	_, ok = m.failoverCandidate(now, time.Minute)
+	assert.Equal(t, false, ok)
+	probe(m, c, 1, false, 10*time.Millisecond, nil, now)
+	e, ok = m.failoverCandidate(now, time.Minute)
+	require.Equal(t, true, ok)
+	assert.Equal(t, true, e.Equals(c))
+
+	// The active endpoint answering SYNCING to engine calls for too long is replaced.
+	probe(m, a, 1, false, 0, nil, now)
+	m.updateEngineStatus(a, true, now)
+	_, ok = m.failoverCandidate(now.Add(time.Minute), time.Minute)
+	assert.Equal(t, false, ok)
+	e, ok = m.failoverCandidate(now.Add(2*time.Minute), time.Minute)
+	require.Equal(t, true, ok)
+	assert.Equal(t, true, e.Equals(c))
+	m.updateEngineStatus(a, false, now)
+	_, ok = m.failoverCandidate(now.Add(2*time.Minute), time.Minute)
+	assert.Equal(t, false, ok)
+	// The engine API status of another endpoint does not make the active one syncing.
+	m.updateEngineStatus(b, true, now)
+	_, ok = m.failoverCandidate(now.Add(2*time.Minute), time.Minute)
+	assert.Equal(t, false, ok)
+
+	assert.Equal(t, true, m.next(a).Equals(b))
+	assert.Equal(t, true, m.next(c).Equals(a))
+	m.setActive(b)
+	active, ok := m.activeEndpoint()
+	require.Equal(t, true, ok)
+	assert.Equal(t, true, active.Equals(b))
+}
+
+// closeNotifyingClient is an execution client reporting when it is closed.
+type closeNotifyingClient struct {
+	RPCClientEmpty
+	closed chan struct{}
+}
+
+func (c *closeNotifyingClient) Close() {
+	close(c.closed)
+}
+
+func TestService_SetRPCClient_ClosesPreviousOnceDrained(t *testing.T) {
+	prev := &closeNotifyingClient{closed: make(chan struct{})}
+	s := &Service{}
+	s.setRPCClient(network.Endpoint{}, prev, nil, nil)
+	client, release := s.acquireClient()
+	assert.Equal(t, RPCClient(prev), client)
+
+	s.setRPCClient(network.Endpoint{}, RPCClientEmpty{}, nil, nil)
+	client, releaseNext := s.acquireClient()
+	releaseNext()
+	assert.Equal(t, RPCClient(RPCClientEmpty{}), client)
+	select {
+	case <-prev.closed:
+		t.Fatal("Closed the previous client with a call in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	select {
+	case <-prev.closed:
+	case <-time.After(time.Second):
+		t.Fatal("Did not close the previous client")
+	}
+}
+
+var errProbe = errors.New("probe failed")
@@ -3,6 +3,7 @@ package execution
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"strings"
@@ -16,7 +17,6 @@ import (
 	"github.com/theQRL/go-zond/common/hexutil"
 	zondRPC "github.com/theQRL/go-zond/rpc"
 	"github.com/theQRL/qrysm/v4/beacon-chain/execution/types"
-	"github.com/theQRL/qrysm/v4/config/features"
 	fieldparams "github.com/theQRL/qrysm/v4/config/fieldparams"
 	"github.com/theQRL/qrysm/v4/config/params"
 	"github.com/theQRL/qrysm/v4/consensus-types/blocks"
@@ -124,44 +124,44 @@ func (s *Service) NewPayload(ctx context.Context, payload interfaces.ExecutionDa
 	defer cancel()
 	result := &pb.PayloadStatus{}
 
+	var fork int
 	switch payload.Proto().(type) {
 	case *pb.ExecutionPayload:
-		payloadPb, ok := payload.Proto().(*pb.ExecutionPayload)
-		if !ok {
-			return nil, errors.New("execution data must be a Bellatrix or Capella execution payload")
-		}
-		err := s.rpcClient.CallContext(ctx, result, NewPayloadMethod, payloadPb)
-		if err != nil {
-			return nil, handleRPCError(err)
-		}
+		fork = version.Bellatrix
 	case *pb.ExecutionPayloadCapella:
-		payloadPb, ok := payload.Proto().(*pb.ExecutionPayloadCapella)
-		if !ok {
-			return nil, errors.New("execution data must be a Capella execution payload")
-		}
-		err := s.rpcClient.CallContext(ctx, result, NewPayloadMethodV2, payloadPb)
-		if err != nil {
-			return nil, handleRPCError(err)
-		}
+		fork = version.Capella
 	default:
 		return nil, errors.New("unknown execution data type")
 	}
+	method, err := s.capabilities.method(newPayloadCall, fork)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := s.callEngine(ctx, result, method, payload.Proto())
+	if err != nil {
+		return nil, handleRPCError(err)
+	}
 
 	switch result.Status {
 	case pb.PayloadStatus_INVALID_BLOCK_HASH:
 		return nil, ErrInvalidBlockHashPayloadStatus
 	case pb.PayloadStatus_ACCEPTED, pb.PayloadStatus_SYNCING:
+		if result.Status == pb.PayloadStatus_SYNCING {
+			s.endpoints.updateEngineStatus(endpoint, true, time.Now())
+		}
 		return nil, ErrAcceptedSyncingPayloadStatus
 	case pb.PayloadStatus_INVALID:
+		s.endpoints.updateEngineStatus(endpoint, false, time.Now())
 		return result.LatestValidHash, ErrInvalidPayloadStatus
 	case pb.PayloadStatus_VALID:
+		s.endpoints.updateEngineStatus(endpoint, false, time.Now())
 		return result.LatestValidHash, nil
 	default:
 		return nil, ErrUnknownPayloadStatus
 	}
 }
 
-// ForkchoiceUpdated calls the engine_forkchoiceUpdatedV1 method via JSON-RPC.
+// ForkchoiceUpdated calls the engine_forkchoiceUpdatedVX method via JSON-RPC.
 func (s *Service) ForkchoiceUpdated(
 	ctx context.Context, state *pb.ForkchoiceState, attrs payloadattribute.Attributer,
 ) (*pb.PayloadIDBytes, []byte, error) {
@@ -180,28 +180,29 @@ func (s *Service) ForkchoiceUpdated(
 	if attrs == nil {
 		return nil, nil, errors.New("nil payload attributer")
 	}
+	var a interface{}
+	var err error
 	switch attrs.Version() {
 	case version.Bellatrix:
-		a, err := attrs.PbV1()
-		if err != nil {
-			return nil, nil, err
-		}
-		err = s.rpcClient.CallContext(ctx, result, ForkchoiceUpdatedMethod, state, a)
-		if err != nil {
-			return nil, nil, handleRPCError(err)
-		}
+		a, err = attrs.PbV1()
 	case version.Capella:
-		a, err := attrs.PbV2()
-		if err != nil {
-			return nil, nil, err
-		}
-		err = s.rpcClient.CallContext(ctx, result, ForkchoiceUpdatedMethodV2, state, a)
-		if err != nil {
-			return nil, nil, handleRPCError(err)
-		}
+		a, err = attrs.PbV2()
 	default:
 		return nil, nil, fmt.Errorf("unknown payload attribute version: %v", attrs.Version())
 	}
+	if err != nil {
+		return nil, nil, err
+	}
+	method, err := s.capabilities.method(forkchoiceUpdatedCall, attrs.Version())
+	if err != nil {
+		return nil, nil, err
+	}
+	// The state is kept to be sent again when failing over to another execution endpoint.
+	s.setLatestForkchoiceState(state, attrs.Version())
+	endpoint, err := s.callEngine(ctx, result, method, state, a)
+	if err != nil {
+		return nil, nil, handleRPCError(err)
+	}
 
 	if result.Status == nil {
 		return nil, nil, ErrNilResponse
@@ -209,10 +210,13 @@ func (s *Service) ForkchoiceUpdated(
 	resp := result.Status
 	switch resp.Status {
 	case pb.PayloadStatus_SYNCING:
+		s.endpoints.updateEngineStatus(endpoint, true, time.Now())
 		return nil, nil, ErrAcceptedSyncingPayloadStatus
 	case pb.PayloadStatus_INVALID:
+		s.endpoints.updateEngineStatus(endpoint, false, time.Now())
 		return nil, resp.LatestValidHash, ErrInvalidPayloadStatus
 	case pb.PayloadStatus_VALID:
+		s.endpoints.updateEngineStatus(endpoint, false, time.Now())
 		return result.PayloadId, resp.LatestValidHash, nil
 	default:
 		return nil, nil, ErrUnknownPayloadStatus
@@ -232,9 +236,17 @@ func (s *Service) GetPayload(ctx context.Context, payloadId [8]byte, slot primit
 	ctx, cancel := context.WithDeadline(ctx, d)
 	defer cancel()
 
+	fork := version.Bellatrix
 	if slots.ToEpoch(slot) >= params.BeaconConfig().CapellaForkEpoch {
+		fork = version.Capella
+	}
+	method, err := s.capabilities.method(getPayloadCall, fork)
+	if err != nil {
+		return nil, err
+	}
+	if fork == version.Capella {
 		result := &pb.ExecutionPayloadCapellaWithValue{}
-		err := s.rpcClient.CallContext(ctx, result, GetPayloadMethodV2, pb.PayloadIDBytes(payloadId))
+		err := s.callContext(ctx, result, method, pb.PayloadIDBytes(payloadId))
 		if err != nil {
 			return nil, handleRPCError(err)
 		}
@@ -244,8 +256,7 @@ func (s *Service) GetPayload(ctx context.Context, payloadId [8]byte, slot primit
 	}
 
 	result := &pb.ExecutionPayload{}
-	err := s.rpcClient.CallContext(ctx, result, GetPayloadMethod, pb.PayloadIDBytes(payloadId))
-	if err != nil {
+	if err := s.callContext(ctx, result, method, pb.PayloadIDBytes(payloadId)); err != nil {
 		return nil, handleRPCError(err)
 	}
 	return blocks.WrappedExecutionPayload(result)
@@ -265,7 +276,7 @@ func (s *Service) ExchangeTransitionConfiguration(
 	ctx, cancel := context.WithDeadline(ctx, d)
 	defer cancel()
 	result := &pb.TransitionConfiguration{}
-	if err := s.rpcClient.CallContext(ctx, result, ExchangeTransitionConfigurationMethod, cfg); err != nil {
+	if err := s.callContext(ctx, result, ExchangeTransitionConfigurationMethod, cfg); err != nil {
 		return handleRPCError(err)
 	}
 
@@ -296,15 +307,34 @@ func (s *Service) ExchangeTransitionConfiguration(
 	return nil
 }
 
+// ExchangeCapabilities calls the engine_exchangeCapabilities method via JSON-RPC, and returns the
+// engine methods supported by the execution client.
 func (s *Service) ExchangeCapabilities(ctx context.Context) ([]string, error) {
-	if !features.Get().EnableOptionalEngineMethods {
-		return nil, errors.New("optional engine methods not enabled")
-	}
 	ctx, span := trace.StartSpan(ctx, "powchain.engine-api-client.ExchangeCapabilities")
 	defer span.End()
 
+	client, release := s.acquireClient()
+	defer release()
+	return exchangeCapabilities(ctx, client)
+}
+
+// exchangeCapabilities calls engine_exchangeCapabilities with the given client, which need not be
+// the one attached to the service yet.
+func exchangeCapabilities(ctx context.Context, client RPCClient) ([]string, error) {
+	d := time.Now().Add(defaultEngineTimeout)
+	ctx, cancel := context.WithDeadline(ctx, d)
+	defer cancel()
+	var raw json.RawMessage
+	if err := client.CallContext(ctx, &raw, ExchangeCapabilities, supportedEngineEndpoints); err != nil {
+		return nil, handleRPCError(err)
+	}
+	// The specification returns a list of methods, which some execution clients wrap in an object.
 	result := &pb.ExchangeCapabilities{}
-	err := s.rpcClient.CallContext(ctx, &result, ExchangeCapabilities, supportedEngineEndpoints)
+	if err := json.Unmarshal(raw, &result.SupportedMethods); err != nil {
+		if err := json.Unmarshal(raw, result); err != nil {
+			return nil, errors.Wrap(err, "could not decode supported engine methods")
+		}
+	}
 
 	var unsupported []string
 	for _, s1 := range supportedEngineEndpoints {
@@ -322,7 +352,7 @@ func (s *Service) ExchangeCapabilities(ctx context.Context) ([]string, error) {
 	if len(unsupported) != 0 {
 		log.Warnf("Please update client, detected the following unsupported engine methods: %s", unsupported)
 	}
-	return result.SupportedMethods, handleRPCError(err)
+	return result.SupportedMethods, nil
 }
 
 // GetTerminalBlockHash returns the valid terminal block hash based on total difficulty.
@@ -415,7 +445,7 @@ func (s *Service) LatestExecutionBlock(ctx context.Context) (*pb.ExecutionBlock,
 	defer span.End()
 
 	result := &pb.ExecutionBlock{}
-	err := s.rpcClient.CallContext(
+	err := s.callContext(
 		ctx,
 		result,
 		ExecutionBlockByNumberMethod,
@@ -431,7 +461,7 @@ func (s *Service) ExecutionBlockByHash(ctx context.Context, hash common.Hash, wi
 	ctx, span := trace.StartSpan(ctx, "powchain.engine-api-client.ExecutionBlockByHash")
 	defer span.End()
 	result := &pb.ExecutionBlock{}
-	err := s.rpcClient.CallContext(ctx, result, ExecutionBlockByHashMethod, hash, withTxs)
+	err := s.callContext(ctx, result, ExecutionBlockByHashMethod, hash, withTxs)
 	return result, handleRPCError(err)
 }
 
@@ -457,7 +487,7 @@ func (s *Service) ExecutionBlocksByHashes(ctx context.Context, hashes []common.H
 		})
 		execBlks = append(execBlks, blk)
 	}
-	ioErr := s.rpcClient.BatchCall(elems)
+	ioErr := s.batchCall(elems)
 	if ioErr != nil {
 		return nil, ioErr
 	}
@@ -472,7 +502,7 @@ func (s *Service) ExecutionBlocksByHashes(ctx context.Context, hashes []common.H
 // HeaderByHash returns the relevant header details for the provided block hash.
 func (s *Service) HeaderByHash(ctx context.Context, hash common.Hash) (*types.HeaderInfo, error) {
 	var hdr *types.HeaderInfo
-	err := s.rpcClient.CallContext(ctx, &hdr, ExecutionBlockByHashMethod, hash, false /* no transactions */)
+	err := s.callContext(ctx, &hdr, ExecutionBlockByHashMethod, hash, false /* no transactions */)
 	if err == nil && hdr == nil {
 		err = ethereum.NotFound
 	}
@@ -482,7 +512,7 @@ func (s *Service) HeaderByHash(ctx context.Context, hash common.Hash) (*types.He
 // HeaderByNumber returns the relevant header details for the provided block number.
 func (s *Service) HeaderByNumber(ctx context.Context, number *big.Int) (*types.HeaderInfo, error) {
 	var hdr *types.HeaderInfo
-	err := s.rpcClient.CallContext(ctx, &hdr, ExecutionBlockByNumberMethod, toBlockNumArg(number), false /* no transactions */)
+	err := s.callContext(ctx, &hdr, ExecutionBlockByNumberMethod, toBlockNumArg(number), false /* no transactions */)
 	if err == nil && hdr == nil {
 		err = ethereum.NotFound
 	}
@@ -491,14 +521,14 @@ func (s *Service) HeaderByNumber(ctx context.Context, number *big.Int) (*types.H
 
 // GetPayloadBodiesByHash returns the relevant payload bodies for the provided block hash.
 func (s *Service) GetPayloadBodiesByHash(ctx context.Context, executionBlockHashes []common.Hash) ([]*pb.ExecutionPayloadBodyV1, error) {
-	if !features.Get().EnableOptionalEngineMethods {
-		return nil, errors.New("optional engine methods not enabled")
+	if !s.payloadBodiesSupported(GetPayloadBodiesByHashV1) {
+		return nil, errors.Wrapf(ErrMissingCapability, "%s is not supported", GetPayloadBodiesByHashV1)
 	}
 	ctx, span := trace.StartSpan(ctx, "powchain.engine-api-client.GetPayloadBodiesByHashV1")
 	defer span.End()
 
 	result := make([]*pb.ExecutionPayloadBodyV1, 0)
-	err := s.rpcClient.CallContext(ctx, &result, GetPayloadBodiesByHashV1, executionBlockHashes)
+	err := s.callContext(ctx, &result, GetPayloadBodiesByHashV1, executionBlockHashes)
 
 	for i, item := range result {
 		if item == nil {
@@ -513,14 +543,14 @@ func (s *Service) GetPayloadBodiesByHash(ctx context.Context, executionBlockHash
 
 // GetPayloadBodiesByRange returns the relevant payload bodies for the provided range.
 func (s *Service) GetPayloadBodiesByRange(ctx context.Context, start, count uint64) ([]*pb.ExecutionPayloadBodyV1, error) {
-	if !features.Get().EnableOptionalEngineMethods {
-		return nil, errors.New("optional engine methods not enabled")
+	if !s.payloadBodiesSupported(GetPayloadBodiesByRangeV1) {
+		return nil, errors.Wrapf(ErrMissingCapability, "%s is not supported", GetPayloadBodiesByRangeV1)
 	}
 	ctx, span := trace.StartSpan(ctx, "powchain.engine-api-client.GetPayloadBodiesByRangeV1")
 	defer span.End()
 
 	result := make([]*pb.ExecutionPayloadBodyV1, 0)
-	err := s.rpcClient.CallContext(ctx, &result, GetPayloadBodiesByRangeV1, start, count)
+	err := s.callContext(ctx, &result, GetPayloadBodiesByRangeV1, start, count)
 
 	for i, item := range result {
 		if item == nil {
@@ -564,6 +594,15 @@ func (s *Service) ReconstructFullBlock(
 		return blocks.BuildSignedBeaconBlockFromExecutionPayload(blindedBlock, payload)
 	}
 
+	if payloads := s.payloadsFromBodies(ctx, []interfaces.ExecutionData{header}, []int{blindedBlock.Version()}); payloads[0] != nil {
+		fullBlock, err := blocks.BuildSignedBeaconBlockFromExecutionPayload(blindedBlock, payloads[0].Proto())
+		if err != nil {
+			return nil, err
+		}
+		reconstructedExecutionPayloadCount.Add(1)
+		return fullBlock, nil
+	}
+
 	executionBlockHash := common.BytesToHash(header.BlockHash())
 	executionBlock, err := s.ExecutionBlockByHash(ctx, executionBlockHash, true /* with txs */)
 	if err != nil {
@@ -600,6 +639,8 @@ func (s *Service) ReconstructFullBellatrixBlockBatch(
 	executionHashes := []common.Hash{}
 	validExecPayloads := []int{}
 	zeroExecPayloads := []int{}
+	headers := make([]interfaces.ExecutionData, len(blindedBlocks))
+	versions := make([]int, len(blindedBlocks))
 	for i, b := range blindedBlocks {
 		if err := blocks.BeaconBlockIsNil(b); err != nil {
 			return nil, errors.Wrap(err, "cannot reconstruct bellatrix block from nil data")
@@ -614,16 +655,40 @@ func (s *Service) ReconstructFullBellatrixBlockBatch(
 		if header.IsNil() {
 			return nil, errors.New("execution payload header in blinded block was nil")
 		}
+		headers[i] = header
+		versions[i] = b.Version()
 		// Determine if the block is pre-merge or post-merge. Depending on the result,
 		// we will ask the execution engine for the full payload.
 		if bytes.Equal(header.BlockHash(), params.BeaconConfig().ZeroHash[:]) {
 			zeroExecPayloads = append(zeroExecPayloads, i)
 		} else {
-			executionBlockHash := common.BytesToHash(header.BlockHash())
 			validExecPayloads = append(validExecPayloads, i)
-			executionHashes = append(executionHashes, executionBlockHash)
 		}
 	}
+
+	// Payloads are rebuilt from the payload bodies of the execution client when it supports
+	// them, and from its execution blocks otherwise.
+	fullBlocks := make([]interfaces.SignedBeaconBlock, len(blindedBlocks))
+	validHeaders := make([]interfaces.ExecutionData, len(validExecPayloads))
+	validVersions := make([]int, len(validExecPayloads))
+	for sliceIdx, realIdx := range validExecPayloads {
+		validHeaders[sliceIdx] = headers[realIdx]
+		validVersions[sliceIdx] = versions[realIdx]
+	}
+	payloads := s.payloadsFromBodies(ctx, validHeaders, validVersions)
+	fromBlocks := []int{}
+	for sliceIdx, realIdx := range validExecPayloads {
+		if payloads[sliceIdx] == nil {
+			fromBlocks = append(fromBlocks, realIdx)
+			executionHashes = append(executionHashes, common.BytesToHash(headers[realIdx].BlockHash()))
+			continue
+		}
+		fullBlock, err := blocks.BuildSignedBeaconBlockFromExecutionPayload(blindedBlocks[realIdx], payloads[sliceIdx].Proto())
+		if err != nil {
+			return nil, err
+		}
+		fullBlocks[realIdx] = fullBlock
+	}
 	execBlocks, err := s.ExecutionBlocksByHashes(ctx, executionHashes, true /* with txs*/)
 	if err != nil {
 		return nil, fmt.Errorf("could not fetch execution blocks with txs by hash %#x: %v", executionHashes, err)
@@ -631,8 +696,7 @@ func (s *Service) ReconstructFullBellatrixBlockBatch(
 
 	// For each valid payload, we reconstruct the full block from it with the
 	// blinded block.
-	fullBlocks := make([]interfaces.SignedBeaconBlock, len(blindedBlocks))
-	for sliceIdx, realIdx := range validExecPayloads {
+	for sliceIdx, realIdx := range fromBlocks {
 		b := execBlocks[sliceIdx]
 		if b == nil {
 			return nil, fmt.Errorf("received nil execution block for request by hash %#x", executionHashes[sliceIdx])
@@ -670,6 +734,89 @@ func (s *Service) ReconstructFullBellatrixBlockBatch(
 	return fullBlocks, nil
 }
 
+// fullPayloadFromPayloadBody combines a payload header with the payload body returned by the
+// execution client, after checking the body matches the transactions and withdrawals roots of the header.
+func fullPayloadFromPayloadBody(
+	header interfaces.ExecutionData, body *pb.ExecutionPayloadBodyV1, bVersion int,
+) (interfaces.ExecutionData, error) {
+	if header.IsNil() || body == nil {
+		return nil, errors.New("execution payload header and body cannot be nil")
+	}
+	var payload interfaces.ExecutionData
+	var err error
+	switch bVersion {
+	case version.Bellatrix:
+		payload, err = blocks.WrappedExecutionPayload(&pb.ExecutionPayload{
+			ParentHash:    header.ParentHash(),
+			FeeRecipient:  header.FeeRecipient(),
+			StateRoot:     header.StateRoot(),
+			ReceiptsRoot:  header.ReceiptsRoot(),
+			LogsBloom:     header.LogsBloom(),
+			PrevRandao:    header.PrevRandao(),
+			BlockNumber:   header.BlockNumber(),
+			GasLimit:      header.GasLimit(),
+			GasUsed:       header.GasUsed(),
+			Timestamp:     header.Timestamp(),
+			ExtraData:     header.ExtraData(),
+			BaseFeePerGas: header.BaseFeePerGas(),
+			BlockHash:     header.BlockHash(),
+			Transactions:  body.Transactions,
+		})
+	case version.Capella:
+		payload, err = blocks.WrappedExecutionPayloadCapella(&pb.ExecutionPayloadCapella{
+			ParentHash:    header.ParentHash(),
+			FeeRecipient:  header.FeeRecipient(),
+			StateRoot:     header.StateRoot(),
+			ReceiptsRoot:  header.ReceiptsRoot(),
+			LogsBloom:     header.LogsBloom(),
+			PrevRandao:    header.PrevRandao(),
+			BlockNumber:   header.BlockNumber(),
+			GasLimit:      header.GasLimit(),
+			GasUsed:       header.GasUsed(),
+			Timestamp:     header.Timestamp(),
+			ExtraData:     header.ExtraData(),
+			BaseFeePerGas: header.BaseFeePerGas(),
+			BlockHash:     header.BlockHash(),
+			Transactions:  body.Transactions,
+			Withdrawals:   body.Withdrawals,
+		}, 0) // We can't get the block value and don't care about the block value for this instance
+	default:
+		return nil, fmt.Errorf("unsupported block version %s", version.String(bVersion))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	wantTxRoot, err := header.TransactionsRoot()
+	if err != nil {
+		return nil, err
+	}
+	var gotTxRoot, wantWithdrawalsRoot, gotWithdrawalsRoot []byte
+	if bVersion == version.Bellatrix {
+		h, err := blocks.PayloadToHeader(payload)
+		if err != nil {
+			return nil, err
+		}
+		gotTxRoot = h.TransactionsRoot
+	} else {
+		h, err := blocks.PayloadToHeaderCapella(payload)
+		if err != nil {
+			return nil, err
+		}
+		gotTxRoot, gotWithdrawalsRoot = h.TransactionsRoot, h.WithdrawalsRoot
+		if wantWithdrawalsRoot, err = header.WithdrawalsRoot(); err != nil {
+			return nil, err
+		}
+	}
+	if !bytes.Equal(wantTxRoot, gotTxRoot) {
+		return nil, fmt.Errorf("transactions root %#x of payload body does not match header %#x", gotTxRoot, wantTxRoot)
+	}
+	if !bytes.Equal(wantWithdrawalsRoot, gotWithdrawalsRoot) {
+		return nil, fmt.Errorf("withdrawals root %#x of payload body does not match header %#x", gotWithdrawalsRoot, wantWithdrawalsRoot)
+	}
+	return payload, nil
+}
+
 func fullPayloadFromExecutionBlock(
 	header interfaces.ExecutionData, block *pb.ExecutionBlock,
 ) (interfaces.ExecutionData, error) {
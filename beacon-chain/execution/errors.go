@@ -36,4 +36,7 @@ var (
 	ErrNilResponse = errors.New("nil response")
 	// ErrRequestTooLarge when the request is too large
 	ErrRequestTooLarge = errors.New("request too large")
+	// ErrMissingCapability when the execution client supports no version of an engine method
+	// required at the current fork.
+	ErrMissingCapability = errors.New("execution client lacks a required engine API capability")
 )
@@ -11,7 +11,6 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/theQRL/go-zond"
-	"github.com/theQRL/go-zond/accounts/abi/bind"
 	"github.com/theQRL/go-zond/common"
 	zondtypes "github.com/theQRL/go-zond/core/types"
 	"github.com/theQRL/qrysm/v4/beacon-chain/core/feed"
@@ -65,7 +64,7 @@ func (s *Service) ProcessETH1Block(ctx context.Context, blkNum *big.Int) error {
 		FromBlock: blkNum,
 		ToBlock:   blkNum,
 	}
-	logs, err := s.httpLogger.FilterLogs(ctx, query)
+	logs, err := s.filterLogs(ctx, query)
 	if err != nil {
 		return err
 	}
@@ -286,7 +285,7 @@ func (s *Service) processPastLogs(ctx context.Context) error {
 	}
 	// To store all blocks.
 	headersMap := make(map[uint64]*types.HeaderInfo)
-	rawLogCount, err := s.depositContractCaller.GetDepositCount(&bind.CallOpts{})
+	rawLogCount, err := s.depositCount()
 	if err != nil {
 		return err
 	}
@@ -381,7 +380,7 @@ func (s *Service) processBlockInBatch(ctx context.Context, currentBlockNum uint6
 		query.ToBlock = big.NewInt(0).SetUint64(latestFollowHeight)
 		end = latestFollowHeight
 	}
-	logs, err := s.httpLogger.FilterLogs(ctx, query)
+	logs, err := s.filterLogs(ctx, query)
 	if err != nil {
 		if tooMuchDataRequestedError(err) {
 			if batchSize == 0 {
@@ -75,4 +75,35 @@ var (
 		Name: "execution_payload_bodies_count",
 		Help: "The number of requested payload bodies is too large",
 	})
+	payloadBodiesFetched = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "execution_payload_bodies_fetched_count",
+		Help: "The number of payload bodies fetched from the execution client to reconstruct blinded blocks, by method",
+	}, []string{"method"})
+	payloadBodyCacheHit = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "execution_payload_body_cache_hit",
+		Help: "The number of payload bodies found in the cache when reconstructing blinded blocks",
+	})
+	payloadBodyCacheMiss = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "execution_payload_body_cache_miss",
+		Help: "The number of payload bodies missing from the cache when reconstructing blinded blocks",
+	})
+	reconstructPayloadsLatency = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "reconstruct_payloads_from_bodies_latency_milliseconds",
+			Help:    "Captures the latency of reconstructing a batch of payloads from payload bodies in milliseconds",
+			Buckets: []float64{5, 25, 50, 100, 200, 500, 1000, 2000, 4000},
+		},
+	)
+	executionEndpointHealthy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "execution_endpoint_healthy",
+		Help: "Whether an execution endpoint was reachable and synced at its last health check (1) or not (0)",
+	}, []string{"endpoint"})
+	executionEndpointLatency = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "execution_endpoint_latency_milliseconds",
+		Help: "The latency of the last health check of an execution endpoint in milliseconds",
+	}, []string{"endpoint"})
+	executionEndpointFailovers = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "execution_endpoint_failover_count",
+		Help: "The number of times the beacon node switched to another execution endpoint",
+	})
 )
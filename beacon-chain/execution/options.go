@@ -1,8 +1,12 @@
 package execution
 
 import (
+	"time"
+
+	"github.com/pkg/errors"
 	"github.com/theQRL/go-zond/common"
 	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositcache"
+	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositsnapshot"
 	statefeed "github.com/theQRL/qrysm/v4/beacon-chain/core/feed/state"
 	"github.com/theQRL/qrysm/v4/beacon-chain/db"
 	"github.com/theQRL/qrysm/v4/beacon-chain/state"
@@ -27,16 +31,51 @@ func WithHttpEndpointAndJWTSecret(endpointString string, secret []byte) Option {
 		if len(secret) == 0 {
 			return nil
 		}
-		// Overwrite authorization type for all endpoints to be of a bearer type.
-		hEndpoint := network.HttpEndpoint(endpointString)
-		hEndpoint.Auth.Method = authorization.Bearer
-		hEndpoint.Auth.Value = string(secret)
+		s.cfg.currHttpEndpoint = httpEndpointWithJWTSecret(endpointString, secret)
+		return nil
+	}
+}
+
+// WithFallbackHttpEndpoint adds an execution node JSON-RPC endpoint to fail over to when the
+// endpoint in use is unhealthy. The secret authenticates the endpoint, if set.
+func WithFallbackHttpEndpoint(endpointString string, secret []byte) Option {
+	return func(s *Service) error {
+		s.cfg.fallbackHttpEndpoints = append(s.cfg.fallbackHttpEndpoints, httpEndpointWithJWTSecret(endpointString, secret))
+		return nil
+	}
+}
+
+// WithHealthCheckInterval sets how often the execution endpoints are probed.
+func WithHealthCheckInterval(interval time.Duration) Option {
+	return func(s *Service) error {
+		if interval <= 0 {
+			return errors.New("execution endpoint health check interval must be positive")
+		}
+		s.cfg.healthCheckInterval = interval
+		return nil
+	}
+}
 
-		s.cfg.currHttpEndpoint = hEndpoint
+// WithMaxSyncingDuration sets how long the execution endpoint in use may stay syncing before
+// failing over to a synced endpoint.
+func WithMaxSyncingDuration(d time.Duration) Option {
+	return func(s *Service) error {
+		s.cfg.maxSyncingDuration = d
 		return nil
 	}
 }
 
+func httpEndpointWithJWTSecret(endpointString string, secret []byte) network.Endpoint {
+	hEndpoint := network.HttpEndpoint(endpointString)
+	if len(secret) == 0 {
+		return hEndpoint
+	}
+	// Overwrite authorization type for all endpoints to be of a bearer type.
+	hEndpoint.Auth.Method = authorization.Bearer
+	hEndpoint.Auth.Value = string(secret)
+	return hEndpoint
+}
+
 // WithHeaders adds headers to the execution node JSON-RPC requests.
 func WithHeaders(headers []string) Option {
 	return func(s *Service) error {
@@ -108,3 +147,12 @@ func WithFinalizedStateAtStartup(st state.BeaconState) Option {
 		return nil
 	}
 }
+
+// WithDepositSnapshot starts the deposit tree of a node which has not processed any deposit yet from
+// a finalized deposit snapshot, so that only the deposit logs after the snapshot are processed.
+func WithDepositSnapshot(snapshot *depositsnapshot.DepositTreeSnapshot) Option {
+	return func(s *Service) error {
+		s.cfg.depositSnapshot = snapshot
+		return nil
+	}
+}
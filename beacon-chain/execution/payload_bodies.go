@@ -0,0 +1,158 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/theQRL/go-zond/common"
+	lruwrpr "github.com/theQRL/qrysm/v4/cache/lru"
+	"github.com/theQRL/qrysm/v4/consensus-types/interfaces"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	pb "github.com/theQRL/qrysm/v4/proto/engine/v1"
+	"go.opencensus.io/trace"
+)
+
+const (
+	// payloadBodyCacheSize is the number of payload bodies kept to serve the blocks of recently
+	// requested ranges again without calling the execution client.
+	payloadBodyCacheSize = 256
+	// maxPayloadBodiesRange is the number of payload bodies requested at once by range, which
+	// execution clients must support.
+	maxPayloadBodiesRange = 32
+)
+
+// newPayloadBodyCache returns a cache of payload bodies by execution block hash.
+func newPayloadBodyCache() *lru.Cache {
+	return lruwrpr.New(payloadBodyCacheSize)
+}
+
+func (s *Service) cachedPayloadBody(blockHash [32]byte) *pb.ExecutionPayloadBodyV1 {
+	if s.payloadBodyCache == nil {
+		return nil
+	}
+	v, ok := s.payloadBodyCache.Get(blockHash)
+	if !ok {
+		payloadBodyCacheMiss.Inc()
+		return nil
+	}
+	payloadBodyCacheHit.Inc()
+	body, ok := v.(*pb.ExecutionPayloadBodyV1)
+	if !ok {
+		return nil
+	}
+	return body
+}
+
+func (s *Service) cachePayloadBody(blockHash [32]byte, body *pb.ExecutionPayloadBodyV1) {
+	if s.payloadBodyCache == nil {
+		return
+	}
+	s.payloadBodyCache.Add(blockHash, body)
+}
+
+// payloadsFromBodies rebuilds the payloads of the given headers from the payload bodies of the
+// execution client. Bodies are taken from the cache first, then requested by range for runs of
+// consecutive execution blocks, and by hash for the remaining headers. Payloads are nil where they
+// could not be rebuilt, either because the execution client does not support the payload bodies
+// methods, or because it did not return a body matching the header.
+func (s *Service) payloadsFromBodies(ctx context.Context, headers []interfaces.ExecutionData, versions []int) []interfaces.ExecutionData {
+	ctx, span := trace.StartSpan(ctx, "powchain.engine-api-client.payloadsFromBodies")
+	defer span.End()
+	start := time.Now()
+	defer func() {
+		reconstructPayloadsLatency.Observe(float64(time.Since(start).Milliseconds()))
+	}()
+
+	payloads := make([]interfaces.ExecutionData, len(headers))
+	build := func(i int, body *pb.ExecutionPayloadBodyV1) bool {
+		p, err := fullPayloadFromPayloadBody(headers[i], body, versions[i])
+		if err != nil {
+			log.WithError(err).WithField("blockHash", fmt.Sprintf("%#x", headers[i].BlockHash())).
+				Debug("Could not rebuild payload from its body")
+			return false
+		}
+		payloads[i] = p
+		s.cachePayloadBody(bytesutil.ToBytes32(headers[i].BlockHash()), body)
+		return true
+	}
+
+	var missing []int
+	for i, h := range headers {
+		if body := s.cachedPayloadBody(bytesutil.ToBytes32(h.BlockHash())); body != nil && build(i, body) {
+			continue
+		}
+		missing = append(missing, i)
+	}
+	if len(missing) > 1 && s.payloadBodiesSupported(GetPayloadBodiesByRangeV1) {
+		missing = s.payloadsFromBodiesByRange(ctx, headers, missing, build)
+	}
+	if len(missing) > 0 && s.payloadBodiesSupported(GetPayloadBodiesByHashV1) {
+		hashes := make([]common.Hash, len(missing))
+		for j, i := range missing {
+			hashes[j] = common.BytesToHash(headers[i].BlockHash())
+		}
+		bodies, err := s.GetPayloadBodiesByHash(ctx, hashes)
+		if err != nil {
+			log.WithError(err).Debug("Could not get payload bodies by hash")
+			return payloads
+		}
+		payloadBodiesFetched.WithLabelValues(GetPayloadBodiesByHashV1).Add(float64(len(bodies)))
+		for j, i := range missing {
+			if j < len(bodies) {
+				build(i, bodies[j])
+			}
+		}
+	}
+	return payloads
+}
+
+// payloadsFromBodiesByRange requests the bodies of the missing headers by ranges of execution block
+// numbers, and returns the headers still missing a payload.
+func (s *Service) payloadsFromBodiesByRange(
+	ctx context.Context, headers []interfaces.ExecutionData, missing []int, build func(int, *pb.ExecutionPayloadBodyV1) bool,
+) []int {
+	byNumber := make(map[uint64][]int, len(missing))
+	numbers := make([]uint64, 0, len(missing))
+	for _, i := range missing {
+		n := headers[i].BlockNumber()
+		if _, ok := byNumber[n]; !ok {
+			numbers = append(numbers, n)
+		}
+		byNumber[n] = append(byNumber[n], i)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	var remaining []int
+	for len(numbers) > 0 {
+		// Requests cover consecutive block numbers only, so that no unneeded body is fetched.
+		run := 1
+		for run < len(numbers) && run < maxPayloadBodiesRange && numbers[run] == numbers[run-1]+1 {
+			run++
+		}
+		if run == 1 {
+			remaining = append(remaining, byNumber[numbers[0]]...)
+			numbers = numbers[1:]
+			continue
+		}
+		first := numbers[0]
+		bodies, err := s.GetPayloadBodiesByRange(ctx, first, uint64(run))
+		if err != nil {
+			log.WithError(err).Debug("Could not get payload bodies by range")
+		} else {
+			payloadBodiesFetched.WithLabelValues(GetPayloadBodiesByRangeV1).Add(float64(len(bodies)))
+		}
+		for _, n := range numbers[:run] {
+			for _, i := range byNumber[n] {
+				if err != nil || n-first >= uint64(len(bodies)) || !build(i, bodies[n-first]) {
+					remaining = append(remaining, i)
+				}
+			}
+		}
+		numbers = numbers[run:]
+	}
+	sort.Ints(remaining)
+	return remaining
+}
@@ -0,0 +1,167 @@
+package execution
+
+import (
+	"context"
+	"testing"
+
+	fieldparams "github.com/theQRL/qrysm/v4/config/fieldparams"
+	"github.com/theQRL/qrysm/v4/consensus-types/blocks"
+	"github.com/theQRL/qrysm/v4/consensus-types/interfaces"
+	pb "github.com/theQRL/qrysm/v4/proto/engine/v1"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/runtime/version"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/util"
+)
+
+// blindedCapellaBlocks returns blinded blocks with payloads at the given execution block numbers,
+// along with their payloads.
+func blindedCapellaBlocks(t *testing.T, numbers ...uint64) ([]interfaces.ReadOnlySignedBeaconBlock, []*pb.ExecutionPayloadCapella) {
+	blinded := make([]interfaces.ReadOnlySignedBeaconBlock, len(numbers))
+	payloads := make([]*pb.ExecutionPayloadCapella, len(numbers))
+	for i, n := range numbers {
+		p, ok := fixtures()["ExecutionPayloadCapella"].(*pb.ExecutionPayloadCapella)
+		require.Equal(t, true, ok)
+		p = zondpb.CopyExecutionPayloadCapella(p)
+		p.BlockNumber = n
+		p.BlockHash = make([]byte, 32)
+		p.BlockHash[0] = byte(n)
+		p.Transactions = [][]byte{{byte(n)}}
+		wrapped, err := blocks.WrappedExecutionPayloadCapella(p, 0)
+		require.NoError(t, err)
+		header, err := blocks.PayloadToHeaderCapella(wrapped)
+		require.NoError(t, err)
+		b := util.NewBlindedBeaconBlockCapella()
+		b.Block.Slot = 1
+		b.Block.Body.ExecutionPayloadHeader = header
+		blinded[i], err = blocks.NewSignedBeaconBlock(b)
+		require.NoError(t, err)
+		payloads[i] = p
+	}
+	return blinded, payloads
+}
+
+func bodyOf(p *pb.ExecutionPayloadCapella) *pb.ExecutionPayloadBodyV1 {
+	return &pb.ExecutionPayloadBodyV1{Transactions: p.Transactions, Withdrawals: p.Withdrawals}
+}
+
+func TestReconstructFullBellatrixBlockBatch_BodiesByRange(t *testing.T) {
+	ctx := context.Background()
+	blinded, payloads := blindedCapellaBlocks(t, 5, 6, 9)
+	s, calls := countingEngineServer(t, map[string]interface{}{
+		ExchangeCapabilities: append([]string{GetPayloadBodiesByRangeV1, GetPayloadBodiesByHashV1}, forkEngineMethods...),
+		// Blocks 5 and 6 are requested by range, and the lone block 9 by hash.
+		GetPayloadBodiesByRangeV1: []*pb.ExecutionPayloadBodyV1{bodyOf(payloads[0]), bodyOf(payloads[1])},
+		GetPayloadBodiesByHashV1:  []*pb.ExecutionPayloadBodyV1{bodyOf(payloads[2])},
+	})
+	s.payloadBodyCache = newPayloadBodyCache()
+	require.NoError(t, s.negotiateCapabilities(ctx, s.rpcClient))
+
+	full, err := s.ReconstructFullBellatrixBlockBatch(ctx, blinded)
+	require.NoError(t, err)
+	require.Equal(t, len(blinded), len(full))
+	for i, b := range full {
+		got, err := b.Block().Body().Execution()
+		require.NoError(t, err)
+		assert.DeepEqual(t, payloads[i], got.Proto())
+	}
+	assert.Equal(t, 1, calls(GetPayloadBodiesByRangeV1))
+	assert.Equal(t, 1, calls(GetPayloadBodiesByHashV1))
+
+	// The bodies of the range are now served from the cache.
+	full, err = s.ReconstructFullBellatrixBlockBatch(ctx, blinded[:2])
+	require.NoError(t, err)
+	require.Equal(t, 2, len(full))
+	got, err := full[1].Block().Body().Execution()
+	require.NoError(t, err)
+	assert.DeepEqual(t, payloads[1], got.Proto())
+	assert.Equal(t, 1, calls(GetPayloadBodiesByRangeV1))
+	assert.Equal(t, 1, calls(GetPayloadBodiesByHashV1))
+}
+
+func TestReconstructFullBellatrixBlockBatch_MismatchedRangeFallsBackToHash(t *testing.T) {
+	ctx := context.Background()
+	blinded, payloads := blindedCapellaBlocks(t, 5, 6)
+	s, calls := countingEngineServer(t, map[string]interface{}{
+		ExchangeCapabilities: append([]string{GetPayloadBodiesByRangeV1, GetPayloadBodiesByHashV1}, forkEngineMethods...),
+		// The execution client has a different block 6 on its canonical chain.
+		GetPayloadBodiesByRangeV1: []*pb.ExecutionPayloadBodyV1{bodyOf(payloads[0]), bodyOf(payloads[0])},
+		GetPayloadBodiesByHashV1:  []*pb.ExecutionPayloadBodyV1{bodyOf(payloads[1])},
+	})
+	require.NoError(t, s.negotiateCapabilities(ctx, s.rpcClient))
+
+	full, err := s.ReconstructFullBellatrixBlockBatch(ctx, blinded)
+	require.NoError(t, err)
+	for i, b := range full {
+		got, err := b.Block().Body().Execution()
+		require.NoError(t, err)
+		assert.DeepEqual(t, payloads[i], got.Proto())
+	}
+	assert.Equal(t, 1, calls(GetPayloadBodiesByRangeV1))
+	assert.Equal(t, 1, calls(GetPayloadBodiesByHashV1))
+}
+
+func TestReconstructFullBlock_FromPayloadBodies(t *testing.T) {
+	fix := fixtures()
+	payload, ok := fix["ExecutionPayloadCapella"].(*pb.ExecutionPayloadCapella)
+	require.Equal(t, true, ok)
+	wrapped, err := blocks.WrappedExecutionPayloadCapella(payload, 0)
+	require.NoError(t, err)
+	header, err := blocks.PayloadToHeaderCapella(wrapped)
+	require.NoError(t, err)
+	body := &pb.ExecutionPayloadBodyV1{
+		Transactions: payload.Transactions,
+		Withdrawals:  payload.Withdrawals,
+	}
+
+	s := engineServer(t, map[string]interface{}{
+		ExchangeCapabilities:     append([]string{GetPayloadBodiesByHashV1}, forkEngineMethods...),
+		GetPayloadBodiesByHashV1: []*pb.ExecutionPayloadBodyV1{body},
+	})
+	require.NoError(t, s.negotiateCapabilities(context.Background(), s.rpcClient))
+	blindedBlock := util.NewBlindedBeaconBlockCapella()
+	blindedBlock.Block.Body.ExecutionPayloadHeader = header
+	blinded, err := blocks.NewSignedBeaconBlock(blindedBlock)
+	require.NoError(t, err)
+	reconstructed, err := s.ReconstructFullBlock(context.Background(), blinded)
+	require.NoError(t, err)
+	got, err := reconstructed.Block().Body().Execution()
+	require.NoError(t, err)
+	require.DeepEqual(t, payload, got.Proto())
+
+	reconstructedBatch, err := s.ReconstructFullBellatrixBlockBatch(context.Background(), []interfaces.ReadOnlySignedBeaconBlock{blinded})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(reconstructedBatch))
+	got, err = reconstructedBatch[0].Block().Body().Execution()
+	require.NoError(t, err)
+	require.DeepEqual(t, payload, got.Proto())
+}
+
+func TestFullPayloadFromPayloadBody_RootMismatch(t *testing.T) {
+	fix := fixtures()
+	payload, ok := fix["ExecutionPayloadCapella"].(*pb.ExecutionPayloadCapella)
+	require.Equal(t, true, ok)
+	wrapped, err := blocks.WrappedExecutionPayloadCapella(payload, 0)
+	require.NoError(t, err)
+	h, err := blocks.PayloadToHeaderCapella(wrapped)
+	require.NoError(t, err)
+	header, err := blocks.WrappedExecutionPayloadHeaderCapella(h, 0)
+	require.NoError(t, err)
+
+	_, err = fullPayloadFromPayloadBody(header, &pb.ExecutionPayloadBodyV1{
+		Transactions: [][]byte{{'a'}},
+		Withdrawals:  payload.Withdrawals,
+	}, version.Capella)
+	require.ErrorContains(t, "transactions root", err)
+	_, err = fullPayloadFromPayloadBody(header, &pb.ExecutionPayloadBodyV1{
+		Transactions: payload.Transactions,
+		Withdrawals: []*pb.Withdrawal{{
+			Index:          1,
+			ValidatorIndex: 2,
+			Address:        make([]byte, fieldparams.FeeRecipientLength),
+			Amount:         3,
+		}},
+	}, version.Capella)
+	require.ErrorContains(t, "withdrawals root", err)
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/theQRL/go-zond"
+	"github.com/theQRL/go-zond/accounts/abi/bind"
+	zondtypes "github.com/theQRL/go-zond/core/types"
 	zondRPC "github.com/theQRL/go-zond/rpc"
 	"github.com/theQRL/go-zond/zondclient"
 	"github.com/theQRL/qrysm/v4/config/params"
@@ -21,17 +25,12 @@ func (s *Service) setupExecutionClientConnections(ctx context.Context, currEndpo
 	if err != nil {
 		return errors.Wrap(err, "could not dial execution node")
 	}
-	// Attach the clients to the service struct.
 	fetcher := zondclient.NewClient(client)
-	s.rpcClient = client
-	s.httpLogger = fetcher
-
 	depositContractCaller, err := contracts.NewDepositContractCaller(s.cfg.depositContractAddr, fetcher)
 	if err != nil {
 		client.Close()
 		return errors.Wrap(err, "could not initialize deposit contract caller")
 	}
-	s.depositContractCaller = depositContractCaller
 
 	// Ensure we have the correct chain and deposit IDs.
 	if err := ensureCorrectExecutionChain(ctx, fetcher); err != nil {
@@ -44,6 +43,16 @@ func (s *Service) setupExecutionClientConnections(ctx context.Context, currEndpo
 		}
 		return errors.Wrap(err, errStr)
 	}
+	if err := s.negotiateCapabilities(ctx, client); err != nil {
+		if errors.Is(err, ErrMissingCapability) {
+			client.Close()
+			return err
+		}
+		log.WithError(err).Warn("Could not negotiate engine API capabilities, using default engine method versions")
+	}
+	// Attach the clients to the service struct only once the endpoint is known to be usable, so
+	// that a failed connection attempt does not replace a working client.
+	s.setRPCClient(currEndpoint, client, fetcher, depositContractCaller)
 	s.updateConnectedETH1(true)
 	s.runError = nil
 	return nil
@@ -63,20 +72,18 @@ func (s *Service) pollConnectionStatus(ctx context.Context) {
 	}
 	ticker := time.NewTicker(backOffPeriod)
 	defer ticker.Stop()
+	endpoint := s.activeEndpoint()
 	for {
 		select {
 		case <-ticker.C:
-			log.Debugf("Trying to dial endpoint: %s", logs.MaskCredentialsLogging(s.cfg.currHttpEndpoint.Url))
-			currClient := s.rpcClient
-			if err := s.setupExecutionClientConnections(ctx, s.cfg.currHttpEndpoint); err != nil {
+			log.Debugf("Trying to dial endpoint: %s", logs.MaskCredentialsLogging(endpoint.Url))
+			if err := s.setupExecutionClientConnections(ctx, endpoint); err != nil {
 				errorLogger(err, "Could not connect to execution client endpoint")
+				endpoint = s.endpoints.next(endpoint)
 				continue
 			}
-			// Close previous client, if connection was successful.
-			if currClient != nil {
-				currClient.Close()
-			}
-			log.Infof("Connected to new endpoint: %s", logs.MaskCredentialsLogging(s.cfg.currHttpEndpoint.Url))
+			s.endpoints.setActive(endpoint)
+			log.Infof("Connected to new endpoint: %s", logs.MaskCredentialsLogging(endpoint.Url))
 			return
 		case <-s.ctx.Done():
 			log.Debug("Received cancelled context,closing existing powchain service")
@@ -91,15 +98,10 @@ func (s *Service) retryExecutionClientConnection(ctx context.Context, err error)
 	s.updateConnectedETH1(false)
 	// Back off for a while before redialing.
 	time.Sleep(backOffPeriod)
-	currClient := s.rpcClient
-	if err := s.setupExecutionClientConnections(ctx, s.cfg.currHttpEndpoint); err != nil {
+	if err := s.setupExecutionClientConnections(ctx, s.activeEndpoint()); err != nil {
 		s.runError = errors.Wrap(err, "setupExecutionClientConnections")
 		return
 	}
-	// Close previous client, if connection was successful.
-	if currClient != nil {
-		currClient.Close()
-	}
 	// Reset run error in the event of a successful connection.
 	s.runError = nil
 }
@@ -143,3 +145,107 @@ func ensureCorrectExecutionChain(ctx context.Context, client *zondclient.Client)
 	}
 	return nil
 }
+
+// setRPCClient attaches the clients of an execution endpoint to the service. The execution client
+// it replaces is closed once the calls in flight on it have returned.
+func (s *Service) setRPCClient(
+	endpoint network.Endpoint,
+	client RPCClient,
+	logger bind.ContractFilterer,
+	caller *contracts.DepositContractCaller,
+) {
+	s.rpcClientLock.Lock()
+	prev, prevCalls := s.rpcClient, s.rpcCalls
+	s.rpcClient = client
+	s.rpcEndpoint = endpoint
+	s.httpLogger = logger
+	s.depositContractCaller = caller
+	s.rpcCalls = new(sync.WaitGroup)
+	s.rpcClientLock.Unlock()
+	if prev != nil && prev != client {
+		go closeWhenDrained(prev, prevCalls)
+	}
+}
+
+// closeRPCClient closes the execution client in use once the calls in flight on it have returned.
+func (s *Service) closeRPCClient() {
+	s.rpcClientLock.Lock()
+	client, calls := s.rpcClient, s.rpcCalls
+	// Calls made from now on fail with the closed client, and are not waited for.
+	s.rpcCalls = new(sync.WaitGroup)
+	s.rpcClientLock.Unlock()
+	if client != nil {
+		go closeWhenDrained(client, calls)
+	}
+}
+
+func closeWhenDrained(client RPCClient, calls *sync.WaitGroup) {
+	if calls != nil {
+		calls.Wait()
+	}
+	client.Close()
+}
+
+// acquireClient returns the execution client in use, and a function to call once done with it.
+// The client is not closed before being released, even if replaced in the meantime.
+func (s *Service) acquireClient() (RPCClient, func()) {
+	s.rpcClientLock.RLock()
+	defer s.rpcClientLock.RUnlock()
+	return s.rpcClient, s.trackCall()
+}
+
+// trackCall counts a call in flight on the execution client in use, and returns the function
+// ending it. The caller must hold rpcClientLock.
+func (s *Service) trackCall() func() {
+	if s.rpcCalls == nil {
+		return func() {}
+	}
+	s.rpcCalls.Add(1)
+	return s.rpcCalls.Done
+}
+
+func (s *Service) hasRPCClient() bool {
+	s.rpcClientLock.RLock()
+	defer s.rpcClientLock.RUnlock()
+	return s.rpcClient != nil
+}
+
+func (s *Service) callContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	client, release := s.acquireClient()
+	defer release()
+	return client.CallContext(ctx, result, method, args...)
+}
+
+// callEngine is callContext returning the endpoint which served the call, so that its response is
+// attributed to that endpoint even if we failed over to another one in the meantime.
+func (s *Service) callEngine(
+	ctx context.Context, result interface{}, method string, args ...interface{},
+) (network.Endpoint, error) {
+	s.rpcClientLock.RLock()
+	client, endpoint, release := s.rpcClient, s.rpcEndpoint, s.trackCall()
+	s.rpcClientLock.RUnlock()
+	defer release()
+	return endpoint, client.CallContext(ctx, result, method, args...)
+}
+
+func (s *Service) batchCall(b []zondRPC.BatchElem) error {
+	client, release := s.acquireClient()
+	defer release()
+	return client.BatchCall(b)
+}
+
+func (s *Service) filterLogs(ctx context.Context, query ethereum.FilterQuery) ([]zondtypes.Log, error) {
+	s.rpcClientLock.RLock()
+	logger, release := s.httpLogger, s.trackCall()
+	s.rpcClientLock.RUnlock()
+	defer release()
+	return logger.FilterLogs(ctx, query)
+}
+
+func (s *Service) depositCount() ([]byte, error) {
+	s.rpcClientLock.RLock()
+	caller, release := s.depositContractCaller, s.trackCall()
+	s.rpcClientLock.RUnlock()
+	defer release()
+	return caller.GetDepositCount(&bind.CallOpts{})
+}
@@ -13,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -22,6 +23,7 @@ import (
 	"github.com/theQRL/go-zond/common/hexutil"
 	zondRPC "github.com/theQRL/go-zond/rpc"
 	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositcache"
+	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositsnapshot"
 	"github.com/theQRL/qrysm/v4/beacon-chain/core/feed"
 	statefeed "github.com/theQRL/qrysm/v4/beacon-chain/core/feed/state"
 	"github.com/theQRL/qrysm/v4/beacon-chain/core/transition"
@@ -36,6 +38,7 @@ import (
 	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
 	"github.com/theQRL/qrysm/v4/monitoring/clientstats"
 	"github.com/theQRL/qrysm/v4/network"
+	pb "github.com/theQRL/qrysm/v4/proto/engine/v1"
 	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
 	prysmTime "github.com/theQRL/qrysm/v4/time"
 	"github.com/theQRL/qrysm/v4/time/slots"
@@ -125,8 +128,12 @@ type config struct {
 	eth1HeaderReqLimit      uint64
 	beaconNodeStatsUpdater  BeaconNodeStatsUpdater
 	currHttpEndpoint        network.Endpoint
+	fallbackHttpEndpoints   []network.Endpoint
+	healthCheckInterval     time.Duration
+	maxSyncingDuration      time.Duration
 	headers                 []string
 	finalizedStateAtStartup state.BeaconState
+	depositSnapshot         *depositsnapshot.DepositTreeSnapshot
 }
 
 // Service fetches important information about the canonical
@@ -146,6 +153,9 @@ type Service struct {
 	eth1HeadTicker          *time.Ticker
 	httpLogger              bind.ContractFilterer
 	rpcClient               RPCClient
+	rpcEndpoint             network.Endpoint
+	rpcClientLock           sync.RWMutex
+	rpcCalls                *sync.WaitGroup
 	headerCache             *headerCache // cache to store block hash/block height.
 	latestEth1Data          *zondpb.LatestETH1Data
 	depositContractCaller   *contracts.DepositContractCaller
@@ -154,6 +164,12 @@ type Service struct {
 	lastReceivedMerkleIndex int64 // Keeps track of the last received index to prevent log spam.
 	runError                error
 	preGenesisState         state.BeaconState
+	capabilities            engineCapabilities
+	payloadBodyCache        *lru.Cache
+	endpoints               endpointMonitor
+	forkchoiceLock          sync.RWMutex
+	latestForkchoiceState   *pb.ForkchoiceState
+	latestForkchoiceVersion int
 }
 
 // NewService sets up a new instance with an ethclient when given a web3 endpoint as a string in the config.
@@ -177,6 +193,8 @@ func NewService(ctx context.Context, opts ...Option) (*Service, error) {
 		cfg: &config{
 			beaconNodeStatsUpdater: &NopBeaconNodeStatsUpdater{},
 			eth1HeaderReqLimit:     defaultEth1HeaderReqLimit,
+			healthCheckInterval:    defaultHealthCheckInterval,
+			maxSyncingDuration:     defaultMaxSyncingDuration,
 		},
 		latestEth1Data: &zondpb.LatestETH1Data{
 			BlockHeight:        0,
@@ -184,8 +202,9 @@ func NewService(ctx context.Context, opts ...Option) (*Service, error) {
 			BlockHash:          []byte{},
 			LastRequestedBlock: 0,
 		},
-		headerCache: newHeaderCache(),
-		depositTrie: depositTrie,
+		headerCache:      newHeaderCache(),
+		payloadBodyCache: newPayloadBodyCache(),
+		depositTrie:      depositTrie,
 		chainStartData: &zondpb.ChainStartData{
 			Eth1Data:           &zondpb.Eth1Data{},
 			ChainstartDeposits: make([]*zondpb.Deposit, 0),
@@ -200,6 +219,7 @@ func NewService(ctx context.Context, opts ...Option) (*Service, error) {
 			return nil, err
 		}
 	}
+	s.endpoints.init(append([]network.Endpoint{s.cfg.currHttpEndpoint}, s.cfg.fallbackHttpEndpoints...))
 
 	if err := s.ensureValidPowchainData(ctx); err != nil {
 		return nil, errors.Wrap(err, "unable to validate powchain data")
@@ -213,12 +233,15 @@ func NewService(ctx context.Context, opts ...Option) (*Service, error) {
 	if err := s.initializeEth1Data(ctx, eth1Data); err != nil {
 		return nil, err
 	}
+	if err := s.initDepositSnapshot(ctx); err != nil {
+		return nil, errors.Wrap(err, "could not initialize deposit tree from snapshot")
+	}
 	return s, nil
 }
 
 // Start the powchain service's main event loop.
 func (s *Service) Start() {
-	if err := s.setupExecutionClientConnections(s.ctx, s.cfg.currHttpEndpoint); err != nil {
+	if err := s.setupExecutionClientConnections(s.ctx, s.activeEndpoint()); err != nil {
 		log.WithError(err).Error("Could not connect to execution endpoint")
 	}
 	// If the chain has not started already and we don't have access to eth1 nodes, we will not be
@@ -240,6 +263,11 @@ func (s *Service) Start() {
 	// Poll the execution client connection and fallback if errors occur.
 	s.pollConnectionStatus(s.ctx)
 
+	// Fail over between execution endpoints when more than one is configured.
+	if s.endpoints.count() > 1 {
+		go s.monitorEndpoints(s.ctx)
+	}
+
 	// Check transition configuration for the engine API client in the background.
 	go s.checkTransitionConfiguration(s.ctx, make(chan *feed.Event, 1))
 
@@ -251,9 +279,7 @@ func (s *Service) Stop() error {
 	if s.cancel != nil {
 		defer s.cancel()
 	}
-	if s.rpcClient != nil {
-		s.rpcClient.Close()
-	}
+	s.closeRPCClient()
 	return nil
 }
 
@@ -291,7 +317,7 @@ func (s *Service) ExecutionClientConnected() bool {
 
 // ExecutionClientEndpoint returns the URL of the current, connected execution client.
 func (s *Service) ExecutionClientEndpoint() string {
-	return s.cfg.currHttpEndpoint.Url
+	return s.activeEndpoint().Url
 }
 
 // ExecutionClientConnectionErr returns the error (if any) of the current connection.
@@ -334,9 +360,20 @@ func (s *Service) followedBlockHeight(ctx context.Context) (uint64, error) {
 
 func (s *Service) initDepositCaches(ctx context.Context, ctrs []*zondpb.DepositContainer) error {
 	if len(ctrs) == 0 {
+		// A deposit trie holding deposits without containers was started from a deposit snapshot.
+		if s.depositTrie.NumOfItems() > 0 {
+			return s.initDepositCacheFromSnapshot(ctx)
+		}
 		return nil
 	}
 	s.cfg.depositCache.InsertDepositContainers(ctx, ctrs)
+	// Containers, which are now sorted, only start after the first deposit when the
+	// deposit trie was started from a deposit snapshot.
+	if ctrs[0].Index > 0 {
+		if err := s.initDepositCacheFromSnapshot(ctx); err != nil {
+			return err
+		}
+	}
 	if !s.chainStartData.Chainstarted {
 		// Do not add to pending cache if no genesis state exists.
 		validDepositsCount.Add(float64(s.preGenesisState.Eth1DepositIndex()))
@@ -380,10 +417,9 @@ func (s *Service) initDepositCaches(ctx context.Context, ctrs []*zondpb.DepositC
 		}
 	}
 	validDepositsCount.Add(float64(currIndex))
-	// Only add pending deposits if the container slice length
-	// is more than the current index in state.
-	if uint64(len(ctrs)) > currIndex {
-		for _, c := range ctrs[currIndex:] {
+	// Only add pending deposits which are not yet included in state.
+	for _, c := range ctrs {
+		if uint64(c.Index) >= currIndex {
 			s.cfg.depositCache.InsertPendingDeposit(ctx, c.Deposit, c.Eth1BlockHeight, c.Index, bytesutil.ToBytes32(c.DepositRoot))
 		}
 	}
@@ -428,7 +464,7 @@ func (s *Service) batchRequestHeaders(startBlock, endBlock uint64) ([]*types.Hea
 		})
 		headers = append(headers, header)
 	}
-	ioErr := s.rpcClient.BatchCall(elems)
+	ioErr := s.batchCall(elems)
 	if ioErr != nil {
 		return nil, ioErr
 	}
@@ -592,7 +628,7 @@ func (s *Service) run(done <-chan struct{}) {
 		case <-done:
 			s.isRunning = false
 			s.runError = nil
-			s.rpcClient.Close()
+			s.closeRPCClient()
 			s.updateConnectedETH1(false)
 			log.Debug("Context closed, exiting goroutine")
 			return
@@ -763,8 +799,9 @@ func (s *Service) initializeEth1Data(ctx context.Context, eth1DataInDB *zondpb.E
 }
 
 // Validates that all deposit containers are valid and have their relevant indices
-// in order.
-func validateDepositContainers(ctrs []*zondpb.DepositContainer) bool {
+// in order. The containers may start after index 0 if the deposits before them are
+// covered by a deposit snapshot of snapshotCount deposits.
+func validateDepositContainers(ctrs []*zondpb.DepositContainer, snapshotCount uint64) bool {
 	ctrLen := len(ctrs)
 	// Exit for empty containers.
 	if ctrLen == 0 {
@@ -774,7 +811,11 @@ func validateDepositContainers(ctrs []*zondpb.DepositContainer) bool {
 	sort.Slice(ctrs, func(i, j int) bool {
 		return ctrs[i].Index < ctrs[j].Index
 	})
-	startIndex := int64(0)
+	startIndex := ctrs[0].Index
+	if startIndex < 0 || uint64(startIndex) > snapshotCount {
+		log.Info("Recovering missing deposit containers, node is re-requesting missing deposit data")
+		return false
+	}
 	for _, c := range ctrs {
 		if c.Index != startIndex {
 			log.Info("Recovering missing deposit containers, node is re-requesting missing deposit data")
@@ -800,7 +841,15 @@ func (s *Service) ensureValidPowchainData(ctx context.Context) error {
 	if err != nil {
 		return errors.Wrap(err, "unable to retrieve eth1 data")
 	}
-	if eth1Data == nil || !eth1Data.ChainstartData.Chainstarted || !validateDepositContainers(eth1Data.DepositContainers) {
+	snapshot, err := s.cfg.beaconDB.DepositSnapshot(ctx)
+	if err != nil {
+		return errors.Wrap(err, "unable to retrieve deposit snapshot")
+	}
+	var snapshotCount uint64
+	if snapshot != nil {
+		snapshotCount = snapshot.DepositCount()
+	}
+	if eth1Data == nil || !eth1Data.ChainstartData.Chainstarted || !validateDepositContainers(eth1Data.DepositContainers, snapshotCount) {
 		pbState, err := native.ProtobufBeaconStatePhase0(s.preGenesisState.ToProtoUnsafe())
 		if err != nil {
 			return err
@@ -11,6 +11,7 @@ import (
 	"github.com/theQRL/qrysm/v4/beacon-chain/forkchoice"
 	forkchoicetypes "github.com/theQRL/qrysm/v4/beacon-chain/forkchoice/types"
 	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	"github.com/theQRL/qrysm/v4/config/features"
 	fieldparams "github.com/theQRL/qrysm/v4/config/fieldparams"
 	"github.com/theQRL/qrysm/v4/config/params"
 	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
@@ -36,6 +37,9 @@ func New() *ForkChoice {
 		slashedIndices:                make(map[primitives.ValidatorIndex]bool),
 		receivedBlocksLastEpoch:       [fieldparams.SlotsPerEpoch]primitives.Slot{},
 	}
+	if features.Get().EnableForkChoiceRecorder {
+		s.recorder = NewRecorder(features.Get().ForkChoiceRecorderSize)
+	}
 
 	b := make([]uint64, 0)
 	v := make([]Vote, 0)
@@ -68,10 +72,11 @@ func (f *ForkChoice) Head(
 	if err := f.store.treeRootNode.applyWeightChanges(ctx); err != nil {
 		return [32]byte{}, errors.Wrap(err, "could not apply weight changes")
 	}
+	f.store.recorder.recordWeights(f.store)
 
 	jc := f.JustifiedCheckpoint()
 	fc := f.FinalizedCheckpoint()
-	currentEpoch := slots.EpochsSinceGenesis(time.Unix(int64(f.store.genesisTime), 0))
+	currentEpoch := slots.ToEpoch(f.store.currentSlot())
 	if err := f.store.treeRootNode.updateBestDescendant(ctx, jc.Epoch, fc.Epoch, currentEpoch); err != nil {
 		return [32]byte{}, errors.Wrap(err, "could not update best descendant")
 	}
@@ -506,6 +511,11 @@ func (f *ForkChoice) SetGenesisTime(genesisTime uint64) {
 	f.store.genesisTime = genesisTime
 }
 
+// SetNower sets the source of the current time of forkchoice, which is the system time by default.
+func (f *ForkChoice) SetNower(now func() time.Time) {
+	f.store.now = now
+}
+
 // SetOriginRoot sets the genesis block root
 func (f *ForkChoice) SetOriginRoot(root [32]byte) {
 	f.store.originRoot = root
@@ -596,6 +606,14 @@ func (f *ForkChoice) ForkChoiceDump(ctx context.Context) (*v1.ForkChoiceDump, er
 	return resp, nil
 }
 
+// History returns the fork choice recorder, or nil when it is not enabled.
+func (f *ForkChoice) History() forkchoice.HistoryExporter {
+	if f.store.recorder == nil {
+		return nil
+	}
+	return f.store.recorder
+}
+
 // SetBalancesByRooter sets the balanceByRoot handler in forkchoice
 func (f *ForkChoice) SetBalancesByRooter(handler forkchoice.BalancesByRooter) {
 	f.balancesByRoot = handler
@@ -28,6 +28,9 @@ func (f *ForkChoice) applyProposerBoostScore() error {
 		} else {
 			proposerScore = (s.committeeWeight * params.BeaconConfig().ProposerScoreBoost) / 100
 			currentNode.balance += proposerScore
+			if s.proposerBoostRoot != s.previousProposerBoostRoot {
+				s.recorder.recordProposerBoost(currentNode, proposerScore)
+			}
 		}
 	}
 	s.previousProposerBoostRoot = s.proposerBoostRoot
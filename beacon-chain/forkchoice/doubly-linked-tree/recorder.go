@@ -0,0 +1,351 @@
+package doublylinkedtree
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	fieldparams "github.com/theQRL/qrysm/v4/config/fieldparams"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+)
+
+// DefaultRecorderSize is the number of events kept by the fork choice recorder when no
+// size is configured.
+const DefaultRecorderSize = 4096
+
+// EventType is the kind of an event recorded by the fork choice recorder.
+type EventType string
+
+const (
+	// EventHeadChange is recorded when the head of fork choice moves to a different block.
+	EventHeadChange EventType = "head_change"
+	// EventWeightUpdate is recorded when the weight of a tip of the tree changes.
+	EventWeightUpdate EventType = "weight_update"
+	// EventProposerBoost is recorded when a new block receives the proposer boost.
+	EventProposerBoost EventType = "proposer_boost"
+	// EventLateBlockReorg is recorded when fork choice decides whether to reorg a late block.
+	EventLateBlockReorg EventType = "late_block_reorg"
+)
+
+// Late block reorg decisions.
+const (
+	decisionOverrideFCU  = "should_override_fcu"
+	decisionProposerHead = "get_proposer_head"
+)
+
+// RecordedEvent is a single fork choice event kept by the Recorder.
+type RecordedEvent struct {
+	Index        uint64
+	Type         EventType
+	Time         time.Time
+	Slot         primitives.Slot
+	Root         [fieldparams.RootLength]byte
+	ParentRoot   [fieldparams.RootLength]byte
+	PreviousSlot primitives.Slot
+	PreviousRoot [fieldparams.RootLength]byte
+	// Weight is the weight of the block after the event.
+	Weight uint64
+	// PreviousWeight is the weight of the block before a weight update.
+	PreviousWeight uint64
+	// BoostScore is the score added to the block by the proposer boost.
+	BoostScore uint64
+	// Reorg is set on head changes when the new head does not descend from the previous
+	// head, and on late block reorg decisions which chose to orphan the head.
+	Reorg bool
+	// Decision is the function which took a late block reorg decision.
+	Decision string
+	// Reason explains why a late block reorg was or was not attempted.
+	Reason string
+}
+
+// Recorder keeps the latest fork choice events in a bounded ring buffer, so that the
+// movements of the head can be inspected after a reorg. It is safe for concurrent use.
+// A nil Recorder records nothing.
+type Recorder struct {
+	sync.Mutex
+	events      []RecordedEvent
+	count       uint64
+	tipWeights  map[[fieldparams.RootLength]byte]uint64
+	currentTime func() time.Time
+}
+
+// NewRecorder returns a recorder which keeps the given number of latest events.
+func NewRecorder(size int) *Recorder {
+	if size <= 0 {
+		size = DefaultRecorderSize
+	}
+	return &Recorder{
+		events:      make([]RecordedEvent, size),
+		tipWeights:  make(map[[fieldparams.RootLength]byte]uint64),
+		currentTime: time.Now,
+	}
+}
+
+// Events returns the recorded events, oldest first.
+func (r *Recorder) Events() []RecordedEvent {
+	if r == nil {
+		return nil
+	}
+	r.Lock()
+	defer r.Unlock()
+	size := uint64(len(r.events))
+	if r.count <= size {
+		return append([]RecordedEvent{}, r.events[:r.count]...)
+	}
+	start := r.count % size
+	events := make([]RecordedEvent, 0, size)
+	events = append(events, r.events[start:]...)
+	return append(events, r.events[:start]...)
+}
+
+func (r *Recorder) record(e RecordedEvent) {
+	r.Lock()
+	defer r.Unlock()
+	e.Index = r.count
+	e.Time = r.currentTime()
+	r.events[r.count%uint64(len(r.events))] = e
+	r.count++
+}
+
+// recordHeadChange records that the head moved from prev to head.
+func (r *Recorder) recordHeadChange(prev, head *Node) {
+	if r == nil || head == nil {
+		return
+	}
+	e := RecordedEvent{
+		Type:   EventHeadChange,
+		Slot:   head.slot,
+		Root:   head.root,
+		Weight: head.weight,
+	}
+	if head.parent != nil {
+		e.ParentRoot = head.parent.root
+	}
+	if prev != nil {
+		e.PreviousSlot = prev.slot
+		e.PreviousRoot = prev.root
+		e.Reorg = !isDescendant(prev, head)
+	}
+	r.record(e)
+}
+
+// isDescendant returns whether node is the ancestor itself or one of its descendants.
+func isDescendant(ancestor, node *Node) bool {
+	for n := node; n != nil && n.slot >= ancestor.slot; n = n.parent {
+		if n == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// recordWeights records the weight changes of the tips of the tree since the last call.
+func (r *Recorder) recordWeights(s *Store) {
+	if r == nil {
+		return
+	}
+	tips := make(map[[fieldparams.RootLength]byte]uint64)
+	for root, node := range s.nodeByRoot {
+		if len(node.children) != 0 {
+			continue
+		}
+		tips[root] = node.weight
+		prev, ok := r.tipWeights[root]
+		if ok && prev == node.weight {
+			continue
+		}
+		e := RecordedEvent{
+			Type:           EventWeightUpdate,
+			Slot:           node.slot,
+			Root:           root,
+			Weight:         node.weight,
+			PreviousWeight: prev,
+		}
+		if node.parent != nil {
+			e.ParentRoot = node.parent.root
+		}
+		r.record(e)
+	}
+	r.tipWeights = tips
+}
+
+// recordProposerBoost records that the node received the proposer boost.
+func (r *Recorder) recordProposerBoost(node *Node, score uint64) {
+	if r == nil || node == nil {
+		return
+	}
+	e := RecordedEvent{
+		Type:       EventProposerBoost,
+		Slot:       node.slot,
+		Root:       node.root,
+		Weight:     node.weight,
+		BoostScore: score,
+	}
+	if node.parent != nil {
+		e.ParentRoot = node.parent.root
+	}
+	r.record(e)
+}
+
+// recordReorgDecision records whether the late block reorg logic decided to orphan the head.
+func (r *Recorder) recordReorgDecision(head *Node, decision string, reorg bool, reason string) {
+	if r == nil || head == nil {
+		return
+	}
+	e := RecordedEvent{
+		Type:     EventLateBlockReorg,
+		Slot:     head.slot,
+		Root:     head.root,
+		Weight:   head.weight,
+		Reorg:    reorg,
+		Decision: decision,
+		Reason:   reason,
+	}
+	if head.parent != nil {
+		e.ParentRoot = head.parent.root
+	}
+	r.record(e)
+}
+
+type recordedEventJson struct {
+	Index          uint64    `json:"index"`
+	Type           EventType `json:"type"`
+	Time           time.Time `json:"time"`
+	Slot           string    `json:"slot"`
+	Root           string    `json:"root"`
+	ParentRoot     string    `json:"parent_root,omitempty"`
+	PreviousSlot   string    `json:"previous_slot,omitempty"`
+	PreviousRoot   string    `json:"previous_root,omitempty"`
+	Weight         string    `json:"weight"`
+	PreviousWeight string    `json:"previous_weight,omitempty"`
+	BoostScore     string    `json:"boost_score,omitempty"`
+	Reorg          bool      `json:"reorg"`
+	Decision       string    `json:"decision,omitempty"`
+	Reason         string    `json:"reason,omitempty"`
+}
+
+type historyJson struct {
+	Recorded uint64               `json:"recorded"`
+	Capacity int                  `json:"capacity"`
+	Events   []*recordedEventJson `json:"events"`
+}
+
+// ExportJSON writes the recorded events to w as JSON, oldest first.
+func (r *Recorder) ExportJSON(w io.Writer) error {
+	events := r.Events()
+	h := &historyJson{Events: make([]*recordedEventJson, len(events))}
+	if r != nil {
+		r.Lock()
+		h.Recorded, h.Capacity = r.count, len(r.events)
+		r.Unlock()
+	}
+	for i, e := range events {
+		j := &recordedEventJson{
+			Index:    e.Index,
+			Type:     e.Type,
+			Time:     e.Time,
+			Slot:     fmt.Sprintf("%d", e.Slot),
+			Root:     fmt.Sprintf("%#x", e.Root),
+			Weight:   fmt.Sprintf("%d", e.Weight),
+			Reorg:    e.Reorg,
+			Decision: e.Decision,
+			Reason:   e.Reason,
+		}
+		if e.ParentRoot != [fieldparams.RootLength]byte{} {
+			j.ParentRoot = fmt.Sprintf("%#x", e.ParentRoot)
+		}
+		if e.Type == EventHeadChange && e.PreviousRoot != [fieldparams.RootLength]byte{} {
+			j.PreviousSlot = fmt.Sprintf("%d", e.PreviousSlot)
+			j.PreviousRoot = fmt.Sprintf("%#x", e.PreviousRoot)
+		}
+		if e.Type == EventWeightUpdate {
+			j.PreviousWeight = fmt.Sprintf("%d", e.PreviousWeight)
+		}
+		if e.Type == EventProposerBoost {
+			j.BoostScore = fmt.Sprintf("%d", e.BoostScore)
+		}
+		h.Events[i] = j
+	}
+	return errors.Wrap(json.NewEncoder(w).Encode(h), "could not encode fork choice history")
+}
+
+// ExportGraphviz writes the recorded events to w as a Graphviz digraph. Blocks are linked to
+// their parents by solid edges and head changes are drawn as dashed edges, in red when the
+// new head does not descend from the previous one. Boosted blocks are drawn in blue and
+// blocks which were decided to be orphaned by the late block reorg logic in red.
+func (r *Recorder) ExportGraphviz(w io.Writer) error {
+	events := r.Events()
+	type block struct {
+		slot    primitives.Slot
+		parent  [fieldparams.RootLength]byte
+		weight  uint64
+		boosted bool
+		orphan  bool
+	}
+	blocks := make(map[[fieldparams.RootLength]byte]*block)
+	var order [][fieldparams.RootLength]byte
+	see := func(root [fieldparams.RootLength]byte, slot primitives.Slot) *block {
+		b, ok := blocks[root]
+		if !ok {
+			b = &block{slot: slot}
+			blocks[root] = b
+			order = append(order, root)
+		}
+		return b
+	}
+	for _, e := range events {
+		b := see(e.Root, e.Slot)
+		if e.ParentRoot != [fieldparams.RootLength]byte{} {
+			b.parent = e.ParentRoot
+		}
+		b.weight = e.Weight
+		switch e.Type {
+		case EventHeadChange:
+			if e.PreviousRoot != [fieldparams.RootLength]byte{} {
+				see(e.PreviousRoot, e.PreviousSlot)
+			}
+		case EventProposerBoost:
+			b.boosted = true
+		case EventLateBlockReorg:
+			b.orphan = b.orphan || e.Reorg
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph forkchoice {")
+	fmt.Fprintln(bw, "\trankdir=LR;")
+	fmt.Fprintln(bw, "\tnode [shape=box];")
+	for _, root := range order {
+		b := blocks[root]
+		attrs := ""
+		switch {
+		case b.orphan:
+			attrs = ", color=red"
+		case b.boosted:
+			attrs = ", color=blue"
+		}
+		fmt.Fprintf(bw, "\t\"%#x\" [label=\"slot %d\\n%#x\\nweight %d\"%s];\n", root, b.slot, root[:4], b.weight, attrs)
+	}
+	for _, root := range order {
+		b := blocks[root]
+		if _, ok := blocks[b.parent]; ok {
+			fmt.Fprintf(bw, "\t\"%#x\" -> \"%#x\";\n", b.parent, root)
+		}
+	}
+	for _, e := range events {
+		if e.Type != EventHeadChange || e.PreviousRoot == [fieldparams.RootLength]byte{} {
+			continue
+		}
+		color := "gray"
+		if e.Reorg {
+			color = "red"
+		}
+		fmt.Fprintf(bw, "\t\"%#x\" -> \"%#x\" [style=dashed, color=%s, label=\"head #%d\"];\n", e.PreviousRoot, e.Root, color, e.Index)
+	}
+	fmt.Fprintln(bw, "}")
+	return errors.Wrap(bw.Flush(), "could not write fork choice graph")
+}
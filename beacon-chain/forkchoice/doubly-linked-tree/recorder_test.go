@@ -0,0 +1,182 @@
+package doublylinkedtree
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+)
+
+func TestRecorder_RingBuffer(t *testing.T) {
+	r := NewRecorder(3)
+	for i := 0; i < 5; i++ {
+		r.record(RecordedEvent{Type: EventHeadChange, Root: [32]byte{byte(i)}})
+	}
+	events := r.Events()
+	require.Equal(t, 3, len(events))
+	for i, e := range events {
+		assert.Equal(t, uint64(i+2), e.Index)
+		assert.Equal(t, [32]byte{byte(i + 2)}, e.Root)
+	}
+
+	var nilRecorder *Recorder
+	nilRecorder.recordHeadChange(nil, &Node{})
+	assert.Equal(t, 0, len(nilRecorder.Events()))
+}
+
+func TestRecorder_HeadChanges(t *testing.T) {
+	ctx := context.Background()
+	f := setup(0, 0)
+	f.store.recorder = NewRecorder(64)
+	f.justifiedBalances = []uint64{10, 10, 10}
+
+	st, root, err := prepareForkchoiceState(ctx, 1, [32]byte{'a'}, params.BeaconConfig().ZeroHash, [32]byte{'A'}, 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, f.InsertNode(ctx, st, root))
+	st, root, err = prepareForkchoiceState(ctx, 2, [32]byte{'b'}, [32]byte{'a'}, [32]byte{'B'}, 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, f.InsertNode(ctx, st, root))
+	headRoot, err := f.Head(ctx)
+	require.NoError(t, err)
+	require.Equal(t, [32]byte{'b'}, headRoot)
+
+	// A sibling of the head with more votes and the proposer boost reorgs the head.
+	st, root, err = prepareForkchoiceState(ctx, 3, [32]byte{'c'}, [32]byte{'a'}, [32]byte{'C'}, 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, f.InsertNode(ctx, st, root))
+	f.ProcessAttestation(ctx, []uint64{0, 1}, [32]byte{'c'}, 0)
+	f.store.proposerBoostRoot = [32]byte{'c'}
+	f.store.committeeWeight = 30
+	headRoot, err = f.Head(ctx)
+	require.NoError(t, err)
+	require.Equal(t, [32]byte{'c'}, headRoot)
+
+	var heads, boosts, weights []RecordedEvent
+	for _, e := range f.store.recorder.Events() {
+		switch e.Type {
+		case EventHeadChange:
+			heads = append(heads, e)
+		case EventProposerBoost:
+			boosts = append(boosts, e)
+		case EventWeightUpdate:
+			weights = append(weights, e)
+		}
+	}
+	require.Equal(t, 2, len(heads))
+	assert.Equal(t, [32]byte{'b'}, heads[0].Root)
+	assert.Equal(t, false, heads[0].Reorg)
+	assert.Equal(t, [32]byte{'c'}, heads[1].Root)
+	assert.Equal(t, [32]byte{'b'}, heads[1].PreviousRoot)
+	assert.Equal(t, [32]byte{'a'}, heads[1].ParentRoot)
+	assert.Equal(t, true, heads[1].Reorg)
+
+	require.Equal(t, 1, len(boosts))
+	assert.Equal(t, [32]byte{'c'}, boosts[0].Root)
+	assert.Equal(t, 30*params.BeaconConfig().ProposerScoreBoost/100, boosts[0].BoostScore)
+
+	require.NotEqual(t, 0, len(weights))
+	last := weights[len(weights)-1]
+	assert.Equal(t, [32]byte{'c'}, last.Root)
+	assert.Equal(t, uint64(20)+boosts[0].BoostScore, last.Weight)
+
+	// A head that did not change records nothing new.
+	count := len(f.store.recorder.Events())
+	_, err = f.Head(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, count, len(f.store.recorder.Events()))
+}
+
+func TestRecorder_LateBlockReorgDecisions(t *testing.T) {
+	f := setup(0, 0)
+	f.store.recorder = NewRecorder(64)
+	f.numActiveValidators = 640
+	f.justifiedBalances = make([]uint64, f.numActiveValidators)
+	for i := range f.justifiedBalances {
+		f.justifiedBalances[i] = uint64(10)
+		f.store.committeeWeight += uint64(10)
+	}
+	f.store.committeeWeight /= uint64(params.BeaconConfig().SlotsPerEpoch)
+	ctx := context.Background()
+	driftGenesisTime(f, 1, 0)
+	st, root, err := prepareForkchoiceState(ctx, 1, [32]byte{'a'}, [32]byte{}, [32]byte{'A'}, 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, f.InsertNode(ctx, st, root))
+	attesters := make([]uint64, f.numActiveValidators-64)
+	for i := range attesters {
+		attesters[i] = uint64(i + 64)
+	}
+	f.ProcessAttestation(ctx, attesters, root, 0)
+
+	driftGenesisTime(f, 2, orphanLateBlockFirstThreshold+1)
+	st, root, err = prepareForkchoiceState(ctx, 2, [32]byte{'b'}, [32]byte{'a'}, [32]byte{'B'}, 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, f.InsertNode(ctx, st, root))
+	_, err = f.Head(ctx)
+	require.NoError(t, err)
+
+	require.Equal(t, true, f.ShouldOverrideFCU())
+	events := f.store.recorder.Events()
+	e := events[len(events)-1]
+	assert.Equal(t, EventLateBlockReorg, e.Type)
+	assert.Equal(t, decisionOverrideFCU, e.Decision)
+	assert.Equal(t, true, e.Reorg)
+	assert.Equal(t, reasonWeakHead, e.Reason)
+	assert.Equal(t, [32]byte{'b'}, e.Root)
+
+	saved := f.store.headNode.timestamp
+	f.store.headNode.timestamp = saved - 2
+	require.Equal(t, false, f.ShouldOverrideFCU())
+	f.store.headNode.timestamp = saved
+	events = f.store.recorder.Events()
+	e = events[len(events)-1]
+	assert.Equal(t, false, e.Reorg)
+	assert.Equal(t, reasonArrivedEarly, e.Reason)
+
+	// Decisions on heads which are not from the current slot are not recorded.
+	count := len(events)
+	driftGenesisTime(f, 3, 0)
+	require.Equal(t, false, f.ShouldOverrideFCU())
+	assert.Equal(t, count, len(f.store.recorder.Events()))
+}
+
+func TestRecorder_Export(t *testing.T) {
+	r := NewRecorder(16)
+	a := &Node{slot: 1, root: [32]byte{'a'}}
+	b := &Node{slot: 2, root: [32]byte{'b'}, parent: a, weight: 10}
+	c := &Node{slot: 3, root: [32]byte{'c'}, parent: a, weight: 20}
+	a.children = []*Node{b, c}
+	r.recordHeadChange(a, b)
+	r.recordProposerBoost(c, 5)
+	r.recordHeadChange(b, c)
+	r.recordReorgDecision(c, decisionProposerHead, false, reasonHeadStrong)
+
+	var buf bytes.Buffer
+	require.NoError(t, r.ExportJSON(&buf))
+	var h historyJson
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &h))
+	assert.Equal(t, uint64(4), h.Recorded)
+	assert.Equal(t, 16, h.Capacity)
+	require.Equal(t, 4, len(h.Events))
+	assert.Equal(t, EventHeadChange, h.Events[2].Type)
+	assert.Equal(t, "3", h.Events[2].Slot)
+	assert.Equal(t, fmt.Sprintf("%#x", [32]byte{'c'}), h.Events[2].Root)
+	assert.Equal(t, fmt.Sprintf("%#x", [32]byte{'b'}), h.Events[2].PreviousRoot)
+	assert.Equal(t, true, h.Events[2].Reorg)
+	assert.Equal(t, "5", h.Events[1].BoostScore)
+	assert.Equal(t, reasonHeadStrong, h.Events[3].Reason)
+
+	buf.Reset()
+	require.NoError(t, r.ExportGraphviz(&buf))
+	dot := buf.String()
+	assert.Equal(t, true, strings.HasPrefix(dot, "digraph forkchoice {"))
+	assert.Equal(t, true, strings.Contains(dot, fmt.Sprintf("\"%#x\" -> \"%#x\";", [32]byte{'a'}, [32]byte{'c'})))
+	assert.Equal(t, true, strings.Contains(dot, fmt.Sprintf("\"%#x\" -> \"%#x\" [style=dashed, color=red, label=\"head #2\"];", [32]byte{'b'}, [32]byte{'c'})))
+	assert.Equal(t, true, strings.Contains(dot, fmt.Sprintf("\"%#x\" -> \"%#x\" [style=dashed, color=gray, label=\"head #0\"];", [32]byte{'a'}, [32]byte{'b'})))
+	assert.Equal(t, true, strings.Contains(dot, "color=blue"))
+}
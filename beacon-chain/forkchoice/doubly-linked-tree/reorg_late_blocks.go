@@ -1,8 +1,6 @@
 package doublylinkedtree
 
 import (
-	"time"
-
 	"github.com/theQRL/qrysm/v4/config/features"
 	"github.com/theQRL/qrysm/v4/config/params"
 	"github.com/theQRL/qrysm/v4/time/slots"
@@ -32,46 +30,72 @@ const orphanLateBlockProposingEarly = 2
 // the engine's view of head with the parent block or the incoming block. It
 // does not guarantee an attempted reorg. This will only be decided later at
 // proposal time by calling GetProposerHead.
-func (f *ForkChoice) ShouldOverrideFCU() (override bool) {
-	override = false
+func (f *ForkChoice) ShouldOverrideFCU() bool {
+	override, reason := f.shouldOverrideFCU()
+	// Decisions on heads which are not from the current slot are taken on every
+	// call and are not worth recording.
+	if reason != reasonHeadNotCurrent {
+		f.store.recorder.recordReorgDecision(f.store.headNode, decisionOverrideFCU, override, reason)
+	}
+	return override
+}
+
+// Reasons for which a late block is or is not reorged.
+const (
+	reasonNoHead               = "no head"
+	reasonHeadNotCurrent       = "head is not from the current slot"
+	reasonEpochBoundary        = "next slot is an epoch boundary"
+	reasonArrivalUnknown       = "could not check if block arrived early"
+	reasonArrivedEarly         = "head arrived early"
+	reasonNotFinalizing        = "chain is not finalizing"
+	reasonNoParent             = "head has no parent"
+	reasonNotSingleSlot        = "head is not from the slot after its parent"
+	reasonHeadStrong           = "head LMD vote is strong"
+	reasonParentWeak           = "parent LMD vote is weak"
+	reasonProposingTimeUnknown = "could not check if proposing early"
+	reasonProposingLate        = "not proposing early"
+	reasonWeakHead             = "head is weak and arrived late"
+)
 
+// shouldOverrideFCU implements ShouldOverrideFCU and returns the reason of its decision.
+func (f *ForkChoice) shouldOverrideFCU() (bool, string) {
 	// We only need to override FCU if our current head is from the current
 	// slot. This differs from the spec implementation in that we assume
 	// that we will call this function in the previous slot to proposing.
 	head := f.store.headNode
 	if head == nil {
-		return
+		return false, reasonNoHead
 	}
 
-	if head.slot != slots.CurrentSlot(f.store.genesisTime) {
-		return
+	if head.slot != f.store.currentSlot() {
+		return false, reasonHeadNotCurrent
 	}
 
 	// Do not reorg on epoch boundaries
 	if (head.slot+1)%params.BeaconConfig().SlotsPerEpoch == 0 {
-		return
+		return false, reasonEpochBoundary
 	}
 	// Only reorg blocks that arrive late
 	early, err := head.arrivedEarly(f.store.genesisTime)
 	if err != nil {
 		log.WithError(err).Error("could not check if block arrived early")
-		return
+		return false, reasonArrivalUnknown
 	}
 	if early {
-		return
+		return false, reasonArrivedEarly
 	}
 	// Only reorg if we have been finalizing
 	finalizedEpoch := f.store.finalizedCheckpoint.Epoch
 	if slots.ToEpoch(head.slot+1) > finalizedEpoch+params.BeaconConfig().ReorgMaxEpochsSinceFinalization {
-		return
+		return false, reasonNotFinalizing
 	}
 	// Only orphan a single block
 	parent := head.parent
 	if parent == nil {
-		return
+		return false, reasonNoParent
 	}
 	if head.slot > parent.slot+1 {
-		return
+		return false, reasonNotSingleSlot
 	}
 	// Do not orphan a block that has higher justification than the parent
 	// if head.unrealizedJustifiedEpoch > parent.unrealizedJustifiedEpoch {
@@ -80,14 +104,14 @@ func (f *ForkChoice) ShouldOverrideFCU() (override bool) {
 
 	// Only orphan a block if the head LMD vote is weak
 	if head.weight*100 > f.store.committeeWeight*params.BeaconConfig().ReorgWeightThreshold {
-		return
+		return false, reasonHeadStrong
 	}
 
 	// Only orphan a block if the parent LMD vote is strong
 	if parent.weight*100 < f.store.committeeWeight*params.BeaconConfig().ReorgParentWeightThreshold {
-		return
+		return false, reasonParentWeak
 	}
-	return true
+	return true, reasonWeakHead
 }
 
 // GetProposerHead returns the block root that has to be used as ParentRoot by a
@@ -101,60 +125,70 @@ func (f *ForkChoice) GetProposerHead() [32]byte {
 	if features.Get().DisableReorgLateBlocks {
 		return f.CachedHeadRoot()
 	}
+	root, reason := f.getProposerHead()
+	head := f.store.headNode
+	if head != nil && reason != reasonHeadNotCurrent {
+		f.store.recorder.recordReorgDecision(head, decisionProposerHead, root != head.root, reason)
+	}
+	return root
+}
+
+// getProposerHead implements GetProposerHead and returns the reason of its decision.
+func (f *ForkChoice) getProposerHead() ([32]byte, string) {
 	head := f.store.headNode
 	if head == nil {
-		return [32]byte{}
+		return [32]byte{}, reasonNoHead
 	}
 
 	// Only reorg blocks from the previous slot.
-	if head.slot+1 != slots.CurrentSlot(f.store.genesisTime) {
-		return head.root
+	if head.slot+1 != f.store.currentSlot() {
+		return head.root, reasonHeadNotCurrent
 	}
 	// Do not reorg on epoch boundaries
 	if (head.slot+1)%params.BeaconConfig().SlotsPerEpoch == 0 {
-		return head.root
+		return head.root, reasonEpochBoundary
 	}
 	// Only reorg blocks that arrive late
 	early, err := head.arrivedEarly(f.store.genesisTime)
 	if err != nil {
 		log.WithError(err).Error("could not check if block arrived early")
-		return head.root
+		return head.root, reasonArrivalUnknown
 	}
 	if early {
-		return head.root
+		return head.root, reasonArrivedEarly
 	}
 	// Only reorg if we have been finalizing
 	finalizedEpoch := f.store.finalizedCheckpoint.Epoch
 	if slots.ToEpoch(head.slot+1) > finalizedEpoch+params.BeaconConfig().ReorgMaxEpochsSinceFinalization {
-		return head.root
+		return head.root, reasonNotFinalizing
 	}
 	// Only orphan a single block
 	parent := head.parent
 	if parent == nil {
-		return head.root
+		return head.root, reasonNoParent
 	}
 	if head.slot > parent.slot+1 {
-		return head.root
+		return head.root, reasonNotSingleSlot
 	}
 
 	// Only orphan a block if the head LMD vote is weak
 	if head.weight*100 > f.store.committeeWeight*params.BeaconConfig().ReorgWeightThreshold {
-		return head.root
+		return head.root, reasonHeadStrong
 	}
 
 	// Only orphan a block if the parent LMD vote is strong
 	if parent.weight*100 < f.store.committeeWeight*params.BeaconConfig().ReorgParentWeightThreshold {
-		return head.root
+		return head.root, reasonParentWeak
 	}
 
 	// Only reorg if we are proposing early
-	secs, err := slots.SecondsSinceSlotStart(head.slot+1, f.store.genesisTime, uint64(time.Now().Unix()))
+	secs, err := slots.SecondsSinceSlotStart(head.slot+1, f.store.genesisTime, uint64(f.store.currentTime().Unix()))
 	if err != nil {
 		log.WithError(err).Error("could not check if proposing early")
-		return head.root
+		return head.root, reasonProposingTimeUnknown
 	}
 	if secs >= orphanLateBlockProposingEarly {
-		return head.root
+		return head.root, reasonProposingLate
 	}
-	return parent.root
+	return parent.root, reasonWeakHead
 }
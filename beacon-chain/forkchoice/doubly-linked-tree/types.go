@@ -2,6 +2,7 @@ package doublylinkedtree
 
 import (
 	"sync"
+	"time"
 
 	"github.com/theQRL/qrysm/v4/beacon-chain/forkchoice"
 	forkchoicetypes "github.com/theQRL/qrysm/v4/beacon-chain/forkchoice/types"
@@ -41,6 +42,8 @@ type Store struct {
 	highestReceivedNode           *Node                                      // The highest slot node.
 	receivedBlocksLastEpoch       [fieldparams.SlotsPerEpoch]primitives.Slot // Using `highestReceivedSlot`. The slot of blocks received in the last epoch.
 	allTipsAreInvalid             bool                                       // tracks if all tips are not viable for head
+	recorder                      *Recorder                                  // records the history of fork choice when enabled.
+	now                           func() time.Time                           // the source of the current time, the system time if nil.
 }
 
 // Node defines the individual block which includes its block parent, ancestor and how much weight accounted for it.
@@ -2,6 +2,7 @@ package forkchoice
 
 import (
 	"context"
+	"io"
 
 	forkchoicetypes "github.com/theQRL/qrysm/v4/beacon-chain/forkchoice/types"
 	"github.com/theQRL/qrysm/v4/beacon-chain/state"
@@ -63,6 +64,7 @@ type Getter interface {
 	HighestReceivedBlockSlot() primitives.Slot
 	ReceivedBlocksLastEpoch() (uint64, error)
 	ForkChoiceDump(context.Context) (*v1.ForkChoiceDump, error)
+	History() HistoryExporter
 	Weight(root [32]byte) (uint64, error)
 	Tips() ([][32]byte, []primitives.Slot)
 	IsOptimistic(root [32]byte) (bool, error)
@@ -82,3 +84,10 @@ type Setter interface {
 	SetBalancesByRooter(BalancesByRooter)
 	InsertSlashedIndex(context.Context, primitives.ValidatorIndex)
 }
+
+// HistoryExporter exports the events recorded by fork choice, such as head changes and
+// late block reorg decisions.
+type HistoryExporter interface {
+	ExportJSON(w io.Writer) error
+	ExportGraphviz(w io.Writer) error
+}
@@ -0,0 +1,311 @@
+package replay
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	ssz "github.com/prysmaticlabs/fastssz"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/blocks"
+	"github.com/theQRL/qrysm/v4/consensus-types/interfaces"
+	"github.com/theQRL/qrysm/v4/encoding/ssz/detect"
+	"github.com/theQRL/qrysm/v4/io/file"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/runtime/version"
+)
+
+// captureMagic starts every capture file, followed by the name of the chain config the
+// capture was recorded with.
+var captureMagic = [8]byte{'q', 'r', 'y', 's', 'm', 'f', 'c', '1'}
+
+// maxRecordSize bounds the size of a record, so that a corrupted length does not
+// allocate an arbitrary amount of memory.
+const maxRecordSize = 1 << 30
+
+type recordKind uint8
+
+const (
+	kindAnchorState recordKind = iota + 1
+	kindAnchorBlock
+	kindBlock
+	kindAttestation
+)
+
+// Writer appends gossip arrivals to a capture. Each record holds its kind, the arrival
+// time in unix nanoseconds, and the SSZ encoding of the object, prefixed with the fork of
+// the block for blocks. A capture starts with the anchor state and block, from which a
+// replay initializes the chain. Every record is flushed as soon as it is written, so that a
+// capture cut short by a crash holds everything received up to it. It is safe for concurrent use.
+type Writer struct {
+	mu        sync.Mutex
+	w         *bufio.Writer
+	c         io.Closer
+	hasAnchor bool
+}
+
+// Create creates the capture file at the path, replacing any existing file.
+func Create(path string) (*Writer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, params.BeaconIoConfig().ReadWritePermissions) // #nosec G304
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create capture file %s", path)
+	}
+	w, err := NewWriter(f)
+	if err != nil {
+		return nil, err
+	}
+	w.c = f
+	return w, nil
+}
+
+// NewWriter starts a capture on w.
+func NewWriter(w io.Writer) (*Writer, error) {
+	bw := bufio.NewWriter(w)
+	name := []byte(params.BeaconConfig().ConfigName)
+	var header [2]byte
+	binary.LittleEndian.PutUint16(header[:], uint16(len(name)))
+	for _, b := range [][]byte{captureMagic[:], header[:], name} {
+		if _, err := bw.Write(b); err != nil {
+			return nil, errors.Wrap(err, "could not write capture header")
+		}
+	}
+	return &Writer{w: bw}, nil
+}
+
+// WriteAnchor records the state and block the capture starts from. It must be called once,
+// before any arrival is recorded.
+func (w *Writer) WriteAnchor(st state.ReadOnlyBeaconState, blk interfaces.ReadOnlySignedBeaconBlock) error {
+	if st == nil || st.IsNil() {
+		return errors.New("nil anchor state")
+	}
+	if err := checkBlock(blk); err != nil {
+		return err
+	}
+	stateBytes, err := st.MarshalSSZ()
+	if err != nil {
+		return errors.Wrap(err, "could not marshal anchor state")
+	}
+	blockBytes, err := marshalBlock(blk)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal anchor block")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.hasAnchor {
+		return errors.New("capture already has an anchor")
+	}
+	now := time.Now()
+	if err := w.write(kindAnchorState, now, stateBytes); err != nil {
+		return err
+	}
+	if err := w.write(kindAnchorBlock, now, blockBytes); err != nil {
+		return err
+	}
+	w.hasAnchor = true
+	return nil
+}
+
+// WriteBlock records the arrival of a block at the given time.
+func (w *Writer) WriteBlock(t time.Time, blk interfaces.ReadOnlySignedBeaconBlock) error {
+	if err := checkBlock(blk); err != nil {
+		return err
+	}
+	b, err := marshalBlock(blk)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal block")
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writeArrival(kindBlock, t, b)
+}
+
+// WriteAttestation records the arrival of an attestation at the given time.
+func (w *Writer) WriteAttestation(t time.Time, att *zondpb.Attestation) error {
+	if att == nil || att.Data == nil {
+		return errors.New("nil attestation")
+	}
+	b, err := att.MarshalSSZ()
+	if err != nil {
+		return errors.Wrap(err, "could not marshal attestation")
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writeArrival(kindAttestation, t, b)
+}
+
+// Close flushes the capture and closes the underlying file, if any.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.w.Flush(); err != nil {
+		return errors.Wrap(err, "could not flush capture")
+	}
+	if w.c != nil {
+		return w.c.Close()
+	}
+	return nil
+}
+
+func (w *Writer) writeArrival(kind recordKind, t time.Time, data []byte) error {
+	if !w.hasAnchor {
+		return errors.New("capture has no anchor")
+	}
+	return w.write(kind, t, data)
+}
+
+func (w *Writer) write(kind recordKind, t time.Time, data []byte) error {
+	var header [13]byte
+	header[0] = byte(kind)
+	binary.LittleEndian.PutUint64(header[1:9], uint64(t.UnixNano()))
+	binary.LittleEndian.PutUint32(header[9:13], uint32(len(data)))
+	if _, err := w.w.Write(header[:]); err != nil {
+		return errors.Wrap(err, "could not write record header")
+	}
+	if _, err := w.w.Write(data); err != nil {
+		return errors.Wrap(err, "could not write record")
+	}
+	return errors.Wrap(w.w.Flush(), "could not flush record")
+}
+
+func marshalBlock(blk interfaces.ReadOnlySignedBeaconBlock) ([]byte, error) {
+	b, err := blk.MarshalSSZ()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{byte(blk.Version())}, b...), nil
+}
+
+func unmarshalBlock(b []byte) (interfaces.ReadOnlySignedBeaconBlock, error) {
+	if len(b) == 0 {
+		return nil, errors.New("empty block record")
+	}
+	var blk ssz.Unmarshaler
+	switch int(b[0]) {
+	case version.Phase0:
+		blk = &zondpb.SignedBeaconBlock{}
+	case version.Altair:
+		blk = &zondpb.SignedBeaconBlockAltair{}
+	case version.Bellatrix:
+		blk = &zondpb.SignedBeaconBlockBellatrix{}
+	case version.Capella:
+		blk = &zondpb.SignedBeaconBlockCapella{}
+	default:
+		return nil, errors.Errorf("unknown block version %d", b[0])
+	}
+	if err := blk.UnmarshalSSZ(b[1:]); err != nil {
+		return nil, err
+	}
+	return blocks.NewSignedBeaconBlock(blk)
+}
+
+func checkBlock(blk interfaces.ReadOnlySignedBeaconBlock) error {
+	if blk == nil || blk.IsNil() {
+		return errors.New("nil block")
+	}
+	if blk.IsBlinded() {
+		return errors.New("cannot capture a blinded block")
+	}
+	return nil
+}
+
+// Arrival is a block or an attestation received over gossip. Exactly one of Block and
+// Attestation is set.
+type Arrival struct {
+	Time        time.Time
+	Block       interfaces.ReadOnlySignedBeaconBlock
+	Attestation *zondpb.Attestation
+}
+
+// Capture is a decoded capture file.
+type Capture struct {
+	ConfigName  string
+	AnchorState state.BeaconState
+	AnchorBlock interfaces.ReadOnlySignedBeaconBlock
+	// Arrivals are sorted by their arrival time.
+	Arrivals []*Arrival
+}
+
+// ReadFile decodes the capture file at the path.
+func ReadFile(path string) (*Capture, error) {
+	b, err := file.ReadFileAsBytes(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read capture file %s", path)
+	}
+	return Read(b)
+}
+
+// Read decodes a capture.
+func Read(b []byte) (*Capture, error) {
+	if len(b) < len(captureMagic)+2 || [8]byte(b[:8]) != captureMagic {
+		return nil, errors.New("not a fork choice capture")
+	}
+	nameLen := int(binary.LittleEndian.Uint16(b[8:10]))
+	b = b[10:]
+	if len(b) < nameLen {
+		return nil, errors.New("truncated capture header")
+	}
+	c := &Capture{ConfigName: string(b[:nameLen])}
+	b = b[nameLen:]
+
+	for len(b) > 0 {
+		if len(b) < 13 {
+			return nil, errors.New("truncated record header")
+		}
+		kind := recordKind(b[0])
+		t := time.Unix(0, int64(binary.LittleEndian.Uint64(b[1:9])))
+		size := binary.LittleEndian.Uint32(b[9:13])
+		if size > maxRecordSize || uint64(len(b)-13) < uint64(size) {
+			return nil, errors.Errorf("truncated record of %d bytes", size)
+		}
+		data := b[13 : 13+size]
+		b = b[13+size:]
+
+		switch kind {
+		case kindAnchorState:
+			if c.AnchorState != nil {
+				return nil, errors.New("capture has several anchor states")
+			}
+			u, err := detect.FromState(data)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not detect the fork of the anchor state")
+			}
+			st, err := u.UnmarshalBeaconState(data)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not unmarshal anchor state")
+			}
+			c.AnchorState = st
+		case kindAnchorBlock, kindBlock:
+			blk, err := unmarshalBlock(data)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not unmarshal block")
+			}
+			if kind == kindAnchorBlock {
+				c.AnchorBlock = blk
+			} else {
+				c.Arrivals = append(c.Arrivals, &Arrival{Time: t, Block: blk})
+			}
+		case kindAttestation:
+			att := &zondpb.Attestation{}
+			if err := att.UnmarshalSSZ(data); err != nil {
+				return nil, errors.Wrap(err, "could not unmarshal attestation")
+			}
+			c.Arrivals = append(c.Arrivals, &Arrival{Time: t, Attestation: att})
+		default:
+			return nil, errors.Errorf("unknown record kind %d", kind)
+		}
+	}
+	if c.AnchorState == nil || c.AnchorBlock == nil {
+		return nil, errors.New("capture has no anchor")
+	}
+	sort.SliceStable(c.Arrivals, func(i, j int) bool {
+		return c.Arrivals[i].Time.Before(c.Arrivals[j].Time)
+	})
+	return c, nil
+}
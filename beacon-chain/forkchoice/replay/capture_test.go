@@ -0,0 +1,102 @@
+package replay
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	fieldparams "github.com/theQRL/qrysm/v4/config/fieldparams"
+	"github.com/theQRL/qrysm/v4/consensus-types/blocks"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/util"
+)
+
+// anchorState returns a marshalable state, whose sync committees have Dilithium sized keys.
+func anchorState(t *testing.T) state.BeaconState {
+	st, err := util.NewBeaconStateCapella(func(s *zondpb.BeaconStateCapella) error {
+		pubkeys := make([][]byte, fieldparams.SyncCommitteeLength)
+		for i := range pubkeys {
+			pubkeys[i] = make([]byte, dilithium2.CryptoPublicKeyBytes)
+		}
+		sc := &zondpb.SyncCommittee{
+			Pubkeys:         pubkeys,
+			AggregatePubkey: make([]byte, fieldparams.SyncCommitteeLength*dilithium2.CryptoPublicKeyBytes),
+		}
+		s.CurrentSyncCommittee = sc
+		s.NextSyncCommittee = sc
+		return nil
+	})
+	require.NoError(t, err)
+	return st
+}
+
+func TestCapture_RoundTrip(t *testing.T) {
+	st := anchorState(t)
+	require.NoError(t, st.SetSlot(3))
+	anchor, err := blocks.NewSignedBeaconBlock(util.NewBeaconBlockCapella())
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	require.NoError(t, err)
+	att := util.NewAttestation()
+	require.ErrorContains(t, "capture has no anchor", w.WriteAttestation(time.Unix(10, 0), att))
+	require.NoError(t, w.WriteAnchor(st, anchor))
+	require.ErrorContains(t, "capture already has an anchor", w.WriteAnchor(st, anchor))
+
+	b := util.NewBeaconBlockCapella()
+	b.Block.Slot = 4
+	blk, err := blocks.NewSignedBeaconBlock(b)
+	require.NoError(t, err)
+	// Arrivals are written out of order, as concurrent gossip validators may do.
+	require.NoError(t, w.WriteAttestation(time.Unix(20, 5), att))
+	require.NoError(t, w.WriteBlock(time.Unix(20, 0), blk))
+	// Every record is flushed as it is written, so the capture is readable before it is closed.
+	c, err := Read(buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, 2, len(c.Arrivals))
+	require.NoError(t, w.Close())
+
+	c, err = Read(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, st.Slot(), c.AnchorState.Slot())
+	assert.Equal(t, anchor.Version(), c.AnchorBlock.Version())
+	require.Equal(t, 2, len(c.Arrivals))
+	require.NotNil(t, c.Arrivals[0].Block)
+	assert.Equal(t, time.Unix(20, 0).UnixNano(), c.Arrivals[0].Time.UnixNano())
+	assert.Equal(t, b.Block.Slot, c.Arrivals[0].Block.Block().Slot())
+	wantRoot, err := blk.Block().HashTreeRoot()
+	require.NoError(t, err)
+	gotRoot, err := c.Arrivals[0].Block.Block().HashTreeRoot()
+	require.NoError(t, err)
+	assert.Equal(t, wantRoot, gotRoot)
+	require.NotNil(t, c.Arrivals[1].Attestation)
+	assert.DeepEqual(t, att, c.Arrivals[1].Attestation)
+}
+
+func TestCapture_ReadInvalid(t *testing.T) {
+	_, err := Read([]byte("not a capture"))
+	require.ErrorContains(t, "not a fork choice capture", err)
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	_, err = Read(buf.Bytes())
+	require.ErrorContains(t, "capture has no anchor", err)
+
+	st := anchorState(t)
+	anchor, err := blocks.NewSignedBeaconBlock(util.NewBeaconBlockCapella())
+	require.NoError(t, err)
+	buf.Reset()
+	w, err = NewWriter(&buf)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteAnchor(st, anchor))
+	require.NoError(t, w.Close())
+	_, err = Read(buf.Bytes()[:buf.Len()-1])
+	require.ErrorContains(t, "truncated record", err)
+}
@@ -0,0 +1,5 @@
+// Package replay captures the blocks and attestations a node receives over gossip, with
+// their arrival times, and replays them deterministically through a blockchain service to
+// find out how fork choice moved the head. It is meant to investigate incidents where nodes
+// disagreed on the head of the chain.
+package replay
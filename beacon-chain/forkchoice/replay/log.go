@@ -0,0 +1,5 @@
+package replay
+
+import "github.com/sirupsen/logrus"
+
+var log = logrus.WithField("prefix", "forkchoice-replay")
@@ -0,0 +1,335 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/theQRL/qrysm/v4/async/event"
+	"github.com/theQRL/qrysm/v4/beacon-chain/blockchain"
+	"github.com/theQRL/qrysm/v4/beacon-chain/cache"
+	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositcache"
+	coreTime "github.com/theQRL/qrysm/v4/beacon-chain/core/time"
+	"github.com/theQRL/qrysm/v4/beacon-chain/db/kv"
+	mockExecution "github.com/theQRL/qrysm/v4/beacon-chain/execution/testing"
+	doublylinkedtree "github.com/theQRL/qrysm/v4/beacon-chain/forkchoice/doubly-linked-tree"
+	"github.com/theQRL/qrysm/v4/beacon-chain/operations/attestations"
+	"github.com/theQRL/qrysm/v4/beacon-chain/startup"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state/stategen"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/interfaces"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/time/slots"
+)
+
+// SlotHead is the view of fork choice at the end of a slot of a replay.
+type SlotHead struct {
+	Slot           primitives.Slot  `json:"slot"`
+	HeadRoot       string           `json:"head_root"`
+	HeadSlot       primitives.Slot  `json:"head_slot"`
+	JustifiedEpoch primitives.Epoch `json:"justified_epoch"`
+	FinalizedEpoch primitives.Epoch `json:"finalized_epoch"`
+	Blocks         int              `json:"blocks"`
+	Attestations   int              `json:"attestations"`
+	// Errors are the errors returned when processing the arrivals of the slot.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Report is the result of a replay, with the head at each slot.
+type Report struct {
+	Slots []*SlotHead `json:"slots"`
+}
+
+// Replayer feeds the arrivals of a capture through a blockchain service, in the style of
+// the fork choice runner of the spec tests. The service and its fork choice read the time
+// from a replay clock that only moves when the replay ticks, so that each arrival is
+// processed at the time it was received, and the execution engine is mocked to accept
+// every payload. Like the pending queues of the sync service, blocks of an unknown parent
+// and attestations of an unknown block are held until that block is imported.
+type Replayer struct {
+	service     *blockchain.Service
+	db          *kv.Store
+	dir         string
+	genesis     time.Time
+	clock       *clock
+	slot        primitives.Slot
+	pendingBlks map[[32]byte][]interfaces.ReadOnlySignedBeaconBlock
+	pendingAtts map[[32]byte][]*zondpb.Attestation
+}
+
+// clock is the clock of a replay.
+type clock struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.now
+}
+
+func (c *clock) set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+type stateNotifier struct {
+	feed event.Feed
+}
+
+func (n *stateNotifier) StateFeed() *event.Feed {
+	return &n.feed
+}
+
+// NewReplayer starts a blockchain service from the anchor state and block, backed by a
+// database in a temporary directory. Close must be called to remove it.
+func NewReplayer(ctx context.Context, st state.BeaconState, blk interfaces.ReadOnlySignedBeaconBlock) (*Replayer, error) {
+	dir, err := os.MkdirTemp("", "forkchoice-replay")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create database directory")
+	}
+	r := &Replayer{
+		dir:         dir,
+		genesis:     time.Unix(int64(st.GenesisTime()), 0), // lint:ignore uintcast -- Genesis time will not exceed int64 in your lifetime.
+		slot:        st.Slot(),
+		pendingBlks: make(map[[32]byte][]interfaces.ReadOnlySignedBeaconBlock),
+		pendingAtts: make(map[[32]byte][]*zondpb.Attestation),
+	}
+	r.clock = &clock{now: r.slotStart(st.Slot())}
+	r.db, err = kv.NewKVStore(ctx, dir)
+	if err != nil {
+		r.Close()
+		return nil, errors.Wrap(err, "could not open database")
+	}
+	if err := r.start(ctx, st, blk); err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Replayer) start(ctx context.Context, st state.BeaconState, blk interfaces.ReadOnlySignedBeaconBlock) error {
+	root, err := blk.Block().HashTreeRoot()
+	if err != nil {
+		return errors.Wrap(err, "could not compute anchor block root")
+	}
+	if err := r.db.SaveBlock(ctx, blk); err != nil {
+		return errors.Wrap(err, "could not save anchor block")
+	}
+	if err := r.db.SaveGenesisBlockRoot(ctx, root); err != nil {
+		return errors.Wrap(err, "could not save anchor block root")
+	}
+	if err := r.db.SaveState(ctx, st, root); err != nil {
+		return errors.Wrap(err, "could not save anchor state")
+	}
+	cp := &zondpb.Checkpoint{
+		Epoch: coreTime.CurrentEpoch(st),
+		Root:  root[:],
+	}
+	if err := r.db.SaveJustifiedCheckpoint(ctx, cp); err != nil {
+		return errors.Wrap(err, "could not save justified checkpoint")
+	}
+	if err := r.db.SaveFinalizedCheckpoint(ctx, cp); err != nil {
+		return errors.Wrap(err, "could not save finalized checkpoint")
+	}
+
+	attService, err := attestations.NewService(ctx, &attestations.Config{
+		Pool: attestations.NewPool(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not create attestation service")
+	}
+	depositCache, err := depositcache.New()
+	if err != nil {
+		return errors.Wrap(err, "could not create deposit cache")
+	}
+	fc := doublylinkedtree.New()
+	fc.SetNower(r.clock.Now)
+	r.service, err = blockchain.NewService(ctx,
+		blockchain.WithExecutionEngineCaller(&mockExecution.EngineClient{}),
+		blockchain.WithFinalizedStateAtStartUp(st),
+		blockchain.WithDatabase(r.db),
+		blockchain.WithAttestationService(attService),
+		blockchain.WithForkChoiceStore(fc),
+		blockchain.WithStateGen(stategen.New(r.db, fc)),
+		blockchain.WithStateNotifier(&stateNotifier{}),
+		blockchain.WithAttestationPool(attestations.NewPool()),
+		blockchain.WithDepositCache(depositCache),
+		blockchain.WithProposerIdsCache(cache.NewProposerPayloadIDsCache()),
+		blockchain.WithClockSynchronizer(startup.NewClockSynchronizer()),
+		blockchain.WithNower(r.clock.Now),
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not create blockchain service")
+	}
+	return errors.Wrap(r.service.StartFromSavedState(st), "could not start blockchain service")
+}
+
+// Close removes the database of the replay.
+func (r *Replayer) Close() {
+	if r.db != nil {
+		if err := r.db.Close(); err != nil {
+			log.WithError(err).Error("Could not close replay database")
+		}
+	}
+	if err := os.RemoveAll(r.dir); err != nil {
+		log.WithError(err).Error("Could not remove replay database")
+	}
+}
+
+// Tick moves the replay clock forward to the given time, starting each slot in between at
+// its start time. The clock never moves backward.
+func (r *Replayer) Tick(ctx context.Context, t time.Time) error {
+	if t.Before(r.clock.Now()) {
+		return nil
+	}
+	for current := slots.Duration(r.genesis, t); r.slot < current; {
+		r.slot++
+		r.clock.set(r.slotStart(r.slot))
+		if err := r.service.NewSlot(ctx, r.slot); err != nil {
+			return errors.Wrapf(err, "could not start slot %d", r.slot)
+		}
+	}
+	r.clock.set(t)
+	return nil
+}
+
+func (r *Replayer) slotStart(slot primitives.Slot) time.Time {
+	return r.genesis.Add(time.Duration(uint64(slot)*params.BeaconConfig().SecondsPerSlot) * time.Second)
+}
+
+// Block feeds a block to the service as if it was received over gossip. A block of an
+// unknown parent is held until its parent is imported. Once a block is imported, the
+// blocks and attestations held for it are fed in turn, and the first error is returned.
+func (r *Replayer) Block(ctx context.Context, blk interfaces.ReadOnlySignedBeaconBlock) error {
+	parent := blk.Block().ParentRoot()
+	if !r.service.HasBlock(ctx, parent) {
+		r.pendingBlks[parent] = append(r.pendingBlks[parent], blk)
+		return nil
+	}
+	root, err := blk.Block().HashTreeRoot()
+	if err != nil {
+		return errors.Wrap(err, "could not compute block root")
+	}
+	if err := r.service.ReceiveBlock(ctx, blk, root); err != nil {
+		return err
+	}
+
+	children, atts := r.pendingBlks[root], r.pendingAtts[root]
+	delete(r.pendingBlks, root)
+	delete(r.pendingAtts, root)
+	var firstErr error
+	for _, child := range children {
+		if err := r.Block(ctx, child); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	for _, att := range atts {
+		if err := r.Attestation(ctx, att); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Attestation feeds an attestation to fork choice. An attestation of an unknown block is
+// held until the block is imported.
+func (r *Replayer) Attestation(ctx context.Context, att *zondpb.Attestation) error {
+	root := bytesutil.ToBytes32(att.Data.BeaconBlockRoot)
+	if !r.service.HasBlock(ctx, root) {
+		r.pendingAtts[root] = append(r.pendingAtts[root], att)
+		return nil
+	}
+	return r.service.OnAttestation(ctx, att, params.BeaconNetworkConfig().MaximumGossipClockDisparity)
+}
+
+// Head updates the head of the service and returns the view of fork choice at the slot.
+func (r *Replayer) Head(ctx context.Context, slot primitives.Slot) (*SlotHead, error) {
+	if err := r.service.UpdateAndSaveHeadWithBalances(ctx); err != nil {
+		return nil, errors.Wrap(err, "could not update head")
+	}
+	root, err := r.service.HeadRoot(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get head root")
+	}
+	return &SlotHead{
+		Slot:           slot,
+		HeadRoot:       fmt.Sprintf("%#x", root),
+		HeadSlot:       r.service.HeadSlot(),
+		JustifiedEpoch: r.service.CurrentJustifiedCheckpt().Epoch,
+		FinalizedEpoch: r.service.FinalizedCheckpt().Epoch,
+	}, nil
+}
+
+// Replay processes the arrivals of the capture in order, each at its arrival time, and
+// reports the head at the end of every slot from the anchor to the last arrival. Errors
+// processing an arrival, such as an invalid block, are reported in the slot of the arrival
+// and do not stop the replay. The chain config in use must be the one
+// the capture was recorded with.
+func Replay(ctx context.Context, c *Capture) (*Report, error) {
+	if c.ConfigName != params.BeaconConfig().ConfigName {
+		return nil, errors.Errorf("capture was recorded with chain config %s, but %s is in use", c.ConfigName, params.BeaconConfig().ConfigName)
+	}
+	r, err := NewReplayer(ctx, c.AnchorState, c.AnchorBlock)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	secondsPerSlot := time.Duration(params.BeaconConfig().SecondsPerSlot) * time.Second
+	lastSlot := c.AnchorState.Slot()
+	if len(c.Arrivals) > 0 {
+		last := c.Arrivals[len(c.Arrivals)-1].Time
+		if last.After(r.genesis) {
+			if s := primitives.Slot(last.Sub(r.genesis) / secondsPerSlot); s > lastSlot {
+				lastSlot = s
+			}
+		}
+	}
+
+	report := &Report{}
+	i := 0
+	for slot := c.AnchorState.Slot(); slot <= lastSlot; slot++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		slotStart := r.slotStart(slot)
+		if err := r.Tick(ctx, slotStart); err != nil {
+			return nil, err
+		}
+		var errs []string
+		blocks, atts := 0, 0
+		for ; i < len(c.Arrivals) && c.Arrivals[i].Time.Before(slotStart.Add(secondsPerSlot)); i++ {
+			a := c.Arrivals[i]
+			if err := r.Tick(ctx, a.Time); err != nil {
+				return nil, err
+			}
+			var err error
+			if a.Block != nil {
+				blocks++
+				err = r.Block(ctx, a.Block)
+			} else {
+				atts++
+				err = r.Attestation(ctx, a.Attestation)
+			}
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		head, err := r.Head(ctx, slot)
+		if err != nil {
+			return nil, err
+		}
+		head.Blocks, head.Attestations, head.Errors = blocks, atts, errs
+		report.Slots = append(report.Slots, head)
+	}
+	return report, nil
+}
@@ -0,0 +1,143 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	coreBlocks "github.com/theQRL/qrysm/v4/beacon-chain/core/blocks"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/transition"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/blocks"
+	"github.com/theQRL/qrysm/v4/consensus-types/interfaces"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/spectest/shared/common/forkchoice"
+	"github.com/theQRL/qrysm/v4/testing/util"
+)
+
+// reorgCapture is a capture of two forks from genesis: a1 at slot 1 and a2 at slot 2, then
+// b3 at slot 3, which takes the head with the attestations of slot 3.
+type reorgCapture struct {
+	capture        []byte
+	a1, a2, b3     interfaces.ReadOnlySignedBeaconBlock
+	a2Root, b3Root [32]byte
+	atts           []*zondpb.Attestation
+	slotStart      func(primitives.Slot) time.Time
+}
+
+func newReorgCapture(t *testing.T) *reorgCapture {
+	ctx := context.Background()
+	genesisState, keys := util.DeterministicGenesisState(t, 64)
+	stateRoot, err := genesisState.HashTreeRoot(ctx)
+	require.NoError(t, err)
+	genesis, err := blocks.NewSignedBeaconBlock(coreBlocks.NewGenesisBlock(stateRoot[:]))
+	require.NoError(t, err)
+
+	// Blocks carry no attestations, so that only the attestations of the capture count in
+	// fork choice.
+	conf := util.DefaultBlockGenConfig()
+	conf.NumAttestations = 0
+	b, err := util.GenerateFullBlock(genesisState, keys, conf, 1)
+	require.NoError(t, err)
+	a1, err := blocks.NewSignedBeaconBlock(b)
+	require.NoError(t, err)
+	a1State, err := transition.ExecuteStateTransition(ctx, genesisState.Copy(), a1)
+	require.NoError(t, err)
+	b, err = util.GenerateFullBlock(a1State, keys, conf, 2)
+	require.NoError(t, err)
+	a2, err := blocks.NewSignedBeaconBlock(b)
+	require.NoError(t, err)
+
+	b, err = util.GenerateFullBlock(genesisState, keys, conf, 3)
+	require.NoError(t, err)
+	b3, err := blocks.NewSignedBeaconBlock(b)
+	require.NoError(t, err)
+	b3State, err := transition.ExecuteStateTransition(ctx, genesisState.Copy(), b3)
+	require.NoError(t, err)
+	atts, err := util.GenerateAttestations(b3State, keys, 1, 3, false)
+	require.NoError(t, err)
+
+	rc := &reorgCapture{a1: a1, a2: a2, b3: b3, atts: atts}
+	rc.a2Root, err = a2.Block().HashTreeRoot()
+	require.NoError(t, err)
+	rc.b3Root, err = b3.Block().HashTreeRoot()
+	require.NoError(t, err)
+	require.DeepEqual(t, rc.b3Root[:], atts[0].Data.BeaconBlockRoot)
+
+	secondsPerSlot := time.Duration(params.BeaconConfig().SecondsPerSlot) * time.Second
+	genesisTime := time.Unix(int64(genesisState.GenesisTime()), 0)
+	rc.slotStart = func(slot primitives.Slot) time.Time {
+		return genesisTime.Add(time.Duration(slot) * secondsPerSlot)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteAnchor(genesisState, genesis))
+	// a1 arrives after its child a2, which waits for it as a pending block.
+	require.NoError(t, w.WriteBlock(rc.slotStart(2).Add(time.Second), a2))
+	require.NoError(t, w.WriteBlock(rc.slotStart(2).Add(2*time.Second), a1))
+	require.NoError(t, w.WriteBlock(rc.slotStart(3).Add(time.Second), b3))
+	for _, att := range atts {
+		require.NoError(t, w.WriteAttestation(rc.slotStart(4).Add(time.Second), att))
+	}
+	require.NoError(t, w.Close())
+	rc.capture = buf.Bytes()
+	return rc
+}
+
+func TestReplay_Reorg(t *testing.T) {
+	ctx := context.Background()
+	rc := newReorgCapture(t)
+
+	replay := func() *Report {
+		c, err := Read(rc.capture)
+		require.NoError(t, err)
+		report, err := Replay(ctx, c)
+		require.NoError(t, err)
+		return report
+	}
+	report := replay()
+	// A second replay of the same capture, later in wall clock time, sees the same heads.
+	time.Sleep(time.Second)
+	require.DeepEqual(t, report, replay())
+
+	require.Equal(t, 5, len(report.Slots))
+	for _, s := range report.Slots {
+		require.Equal(t, 0, len(s.Errors), "slot %d: %v", s.Slot, s.Errors)
+		require.Equal(t, primitives.Epoch(0), s.JustifiedEpoch)
+		require.Equal(t, primitives.Epoch(0), s.FinalizedEpoch)
+	}
+	require.Equal(t, 2, report.Slots[2].Blocks)
+	require.Equal(t, fmt.Sprintf("%#x", rc.a2Root), report.Slots[2].HeadRoot)
+	require.Equal(t, primitives.Slot(2), report.Slots[2].HeadSlot)
+	// b3 is boosted as a timely block, then kept as the head by the attestations of slot 3.
+	require.Equal(t, fmt.Sprintf("%#x", rc.b3Root), report.Slots[3].HeadRoot)
+	require.Equal(t, len(rc.atts), report.Slots[4].Attestations)
+	require.Equal(t, fmt.Sprintf("%#x", rc.b3Root), report.Slots[4].HeadRoot)
+	require.Equal(t, primitives.Slot(3), report.Slots[4].HeadSlot)
+
+	// The fork choice runner of the spec tests agrees on the heads.
+	c, err := Read(rc.capture)
+	require.NoError(t, err)
+	builder := forkchoice.NewBuilder(t, c.AnchorState, c.AnchorBlock)
+	tick := func(tm time.Time) {
+		builder.Tick(t, tm.Unix()-rc.slotStart(0).Unix())
+	}
+	tick(rc.slotStart(2).Add(2 * time.Second))
+	builder.ValidBlock(t, rc.a1)
+	builder.ValidBlock(t, rc.a2)
+	builder.Check(t, &forkchoice.Check{Head: &forkchoice.SlotRoot{Slot: 2, Root: report.Slots[2].HeadRoot}})
+	tick(rc.slotStart(3).Add(time.Second))
+	builder.ValidBlock(t, rc.b3)
+	builder.Check(t, &forkchoice.Check{Head: &forkchoice.SlotRoot{Slot: 3, Root: report.Slots[3].HeadRoot}})
+	tick(rc.slotStart(4).Add(time.Second))
+	for _, att := range rc.atts {
+		builder.Attestation(t, att)
+	}
+	builder.Check(t, &forkchoice.Check{Head: &forkchoice.SlotRoot{Slot: 3, Root: report.Slots[4].HeadRoot}})
+}
@@ -0,0 +1,318 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/helpers"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/crypto/hash"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	"github.com/theQRL/qrysm/v4/time/slots"
+)
+
+// alertQueueSize is the number of alerts waiting to be sent before new alerts are dropped.
+const alertQueueSize = 256
+
+// AlertKind identifies the rule which raised an alert.
+type AlertKind string
+
+const (
+	// MissedAttestationsAlert is raised when a tracked validator misses consecutive attestations.
+	MissedAttestationsAlert AlertKind = "missed_attestations"
+	// MissedProposalAlert is raised when no block of a tracked validator is observed for its proposal slot.
+	MissedProposalAlert AlertKind = "missed_proposal"
+	// BalanceDropAlert is raised when the balance of a tracked validator drops over an epoch.
+	BalanceDropAlert AlertKind = "balance_drop"
+	// SlashingAlert is raised when a slashing of a tracked validator is included in a block.
+	SlashingAlert AlertKind = "slashing"
+)
+
+// Alert is sent to the notifiers when a tracked validator misses its duties or is slashed.
+type Alert struct {
+	Kind           AlertKind                 `json:"kind"`
+	ValidatorIndex primitives.ValidatorIndex `json:"validator_index"`
+	Epoch          primitives.Epoch          `json:"epoch"`
+	Slot           primitives.Slot           `json:"slot"`
+	Message        string                    `json:"message"`
+	Time           time.Time                 `json:"time"`
+}
+
+// AlertConfig defines the alert rules of the validator monitor, and the notifiers alerts are sent to.
+type AlertConfig struct {
+	// MissedAttestations is the number of consecutive missed attestations raising an alert, 0 disables the rule.
+	MissedAttestations uint64
+	// MissedProposals raises an alert when a proposal of a tracked validator is missed.
+	MissedProposals bool
+	// BalanceDrop is the balance decrease in Gwei over an epoch raising an alert, 0 disables the rule.
+	BalanceDrop uint64
+	// Slashing raises an alert when a slashing of a tracked validator is included in a block.
+	Slashing bool
+	// Cooldown is the minimum duration between two alerts of the same kind for the same validator.
+	Cooldown  time.Duration
+	Notifiers []Notifier
+}
+
+type alertKey struct {
+	kind AlertKind
+	idx  primitives.ValidatorIndex
+}
+
+type sentAlert struct {
+	time time.Time
+	slot primitives.Slot
+}
+
+// alerter deduplicates the alerts raised by the monitor and sends them to the notifiers.
+type alerter struct {
+	cfg   *AlertConfig
+	queue chan *Alert
+
+	sync.Mutex
+	sent map[alertKey]sentAlert
+}
+
+func newAlerter(cfg *AlertConfig) *alerter {
+	return &alerter{
+		cfg:   cfg,
+		queue: make(chan *Alert, alertQueueSize),
+		sent:  make(map[alertKey]sentAlert),
+	}
+}
+
+// raise queues the alert for the notifiers. An alert is dropped when an alert of the same kind was
+// already raised for the validator at the same or a later slot, or less than the cooldown ago.
+func (a *alerter) raise(alert *Alert) bool {
+	a.Lock()
+	key := alertKey{kind: alert.Kind, idx: alert.ValidatorIndex}
+	if last, ok := a.sent[key]; ok && (alert.Slot <= last.slot || time.Since(last.time) < a.cfg.Cooldown) {
+		a.Unlock()
+		return false
+	}
+	alert.Time = time.Now()
+	a.sent[key] = sentAlert{time: alert.Time, slot: alert.Slot}
+	a.Unlock()
+
+	log.WithFields(logrus.Fields{
+		"Kind":           alert.Kind,
+		"ValidatorIndex": alert.ValidatorIndex,
+		"Epoch":          alert.Epoch,
+		"Slot":           alert.Slot,
+	}).Warn(alert.Message)
+	select {
+	case a.queue <- alert:
+		return true
+	default:
+		log.WithField("Kind", alert.Kind).Error("Alert queue is full, dropping alert")
+		return false
+	}
+}
+
+// run sends the queued alerts to every notifier until the context is canceled.
+func (a *alerter) run(ctx context.Context) {
+	for {
+		select {
+		case alert := <-a.queue:
+			for _, n := range a.cfg.Notifiers {
+				if err := n.Notify(ctx, alert); err != nil {
+					log.WithError(err).WithField("Kind", alert.Kind).Error("Could not send alert")
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// processAlerts evaluates the alert rules of the missed proposals at each block, and of the
+// missed attestations and balance drops at the first block of each epoch. The missed attestations
+// of every epoch since the last block are counted, so that epochs without blocks are not skipped.
+func (s *Service) processAlerts(ctx context.Context, st state.BeaconState, slot primitives.Slot) {
+	if s.alerter == nil {
+		return
+	}
+	epoch := slots.ToEpoch(slot)
+	s.Lock()
+	defer s.Unlock()
+
+	if epoch > s.alertEpoch {
+		for e := s.alertEpoch + 1; e <= epoch; e++ {
+			s.processMissedAttestations(st, e)
+		}
+		s.alertEpoch = epoch
+		s.processBalanceDrops(st, epoch)
+		if s.alerter.cfg.MissedProposals {
+			if err := s.updateExpectedProposals(ctx, st); err != nil {
+				log.WithError(err).Error("Could not compute proposers, missed proposals will not be alerted")
+			}
+		}
+	}
+	s.processMissedProposals(slot)
+}
+
+// processMissedProposals raises an alert for each expected proposal of a tracked validator before
+// the given block slot, for which no block was observed.
+// It assumes the caller holds the service Lock
+func (s *Service) processMissedProposals(slot primitives.Slot) {
+	for proposalSlot, idx := range s.expectedProposals {
+		if proposalSlot > slot {
+			continue
+		}
+		delete(s.expectedProposals, proposalSlot)
+		if proposalSlot == slot {
+			continue
+		}
+		s.alerter.raise(&Alert{
+			Kind:           MissedProposalAlert,
+			ValidatorIndex: idx,
+			Epoch:          slots.ToEpoch(proposalSlot),
+			Slot:           proposalSlot,
+			Message:        fmt.Sprintf("Validator %d missed its proposal at slot %d", idx, proposalSlot),
+		})
+	}
+}
+
+// processMissedAttestations counts the consecutive epochs in which the attestations of the tracked
+// validators were not included. At the first block of an epoch the inclusion window of the attestations
+// of two epochs before is over.
+// It assumes the caller holds the service Lock
+func (s *Service) processMissedAttestations(st state.BeaconState, epoch primitives.Epoch) {
+	threshold := s.alerter.cfg.MissedAttestations
+	if threshold == 0 || epoch < 2 {
+		return
+	}
+	missed := epoch - 2
+	start, err := slots.EpochStart(missed)
+	if err != nil {
+		log.WithError(err).Error("Could not compute epoch start slot")
+		return
+	}
+	for idx := range s.TrackedValidators {
+		l, ok := s.latestPerformance[idx]
+		if !ok || s.aggregatedPerformance[idx].startEpoch > missed {
+			continue
+		}
+		val, err := st.ValidatorAtIndexReadOnly(idx)
+		if err != nil || !helpers.IsActiveValidatorUsingTrie(val, missed) {
+			delete(s.missedAttestations, idx)
+			continue
+		}
+		if l.inclusionSlot != 0 && slots.ToEpoch(l.attestedSlot) >= missed {
+			delete(s.missedAttestations, idx)
+			continue
+		}
+		s.missedAttestations[idx]++
+		count := s.missedAttestations[idx]
+		if count < threshold {
+			continue
+		}
+		s.alerter.raise(&Alert{
+			Kind:           MissedAttestationsAlert,
+			ValidatorIndex: idx,
+			Epoch:          missed,
+			Slot:           start,
+			Message:        fmt.Sprintf("Validator %d missed %d consecutive attestations", idx, count),
+		})
+	}
+}
+
+// processBalanceDrops raises an alert for each tracked validator whose balance decreased by more than
+// the configured drop since the first block of the previous epoch.
+// It assumes the caller holds the service Lock
+func (s *Service) processBalanceDrops(st state.BeaconState, epoch primitives.Epoch) {
+	drop := s.alerter.cfg.BalanceDrop
+	if drop == 0 {
+		return
+	}
+	start, err := slots.EpochStart(epoch)
+	if err != nil {
+		log.WithError(err).Error("Could not compute epoch start slot")
+		return
+	}
+	for idx := range s.TrackedValidators {
+		balance, err := st.BalanceAtIndex(idx)
+		if err != nil {
+			delete(s.epochStartBalances, idx)
+			continue
+		}
+		prev, ok := s.epochStartBalances[idx]
+		s.epochStartBalances[idx] = balance
+		if !ok || prev < balance || prev-balance < drop {
+			continue
+		}
+		s.alerter.raise(&Alert{
+			Kind:           BalanceDropAlert,
+			ValidatorIndex: idx,
+			Epoch:          epoch,
+			Slot:           start,
+			Message:        fmt.Sprintf("Balance of validator %d dropped by %d Gwei to %d Gwei", idx, prev-balance, balance),
+		})
+	}
+}
+
+// updateExpectedProposals records the proposal slots of the tracked validators in the whole current epoch of
+// the state, including the slots before the state slot which had no block. The proposers of an epoch only depend
+// on the state at its start, so they can be computed from any later state of the epoch.
+// It assumes the caller holds the service Lock
+func (s *Service) updateExpectedProposals(ctx context.Context, st state.BeaconState) error {
+	proposers, err := proposerIndices(ctx, st)
+	if err != nil {
+		return err
+	}
+	start, err := slots.EpochStart(slots.ToEpoch(st.Slot()))
+	if err != nil {
+		return err
+	}
+	for i, idx := range proposers {
+		if s.trackedIndex(idx) {
+			s.expectedProposals[start+primitives.Slot(i)] = idx
+		}
+	}
+	return nil
+}
+
+// raiseSlashingAlert raises an alert for the slashing of a tracked validator included in the block at the given slot.
+func (s *Service) raiseSlashingAlert(idx primitives.ValidatorIndex, slot primitives.Slot, kind string) {
+	if s.alerter == nil || !s.alerter.cfg.Slashing {
+		return
+	}
+	s.alerter.raise(&Alert{
+		Kind:           SlashingAlert,
+		ValidatorIndex: idx,
+		Epoch:          slots.ToEpoch(slot),
+		Slot:           slot,
+		Message:        fmt.Sprintf("%s slashing of validator %d was included at slot %d", kind, idx, slot),
+	})
+}
+
+// proposerIndices returns the proposer index of each slot in the current epoch of the state.
+func proposerIndices(ctx context.Context, st state.ReadOnlyBeaconState) ([]primitives.ValidatorIndex, error) {
+	epoch := slots.ToEpoch(st.Slot())
+	seed, err := helpers.Seed(st, epoch, params.BeaconConfig().DomainBeaconProposer)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not generate seed")
+	}
+	active, err := helpers.ActiveValidatorIndices(ctx, st, epoch)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get active indices")
+	}
+	start, err := slots.EpochStart(epoch)
+	if err != nil {
+		return nil, err
+	}
+	proposers := make([]primitives.ValidatorIndex, params.BeaconConfig().SlotsPerEpoch)
+	for i := range proposers {
+		seedWithSlot := append(seed[:], bytesutil.Bytes8(uint64(start)+uint64(i))...)
+		proposers[i], err = helpers.ComputeProposerIndex(st, active, hash.Hash(seedWithSlot))
+		if err != nil {
+			return nil, errors.Wrap(err, "could not compute proposer index")
+		}
+	}
+	return proposers, nil
+}
@@ -0,0 +1,213 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/helpers"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/blocks"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/util"
+)
+
+func queuedAlerts(a *alerter) []*Alert {
+	var alerts []*Alert
+	for {
+		select {
+		case alert := <-a.queue:
+			alerts = append(alerts, alert)
+		default:
+			return alerts
+		}
+	}
+}
+
+func TestAlerter_Raise(t *testing.T) {
+	a := newAlerter(&AlertConfig{Cooldown: time.Hour})
+	require.Equal(t, true, a.raise(&Alert{Kind: MissedProposalAlert, ValidatorIndex: 1, Slot: 10}))
+	// The same event is deduplicated, and later events are within the cooldown.
+	require.Equal(t, false, a.raise(&Alert{Kind: MissedProposalAlert, ValidatorIndex: 1, Slot: 10}))
+	require.Equal(t, false, a.raise(&Alert{Kind: MissedProposalAlert, ValidatorIndex: 1, Slot: 20}))
+	// The cooldown applies per kind and validator.
+	require.Equal(t, true, a.raise(&Alert{Kind: MissedProposalAlert, ValidatorIndex: 2, Slot: 10}))
+	require.Equal(t, true, a.raise(&Alert{Kind: SlashingAlert, ValidatorIndex: 1, Slot: 10}))
+	require.Equal(t, 3, len(queuedAlerts(a)))
+
+	a = newAlerter(&AlertConfig{})
+	require.Equal(t, true, a.raise(&Alert{Kind: BalanceDropAlert, ValidatorIndex: 1, Slot: 32}))
+	require.Equal(t, false, a.raise(&Alert{Kind: BalanceDropAlert, ValidatorIndex: 1, Slot: 32}))
+	require.Equal(t, true, a.raise(&Alert{Kind: BalanceDropAlert, ValidatorIndex: 1, Slot: 64}))
+	alerts := queuedAlerts(a)
+	require.Equal(t, 2, len(alerts))
+	assert.Equal(t, false, alerts[0].Time.IsZero())
+}
+
+func TestProcessAlerts_MissedAttestations(t *testing.T) {
+	ctx := context.Background()
+	s := setupService(t)
+	s.alerter = newAlerter(&AlertConfig{MissedAttestations: 2})
+	st, _ := util.DeterministicGenesisStateAltair(t, 256)
+	slotsPerEpoch := params.BeaconConfig().SlotsPerEpoch
+	s.alertEpoch = 2
+
+	// Validator 2 has an attestation of epoch 1 included.
+	s.latestPerformance[2] = ValidatorLatestPerformance{
+		attestedSlot:  slotsPerEpoch,
+		inclusionSlot: slotsPerEpoch + 1,
+	}
+	s.processAlerts(ctx, st, 3*slotsPerEpoch)
+	require.Equal(t, 0, len(queuedAlerts(s.alerter)))
+	require.Equal(t, uint64(1), s.missedAttestations[1])
+	_, ok := s.missedAttestations[2]
+	require.Equal(t, false, ok)
+
+	// A later block of the same epoch does not evaluate the rule again.
+	s.processAlerts(ctx, st, 3*slotsPerEpoch+1)
+	require.Equal(t, uint64(1), s.missedAttestations[1])
+
+	s.processAlerts(ctx, st, 4*slotsPerEpoch)
+	alerts := queuedAlerts(s.alerter)
+	require.Equal(t, 3, len(alerts))
+	for _, alert := range alerts {
+		assert.Equal(t, MissedAttestationsAlert, alert.Kind)
+		assert.Equal(t, primitives.Epoch(2), alert.Epoch)
+		assert.Equal(t, 2*slotsPerEpoch, alert.Slot)
+		assert.NotEqual(t, primitives.ValidatorIndex(2), alert.ValidatorIndex)
+	}
+	require.Equal(t, uint64(1), s.missedAttestations[2])
+}
+
+func TestProcessAlerts_MissedAttestationsInSkippedEpochs(t *testing.T) {
+	ctx := context.Background()
+	s := setupService(t)
+	s.alerter = newAlerter(&AlertConfig{MissedAttestations: 3})
+	st, _ := util.DeterministicGenesisStateAltair(t, 256)
+	slotsPerEpoch := params.BeaconConfig().SlotsPerEpoch
+	s.alertEpoch = 2
+
+	// The inclusion windows of epochs 1, 2 and 3 are over, none of them had a block.
+	s.processAlerts(ctx, st, 5*slotsPerEpoch)
+	for idx := range s.TrackedValidators {
+		require.Equal(t, uint64(3), s.missedAttestations[idx])
+	}
+	alerts := queuedAlerts(s.alerter)
+	require.Equal(t, len(s.TrackedValidators), len(alerts))
+	for _, alert := range alerts {
+		assert.Equal(t, MissedAttestationsAlert, alert.Kind)
+		assert.Equal(t, primitives.Epoch(3), alert.Epoch)
+	}
+}
+
+func TestProcessAlerts_BalanceDrop(t *testing.T) {
+	ctx := context.Background()
+	s := setupService(t)
+	s.alerter = newAlerter(&AlertConfig{BalanceDrop: 1000000000})
+	st, _ := util.DeterministicGenesisStateAltair(t, 256)
+	slotsPerEpoch := params.BeaconConfig().SlotsPerEpoch
+
+	s.processAlerts(ctx, st, slotsPerEpoch)
+	require.Equal(t, 0, len(queuedAlerts(s.alerter)))
+
+	balance, err := st.BalanceAtIndex(1)
+	require.NoError(t, err)
+	require.NoError(t, st.UpdateBalancesAtIndex(1, balance-2000000000))
+	require.NoError(t, st.UpdateBalancesAtIndex(2, balance-100))
+	s.processAlerts(ctx, st, 2*slotsPerEpoch)
+	alerts := queuedAlerts(s.alerter)
+	require.Equal(t, 1, len(alerts))
+	assert.Equal(t, BalanceDropAlert, alerts[0].Kind)
+	assert.Equal(t, primitives.ValidatorIndex(1), alerts[0].ValidatorIndex)
+	assert.Equal(t, primitives.Epoch(2), alerts[0].Epoch)
+}
+
+func TestProcessMissedProposals(t *testing.T) {
+	s := setupService(t)
+	s.alerter = newAlerter(&AlertConfig{MissedProposals: true})
+	s.expectedProposals = map[primitives.Slot]primitives.ValidatorIndex{5: 1, 6: 2, 9: 12}
+
+	s.processMissedProposals(6)
+	alerts := queuedAlerts(s.alerter)
+	require.Equal(t, 1, len(alerts))
+	assert.Equal(t, MissedProposalAlert, alerts[0].Kind)
+	assert.Equal(t, primitives.ValidatorIndex(1), alerts[0].ValidatorIndex)
+	assert.Equal(t, primitives.Slot(5), alerts[0].Slot)
+	assert.DeepEqual(t, map[primitives.Slot]primitives.ValidatorIndex{9: 12}, s.expectedProposals)
+}
+
+func TestProcessAlerts_MissedProposalsBeforeFirstBlockOfEpoch(t *testing.T) {
+	ctx := context.Background()
+	s := setupService(t)
+	s.alerter = newAlerter(&AlertConfig{MissedProposals: true})
+	st, _ := util.DeterministicGenesisStateAltair(t, 256)
+	slotsPerEpoch := params.BeaconConfig().SlotsPerEpoch
+	// The first block of epoch 1 is at its fourth slot.
+	slot := slotsPerEpoch + 3
+	require.NoError(t, st.SetSlot(slot))
+	proposers, err := proposerIndices(ctx, st)
+	require.NoError(t, err)
+	s.TrackedValidators = make(map[primitives.ValidatorIndex]bool)
+	for _, idx := range proposers {
+		s.TrackedValidators[idx] = true
+	}
+
+	s.processAlerts(ctx, st, slot)
+	missed := make(map[primitives.ValidatorIndex]bool)
+	for _, alert := range queuedAlerts(s.alerter) {
+		assert.Equal(t, MissedProposalAlert, alert.Kind)
+		require.Equal(t, true, alert.Slot >= slotsPerEpoch && alert.Slot < slot)
+		assert.Equal(t, proposers[alert.Slot-slotsPerEpoch], alert.ValidatorIndex)
+		missed[alert.ValidatorIndex] = true
+	}
+	for _, idx := range proposers[:3] {
+		assert.Equal(t, true, missed[idx])
+	}
+	assert.Equal(t, int(slotsPerEpoch)-4, len(s.expectedProposals))
+}
+
+func TestProposerIndices(t *testing.T) {
+	ctx := context.Background()
+	st, _ := util.DeterministicGenesisStateAltair(t, 256)
+	proposers, err := proposerIndices(ctx, st)
+	require.NoError(t, err)
+	require.Equal(t, int(params.BeaconConfig().SlotsPerEpoch), len(proposers))
+	for i, want := range proposers {
+		require.NoError(t, st.SetSlot(primitives.Slot(i)))
+		got, err := helpers.BeaconProposerIndex(ctx, st)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+}
+
+func TestProcessSlashings_Alert(t *testing.T) {
+	s := &Service{
+		TrackedValidators: map[primitives.ValidatorIndex]bool{2: true},
+		alerter:           newAlerter(&AlertConfig{Slashing: true}),
+	}
+	wb, err := blocks.NewBeaconBlock(&zondpb.BeaconBlock{
+		Slot: 40,
+		Body: &zondpb.BeaconBlockBody{
+			ProposerSlashings: []*zondpb.ProposerSlashing{
+				{
+					Header_1: &zondpb.SignedBeaconBlockHeader{
+						Header: &zondpb.BeaconBlockHeader{ProposerIndex: 2, Slot: 1},
+					},
+					Header_2: &zondpb.SignedBeaconBlockHeader{
+						Header: &zondpb.BeaconBlockHeader{ProposerIndex: 2, Slot: 1},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	s.processSlashings(wb)
+	alerts := queuedAlerts(s.alerter)
+	require.Equal(t, 1, len(alerts))
+	assert.Equal(t, SlashingAlert, alerts[0].Kind)
+	assert.Equal(t, primitives.ValidatorIndex(2), alerts[0].ValidatorIndex)
+	assert.Equal(t, primitives.Slot(40), alerts[0].Slot)
+}
@@ -0,0 +1,98 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/theQRL/qrysm/v4/config/params"
+)
+
+// webhookTimeout is the maximum duration of a webhook request.
+const webhookTimeout = 10 * time.Second
+
+// Notifier sends the alerts raised by the validator monitor to an alert sink.
+type Notifier interface {
+	Notify(ctx context.Context, alert *Alert) error
+}
+
+// WebhookNotifier posts each alert as a JSON object to an HTTP endpoint.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier returns a notifier posting alerts to the given URL.
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// Notify posts the alert to the webhook, and fails if the endpoint does not reply with a 2xx status.
+func (n *WebhookNotifier) Notify(ctx context.Context, alert *Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal alert")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "could not create webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "could not send webhook request")
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.WithError(err).Debug("Could not close webhook response body")
+		}
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("webhook replied with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// JSONNotifier writes each alert as a line of JSON, for example to the standard output or a file.
+type JSONNotifier struct {
+	sync.Mutex
+	w io.Writer
+}
+
+// NewJSONNotifier returns a notifier writing alerts as JSON lines to the given writer.
+func NewJSONNotifier(w io.Writer) *JSONNotifier {
+	return &JSONNotifier{w: w}
+}
+
+// NewFileNotifier returns a notifier appending alerts as JSON lines to the file at the given path.
+// The file is created if it does not exist.
+func NewFileNotifier(path string) (*JSONNotifier, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, params.BeaconIoConfig().ReadWritePermissions) // #nosec G304
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open alert file %s", path)
+	}
+	return NewJSONNotifier(f), nil
+}
+
+// Notify writes the alert as a line of JSON.
+func (n *JSONNotifier) Notify(_ context.Context, alert *Alert) error {
+	line, err := json.Marshal(alert)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal alert")
+	}
+	n.Lock()
+	defer n.Unlock()
+	if _, err := n.w.Write(append(line, '\n')); err != nil {
+		return errors.Wrap(err, "could not write alert")
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	received := make(chan *Alert, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		alert := &Alert{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(alert))
+		received <- alert
+	}))
+	defer srv.Close()
+
+	n := NewWebhookNotifier(srv.URL)
+	require.NoError(t, n.Notify(context.Background(), &Alert{Kind: MissedProposalAlert, ValidatorIndex: 3, Slot: 7}))
+	alert := <-received
+	assert.Equal(t, MissedProposalAlert, alert.Kind)
+	assert.Equal(t, 3, int(alert.ValidatorIndex))
+	assert.Equal(t, 7, int(alert.Slot))
+}
+
+func TestWebhookNotifier_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	err := NewWebhookNotifier(srv.URL).Notify(context.Background(), &Alert{Kind: SlashingAlert})
+	require.ErrorContains(t, "webhook replied with status 500", err)
+}
+
+func TestJSONNotifier(t *testing.T) {
+	buf := &bytes.Buffer{}
+	n := NewJSONNotifier(buf)
+	require.NoError(t, n.Notify(context.Background(), &Alert{Kind: SlashingAlert, ValidatorIndex: 1}))
+	require.NoError(t, n.Notify(context.Background(), &Alert{Kind: BalanceDropAlert, ValidatorIndex: 2}))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Equal(t, 2, len(lines))
+	alert := &Alert{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), alert))
+	assert.Equal(t, BalanceDropAlert, alert.Kind)
+	assert.Equal(t, 2, int(alert.ValidatorIndex))
+}
+
+func TestFileNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.json")
+	n, err := NewFileNotifier(path)
+	require.NoError(t, err)
+	require.NoError(t, n.Notify(context.Background(), &Alert{Kind: SlashingAlert, ValidatorIndex: 1}))
+
+	// Alerts are appended to an existing file.
+	n, err = NewFileNotifier(path)
+	require.NoError(t, err)
+	require.NoError(t, n.Notify(context.Background(), &Alert{Kind: MissedAttestationsAlert, ValidatorIndex: 2}))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Equal(t, 2, len(lines))
+	alert := &Alert{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), alert))
+	assert.Equal(t, SlashingAlert, alert.Kind)
+}
+
+func TestAlerter_Run(t *testing.T) {
+	received := make(chan *Alert, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		alert := &Alert{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(alert))
+		received <- alert
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	buf := &bytes.Buffer{}
+	a := newAlerter(&AlertConfig{Notifiers: []Notifier{NewWebhookNotifier(srv.URL), NewJSONNotifier(buf)}})
+	go a.run(ctx)
+	require.Equal(t, true, a.raise(&Alert{Kind: MissedProposalAlert, ValidatorIndex: 4, Slot: 9}))
+	alert := <-received
+	assert.Equal(t, 4, int(alert.ValidatorIndex))
+}
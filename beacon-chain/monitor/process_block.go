@@ -44,6 +44,8 @@ func (s *Service) processBlock(ctx context.Context, b interfaces.ReadOnlySignedB
 		return
 	}
 
+	s.resolveTrackedPubkeys(ctx, st)
+
 	currEpoch := slots.ToEpoch(blk.Slot())
 	s.RLock()
 	lastSyncedEpoch := s.lastSyncedEpoch
@@ -57,6 +59,7 @@ func (s *Service) processBlock(ctx context.Context, b interfaces.ReadOnlySignedB
 	s.processSyncAggregate(st, blk)
 	s.processProposedBlock(st, root, blk)
 	s.processAttestations(ctx, st, blk)
+	s.processAlerts(ctx, st, blk.Slot())
 
 	if blk.Slot()%(AggregateReportingPeriod*params.BeaconConfig().SlotsPerEpoch) == 0 {
 		s.logAggregatedPerformance()
@@ -115,6 +118,7 @@ func (s *Service) processSlashings(blk interfaces.ReadOnlyBeaconBlock) {
 				"BodyRoot1":     fmt.Sprintf("%#x", bytesutil.Trunc(slashing.Header_1.Header.BodyRoot)),
 				"BodyRoot2":     fmt.Sprintf("%#x", bytesutil.Trunc(slashing.Header_2.Header.BodyRoot)),
 			}).Info("Proposer slashing was included")
+			s.raiseSlashingAlert(idx, blk.Slot(), "Proposer")
 		}
 	}
 
@@ -133,6 +137,7 @@ func (s *Service) processSlashings(blk interfaces.ReadOnlyBeaconBlock) {
 					"SourceEpoch2":       slashing.Attestation_2.Data.Source.Epoch,
 					"TargetEpoch2":       slashing.Attestation_2.Data.Target.Epoch,
 				}).Info("Attester slashing was included")
+				s.raiseSlashingAlert(primitives.ValidatorIndex(idx), blk.Slot(), "Attester")
 			}
 		}
 	}
@@ -2,20 +2,24 @@ package monitor
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"sort"
 	"sync"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
 	"github.com/theQRL/qrysm/v4/async/event"
 	"github.com/theQRL/qrysm/v4/beacon-chain/blockchain"
 	"github.com/theQRL/qrysm/v4/beacon-chain/core/feed"
 	"github.com/theQRL/qrysm/v4/beacon-chain/core/feed/operation"
 	statefeed "github.com/theQRL/qrysm/v4/beacon-chain/core/feed/state"
 	"github.com/theQRL/qrysm/v4/beacon-chain/core/helpers"
+	"github.com/theQRL/qrysm/v4/beacon-chain/db"
 	"github.com/theQRL/qrysm/v4/beacon-chain/state"
 	"github.com/theQRL/qrysm/v4/beacon-chain/state/stategen"
 	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
 	"github.com/theQRL/qrysm/v4/time/slots"
 )
 
@@ -59,6 +63,10 @@ type ValidatorMonitorConfig struct {
 	HeadFetcher         blockchain.HeadFetcher
 	StateGen            stategen.StateManager
 	InitialSyncComplete chan struct{}
+	// BeaconDB persists the tracked validators across restarts, when set.
+	BeaconDB db.NoHeadAccessDatabase
+	// Alerts defines the alert rules and notifiers, alerts are disabled when nil.
+	Alerts *AlertConfig
 }
 
 // Service is the main structure that tracks validators and reports logs and
@@ -69,18 +77,29 @@ type Service struct {
 	cancel    context.CancelFunc
 	isLogging bool
 
-	// Locks access to TrackedValidators, latestPerformance, aggregatedPerformance,
-	// trackedSyncedCommitteeIndices and lastSyncedEpoch
+	alerter *alerter
+
+	// Locks access to TrackedValidators, trackedPubkeys, latestPerformance, aggregatedPerformance,
+	// trackedSyncedCommitteeIndices, lastSyncedEpoch and the alert state
 	sync.RWMutex
 
 	TrackedValidators           map[primitives.ValidatorIndex]bool
+	trackedPubkeys              map[[dilithium2.CryptoPublicKeyBytes]byte]bool
 	latestPerformance           map[primitives.ValidatorIndex]ValidatorLatestPerformance
 	aggregatedPerformance       map[primitives.ValidatorIndex]ValidatorAggregatedPerformance
 	trackedSyncCommitteeIndices map[primitives.ValidatorIndex][]primitives.CommitteeIndex
 	lastSyncedEpoch             primitives.Epoch
+
+	// Alert state: consecutive missed attestations, balances at the start of the last epoch,
+	// proposal slots of tracked validators and the last epoch the alert rules were evaluated.
+	missedAttestations map[primitives.ValidatorIndex]uint64
+	epochStartBalances map[primitives.ValidatorIndex]uint64
+	expectedProposals  map[primitives.Slot]primitives.ValidatorIndex
+	alertEpoch         primitives.Epoch
 }
 
-// NewService sets up a new validator monitor service instance when given a list of validator indices to track.
+// NewService sets up a new validator monitor service instance when given a list of validator indices to track,
+// in addition to the validators tracked before the last restart.
 func NewService(ctx context.Context, config *ValidatorMonitorConfig, tracked []primitives.ValidatorIndex) (*Service, error) {
 	ctx, cancel := context.WithCancel(ctx)
 	r := &Service{
@@ -88,14 +107,38 @@ func NewService(ctx context.Context, config *ValidatorMonitorConfig, tracked []p
 		ctx:                         ctx,
 		cancel:                      cancel,
 		TrackedValidators:           make(map[primitives.ValidatorIndex]bool, len(tracked)),
+		trackedPubkeys:              make(map[[dilithium2.CryptoPublicKeyBytes]byte]bool),
 		latestPerformance:           make(map[primitives.ValidatorIndex]ValidatorLatestPerformance),
 		aggregatedPerformance:       make(map[primitives.ValidatorIndex]ValidatorAggregatedPerformance),
 		trackedSyncCommitteeIndices: make(map[primitives.ValidatorIndex][]primitives.CommitteeIndex),
+		missedAttestations:          make(map[primitives.ValidatorIndex]uint64),
+		epochStartBalances:          make(map[primitives.ValidatorIndex]uint64),
+		expectedProposals:           make(map[primitives.Slot]primitives.ValidatorIndex),
 		isLogging:                   false,
 	}
+	if config.Alerts != nil && len(config.Alerts.Notifiers) > 0 {
+		r.alerter = newAlerter(config.Alerts)
+	}
 	for _, idx := range tracked {
 		r.TrackedValidators[idx] = true
 	}
+	if config.BeaconDB != nil {
+		indices, pubkeys, err := config.BeaconDB.MonitoredValidators(ctx)
+		if err != nil {
+			cancel()
+			return nil, errors.Wrap(err, "could not retrieve monitored validators")
+		}
+		for _, idx := range indices {
+			r.TrackedValidators[idx] = true
+		}
+		for _, pubkey := range pubkeys {
+			if len(pubkey) != dilithium2.CryptoPublicKeyBytes {
+				log.WithField("PublicKey", fmt.Sprintf("%#x", bytesutil.Trunc(pubkey))).Warn("Ignoring monitored public key of invalid length")
+				continue
+			}
+			r.trackedPubkeys[bytesutil.ToBytes2592(pubkey)] = true
+		}
+	}
 	return r, nil
 }
 
@@ -114,6 +157,9 @@ func (s *Service) Start() {
 		"ValidatorIndices": tracked,
 	}).Info("Starting service")
 
+	if s.alerter != nil {
+		go s.alerter.run(s.ctx)
+	}
 	go s.run()
 }
 
@@ -138,7 +184,9 @@ func (s *Service) run() {
 
 	s.Lock()
 	s.initializePerformanceStructures(st, epoch)
+	s.alertEpoch = epoch
 	s.Unlock()
+	s.resolveTrackedPubkeys(s.ctx, st)
 
 	s.updateSyncCommitteeTrackedVals(st)
 
@@ -155,19 +203,26 @@ func (s *Service) run() {
 // and validatorAggregatedPerformance for each tracked validator.
 func (s *Service) initializePerformanceStructures(state state.BeaconState, epoch primitives.Epoch) {
 	for idx := range s.TrackedValidators {
-		balance, err := state.BalanceAtIndex(idx)
-		if err != nil {
-			log.WithError(err).WithField("ValidatorIndex", idx).Error(
-				"Could not fetch starting balance, skipping aggregated logs.")
-			balance = 0
-		}
-		s.aggregatedPerformance[idx] = ValidatorAggregatedPerformance{
-			startEpoch:   epoch,
-			startBalance: balance,
-		}
-		s.latestPerformance[idx] = ValidatorLatestPerformance{
-			balance: balance,
-		}
+		s.initializePerformance(state, epoch, idx)
+	}
+}
+
+// initializePerformance initializes the validatorLatestPerformance and
+// validatorAggregatedPerformance of a tracked validator.
+// It assumes the caller holds the service Lock
+func (s *Service) initializePerformance(state state.BeaconState, epoch primitives.Epoch, idx primitives.ValidatorIndex) {
+	balance, err := state.BalanceAtIndex(idx)
+	if err != nil {
+		log.WithError(err).WithField("ValidatorIndex", idx).Error(
+			"Could not fetch starting balance, skipping aggregated logs.")
+		balance = 0
+	}
+	s.aggregatedPerformance[idx] = ValidatorAggregatedPerformance{
+		startEpoch:   epoch,
+		startBalance: balance,
+	}
+	s.latestPerformance[idx] = ValidatorLatestPerformance{
+		balance: balance,
 	}
 }
 
@@ -211,6 +266,9 @@ func (s *Service) monitorRoutine(stateChannel chan *feed.Event, stateSub event.S
 	for {
 		select {
 		case e := <-stateChannel:
+			if !s.tracking() {
+				continue
+			}
 			if e.Type == statefeed.BlockProcessed {
 				data, ok := e.Data.(*statefeed.BlockProcessedData)
 				if !ok {
@@ -221,6 +279,9 @@ func (s *Service) monitorRoutine(stateChannel chan *feed.Event, stateSub event.S
 				}
 			}
 		case e := <-opChannel:
+			if !s.tracking() {
+				continue
+			}
 			switch e.Type {
 			case operation.UnaggregatedAttReceived:
 				data, ok := e.Data.(*operation.UnAggregatedAttReceivedData)
@@ -274,16 +335,22 @@ func (s *Service) updateSyncCommitteeTrackedVals(state state.BeaconState) {
 	s.Lock()
 	defer s.Unlock()
 	for idx := range s.TrackedValidators {
-		syncIdx, err := helpers.CurrentPeriodSyncSubcommitteeIndices(state, idx)
-		if err != nil {
-			log.WithError(err).WithField("ValidatorIndex", idx).Error(
-				"Sync committee assignments will not be reported")
-			delete(s.trackedSyncCommitteeIndices, idx)
-		} else if len(syncIdx) == 0 {
-			delete(s.trackedSyncCommitteeIndices, idx)
-		} else {
-			s.trackedSyncCommitteeIndices[idx] = syncIdx
-		}
+		s.updateSyncCommitteeTrackedVal(state, idx)
 	}
 	s.lastSyncedEpoch = slots.ToEpoch(state.Slot())
 }
+
+// updateSyncCommitteeTrackedVal updates the sync committee assignments of a tracked validator.
+// It assumes the caller holds the service Lock
+func (s *Service) updateSyncCommitteeTrackedVal(state state.BeaconState, idx primitives.ValidatorIndex) {
+	syncIdx, err := helpers.CurrentPeriodSyncSubcommitteeIndices(state, idx)
+	if err != nil {
+		log.WithError(err).WithField("ValidatorIndex", idx).Error(
+			"Sync committee assignments will not be reported")
+		delete(s.trackedSyncCommitteeIndices, idx)
+	} else if len(syncIdx) == 0 {
+		delete(s.trackedSyncCommitteeIndices, idx)
+	} else {
+		s.trackedSyncCommitteeIndices[idx] = syncIdx
+	}
+}
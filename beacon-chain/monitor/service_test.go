@@ -8,6 +8,7 @@ import (
 	"time"
 
 	logTest "github.com/sirupsen/logrus/hooks/test"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
 	mock "github.com/theQRL/qrysm/v4/beacon-chain/blockchain/testing"
 	"github.com/theQRL/qrysm/v4/beacon-chain/core/altair"
 	"github.com/theQRL/qrysm/v4/beacon-chain/core/feed"
@@ -94,14 +95,19 @@ func setupService(t *testing.T) *Service {
 			HeadFetcher:         chainService,
 			AttestationNotifier: chainService.OperationNotifier(),
 			InitialSyncComplete: make(chan struct{}),
+			BeaconDB:            beaconDB,
 		},
 
 		ctx:                         context.Background(),
 		TrackedValidators:           trackedVals,
+		trackedPubkeys:              make(map[[dilithium2.CryptoPublicKeyBytes]byte]bool),
 		latestPerformance:           latestPerformance,
 		aggregatedPerformance:       aggregatedPerformance,
 		trackedSyncCommitteeIndices: trackedSyncCommitteeIndices,
 		lastSyncedEpoch:             0,
+		missedAttestations:          make(map[primitives.ValidatorIndex]uint64),
+		epochStartBalances:          make(map[primitives.ValidatorIndex]uint64),
+		expectedProposals:           make(map[primitives.Slot]primitives.ValidatorIndex),
 	}
 }
 
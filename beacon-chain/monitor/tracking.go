@@ -0,0 +1,251 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	"github.com/theQRL/qrysm/v4/time/slots"
+)
+
+// TrackedValidatorPerformance is the latest and the aggregated performance of a tracked validator.
+type TrackedValidatorPerformance struct {
+	ValidatorIndex primitives.ValidatorIndex
+
+	// Latest performance.
+	AttestedSlot  primitives.Slot
+	InclusionSlot primitives.Slot
+	TimelySource  bool
+	TimelyTarget  bool
+	TimelyHead    bool
+	Balance       uint64
+	BalanceChange int64
+
+	// Performance aggregated since the validator is tracked.
+	StartEpoch                      primitives.Epoch
+	StartBalance                    uint64
+	TotalAttestedCount              uint64
+	TotalRequestedCount             uint64
+	TotalDistance                   uint64
+	TotalCorrectSource              uint64
+	TotalCorrectTarget              uint64
+	TotalCorrectHead                uint64
+	TotalProposedCount              uint64
+	TotalAggregations               uint64
+	TotalSyncCommitteeContributions uint64
+	TotalSyncCommitteeAggregations  uint64
+}
+
+// TrackValidators starts tracking the given validator indices and public keys, and persists the
+// tracked validators. A public key is tracked by its validator index once the deposit of the
+// validator has been processed, so validators can be tracked before their activation.
+func (s *Service) TrackValidators(ctx context.Context, indices []primitives.ValidatorIndex, pubkeys [][dilithium2.CryptoPublicKeyBytes]byte) error {
+	st, err := s.syncedHeadState(ctx)
+	if err != nil {
+		return err
+	}
+	s.Lock()
+	for _, idx := range indices {
+		s.trackIndex(st, idx)
+	}
+	for _, pubkey := range pubkeys {
+		if st != nil {
+			if idx, ok := st.ValidatorIndexByPubkey(pubkey); ok {
+				s.trackIndex(st, idx)
+				continue
+			}
+		}
+		s.trackedPubkeys[pubkey] = true
+	}
+	s.Unlock()
+	return s.saveTrackedValidators(ctx)
+}
+
+// UntrackValidators stops tracking the given validator indices and public keys, and persists the
+// tracked validators.
+func (s *Service) UntrackValidators(ctx context.Context, indices []primitives.ValidatorIndex, pubkeys [][dilithium2.CryptoPublicKeyBytes]byte) error {
+	var st state.BeaconState
+	if len(pubkeys) > 0 {
+		var err error
+		st, err = s.config.HeadFetcher.HeadState(ctx)
+		if err != nil {
+			return errors.Wrap(err, "could not get head state")
+		}
+	}
+	s.Lock()
+	for _, idx := range indices {
+		s.untrackIndex(idx)
+	}
+	for _, pubkey := range pubkeys {
+		delete(s.trackedPubkeys, pubkey)
+		if st == nil || st.IsNil() {
+			continue
+		}
+		if idx, ok := st.ValidatorIndexByPubkey(pubkey); ok {
+			s.untrackIndex(idx)
+		}
+	}
+	s.Unlock()
+	return s.saveTrackedValidators(ctx)
+}
+
+// TrackedValidatorsList returns the sorted indices of the tracked validators, and the public keys
+// of tracked validators whose deposit has not been processed yet.
+func (s *Service) TrackedValidatorsList() ([]primitives.ValidatorIndex, [][dilithium2.CryptoPublicKeyBytes]byte) {
+	s.RLock()
+	defer s.RUnlock()
+	indices := make([]primitives.ValidatorIndex, 0, len(s.TrackedValidators))
+	for idx := range s.TrackedValidators {
+		indices = append(indices, idx)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	pubkeys := make([][dilithium2.CryptoPublicKeyBytes]byte, 0, len(s.trackedPubkeys))
+	for pubkey := range s.trackedPubkeys {
+		pubkeys = append(pubkeys, pubkey)
+	}
+	sort.Slice(pubkeys, func(i, j int) bool { return string(pubkeys[i][:]) < string(pubkeys[j][:]) })
+	return indices, pubkeys
+}
+
+// Performance returns the performance of the tracked validators, sorted by validator index. It is
+// empty until the monitor has synced to the head.
+func (s *Service) Performance() []*TrackedValidatorPerformance {
+	s.RLock()
+	defer s.RUnlock()
+	perfs := make([]*TrackedValidatorPerformance, 0, len(s.TrackedValidators))
+	for idx := range s.TrackedValidators {
+		l, ok := s.latestPerformance[idx]
+		if !ok {
+			continue
+		}
+		a := s.aggregatedPerformance[idx]
+		perfs = append(perfs, &TrackedValidatorPerformance{
+			ValidatorIndex:                  idx,
+			AttestedSlot:                    l.attestedSlot,
+			InclusionSlot:                   l.inclusionSlot,
+			TimelySource:                    l.timelySource,
+			TimelyTarget:                    l.timelyTarget,
+			TimelyHead:                      l.timelyHead,
+			Balance:                         l.balance,
+			BalanceChange:                   l.balanceChange,
+			StartEpoch:                      a.startEpoch,
+			StartBalance:                    a.startBalance,
+			TotalAttestedCount:              a.totalAttestedCount,
+			TotalRequestedCount:             a.totalRequestedCount,
+			TotalDistance:                   a.totalDistance,
+			TotalCorrectSource:              a.totalCorrectSource,
+			TotalCorrectTarget:              a.totalCorrectTarget,
+			TotalCorrectHead:                a.totalCorrectHead,
+			TotalProposedCount:              a.totalProposedCount,
+			TotalAggregations:               a.totalAggregations,
+			TotalSyncCommitteeContributions: a.totalSyncCommitteeContributions,
+			TotalSyncCommitteeAggregations:  a.totalSyncCommitteeAggregations,
+		})
+	}
+	sort.Slice(perfs, func(i, j int) bool { return perfs[i].ValidatorIndex < perfs[j].ValidatorIndex })
+	return perfs
+}
+
+// tracking returns true if any validator is tracked, by index or by public key.
+func (s *Service) tracking() bool {
+	s.RLock()
+	defer s.RUnlock()
+	return len(s.TrackedValidators) > 0 || len(s.trackedPubkeys) > 0
+}
+
+// syncedHeadState returns the head state once the monitor has synced to the head, and nil before.
+func (s *Service) syncedHeadState(ctx context.Context) (state.BeaconState, error) {
+	s.RLock()
+	isLogging := s.isLogging
+	s.RUnlock()
+	if !isLogging {
+		return nil, nil
+	}
+	st, err := s.config.HeadFetcher.HeadState(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get head state")
+	}
+	if st == nil || st.IsNil() {
+		return nil, errors.New("head state is nil")
+	}
+	return st, nil
+}
+
+// resolveTrackedPubkeys tracks by validator index the tracked public keys whose deposit has been
+// processed in the given state.
+func (s *Service) resolveTrackedPubkeys(ctx context.Context, st state.BeaconState) {
+	resolved := false
+	s.Lock()
+	for pubkey := range s.trackedPubkeys {
+		idx, ok := st.ValidatorIndexByPubkey(pubkey)
+		if !ok {
+			continue
+		}
+		delete(s.trackedPubkeys, pubkey)
+		s.trackIndex(st, idx)
+		resolved = true
+		log.WithFields(logrus.Fields{
+			"PublicKey":      fmt.Sprintf("%#x", bytesutil.Trunc(pubkey[:])),
+			"ValidatorIndex": idx,
+		}).Info("Tracking validator of public key")
+	}
+	s.Unlock()
+	if resolved {
+		if err := s.saveTrackedValidators(ctx); err != nil {
+			log.WithError(err).Error("Could not save tracked validators")
+		}
+	}
+}
+
+// trackIndex adds a validator index to the tracked validators. The performance of the validator is
+// initialized from the given state, or when the monitor syncs to the head if the state is nil.
+// It assumes the caller holds the service Lock
+func (s *Service) trackIndex(st state.BeaconState, idx primitives.ValidatorIndex) {
+	if s.trackedIndex(idx) {
+		return
+	}
+	s.TrackedValidators[idx] = true
+	if st == nil {
+		return
+	}
+	s.initializePerformance(st, slots.ToEpoch(st.Slot()), idx)
+	s.updateSyncCommitteeTrackedVal(st, idx)
+}
+
+// untrackIndex removes a validator index and its performance from the tracked validators.
+// It assumes the caller holds the service Lock
+func (s *Service) untrackIndex(idx primitives.ValidatorIndex) {
+	delete(s.TrackedValidators, idx)
+	delete(s.latestPerformance, idx)
+	delete(s.aggregatedPerformance, idx)
+	delete(s.trackedSyncCommitteeIndices, idx)
+	delete(s.missedAttestations, idx)
+	delete(s.epochStartBalances, idx)
+	for slot, proposer := range s.expectedProposals {
+		if proposer == idx {
+			delete(s.expectedProposals, slot)
+		}
+	}
+}
+
+// saveTrackedValidators persists the tracked validators, if the monitor has a database.
+func (s *Service) saveTrackedValidators(ctx context.Context) error {
+	if s.config.BeaconDB == nil {
+		return nil
+	}
+	indices, pubkeys := s.TrackedValidatorsList()
+	encoded := make([][]byte, len(pubkeys))
+	for i := range pubkeys {
+		encoded[i] = pubkeys[i][:]
+	}
+	if err := s.config.BeaconDB.SaveMonitoredValidators(ctx, indices, encoded); err != nil {
+		return errors.Wrap(err, "could not save monitored validators")
+	}
+	return nil
+}
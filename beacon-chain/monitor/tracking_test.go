@@ -0,0 +1,82 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	"github.com/theQRL/qrysm/v4/testing/require"
+)
+
+func TestTrackValidators(t *testing.T) {
+	ctx := context.Background()
+	s := setupService(t)
+	s.isLogging = true
+	st, err := s.config.HeadFetcher.HeadState(ctx)
+	require.NoError(t, err)
+	pubkey3 := bytesutil.ToBytes2592(st.Validators()[3].PublicKey)
+	unknown := [dilithium2.CryptoPublicKeyBytes]byte{'x'}
+
+	require.NoError(t, s.TrackValidators(ctx, []primitives.ValidatorIndex{20}, [][dilithium2.CryptoPublicKeyBytes]byte{pubkey3, unknown}))
+	indices, pubkeys := s.TrackedValidatorsList()
+	require.DeepEqual(t, []primitives.ValidatorIndex{1, 2, 3, 12, 15, 20}, indices)
+	require.DeepEqual(t, [][dilithium2.CryptoPublicKeyBytes]byte{unknown}, pubkeys)
+
+	// Validators tracked at runtime have their performance initialized from the head state.
+	balance, err := st.BalanceAtIndex(20)
+	require.NoError(t, err)
+	perfs := s.Performance()
+	require.Equal(t, 6, len(perfs))
+	require.Equal(t, primitives.ValidatorIndex(20), perfs[5].ValidatorIndex)
+	require.Equal(t, balance, perfs[5].Balance)
+	require.Equal(t, balance, perfs[5].StartBalance)
+
+	// The tracked validators are restored on restart.
+	restarted, err := NewService(ctx, s.config, nil)
+	require.NoError(t, err)
+	indices, pubkeys = restarted.TrackedValidatorsList()
+	require.DeepEqual(t, []primitives.ValidatorIndex{1, 2, 3, 12, 15, 20}, indices)
+	require.DeepEqual(t, [][dilithium2.CryptoPublicKeyBytes]byte{unknown}, pubkeys)
+
+	require.NoError(t, s.UntrackValidators(ctx, []primitives.ValidatorIndex{1, 20}, [][dilithium2.CryptoPublicKeyBytes]byte{pubkey3, unknown}))
+	indices, pubkeys = s.TrackedValidatorsList()
+	require.DeepEqual(t, []primitives.ValidatorIndex{2, 12, 15}, indices)
+	require.Equal(t, 0, len(pubkeys))
+	_, ok := s.latestPerformance[1]
+	require.Equal(t, false, ok)
+	_, ok = s.trackedSyncCommitteeIndices[1]
+	require.Equal(t, false, ok)
+}
+
+func TestTrackValidators_BeforeSync(t *testing.T) {
+	ctx := context.Background()
+	s := setupService(t)
+	st, err := s.config.HeadFetcher.HeadState(ctx)
+	require.NoError(t, err)
+	pubkey3 := bytesutil.ToBytes2592(st.Validators()[3].PublicKey)
+
+	// Public keys are only resolved, and performance initialized, once the monitor has synced.
+	require.NoError(t, s.TrackValidators(ctx, []primitives.ValidatorIndex{20}, [][dilithium2.CryptoPublicKeyBytes]byte{pubkey3}))
+	indices, pubkeys := s.TrackedValidatorsList()
+	require.DeepEqual(t, []primitives.ValidatorIndex{1, 2, 12, 15, 20}, indices)
+	require.DeepEqual(t, [][dilithium2.CryptoPublicKeyBytes]byte{pubkey3}, pubkeys)
+	_, ok := s.latestPerformance[20]
+	require.Equal(t, false, ok)
+
+	s.resolveTrackedPubkeys(ctx, st)
+	indices, pubkeys = s.TrackedValidatorsList()
+	require.DeepEqual(t, []primitives.ValidatorIndex{1, 2, 3, 12, 15, 20}, indices)
+	require.Equal(t, 0, len(pubkeys))
+	_, ok = s.latestPerformance[3]
+	require.Equal(t, true, ok)
+	// Validators 1 and 2 are in the current sync committee of the state.
+	_, ok = s.trackedSyncCommitteeIndices[3]
+	require.Equal(t, false, ok)
+
+	savedIndices, savedPubkeys, err := s.config.BeaconDB.MonitoredValidators(ctx)
+	require.NoError(t, err)
+	require.DeepEqual(t, []primitives.ValidatorIndex{1, 2, 3, 12, 15, 20}, savedIndices)
+	require.Equal(t, 0, len(savedPubkeys))
+}
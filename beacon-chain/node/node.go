@@ -31,6 +31,7 @@ import (
 	"github.com/theQRL/qrysm/v4/beacon-chain/execution"
 	"github.com/theQRL/qrysm/v4/beacon-chain/forkchoice"
 	doublylinkedtree "github.com/theQRL/qrysm/v4/beacon-chain/forkchoice/doubly-linked-tree"
+	"github.com/theQRL/qrysm/v4/beacon-chain/forkchoice/replay"
 	"github.com/theQRL/qrysm/v4/beacon-chain/gateway"
 	"github.com/theQRL/qrysm/v4/beacon-chain/monitor"
 	"github.com/theQRL/qrysm/v4/beacon-chain/node/registration"
@@ -42,6 +43,7 @@ import (
 	"github.com/theQRL/qrysm/v4/beacon-chain/p2p"
 	"github.com/theQRL/qrysm/v4/beacon-chain/rpc"
 	"github.com/theQRL/qrysm/v4/beacon-chain/rpc/apimiddleware"
+	validatorprysm "github.com/theQRL/qrysm/v4/beacon-chain/rpc/prysm/validator"
 	"github.com/theQRL/qrysm/v4/beacon-chain/slasher"
 	"github.com/theQRL/qrysm/v4/beacon-chain/startup"
 	"github.com/theQRL/qrysm/v4/beacon-chain/state"
@@ -260,6 +262,11 @@ func New(cliCtx *cli.Context, opts ...Option) (*BeaconNode, error) {
 		return nil, err
 	}
 
+	log.Debugln("Registering Validator Monitoring Service")
+	if err := beacon.registerValidatorMonitorService(beacon.initialSyncComplete); err != nil {
+		return nil, err
+	}
+
 	log.Debugln("Registering RPC Service")
 	router := mux.NewRouter()
 	if err := beacon.registerRPCService(router); err != nil {
@@ -271,11 +278,6 @@ func New(cliCtx *cli.Context, opts ...Option) (*BeaconNode, error) {
 		return nil, err
 	}
 
-	log.Debugln("Registering Validator Monitoring Service")
-	if err := beacon.registerValidatorMonitorService(beacon.initialSyncComplete); err != nil {
-		return nil, err
-	}
-
 	if !cliCtx.Bool(cmd.DisableMonitoringFlag.Name) {
 		log.Debugln("Registering Prometheus Service")
 		if err := beacon.registerPrometheusService(cliCtx); err != nil {
@@ -498,6 +500,9 @@ func (b *BeaconNode) startSlasherDB(cliCtx *cli.Context) error {
 
 func (b *BeaconNode) startStateGen(ctx context.Context, bfs *backfill.Status, fc forkchoice.ForkChoicer) error {
 	opts := []stategen.StateGenOption{stategen.WithBackfillStatus(bfs)}
+	if b.cliCtx.Bool(flags.ArchiveStateDiffs.Name) {
+		opts = append(opts, stategen.WithStateDiffs())
+	}
 	sg := stategen.New(b.db, fc, opts...)
 
 	cp, err := b.db.FinalizedCheckpoint(ctx)
@@ -679,8 +684,7 @@ func (b *BeaconNode) registerSyncService(initialSyncComplete chan struct{}) erro
 		return err
 	}
 
-	rs := regularsync.NewService(
-		b.ctx,
+	opts := []regularsync.Option{
 		regularsync.WithDatabase(b.db),
 		regularsync.WithP2P(b.fetchP2P()),
 		regularsync.WithChainService(chainService),
@@ -699,7 +703,15 @@ func (b *BeaconNode) registerSyncService(initialSyncComplete chan struct{}) erro
 		regularsync.WithExecutionPayloadReconstructor(web3Service),
 		regularsync.WithClockWaiter(b.clockWaiter),
 		regularsync.WithInitialSyncComplete(initialSyncComplete),
-	)
+	}
+	if b.cliCtx.IsSet(flags.ForkChoiceCaptureFile.Name) {
+		w, err := replay.Create(b.cliCtx.String(flags.ForkChoiceCaptureFile.Name))
+		if err != nil {
+			return err
+		}
+		opts = append(opts, regularsync.WithGossipCapture(w))
+	}
+	rs := regularsync.NewService(b.ctx, opts...)
 	return b.services.RegisterService(rs)
 }
 
@@ -775,6 +787,15 @@ func (b *BeaconNode) registerRPCService(router *mux.Router) error {
 		}
 	}
 
+	var validatorMonitor validatorprysm.ValidatorMonitor
+	if b.validatorMonitorEnabled() {
+		var monitorService *monitor.Service
+		if err := b.services.FetchService(&monitorService); err != nil {
+			return err
+		}
+		validatorMonitor = monitorService
+	}
+
 	genesisValidators := b.cliCtx.Uint64(flags.InteropNumValidatorsFlag.Name)
 	var depositFetcher depositcache.DepositFetcher
 	var chainStartFetcher execution.ChainStartFetcher
@@ -846,10 +867,13 @@ func (b *BeaconNode) registerRPCService(router *mux.Router) error {
 		StateGen:                      b.stateGen,
 		EnableDebugRPCEndpoints:       enableDebugRPCEndpoints,
 		MaxMsgSize:                    maxMsgSize,
+		MaxConcurrentStateReplays:     b.cliCtx.Int(flags.MaxConcurrentStateReplays.Name),
 		ProposerIdsCache:              b.proposerIdsCache,
 		BlockBuilder:                  b.fetchBuilderService(),
 		Router:                        router,
 		ClockWaiter:                   b.clockWaiter,
+		ValidatorMonitor:              validatorMonitor,
+		EnableValidatorMonitorAPI:     b.cliCtx.Bool(cmd.EnableValidatorMonitorAPIFlag.Name),
 	})
 
 	return b.services.RegisterService(rpcService)
@@ -950,11 +974,17 @@ func (b *BeaconNode) registerDeterministicGenesisService() error {
 	return nil
 }
 
+// validatorMonitorEnabled returns whether validators are tracked from the command line, or can be
+// tracked at runtime.
+func (b *BeaconNode) validatorMonitorEnabled() bool {
+	return b.cliCtx.IntSlice(cmd.ValidatorMonitorIndicesFlag.Name) != nil || b.cliCtx.Bool(cmd.EnableValidatorMonitorAPIFlag.Name)
+}
+
 func (b *BeaconNode) registerValidatorMonitorService(initialSyncComplete chan struct{}) error {
-	cliSlice := b.cliCtx.IntSlice(cmd.ValidatorMonitorIndicesFlag.Name)
-	if cliSlice == nil {
+	if !b.validatorMonitorEnabled() {
 		return nil
 	}
+	cliSlice := b.cliCtx.IntSlice(cmd.ValidatorMonitorIndicesFlag.Name)
 	tracked := make([]primitives.ValidatorIndex, len(cliSlice))
 	for i := range tracked {
 		tracked[i] = primitives.ValidatorIndex(cliSlice[i])
@@ -970,7 +1000,13 @@ func (b *BeaconNode) registerValidatorMonitorService(initialSyncComplete chan st
 		StateGen:            b.stateGen,
 		HeadFetcher:         chainService,
 		InitialSyncComplete: initialSyncComplete,
+		BeaconDB:            b.db,
 	}
+	alerts, err := validatorMonitorAlerts(b.cliCtx)
+	if err != nil {
+		return err
+	}
+	monitorConfig.Alerts = alerts
 	svc, err := monitor.NewService(b.ctx, monitorConfig, tracked)
 	if err != nil {
 		return err
@@ -978,6 +1014,36 @@ func (b *BeaconNode) registerValidatorMonitorService(initialSyncComplete chan st
 	return b.services.RegisterService(svc)
 }
 
+// validatorMonitorAlerts returns the alert rules and notifiers of the validator monitor, or nil when
+// no alert notifier is configured.
+func validatorMonitorAlerts(cliCtx *cli.Context) (*monitor.AlertConfig, error) {
+	var notifiers []monitor.Notifier
+	for _, url := range cliCtx.StringSlice(cmd.MonitorAlertWebhookFlag.Name) {
+		notifiers = append(notifiers, monitor.NewWebhookNotifier(url))
+	}
+	if path := cliCtx.String(cmd.MonitorAlertFileFlag.Name); path != "" {
+		n, err := monitor.NewFileNotifier(path)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, n)
+	}
+	if cliCtx.Bool(cmd.MonitorAlertStdoutFlag.Name) {
+		notifiers = append(notifiers, monitor.NewJSONNotifier(os.Stdout))
+	}
+	if len(notifiers) == 0 {
+		return nil, nil
+	}
+	return &monitor.AlertConfig{
+		MissedAttestations: cliCtx.Uint64(cmd.MonitorAlertMissedAttestationsFlag.Name),
+		MissedProposals:    cliCtx.Bool(cmd.MonitorAlertMissedProposalsFlag.Name),
+		BalanceDrop:        cliCtx.Uint64(cmd.MonitorAlertBalanceDropFlag.Name),
+		Slashing:           cliCtx.Bool(cmd.MonitorAlertSlashingFlag.Name),
+		Cooldown:           cliCtx.Duration(cmd.MonitorAlertCooldownFlag.Name),
+		Notifiers:          notifiers,
+	}, nil
+}
+
 func (b *BeaconNode) registerBuilderService(cliCtx *cli.Context) error {
 	var chainService *blockchain.Service
 	if err := b.services.FetchService(&chainService); err != nil {
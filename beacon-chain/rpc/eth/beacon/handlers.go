@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/pkg/errors"
@@ -19,6 +21,7 @@ import (
 )
 
 const (
+	octetStreamMediaType                        = "application/octet-stream"
 	broadcastValidationQueryParam               = "broadcast_validation"
 	broadcastValidationConsensus                = "consensus"
 	broadcastValidationConsensusAndEquivocation = "consensus_and_equivocation"
@@ -373,3 +376,41 @@ func (bs *Server) checkSync(ctx context.Context, w http.ResponseWriter) bool {
 	}
 	return true
 }
+
+// GetDepositSnapshot returns the EIP-4881 deposit snapshot of the finalized deposit tree, in JSON or,
+// when requested in the Accept header, in SSZ.
+func (bs *Server) GetDepositSnapshot(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := bs.BeaconDB.DepositSnapshot(r.Context())
+	if err != nil {
+		network.WriteError(w, &network.DefaultErrorJson{
+			Message: "Could not get deposit snapshot: " + err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	if snapshot == nil {
+		network.WriteError(w, &network.DefaultErrorJson{
+			Message: "No finalized snapshot available",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	if !strings.Contains(r.Header.Get("Accept"), octetStreamMediaType) {
+		network.WriteJson(w, &GetDepositSnapshotResponse{Data: snapshot})
+		return
+	}
+	enc, err := snapshot.MarshalSSZ()
+	if err != nil {
+		network.WriteError(w, &network.DefaultErrorJson{
+			Message: "Could not encode deposit snapshot: " + err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(enc)))
+	w.Header().Set("Content-Type", octetStreamMediaType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(enc); err != nil {
+		log.WithError(err).Error("Could not write deposit snapshot")
+	}
+}
@@ -2,6 +2,8 @@ package beacon
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -10,10 +12,15 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/mock"
 	testing2 "github.com/theQRL/qrysm/v4/beacon-chain/blockchain/testing"
+	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositsnapshot"
+	dbTest "github.com/theQRL/qrysm/v4/beacon-chain/db/testing"
 	mockSync "github.com/theQRL/qrysm/v4/beacon-chain/sync/initial-sync/testing"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/container/trie"
 	zond "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
 	"github.com/theQRL/qrysm/v4/testing/assert"
 	mock2 "github.com/theQRL/qrysm/v4/testing/mock"
+	"github.com/theQRL/qrysm/v4/testing/require"
 )
 
 func TestPublishBlockV2(t *testing.T) {
@@ -1273,3 +1280,46 @@ const (
   "signature": "0x1b66ac1fb663c9bc59509846d6ec05345bd908eda73e670af888da41af171505cc411d61252fb6cb3fa0017b679f8bb2305b26a285fa2737f175668d0dff91cc1b66ac1fb663c9bc59509846d6ec05345bd908eda73e670af888da41af171505"
 }`
 )
+
+func TestGetDepositSnapshot(t *testing.T) {
+	ctx := context.Background()
+	beaconDB := dbTest.SetupDB(t)
+	server := &Server{BeaconDB: beaconDB}
+
+	t.Run("no snapshot", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "http://foo.example/eth/v1/beacon/deposit_snapshot", nil)
+		writer := httptest.NewRecorder()
+		writer.Body = &bytes.Buffer{}
+		server.GetDepositSnapshot(writer, request)
+		assert.Equal(t, http.StatusNotFound, writer.Code)
+	})
+
+	depositTrie, err := trie.GenerateTrieFromItems([][]byte{{1}, {2}, {3}}, params.BeaconConfig().DepositContractTreeDepth)
+	require.NoError(t, err)
+	snapshot, err := depositsnapshot.SnapshotFromTrie(depositTrie, 3, [32]byte{'a'}, 100)
+	require.NoError(t, err)
+	require.NoError(t, beaconDB.SaveDepositSnapshot(ctx, snapshot))
+
+	t.Run("JSON", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "http://foo.example/eth/v1/beacon/deposit_snapshot", nil)
+		writer := httptest.NewRecorder()
+		writer.Body = &bytes.Buffer{}
+		server.GetDepositSnapshot(writer, request)
+		require.Equal(t, http.StatusOK, writer.Code)
+		resp := &GetDepositSnapshotResponse{}
+		require.NoError(t, json.Unmarshal(writer.Body.Bytes(), resp))
+		assert.DeepEqual(t, snapshot, resp.Data)
+	})
+	t.Run("SSZ", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "http://foo.example/eth/v1/beacon/deposit_snapshot", nil)
+		request.Header.Set("Accept", octetStreamMediaType)
+		writer := httptest.NewRecorder()
+		writer.Body = &bytes.Buffer{}
+		server.GetDepositSnapshot(writer, request)
+		require.Equal(t, http.StatusOK, writer.Code)
+		assert.Equal(t, octetStreamMediaType, writer.Header().Get("Content-Type"))
+		decoded, err := depositsnapshot.DecodeSnapshot(writer.Body.Bytes())
+		require.NoError(t, err)
+		assert.DeepEqual(t, snapshot, decoded)
+	})
+}
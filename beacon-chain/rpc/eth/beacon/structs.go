@@ -6,6 +6,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/theQRL/go-zond/common/hexutil"
+	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositsnapshot"
 	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
 	bytesutil2 "github.com/theQRL/qrysm/v4/encoding/bytesutil"
 	enginev1 "github.com/theQRL/qrysm/v4/proto/engine/v1"
@@ -13,6 +14,10 @@ import (
 	"github.com/wealdtech/go-bytesutil"
 )
 
+type GetDepositSnapshotResponse struct {
+	Data *depositsnapshot.DepositTreeSnapshot `json:"data"`
+}
+
 type SignedBeaconBlock struct {
 	Message   BeaconBlock `json:"message" validate:"required"`
 	Signature string      `json:"signature" validate:"required"`
@@ -0,0 +1,179 @@
+package debug
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/theQRL/go-zond/common/hexutil"
+	"github.com/theQRL/qrysm/v4/beacon-chain/rpc/eth/helpers"
+	"github.com/theQRL/qrysm/v4/network"
+	"go.opencensus.io/trace"
+)
+
+// maxProofIndices is the maximum number of paths and generalized indices of a single proof request.
+const maxProofIndices = 64
+
+// GetStateProof returns a Merkle multiproof of the values of the beacon state for the given state ID.
+// The values are given by the "path" query parameter, a dot separated path of field names and
+// indices such as validators.5.effective_balance, and by the "gindex" query parameter holding
+// generalized indices. Both parameters can be repeated to prove several values at once.
+func (ds *Server) GetStateProof(w http.ResponseWriter, r *http.Request) {
+	ctx, span := trace.StartSpan(r.Context(), "debug.GetStateProof")
+	defer span.End()
+
+	stateId := mux.Vars(r)["state_id"]
+	if stateId == "" {
+		network.WriteError(w, &network.DefaultErrorJson{
+			Message: "state_id is required in URL params",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	query := r.URL.Query()
+	paths, rawGindices := query["path"], query["gindex"]
+	if len(paths)+len(rawGindices) == 0 {
+		network.WriteError(w, &network.DefaultErrorJson{
+			Message: "at least one path or gindex query parameter is required",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	if len(paths)+len(rawGindices) > maxProofIndices {
+		network.WriteError(w, &network.DefaultErrorJson{
+			Message: fmt.Sprintf("at most %d paths and generalized indices can be proven at once", maxProofIndices),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	st, err := ds.Stater.State(ctx, []byte(stateId))
+	if err != nil {
+		network.WriteError(w, &network.DefaultErrorJson{
+			Message: "could not retrieve state: " + err.Error(),
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	gindices := make([]uint64, 0, len(paths)+len(rawGindices))
+	for _, path := range paths {
+		gindex, err := st.GeneralizedIndex(strings.Split(path, ".")...)
+		if err != nil {
+			network.WriteError(w, &network.DefaultErrorJson{
+				Message: fmt.Sprintf("invalid path %s: %v", path, err),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		gindices = append(gindices, gindex)
+	}
+	for _, raw := range rawGindices {
+		gindex, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil || gindex == 0 {
+			network.WriteError(w, &network.DefaultErrorJson{
+				Message: fmt.Sprintf("invalid generalized index %s", raw),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		gindices = append(gindices, gindex)
+	}
+	p, err := st.Multiproof(ctx, gindices)
+	if err != nil {
+		network.WriteError(w, &network.DefaultErrorJson{
+			Message: "could not compute proof: " + err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	isOptimistic, err := helpers.IsOptimistic(ctx, []byte(stateId), ds.OptimisticModeFetcher, ds.Stater, ds.ChainInfoFetcher, ds.BeaconDB)
+	if err != nil {
+		network.WriteError(w, &network.DefaultErrorJson{
+			Message: "could not check if slot's block is optimistic: " + err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	blockRoot, err := st.LatestBlockHeader().HashTreeRoot()
+	if err != nil {
+		network.WriteError(w, &network.DefaultErrorJson{
+			Message: "could not calculate root of latest block header: " + err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	data := &StateProof{
+		StateRoot: hexutil.Encode(p.Root[:]),
+		Gindices:  make([]string, len(p.Indices)),
+		Leaves:    make([]string, len(p.Leaves)),
+		Proof:     make([]string, len(p.Hashes)),
+	}
+	for i, gindex := range p.Indices {
+		data.Gindices[i] = strconv.FormatUint(gindex, 10)
+	}
+	for i, leaf := range p.Leaves {
+		data.Leaves[i] = hexutil.Encode(leaf[:])
+	}
+	for i, h := range p.Hashes {
+		data.Proof[i] = hexutil.Encode(h[:])
+	}
+	network.WriteJson(w, &StateProofResponse{
+		Data:                data,
+		ExecutionOptimistic: isOptimistic,
+		Finalized:           ds.FinalizationFetcher.IsFinalized(ctx, blockRoot),
+	})
+}
+
+// GetForkChoiceHistory returns the head changes, tip weight updates, proposer boosts and late block
+// reorg decisions recorded by fork choice. The "format" query parameter selects between "json", the
+// default, and "dot", a Graphviz digraph of the recorded blocks and head changes.
+func (ds *Server) GetForkChoiceHistory(w http.ResponseWriter, r *http.Request) {
+	_, span := trace.StartSpan(r.Context(), "debug.GetForkChoiceHistory")
+	defer span.End()
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "dot" {
+		network.WriteError(w, &network.DefaultErrorJson{
+			Message: fmt.Sprintf("unsupported format %s, expected json or dot", format),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	history := ds.ForkchoiceFetcher.ForkChoiceHistory()
+	if history == nil {
+		network.WriteError(w, &network.DefaultErrorJson{
+			Message: "fork choice history is not recorded, start the node with --enable-forkchoice-recorder",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	var buf bytes.Buffer
+	contentType := "application/json"
+	exportFn := history.ExportJSON
+	if format == "dot" {
+		contentType = "text/vnd.graphviz"
+		exportFn = history.ExportGraphviz
+	}
+	if err := exportFn(&buf); err != nil {
+		network.WriteError(w, &network.DefaultErrorJson{
+			Message: "could not export fork choice history: " + err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := buf.WriteTo(w); err != nil {
+		log.WithError(err).Error("Could not write fork choice history")
+	}
+}
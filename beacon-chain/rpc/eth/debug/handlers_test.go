@@ -0,0 +1,165 @@
+package debug
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/theQRL/go-zond/common/hexutil"
+	blockchainmock "github.com/theQRL/qrysm/v4/beacon-chain/blockchain/testing"
+	dbTest "github.com/theQRL/qrysm/v4/beacon-chain/db/testing"
+	doublylinkedtree "github.com/theQRL/qrysm/v4/beacon-chain/forkchoice/doubly-linked-tree"
+	"github.com/theQRL/qrysm/v4/beacon-chain/rpc/testutil"
+	statenative "github.com/theQRL/qrysm/v4/beacon-chain/state/state-native"
+	"github.com/theQRL/qrysm/v4/config/features"
+	"github.com/theQRL/qrysm/v4/encoding/ssz/proof"
+	"github.com/theQRL/qrysm/v4/network"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/util"
+)
+
+func TestGetStateProof(t *testing.T) {
+	ctx := context.Background()
+	db := dbTest.SetupDB(t)
+	blk := util.NewBeaconBlock()
+	blkRoot, err := blk.Block.HashTreeRoot()
+	require.NoError(t, err)
+	util.SaveBlock(t, ctx, db, blk)
+	require.NoError(t, db.SaveGenesisBlockRoot(ctx, blkRoot))
+
+	st, err := util.NewBeaconStateCapella()
+	require.NoError(t, err)
+	require.NoError(t, st.SetBalances([]uint64{10, 20, 30}))
+	htr, err := st.HashTreeRoot(ctx)
+	require.NoError(t, err)
+	chainService := &blockchainmock.ChainService{}
+	s := &Server{
+		Stater:                &testutil.MockStater{BeaconState: st},
+		HeadFetcher:           chainService,
+		OptimisticModeFetcher: chainService,
+		FinalizationFetcher:   chainService,
+		BeaconDB:              db,
+	}
+
+	request := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/eth/v1/debug/beacon/states/head/proof?"+query, nil)
+		req = mux.SetURLVars(req, map[string]string{"state_id": "head"})
+		writer := httptest.NewRecorder()
+		writer.Body = &bytes.Buffer{}
+		s.GetStateProof(writer, req)
+		return writer
+	}
+
+	t.Run("paths and generalized indices", func(t *testing.T) {
+		gindex := statenative.FinalizedRootGeneralizedIndex()
+		writer := request("path=balances.2&path=balances.__len__&gindex=" + strconv.FormatUint(gindex, 10))
+		require.Equal(t, http.StatusOK, writer.Code)
+		resp := &StateProofResponse{}
+		require.NoError(t, json.Unmarshal(writer.Body.Bytes(), resp))
+		assert.Equal(t, hexutil.Encode(htr[:]), resp.Data.StateRoot)
+		require.Equal(t, 3, len(resp.Data.Gindices))
+		assert.Equal(t, strconv.FormatUint(gindex, 10), resp.Data.Gindices[2])
+
+		indices := make([]uint64, len(resp.Data.Gindices))
+		for i, g := range resp.Data.Gindices {
+			indices[i], err = strconv.ParseUint(g, 10, 64)
+			require.NoError(t, err)
+		}
+		leaves := decodeRoots(t, resp.Data.Leaves)
+		hashes := decodeRoots(t, resp.Data.Proof)
+		// The first chunk of the balances holds the third balance.
+		assert.Equal(t, byte(30), leaves[0][16])
+		assert.Equal(t, byte(3), leaves[1][0])
+		valid, err := proof.Verify(htr, indices, leaves, hashes)
+		require.NoError(t, err)
+		assert.Equal(t, true, valid)
+	})
+	t.Run("no path", func(t *testing.T) {
+		writer := request("")
+		assert.Equal(t, http.StatusBadRequest, writer.Code)
+		e := &network.DefaultErrorJson{}
+		require.NoError(t, json.Unmarshal(writer.Body.Bytes(), e))
+		assert.StringContains(t, "at least one path or gindex", e.Message)
+	})
+	t.Run("invalid path", func(t *testing.T) {
+		writer := request("path=foo.bar")
+		assert.Equal(t, http.StatusBadRequest, writer.Code)
+		e := &network.DefaultErrorJson{}
+		require.NoError(t, json.Unmarshal(writer.Body.Bytes(), e))
+		assert.StringContains(t, "invalid path foo.bar", e.Message)
+	})
+	t.Run("invalid generalized index", func(t *testing.T) {
+		writer := request("gindex=0")
+		assert.Equal(t, http.StatusBadRequest, writer.Code)
+	})
+}
+
+func decodeRoots(t *testing.T, hexRoots []string) [][32]byte {
+	roots := make([][32]byte, len(hexRoots))
+	for i, h := range hexRoots {
+		b, err := hexutil.Decode(h)
+		require.NoError(t, err)
+		copy(roots[i][:], b)
+	}
+	return roots
+}
+
+func TestGetForkChoiceHistory(t *testing.T) {
+	t.Run("recorder not enabled", func(t *testing.T) {
+		s := &Server{ForkchoiceFetcher: &blockchainmock.ChainService{ForkChoiceStore: doublylinkedtree.New()}}
+		request := httptest.NewRequest(http.MethodGet, "http://example.com/eth/v1/debug/fork_choice/history", nil)
+		writer := httptest.NewRecorder()
+		writer.Body = &bytes.Buffer{}
+
+		s.GetForkChoiceHistory(writer, request)
+		assert.Equal(t, http.StatusNotFound, writer.Code)
+	})
+
+	resetCfg := features.InitWithReset(&features.Flags{EnableForkChoiceRecorder: true, ForkChoiceRecorderSize: 16})
+	defer resetCfg()
+	s := &Server{ForkchoiceFetcher: &blockchainmock.ChainService{ForkChoiceStore: doublylinkedtree.New()}}
+
+	t.Run("json", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "http://example.com/eth/v1/debug/fork_choice/history", nil)
+		writer := httptest.NewRecorder()
+		writer.Body = &bytes.Buffer{}
+
+		s.GetForkChoiceHistory(writer, request)
+		require.Equal(t, http.StatusOK, writer.Code)
+		assert.Equal(t, "application/json", writer.Header().Get("Content-Type"))
+		resp := struct {
+			Capacity int           `json:"capacity"`
+			Events   []interface{} `json:"events"`
+		}{}
+		require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &resp))
+		assert.Equal(t, 16, resp.Capacity)
+		assert.Equal(t, 0, len(resp.Events))
+	})
+	t.Run("dot", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "http://example.com/eth/v1/debug/fork_choice/history?format=dot", nil)
+		writer := httptest.NewRecorder()
+		writer.Body = &bytes.Buffer{}
+
+		s.GetForkChoiceHistory(writer, request)
+		require.Equal(t, http.StatusOK, writer.Code)
+		assert.Equal(t, "text/vnd.graphviz", writer.Header().Get("Content-Type"))
+		assert.StringContains(t, "digraph forkchoice {", writer.Body.String())
+	})
+	t.Run("unsupported format", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "http://example.com/eth/v1/debug/fork_choice/history?format=svg", nil)
+		writer := httptest.NewRecorder()
+		writer.Body = &bytes.Buffer{}
+
+		s.GetForkChoiceHistory(writer, request)
+		assert.Equal(t, http.StatusBadRequest, writer.Code)
+		e := &network.DefaultErrorJson{}
+		require.NoError(t, json.Unmarshal(writer.Body.Bytes(), e))
+		assert.StringContains(t, "unsupported format svg", e.Message)
+	})
+}
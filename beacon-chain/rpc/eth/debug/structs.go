@@ -0,0 +1,14 @@
+package debug
+
+type StateProofResponse struct {
+	Data                *StateProof `json:"data"`
+	ExecutionOptimistic bool        `json:"execution_optimistic"`
+	Finalized           bool        `json:"finalized"`
+}
+
+type StateProof struct {
+	StateRoot string   `json:"state_root" hex:"true"`
+	Gindices  []string `json:"gindices"`
+	Leaves    []string `json:"leaves" hex:"true"`
+	Proof     []string `json:"proof" hex:"true"`
+}
@@ -4,23 +4,26 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
 	"github.com/theQRL/qrysm/v4/beacon-chain/core/altair"
 	coreblocks "github.com/theQRL/qrysm/v4/beacon-chain/core/blocks"
 	"github.com/theQRL/qrysm/v4/beacon-chain/core/epoch/precompute"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/helpers"
 	"github.com/theQRL/qrysm/v4/beacon-chain/core/validators"
 	"github.com/theQRL/qrysm/v4/beacon-chain/rpc/lookup"
 	"github.com/theQRL/qrysm/v4/beacon-chain/state"
-	fieldparams "github.com/theQRL/qrysm/v4/config/fieldparams"
 	"github.com/theQRL/qrysm/v4/config/params"
 	"github.com/theQRL/qrysm/v4/consensus-types/blocks"
 	"github.com/theQRL/qrysm/v4/consensus-types/interfaces"
 	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
 	bytesutil2 "github.com/theQRL/qrysm/v4/encoding/bytesutil"
 	"github.com/theQRL/qrysm/v4/network"
+	zond "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
 	"github.com/theQRL/qrysm/v4/runtime/version"
 	"github.com/theQRL/qrysm/v4/time/slots"
 	"github.com/wealdtech/go-bytesutil"
@@ -68,6 +71,33 @@ func (s *Server) BlockRewards(w http.ResponseWriter, r *http.Request) {
 		network.WriteError(w, errJson)
 		return
 	}
+	prevParticipation, err := st.PreviousEpochParticipation()
+	if err != nil {
+		errJson := &network.DefaultErrorJson{
+			Message: "Could not get previous epoch participation: " + err.Error(),
+			Code:    http.StatusInternalServerError,
+		}
+		network.WriteError(w, errJson)
+		return
+	}
+	currParticipation, err := st.CurrentEpochParticipation()
+	if err != nil {
+		errJson := &network.DefaultErrorJson{
+			Message: "Could not get current epoch participation: " + err.Error(),
+			Code:    http.StatusInternalServerError,
+		}
+		network.WriteError(w, errJson)
+		return
+	}
+	totalBalance, err := helpers.TotalActiveBalance(st)
+	if err != nil {
+		errJson := &network.DefaultErrorJson{
+			Message: "Could not get total active balance: " + err.Error(),
+			Code:    http.StatusInternalServerError,
+		}
+		network.WriteError(w, errJson)
+		return
+	}
 	st, err = altair.ProcessAttestationsNoVerifySignature(r.Context(), st, blk)
 	if err != nil {
 		errJson := &network.DefaultErrorJson{
@@ -77,6 +107,15 @@ func (s *Server) BlockRewards(w http.ResponseWriter, r *http.Request) {
 		network.WriteError(w, errJson)
 		return
 	}
+	attesterRewards, err := attestationRewardsByAttester(st, prevParticipation, currParticipation, totalBalance)
+	if err != nil {
+		errJson := &network.DefaultErrorJson{
+			Message: "Could not get attestation rewards by attester: " + err.Error(),
+			Code:    http.StatusInternalServerError,
+		}
+		network.WriteError(w, errJson)
+		return
+	}
 	attBalance, err := st.BalanceAtIndex(proposerIndex)
 	if err != nil {
 		errJson := &network.DefaultErrorJson{
@@ -131,6 +170,15 @@ func (s *Server) BlockRewards(w http.ResponseWriter, r *http.Request) {
 		network.WriteError(w, errJson)
 		return
 	}
+	participantRewards, err := syncAggregateRewardsByParticipant(st, sa)
+	if err != nil {
+		errJson := &network.DefaultErrorJson{
+			Message: "Could not get sync aggregate rewards by participant: " + err.Error(),
+			Code:    http.StatusInternalServerError,
+		}
+		network.WriteError(w, errJson)
+		return
+	}
 	var syncCommitteeReward uint64
 	_, syncCommitteeReward, err = altair.ProcessSyncAggregate(r.Context(), st, sa)
 	if err != nil {
@@ -169,6 +217,9 @@ func (s *Server) BlockRewards(w http.ResponseWriter, r *http.Request) {
 			SyncAggregate:     strconv.FormatUint(syncCommitteeReward, 10),
 			ProposerSlashings: strconv.FormatUint(proposerSlashingsBalance-attSlashingsBalance, 10),
 			AttesterSlashings: strconv.FormatUint(attSlashingsBalance-attBalance, 10),
+
+			AttestationsByAttester:     attesterRewards,
+			SyncAggregateByParticipant: participantRewards,
 		},
 		ExecutionOptimistic: optimistic,
 		Finalized:           s.FinalizationFetcher.IsFinalized(r.Context(), blkRoot),
@@ -227,6 +278,163 @@ func (s *Server) AttestationRewards(w http.ResponseWriter, r *http.Request) {
 	network.WriteJson(w, resp)
 }
 
+// SyncCommitteeRewards is an HTTP handler for Beacon API getSyncCommitteeRewards. It returns the rewards and
+// penalties of the sync committee members for their participation in the sync aggregate of the block. The
+// rewards can be limited to the validator indices or public keys in the request body.
+func (s *Server) SyncCommitteeRewards(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(r.URL.Path, "/")
+	blockId := segments[len(segments)-1]
+
+	blk, err := s.Blocker.Block(r.Context(), []byte(blockId))
+	if errJson := handleGetBlockError(blk, err); errJson != nil {
+		network.WriteError(w, errJson)
+		return
+	}
+	if blk.Version() == version.Phase0 {
+		errJson := &network.DefaultErrorJson{
+			Message: "Sync committee rewards are not supported for Phase 0",
+			Code:    http.StatusBadRequest,
+		}
+		network.WriteError(w, errJson)
+		return
+	}
+
+	st, err := s.ReplayerBuilder.ReplayerForSlot(blk.Block().Slot()-1).ReplayToSlot(r.Context(), blk.Block().Slot())
+	if err != nil {
+		errJson := &network.DefaultErrorJson{
+			Message: "Could not get state: " + err.Error(),
+			Code:    http.StatusInternalServerError,
+		}
+		network.WriteError(w, errJson)
+		return
+	}
+	sa, err := blk.Block().Body().SyncAggregate()
+	if err != nil {
+		errJson := &network.DefaultErrorJson{
+			Message: "Could not get sync aggregate: " + err.Error(),
+			Code:    http.StatusInternalServerError,
+		}
+		network.WriteError(w, errJson)
+		return
+	}
+	valIndices, ok := syncCommitteeRewardsIndices(w, r, st)
+	if !ok {
+		return
+	}
+
+	preBalances := make([]uint64, len(valIndices))
+	for i, valIdx := range valIndices {
+		preBalances[i], err = st.BalanceAtIndex(valIdx)
+		if err != nil {
+			errJson := &network.DefaultErrorJson{
+				Message: "Could not get validator's balance: " + err.Error(),
+				Code:    http.StatusInternalServerError,
+			}
+			network.WriteError(w, errJson)
+			return
+		}
+	}
+	st, proposerReward, err := altair.ProcessSyncAggregate(r.Context(), st, sa)
+	if err != nil {
+		errJson := &network.DefaultErrorJson{
+			Message: "Could not get sync aggregate rewards: " + err.Error(),
+			Code:    http.StatusInternalServerError,
+		}
+		network.WriteError(w, errJson)
+		return
+	}
+	proposerIndex := blk.Block().ProposerIndex()
+	rewards := make([]SyncCommitteeReward, len(valIndices))
+	for i, valIdx := range valIndices {
+		balance, err := st.BalanceAtIndex(valIdx)
+		if err != nil {
+			errJson := &network.DefaultErrorJson{
+				Message: "Could not get validator's balance: " + err.Error(),
+				Code:    http.StatusInternalServerError,
+			}
+			network.WriteError(w, errJson)
+			return
+		}
+		reward := int64(balance) - int64(preBalances[i])
+		// The proposer reward for including the sync aggregate is not a reward of the committee member.
+		if valIdx == proposerIndex {
+			reward -= int64(proposerReward)
+		}
+		rewards[i] = SyncCommitteeReward{
+			ValidatorIndex: strconv.FormatUint(uint64(valIdx), 10),
+			Reward:         strconv.FormatInt(reward, 10),
+		}
+	}
+
+	optimistic, err := s.OptimisticModeFetcher.IsOptimistic(r.Context())
+	if err != nil {
+		errJson := &network.DefaultErrorJson{
+			Message: "Could not get optimistic mode info: " + err.Error(),
+			Code:    http.StatusInternalServerError,
+		}
+		network.WriteError(w, errJson)
+		return
+	}
+	blkRoot, err := blk.Block().HashTreeRoot()
+	if err != nil {
+		errJson := &network.DefaultErrorJson{
+			Message: "Could not get block root: " + err.Error(),
+			Code:    http.StatusInternalServerError,
+		}
+		network.WriteError(w, errJson)
+		return
+	}
+
+	response := &SyncCommitteeRewardsResponse{
+		Data:                rewards,
+		ExecutionOptimistic: optimistic,
+		Finalized:           s.FinalizationFetcher.IsFinalized(r.Context(), blkRoot),
+	}
+	network.WriteJson(w, response)
+}
+
+// syncCommitteeRewardsIndices returns the members of the current sync committee, in committee order and
+// each once, limited to the validators requested in the request body.
+func syncCommitteeRewardsIndices(w http.ResponseWriter, r *http.Request, st state.BeaconState) ([]primitives.ValidatorIndex, bool) {
+	committee, err := st.CurrentSyncCommittee()
+	if err != nil || committee == nil {
+		errJson := &network.DefaultErrorJson{
+			Message: "Could not get current sync committee",
+			Code:    http.StatusInternalServerError,
+		}
+		network.WriteError(w, errJson)
+		return nil, false
+	}
+	requested, ok := validatorIndicesFromRequest(w, r, st)
+	if !ok {
+		return nil, false
+	}
+	filter := make(map[primitives.ValidatorIndex]bool, len(requested))
+	for _, valIdx := range requested {
+		filter[valIdx] = true
+	}
+
+	members := make([]primitives.ValidatorIndex, 0, len(committee.Pubkeys))
+	seen := make(map[primitives.ValidatorIndex]bool, len(committee.Pubkeys))
+	for _, pubkey := range committee.Pubkeys {
+		valIdx, ok := st.ValidatorIndexByPubkey(bytesutil2.ToBytes2592(pubkey))
+		if !ok {
+			errJson := &network.DefaultErrorJson{
+				Message: fmt.Sprintf("No validator index found for sync committee member %#x", pubkey),
+				Code:    http.StatusInternalServerError,
+			}
+			network.WriteError(w, errJson)
+			return nil, false
+		}
+		if seen[valIdx] || (len(requested) > 0 && !filter[valIdx]) {
+			continue
+		}
+		seen[valIdx] = true
+		members = append(members, valIdx)
+	}
+	return members, true
+}
+
 func (s *Server) attRewardsState(w http.ResponseWriter, r *http.Request) (state.BeaconState, bool) {
 	segments := strings.Split(r.URL.Path, "/")
 	requestedEpoch, err := strconv.ParseUint(segments[len(segments)-1], 10, 64)
@@ -299,15 +507,39 @@ func attRewardsBalancesAndVals(
 		network.WriteError(w, errJson)
 		return nil, nil, nil, false
 	}
+	valIndices, ok := validatorIndicesFromRequest(w, r, st)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	if len(valIndices) == 0 {
+		valIndices = make([]primitives.ValidatorIndex, len(allVals))
+		for i := 0; i < len(allVals); i++ {
+			valIndices[i] = primitives.ValidatorIndex(i)
+		}
+	}
+	if len(valIndices) == len(allVals) {
+		return bal, allVals, valIndices, true
+	} else {
+		filteredVals := make([]*precompute.Validator, len(valIndices))
+		for i, valIx := range valIndices {
+			filteredVals[i] = allVals[valIx]
+		}
+		return bal, filteredVals, valIndices, true
+	}
+}
+
+// validatorIndicesFromRequest decodes the validator indices or public keys in the request body. It returns
+// no indices if the request has no body.
+func validatorIndicesFromRequest(w http.ResponseWriter, r *http.Request, st state.BeaconState) ([]primitives.ValidatorIndex, bool) {
 	var rawValIds []string
 	if r.Body != http.NoBody {
-		if err = json.NewDecoder(r.Body).Decode(&rawValIds); err != nil {
+		if err := json.NewDecoder(r.Body).Decode(&rawValIds); err != nil {
 			errJson := &network.DefaultErrorJson{
 				Message: "Could not decode validators: " + err.Error(),
 				Code:    http.StatusBadRequest,
 			}
 			network.WriteError(w, errJson)
-			return nil, nil, nil, false
+			return nil, false
 		}
 	}
 	valIndices := make([]primitives.ValidatorIndex, len(rawValIds))
@@ -315,13 +547,13 @@ func attRewardsBalancesAndVals(
 		index, err := strconv.ParseUint(v, 10, 64)
 		if err != nil {
 			pubkey, err := bytesutil.FromHexString(v)
-			if err != nil || len(pubkey) != fieldparams.BLSPubkeyLength {
+			if err != nil || len(pubkey) != dilithium2.CryptoPublicKeyBytes {
 				errJson := &network.DefaultErrorJson{
 					Message: fmt.Sprintf("%s is not a validator index or pubkey", v),
 					Code:    http.StatusBadRequest,
 				}
 				network.WriteError(w, errJson)
-				return nil, nil, nil, false
+				return nil, false
 			}
 			var ok bool
 			valIndices[i], ok = st.ValidatorIndexByPubkey(bytesutil2.ToBytes2592(pubkey))
@@ -331,7 +563,7 @@ func attRewardsBalancesAndVals(
 					Code:    http.StatusBadRequest,
 				}
 				network.WriteError(w, errJson)
-				return nil, nil, nil, false
+				return nil, false
 			}
 		} else {
 			if index >= uint64(st.NumValidators()) {
@@ -340,26 +572,12 @@ func attRewardsBalancesAndVals(
 					Code:    http.StatusBadRequest,
 				}
 				network.WriteError(w, errJson)
-				return nil, nil, nil, false
+				return nil, false
 			}
 			valIndices[i] = primitives.ValidatorIndex(index)
 		}
 	}
-	if len(valIndices) == 0 {
-		valIndices = make([]primitives.ValidatorIndex, len(allVals))
-		for i := 0; i < len(allVals); i++ {
-			valIndices[i] = primitives.ValidatorIndex(i)
-		}
-	}
-	if len(valIndices) == len(allVals) {
-		return bal, allVals, valIndices, true
-	} else {
-		filteredVals := make([]*precompute.Validator, len(valIndices))
-		for i, valIx := range valIndices {
-			filteredVals[i] = allVals[valIx]
-		}
-		return bal, filteredVals, valIndices, true
-	}
+	return valIndices, true
 }
 
 // idealAttRewards returns rewards for hypothetical, perfectly voting validators
@@ -454,6 +672,117 @@ func totalAttRewards(
 	return totalRewards, true
 }
 
+// attestationRewardsByAttester returns the proposer reward earned for every attester whose participation
+// flags were set by the attestations of the block, given the participation before the attestations were
+// processed. Each reward is rounded down on its own, so the rewards may add up to slightly less than the
+// attestations reward of the block.
+func attestationRewardsByAttester(st state.BeaconState, prevParticipation, currParticipation []byte, totalBalance uint64) ([]ProposerRewardShare, error) {
+	cfg := params.BeaconConfig()
+	postPrevParticipation, err := st.PreviousEpochParticipation()
+	if err != nil {
+		return nil, err
+	}
+	postCurrParticipation, err := st.CurrentEpochParticipation()
+	if err != nil {
+		return nil, err
+	}
+	flagWeights := map[uint8]uint64{
+		cfg.TimelySourceFlagIndex: cfg.TimelySourceWeight,
+		cfg.TimelyTargetFlagIndex: cfg.TimelyTargetWeight,
+		cfg.TimelyHeadFlagIndex:   cfg.TimelyHeadWeight,
+	}
+	numerators := make(map[primitives.ValidatorIndex]uint64)
+	for _, p := range [][2][]byte{{prevParticipation, postPrevParticipation}, {currParticipation, postCurrParticipation}} {
+		pre, post := p[0], p[1]
+		for i := range post {
+			if i < len(pre) && pre[i] == post[i] {
+				continue
+			}
+			var weight uint64
+			for flag, w := range flagWeights {
+				hadFlag := false
+				if i < len(pre) {
+					if hadFlag, err = altair.HasValidatorFlag(pre[i], flag); err != nil {
+						return nil, err
+					}
+				}
+				hasFlag, err := altair.HasValidatorFlag(post[i], flag)
+				if err != nil {
+					return nil, err
+				}
+				if hasFlag && !hadFlag {
+					weight += w
+				}
+			}
+			if weight == 0 {
+				continue
+			}
+			br, err := altair.BaseRewardWithTotalBalance(st, primitives.ValidatorIndex(i), totalBalance)
+			if err != nil {
+				return nil, err
+			}
+			numerators[primitives.ValidatorIndex(i)] += br * weight
+		}
+	}
+	d := (cfg.WeightDenominator - cfg.ProposerWeight) * cfg.WeightDenominator / cfg.ProposerWeight
+	for valIdx := range numerators {
+		numerators[valIdx] /= d
+	}
+	return proposerRewardShares(numerators), nil
+}
+
+// syncAggregateRewardsByParticipant returns the proposer reward earned for including the sync committee
+// messages of every participant of the sync aggregate. A validator may hold several positions in the
+// sync committee, in which case it earns the proposer a reward for each of them.
+func syncAggregateRewardsByParticipant(st state.BeaconState, sa *zond.SyncAggregate) ([]ProposerRewardShare, error) {
+	committee, err := st.CurrentSyncCommittee()
+	if err != nil {
+		return nil, err
+	}
+	if committee == nil {
+		return nil, errors.New("nil current sync committee in state")
+	}
+	activeBalance, err := helpers.TotalActiveBalance(st)
+	if err != nil {
+		return nil, err
+	}
+	proposerReward, _, err := altair.SyncRewards(activeBalance)
+	if err != nil {
+		return nil, err
+	}
+	rewards := make(map[primitives.ValidatorIndex]uint64)
+	for i := uint64(0); i < sa.SyncCommitteeBits.Len() && i < uint64(len(committee.Pubkeys)); i++ {
+		if !sa.SyncCommitteeBits.BitAt(i) {
+			continue
+		}
+		valIdx, ok := st.ValidatorIndexByPubkey(bytesutil2.ToBytes2592(committee.Pubkeys[i]))
+		if !ok {
+			return nil, errors.New("validator public key does not exist in state")
+		}
+		rewards[valIdx] += proposerReward
+	}
+	return proposerRewardShares(rewards), nil
+}
+
+// proposerRewardShares returns the rewards ordered by validator index.
+func proposerRewardShares(rewards map[primitives.ValidatorIndex]uint64) []ProposerRewardShare {
+	indices := make([]primitives.ValidatorIndex, 0, len(rewards))
+	for valIdx := range rewards {
+		indices = append(indices, valIdx)
+	}
+	sort.Slice(indices, func(i, j int) bool {
+		return indices[i] < indices[j]
+	})
+	shares := make([]ProposerRewardShare, len(indices))
+	for i, valIdx := range indices {
+		shares[i] = ProposerRewardShare{
+			ValidatorIndex: strconv.FormatUint(uint64(valIdx), 10),
+			Reward:         strconv.FormatUint(rewards[valIdx], 10),
+		}
+	}
+	return shares
+}
+
 func handleGetBlockError(blk interfaces.ReadOnlySignedBeaconBlock, err error) *network.DefaultErrorJson {
 	if errors.Is(err, lookup.BlockIdParseError{}) {
 		return &network.DefaultErrorJson{
@@ -15,6 +15,7 @@ import (
 	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
 	mock "github.com/theQRL/qrysm/v4/beacon-chain/blockchain/testing"
 	"github.com/theQRL/qrysm/v4/beacon-chain/core/altair"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/helpers"
 	"github.com/theQRL/qrysm/v4/beacon-chain/core/signing"
 	"github.com/theQRL/qrysm/v4/beacon-chain/rpc/testutil"
 	"github.com/theQRL/qrysm/v4/beacon-chain/state"
@@ -26,6 +27,7 @@ import (
 	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
 	"github.com/theQRL/qrysm/v4/crypto/bls"
 	"github.com/theQRL/qrysm/v4/crypto/bls/blst"
+	"github.com/theQRL/qrysm/v4/crypto/dilithium"
 	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
 	"github.com/theQRL/qrysm/v4/network"
 	zond "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
@@ -401,10 +403,29 @@ func TestAttestationRewards(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, e.Code)
 		assert.Equal(t, "foo is not a validator index or pubkey", e.Message)
 	})
+	t.Run("pubkey of wrong length", func(t *testing.T) {
+		url := "http://only.the.epoch.number.at.the.end.is.important/1"
+		var body bytes.Buffer
+		pubkey := fmt.Sprintf("%#x", make([]byte, fieldparams.BLSPubkeyLength))
+		valIds, err := json.Marshal([]string{"10", pubkey})
+		require.NoError(t, err)
+		_, err = body.Write(valIds)
+		require.NoError(t, err)
+		request := httptest.NewRequest("POST", url, &body)
+		writer := httptest.NewRecorder()
+		writer.Body = &bytes.Buffer{}
+
+		s.AttestationRewards(writer, request)
+		assert.Equal(t, http.StatusBadRequest, writer.Code)
+		e := &network.DefaultErrorJson{}
+		require.NoError(t, json.Unmarshal(writer.Body.Bytes(), e))
+		assert.Equal(t, http.StatusBadRequest, e.Code)
+		assert.Equal(t, pubkey+" is not a validator index or pubkey", e.Message)
+	})
 	t.Run("unknown validator pubkey", func(t *testing.T) {
 		url := "http://only.the.epoch.number.at.the.end.is.important/1"
 		var body bytes.Buffer
-		privkey, err := bls.RandKey()
+		privkey, err := dilithium.RandKey()
 		require.NoError(t, err)
 		pubkey := fmt.Sprintf("%#x", privkey.PublicKey().Marshal())
 		valIds, err := json.Marshal([]string{"10", pubkey})
@@ -480,3 +501,207 @@ func TestAttestationRewards(t *testing.T) {
 		assert.Equal(t, "Attestation rewards are available after two epoch transitions to ensure all attestations have a chance of inclusion", e.Message)
 	})
 }
+
+func TestSyncCommitteeRewards(t *testing.T) {
+	valCount := 64
+
+	st, err := util.NewBeaconStateCapella()
+	require.NoError(t, err)
+	require.NoError(t, st.SetSlot(1))
+	validators := make([]*zond.Validator, 0, valCount)
+	balances := make([]uint64, 0, valCount)
+	secretKeys := make([]dilithium.DilithiumKey, 0, valCount)
+	for i := 0; i < valCount; i++ {
+		key, err := dilithium.RandKey()
+		require.NoError(t, err)
+		secretKeys = append(secretKeys, key)
+		validators = append(validators, &zond.Validator{
+			PublicKey:         key.PublicKey().Marshal(),
+			ExitEpoch:         params.BeaconConfig().FarFutureEpoch,
+			WithdrawableEpoch: params.BeaconConfig().FarFutureEpoch,
+			EffectiveBalance:  params.BeaconConfig().MaxEffectiveBalance,
+		})
+		balances = append(balances, params.BeaconConfig().MaxEffectiveBalance)
+	}
+	require.NoError(t, st.SetValidators(validators))
+	require.NoError(t, st.SetBalances(balances))
+	syncCommittee, err := altair.NextSyncCommittee(context.Background(), st)
+	require.NoError(t, err)
+	require.NoError(t, st.SetCurrentSyncCommittee(syncCommittee))
+	slot0bRoot := bytesutil.PadTo([]byte("slot0root"), 32)
+	bRoots := make([][]byte, fieldparams.BlockRootsLength)
+	bRoots[0] = slot0bRoot
+	require.NoError(t, st.SetBlockRoots(bRoots))
+	proposerIndex, err := helpers.BeaconProposerIndex(context.Background(), st)
+	require.NoError(t, err)
+
+	committeeIndices := make([]primitives.ValidatorIndex, len(syncCommittee.Pubkeys))
+	for i, pubkey := range syncCommittee.Pubkeys {
+		idx, ok := st.ValidatorIndexByPubkey(bytesutil.ToBytes2592(pubkey))
+		require.Equal(t, true, ok)
+		committeeIndices[i] = idx
+	}
+
+	scBits := bitfield.NewBitvector512()
+	scBits.SetBitAt(10, true)
+	scBits.SetBitAt(100, true)
+	domain, err := signing.Domain(st.Fork(), 0, params.BeaconConfig().DomainSyncCommittee, st.GenesisValidatorsRoot())
+	require.NoError(t, err)
+	sszBytes := primitives.SSZBytes(slot0bRoot)
+	r, err := signing.ComputeSigningRoot(&sszBytes, domain)
+	require.NoError(t, err)
+	var sig []byte
+	sig = append(sig, secretKeys[committeeIndices[10]].Sign(r[:]).Marshal()...)
+	sig = append(sig, secretKeys[committeeIndices[100]].Sign(r[:]).Marshal()...)
+
+	b := util.HydrateSignedBeaconBlockAltair(util.NewBeaconBlockAltair())
+	b.Block.Slot = 2
+	b.Block.ProposerIndex = proposerIndex
+	b.Block.Body.SyncAggregate = &zond.SyncAggregate{SyncCommitteeBits: scBits, SyncCommitteeSignature: sig}
+	sbb, err := blocks.NewSignedBeaconBlock(b)
+	require.NoError(t, err)
+	phase0block, err := blocks.NewSignedBeaconBlock(util.NewBeaconBlock())
+	require.NoError(t, err)
+
+	activeBalance, err := helpers.TotalActiveBalance(st)
+	require.NoError(t, err)
+	proposerReward, participantReward, err := altair.SyncRewards(activeBalance)
+	require.NoError(t, err)
+	expected := make(map[primitives.ValidatorIndex]int64)
+	for i, idx := range committeeIndices {
+		if scBits.BitAt(uint64(i)) {
+			expected[idx] += int64(participantReward)
+		} else {
+			expected[idx] -= int64(participantReward)
+		}
+	}
+
+	mockChainService := &mock.ChainService{Optimistic: true}
+	newServer := func() *Server {
+		// The sync aggregate is processed on top of the replayed state, so each request needs a fresh copy.
+		return &Server{
+			Blocker: &testutil.MockBlocker{SlotBlockMap: map[primitives.Slot]interfaces.ReadOnlySignedBeaconBlock{
+				0: phase0block,
+				2: sbb,
+			}},
+			OptimisticModeFetcher: mockChainService,
+			FinalizationFetcher:   mockChainService,
+			ReplayerBuilder:       mockstategen.NewMockReplayerBuilder(mockstategen.WithMockState(st.Copy())),
+		}
+	}
+
+	t.Run("ok - all members", func(t *testing.T) {
+		url := "http://only.the.slot.number.at.the.end.is.important/2"
+		request := httptest.NewRequest("POST", url, nil)
+		writer := httptest.NewRecorder()
+		writer.Body = &bytes.Buffer{}
+
+		newServer().SyncCommitteeRewards(writer, request)
+		assert.Equal(t, http.StatusOK, writer.Code)
+		resp := &SyncCommitteeRewardsResponse{}
+		require.NoError(t, json.Unmarshal(writer.Body.Bytes(), resp))
+		require.Equal(t, len(expected), len(resp.Data))
+		for _, reward := range resp.Data {
+			idx, err := strconv.ParseUint(reward.ValidatorIndex, 10, 64)
+			require.NoError(t, err)
+			assert.Equal(t, strconv.FormatInt(expected[primitives.ValidatorIndex(idx)], 10), reward.Reward)
+		}
+		assert.Equal(t, strconv.FormatUint(uint64(committeeIndices[0]), 10), resp.Data[0].ValidatorIndex)
+		assert.Equal(t, true, resp.ExecutionOptimistic)
+		assert.Equal(t, false, resp.Finalized)
+	})
+	t.Run("ok - filtered vals", func(t *testing.T) {
+		url := "http://only.the.slot.number.at.the.end.is.important/2"
+		var body bytes.Buffer
+		pubkey := fmt.Sprintf("%#x", secretKeys[committeeIndices[100]].PublicKey().Marshal())
+		valIds, err := json.Marshal([]string{strconv.FormatUint(uint64(committeeIndices[10]), 10), pubkey})
+		require.NoError(t, err)
+		_, err = body.Write(valIds)
+		require.NoError(t, err)
+		request := httptest.NewRequest("POST", url, &body)
+		writer := httptest.NewRecorder()
+		writer.Body = &bytes.Buffer{}
+
+		newServer().SyncCommitteeRewards(writer, request)
+		assert.Equal(t, http.StatusOK, writer.Code)
+		resp := &SyncCommitteeRewardsResponse{}
+		require.NoError(t, json.Unmarshal(writer.Body.Bytes(), resp))
+		want := map[primitives.ValidatorIndex]bool{committeeIndices[10]: true, committeeIndices[100]: true}
+		require.Equal(t, len(want), len(resp.Data))
+		for _, reward := range resp.Data {
+			idx, err := strconv.ParseUint(reward.ValidatorIndex, 10, 64)
+			require.NoError(t, err)
+			assert.Equal(t, true, want[primitives.ValidatorIndex(idx)])
+			assert.Equal(t, strconv.FormatInt(expected[primitives.ValidatorIndex(idx)], 10), reward.Reward)
+		}
+	})
+	t.Run("phase 0", func(t *testing.T) {
+		url := "http://only.the.slot.number.at.the.end.is.important/0"
+		request := httptest.NewRequest("POST", url, nil)
+		writer := httptest.NewRecorder()
+		writer.Body = &bytes.Buffer{}
+
+		newServer().SyncCommitteeRewards(writer, request)
+		assert.Equal(t, http.StatusBadRequest, writer.Code)
+		e := &network.DefaultErrorJson{}
+		require.NoError(t, json.Unmarshal(writer.Body.Bytes(), e))
+		assert.Equal(t, "Sync committee rewards are not supported for Phase 0", e.Message)
+	})
+	t.Run("sync aggregate breakdown", func(t *testing.T) {
+		shares, err := syncAggregateRewardsByParticipant(st, b.Block.Body.SyncAggregate)
+		require.NoError(t, err)
+		want := make(map[primitives.ValidatorIndex]uint64)
+		want[committeeIndices[10]] += proposerReward
+		want[committeeIndices[100]] += proposerReward
+		require.Equal(t, len(want), len(shares))
+		for _, share := range shares {
+			idx, err := strconv.ParseUint(share.ValidatorIndex, 10, 64)
+			require.NoError(t, err)
+			assert.Equal(t, strconv.FormatUint(want[primitives.ValidatorIndex(idx)], 10), share.Reward)
+		}
+	})
+}
+
+func TestAttestationRewardsByAttester(t *testing.T) {
+	valCount := 64
+
+	st, err := util.NewBeaconStateCapella()
+	require.NoError(t, err)
+	validators := make([]*zond.Validator, valCount)
+	balances := make([]uint64, valCount)
+	for i := range validators {
+		validators[i] = &zond.Validator{
+			PublicKey:         make([]byte, dilithium2.CryptoPublicKeyBytes),
+			ExitEpoch:         params.BeaconConfig().FarFutureEpoch,
+			WithdrawableEpoch: params.BeaconConfig().FarFutureEpoch,
+			EffectiveBalance:  params.BeaconConfig().MaxEffectiveBalance,
+		}
+		balances[i] = params.BeaconConfig().MaxEffectiveBalance
+	}
+	require.NoError(t, st.SetValidators(validators))
+	require.NoError(t, st.SetBalances(balances))
+	prevParticipation := make([]byte, valCount)
+	prevParticipation[3] = 0b001
+	currParticipation := make([]byte, valCount)
+	postPrevParticipation := make([]byte, valCount)
+	postPrevParticipation[3] = 0b011
+	postCurrParticipation := make([]byte, valCount)
+	postCurrParticipation[1] = 0b111
+	require.NoError(t, st.SetPreviousParticipationBits(postPrevParticipation))
+	require.NoError(t, st.SetCurrentParticipationBits(postCurrParticipation))
+	totalBalance, err := helpers.TotalActiveBalance(st)
+	require.NoError(t, err)
+
+	shares, err := attestationRewardsByAttester(st, prevParticipation, currParticipation, totalBalance)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(shares))
+	cfg := params.BeaconConfig()
+	br, err := altair.BaseRewardWithTotalBalance(st, 1, totalBalance)
+	require.NoError(t, err)
+	d := (cfg.WeightDenominator - cfg.ProposerWeight) * cfg.WeightDenominator / cfg.ProposerWeight
+	assert.Equal(t, "1", shares[0].ValidatorIndex)
+	assert.Equal(t, strconv.FormatUint(br*(cfg.TimelySourceWeight+cfg.TimelyTargetWeight+cfg.TimelyHeadWeight)/d, 10), shares[0].Reward)
+	// Only the target flag was newly set for validator 3.
+	assert.Equal(t, "3", shares[1].ValidatorIndex)
+	assert.Equal(t, strconv.FormatUint(br*cfg.TimelyTargetWeight/d, 10), shares[1].Reward)
+}
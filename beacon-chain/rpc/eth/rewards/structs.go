@@ -13,6 +13,16 @@ type BlockRewards struct {
 	SyncAggregate     string `json:"sync_aggregate"`
 	ProposerSlashings string `json:"proposer_slashings"`
 	AttesterSlashings string `json:"attester_slashings"`
+	// AttestationsByAttester breaks the attestations reward down by the attesters whose votes the block included.
+	AttestationsByAttester []ProposerRewardShare `json:"attestations_by_attester"`
+	// SyncAggregateByParticipant breaks the sync aggregate reward down by the participating sync committee members.
+	SyncAggregateByParticipant []ProposerRewardShare `json:"sync_aggregate_by_participant"`
+}
+
+// ProposerRewardShare is the part of the proposer reward of a block earned by including the vote of a validator.
+type ProposerRewardShare struct {
+	ValidatorIndex string `json:"validator_index"`
+	Reward         string `json:"reward"`
 }
 
 type AttestationRewardsResponse struct {
@@ -40,3 +50,14 @@ type TotalAttestationReward struct {
 	Source         string `json:"source"`
 	InclusionDelay string `json:"inclusion_delay"`
 }
+
+type SyncCommitteeRewardsResponse struct {
+	Data                []SyncCommitteeReward `json:"data"`
+	ExecutionOptimistic bool                  `json:"execution_optimistic"`
+	Finalized           bool                  `json:"finalized"`
+}
+
+type SyncCommitteeReward struct {
+	ValidatorIndex string `json:"validator_index"`
+	Reward         string `json:"reward"`
+}
@@ -2,6 +2,7 @@ package validator
 
 import (
 	"github.com/theQRL/qrysm/v4/beacon-chain/blockchain"
+	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositcache"
 	"github.com/theQRL/qrysm/v4/beacon-chain/sync"
 )
 
@@ -11,4 +12,6 @@ type Server struct {
 	GenesisTimeFetcher blockchain.TimeFetcher
 	SyncChecker        sync.Checker
 	HeadFetcher        blockchain.HeadFetcher
+	ValidatorMonitor   ValidatorMonitor
+	DepositFetcher     depositcache.DepositFetcher
 }
@@ -0,0 +1,238 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/go-zond/common/hexutil"
+	"github.com/theQRL/qrysm/v4/beacon-chain/monitor"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	"github.com/theQRL/qrysm/v4/network"
+)
+
+const (
+	// maxMonitoredValidatorsRequestSize bounds the size of the body of a request changing the
+	// tracked validators.
+	maxMonitoredValidatorsRequestSize = 1 << 20
+	// maxMonitoredValidatorsPerRequest bounds the number of validators added or removed by a request.
+	maxMonitoredValidatorsPerRequest = 128
+)
+
+// ValidatorMonitor tracks the performance of a set of validators which can be changed at runtime.
+type ValidatorMonitor interface {
+	TrackValidators(ctx context.Context, indices []primitives.ValidatorIndex, pubkeys [][dilithium2.CryptoPublicKeyBytes]byte) error
+	UntrackValidators(ctx context.Context, indices []primitives.ValidatorIndex, pubkeys [][dilithium2.CryptoPublicKeyBytes]byte) error
+	TrackedValidatorsList() ([]primitives.ValidatorIndex, [][dilithium2.CryptoPublicKeyBytes]byte)
+	Performance() []*monitor.TrackedValidatorPerformance
+}
+
+type MonitoredValidatorsRequest struct {
+	Indices    []string `json:"indices"`
+	PublicKeys []string `json:"public_keys"`
+}
+
+type MonitoredValidatorsResponse struct {
+	Indices           []string `json:"indices"`
+	PendingPublicKeys []string `json:"pending_public_keys"`
+}
+
+type MonitoredValidatorsPerformanceResponse struct {
+	Data []*MonitoredValidatorPerformance `json:"data"`
+}
+
+type MonitoredValidatorPerformance struct {
+	ValidatorIndex string                      `json:"validator_index"`
+	Latest         *LatestValidatorPerformance `json:"latest"`
+	Aggregated     *AggregatedPerformance      `json:"aggregated"`
+}
+
+type LatestValidatorPerformance struct {
+	AttestedSlot  string `json:"attested_slot"`
+	InclusionSlot string `json:"inclusion_slot"`
+	TimelySource  bool   `json:"timely_source"`
+	TimelyTarget  bool   `json:"timely_target"`
+	TimelyHead    bool   `json:"timely_head"`
+	Balance       string `json:"balance"`
+	BalanceChange string `json:"balance_change"`
+}
+
+type AggregatedPerformance struct {
+	StartEpoch                      string `json:"start_epoch"`
+	StartBalance                    string `json:"start_balance"`
+	TotalAttestedCount              string `json:"total_attested_count"`
+	TotalRequestedCount             string `json:"total_requested_count"`
+	TotalDistance                   string `json:"total_distance"`
+	TotalCorrectSource              string `json:"total_correct_source"`
+	TotalCorrectTarget              string `json:"total_correct_target"`
+	TotalCorrectHead                string `json:"total_correct_head"`
+	TotalProposedCount              string `json:"total_proposed_count"`
+	TotalAggregations               string `json:"total_aggregations"`
+	TotalSyncCommitteeContributions string `json:"total_sync_committee_contributions"`
+	TotalSyncCommitteeAggregations  string `json:"total_sync_committee_aggregations"`
+}
+
+// ListMonitoredValidators returns the validators tracked by the validator monitor: the tracked
+// validator indices, and the public keys of tracked validators whose deposit is not processed yet.
+func (vs *Server) ListMonitoredValidators(w http.ResponseWriter, _ *http.Request) {
+	network.WriteJson(w, vs.monitoredValidators())
+}
+
+// AddMonitoredValidators starts tracking the validators of the given indices and public keys in
+// the validator monitor. The tracked validators are kept across restarts.
+func (vs *Server) AddMonitoredValidators(w http.ResponseWriter, r *http.Request) {
+	indices, pubkeys, ok := vs.decodeMonitoredValidatorsRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := vs.ValidatorMonitor.TrackValidators(r.Context(), indices, pubkeys); err != nil {
+		handleHTTPError(w, "Could not track validators: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	network.WriteJson(w, vs.monitoredValidators())
+}
+
+// RemoveMonitoredValidators stops tracking the validators of the given indices and public keys in
+// the validator monitor.
+func (vs *Server) RemoveMonitoredValidators(w http.ResponseWriter, r *http.Request) {
+	indices, pubkeys, ok := vs.decodeMonitoredValidatorsRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := vs.ValidatorMonitor.UntrackValidators(r.Context(), indices, pubkeys); err != nil {
+		handleHTTPError(w, "Could not untrack validators: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	network.WriteJson(w, vs.monitoredValidators())
+}
+
+// GetMonitoredValidatorsPerformance returns the latest performance of the validators tracked by
+// the validator monitor, and their performance aggregated since they are tracked.
+func (vs *Server) GetMonitoredValidatorsPerformance(w http.ResponseWriter, _ *http.Request) {
+	perfs := vs.ValidatorMonitor.Performance()
+	data := make([]*MonitoredValidatorPerformance, len(perfs))
+	for i, p := range perfs {
+		data[i] = &MonitoredValidatorPerformance{
+			ValidatorIndex: strconv.FormatUint(uint64(p.ValidatorIndex), 10),
+			Latest: &LatestValidatorPerformance{
+				AttestedSlot:  strconv.FormatUint(uint64(p.AttestedSlot), 10),
+				InclusionSlot: strconv.FormatUint(uint64(p.InclusionSlot), 10),
+				TimelySource:  p.TimelySource,
+				TimelyTarget:  p.TimelyTarget,
+				TimelyHead:    p.TimelyHead,
+				Balance:       strconv.FormatUint(p.Balance, 10),
+				BalanceChange: strconv.FormatInt(p.BalanceChange, 10),
+			},
+			Aggregated: &AggregatedPerformance{
+				StartEpoch:                      strconv.FormatUint(uint64(p.StartEpoch), 10),
+				StartBalance:                    strconv.FormatUint(p.StartBalance, 10),
+				TotalAttestedCount:              strconv.FormatUint(p.TotalAttestedCount, 10),
+				TotalRequestedCount:             strconv.FormatUint(p.TotalRequestedCount, 10),
+				TotalDistance:                   strconv.FormatUint(p.TotalDistance, 10),
+				TotalCorrectSource:              strconv.FormatUint(p.TotalCorrectSource, 10),
+				TotalCorrectTarget:              strconv.FormatUint(p.TotalCorrectTarget, 10),
+				TotalCorrectHead:                strconv.FormatUint(p.TotalCorrectHead, 10),
+				TotalProposedCount:              strconv.FormatUint(p.TotalProposedCount, 10),
+				TotalAggregations:               strconv.FormatUint(p.TotalAggregations, 10),
+				TotalSyncCommitteeContributions: strconv.FormatUint(p.TotalSyncCommitteeContributions, 10),
+				TotalSyncCommitteeAggregations:  strconv.FormatUint(p.TotalSyncCommitteeAggregations, 10),
+			},
+		}
+	}
+	network.WriteJson(w, &MonitoredValidatorsPerformanceResponse{Data: data})
+}
+
+func (vs *Server) monitoredValidators() *MonitoredValidatorsResponse {
+	indices, pubkeys := vs.ValidatorMonitor.TrackedValidatorsList()
+	resp := &MonitoredValidatorsResponse{
+		Indices:           make([]string, len(indices)),
+		PendingPublicKeys: make([]string, len(pubkeys)),
+	}
+	for i, idx := range indices {
+		resp.Indices[i] = strconv.FormatUint(uint64(idx), 10)
+	}
+	for i := range pubkeys {
+		resp.PendingPublicKeys[i] = hexutil.Encode(pubkeys[i][:])
+	}
+	return resp
+}
+
+// decodeMonitoredValidatorsRequest decodes the validator indices and public keys of the request
+// body, and writes an error response if they are invalid. Validators must be known to the head
+// state, or have a deposit which is not processed yet.
+func (vs *Server) decodeMonitoredValidatorsRequest(
+	w http.ResponseWriter,
+	r *http.Request,
+) ([]primitives.ValidatorIndex, [][dilithium2.CryptoPublicKeyBytes]byte, bool) {
+	var req MonitoredValidatorsRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxMonitoredValidatorsRequestSize)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			handleHTTPError(w, fmt.Sprintf("Request body is larger than %d bytes", maxBytesErr.Limit), http.StatusRequestEntityTooLarge)
+			return nil, nil, false
+		}
+		handleHTTPError(w, "Could not decode request body: "+err.Error(), http.StatusBadRequest)
+		return nil, nil, false
+	}
+	if len(req.Indices)+len(req.PublicKeys) > maxMonitoredValidatorsPerRequest {
+		handleHTTPError(
+			w,
+			fmt.Sprintf("Too many validators, expected at most %d per request", maxMonitoredValidatorsPerRequest),
+			http.StatusBadRequest,
+		)
+		return nil, nil, false
+	}
+	indices := make([]primitives.ValidatorIndex, len(req.Indices))
+	for i, s := range req.Indices {
+		idx, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			handleHTTPError(w, fmt.Sprintf("Invalid validator index %q: %v", s, err), http.StatusBadRequest)
+			return nil, nil, false
+		}
+		indices[i] = primitives.ValidatorIndex(idx)
+	}
+	pubkeys := make([][dilithium2.CryptoPublicKeyBytes]byte, len(req.PublicKeys))
+	for i, s := range req.PublicKeys {
+		pubkey, err := hexutil.Decode(s)
+		if err != nil {
+			handleHTTPError(w, fmt.Sprintf("Invalid public key %q: %v", s, err), http.StatusBadRequest)
+			return nil, nil, false
+		}
+		if len(pubkey) != dilithium2.CryptoPublicKeyBytes {
+			handleHTTPError(w, fmt.Sprintf("Invalid public key length %d, expected %d", len(pubkey), dilithium2.CryptoPublicKeyBytes), http.StatusBadRequest)
+			return nil, nil, false
+		}
+		pubkeys[i] = bytesutil.ToBytes2592(pubkey)
+	}
+
+	st, err := vs.HeadFetcher.HeadStateReadOnly(r.Context())
+	if err != nil {
+		handleHTTPError(w, "Could not get head state: "+err.Error(), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	for _, idx := range indices {
+		if uint64(idx) >= uint64(st.NumValidators()) {
+			handleHTTPError(w, fmt.Sprintf("Unknown validator index %d", idx), http.StatusBadRequest)
+			return nil, nil, false
+		}
+	}
+	for i := range pubkeys {
+		if _, ok := st.ValidatorIndexByPubkey(pubkeys[i]); ok {
+			continue
+		}
+		if vs.DepositFetcher != nil {
+			if deposit, _ := vs.DepositFetcher.DepositByPubkey(r.Context(), pubkeys[i][:]); deposit != nil {
+				continue
+			}
+		}
+		handleHTTPError(w, fmt.Sprintf("Unknown validator public key %#x", bytesutil.Trunc(pubkeys[i][:])), http.StatusBadRequest)
+		return nil, nil, false
+	}
+	return indices, pubkeys, true
+}
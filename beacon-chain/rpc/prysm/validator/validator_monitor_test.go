@@ -0,0 +1,201 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/go-zond/common/hexutil"
+	mock "github.com/theQRL/qrysm/v4/beacon-chain/blockchain/testing"
+	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositcache"
+	"github.com/theQRL/qrysm/v4/beacon-chain/monitor"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/util"
+)
+
+type mockValidatorMonitor struct {
+	indices []primitives.ValidatorIndex
+	pubkeys [][dilithium2.CryptoPublicKeyBytes]byte
+	perfs   []*monitor.TrackedValidatorPerformance
+}
+
+func (m *mockValidatorMonitor) TrackValidators(_ context.Context, indices []primitives.ValidatorIndex, pubkeys [][dilithium2.CryptoPublicKeyBytes]byte) error {
+	m.indices = append(m.indices, indices...)
+	m.pubkeys = append(m.pubkeys, pubkeys...)
+	return nil
+}
+
+func (m *mockValidatorMonitor) UntrackValidators(_ context.Context, indices []primitives.ValidatorIndex, _ [][dilithium2.CryptoPublicKeyBytes]byte) error {
+	for _, idx := range indices {
+		for i := range m.indices {
+			if m.indices[i] == idx {
+				m.indices = append(m.indices[:i], m.indices[i+1:]...)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (m *mockValidatorMonitor) TrackedValidatorsList() ([]primitives.ValidatorIndex, [][dilithium2.CryptoPublicKeyBytes]byte) {
+	return m.indices, m.pubkeys
+}
+
+func (m *mockValidatorMonitor) Performance() []*monitor.TrackedValidatorPerformance {
+	return m.perfs
+}
+
+// monitorHeadFetcher returns a head fetcher whose head state has the given number of validators.
+// The public key of each validator starts with its index.
+func monitorHeadFetcher(t *testing.T, numValidators int) *mock.ChainService {
+	st, err := util.NewBeaconState()
+	require.NoError(t, err)
+	vals := make([]*zondpb.Validator, numValidators)
+	for i := range vals {
+		pubkey := make([]byte, dilithium2.CryptoPublicKeyBytes)
+		pubkey[0] = byte(i)
+		vals[i] = &zondpb.Validator{PublicKey: pubkey, WithdrawalCredentials: make([]byte, 32)}
+	}
+	require.NoError(t, st.SetValidators(vals))
+	return &mock.ChainService{State: st}
+}
+
+func TestServer_MonitoredValidators(t *testing.T) {
+	ctx := context.Background()
+	pubkey := [dilithium2.CryptoPublicKeyBytes]byte{'a'}
+	depositCache, err := depositcache.New()
+	require.NoError(t, err)
+	deposit := &zondpb.Deposit{Data: &zondpb.Deposit_Data{PublicKey: pubkey[:]}}
+	require.NoError(t, depositCache.InsertDeposit(ctx, deposit, 10, 0, [32]byte{}))
+	m := &mockValidatorMonitor{}
+	vs := &Server{
+		ValidatorMonitor: m,
+		HeadFetcher:      monitorHeadFetcher(t, 6),
+		DepositFetcher:   depositCache,
+	}
+
+	body, err := json.Marshal(&MonitoredValidatorsRequest{
+		Indices:    []string{"1", "5"},
+		PublicKeys: []string{hexutil.Encode(pubkey[:])},
+	})
+	require.NoError(t, err)
+	request := httptest.NewRequest(http.MethodPost, "http://example.com/prysm/validators/monitor", bytes.NewReader(body))
+	writer := httptest.NewRecorder()
+	vs.AddMonitoredValidators(writer, request)
+	require.Equal(t, http.StatusOK, writer.Code)
+	resp := &MonitoredValidatorsResponse{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), resp))
+	assert.DeepEqual(t, []string{"1", "5"}, resp.Indices)
+	assert.DeepEqual(t, []string{hexutil.Encode(pubkey[:])}, resp.PendingPublicKeys)
+
+	body, err = json.Marshal(&MonitoredValidatorsRequest{Indices: []string{"1"}})
+	require.NoError(t, err)
+	request = httptest.NewRequest(http.MethodDelete, "http://example.com/prysm/validators/monitor", bytes.NewReader(body))
+	writer = httptest.NewRecorder()
+	vs.RemoveMonitoredValidators(writer, request)
+	require.Equal(t, http.StatusOK, writer.Code)
+
+	request = httptest.NewRequest(http.MethodGet, "http://example.com/prysm/validators/monitor", nil)
+	writer = httptest.NewRecorder()
+	vs.ListMonitoredValidators(writer, request)
+	require.Equal(t, http.StatusOK, writer.Code)
+	resp = &MonitoredValidatorsResponse{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), resp))
+	assert.DeepEqual(t, []string{"5"}, resp.Indices)
+}
+
+func TestServer_AddMonitoredValidators_InvalidRequest(t *testing.T) {
+	depositCache, err := depositcache.New()
+	require.NoError(t, err)
+	m := &mockValidatorMonitor{}
+	vs := &Server{
+		ValidatorMonitor: m,
+		HeadFetcher:      monitorHeadFetcher(t, 6),
+		DepositFetcher:   depositCache,
+	}
+	unknownPubkey := [dilithium2.CryptoPublicKeyBytes]byte{'a'}
+	tooManyIndices := make([]string, maxMonitoredValidatorsPerRequest+1)
+	for i := range tooManyIndices {
+		tooManyIndices[i] = strconv.Itoa(i % 6)
+	}
+	tooMany, err := json.Marshal(&MonitoredValidatorsRequest{Indices: tooManyIndices})
+	require.NoError(t, err)
+	tests := []struct {
+		name string
+		body string
+		code int
+		want string
+	}{
+		{name: "invalid body", body: "{", code: http.StatusBadRequest, want: "Could not decode request body"},
+		{name: "invalid index", body: `{"indices":["a"]}`, code: http.StatusBadRequest, want: "Invalid validator index"},
+		{name: "invalid public key", body: `{"public_keys":["0xzz"]}`, code: http.StatusBadRequest, want: "Invalid public key"},
+		{name: "short public key", body: `{"public_keys":["0x01"]}`, code: http.StatusBadRequest, want: "Invalid public key length 1"},
+		{name: "unknown index", body: `{"indices":["1","6"]}`, code: http.StatusBadRequest, want: "Unknown validator index 6"},
+		{
+			name: "unknown public key",
+			body: `{"public_keys":["` + hexutil.Encode(unknownPubkey[:]) + `"]}`,
+			code: http.StatusBadRequest,
+			want: "Unknown validator public key",
+		},
+		{name: "too many validators", body: string(tooMany), code: http.StatusBadRequest, want: "Too many validators"},
+		{
+			name: "body too large",
+			body: `{"indices":["` + strings.Repeat("1", maxMonitoredValidatorsRequestSize) + `"]}`,
+			code: http.StatusRequestEntityTooLarge,
+			want: "Request body is larger than",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "http://example.com/prysm/validators/monitor", bytes.NewReader([]byte(tt.body)))
+			writer := httptest.NewRecorder()
+			vs.AddMonitoredValidators(writer, request)
+			assert.Equal(t, tt.code, writer.Code)
+			assert.StringContains(t, tt.want, writer.Body.String())
+			assert.Equal(t, 0, len(m.indices))
+			assert.Equal(t, 0, len(m.pubkeys))
+		})
+	}
+}
+
+func TestServer_GetMonitoredValidatorsPerformance(t *testing.T) {
+	vs := &Server{ValidatorMonitor: &mockValidatorMonitor{
+		perfs: []*monitor.TrackedValidatorPerformance{
+			{
+				ValidatorIndex:      3,
+				AttestedSlot:        10,
+				TimelyHead:          true,
+				Balance:             40000000000,
+				BalanceChange:       -100,
+				StartEpoch:          1,
+				TotalAttestedCount:  2,
+				TotalRequestedCount: 3,
+			},
+		},
+	}}
+	request := httptest.NewRequest(http.MethodGet, "http://example.com/prysm/validators/monitor/performance", nil)
+	writer := httptest.NewRecorder()
+	vs.GetMonitoredValidatorsPerformance(writer, request)
+	require.Equal(t, http.StatusOK, writer.Code)
+	resp := &MonitoredValidatorsPerformanceResponse{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), resp))
+	require.Equal(t, 1, len(resp.Data))
+	perf := resp.Data[0]
+	assert.Equal(t, "3", perf.ValidatorIndex)
+	assert.Equal(t, "10", perf.Latest.AttestedSlot)
+	assert.Equal(t, true, perf.Latest.TimelyHead)
+	assert.Equal(t, "40000000000", perf.Latest.Balance)
+	assert.Equal(t, "-100", perf.Latest.BalanceChange)
+	assert.Equal(t, "1", perf.Aggregated.StartEpoch)
+	assert.Equal(t, "2", perf.Aggregated.TotalAttestedCount)
+	assert.Equal(t, "3", perf.Aggregated.TotalRequestedCount)
+}
@@ -119,12 +119,15 @@ type Config struct {
 	OperationNotifier             opfeed.Notifier
 	StateGen                      *stategen.State
 	MaxMsgSize                    int
+	MaxConcurrentStateReplays     int
 	ExecutionEngineCaller         execution.EngineCaller
 	ProposerIdsCache              *cache.ProposerPayloadIDsCache
 	OptimisticModeFetcher         blockchain.OptimisticModeFetcher
 	BlockBuilder                  builder.BlockBuilder
 	Router                        *mux.Router
 	ClockWaiter                   startup.ClockWaiter
+	ValidatorMonitor              httpserver.ValidatorMonitor
+	EnableValidatorMonitorAPI     bool
 }
 
 // NewService instantiates a new RPC service instance that will
@@ -196,13 +199,15 @@ func (s *Service) Start() {
 		stateCache = s.cfg.StateGen.CombinedCache()
 	}
 	withCache := stategen.WithCache(stateCache)
-	ch := stategen.NewCanonicalHistory(s.cfg.BeaconDB, s.cfg.ChainInfoFetcher, s.cfg.ChainInfoFetcher, withCache)
+	withReplayCache := stategen.WithReplayCache(stategen.NewReplayCache(stategen.DefaultReplayCacheSize))
+	ch := stategen.NewCanonicalHistory(s.cfg.BeaconDB, s.cfg.ChainInfoFetcher, s.cfg.ChainInfoFetcher, withCache, withReplayCache)
+	replayer := stategen.NewReplayScheduler(ch, s.cfg.MaxConcurrentStateReplays)
 	stater := &lookup.BeaconDbStater{
 		BeaconDB:           s.cfg.BeaconDB,
 		ChainInfoFetcher:   s.cfg.ChainInfoFetcher,
 		GenesisTimeFetcher: s.cfg.GenesisTimeFetcher,
 		StateGenService:    s.cfg.StateGen,
-		ReplayerBuilder:    ch,
+		ReplayerBuilder:    replayer,
 	}
 	blocker := &lookup.BeaconDbBlocker{
 		BeaconDB:         s.cfg.BeaconDB,
@@ -213,13 +218,14 @@ func (s *Service) Start() {
 		Blocker:               blocker,
 		OptimisticModeFetcher: s.cfg.OptimisticModeFetcher,
 		FinalizationFetcher:   s.cfg.FinalizationFetcher,
-		ReplayerBuilder:       ch,
+		ReplayerBuilder:       replayer,
 		TimeFetcher:           s.cfg.GenesisTimeFetcher,
 		Stater:                stater,
 		HeadFetcher:           s.cfg.HeadFetcher,
 	}
 	s.cfg.Router.HandleFunc("/eth/v1/beacon/rewards/blocks/{block_id}", rewardsServer.BlockRewards)
 	s.cfg.Router.HandleFunc("/eth/v1/beacon/rewards/attestations/{epoch}", rewardsServer.AttestationRewards)
+	s.cfg.Router.HandleFunc("/eth/v1/beacon/rewards/sync_committee/{block_id}", rewardsServer.SyncCommitteeRewards)
 
 	builderServer := &rpcBuilder.Server{
 		FinalizationFetcher:   s.cfg.FinalizationFetcher,
@@ -256,7 +262,7 @@ func (s *Service) Start() {
 		SlashingsPool:          s.cfg.SlashingsPool,
 		StateGen:               s.cfg.StateGen,
 		SyncCommitteePool:      s.cfg.SyncCommitteeObjectPool,
-		ReplayerBuilder:        ch,
+		ReplayerBuilder:        replayer,
 		ExecutionEngineCaller:  s.cfg.ExecutionEngineCaller,
 		BeaconDB:               s.cfg.BeaconDB,
 		ProposerSlotIndexCache: s.cfg.ProposerIdsCache,
@@ -345,7 +351,7 @@ func (s *Service) Start() {
 		SyncChecker:                 s.cfg.SyncService,
 		ReceivedAttestationsBuffer:  make(chan *zondpbv1alpha1.Attestation, attestationBufferSize),
 		CollectedAttestationsBuffer: make(chan []*zondpbv1alpha1.Attestation, attestationBufferSize),
-		ReplayerBuilder:             ch,
+		ReplayerBuilder:             replayer,
 	}
 	beaconChainServerV1 := &beacon.Server{
 		CanonicalHistory:              ch,
@@ -376,10 +382,22 @@ func (s *Service) Start() {
 		GenesisTimeFetcher: s.cfg.GenesisTimeFetcher,
 		HeadFetcher:        s.cfg.HeadFetcher,
 		SyncChecker:        s.cfg.SyncService,
+		ValidatorMonitor:   s.cfg.ValidatorMonitor,
+		DepositFetcher:     s.cfg.DepositFetcher,
 	}
 	s.cfg.Router.HandleFunc("/prysm/validators/performance", httpServer.GetValidatorPerformance)
+	if s.cfg.ValidatorMonitor != nil {
+		s.cfg.Router.HandleFunc("/prysm/validators/monitor", httpServer.ListMonitoredValidators).Methods("GET")
+		s.cfg.Router.HandleFunc("/prysm/validators/monitor/performance", httpServer.GetMonitoredValidatorsPerformance).Methods("GET")
+		// Changing the tracked validators is opt-in, as the endpoint is not authenticated.
+		if s.cfg.EnableValidatorMonitorAPI {
+			s.cfg.Router.HandleFunc("/prysm/validators/monitor", httpServer.AddMonitoredValidators).Methods("POST")
+			s.cfg.Router.HandleFunc("/prysm/validators/monitor", httpServer.RemoveMonitoredValidators).Methods("DELETE")
+		}
+	}
 	s.cfg.Router.HandleFunc("/eth/v2/beacon/blocks", beaconChainServerV1.PublishBlockV2)
 	s.cfg.Router.HandleFunc("/eth/v2/beacon/blinded_blocks", beaconChainServerV1.PublishBlindedBlockV2)
+	s.cfg.Router.HandleFunc("/eth/v1/beacon/deposit_snapshot", beaconChainServerV1.GetDepositSnapshot).Methods("GET")
 	zondpbv1alpha1.RegisterNodeServer(s.grpcServer, nodeServer)
 	zondpbservice.RegisterBeaconNodeServer(s.grpcServer, nodeServerV1)
 	zondpbv1alpha1.RegisterHealthServer(s.grpcServer, nodeServer)
@@ -402,7 +420,7 @@ func (s *Service) Start() {
 			HeadFetcher:        s.cfg.HeadFetcher,
 			PeerManager:        s.cfg.PeerManager,
 			PeersFetcher:       s.cfg.PeersFetcher,
-			ReplayerBuilder:    ch,
+			ReplayerBuilder:    replayer,
 		}
 		debugServerV1 := &debug.Server{
 			BeaconDB:              s.cfg.BeaconDB,
@@ -414,6 +432,8 @@ func (s *Service) Start() {
 			FinalizationFetcher:   s.cfg.FinalizationFetcher,
 			ChainInfoFetcher:      s.cfg.ChainInfoFetcher,
 		}
+		s.cfg.Router.HandleFunc("/eth/v1/debug/beacon/states/{state_id}/proof", debugServerV1.GetStateProof).Methods("GET")
+		s.cfg.Router.HandleFunc("/eth/v1/debug/fork_choice/history", debugServerV1.GetForkChoiceHistory).Methods("GET")
 		zondpbv1alpha1.RegisterDebugServer(s.grpcServer, debugServer)
 		zondpbservice.RegisterBeaconDebugServer(s.grpcServer, debugServerV1)
 	}
@@ -237,6 +237,22 @@ func (f *FieldTrie) TrieRoot() ([32]byte, error) {
 	}
 }
 
+// Layers returns a copy of the Merkle layers of the trie, from the leaves up to the root,
+// or nil if the trie is empty. The nodes of the layers are shared with the trie.
+func (f *FieldTrie) Layers() [][]*[32]byte {
+	if f.Empty() {
+		return nil
+	}
+	f.RLock()
+	defer f.RUnlock()
+	layers := make([][]*[32]byte, len(f.fieldLayers))
+	for i, layer := range f.fieldLayers {
+		layers[i] = make([]*[32]byte, len(layer))
+		copy(layers[i], layer)
+	}
+	return layers
+}
+
 // FieldReference returns the underlying field reference
 // object for the trie.
 func (f *FieldTrie) FieldReference() *stateutil.Reference {
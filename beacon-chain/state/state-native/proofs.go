@@ -6,7 +6,9 @@ import (
 
 	"github.com/theQRL/qrysm/v4/beacon-chain/state/fieldtrie"
 	"github.com/theQRL/qrysm/v4/beacon-chain/state/state-native/types"
+	fieldparams "github.com/theQRL/qrysm/v4/config/fieldparams"
 	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	"github.com/theQRL/qrysm/v4/encoding/ssz/proof"
 	"github.com/theQRL/qrysm/v4/runtime/version"
 )
 
@@ -106,3 +108,64 @@ func (b *BeaconState) FinalizedRootProof(ctx context.Context) ([][]byte, error)
 	proof = append(proof, branch...)
 	return proof, nil
 }
+
+// GeneralizedIndex of the value at the given path in the beacon state. Path elements are
+// field names, such as "validators", indices into vectors and lists, or "__len__" for the
+// length of a list, e.g. "validators", "5", "effective_balance".
+func (b *BeaconState) GeneralizedIndex(path ...string) (uint64, error) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	pb := b.ToProtoUnsafe()
+	if pb == nil {
+		return 0, errNotSupported("GeneralizedIndex", b.version)
+	}
+	return proof.GeneralizedIndex(pb, path...)
+}
+
+// Multiproof crafts a Merkle multiproof for the values at the given generalized indices
+// of the beacon state, against the hash tree root of the state.
+func (b *BeaconState) Multiproof(ctx context.Context, gindices []uint64) (*proof.Multiproof, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if err := b.initializeMerkleLayers(ctx); err != nil {
+		return nil, err
+	}
+	if err := b.recomputeDirtyFields(ctx); err != nil {
+		return nil, err
+	}
+	pb := b.ToProtoUnsafe()
+	if pb == nil {
+		return nil, errNotSupported("Multiproof", b.version)
+	}
+	// The field roots are taken from the Merkle layers of the state, so that only the
+	// fields which are part of the proof need to be hashed again. Likewise, the roots of
+	// the validators are taken from their field trie.
+	validators, err := b.validatorsTrieLayers()
+	if err != nil {
+		return nil, err
+	}
+	listLayers := map[int][][]*[32]byte{types.Validators.RealPosition(): validators}
+	root, err := proof.FromObjectWithFieldRoots(pb, b.merkleLayers[0], listLayers)
+	if err != nil {
+		return nil, err
+	}
+	return proof.Prove(root, gindices)
+}
+
+// validatorsTrieLayers returns the Merkle layers of the validators field trie, building the
+// trie if the validators have not been hashed into it yet, so that later proofs reuse it.
+func (b *BeaconState) validatorsTrieLayers() ([][]*[32]byte, error) {
+	if b.rebuildTrie[types.Validators] {
+		if err := b.resetFieldTrie(types.Validators, b.validators, fieldparams.ValidatorRegistryLimit); err != nil {
+			return nil, err
+		}
+		delete(b.rebuildTrie, types.Validators)
+	} else if b.stateFieldLeaves[types.Validators].Empty() {
+		if _, err := b.recomputeFieldTrie(types.Validators, b.validators); err != nil {
+			return nil, err
+		}
+	}
+	return b.stateFieldLeaves[types.Validators].Layers(), nil
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"testing"
 
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
 	"github.com/theQRL/go-zond/common/hexutil"
 	statenative "github.com/theQRL/qrysm/v4/beacon-chain/state/state-native"
 	"github.com/theQRL/qrysm/v4/container/trie"
+	"github.com/theQRL/qrysm/v4/encoding/ssz/proof"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
 	"github.com/theQRL/qrysm/v4/testing/require"
 	"github.com/theQRL/qrysm/v4/testing/util"
 )
@@ -164,3 +167,82 @@ func TestBeaconStateMerkleProofs_bellatrix(t *testing.T) {
 		require.Equal(t, true, valid)
 	})
 }
+
+func TestBeaconStateMultiproof_capella(t *testing.T) {
+	ctx := context.Background()
+	capella, err := util.NewBeaconStateCapella()
+	require.NoError(t, err)
+	htr, err := capella.HashTreeRoot(ctx)
+	require.NoError(t, err)
+
+	t.Run("finalized root", func(t *testing.T) {
+		gIndex, err := capella.GeneralizedIndex("finalized_checkpoint", "root")
+		require.NoError(t, err)
+		require.Equal(t, statenative.FinalizedRootGeneralizedIndex(), gIndex)
+
+		p, err := capella.Multiproof(ctx, []uint64{gIndex})
+		require.NoError(t, err)
+		require.DeepEqual(t, htr, p.Root)
+		require.DeepEqual(t, capella.FinalizedCheckpoint().Root, p.Leaves[0][:])
+		// With a single leaf, the multiproof is the Merkle branch of the leaf.
+		branch, err := capella.FinalizedRootProof(ctx)
+		require.NoError(t, err)
+		require.Equal(t, len(branch), len(p.Hashes))
+		for i := range branch {
+			require.DeepEqual(t, branch[i], p.Hashes[i][:])
+		}
+	})
+	t.Run("recomputes root on dirty fields", func(t *testing.T) {
+		require.NoError(t, capella.SetBalances([]uint64{1, 2, 3, 4, 5}))
+		gIndex, err := capella.GeneralizedIndex("balances", "4")
+		require.NoError(t, err)
+		lenIndex, err := capella.GeneralizedIndex("balances", "__len__")
+		require.NoError(t, err)
+
+		p, err := capella.Multiproof(ctx, []uint64{gIndex, lenIndex})
+		require.NoError(t, err)
+		newRoot, err := capella.HashTreeRoot(ctx)
+		require.NoError(t, err)
+		require.DeepEqual(t, newRoot, p.Root)
+		require.Equal(t, byte(5), p.Leaves[0][0])
+		require.Equal(t, byte(5), p.Leaves[1][0])
+		valid, err := proof.Verify(newRoot, p.Indices, p.Leaves, p.Hashes)
+		require.NoError(t, err)
+		require.Equal(t, true, valid)
+	})
+	t.Run("validators", func(t *testing.T) {
+		validators := make([]*zondpb.Validator, 10)
+		for i := range validators {
+			validators[i] = &zondpb.Validator{
+				PublicKey:             make([]byte, dilithium2.CryptoPublicKeyBytes),
+				WithdrawalCredentials: make([]byte, 32),
+				EffectiveBalance:      uint64(i),
+			}
+		}
+		require.NoError(t, capella.SetValidators(validators))
+		prove := func(i string) *proof.Multiproof {
+			gIndex, err := capella.GeneralizedIndex("validators", i, "effective_balance")
+			require.NoError(t, err)
+			p, err := capella.Multiproof(ctx, []uint64{gIndex})
+			require.NoError(t, err)
+			newRoot, err := capella.HashTreeRoot(ctx)
+			require.NoError(t, err)
+			require.DeepEqual(t, newRoot, p.Root)
+			valid, err := proof.Verify(newRoot, p.Indices, p.Leaves, p.Hashes)
+			require.NoError(t, err)
+			require.Equal(t, true, valid)
+			return p
+		}
+		require.Equal(t, byte(3), prove("3").Leaves[0][0])
+		require.Equal(t, byte(9), prove("9").Leaves[0][0])
+
+		// Proofs follow updates of the validators.
+		updated := zondpb.CopyValidator(validators[3])
+		updated.EffectiveBalance = 42
+		require.NoError(t, capella.UpdateValidatorAtIndex(3, updated))
+		require.Equal(t, byte(42), prove("3").Leaves[0][0])
+
+		_, err := capella.GeneralizedIndex("validators", "10")
+		require.ErrorContains(t, "out of bounds of length 10", err)
+	})
+}
@@ -0,0 +1,259 @@
+// Package statediff computes compact differences between two beacon states of the same fork, and
+// reconstructs a beacon state from a base state and such a difference. A difference only records
+// the fields which changed and, for list fields such as the validators, the balances or the block
+// roots, only the changed elements.
+package statediff
+
+import (
+	"bytes"
+
+	"github.com/golang/snappy"
+	"github.com/pkg/errors"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	statenative "github.com/theQRL/qrysm/v4/beacon-chain/state/state-native"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/runtime/version"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var errInvalidDiff = errors.New("invalid state diff")
+
+// Diff returns the difference to apply to the base state to obtain the target state. Both states
+// must be of the same fork.
+func Diff(base, target state.ReadOnlyBeaconState) ([]byte, error) {
+	if base.Version() != target.Version() {
+		return nil, errors.Errorf("cannot diff a %s state against a %s state", version.String(target.Version()), version.String(base.Version()))
+	}
+	b, err := protoMessage(base.ToProtoUnsafe())
+	if err != nil {
+		return nil, err
+	}
+	t, err := protoMessage(target.ToProtoUnsafe())
+	if err != nil {
+		return nil, err
+	}
+	bm, tm := b.ProtoReflect(), t.ProtoReflect()
+
+	enc := protowire.AppendVarint(nil, uint64(target.Version()))
+	fields := tm.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.IsList() {
+			enc, err = appendListDiff(enc, fd, bm.Get(fd).List(), tm.Get(fd).List())
+		} else {
+			enc, err = appendFieldDiff(enc, fd, bm, tm)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not diff field %s", fd.Name())
+		}
+	}
+	return snappy.Encode(nil, enc), nil
+}
+
+// Apply returns the state obtained by applying the difference to the base state. The base state is
+// not modified.
+func Apply(base state.ReadOnlyBeaconState, diff []byte) (state.BeaconState, error) {
+	enc, err := snappy.Decode(nil, diff)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decompress state diff")
+	}
+	v, n := protowire.ConsumeVarint(enc)
+	if n < 0 {
+		return nil, errInvalidDiff
+	}
+	enc = enc[n:]
+	if int(v) != base.Version() {
+		return nil, errors.Errorf("cannot apply a %s state diff to a %s state", version.String(int(v)), version.String(base.Version()))
+	}
+	b, err := protoMessage(base.ToProto())
+	if err != nil {
+		return nil, err
+	}
+	m := b.ProtoReflect()
+	fields := m.Descriptor().Fields()
+	for len(enc) > 0 {
+		num, n := protowire.ConsumeVarint(enc)
+		if n < 0 {
+			return nil, errInvalidDiff
+		}
+		enc = enc[n:]
+		fd := fields.ByNumber(protowire.Number(num))
+		if fd == nil {
+			return nil, errors.Wrapf(errInvalidDiff, "unknown field number %d", num)
+		}
+		if fd.IsList() {
+			enc, err = applyListDiff(enc, m, fd)
+		} else {
+			enc, err = applyFieldDiff(enc, m, fd)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not apply diff of field %s", fd.Name())
+		}
+	}
+	return initializeState(b)
+}
+
+// appendFieldDiff appends the target value of a singular field when it differs from the base value.
+func appendFieldDiff(enc []byte, fd protoreflect.FieldDescriptor, base, target protoreflect.Message) ([]byte, error) {
+	if base.Has(fd) == target.Has(fd) && (!target.Has(fd) || valuesEqual(fd, base.Get(fd), target.Get(fd))) {
+		return enc, nil
+	}
+	tmp := target.New()
+	if target.Has(fd) {
+		tmp.Set(fd, target.Get(fd))
+	}
+	raw, err := proto.MarshalOptions{Deterministic: true}.Marshal(tmp.Interface())
+	if err != nil {
+		return nil, err
+	}
+	enc = protowire.AppendVarint(enc, uint64(fd.Number()))
+	return protowire.AppendBytes(enc, raw), nil
+}
+
+// appendListDiff appends the length of the target list and its elements which differ from the base list.
+func appendListDiff(enc []byte, fd protoreflect.FieldDescriptor, base, target protoreflect.List) ([]byte, error) {
+	var changed []int
+	for i := 0; i < target.Len(); i++ {
+		if i >= base.Len() || !valuesEqual(fd, base.Get(i), target.Get(i)) {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 && base.Len() == target.Len() {
+		return enc, nil
+	}
+	enc = protowire.AppendVarint(enc, uint64(fd.Number()))
+	enc = protowire.AppendVarint(enc, uint64(target.Len()))
+	enc = protowire.AppendVarint(enc, uint64(len(changed)))
+	for _, i := range changed {
+		enc = protowire.AppendVarint(enc, uint64(i))
+		v := target.Get(i)
+		switch fd.Kind() {
+		case protoreflect.BytesKind:
+			enc = protowire.AppendBytes(enc, v.Bytes())
+		case protoreflect.Uint64Kind:
+			enc = protowire.AppendVarint(enc, v.Uint())
+		case protoreflect.MessageKind:
+			raw, err := proto.MarshalOptions{Deterministic: true}.Marshal(v.Message().Interface())
+			if err != nil {
+				return nil, err
+			}
+			enc = protowire.AppendBytes(enc, raw)
+		default:
+			return nil, errors.Errorf("unsupported list kind %s", fd.Kind())
+		}
+	}
+	return enc, nil
+}
+
+// applyFieldDiff sets a singular field to the value recorded in the diff.
+func applyFieldDiff(enc []byte, m protoreflect.Message, fd protoreflect.FieldDescriptor) ([]byte, error) {
+	raw, n := protowire.ConsumeBytes(enc)
+	if n < 0 {
+		return nil, errInvalidDiff
+	}
+	tmp := m.New()
+	if err := proto.Unmarshal(raw, tmp.Interface()); err != nil {
+		return nil, err
+	}
+	if tmp.Has(fd) {
+		m.Set(fd, tmp.Get(fd))
+	} else {
+		m.Clear(fd)
+	}
+	return enc[n:], nil
+}
+
+// applyListDiff resizes a list field to the length recorded in the diff, and sets its changed elements.
+func applyListDiff(enc []byte, m protoreflect.Message, fd protoreflect.FieldDescriptor) ([]byte, error) {
+	length, n := protowire.ConsumeVarint(enc)
+	if n < 0 {
+		return nil, errInvalidDiff
+	}
+	enc = enc[n:]
+	count, n := protowire.ConsumeVarint(enc)
+	if n < 0 {
+		return nil, errInvalidDiff
+	}
+	enc = enc[n:]
+
+	l := m.Mutable(fd).List()
+	if uint64(l.Len()) > length {
+		l.Truncate(int(length))
+	}
+	for uint64(l.Len()) < length {
+		l.Append(l.NewElement())
+	}
+	for j := uint64(0); j < count; j++ {
+		i, n := protowire.ConsumeVarint(enc)
+		if n < 0 || i >= length {
+			return nil, errInvalidDiff
+		}
+		enc = enc[n:]
+		switch fd.Kind() {
+		case protoreflect.BytesKind:
+			raw, n := protowire.ConsumeBytes(enc)
+			if n < 0 {
+				return nil, errInvalidDiff
+			}
+			l.Set(int(i), protoreflect.ValueOfBytes(bytes.Clone(raw)))
+			enc = enc[n:]
+		case protoreflect.Uint64Kind:
+			v, n := protowire.ConsumeVarint(enc)
+			if n < 0 {
+				return nil, errInvalidDiff
+			}
+			l.Set(int(i), protoreflect.ValueOfUint64(v))
+			enc = enc[n:]
+		case protoreflect.MessageKind:
+			raw, n := protowire.ConsumeBytes(enc)
+			if n < 0 {
+				return nil, errInvalidDiff
+			}
+			v := l.NewElement()
+			if err := proto.Unmarshal(raw, v.Message().Interface()); err != nil {
+				return nil, err
+			}
+			l.Set(int(i), v)
+			enc = enc[n:]
+		default:
+			return nil, errors.Errorf("unsupported list kind %s", fd.Kind())
+		}
+	}
+	return enc, nil
+}
+
+func valuesEqual(fd protoreflect.FieldDescriptor, a, b protoreflect.Value) bool {
+	switch fd.Kind() {
+	case protoreflect.BytesKind:
+		return bytes.Equal(a.Bytes(), b.Bytes())
+	case protoreflect.MessageKind:
+		return proto.Equal(a.Message().Interface(), b.Message().Interface())
+	default:
+		return a.Interface() == b.Interface()
+	}
+}
+
+func protoMessage(pb interface{}) (proto.Message, error) {
+	m, ok := pb.(proto.Message)
+	if !ok || m == nil {
+		return nil, errors.New("state is not a protobuf message")
+	}
+	return m, nil
+}
+
+func initializeState(pb proto.Message) (state.BeaconState, error) {
+	switch st := pb.(type) {
+	case *zondpb.BeaconState:
+		return statenative.InitializeFromProtoUnsafePhase0(st)
+	case *zondpb.BeaconStateAltair:
+		return statenative.InitializeFromProtoUnsafeAltair(st)
+	case *zondpb.BeaconStateBellatrix:
+		return statenative.InitializeFromProtoUnsafeBellatrix(st)
+	case *zondpb.BeaconStateCapella:
+		return statenative.InitializeFromProtoUnsafeCapella(st)
+	default:
+		return nil, errors.Errorf("unsupported state type %T", pb)
+	}
+}
@@ -0,0 +1,128 @@
+package statediff
+
+import (
+	"context"
+	"testing"
+
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	fieldparams "github.com/theQRL/qrysm/v4/config/fieldparams"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/util"
+)
+
+func requireSameState(t *testing.T, want, got state.BeaconState) {
+	wantRoot, err := want.HashTreeRoot(context.Background())
+	require.NoError(t, err)
+	gotRoot, err := got.HashTreeRoot(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, wantRoot, gotRoot)
+}
+
+func syncCommittee() *zondpb.SyncCommittee {
+	pubkeys := make([][]byte, fieldparams.SyncCommitteeLength)
+	for i := range pubkeys {
+		pubkeys[i] = make([]byte, dilithium2.CryptoPublicKeyBytes)
+	}
+	return &zondpb.SyncCommittee{
+		Pubkeys:         pubkeys,
+		AggregatePubkey: make([]byte, fieldparams.SyncCommitteeLength*dilithium2.CryptoPublicKeyBytes),
+	}
+}
+
+func TestDiffApply(t *testing.T) {
+	for name, genesis := range map[string]func() state.BeaconState{
+		"phase0": func() state.BeaconState {
+			st, _ := util.DeterministicGenesisState(t, 64)
+			return st
+		},
+		"altair": func() state.BeaconState {
+			st, _ := util.DeterministicGenesisStateAltair(t, 64)
+			require.NoError(t, st.SetCurrentSyncCommittee(syncCommittee()))
+			require.NoError(t, st.SetNextSyncCommittee(syncCommittee()))
+			return st
+		},
+		"capella": func() state.BeaconState {
+			genesis, _ := util.DeterministicGenesisState(t, 64)
+			st, err := util.NewBeaconStateCapella(func(st *zondpb.BeaconStateCapella) error {
+				st.Validators = genesis.Validators()
+				st.Balances = genesis.Balances()
+				st.PreviousEpochParticipation = make([]byte, len(st.Validators))
+				st.CurrentEpochParticipation = make([]byte, len(st.Validators))
+				st.InactivityScores = make([]uint64, len(st.Validators))
+				st.CurrentSyncCommittee = syncCommittee()
+				st.NextSyncCommittee = syncCommittee()
+				return nil
+			})
+			require.NoError(t, err)
+			return st
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			base := genesis()
+			target := base.Copy()
+			require.NoError(t, target.SetSlot(params.BeaconConfig().SlotsPerEpoch*3))
+			require.NoError(t, target.UpdateBalancesAtIndex(5, 1))
+			require.NoError(t, target.UpdateBlockRootAtIndex(3, bytesutil.ToBytes32([]byte("block-root"))))
+			require.NoError(t, target.UpdateRandaoMixesAtIndex(2, bytesutil.PadTo([]byte("randao"), 32)))
+			require.NoError(t, target.SetFinalizedCheckpoint(&zondpb.Checkpoint{Epoch: 2, Root: bytesutil.PadTo([]byte("finalized"), 32)}))
+			val, err := target.ValidatorAtIndex(7)
+			require.NoError(t, err)
+			val.Slashed = true
+			require.NoError(t, target.UpdateValidatorAtIndex(7, val))
+			newVal, err := target.ValidatorAtIndex(8)
+			require.NoError(t, err)
+			newVal.PublicKey = bytesutil.PadTo([]byte("new-validator"), len(newVal.PublicKey))
+			require.NoError(t, target.AppendValidator(newVal))
+			require.NoError(t, target.AppendBalance(params.BeaconConfig().MaxEffectiveBalance))
+			if target.Version() != 0 {
+				require.NoError(t, target.AppendInactivityScore(0))
+				require.NoError(t, target.AppendCurrentParticipationBits(0))
+				require.NoError(t, target.AppendPreviousParticipationBits(0))
+			}
+
+			diff, err := Diff(base, target)
+			require.NoError(t, err)
+			got, err := Apply(base, diff)
+			require.NoError(t, err)
+			requireSameState(t, target, got)
+
+			// The base state is not modified.
+			requireSameState(t, genesis(), base)
+
+			// The diff is much smaller than the state.
+			full, err := target.MarshalSSZ()
+			require.NoError(t, err)
+			assert.Equal(t, true, len(diff) < len(full)/10, "diff of %d bytes for a state of %d bytes", len(diff), len(full))
+
+			// Diffing a state against itself is an empty diff.
+			diff, err = Diff(target, target)
+			require.NoError(t, err)
+			got, err = Apply(target, diff)
+			require.NoError(t, err)
+			requireSameState(t, target, got)
+		})
+	}
+}
+
+func TestDiff_DifferentVersions(t *testing.T) {
+	phase0, _ := util.DeterministicGenesisState(t, 16)
+	altair, _ := util.DeterministicGenesisStateAltair(t, 16)
+	_, err := Diff(phase0, altair)
+	require.ErrorContains(t, "cannot diff a altair state against a phase0 state", err)
+
+	diff, err := Diff(altair, altair)
+	require.NoError(t, err)
+	_, err = Apply(phase0, diff)
+	require.ErrorContains(t, "cannot apply a altair state diff to a phase0 state", err)
+}
+
+func TestApply_InvalidDiff(t *testing.T) {
+	st, _ := util.DeterministicGenesisState(t, 16)
+	_, err := Apply(st, []byte("not a diff"))
+	require.ErrorContains(t, "could not decompress state diff", err)
+}
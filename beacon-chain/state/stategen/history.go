@@ -20,6 +20,13 @@ func WithCache(c CachedGetter) CanonicalHistoryOption {
 	}
 }
 
+// WithReplayCache caches the intermediate states of the replays, which later replays resume from.
+func WithReplayCache(c *ReplayCache) CanonicalHistoryOption {
+	return func(h *CanonicalHistory) {
+		h.replayCache = c
+	}
+}
+
 type CanonicalHistoryOption func(*CanonicalHistory)
 
 func NewCanonicalHistory(h HistoryAccessor, cc CanonicalChecker, cs CurrentSlotter, opts ...CanonicalHistoryOption) *CanonicalHistory {
@@ -35,14 +42,15 @@ func NewCanonicalHistory(h HistoryAccessor, cc CanonicalChecker, cs CurrentSlott
 }
 
 type CanonicalHistory struct {
-	h     HistoryAccessor
-	cc    CanonicalChecker
-	cs    CurrentSlotter
-	cache CachedGetter
+	h           HistoryAccessor
+	cc          CanonicalChecker
+	cs          CurrentSlotter
+	cache       CachedGetter
+	replayCache *ReplayCache
 }
 
 func (c *CanonicalHistory) ReplayerForSlot(target primitives.Slot) Replayer {
-	return &stateReplayer{chainer: c, method: forSlot, target: target}
+	return &stateReplayer{chainer: c, method: forSlot, target: target, cache: c.replayCache}
 }
 
 func (c *CanonicalHistory) BlockRootForSlot(ctx context.Context, target primitives.Slot) ([32]byte, error) {
@@ -123,6 +131,11 @@ func (c *CanonicalHistory) chainForSlot(ctx context.Context, target primitives.S
 }
 
 func (c *CanonicalHistory) getState(ctx context.Context, blockRoot [32]byte) (state.BeaconState, error) {
+	if c.replayCache != nil {
+		if st, err := c.replayCache.ByBlockRoot(blockRoot); err == nil {
+			return st, nil
+		}
+	}
 	if c.cache != nil {
 		st, err := c.cache.ByBlockRoot(blockRoot)
 		if err == nil {
@@ -25,4 +25,28 @@ var (
 			Help: "Time it took to replay to slot",
 		},
 	)
+	replayQueueWaitSummary = promauto.NewSummary(
+		prometheus.SummaryOpts{
+			Name: "replay_queue_wait_milliseconds",
+			Help: "Time a scheduled state replay waited for a free replay slot",
+		},
+	)
+	replayQueueDepth = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "replay_queue_depth",
+			Help: "The number of scheduled state replays waiting for a free replay slot",
+		},
+	)
+	replaysInProgress = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "replays_in_progress",
+			Help: "The number of scheduled state replays in progress",
+		},
+	)
+	replayDeduplicatedCount = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "replay_deduplicated_total",
+			Help: "The number of state replay requests served by a replay already in progress",
+		},
+	)
 )
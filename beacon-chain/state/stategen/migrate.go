@@ -7,6 +7,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	"github.com/theQRL/qrysm/v4/config/params"
 	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
 	"go.opencensus.io/trace"
 )
@@ -38,13 +39,16 @@ func (s *State) MigrateToCold(ctx context.Context, fRoot [32]byte) error {
 	}
 
 	// Start at previous finalized slot, stop at current finalized slot (it will be handled in the next migration).
-	// If the slot is on archived point, save the state of that slot to the DB.
+	// If the slot is on archived point, save the state of that slot to the DB. With state diffs, the states
+	// of the epoch boundaries in between are saved as diffs to the state of the previous archived point.
 	for slot := oldFSlot; slot < fSlot; slot++ {
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
 
-		if slot%s.slotsPerArchivedPoint == 0 && slot != 0 {
+		archivedPoint := slot%s.slotsPerArchivedPoint == 0
+		diffPoint := s.stateDiffs && slot%params.BeaconConfig().SlotsPerEpoch == 0
+		if (archivedPoint || diffPoint) && slot != 0 {
 			cached, exists, err := s.epochBoundaryStateCache.getBySlot(slot)
 			if err != nil {
 				return fmt.Errorf("could not get epoch boundary state for slot %d", slot)
@@ -95,12 +99,37 @@ func (s *State) MigrateToCold(ctx context.Context, fRoot [32]byte) error {
 					}
 				}
 				s.saveHotStateDB.lock.Unlock()
+				if archivedPoint && s.stateDiffs {
+					if aState == nil {
+						aState, err = s.beaconDB.State(ctx, aRoot)
+						if err != nil {
+							return err
+						}
+					}
+					s.stateDiffBase = &archivedState{root: aRoot, state: aState.Copy()}
+				}
+				continue
+			}
+
+			if !archivedPoint && s.canSaveStateDiff(aState) {
+				if err := s.beaconDB.SaveStateDiff(ctx, aRoot, aState, s.stateDiffBase.root, s.stateDiffBase.state); err != nil {
+					return err
+				}
+				log.WithFields(
+					logrus.Fields{
+						"slot":     aState.Slot(),
+						"root":     hex.EncodeToString(bytesutil.Trunc(aRoot[:])),
+						"baseSlot": s.stateDiffBase.state.Slot(),
+					}).Debug("Saved state diff in DB")
 				continue
 			}
 
 			if err := s.beaconDB.SaveState(ctx, aState, aRoot); err != nil {
 				return err
 			}
+			if s.stateDiffs {
+				s.stateDiffBase = &archivedState{root: aRoot, state: aState.Copy()}
+			}
 			log.WithFields(
 				logrus.Fields{
 					"slot": aState.Slot(),
@@ -120,3 +149,9 @@ func (s *State) MigrateToCold(ctx context.Context, fRoot [32]byte) error {
 
 	return nil
 }
+
+// canSaveStateDiff returns true if the state can be saved as a diff to the state of the last archived point.
+// After a restart, or at a fork transition, the state is saved in full and becomes the base of the next diffs.
+func (s *State) canSaveStateDiff(st state.BeaconState) bool {
+	return s.stateDiffs && s.stateDiffBase != nil && s.stateDiffBase.state.Version() == st.Version()
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"testing"
 
+	"github.com/sirupsen/logrus"
 	logTest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/theQRL/qrysm/v4/beacon-chain/core/blocks"
 	testDB "github.com/theQRL/qrysm/v4/beacon-chain/db/testing"
 	doublylinkedtree "github.com/theQRL/qrysm/v4/beacon-chain/forkchoice/doubly-linked-tree"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	"github.com/theQRL/qrysm/v4/config/params"
 	consensusblocks "github.com/theQRL/qrysm/v4/consensus-types/blocks"
 	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
 	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
@@ -219,3 +222,48 @@ func TestMigrateToCold_ParallelCalls(t *testing.T) {
 	assert.DeepEqual(t, [][32]byte{r7}, service.saveHotStateDB.blockRootsOfSavedStates, "Did not remove all saved hot state roots")
 	require.LogsContain(t, hook, "Saved state in DB")
 }
+
+func TestMigrateToCold_StateDiffs(t *testing.T) {
+	level := logrus.GetLevel()
+	logrus.SetLevel(logrus.DebugLevel)
+	defer logrus.SetLevel(level)
+	hook := logTest.NewGlobal()
+	ctx := context.Background()
+	beaconDB := testDB.SetupDB(t)
+
+	service := New(beaconDB, doublylinkedtree.New(), WithStateDiffs())
+	slotsPerEpoch := params.BeaconConfig().SlotsPerEpoch
+	service.slotsPerArchivedPoint = 2 * slotsPerEpoch
+	service.finalizedInfo.slot = service.slotsPerArchivedPoint
+
+	var roots [][32]byte
+	var states []state.BeaconState
+	for _, slot := range []primitives.Slot{service.slotsPerArchivedPoint, service.slotsPerArchivedPoint + slotsPerEpoch} {
+		beaconState, _ := util.DeterministicGenesisState(t, 32)
+		require.NoError(t, beaconState.SetSlot(slot))
+		require.NoError(t, beaconState.UpdateBalancesAtIndex(0, uint64(slot)))
+		b := util.NewBeaconBlock()
+		b.Block.Slot = slot
+		r, err := b.Block.HashTreeRoot()
+		require.NoError(t, err)
+		util.SaveBlock(t, ctx, service.beaconDB, b)
+		require.NoError(t, service.epochBoundaryStateCache.put(r, beaconState))
+		roots = append(roots, r)
+		states = append(states, beaconState)
+	}
+	b := util.NewBeaconBlock()
+	b.Block.Slot = service.slotsPerArchivedPoint + slotsPerEpoch + 1
+	fRoot, err := b.Block.HashTreeRoot()
+	require.NoError(t, err)
+	util.SaveBlock(t, ctx, service.beaconDB, b)
+	require.NoError(t, service.MigrateToCold(ctx, fRoot))
+
+	require.LogsContain(t, hook, "Saved state in DB")
+	require.LogsContain(t, hook, "Saved state diff in DB")
+	assert.Equal(t, roots[0], service.stateDiffBase.root)
+	for i, r := range roots {
+		gotState, err := service.beaconDB.State(ctx, r)
+		require.NoError(t, err)
+		assert.DeepSSZEqual(t, states[i].ToProtoUnsafe(), gotState.ToProtoUnsafe())
+	}
+}
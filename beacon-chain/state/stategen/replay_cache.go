@@ -0,0 +1,61 @@
+package stategen
+
+import (
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	lruwrpr "github.com/theQRL/qrysm/v4/cache/lru"
+	"github.com/theQRL/qrysm/v4/consensus-types/interfaces"
+)
+
+var (
+	// DefaultReplayCacheSize defines the default max number of intermediate replay states to cache.
+	DefaultReplayCacheSize = 16
+	// Metrics
+	replayCacheHit = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "replay_state_cache_hit",
+		Help: "The total number of cache hits on the replay state cache.",
+	})
+	replayCacheMiss = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "replay_state_cache_miss",
+		Help: "The total number of cache misses on the replay state cache.",
+	})
+)
+
+// ReplayCache stores the intermediate states computed while replaying blocks, keyed by the root of
+// the last block applied to the state. Overlapping replays resume from these states instead of
+// replaying the same blocks again from the saved state they share.
+type ReplayCache struct {
+	cache *lru.Cache
+}
+
+// NewReplayCache initializes a replay cache holding up to size states.
+func NewReplayCache(size int) *ReplayCache {
+	return &ReplayCache{
+		cache: lruwrpr.New(size),
+	}
+}
+
+// ByBlockRoot returns a copy of the cached post-state of the block root.
+func (c *ReplayCache) ByBlockRoot(r [32]byte) (state.BeaconState, error) {
+	item, exists := c.cache.Get(r)
+	if !exists || item == nil {
+		replayCacheMiss.Inc()
+		return nil, ErrNotInCache
+	}
+	replayCacheHit.Inc()
+	return item.(state.BeaconState).Copy(), nil
+}
+
+// put caches a copy of the post-state of the block.
+func (c *ReplayCache) put(b interfaces.ReadOnlySignedBeaconBlock, st state.BeaconState) error {
+	r, err := b.Block().HashTreeRoot()
+	if err != nil {
+		return err
+	}
+	c.cache.Add(r, st.Copy())
+	return nil
+}
+
+var _ CachedGetter = &ReplayCache{}
@@ -0,0 +1,156 @@
+package stategen
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"go.opencensus.io/trace"
+)
+
+// DefaultMaxConcurrentReplays is the default number of state replays a ReplayScheduler runs at once.
+const DefaultMaxConcurrentReplays = 4
+
+var _ ReplayerBuilder = &ReplayScheduler{}
+
+// ReplayScheduler is a ReplayerBuilder which schedules the replays of the underlying builder. Concurrent
+// requests for the same state share a single replay, and at most a fixed number of replays run at once.
+// The other replays wait in a queue, whose depth and wait time are exported as metrics.
+type ReplayScheduler struct {
+	builder ReplayerBuilder
+	slots   chan struct{}
+	lock    sync.Mutex
+	calls   map[replayKey]*replayCall
+}
+
+type replayKey struct {
+	target   primitives.Slot
+	replayTo primitives.Slot
+}
+
+type replayCall struct {
+	done    chan struct{}
+	st      state.BeaconState
+	err     error
+	waiters int
+}
+
+// NewReplayScheduler returns a ReplayScheduler running at most maxConcurrent replays of the builder at once,
+// or DefaultMaxConcurrentReplays if maxConcurrent is not positive.
+func NewReplayScheduler(builder ReplayerBuilder, maxConcurrent int) *ReplayScheduler {
+	if maxConcurrent < 1 {
+		maxConcurrent = DefaultMaxConcurrentReplays
+	}
+	return &ReplayScheduler{
+		builder: builder,
+		slots:   make(chan struct{}, maxConcurrent),
+		calls:   make(map[replayKey]*replayCall),
+	}
+}
+
+// ReplayerForSlot returns a Replayer whose replays are scheduled by the ReplayScheduler.
+func (s *ReplayScheduler) ReplayerForSlot(target primitives.Slot) Replayer {
+	return &scheduledReplayer{scheduler: s, target: target}
+}
+
+// do returns the state of the replay of the key. If the same replay is in progress, it waits for its
+// result instead of starting another one. Every caller receives its own copy of the state.
+func (s *ReplayScheduler) do(ctx context.Context, key replayKey, replay func(context.Context) (state.BeaconState, error)) (state.BeaconState, error) {
+	for {
+		s.lock.Lock()
+		call, ok := s.calls[key]
+		if !ok {
+			call = &replayCall{done: make(chan struct{})}
+			s.calls[key] = call
+			s.lock.Unlock()
+
+			call.st, call.err = s.run(ctx, key, replay)
+			s.lock.Lock()
+			delete(s.calls, key)
+			waiters := call.waiters
+			s.lock.Unlock()
+			close(call.done)
+			if waiters > 0 {
+				log.WithFields(logrus.Fields{
+					"target":   key.target,
+					"replayTo": key.replayTo,
+					"waiters":  waiters,
+				}).Debug("Shared state replay with concurrent requests")
+			}
+			if call.err != nil {
+				return nil, call.err
+			}
+			return call.st.Copy(), nil
+		}
+		call.waiters++
+		s.lock.Unlock()
+
+		replayDeduplicatedCount.Inc()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-call.done:
+		}
+		// The replay was canceled along with the request which started it, so it is started again for this request.
+		if call.err != nil && ctx.Err() == nil && (errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded)) {
+			continue
+		}
+		if call.err != nil {
+			return nil, call.err
+		}
+		return call.st.Copy(), nil
+	}
+}
+
+// run waits for a free replay slot, and replays.
+func (s *ReplayScheduler) run(ctx context.Context, key replayKey, replay func(context.Context) (state.BeaconState, error)) (state.BeaconState, error) {
+	ctx, span := trace.StartSpan(ctx, "stateGen.ReplayScheduler.run")
+	defer span.End()
+
+	start := time.Now()
+	replayQueueDepth.Inc()
+	select {
+	case s.slots <- struct{}{}:
+		replayQueueDepth.Dec()
+	case <-ctx.Done():
+		replayQueueDepth.Dec()
+		return nil, ctx.Err()
+	}
+	defer func() {
+		<-s.slots
+	}()
+	wait := time.Since(start)
+	replayQueueWaitSummary.Observe(float64(wait.Milliseconds()))
+	log.WithFields(logrus.Fields{
+		"target":   key.target,
+		"replayTo": key.replayTo,
+		"wait":     wait,
+	}).Debug("Starting scheduled state replay")
+
+	replaysInProgress.Inc()
+	defer replaysInProgress.Dec()
+	return replay(ctx)
+}
+
+type scheduledReplayer struct {
+	scheduler *ReplayScheduler
+	target    primitives.Slot
+}
+
+// ReplayBlocks replays the blocks up to the target slot through the ReplayScheduler.
+func (r *scheduledReplayer) ReplayBlocks(ctx context.Context) (state.BeaconState, error) {
+	return r.scheduler.do(ctx, replayKey{target: r.target, replayTo: r.target}, func(ctx context.Context) (state.BeaconState, error) {
+		return r.scheduler.builder.ReplayerForSlot(r.target).ReplayBlocks(ctx)
+	})
+}
+
+// ReplayToSlot replays the blocks up to the target slot, and then the slots up to replayTo, through the ReplayScheduler.
+func (r *scheduledReplayer) ReplayToSlot(ctx context.Context, replayTo primitives.Slot) (state.BeaconState, error) {
+	return r.scheduler.do(ctx, replayKey{target: r.target, replayTo: replayTo}, func(ctx context.Context) (state.BeaconState, error) {
+		return r.scheduler.builder.ReplayerForSlot(r.target).ReplayToSlot(ctx, replayTo)
+	})
+}
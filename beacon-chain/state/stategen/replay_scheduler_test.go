@@ -0,0 +1,225 @@
+package stategen
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/util"
+)
+
+type blockingReplayerBuilder struct {
+	st      state.BeaconState
+	release chan struct{}
+	started chan primitives.Slot
+	fail    error
+	replays int32
+	running int32
+	lock    sync.Mutex
+	maxSeen int32
+}
+
+func (b *blockingReplayerBuilder) ReplayerForSlot(target primitives.Slot) Replayer {
+	return &blockingReplayer{b: b, target: target}
+}
+
+type blockingReplayer struct {
+	b      *blockingReplayerBuilder
+	target primitives.Slot
+}
+
+func (r *blockingReplayer) ReplayBlocks(ctx context.Context) (state.BeaconState, error) {
+	return r.ReplayToSlot(ctx, r.target)
+}
+
+func (r *blockingReplayer) ReplayToSlot(ctx context.Context, replayTo primitives.Slot) (state.BeaconState, error) {
+	atomic.AddInt32(&r.b.replays, 1)
+	running := atomic.AddInt32(&r.b.running, 1)
+	defer atomic.AddInt32(&r.b.running, -1)
+	r.b.lock.Lock()
+	if running > r.b.maxSeen {
+		r.b.maxSeen = running
+	}
+	r.b.lock.Unlock()
+	if r.b.started != nil {
+		r.b.started <- r.target
+	}
+	select {
+	case <-ctx.Done():
+		return nil, errors.Wrap(ctx.Err(), "replay canceled")
+	case <-r.b.release:
+	}
+	if r.b.fail != nil {
+		return nil, r.b.fail
+	}
+	st := r.b.st.Copy()
+	if err := st.SetSlot(replayTo); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+func TestReplayScheduler_DeduplicatesReplays(t *testing.T) {
+	ctx := context.Background()
+	st, _ := util.DeterministicGenesisState(t, 32)
+	builder := &blockingReplayerBuilder{st: st, release: make(chan struct{}), started: make(chan primitives.Slot, 10)}
+	s := NewReplayScheduler(builder, 2)
+
+	var wg sync.WaitGroup
+	results := make([]state.BeaconState, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			results[i], err = s.ReplayerForSlot(10).ReplayBlocks(ctx)
+			require.NoError(t, err)
+		}(i)
+	}
+	<-builder.started
+	// Wait for all the requests to join the replay in progress.
+	for waiters(s, replayKey{target: 10, replayTo: 10}) < len(results)-1 {
+		time.Sleep(time.Millisecond)
+	}
+	close(builder.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&builder.replays))
+	for _, res := range results {
+		assert.Equal(t, primitives.Slot(10), res.Slot())
+	}
+	// Every caller receives its own copy of the state.
+	require.NoError(t, results[0].SetSlot(11))
+	assert.Equal(t, primitives.Slot(10), results[1].Slot())
+
+	// A different replay target is a different replay.
+	res, err := s.ReplayerForSlot(10).ReplayToSlot(ctx, 12)
+	require.NoError(t, err)
+	assert.Equal(t, primitives.Slot(12), res.Slot())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&builder.replays))
+}
+
+func waiters(s *ReplayScheduler, key replayKey) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	call, ok := s.calls[key]
+	if !ok {
+		return 0
+	}
+	return call.waiters
+}
+
+func TestReplayScheduler_LimitsConcurrentReplays(t *testing.T) {
+	ctx := context.Background()
+	st, _ := util.DeterministicGenesisState(t, 32)
+	builder := &blockingReplayerBuilder{st: st, release: make(chan struct{}), started: make(chan primitives.Slot, 10)}
+	s := NewReplayScheduler(builder, 2)
+
+	var wg sync.WaitGroup
+	for i := primitives.Slot(1); i <= 5; i++ {
+		wg.Add(1)
+		go func(slot primitives.Slot) {
+			defer wg.Done()
+			_, err := s.ReplayerForSlot(slot).ReplayBlocks(ctx)
+			require.NoError(t, err)
+		}(i)
+	}
+	<-builder.started
+	<-builder.started
+	close(builder.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(5), atomic.LoadInt32(&builder.replays))
+	assert.Equal(t, true, builder.maxSeen <= 2, "%d replays ran at once", builder.maxSeen)
+}
+
+func TestReplayScheduler_CanceledRequest(t *testing.T) {
+	st, _ := util.DeterministicGenesisState(t, 32)
+	builder := &blockingReplayerBuilder{st: st, release: make(chan struct{}), started: make(chan primitives.Slot, 10)}
+	s := NewReplayScheduler(builder, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := s.ReplayerForSlot(10).ReplayBlocks(ctx)
+		errs <- err
+	}()
+	<-builder.started
+
+	// A request joining the replay of a canceled request starts the replay again.
+	results := make(chan state.BeaconState, 1)
+	go func() {
+		res, err := s.ReplayerForSlot(10).ReplayBlocks(context.Background())
+		require.NoError(t, err)
+		results <- res
+	}()
+	for waiters(s, replayKey{target: 10, replayTo: 10}) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	require.ErrorIs(t, <-errs, context.Canceled)
+	<-builder.started
+	close(builder.release)
+	assert.Equal(t, primitives.Slot(10), (<-results).Slot())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&builder.replays))
+}
+
+func TestReplayScheduler_Error(t *testing.T) {
+	st, _ := util.DeterministicGenesisState(t, 32)
+	release := make(chan struct{})
+	close(release)
+	builder := &blockingReplayerBuilder{st: st, release: release, fail: errors.New("replay failed")}
+	s := NewReplayScheduler(builder, 1)
+	_, err := s.ReplayerForSlot(10).ReplayBlocks(context.Background())
+	require.ErrorContains(t, "replay failed", err)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	assert.Equal(t, 0, len(s.calls))
+}
+
+func TestReplayCache_SharesIntermediateStates(t *testing.T) {
+	ctx := context.Background()
+	var zero, one, two, three, four, five primitives.Slot = 50, 51, 150, 151, 152, 200
+	specs := []mockHistorySpec{
+		{slot: zero},
+		{slot: one, savedState: true},
+		{slot: two},
+		{slot: three},
+		{slot: four},
+		{slot: five, canonicalBlock: true},
+	}
+	hist := newMockHistory(t, specs, five+10)
+	cache := NewReplayCache(DefaultReplayCacheSize)
+	ch := NewCanonicalHistory(hist, hist, hist, WithReplayCache(cache))
+	st, err := ch.ReplayerForSlot(five).ReplayBlocks(ctx)
+	require.NoError(t, err)
+
+	// The post-states of the last replayed block of each epoch are cached.
+	for _, slot := range []primitives.Slot{four, five} {
+		cached, err := cache.ByBlockRoot(hist.slotMap[slot])
+		require.NoError(t, err)
+		assert.Equal(t, slot, cached.Slot())
+	}
+	_, err = cache.ByBlockRoot(hist.slotMap[three])
+	require.ErrorIs(t, err, ErrNotInCache)
+
+	// Without the saved state, a later replay resumes from the cached state.
+	delete(hist.states, hist.slotMap[one])
+	again, err := ch.ReplayerForSlot(five + 10).ReplayBlocks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, five+10, again.Slot())
+	stRoot, err := st.HashTreeRoot(ctx)
+	require.NoError(t, err)
+	cached, err := cache.ByBlockRoot(hist.slotMap[five])
+	require.NoError(t, err)
+	cachedRoot, err := cached.HashTreeRoot(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, stRoot, cachedRoot)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/theQRL/qrysm/v4/beacon-chain/state"
 	"github.com/theQRL/qrysm/v4/consensus-types/interfaces"
 	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/time/slots"
 	"go.opencensus.io/trace"
 )
 
@@ -66,6 +67,8 @@ type stateReplayer struct {
 	target  primitives.Slot
 	method  retrievalMethod
 	chainer chainer
+	// cache receives the post-state of the last block of each epoch replayed.
+	cache *ReplayCache
 }
 
 // ReplayBlocks applies all the blocks that were accumulated when building the Replayer.
@@ -99,7 +102,7 @@ func (rs *stateReplayer) ReplayBlocks(ctx context.Context) (state.BeaconState, e
 		"diff":      diff,
 	}).Debug("Replaying canonical blocks from most recent state")
 
-	for _, b := range descendants {
+	for i, b := range descendants {
 		if ctx.Err() != nil {
 			return nil, ctx.Err()
 		}
@@ -107,6 +110,11 @@ func (rs *stateReplayer) ReplayBlocks(ctx context.Context) (state.BeaconState, e
 		if err != nil {
 			return nil, err
 		}
+		if rs.cache != nil && (i == len(descendants)-1 || slots.ToEpoch(descendants[i+1].Block().Slot()) > slots.ToEpoch(b.Block().Slot())) {
+			if err := rs.cache.put(b, s); err != nil {
+				return nil, errors.Wrap(err, "could not cache replayed state")
+			}
+		}
 	}
 	if rs.target > s.Slot() {
 		s, err = ReplayProcessSlots(ctx, s, rs.target)
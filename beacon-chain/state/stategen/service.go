@@ -53,6 +53,15 @@ type State struct {
 	backfillStatus          *backfill.Status
 	migrationLock           *sync.Mutex
 	fc                      forkchoice.ForkChoicer
+	stateDiffs              bool
+	// stateDiffBase is the last full state saved on an archived point, which the
+	// state diffs of the following epoch boundaries are based on.
+	stateDiffBase *archivedState
+}
+
+type archivedState struct {
+	root  [32]byte
+	state state.BeaconState
 }
 
 // This tracks the config in the event of long non-finality,
@@ -83,6 +92,15 @@ func WithBackfillStatus(bfs *backfill.Status) StateGenOption {
 	}
 }
 
+// WithStateDiffs saves the epoch boundary states between archived points as compact diffs to the
+// state of the previous archived point, so historical epoch boundary states are retrieved without
+// replaying blocks.
+func WithStateDiffs() StateGenOption {
+	return func(sg *State) {
+		sg.stateDiffs = true
+	}
+}
+
 // New returns a new state management object.
 func New(beaconDB db.NoHeadAccessDatabase, fc forkchoice.ForkChoicer, opts ...StateGenOption) *State {
 	s := &State{
@@ -135,6 +153,11 @@ func (s *State) Resume(ctx context.Context, fState state.BeaconState) (state.Bea
 	}
 
 	go func() {
+		if s.stateDiffs {
+			if err := s.beaconDB.MigrateStatesToDiffs(ctx, s.slotsPerArchivedPoint); err != nil {
+				log.WithError(err).Error("Could not migrate states to state diffs")
+			}
+		}
 		if err := s.beaconDB.CleanUpDirtyStates(ctx, s.slotsPerArchivedPoint); err != nil {
 			log.WithError(err).Error("Could not clean up dirty states")
 		}
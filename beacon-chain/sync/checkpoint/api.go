@@ -42,5 +42,22 @@ func (dl *APIInitializer) Initialize(ctx context.Context, d db.Database) error {
 	if err != nil {
 		return errors.Wrap(err, "Error retrieving checkpoint origin state and block")
 	}
-	return d.SaveOrigin(ctx, od.StateBytes(), od.BlockBytes())
+	if err := d.SaveOrigin(ctx, od.StateBytes(), od.BlockBytes()); err != nil {
+		return err
+	}
+	// The deposit snapshot lets the node process only the deposit logs after the finalized deposits, but
+	// the deposit logs can still be processed from the deposit contract deployment without it.
+	snapshot, err := dl.c.GetDepositSnapshot(ctx)
+	if err != nil {
+		log.WithError(err).Warn("Could not retrieve deposit snapshot from checkpoint sync provider")
+		return nil
+	}
+	if err := snapshot.VerifyEth1Data(od.State().Eth1Data()); err != nil {
+		log.WithError(err).Warn("Ignoring deposit snapshot from checkpoint sync provider which does not match the eth1 data of the checkpoint state")
+		return nil
+	}
+	if err := d.SaveDepositSnapshot(ctx, snapshot); err != nil {
+		return errors.Wrap(err, "could not save deposit snapshot")
+	}
+	return nil
 }
@@ -0,0 +1,79 @@
+package sync
+
+import (
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	ssz "github.com/prysmaticlabs/fastssz"
+	"github.com/theQRL/qrysm/v4/consensus-types/interfaces"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+)
+
+// startGossipCapture records the head of the chain as the anchor of the gossip capture,
+// from which a replay of the capture starts. The capture is stopped if the anchor cannot
+// be recorded.
+func (s *Service) startGossipCapture() {
+	if s.cfg.gossipCapture == nil {
+		return
+	}
+	err := func() error {
+		st, err := s.cfg.chain.HeadState(s.ctx)
+		if err != nil {
+			return err
+		}
+		blk, err := s.cfg.chain.HeadBlock(s.ctx)
+		if err != nil {
+			return err
+		}
+		return s.cfg.gossipCapture.WriteAnchor(st, blk)
+	}()
+	if err != nil {
+		log.WithError(err).Error("Could not start gossip capture")
+		if err := s.cfg.gossipCapture.Close(); err != nil {
+			log.WithError(err).Error("Could not close gossip capture")
+		}
+		s.cfg.gossipCapture = nil
+		return
+	}
+	log.Info("Capturing gossip blocks and attestations for fork choice replay")
+}
+
+// captureGossip records a decoded gossip block, attestation or aggregate at the time it was
+// received. It is called before the message is validated, so that the capture holds what
+// the node received, including the messages it queues as pending or later ignores.
+func (s *Service) captureGossip(receivedTime time.Time, m ssz.Unmarshaler) {
+	if s.cfg.gossipCapture == nil {
+		return
+	}
+	var err error
+	switch msg := m.(type) {
+	case interfaces.ReadOnlySignedBeaconBlock:
+		err = s.cfg.gossipCapture.WriteBlock(receivedTime, msg)
+	case *zondpb.Attestation:
+		err = s.cfg.gossipCapture.WriteAttestation(receivedTime, msg)
+	case *zondpb.SignedAggregateAttestationAndProof:
+		if msg.Message == nil {
+			return
+		}
+		err = s.cfg.gossipCapture.WriteAttestation(receivedTime, msg.Message.Aggregate)
+	default:
+		return
+	}
+	if err != nil {
+		log.WithError(err).Debug("Could not capture gossip message")
+	}
+}
+
+// captureOwnGossip records a message published by the node itself, which its validators
+// accept without decoding.
+func (s *Service) captureOwnGossip(receivedTime time.Time, msg *pubsub.Message) {
+	if s.cfg.gossipCapture == nil {
+		return
+	}
+	m, err := s.decodePubsubMessage(msg)
+	if err != nil {
+		log.WithError(err).Debug("Could not decode gossip message to capture")
+		return
+	}
+	s.captureGossip(receivedTime, m)
+}
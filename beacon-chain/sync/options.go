@@ -6,6 +6,7 @@ import (
 	"github.com/theQRL/qrysm/v4/beacon-chain/core/feed/operation"
 	"github.com/theQRL/qrysm/v4/beacon-chain/db"
 	"github.com/theQRL/qrysm/v4/beacon-chain/execution"
+	"github.com/theQRL/qrysm/v4/beacon-chain/forkchoice/replay"
 	"github.com/theQRL/qrysm/v4/beacon-chain/operations/attestations"
 	"github.com/theQRL/qrysm/v4/beacon-chain/operations/blstoexec"
 	"github.com/theQRL/qrysm/v4/beacon-chain/operations/slashings"
@@ -137,6 +138,15 @@ func WithClockWaiter(cw startup.ClockWaiter) Option {
 	}
 }
 
+// WithGossipCapture records the blocks and attestations received over gossip, along with
+// their arrival times, so that they can be replayed through fork choice.
+func WithGossipCapture(w *replay.Writer) Option {
+	return func(s *Service) error {
+		s.cfg.gossipCapture = w
+		return nil
+	}
+}
+
 func WithInitialSyncComplete(c chan struct{}) Option {
 	return func(s *Service) error {
 		s.initialSyncComplete = c
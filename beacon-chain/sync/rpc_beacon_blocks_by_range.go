@@ -129,34 +129,42 @@ func (s *Service) writeBlockBatchToStream(ctx context.Context, batch blockBatch,
 	ctx, span := trace.StartSpan(ctx, "sync.WriteBlockRangeToStream")
 	defer span.End()
 
+	canonical := batch.canonical()
+	blocksToWrite := make([]interfaces.ReadOnlySignedBeaconBlock, 0, len(canonical))
 	blinded := make([]interfaces.ReadOnlySignedBeaconBlock, 0)
-	for _, b := range batch.canonical() {
+	blindedIdx := make([]int, 0)
+	for _, b := range canonical {
 		if err := blocks.BeaconBlockIsNil(b); err != nil {
 			continue
 		}
 		if b.IsBlinded() {
 			blinded = append(blinded, b.ReadOnlySignedBeaconBlock)
-			continue
-		}
-		if chunkErr := s.chunkBlockWriter(stream, b); chunkErr != nil {
-			log.WithError(chunkErr).Debug("Could not send a chunked response")
-			return chunkErr
+			blindedIdx = append(blindedIdx, len(blocksToWrite))
 		}
-	}
-	if len(blinded) == 0 {
-		return nil
+		blocksToWrite = append(blocksToWrite, b.ReadOnlySignedBeaconBlock)
 	}
 
-	reconstructed, err := s.cfg.executionPayloadReconstructor.ReconstructFullBellatrixBlockBatch(ctx, blinded)
-	if err != nil {
-		log.WithError(err).Error("Could not reconstruct full bellatrix block batch from blinded bodies")
-		return err
-	}
-	for _, b := range reconstructed {
-		if err := blocks.BeaconBlockIsNil(b); err != nil {
-			continue
+	// Blinded blocks are reconstructed in a single batch, so that their payload bodies are
+	// fetched by range, and then written in slot order along with the full blocks.
+	if len(blinded) > 0 {
+		reconstructed, err := s.cfg.executionPayloadReconstructor.ReconstructFullBellatrixBlockBatch(ctx, blinded)
+		if err != nil {
+			log.WithError(err).Error("Could not reconstruct full bellatrix block batch from blinded bodies")
+			return err
 		}
-		if b.IsBlinded() {
+		if len(reconstructed) != len(blinded) {
+			return errors.Errorf("reconstructed %d blocks from %d blinded blocks", len(reconstructed), len(blinded))
+		}
+		for i, b := range reconstructed {
+			if err := blocks.BeaconBlockIsNil(b); err != nil || b.IsBlinded() {
+				blocksToWrite[blindedIdx[i]] = nil
+				continue
+			}
+			blocksToWrite[blindedIdx[i]] = b
+		}
+	}
+	for _, b := range blocksToWrite {
+		if b == nil {
 			continue
 		}
 		if chunkErr := s.chunkBlockWriter(stream, b); chunkErr != nil {
@@ -164,6 +172,5 @@ func (s *Service) writeBlockBatchToStream(ctx context.Context, batch blockBatch,
 			return chunkErr
 		}
 	}
-
 	return nil
 }
@@ -24,6 +24,7 @@ import (
 	"github.com/theQRL/qrysm/v4/beacon-chain/core/feed/operation"
 	"github.com/theQRL/qrysm/v4/beacon-chain/db"
 	"github.com/theQRL/qrysm/v4/beacon-chain/execution"
+	"github.com/theQRL/qrysm/v4/beacon-chain/forkchoice/replay"
 	"github.com/theQRL/qrysm/v4/beacon-chain/operations/attestations"
 	"github.com/theQRL/qrysm/v4/beacon-chain/operations/blstoexec"
 	"github.com/theQRL/qrysm/v4/beacon-chain/operations/slashings"
@@ -86,6 +87,7 @@ type config struct {
 	slasherAttestationsFeed       *event.Feed
 	slasherBlockHeadersFeed       *event.Feed
 	clock                         *startup.Clock
+	gossipCapture                 *replay.Writer
 }
 
 // This defines the interface for interacting with block chain service
@@ -205,6 +207,9 @@ func (s *Service) Stop() error {
 		s.unSubscribeFromTopic(t)
 	}
 	defer s.cancel()
+	if s.cfg.gossipCapture != nil {
+		return s.cfg.gossipCapture.Close()
+	}
 	return nil
 }
 
@@ -264,6 +269,7 @@ func (s *Service) registerHandlers() {
 			return
 		}
 		currentEpoch := slots.ToEpoch(slots.CurrentSlot(uint64(s.cfg.clock.GenesisTime().Unix())))
+		s.startGossipCapture()
 		s.registerSubscribers(currentEpoch, digest)
 		go s.forkWatcher()
 		return
@@ -30,6 +30,7 @@ import (
 func (s *Service) validateAggregateAndProof(ctx context.Context, pid peer.ID, msg *pubsub.Message) (pubsub.ValidationResult, error) {
 	receivedTime := prysmTime.Now()
 	if pid == s.cfg.p2p.PeerID() {
+		s.captureOwnGossip(receivedTime, msg)
 		return pubsub.ValidationAccept, nil
 	}
 
@@ -47,6 +48,7 @@ func (s *Service) validateAggregateAndProof(ctx context.Context, pid peer.ID, ms
 		tracing.AnnotateError(span, err)
 		return pubsub.ValidationReject, err
 	}
+	s.captureGossip(receivedTime, raw)
 	m, ok := raw.(*zondpb.SignedAggregateAttestationAndProof)
 	if !ok {
 		return pubsub.ValidationReject, errors.Errorf("invalid message type: %T", raw)
@@ -40,6 +40,7 @@ func (s *Service) validateBeaconBlockPubSub(ctx context.Context, pid peer.ID, ms
 	// Validation runs on publish (not just subscriptions), so we should approve any message from
 	// ourselves.
 	if pid == s.cfg.p2p.PeerID() {
+		s.captureOwnGossip(receivedTime, msg)
 		return pubsub.ValidationAccept, nil
 	}
 
@@ -56,6 +57,7 @@ func (s *Service) validateBeaconBlockPubSub(ctx context.Context, pid peer.ID, ms
 		tracing.AnnotateError(span, err)
 		return pubsub.ValidationReject, errors.Wrap(err, "Could not decode message")
 	}
+	s.captureGossip(receivedTime, m)
 
 	s.validateBlockLock.Lock()
 	defer s.validateBlockLock.Unlock()
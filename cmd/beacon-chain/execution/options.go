@@ -7,6 +7,7 @@ import (
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositsnapshot"
 	"github.com/theQRL/qrysm/v4/beacon-chain/execution"
 	"github.com/theQRL/qrysm/v4/cmd/beacon-chain/flags"
 	"github.com/theQRL/qrysm/v4/io/file"
@@ -32,6 +33,57 @@ func FlagOptions(c *cli.Context) ([]execution.Option, error) {
 	if len(jwtSecret) > 0 {
 		opts = append(opts, execution.WithHttpEndpointAndJWTSecret(endpoint, jwtSecret))
 	}
+	fallbackOpts, err := parseFallbackExecutionEndpoints(c, jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, fallbackOpts...)
+	if snapshotPath := c.Path(flags.DepositSnapshotPath.Name); snapshotPath != "" {
+		snapshot, err := readDepositSnapshot(snapshotPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read deposit snapshot file")
+		}
+		opts = append(opts, execution.WithDepositSnapshot(snapshot))
+	}
+	return opts, nil
+}
+
+// Reads a finalized deposit snapshot from a file, encoded either in SSZ or in JSON as served by the
+// beacon API.
+func readDepositSnapshot(snapshotPath string) (*depositsnapshot.DepositTreeSnapshot, error) {
+	enc, err := file.ReadFileAsBytes(snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+	return depositsnapshot.DecodeSnapshot(enc)
+}
+
+// Parses the fallback execution endpoints and their JWT secrets. The secret files are given in the
+// same order as the endpoints, and endpoints without one use the secret of the main endpoint.
+func parseFallbackExecutionEndpoints(c *cli.Context, defaultSecret []byte) ([]execution.Option, error) {
+	endpoints := c.StringSlice(flags.FallbackExecutionEndpoints.Name)
+	secretFiles := c.StringSlice(flags.FallbackExecutionJWTSecrets.Name)
+	if len(secretFiles) > len(endpoints) {
+		return nil, fmt.Errorf("%d fallback JWT secrets provided for %d fallback execution endpoints", len(secretFiles), len(endpoints))
+	}
+	var opts []execution.Option
+	for i, endpoint := range endpoints {
+		secret := defaultSecret
+		if i < len(secretFiles) {
+			var err error
+			secret, err = readJWTSecret(secretFiles[i])
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not read JWT secret file for fallback execution endpoint %d", i)
+			}
+		}
+		opts = append(opts, execution.WithFallbackHttpEndpoint(endpoint, secret))
+	}
+	if len(endpoints) > 0 {
+		opts = append(opts,
+			execution.WithHealthCheckInterval(c.Duration(flags.ExecutionHealthCheckInterval.Name)),
+			execution.WithMaxSyncingDuration(c.Duration(flags.ExecutionMaxSyncingDuration.Name)),
+		)
+	}
 	return opts, nil
 }
 
@@ -48,6 +100,10 @@ func parseJWTSecretFromFile(c *cli.Context) ([]byte, error) {
 	if jwtSecretFile == "" {
 		return nil, nil
 	}
+	return readJWTSecret(jwtSecretFile)
+}
+
+func readJWTSecret(jwtSecretFile string) ([]byte, error) {
 	enc, err := file.ReadFileAsBytes(jwtSecretFile)
 	if err != nil {
 		return nil, err
@@ -6,7 +6,9 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositsnapshot"
 	"github.com/theQRL/qrysm/v4/cmd/beacon-chain/flags"
+	"github.com/theQRL/qrysm/v4/container/trie"
 	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
 	"github.com/theQRL/qrysm/v4/io/file"
 	"github.com/theQRL/qrysm/v4/testing/assert"
@@ -99,3 +101,74 @@ func TestPowchainPreregistration_EmptyWeb3Provider(t *testing.T) {
 	_, err := parseExecutionChainEndpoint(ctx)
 	assert.ErrorContains(t, "you need to specify", err)
 }
+
+func Test_parseFallbackExecutionEndpoints(t *testing.T) {
+	secretPath := filepath.Join(t.TempDir(), "fallbackhex")
+	secret := bytesutil.ToBytes32([]byte("fallback"))
+	require.NoError(t, file.WriteFile(secretPath, []byte(fmt.Sprintf("%#x", secret))))
+
+	t.Run("no fallback endpoints", func(t *testing.T) {
+		app := cli.App{}
+		set := flag.NewFlagSet("test", 0)
+		ctx := cli.NewContext(&app, set, nil)
+		opts, err := parseFallbackExecutionEndpoints(ctx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 0, len(opts))
+	})
+	t.Run("more secrets than endpoints", func(t *testing.T) {
+		app := cli.App{}
+		set := flag.NewFlagSet("test", 0)
+		endpoints := cli.NewStringSlice("http://localhost:8552")
+		set.Var(endpoints, flags.FallbackExecutionEndpoints.Name, "")
+		secrets := cli.NewStringSlice(secretPath, secretPath)
+		set.Var(secrets, flags.FallbackExecutionJWTSecrets.Name, "")
+		ctx := cli.NewContext(&app, set, nil)
+		_, err := parseFallbackExecutionEndpoints(ctx, nil)
+		assert.ErrorContains(t, "2 fallback JWT secrets provided for 1 fallback execution endpoints", err)
+	})
+	t.Run("endpoints with and without their own secret", func(t *testing.T) {
+		app := cli.App{}
+		set := flag.NewFlagSet("test", 0)
+		endpoints := cli.NewStringSlice("http://localhost:8552", "http://localhost:8553")
+		set.Var(endpoints, flags.FallbackExecutionEndpoints.Name, "")
+		secrets := cli.NewStringSlice(secretPath)
+		set.Var(secrets, flags.FallbackExecutionJWTSecrets.Name, "")
+		ctx := cli.NewContext(&app, set, nil)
+		opts, err := parseFallbackExecutionEndpoints(ctx, []byte("main"))
+		require.NoError(t, err)
+		// One option per endpoint, then the health check interval and max syncing duration.
+		assert.Equal(t, 4, len(opts))
+	})
+	t.Run("unreadable secret file", func(t *testing.T) {
+		app := cli.App{}
+		set := flag.NewFlagSet("test", 0)
+		endpoints := cli.NewStringSlice("http://localhost:8552")
+		set.Var(endpoints, flags.FallbackExecutionEndpoints.Name, "")
+		secrets := cli.NewStringSlice("/tmp/askdjkajsd")
+		set.Var(secrets, flags.FallbackExecutionJWTSecrets.Name, "")
+		ctx := cli.NewContext(&app, set, nil)
+		_, err := parseFallbackExecutionEndpoints(ctx, nil)
+		assert.ErrorContains(t, "could not read JWT secret file for fallback execution endpoint 0", err)
+	})
+}
+
+func Test_readDepositSnapshot(t *testing.T) {
+	depositTrie, err := trie.GenerateTrieFromItems([][]byte{{'a'}, {'b'}, {'c'}}, 32)
+	require.NoError(t, err)
+	want, err := depositsnapshot.SnapshotFromTrie(depositTrie, 3, [32]byte{'h'}, 10)
+	require.NoError(t, err)
+	enc, err := want.MarshalSSZ()
+	require.NoError(t, err)
+	snapshotPath := filepath.Join(t.TempDir(), "snapshot.ssz")
+	require.NoError(t, file.WriteFile(snapshotPath, enc))
+
+	got, err := readDepositSnapshot(snapshotPath)
+	require.NoError(t, err)
+	assert.DeepEqual(t, want, got)
+
+	_, err = readDepositSnapshot(filepath.Join(t.TempDir(), "missing"))
+	assert.NotNil(t, err)
+	require.NoError(t, file.WriteFile(snapshotPath, enc[:10]))
+	_, err = readDepositSnapshot(snapshotPath)
+	assert.ErrorContains(t, "could not decode SSZ deposit snapshot", err)
+}
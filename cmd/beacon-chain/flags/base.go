@@ -3,17 +3,19 @@
 package flags
 
 import (
+	"time"
+
 	"github.com/theQRL/qrysm/v4/cmd"
 	"github.com/theQRL/qrysm/v4/config/params"
 	"github.com/urfave/cli/v2"
 )
 
 var (
-	// MevRelayEndpoint provides an HTTP access endpoint to a MEV builder network.
-	MevRelayEndpoint = &cli.StringFlag{
-		Name:  "http-mev-relay",
-		Usage: "A MEV builder relay string http endpoint, this wil be used to interact MEV builder network using API defined in: https://ethereum.github.io/builder-specs/#/Builder",
-		Value: "",
+	// MevRelayEndpoint provides HTTP access endpoints to a MEV builder network.
+	MevRelayEndpoint = &cli.StringSliceFlag{
+		Name: "http-mev-relay",
+		Usage: "A MEV builder relay string http endpoint, this wil be used to interact MEV builder network using API defined in: https://ethereum.github.io/builder-specs/#/Builder. " +
+			"Can be repeated or comma separated to query several relays, in which case the highest valid bid is used",
 	}
 	MaxBuilderConsecutiveMissedSlots = &cli.IntFlag{
 		Name:  "max-builder-consecutive-missed-slots",
@@ -54,6 +56,37 @@ var (
 			"This is not required if using an IPC connection.",
 		Value: "",
 	}
+	// FallbackExecutionEndpoints provides execution client endpoints to fail over to when the endpoint in use is unhealthy.
+	FallbackExecutionEndpoints = &cli.StringSliceFlag{
+		Name: "fallback-execution-endpoint",
+		Usage: "An execution client endpoint to fail over to when the endpoint in use is unreachable or stays syncing. " +
+			"Can be repeated, and endpoints are tried in order after --execution-endpoint.",
+	}
+	// FallbackExecutionJWTSecrets provides the JWT secret files of the fallback execution client endpoints.
+	FallbackExecutionJWTSecrets = &cli.StringSliceFlag{
+		Name: "fallback-jwt-secret",
+		Usage: "A path to a file containing the hex-encoded JWT secret of a fallback execution endpoint, " +
+			"given in the same order as --fallback-execution-endpoint. Endpoints without one use --jwt-secret.",
+	}
+	// ExecutionHealthCheckInterval defines how often the execution client endpoints are probed.
+	ExecutionHealthCheckInterval = &cli.DurationFlag{
+		Name:  "execution-health-check-interval",
+		Usage: "How often the sync status and latency of every execution endpoint is checked when fallback endpoints are set",
+		Value: 12 * time.Second,
+	}
+	// ExecutionMaxSyncingDuration defines how long the execution endpoint in use may stay syncing before failing over.
+	ExecutionMaxSyncingDuration = &cli.DurationFlag{
+		Name:  "execution-max-syncing-duration",
+		Usage: "How long the execution endpoint in use may stay syncing before failing over to a synced fallback endpoint",
+		Value: 2 * time.Minute,
+	}
+	// DepositSnapshotPath defines a flag to start the deposit tree from a finalized deposit snapshot file.
+	DepositSnapshotPath = &cli.PathFlag{
+		Name: "deposit-snapshot",
+		Usage: "Rather than processing every deposit log from the deposit contract deployment, start the deposit tree " +
+			"from an EIP-4881 finalized deposit snapshot, served by beacon nodes at /eth/v1/beacon/deposit_snapshot. " +
+			"This flag allows you to specify a local file containing the snapshot, either ssz-serialized or in json.",
+	}
 	// DepositContractFlag defines a flag for the deposit contract address.
 	DepositContractFlag = &cli.StringFlag{
 		Name:  "deposit-contract",
@@ -152,6 +185,18 @@ var (
 		Usage: "The slot durations of when an archived state gets saved in the beaconDB.",
 		Value: 2048,
 	}
+	// MaxConcurrentStateReplays specifies the number of historical state replays the API runs at once.
+	MaxConcurrentStateReplays = &cli.IntFlag{
+		Name:  "max-concurrent-state-replays",
+		Usage: "The maximum number of historical state regenerations run at once to serve API requests. Further requests wait in a queue, and concurrent requests for the same state share a single regeneration.",
+		Value: 4,
+	}
+	// ArchiveStateDiffs saves the epoch boundary states between archived points as diffs to the state of
+	// the previous archived point.
+	ArchiveStateDiffs = &cli.BoolFlag{
+		Name:  "archive-state-diffs",
+		Usage: "Saves the finalized epoch boundary states between archived points as compact diffs, so historical states are served without replaying blocks. Existing finalized states are converted on startup.",
+	}
 	// BlockBatchLimit specifies the requested block batch size.
 	BlockBatchLimit = &cli.IntFlag{
 		Name:  "block-batch-limit",
@@ -250,4 +295,10 @@ var (
 		Usage: "Directory for the slasher database",
 		Value: cmd.DefaultDataDir(),
 	}
+	// ForkChoiceCaptureFile defines a path on disk where the blocks and attestations received over gossip are captured.
+	ForkChoiceCaptureFile = &cli.StringFlag{
+		Name: "forkchoice-capture-file",
+		Usage: "Captures the blocks and attestations received over gossip, with their arrival times, into the given file. " +
+			"The capture can be replayed through fork choice with the forkchoice-replay tool",
+	}
 )
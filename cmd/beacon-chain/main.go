@@ -39,6 +39,11 @@ var appFlags = []cli.Flag{
 	flags.ExecutionEngineEndpoint,
 	flags.ExecutionEngineHeaders,
 	flags.ExecutionJWTSecretFlag,
+	flags.FallbackExecutionEndpoints,
+	flags.FallbackExecutionJWTSecrets,
+	flags.ExecutionHealthCheckInterval,
+	flags.ExecutionMaxSyncingDuration,
+	flags.DepositSnapshotPath,
 	flags.RPCHost,
 	flags.RPCPort,
 	flags.CertFlag,
@@ -57,6 +62,8 @@ var appFlags = []cli.Flag{
 	flags.InteropNumValidatorsFlag,
 	flags.InteropGenesisTimeFlag,
 	flags.SlotsPerArchivedPoint,
+	flags.ArchiveStateDiffs,
+	flags.MaxConcurrentStateReplays,
 	flags.EnableDebugRPCEndpoints,
 	flags.SubscribeToAllSubnets,
 	flags.HistoricalSlasherNode,
@@ -122,6 +129,15 @@ var appFlags = []cli.Flag{
 	cmd.RestoreSourceFileFlag,
 	cmd.RestoreTargetDirFlag,
 	cmd.ValidatorMonitorIndicesFlag,
+	cmd.EnableValidatorMonitorAPIFlag,
+	cmd.MonitorAlertMissedAttestationsFlag,
+	cmd.MonitorAlertMissedProposalsFlag,
+	cmd.MonitorAlertBalanceDropFlag,
+	cmd.MonitorAlertSlashingFlag,
+	cmd.MonitorAlertCooldownFlag,
+	cmd.MonitorAlertWebhookFlag,
+	cmd.MonitorAlertFileFlag,
+	cmd.MonitorAlertStdoutFlag,
 	cmd.ApiTimeoutFlag,
 	checkpoint.BlockPath,
 	checkpoint.StatePath,
@@ -129,6 +145,7 @@ var appFlags = []cli.Flag{
 	genesis.StatePath,
 	genesis.BeaconAPIURL,
 	flags.SlasherDirFlag,
+	flags.ForkChoiceCaptureFile,
 }
 
 func init() {
@@ -74,6 +74,15 @@ var appHelpFlagGroups = []flagGroup{
 			cmd.RestoreSourceFileFlag,
 			cmd.RestoreTargetDirFlag,
 			cmd.ValidatorMonitorIndicesFlag,
+			cmd.EnableValidatorMonitorAPIFlag,
+			cmd.MonitorAlertMissedAttestationsFlag,
+			cmd.MonitorAlertMissedProposalsFlag,
+			cmd.MonitorAlertBalanceDropFlag,
+			cmd.MonitorAlertSlashingFlag,
+			cmd.MonitorAlertCooldownFlag,
+			cmd.MonitorAlertWebhookFlag,
+			cmd.MonitorAlertFileFlag,
+			cmd.MonitorAlertStdoutFlag,
 			cmd.ApiTimeoutFlag,
 		},
 	},
@@ -108,8 +117,15 @@ var appHelpFlagGroups = []flagGroup{
 			flags.ExecutionEngineEndpoint,
 			flags.ExecutionEngineHeaders,
 			flags.ExecutionJWTSecretFlag,
+			flags.FallbackExecutionEndpoints,
+			flags.FallbackExecutionJWTSecrets,
+			flags.ExecutionHealthCheckInterval,
+			flags.ExecutionMaxSyncingDuration,
+			flags.DepositSnapshotPath,
 			flags.SetGCPercent,
 			flags.SlotsPerArchivedPoint,
+			flags.ArchiveStateDiffs,
+			flags.MaxConcurrentStateReplays,
 			flags.BlockBatchLimit,
 			flags.BlockBatchLimitBurstFactor,
 			flags.EnableDebugRPCEndpoints,
@@ -125,6 +141,7 @@ var appHelpFlagGroups = []flagGroup{
 			flags.MaxBuilderConsecutiveMissedSlots,
 			flags.EngineEndpointTimeoutSeconds,
 			flags.SlasherDirFlag,
+			flags.ForkChoiceCaptureFile,
 			checkpoint.BlockPath,
 			checkpoint.StatePath,
 			checkpoint.RemoteURL,
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"time"
 
 	"github.com/theQRL/qrysm/v4/config/params"
 	"github.com/urfave/cli/v2"
@@ -240,6 +241,56 @@ var (
 		Name:  "monitor-indices",
 		Usage: "List of validator indices to track performance",
 	}
+	// EnableValidatorMonitorAPIFlag enables changing the validators tracked by the validator monitor
+	// at runtime.
+	EnableValidatorMonitorAPIFlag = &cli.BoolFlag{
+		Name: "enable-validator-monitor-api",
+		Usage: "Starts the validator monitor, and enables adding and removing the validators it tracks, by index or " +
+			"public key, through POST and DELETE requests to the /prysm/validators/monitor endpoint. " +
+			"The endpoint is not authenticated, so the HTTP API of the node must not be exposed publicly",
+	}
+	// MonitorAlertMissedAttestationsFlag specifies the number of consecutive missed attestations of a
+	// tracked validator raising an alert.
+	MonitorAlertMissedAttestationsFlag = &cli.Uint64Flag{
+		Name:  "monitor-alert-missed-attestations",
+		Usage: "Raises an alert when a tracked validator misses this number of consecutive attestations, 0 disables the alert",
+	}
+	// MonitorAlertMissedProposalsFlag raises an alert when a tracked validator misses a proposal.
+	MonitorAlertMissedProposalsFlag = &cli.BoolFlag{
+		Name:  "monitor-alert-missed-proposals",
+		Usage: "Raises an alert when a tracked validator misses a proposal",
+	}
+	// MonitorAlertBalanceDropFlag specifies the balance drop over an epoch of a tracked validator raising an alert.
+	MonitorAlertBalanceDropFlag = &cli.Uint64Flag{
+		Name:  "monitor-alert-balance-drop",
+		Usage: "Raises an alert when the balance of a tracked validator drops by this amount in Gwei over an epoch, 0 disables the alert",
+	}
+	// MonitorAlertSlashingFlag raises an alert when a tracked validator is slashed.
+	MonitorAlertSlashingFlag = &cli.BoolFlag{
+		Name:  "monitor-alert-slashing",
+		Usage: "Raises an alert when a slashing of a tracked validator is included in a block",
+	}
+	// MonitorAlertCooldownFlag specifies the minimum duration between two alerts of the same kind for a validator.
+	MonitorAlertCooldownFlag = &cli.DurationFlag{
+		Name:  "monitor-alert-cooldown",
+		Usage: "Minimum duration between two alerts of the same kind for the same validator",
+		Value: time.Hour,
+	}
+	// MonitorAlertWebhookFlag specifies the URLs validator monitor alerts are posted to.
+	MonitorAlertWebhookFlag = &cli.StringSliceFlag{
+		Name:  "monitor-alert-webhook",
+		Usage: "URL the validator monitor alerts are posted to as JSON. Can be used multiple times",
+	}
+	// MonitorAlertFileFlag specifies the file validator monitor alerts are appended to.
+	MonitorAlertFileFlag = &cli.StringFlag{
+		Name:  "monitor-alert-file",
+		Usage: "File the validator monitor alerts are appended to as JSON lines",
+	}
+	// MonitorAlertStdoutFlag writes the validator monitor alerts to the standard output.
+	MonitorAlertStdoutFlag = &cli.BoolFlag{
+		Name:  "monitor-alert-stdout",
+		Usage: "Writes the validator monitor alerts to the standard output as JSON lines",
+	}
 
 	// RestoreSourceFileFlag specifies the filepath to the backed-up database file
 	// which will be used to restore the database.
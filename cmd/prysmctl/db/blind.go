@@ -0,0 +1,50 @@
+package db
+
+import (
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/theQRL/qrysm/v4/beacon-chain/db/kv"
+	"github.com/urfave/cli/v2"
+)
+
+var blindFlags = struct {
+	Path string
+}{}
+
+var blindCmd = &cli.Command{
+	Name: "blind-blocks",
+	Usage: "converts the full post-merge blocks of a stopped beacon node db to blinded blocks, so that execution " +
+		"payloads are no longer stored and are reconstructed from the execution client on demand",
+	Action: func(cliCtx *cli.Context) error {
+		if err := blindAction(cliCtx); err != nil {
+			log.WithError(err).Fatal("Could not blind db blocks")
+		}
+		return nil
+	},
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:        "path",
+			Usage:       "path to directory containing beaconchain.db",
+			Destination: &blindFlags.Path,
+			Required:    true,
+		},
+	},
+}
+
+func blindAction(cliCtx *cli.Context) error {
+	db, err := kv.NewKVStore(cliCtx.Context, blindFlags.Path)
+	if err != nil {
+		return errors.Wrap(err, "could not open db")
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.WithError(err).Error("Could not close db")
+		}
+	}()
+	converted, err := db.BlindFullBlocks(cliCtx.Context)
+	if err != nil {
+		return err
+	}
+	log.WithField("blocks", converted).Info("Converted db to blinded block storage")
+	return nil
+}
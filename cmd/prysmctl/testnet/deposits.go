@@ -0,0 +1,230 @@
+package testnet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/signing"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/contracts/deposit"
+	"github.com/theQRL/qrysm/v4/io/file"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+)
+
+// Statuses of the deposits left out of the genesis state.
+const (
+	// depositInvalid is a deposit that could never be processed, such as one with a bad signature.
+	depositInvalid = "invalid"
+	// depositSkipped is a valid deposit that was left out, such as a second deposit for the same public key.
+	depositSkipped = "skipped"
+)
+
+// depositFile is a deposit_data JSON file generated by the staking-deposit-cli tool.
+type depositFile struct {
+	path    string
+	content []byte
+	entries []*depositDataJSON
+}
+
+// rejectedDeposit is a deposit left out of the genesis state, with the reason why.
+type rejectedDeposit struct {
+	File   string `json:"file"`
+	Index  int    `json:"index"`
+	PubKey string `json:"pubkey,omitempty"`
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// validatedDeposits are the deposits to include in the genesis state, in the order they were read, and the
+// deposits left out of it.
+type validatedDeposits struct {
+	dds      []*zondpb.Deposit_Data
+	roots    [][]byte
+	rejected []*rejectedDeposit
+}
+
+// depositJSONPaths returns the deposit JSON file, followed by the JSON files of the deposit directory in
+// lexical order, so the same directory always yields the deposits in the same order.
+func depositJSONPaths(jsonFile, jsonDir string) ([]string, error) {
+	var paths []string
+	if jsonFile != "" {
+		expanded, err := file.ExpandPath(jsonFile)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, expanded)
+	}
+	if jsonDir != "" {
+		expanded, err := file.ExpandPath(jsonDir)
+		if err != nil {
+			return nil, err
+		}
+		matches, err := filepath.Glob(filepath.Join(expanded, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no JSON files in deposit directory %s", expanded)
+		}
+		sort.Strings(matches)
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+func readDepositFiles(paths []string) ([]*depositFile, error) {
+	files := make([]*depositFile, 0, len(paths))
+	for _, p := range paths {
+		log.Printf("reading deposits from JSON at %s", p)
+		b, err := os.ReadFile(p) // #nosec G304
+		if err != nil {
+			return nil, err
+		}
+		entries, err := depositEntriesFromJSON(b)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse deposits of %s", p)
+		}
+		files = append(files, &depositFile{path: p, content: b, entries: entries})
+	}
+	return files, nil
+}
+
+func depositEntriesFromJSON(enc []byte) ([]*depositDataJSON, error) {
+	var depositJSON []*depositDataJSON
+	if err := json.Unmarshal(enc, &depositJSON); err != nil {
+		return nil, err
+	}
+	return depositJSON, nil
+}
+
+// validateDeposits checks every deposit of the files and returns the ones to include in the genesis state.
+// A deposit is left out if it is malformed, if its deposit data root, withdrawal credentials, amount or
+// signature is wrong, or if an earlier deposit already has its public key.
+func validateDeposits(files []*depositFile) (*validatedDeposits, error) {
+	domain, err := signing.ComputeDomain(params.BeaconConfig().DomainDeposit, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	v := &validatedDeposits{}
+	seen := make(map[string]string)
+	for _, f := range files {
+		for i, entry := range f.entries {
+			if entry == nil {
+				v.rejected = append(v.rejected, &rejectedDeposit{
+					File:   f.path,
+					Index:  i,
+					Status: depositInvalid,
+					Reason: "empty deposit",
+				})
+				continue
+			}
+			reject := func(status, reason string) {
+				v.rejected = append(v.rejected, &rejectedDeposit{
+					File:   f.path,
+					Index:  i,
+					PubKey: entry.PubKey,
+					Status: status,
+					Reason: reason,
+				})
+			}
+			root, dd, err := depositJSONToDepositData(entry)
+			if err != nil {
+				reject(depositInvalid, err.Error())
+				continue
+			}
+			if err := validateDepositData(dd, root, domain); err != nil {
+				reject(depositInvalid, err.Error())
+				continue
+			}
+			source := fmt.Sprintf("%s[%d]", f.path, i)
+			key := string(dd.PublicKey)
+			if first, ok := seen[key]; ok {
+				reject(depositSkipped, fmt.Sprintf("duplicate public key, already deposited by %s", first))
+				continue
+			}
+			seen[key] = source
+			v.dds = append(v.dds, dd)
+			v.roots = append(v.roots, root)
+		}
+	}
+	return v, nil
+}
+
+func validateDepositData(dd *zondpb.Deposit_Data, root []byte, domain []byte) error {
+	if len(dd.PublicKey) != dilithium2.CryptoPublicKeyBytes {
+		return fmt.Errorf("public key is %d bytes, expected %d", len(dd.PublicKey), dilithium2.CryptoPublicKeyBytes)
+	}
+	if len(dd.Signature) != dilithium2.CryptoBytes {
+		return fmt.Errorf("signature is %d bytes, expected %d", len(dd.Signature), dilithium2.CryptoBytes)
+	}
+	if err := validateWithdrawalCredentials(dd.WithdrawalCredentials); err != nil {
+		return err
+	}
+	if dd.Amount < params.BeaconConfig().MinDepositAmount {
+		return fmt.Errorf("amount %d is below the minimum deposit amount %d", dd.Amount, params.BeaconConfig().MinDepositAmount)
+	}
+	if err := deposit.VerifyDepositSignature(dd, domain); err != nil {
+		return errors.Wrap(err, "could not verify deposit signature")
+	}
+	htr, err := dd.HashTreeRoot()
+	if err != nil {
+		return errors.Wrap(err, "could not compute deposit data root")
+	}
+	if !bytes.Equal(htr[:], root) {
+		return fmt.Errorf("deposit data root %#x does not match the deposit data, expected %#x", root, htr)
+	}
+	return nil
+}
+
+// validateWithdrawalCredentials checks that the credentials are either the hash of a Dilithium withdrawal
+// public key or an execution address, which must be left-padded with zeroes.
+func validateWithdrawalCredentials(creds []byte) error {
+	if len(creds) != 32 {
+		return fmt.Errorf("withdrawal credentials are %d bytes, expected 32", len(creds))
+	}
+	cfg := params.BeaconConfig()
+	switch creds[0] {
+	case cfg.DilithiumWithdrawalPrefixByte:
+		return nil
+	case cfg.ZondAddressWithdrawalPrefixByte, cfg.ETH1AddressWithdrawalPrefixByte:
+		if !bytes.Equal(creds[1:12], make([]byte, 11)) {
+			return fmt.Errorf("withdrawal credentials %#x have non-zero padding before the execution address", creds)
+		}
+		return nil
+	default:
+		return fmt.Errorf("withdrawal credentials have unknown prefix %#x", creds[0])
+	}
+}
+
+func depositJSONToDepositData(input *depositDataJSON) ([]byte, *zondpb.Deposit_Data, error) {
+	root, err := hex.DecodeString(strings.TrimPrefix(input.DepositDataRoot, "0x"))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not decode deposit data root")
+	}
+	pk, err := hex.DecodeString(strings.TrimPrefix(input.PubKey, "0x"))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not decode public key")
+	}
+	creds, err := hex.DecodeString(strings.TrimPrefix(input.WithdrawalCredentials, "0x"))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not decode withdrawal credentials")
+	}
+	sig, err := hex.DecodeString(strings.TrimPrefix(input.Signature, "0x"))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not decode signature")
+	}
+	return root, &zondpb.Deposit_Data{
+		PublicKey:             pk,
+		WithdrawalCredentials: creds,
+		Amount:                input.Amount,
+		Signature:             sig,
+	}, nil
+}
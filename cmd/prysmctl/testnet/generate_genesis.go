@@ -2,7 +2,6 @@ package testnet
 
 import (
 	"context"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math/big"
@@ -30,6 +29,8 @@ import (
 var (
 	generateGenesisStateFlags = struct {
 		DepositJsonFile    string
+		DepositJsonDir     string
+		StrictDeposits     bool
 		ChainConfigFile    string
 		ConfigName         string
 		NumValidators      uint64
@@ -37,6 +38,7 @@ var (
 		OutputSSZ          string
 		OutputJSON         string
 		OutputYaml         string
+		OutputManifest     string
 		ForkName           string
 		OverrideEth1Data   bool
 		ExecutionEndpoint  string
@@ -62,6 +64,12 @@ var (
 		Usage:       "Output filename of the JSON marshaling of the generated genesis state",
 		Value:       "",
 	}
+	outputManifestFlag = &cli.StringFlag{
+		Name:        "output-manifest",
+		Destination: &generateGenesisStateFlags.OutputManifest,
+		Usage:       "Output filename of a JSON manifest of the config, inputs and outputs, to reproduce and check the generated genesis state",
+		Value:       "",
+	}
 	generateGenesisStateCmd = &cli.Command{
 		Name:  "generate-genesis",
 		Usage: "Generate a beacon chain genesis state",
@@ -82,6 +90,16 @@ var (
 				Destination: &generateGenesisStateFlags.DepositJsonFile,
 				Usage:       "Path to deposit_data.json file generated by the staking-deposit-cli tool for optionally specifying validators in genesis state",
 			},
+			&cli.StringFlag{
+				Name:        "deposit-json-dir",
+				Destination: &generateGenesisStateFlags.DepositJsonDir,
+				Usage:       "Path to a directory of deposit_data JSON files generated by the staking-deposit-cli tool, read in lexical order after --deposit-json-file",
+			},
+			&cli.BoolFlag{
+				Name:        "strict-deposits",
+				Destination: &generateGenesisStateFlags.StrictDeposits,
+				Usage:       "Fails instead of leaving out the deposits that are invalid or duplicate",
+			},
 			&cli.StringFlag{
 				Name:        "config-name",
 				Usage:       "Config kind to be used for generating the genesis state. Default: mainnet. Options include mainnet, interop, minimal, prater, sepolia. --chain-config-file will override this flag.",
@@ -131,6 +149,7 @@ var (
 			outputSSZFlag,
 			outputYamlFlag,
 			outputJsonFlag,
+			outputManifestFlag,
 		},
 	}
 )
@@ -170,18 +189,25 @@ func cliActionGenerateGenesisState(cliCtx *cli.Context) error {
 	if err := setGlobalParams(); err != nil {
 		return fmt.Errorf("could not set config params: %v", err)
 	}
-	st, err := generateGenesis(cliCtx.Context)
+	manifest, err := newGenesisManifest()
+	if err != nil {
+		return fmt.Errorf("could not create genesis manifest: %v", err)
+	}
+	st, err := generateGenesis(cliCtx.Context, manifest)
 	if err != nil {
 		return fmt.Errorf("could not generate genesis state: %v", err)
 	}
+	if err := manifest.setGenesisState(cliCtx.Context, st); err != nil {
+		return err
+	}
 
 	if outputJson != "" {
-		if err := writeToOutputFile(outputJson, st, json.Marshal); err != nil {
+		if err := writeToOutputFile(outputJson, st, json.Marshal, manifest); err != nil {
 			return err
 		}
 	}
 	if outputYaml != "" {
-		if err := writeToOutputFile(outputYaml, st, yaml.Marshal); err != nil {
+		if err := writeToOutputFile(outputYaml, st, yaml.Marshal, manifest); err != nil {
 			return err
 		}
 	}
@@ -196,7 +222,12 @@ func cliActionGenerateGenesisState(cliCtx *cli.Context) error {
 			}
 			return marshaler.MarshalSSZ()
 		}
-		if err := writeToOutputFile(outputSSZ, st, marshalFn); err != nil {
+		if err := writeToOutputFile(outputSSZ, st, marshalFn, manifest); err != nil {
+			return err
+		}
+	}
+	if f := generateGenesisStateFlags.OutputManifest; f != "" {
+		if err := manifest.write(f); err != nil {
 			return err
 		}
 	}
@@ -217,7 +248,7 @@ func setGlobalParams() error {
 	return params.SetActive(cfg.Copy())
 }
 
-func generateGenesis(ctx context.Context) (state.BeaconState, error) {
+func generateGenesis(ctx context.Context, manifest *genesisManifest) (state.BeaconState, error) {
 	f := &generateGenesisStateFlags
 	if f.GenesisTime == 0 {
 		f.GenesisTime = uint64(time.Now().Unix())
@@ -230,24 +261,45 @@ func generateGenesis(ctx context.Context) (state.BeaconState, error) {
 	}
 	opts := make([]interop.PremineGenesisOpt, 0)
 	nv := f.NumValidators
-	if f.DepositJsonFile != "" {
-		expanded, err := file.ExpandPath(f.DepositJsonFile)
+	if f.DepositJsonFile != "" || f.DepositJsonDir != "" {
+		paths, err := depositJSONPaths(f.DepositJsonFile, f.DepositJsonDir)
 		if err != nil {
 			return nil, err
 		}
-		log.Printf("reading deposits from JSON at %s", expanded)
-		b, err := os.ReadFile(expanded) // #nosec G304
+		files, err := readDepositFiles(paths)
 		if err != nil {
 			return nil, err
 		}
-		roots, dds, err := depositEntriesFromJSON(b)
+		deposits, err := validateDeposits(files)
 		if err != nil {
 			return nil, err
 		}
-		opts = append(opts, interop.WithDepositData(dds, roots))
+		for _, df := range files {
+			manifest.DepositFiles = append(manifest.DepositFiles, newManifestFile(df.path, df.content))
+		}
+		for _, r := range deposits.rejected {
+			log.WithFields(logrus.Fields{
+				"file":   r.File,
+				"index":  r.Index,
+				"status": r.Status,
+			}).Warn(r.Reason)
+		}
+		manifest.AcceptedDeposits = len(deposits.dds)
+		manifest.RejectedDeposits = append(manifest.RejectedDeposits, deposits.rejected...)
+		log.WithFields(logrus.Fields{
+			"accepted": len(deposits.dds),
+			"rejected": len(deposits.rejected),
+		}).Info("Validated deposits")
+		if f.StrictDeposits && len(deposits.rejected) > 0 {
+			return nil, fmt.Errorf("%d deposits are invalid or duplicate", len(deposits.rejected))
+		}
+		if len(deposits.dds) == 0 {
+			return nil, errors.New("no valid deposits")
+		}
+		opts = append(opts, interop.WithDepositData(deposits.dds, deposits.roots))
 	} else if nv == 0 {
 		return nil, fmt.Errorf(
-			"expected --num-validators > 0 or --deposit-json-file to have been provided",
+			"expected --num-validators > 0, --deposit-json-file or --deposit-json-dir to have been provided",
 		)
 	}
 
@@ -257,6 +309,7 @@ func generateGenesis(ctx context.Context) (state.BeaconState, error) {
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to read %s", f.GethGenesisJsonIn)
 		}
+		manifest.GethGenesisJsonIn = newManifestFile(f.GethGenesisJsonIn, gbytes)
 		if err := json.Unmarshal(gbytes, gen); err != nil {
 			return nil, err
 		}
@@ -330,53 +383,11 @@ func generateGenesis(ctx context.Context) (state.BeaconState, error) {
 	return genesisState, err
 }
 
-func depositEntriesFromJSON(enc []byte) ([][]byte, []*zondpb.Deposit_Data, error) {
-	var depositJSON []*depositDataJSON
-	if err := json.Unmarshal(enc, &depositJSON); err != nil {
-		return nil, nil, err
-	}
-	dds := make([]*zondpb.Deposit_Data, len(depositJSON))
-	roots := make([][]byte, len(depositJSON))
-	for i, val := range depositJSON {
-		root, data, err := depositJSONToDepositData(val)
-		if err != nil {
-			return nil, nil, err
-		}
-		dds[i] = data
-		roots[i] = root
-	}
-	return roots, dds, nil
-}
-
-func depositJSONToDepositData(input *depositDataJSON) ([]byte, *zondpb.Deposit_Data, error) {
-	root, err := hex.DecodeString(strings.TrimPrefix(input.DepositDataRoot, "0x"))
-	if err != nil {
-		return nil, nil, err
-	}
-	pk, err := hex.DecodeString(strings.TrimPrefix(input.PubKey, "0x"))
-	if err != nil {
-		return nil, nil, err
-	}
-	creds, err := hex.DecodeString(strings.TrimPrefix(input.WithdrawalCredentials, "0x"))
-	if err != nil {
-		return nil, nil, err
-	}
-	sig, err := hex.DecodeString(strings.TrimPrefix(input.Signature, "0x"))
-	if err != nil {
-		return nil, nil, err
-	}
-	return root, &zondpb.Deposit_Data{
-		PublicKey:             pk,
-		WithdrawalCredentials: creds,
-		Amount:                input.Amount,
-		Signature:             sig,
-	}, nil
-}
-
 func writeToOutputFile(
 	fPath string,
 	data interface{},
 	marshalFn func(o interface{}) ([]byte, error),
+	manifest *genesisManifest,
 ) error {
 	encoded, err := marshalFn(data)
 	if err != nil {
@@ -385,6 +396,7 @@ func writeToOutputFile(
 	if err := file.WriteFile(fPath, encoded); err != nil {
 		return err
 	}
+	manifest.Outputs = append(manifest.Outputs, newManifestFile(fPath, encoded))
 	log.Printf("Done writing genesis state to %s", fPath)
 	return nil
 }
@@ -3,9 +3,11 @@ package testnet
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
-	"github.com/theQRL/qrysm/v4/crypto/bls"
+	"github.com/theQRL/qrysm/v4/crypto/dilithium"
 	"github.com/theQRL/qrysm/v4/runtime/interop"
 	"github.com/theQRL/qrysm/v4/testing/assert"
 	"github.com/theQRL/qrysm/v4/testing/require"
@@ -16,18 +18,84 @@ func Test_genesisStateFromJSONValidators(t *testing.T) {
 	jsonData := createGenesisDepositData(t, numKeys)
 	jsonInput, err := json.Marshal(jsonData)
 	require.NoError(t, err)
-	_, dds, err := depositEntriesFromJSON(jsonInput)
+	entries, err := depositEntriesFromJSON(jsonInput)
 	require.NoError(t, err)
-	for i := range dds {
-		assert.DeepEqual(t, fmt.Sprintf("%#x", dds[i].PublicKey), jsonData[i].PubKey)
+	deposits, err := validateDeposits([]*depositFile{{path: "deposit_data.json", entries: entries}})
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(deposits.rejected))
+	require.Equal(t, numKeys, len(deposits.dds))
+	for i := range deposits.dds {
+		assert.DeepEqual(t, fmt.Sprintf("%#x", deposits.dds[i].PublicKey), jsonData[i].PubKey)
+	}
+}
+
+func Test_validateDeposits(t *testing.T) {
+	jsonData := createGenesisDepositData(t, 8)
+	jsonData[1].Signature = jsonData[0].Signature
+	jsonData[2].DepositDataRoot = jsonData[0].DepositDataRoot
+	jsonData[3].WithdrawalCredentials = "0x02" + jsonData[3].WithdrawalCredentials[4:]
+	jsonData[4].PubKey = "0x1234"
+	jsonData[5].Signature = "0xzz"
+	duplicate := *jsonData[6]
+	files := []*depositFile{
+		{path: "a.json", entries: jsonData[:4]},
+		{path: "b.json", entries: append(jsonData[4:], nil, &duplicate)},
+	}
+
+	deposits, err := validateDeposits(files)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(deposits.dds))
+	assert.DeepEqual(t, fmt.Sprintf("%#x", deposits.dds[0].PublicKey), jsonData[0].PubKey)
+	assert.DeepEqual(t, fmt.Sprintf("%#x", deposits.dds[1].PublicKey), jsonData[6].PubKey)
+	assert.DeepEqual(t, fmt.Sprintf("%#x", deposits.dds[2].PublicKey), jsonData[7].PubKey)
+
+	want := []struct {
+		file   string
+		index  int
+		status string
+		reason string
+	}{
+		{"a.json", 1, depositInvalid, "could not verify deposit signature"},
+		{"a.json", 2, depositInvalid, "does not match the deposit data"},
+		{"a.json", 3, depositInvalid, "unknown prefix"},
+		{"b.json", 0, depositInvalid, "public key is 2 bytes"},
+		{"b.json", 1, depositInvalid, "could not decode signature"},
+		{"b.json", 4, depositInvalid, "empty deposit"},
+		{"b.json", 5, depositSkipped, "duplicate public key, already deposited by b.json[2]"},
+	}
+	require.Equal(t, len(want), len(deposits.rejected))
+	for i, w := range want {
+		r := deposits.rejected[i]
+		assert.Equal(t, w.file, r.File)
+		assert.Equal(t, w.index, r.Index)
+		assert.Equal(t, w.status, r.Status)
+		assert.StringContains(t, w.reason, r.Reason)
 	}
 }
 
+func Test_depositJSONPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"deposit_data-2.json", "deposit_data-1.json", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("[]"), 0600))
+	}
+	extra := filepath.Join(t.TempDir(), "extra.json")
+	paths, err := depositJSONPaths(extra, dir)
+	require.NoError(t, err)
+	assert.DeepEqual(t, []string{
+		extra,
+		filepath.Join(dir, "deposit_data-1.json"),
+		filepath.Join(dir, "deposit_data-2.json"),
+	}, paths)
+
+	_, err = depositJSONPaths("", t.TempDir())
+	assert.ErrorContains(t, "no JSON files", err)
+}
+
 func createGenesisDepositData(t *testing.T, numKeys int) []*depositDataJSON {
-	pubKeys := make([]bls.PublicKey, numKeys)
-	privKeys := make([]bls.SecretKey, numKeys)
+	pubKeys := make([]dilithium.PublicKey, numKeys)
+	privKeys := make([]dilithium.DilithiumKey, numKeys)
 	for i := 0; i < numKeys; i++ {
-		randKey, err := bls.RandKey()
+		randKey, err := dilithium.RandKey()
 		require.NoError(t, err)
 		privKeys[i] = randKey
 		pubKeys[i] = randKey.PublicKey()
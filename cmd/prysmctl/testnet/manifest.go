@@ -0,0 +1,98 @@
+package testnet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/crypto/hash"
+	"github.com/theQRL/qrysm/v4/io/file"
+	"github.com/theQRL/qrysm/v4/runtime/version"
+)
+
+// manifestFile is a file read or written by the command, identified by its sha256 hash.
+type manifestFile struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+func newManifestFile(path string, content []byte) *manifestFile {
+	return &manifestFile{Path: path, SHA256: fmt.Sprintf("%#x", hash.Hash(content))}
+}
+
+// genesisManifest records everything the genesis state was generated from, so that anyone can check they
+// generate the same genesis state from the same inputs.
+type genesisManifest struct {
+	QrysmVersion string `json:"qrysm_version"`
+	ConfigName   string `json:"config_name"`
+	// ConfigHash is the sha256 hash of the YAML encoding of the active chain config.
+	ConfigHash      string        `json:"config_hash"`
+	ChainConfigFile *manifestFile `json:"chain_config_file,omitempty"`
+	Fork            string        `json:"fork"`
+	GenesisTime     uint64        `json:"genesis_time"`
+	// NumValidators is the number of deterministically generated validators, used only without deposits.
+	NumValidators     uint64          `json:"num_validators"`
+	DepositFiles      []*manifestFile `json:"deposit_files,omitempty"`
+	GethGenesisJsonIn *manifestFile   `json:"geth_genesis_json_in,omitempty"`
+	// OverrideEth1Data is set if the Eth1Data came from an execution client, which makes it an input that is not
+	// recorded here.
+	OverrideEth1Data      bool               `json:"override_eth1data"`
+	AcceptedDeposits      int                `json:"accepted_deposits"`
+	RejectedDeposits      []*rejectedDeposit `json:"rejected_deposits"`
+	ValidatorCount        int                `json:"validator_count"`
+	GenesisValidatorsRoot string             `json:"genesis_validators_root"`
+	GenesisStateRoot      string             `json:"genesis_state_root"`
+	Outputs               []*manifestFile    `json:"outputs"`
+}
+
+func newGenesisManifest() (*genesisManifest, error) {
+	f := &generateGenesisStateFlags
+	cfg := params.BeaconConfig()
+	m := &genesisManifest{
+		QrysmVersion:     version.Version(),
+		ConfigName:       cfg.ConfigName,
+		ConfigHash:       fmt.Sprintf("%#x", hash.Hash(params.ConfigToYaml(cfg))),
+		Fork:             f.ForkName,
+		NumValidators:    f.NumValidators,
+		OverrideEth1Data: f.OverrideEth1Data,
+		RejectedDeposits: []*rejectedDeposit{},
+	}
+	if f.ChainConfigFile != "" {
+		b, err := os.ReadFile(f.ChainConfigFile) // #nosec G304
+		if err != nil {
+			return nil, err
+		}
+		m.ChainConfigFile = newManifestFile(f.ChainConfigFile, b)
+	}
+	return m, nil
+}
+
+// setGenesisState records the genesis state the inputs yielded.
+func (m *genesisManifest) setGenesisState(ctx context.Context, st state.BeaconState) error {
+	root, err := st.HashTreeRoot(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not compute genesis state root")
+	}
+	gvr := st.GenesisValidatorsRoot()
+	m.GenesisTime = st.GenesisTime()
+	m.ValidatorCount = st.NumValidators()
+	m.GenesisValidatorsRoot = fmt.Sprintf("%#x", gvr)
+	m.GenesisStateRoot = fmt.Sprintf("%#x", root)
+	return nil
+}
+
+func (m *genesisManifest) write(path string) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := file.WriteFile(path, b); err != nil {
+		return errors.Wrap(err, "could not write genesis manifest")
+	}
+	log.Printf("Done writing genesis manifest to %s", path)
+	return nil
+}
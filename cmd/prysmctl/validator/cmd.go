@@ -24,7 +24,7 @@ var (
 	PathFlag = &cli.StringFlag{
 		Name:    "path",
 		Aliases: []string{"p"},
-		Usage:   "path to the signed withdrawal messages JSON",
+		Usage:   "path to the signed withdrawal or exit messages JSON",
 	}
 
 	ConfirmFlag = &cli.BoolFlag{
@@ -187,6 +187,26 @@ var Commands = []*cli.Command{
 					return nil
 				},
 			},
+			{
+				Name:  "verify-messages",
+				Usage: "Verifies signed voluntary exits and withdrawal address changes offline, and reports on which networks they are valid.",
+				Flags: []cli.Flag{
+					PathFlag,
+					VerifyNetworksFlag,
+					VerifyBeaconStatesFlag,
+					cmd.ChainConfigFileFlag,
+					cmd.ConfigFileFlag,
+				},
+				Before: func(cliCtx *cli.Context) error {
+					return cmd.LoadFlagsFromConfig(cliCtx, cliCtx.Command.Flags)
+				},
+				Action: func(cliCtx *cli.Context) error {
+					if err := verifySignedMessages(cliCtx); err != nil {
+						log.WithError(err).Fatal("Could not verify signed messages")
+					}
+					return nil
+				},
+			},
 		},
 	},
 }
@@ -0,0 +1,391 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/go-zond/common"
+	"github.com/theQRL/go-zond/common/hexutil"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/blocks"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/helpers"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/signing"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/time"
+	"github.com/theQRL/qrysm/v4/beacon-chain/rpc/apimiddleware"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	"github.com/theQRL/qrysm/v4/cmd"
+	fieldparams "github.com/theQRL/qrysm/v4/config/fieldparams"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/encoding/ssz/detect"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	VerifyNetworksFlag = &cli.StringSliceFlag{
+		Name: "network",
+		Usage: "network to verify the signed messages against, as <config name>:<genesis validators root>, e.g. " +
+			"mainnet:0x4b36... Exits can only be verified against networks given with --beacon-state, as their " +
+			"signatures are checked against the public key of the validator in the beacon state. Can be repeated.",
+	}
+
+	VerifyBeaconStatesFlag = &cli.StringSliceFlag{
+		Name: "beacon-state",
+		Usage: "path to an SSZ encoded beacon state of a network to verify the signed messages against. The network " +
+			"is detected from the fork version of the state, and its validators are used to verify exits and " +
+			"withdrawal credentials. Can be repeated.",
+	}
+)
+
+// verifyNetwork is a network signed messages are verified against.
+type verifyNetwork struct {
+	name                  string
+	cfg                   *params.BeaconChainConfig
+	genesisValidatorsRoot []byte
+	// st is the state of the network, if known. Exits can only be verified with a state.
+	st state.ReadOnlyBeaconState
+}
+
+// signedMessage is a signed voluntary exit or DilithiumToExecutionChange read from a file.
+type signedMessage struct {
+	source string
+	exit   *zondpb.SignedVoluntaryExit
+	change *zondpb.SignedDilithiumToExecutionChange
+}
+
+func (m *signedMessage) String() string {
+	if m.exit != nil {
+		return fmt.Sprintf("voluntary exit of validator %d at epoch %d", m.exit.Exit.ValidatorIndex, m.exit.Exit.Epoch)
+	}
+	return fmt.Sprintf("withdrawal address change of validator %d to %#x", m.change.Message.ValidatorIndex, m.change.Message.ToExecutionAddress)
+}
+
+// verifySignedMessages verifies the signed voluntary exits and DilithiumToExecutionChange messages found
+// in the JSON files at the path against the given networks, without connecting to a beacon node. It reports
+// on which of the networks every message is valid, and fails if a message is valid on none of them.
+func verifySignedMessages(c *cli.Context) error {
+	if !c.IsSet(PathFlag.Name) {
+		return fmt.Errorf("no --%s flag value was provided", PathFlag.Name)
+	}
+	if c.IsSet(cmd.ChainConfigFileFlag.Name) {
+		if err := params.LoadChainConfigFile(c.String(cmd.ChainConfigFileFlag.Name), nil); err != nil {
+			return errors.Wrap(err, "could not load chain config file")
+		}
+	}
+	networks, err := loadVerifyNetworks(c.StringSlice(VerifyNetworksFlag.Name), c.StringSlice(VerifyBeaconStatesFlag.Name))
+	if err != nil {
+		return err
+	}
+	messages, err := readSignedMessages(c.String(PathFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	invalid := 0
+	for _, m := range messages {
+		var validOn []string
+		for _, n := range networks {
+			if err := verifySignedMessage(n, m); err != nil {
+				log.WithFields(log.Fields{
+					"source":  m.source,
+					"network": n.name,
+				}).WithError(err).Warnf("Invalid %s", m)
+				continue
+			}
+			validOn = append(validOn, n.name)
+		}
+		if len(validOn) == 0 {
+			invalid++
+			continue
+		}
+		log.WithFields(log.Fields{
+			"source":   m.source,
+			"networks": strings.Join(validOn, ","),
+		}).Infof("Valid %s", m)
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d signed messages are not valid on any of the given networks", invalid, len(messages))
+	}
+	log.Infof("All (total:%d) signed messages are valid on at least one of the given networks.", len(messages))
+	return nil
+}
+
+// loadVerifyNetworks loads the networks given as <config name>:<genesis validators root> pairs and
+// as beacon state files.
+func loadVerifyNetworks(networkFlags, statePaths []string) ([]*verifyNetwork, error) {
+	var networks []*verifyNetwork
+	for _, flag := range networkFlags {
+		name, root, found := strings.Cut(flag, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid network %s, wanted <config name>:<genesis validators root>", flag)
+		}
+		cfg, err := params.ByName(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unknown network %s", name)
+		}
+		gvr, err := hexutil.Decode(root)
+		if err != nil || len(gvr) != fieldparams.RootLength {
+			return nil, fmt.Errorf("invalid genesis validators root %s", root)
+		}
+		networks = append(networks, &verifyNetwork{name: name, cfg: cfg, genesisValidatorsRoot: gvr})
+	}
+	for _, path := range statePaths {
+		b, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read beacon state %s", path)
+		}
+		vu, err := detect.FromState(b)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not detect network of beacon state %s", path)
+		}
+		st, err := vu.UnmarshalBeaconState(b)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not unmarshal beacon state %s", path)
+		}
+		networks = append(networks, &verifyNetwork{
+			name:                  vu.Config.ConfigName,
+			cfg:                   vu.Config,
+			genesisValidatorsRoot: st.GenesisValidatorsRoot(),
+			st:                    st,
+		})
+	}
+	if len(networks) == 0 {
+		return nil, fmt.Errorf("no network to verify against, use --%s or --%s", VerifyNetworksFlag.Name, VerifyBeaconStatesFlag.Name)
+	}
+	return networks, nil
+}
+
+// readSignedMessages reads the signed messages of every JSON file at the path. A file may contain a
+// single message or a list of messages, as written by the exit and withdrawal tools.
+func readSignedMessages(path string) ([]*signedMessage, error) {
+	paths, err := findWithdrawalFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	var messages []*signedMessage
+	for _, p := range paths {
+		b, err := os.ReadFile(filepath.Clean(p))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open file")
+		}
+		m, err := parseSignedMessages(b, p)
+		if err != nil {
+			log.Warnf("provided file: %s, is not a list of signed exit or withdrawal messages. Error:%s", p, err.Error())
+			continue
+		}
+		messages = append(messages, m...)
+	}
+	if len(messages) == 0 {
+		return nil, errors.New("the list of signed messages is empty")
+	}
+	return messages, nil
+}
+
+func parseSignedMessages(b []byte, source string) ([]*signedMessage, error) {
+	var raw []json.RawMessage
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '{' {
+		raw = []json.RawMessage{trimmed}
+	} else if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	messages := make([]*signedMessage, len(raw))
+	for i, r := range raw {
+		var probe struct {
+			Message map[string]json.RawMessage `json:"message"`
+		}
+		if err := json.Unmarshal(r, &probe); err != nil {
+			return nil, err
+		}
+		m := &signedMessage{source: fmt.Sprintf("%s[%d]", source, i)}
+		var err error
+		switch {
+		case probe.Message["from_dilithium_pubkey"] != nil:
+			change := &apimiddleware.SignedDilithiumToExecutionChangeJson{}
+			if err := json.Unmarshal(r, change); err != nil {
+				return nil, err
+			}
+			m.change, err = signedChangeFromJson(change)
+		case probe.Message["epoch"] != nil:
+			exit := &apimiddleware.SignedVoluntaryExitJson{}
+			if err := json.Unmarshal(r, exit); err != nil {
+				return nil, err
+			}
+			m.exit, err = signedExitFromJson(exit)
+		default:
+			return nil, fmt.Errorf("message %d is neither a voluntary exit nor a withdrawal address change", i)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid message %d", i)
+		}
+		messages[i] = m
+	}
+	return messages, nil
+}
+
+func signedExitFromJson(exit *apimiddleware.SignedVoluntaryExitJson) (*zondpb.SignedVoluntaryExit, error) {
+	epoch, err := strconv.ParseUint(exit.Exit.Epoch, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid epoch")
+	}
+	index, err := strconv.ParseUint(exit.Exit.ValidatorIndex, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid validator index")
+	}
+	sig, err := decodeHex(exit.Signature, dilithium2.CryptoBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid signature")
+	}
+	return &zondpb.SignedVoluntaryExit{
+		Exit: &zondpb.VoluntaryExit{
+			Epoch:          primitives.Epoch(epoch),
+			ValidatorIndex: primitives.ValidatorIndex(index),
+		},
+		Signature: sig,
+	}, nil
+}
+
+func signedChangeFromJson(change *apimiddleware.SignedDilithiumToExecutionChangeJson) (*zondpb.SignedDilithiumToExecutionChange, error) {
+	index, err := strconv.ParseUint(change.Message.ValidatorIndex, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid validator index")
+	}
+	pubKey, err := decodeHex(change.Message.FromDilithiumPubkey, dilithium2.CryptoPublicKeyBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid public key")
+	}
+	address, err := decodeHex(change.Message.ToExecutionAddress, common.AddressLength)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid execution address")
+	}
+	sig, err := decodeHex(change.Signature, dilithium2.CryptoBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid signature")
+	}
+	return &zondpb.SignedDilithiumToExecutionChange{
+		Message: &zondpb.DilithiumToExecutionChange{
+			ValidatorIndex:      primitives.ValidatorIndex(index),
+			FromDilithiumPubkey: pubKey,
+			ToExecutionAddress:  address,
+		},
+		Signature: sig,
+	}, nil
+}
+
+// decodeHex decodes a hex string of the given length in bytes, with or without 0x prefix.
+func decodeHex(s string, length int) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") {
+		s = "0x" + s
+	}
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != length {
+		return nil, fmt.Errorf("wanted %d bytes, got %d", length, len(b))
+	}
+	return b, nil
+}
+
+func verifySignedMessage(n *verifyNetwork, m *signedMessage) error {
+	if m.exit != nil {
+		return verifySignedExit(n, m.exit)
+	}
+	return verifySignedChange(n, m.change)
+}
+
+// verifySignedExit verifies a voluntary exit against the network. The exit is signed with the fork version
+// active at its epoch, and the validator must be able to exit at that epoch.
+func verifySignedExit(n *verifyNetwork, signed *zondpb.SignedVoluntaryExit) error {
+	if n.st == nil {
+		return errors.New("the validator public key is unknown, a beacon state of the network is required to verify exits")
+	}
+	exit := signed.Exit
+	if exit.Epoch >= n.cfg.FarFutureEpoch {
+		return errors.New("exit epoch is the far future epoch")
+	}
+	val, err := n.st.ValidatorAtIndexReadOnly(exit.ValidatorIndex)
+	if err != nil {
+		return errors.Wrap(err, "validator is unknown")
+	}
+	if val.ExitEpoch() != n.cfg.FarFutureEpoch {
+		return fmt.Errorf("validator has already initiated an exit at epoch %d", val.ExitEpoch())
+	}
+	if !helpers.IsActiveValidatorUsingTrie(val, exit.Epoch) {
+		return fmt.Errorf("validator is not active at exit epoch %d", exit.Epoch)
+	}
+	if exit.Epoch < val.ActivationEpoch()+n.cfg.ShardCommitteePeriod {
+		return fmt.Errorf("validator has not been active long enough to exit at epoch %d", exit.Epoch)
+	}
+	forkVersion := forkVersionAtEpoch(n.cfg, exit.Epoch)
+	domain, err := signing.ComputeDomain(n.cfg.DomainVoluntaryExit, forkVersion, n.genesisValidatorsRoot)
+	if err != nil {
+		return errors.Wrap(err, "could not compute signing domain")
+	}
+	pubKey := val.PublicKey()
+	if err := signing.VerifySigningRoot(exit, pubKey[:], signed.Signature, domain); err != nil {
+		return errors.Wrapf(err, "signature does not verify with fork version %#x", forkVersion)
+	}
+	if current := time.CurrentEpoch(n.st); exit.Epoch > current {
+		log.WithFields(log.Fields{
+			"network":        n.name,
+			"validatorIndex": exit.ValidatorIndex,
+			"exitEpoch":      exit.Epoch,
+			"currentEpoch":   current,
+		}).Info("Voluntary exit cannot be included before its epoch")
+	}
+	return nil
+}
+
+// verifySignedChange verifies a DilithiumToExecutionChange against the network. These messages are always
+// signed with the genesis fork version, and are only valid on networks which have scheduled Capella. If
+// a state of the network is known, the withdrawal credentials of the validator are checked as well.
+func verifySignedChange(n *verifyNetwork, signed *zondpb.SignedDilithiumToExecutionChange) error {
+	if n.cfg.CapellaForkEpoch >= n.cfg.FarFutureEpoch {
+		return errors.New("withdrawal address changes are only valid after the Capella fork, which is not scheduled on the network")
+	}
+	if n.st != nil {
+		// Checks the Dilithium withdrawal prefix of the credentials and that they commit to the public key.
+		if _, err := blocks.ValidateDilithiumToExecutionChange(n.st, signed); err != nil {
+			return errors.Wrap(err, "invalid withdrawal credentials")
+		}
+	}
+	domain, err := signing.ComputeDomain(n.cfg.DomainDilithiumToExecutionChange, n.cfg.GenesisForkVersion, n.genesisValidatorsRoot)
+	if err != nil {
+		return errors.Wrap(err, "could not compute signing domain")
+	}
+	if err := signing.VerifySigningRoot(signed.Message, signed.Message.FromDilithiumPubkey, signed.Signature, domain); err != nil {
+		return errors.Wrap(err, "signature does not verify")
+	}
+	return nil
+}
+
+// forkVersionAtEpoch returns the fork version active at the epoch in the fork schedule of the config.
+func forkVersionAtEpoch(cfg *params.BeaconChainConfig, epoch primitives.Epoch) []byte {
+	type scheduledFork struct {
+		version [fieldparams.VersionLength]byte
+		epoch   primitives.Epoch
+	}
+	schedule := make([]scheduledFork, 0, len(cfg.ForkVersionSchedule))
+	for v, e := range cfg.ForkVersionSchedule {
+		schedule = append(schedule, scheduledFork{version: v, epoch: e})
+	}
+	sort.Slice(schedule, func(i, j int) bool {
+		return schedule[i].epoch < schedule[j].epoch
+	})
+	version := cfg.GenesisForkVersion
+	for i := range schedule {
+		if schedule[i].epoch <= epoch {
+			version = schedule[i].version[:]
+		}
+	}
+	return version
+}
@@ -0,0 +1,208 @@
+package validator
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/go-zond/common/hexutil"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/signing"
+	"github.com/theQRL/qrysm/v4/beacon-chain/rpc/apimiddleware"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/crypto/dilithium"
+	"github.com/theQRL/qrysm/v4/crypto/hash"
+	"github.com/theQRL/qrysm/v4/encoding/ssz"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/util"
+	"github.com/urfave/cli/v2"
+)
+
+var testGenesisValidatorsRoot = hexutil.Encode(make([]byte, 31)) + "01"
+
+// setupVerifyMessages writes a mainnet beacon state with a single validator, and returns the path of the
+// state file along with the key of the validator.
+func setupVerifyMessages(t *testing.T) (string, dilithium.DilithiumKey) {
+	key, err := dilithium.RandKey()
+	require.NoError(t, err)
+	pubKey := key.PublicKey().Marshal()
+	creds := ssz.NewHasherFunc(hash.CustomSHA256Hasher()).Hash(pubKey)
+	creds[0] = params.BeaconConfig().DilithiumWithdrawalPrefixByte
+	gvr, err := hexutil.Decode(testGenesisValidatorsRoot)
+	require.NoError(t, err)
+	syncCommitteeKeys := make([][]byte, params.BeaconConfig().SyncCommitteeSize)
+	for i := range syncCommitteeKeys {
+		syncCommitteeKeys[i] = make([]byte, dilithium2.CryptoPublicKeyBytes)
+	}
+	syncCommittee := &zondpb.SyncCommittee{
+		Pubkeys:         syncCommitteeKeys,
+		AggregatePubkey: make([]byte, len(syncCommitteeKeys)*dilithium2.CryptoPublicKeyBytes),
+	}
+
+	st, err := util.NewBeaconStateCapella(func(st *zondpb.BeaconStateCapella) error {
+		st.Slot = params.BeaconConfig().SlotsPerEpoch * 300
+		st.GenesisValidatorsRoot = gvr
+		st.Fork = &zondpb.Fork{
+			PreviousVersion: params.MainnetConfig().GenesisForkVersion,
+			CurrentVersion:  params.MainnetConfig().CapellaForkVersion,
+		}
+		st.Validators = []*zondpb.Validator{{
+			PublicKey:                  pubKey,
+			WithdrawalCredentials:      creds[:],
+			EffectiveBalance:           params.BeaconConfig().MaxEffectiveBalance,
+			ActivationEligibilityEpoch: 0,
+			ActivationEpoch:            0,
+			ExitEpoch:                  params.BeaconConfig().FarFutureEpoch,
+			WithdrawableEpoch:          params.BeaconConfig().FarFutureEpoch,
+		}}
+		st.Balances = []uint64{params.BeaconConfig().MaxEffectiveBalance}
+		st.InactivityScores = []uint64{0}
+		st.PreviousEpochParticipation = []byte{0}
+		st.CurrentEpochParticipation = []byte{0}
+		st.CurrentSyncCommittee = syncCommittee
+		st.NextSyncCommittee = syncCommittee
+		return nil
+	})
+	require.NoError(t, err)
+	enc, err := st.MarshalSSZ()
+	require.NoError(t, err)
+	statePath := filepath.Join(t.TempDir(), "state.ssz")
+	require.NoError(t, os.WriteFile(statePath, enc, 0600))
+	return statePath, key
+}
+
+func signedExitJson(t *testing.T, key dilithium.DilithiumKey, epoch primitives.Epoch, forkVersion []byte) *apimiddleware.SignedVoluntaryExitJson {
+	gvr, err := hexutil.Decode(testGenesisValidatorsRoot)
+	require.NoError(t, err)
+	exit := &zondpb.VoluntaryExit{Epoch: epoch, ValidatorIndex: 0}
+	domain, err := signing.ComputeDomain(params.BeaconConfig().DomainVoluntaryExit, forkVersion, gvr)
+	require.NoError(t, err)
+	root, err := signing.ComputeSigningRoot(exit, domain)
+	require.NoError(t, err)
+	return &apimiddleware.SignedVoluntaryExitJson{
+		Exit: &apimiddleware.VoluntaryExitJson{
+			Epoch:          strconv.FormatUint(uint64(epoch), 10),
+			ValidatorIndex: "0",
+		},
+		Signature: hexutil.Encode(key.Sign(root[:]).Marshal()),
+	}
+}
+
+func signedChangeJson(t *testing.T, key dilithium.DilithiumKey) *apimiddleware.SignedDilithiumToExecutionChangeJson {
+	gvr, err := hexutil.Decode(testGenesisValidatorsRoot)
+	require.NoError(t, err)
+	change := &zondpb.DilithiumToExecutionChange{
+		ValidatorIndex:      0,
+		FromDilithiumPubkey: key.PublicKey().Marshal(),
+		ToExecutionAddress:  make([]byte, 20),
+	}
+	domain, err := signing.ComputeDomain(params.BeaconConfig().DomainDilithiumToExecutionChange, params.BeaconConfig().GenesisForkVersion, gvr)
+	require.NoError(t, err)
+	root, err := signing.ComputeSigningRoot(change, domain)
+	require.NoError(t, err)
+	return &apimiddleware.SignedDilithiumToExecutionChangeJson{
+		Message: &apimiddleware.DilithiumToExecutionChangeJson{
+			ValidatorIndex:      "0",
+			FromDilithiumPubkey: hexutil.Encode(change.FromDilithiumPubkey),
+			// The deposit CLI writes hex values without prefix.
+			ToExecutionAddress: hexutil.Encode(change.ToExecutionAddress)[2:],
+		},
+		Signature: hexutil.Encode(key.Sign(root[:]).Marshal()),
+	}
+}
+
+func writeMessages(t *testing.T, dir, name string, messages interface{}) {
+	b, err := json.Marshal(messages)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), b, 0600))
+}
+
+func verifyMessagesContext(t *testing.T, dir string, networks, states []string) *cli.Context {
+	app := cli.App{}
+	set := flag.NewFlagSet("test", 0)
+	set.String(PathFlag.Name, dir, "")
+	require.NoError(t, set.Set(PathFlag.Name, dir))
+	networksFlag := cli.NewStringSlice(networks...)
+	statesFlag := cli.NewStringSlice(states...)
+	set.Var(networksFlag, VerifyNetworksFlag.Name, "")
+	set.Var(statesFlag, VerifyBeaconStatesFlag.Name, "")
+	return cli.NewContext(&app, set, nil)
+}
+
+func TestVerifySignedMessages(t *testing.T) {
+	statePath, key := setupVerifyMessages(t)
+	genesisForkVersion := params.MainnetConfig().GenesisForkVersion
+
+	t.Run("valid messages", func(t *testing.T) {
+		hook := logtest.NewGlobal()
+		dir := t.TempDir()
+		writeMessages(t, dir, "exits.json", []*apimiddleware.SignedVoluntaryExitJson{signedExitJson(t, key, 300, genesisForkVersion)})
+		writeMessages(t, dir, "change.json", signedChangeJson(t, key))
+
+		cliCtx := verifyMessagesContext(t, dir, nil, []string{statePath})
+		require.NoError(t, verifySignedMessages(cliCtx))
+		assert.LogsContain(t, hook, "Valid voluntary exit of validator 0 at epoch 300")
+		assert.LogsContain(t, hook, "Valid withdrawal address change of validator 0")
+		assert.LogsContain(t, hook, "All (total:2) signed messages are valid")
+	})
+	t.Run("valid on one network", func(t *testing.T) {
+		hook := logtest.NewGlobal()
+		dir := t.TempDir()
+		writeMessages(t, dir, "change.json", []*apimiddleware.SignedDilithiumToExecutionChangeJson{signedChangeJson(t, key)})
+
+		otherNetwork := params.MainnetName + ":" + hexutil.Encode(make([]byte, 32))
+		cliCtx := verifyMessagesContext(t, dir, []string{otherNetwork}, []string{statePath})
+		require.NoError(t, verifySignedMessages(cliCtx))
+		assert.LogsContain(t, hook, "signature does not verify")
+		assert.LogsContain(t, hook, "Valid withdrawal address change of validator 0")
+	})
+	t.Run("exit signed for the wrong fork", func(t *testing.T) {
+		hook := logtest.NewGlobal()
+		dir := t.TempDir()
+		writeMessages(t, dir, "exits.json", []*apimiddleware.SignedVoluntaryExitJson{
+			signedExitJson(t, key, 300, params.MainnetConfig().CapellaForkVersion),
+		})
+
+		cliCtx := verifyMessagesContext(t, dir, nil, []string{statePath})
+		require.ErrorContains(t, "1 of 1 signed messages are not valid", verifySignedMessages(cliCtx))
+		assert.LogsContain(t, hook, "signature does not verify with fork version 0x00000000")
+	})
+	t.Run("exit before shard committee period", func(t *testing.T) {
+		hook := logtest.NewGlobal()
+		dir := t.TempDir()
+		writeMessages(t, dir, "exits.json", []*apimiddleware.SignedVoluntaryExitJson{signedExitJson(t, key, 10, genesisForkVersion)})
+
+		cliCtx := verifyMessagesContext(t, dir, nil, []string{statePath})
+		require.ErrorContains(t, "not valid", verifySignedMessages(cliCtx))
+		assert.LogsContain(t, hook, "validator has not been active long enough")
+	})
+	t.Run("exit without state", func(t *testing.T) {
+		hook := logtest.NewGlobal()
+		dir := t.TempDir()
+		writeMessages(t, dir, "exits.json", []*apimiddleware.SignedVoluntaryExitJson{signedExitJson(t, key, 300, genesisForkVersion)})
+
+		cliCtx := verifyMessagesContext(t, dir, []string{params.MainnetName + ":" + testGenesisValidatorsRoot}, nil)
+		require.ErrorContains(t, "not valid", verifySignedMessages(cliCtx))
+		assert.LogsContain(t, hook, "a beacon state of the network is required to verify exits")
+	})
+	t.Run("no network", func(t *testing.T) {
+		cliCtx := verifyMessagesContext(t, t.TempDir(), nil, nil)
+		require.ErrorContains(t, "no network to verify against", verifySignedMessages(cliCtx))
+	})
+}
+
+func TestForkVersionAtEpoch(t *testing.T) {
+	cfg := params.MainnetConfig().Copy()
+	cfg.CapellaForkEpoch = 10
+	cfg.InitializeForkSchedule()
+	assert.DeepEqual(t, cfg.GenesisForkVersion, forkVersionAtEpoch(cfg, 9))
+	assert.DeepEqual(t, cfg.CapellaForkVersion, forkVersionAtEpoch(cfg, 10))
+	assert.DeepEqual(t, cfg.CapellaForkVersion, forkVersionAtEpoch(cfg, 11))
+}
@@ -323,6 +323,22 @@ var (
 		Usage: "Allows users to specify the output directory to export their slashing protection EIP-3076 standard JSON File",
 		Value: "",
 	}
+	// SlashingProtectionMinifyFlag exports only the latest safe watermarks of a validator's
+	// slashing protection history instead of its complete history.
+	SlashingProtectionMinifyFlag = &cli.BoolFlag{
+		Name:  "minify",
+		Usage: "Only keeps the highest signed slot and the highest signed source and target epochs of each public key in the slashing protection JSON",
+	}
+	// SlashingProtectionJSONFilesFlag is used to enter the file paths of several slashing protection JSONs.
+	SlashingProtectionJSONFilesFlag = &cli.StringSliceFlag{
+		Name:  "slashing-protection-json-files",
+		Usage: "Paths to slashing protection JSON files of the same chain to merge into a single file",
+	}
+	// SlashingProtectionOutputFileFlag specifies the output path of a slashing protection JSON.
+	SlashingProtectionOutputFileFlag = &cli.StringFlag{
+		Name:  "slashing-protection-output-file",
+		Usage: "Path to write the resulting slashing protection JSON file to",
+	}
 	// GraffitiFileFlag specifies the file path to load graffiti values.
 	GraffitiFileFlag = &cli.StringFlag{
 		Name:  "graffiti-file",
@@ -20,15 +20,16 @@ const (
 )
 
 // Extracts a validator's slashing protection
-// history from their database and formats it into an EIP-3076 standard JSON
+// history from their database and formats it into an EIP-3076 style interchange JSON
 // file via a CLI entrypoint to make it easy to migrate machines or Ethereum consensus clients.
 //
 // Steps:
 // 1. Parse a path to the validator's datadir from the CLI context.
 // 2. Open the validator database.
 // 3. Call the function which actually exports the data from
-// the validator's db into an EIP standard slashing protection format
-// 4. Format and save the JSON file to a user's specified output directory.
+// the validator's db into the slashing protection interchange format.
+// 4. Optionally minify the history to the latest safe watermarks of every key.
+// 5. Format and save the JSON file to a user's specified output directory.
 func exportSlashingProtectionJSON(cliCtx *cli.Context) error {
 	log.Info(
 		"This command exports your validator's attestation and proposal history into " +
@@ -67,6 +68,12 @@ func exportSlashingProtectionJSON(cliCtx *cli.Context) error {
 	if err != nil {
 		return errors.Wrap(err, "could not export slashing protection history")
 	}
+	if cliCtx.Bool(flags.SlashingProtectionMinifyFlag.Name) {
+		eipJSON, err = slashingprotection.MinifyStandardProtectionJSON(cliCtx.Context, eipJSON)
+		if err != nil {
+			return errors.Wrap(err, "could not minify slashing protection history")
+		}
+	}
 
 	// Check if JSON data is empty and issue a warning about common problems to the user.
 	if eipJSON == nil || len(eipJSON.Data) == 0 {
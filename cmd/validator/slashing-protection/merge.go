@@ -0,0 +1,121 @@
+package historycmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/theQRL/qrysm/v4/cmd/validator/flags"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	"github.com/theQRL/qrysm/v4/io/file"
+	slashingprotection "github.com/theQRL/qrysm/v4/validator/slashing-protection-history"
+	"github.com/theQRL/qrysm/v4/validator/slashing-protection-history/format"
+	"github.com/urfave/cli/v2"
+)
+
+// Merges several slashing protection JSON files of the same chain, for example
+// exported from two machines which ran the same keys, into a single file.
+//
+// Steps:
+// 1. Read and validate every input JSON file.
+// 2. Merge the histories of every public key into a single conservative history.
+// 3. Optionally minify the result to the latest safe watermarks.
+// 4. Write the result to the output file.
+func mergeSlashingProtectionJSON(cliCtx *cli.Context) error {
+	paths := cliCtx.StringSlice(flags.SlashingProtectionJSONFilesFlag.Name)
+	if len(paths) < 2 {
+		return fmt.Errorf("at least two slashing protection JSON files must be specified with the %s flag",
+			flags.SlashingProtectionJSONFilesFlag.Name)
+	}
+	outputPath, err := outputFilePath(cliCtx)
+	if err != nil {
+		return err
+	}
+	interchangeJSONs := make([]*format.EIPSlashingProtectionFormat, len(paths))
+	for i, path := range paths {
+		interchangeJSONs[i], err = readSlashingProtectionJSON(path)
+		if err != nil {
+			return err
+		}
+	}
+	merged, slashablePubKeys, err := slashingprotection.MergeStandardProtectionJSONs(cliCtx.Context, interchangeJSONs...)
+	if err != nil {
+		return errors.Wrap(err, "could not merge slashing protection JSON files")
+	}
+	for _, pubKey := range slashablePubKeys {
+		log.WithField("publicKey", fmt.Sprintf("%#x", bytesutil.Trunc(pubKey[:]))).Warn(
+			"Merged history of public key contains slashable messages, it will not be imported unless minified",
+		)
+	}
+	if cliCtx.Bool(flags.SlashingProtectionMinifyFlag.Name) {
+		merged, err = slashingprotection.MinifyStandardProtectionJSON(cliCtx.Context, merged)
+		if err != nil {
+			return errors.Wrap(err, "could not minify merged slashing protection JSON")
+		}
+	}
+	if err := writeSlashingProtectionJSON(outputPath, merged); err != nil {
+		return err
+	}
+	log.Infof("Merged %d slashing protection JSON files into %s", len(paths), outputPath)
+	return nil
+}
+
+// Reduces a slashing protection JSON file to the latest safe watermarks of every public key.
+func minifySlashingProtectionJSON(cliCtx *cli.Context) error {
+	path := cliCtx.String(flags.SlashingProtectionJSONFileFlag.Name)
+	if path == "" {
+		return fmt.Errorf("no slashing protection JSON file specified with the %s flag",
+			flags.SlashingProtectionJSONFileFlag.Name)
+	}
+	outputPath, err := outputFilePath(cliCtx)
+	if err != nil {
+		return err
+	}
+	interchangeJSON, err := readSlashingProtectionJSON(path)
+	if err != nil {
+		return err
+	}
+	minified, err := slashingprotection.MinifyStandardProtectionJSON(cliCtx.Context, interchangeJSON)
+	if err != nil {
+		return errors.Wrap(err, "could not minify slashing protection JSON")
+	}
+	if err := writeSlashingProtectionJSON(outputPath, minified); err != nil {
+		return err
+	}
+	log.Infof("Wrote minified slashing protection JSON to %s", outputPath)
+	return nil
+}
+
+func outputFilePath(cliCtx *cli.Context) (string, error) {
+	outputPath := cliCtx.String(flags.SlashingProtectionOutputFileFlag.Name)
+	if outputPath == "" {
+		return "", fmt.Errorf("no output file specified with the %s flag", flags.SlashingProtectionOutputFileFlag.Name)
+	}
+	if file.FileExists(outputPath) {
+		return "", fmt.Errorf("output file %s already exists", outputPath)
+	}
+	return outputPath, nil
+}
+
+func readSlashingProtectionJSON(path string) (*format.EIPSlashingProtectionFormat, error) {
+	enc, err := file.ReadFileAsBytes(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read slashing protection JSON file %s", path)
+	}
+	interchangeJSON := &format.EIPSlashingProtectionFormat{}
+	if err := json.Unmarshal(enc, interchangeJSON); err != nil {
+		return nil, errors.Wrapf(err, "could not unmarshal slashing protection JSON file %s", path)
+	}
+	return interchangeJSON, nil
+}
+
+func writeSlashingProtectionJSON(path string, interchangeJSON *format.EIPSlashingProtectionFormat) error {
+	encoded, err := json.MarshalIndent(interchangeJSON, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not JSON marshal slashing protection history")
+	}
+	if err := file.WriteFile(path, encoded); err != nil {
+		return errors.Wrapf(err, "could not write file to path %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package historycmd
+
+import (
+	"encoding/json"
+	"flag"
+	"path/filepath"
+	"testing"
+
+	"github.com/theQRL/qrysm/v4/cmd/validator/flags"
+	"github.com/theQRL/qrysm/v4/io/file"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/validator/slashing-protection-history/format"
+	mocks "github.com/theQRL/qrysm/v4/validator/testing"
+	"github.com/urfave/cli/v2"
+)
+
+func TestMergeSlashingProtectionCli(t *testing.T) {
+	dir := t.TempDir()
+	pubKeys, err := mocks.CreateRandomPubKeys(4)
+	require.NoError(t, err)
+
+	// Each file holds the history of a disjoint set of keys.
+	paths := make([]string, 2)
+	for i := range paths {
+		keys := pubKeys[i*2 : i*2+2]
+		attestingHistory, proposalHistory := mocks.MockAttestingAndProposalHistories(keys)
+		mockJSON, err := mocks.MockSlashingProtectionJSON(keys, attestingHistory, proposalHistory)
+		require.NoError(t, err)
+		encoded, err := json.Marshal(mockJSON)
+		require.NoError(t, err)
+		paths[i] = filepath.Join(dir, "history_"+string(rune('a'+i))+".json")
+		require.NoError(t, file.WriteFile(paths[i], encoded))
+	}
+	outputPath := filepath.Join(dir, "merged.json")
+
+	app := cli.App{}
+	set := flag.NewFlagSet("test", 0)
+	files := cli.NewStringSlice(paths...)
+	set.Var(files, flags.SlashingProtectionJSONFilesFlag.Name, "")
+	set.String(flags.SlashingProtectionOutputFileFlag.Name, outputPath, "")
+	set.Bool(flags.SlashingProtectionMinifyFlag.Name, true, "")
+	cliCtx := cli.NewContext(&app, set, nil)
+
+	require.NoError(t, mergeSlashingProtectionJSON(cliCtx))
+
+	enc, err := file.ReadFileAsBytes(outputPath)
+	require.NoError(t, err)
+	merged := &format.EIPSlashingProtectionFormat{}
+	require.NoError(t, json.Unmarshal(enc, merged))
+	assert.Equal(t, format.InterchangeFormatVersion, merged.Metadata.InterchangeFormatVersion)
+	require.Equal(t, len(pubKeys), len(merged.Data))
+	for _, item := range merged.Data {
+		assert.Equal(t, true, len(item.SignedBlocks) <= 1)
+		assert.Equal(t, true, len(item.SignedAttestations) <= 1)
+	}
+
+	// The output file is never overwritten.
+	require.ErrorContains(t, "already exists", mergeSlashingProtectionJSON(cliCtx))
+}
@@ -21,6 +21,7 @@ var Commands = &cli.Command{
 			Flags: cmd.WrapFlags([]cli.Flag{
 				cmd.DataDirFlag,
 				flags.SlashingProtectionExportDirFlag,
+				flags.SlashingProtectionMinifyFlag,
 				features.Mainnet,
 				features.PraterTestnet,
 				features.SepoliaTestnet,
@@ -70,5 +71,49 @@ var Commands = &cli.Command{
 				return nil
 			},
 		},
+		{
+			Name: "merge",
+			Description: `merges several slashing protection JSON files of the same chain into a single file, ` +
+				`for example histories of the same keys exported from different machines`,
+			Flags: cmd.WrapFlags([]cli.Flag{
+				flags.SlashingProtectionJSONFilesFlag,
+				flags.SlashingProtectionOutputFileFlag,
+				flags.SlashingProtectionMinifyFlag,
+				cmd.AcceptTosFlag,
+			}),
+			Before: func(cliCtx *cli.Context) error {
+				if err := cmd.LoadFlagsFromConfig(cliCtx, cliCtx.Command.Flags); err != nil {
+					return err
+				}
+				return tos.VerifyTosAcceptedOrPrompt(cliCtx)
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := mergeSlashingProtectionJSON(cliCtx); err != nil {
+					logrus.Fatalf("Could not merge slashing protection files: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name:        "minify",
+			Description: `reduces a slashing protection JSON file to the latest safe watermarks of every public key`,
+			Flags: cmd.WrapFlags([]cli.Flag{
+				flags.SlashingProtectionJSONFileFlag,
+				flags.SlashingProtectionOutputFileFlag,
+				cmd.AcceptTosFlag,
+			}),
+			Before: func(cliCtx *cli.Context) error {
+				if err := cmd.LoadFlagsFromConfig(cliCtx, cliCtx.Command.Flags); err != nil {
+					return err
+				}
+				return tos.VerifyTosAcceptedOrPrompt(cliCtx)
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := minifySlashingProtectionJSON(cliCtx); err != nil {
+					logrus.Fatalf("Could not minify slashing protection file: %v", err)
+				}
+				return nil
+			},
+		},
 	},
 }
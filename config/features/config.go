@@ -45,6 +45,8 @@ type Flags struct {
 	EnableDoppelGanger                  bool // EnableDoppelGanger enables doppelganger protection on startup for the validator.
 	EnableHistoricalSpaceRepresentation bool // EnableHistoricalSpaceRepresentation enables the saving of registry validators in separate buckets to save space
 	EnableBeaconRESTApi                 bool // EnableBeaconRESTApi enables experimental usage of the beacon REST API by the validator when querying a beacon node
+	EnableDutyLookahead                 bool // EnableDutyLookahead enables fetching and pre-computing validator duties one epoch ahead.
+	EnableForkChoiceRecorder            bool // EnableForkChoiceRecorder records the history of fork choice for the debug API.
 	// Logging related toggles.
 	DisableGRPCConnectionLogs bool // Disables logging when a new grpc client has connected.
 	EnableFullSSZDataLogging  bool // Enables logging for full ssz data on rejected gossip messages
@@ -57,6 +59,7 @@ type Flags struct {
 
 	EnableSlasher                   bool // Enable slasher in the beacon node runtime.
 	EnableSlashingProtectionPruning bool // EnableSlashingProtectionPruning for the validator client.
+	EnablePerformanceHistory        bool // EnablePerformanceHistory persists per-epoch performance records of each validator key.
 
 	SaveFullExecutionPayloads bool // Save full beacon blocks with execution payloads in the database.
 	EnableStartOptimistic     bool // EnableStartOptimistic treats every block as optimistic at startup.
@@ -76,6 +79,13 @@ type Flags struct {
 	// changed on disk. This feature is for advanced use cases only.
 	KeystoreImportDebounceInterval time.Duration
 
+	// DutyLookaheadWorkers specifies the maximum number of duties the validator performs concurrently
+	// when EnableDutyLookahead is set. A value of zero means the number of available CPUs.
+	DutyLookaheadWorkers int
+
+	// ForkChoiceRecorderSize specifies the number of latest events kept by the fork choice recorder.
+	ForkChoiceRecorderSize int
+
 	// AggregateIntervals specifies the time durations at which we aggregate attestations preparing for forkchoice.
 	AggregateIntervals [3]time.Duration
 }
@@ -238,6 +248,11 @@ func ConfigureBeaconChain(ctx *cli.Context) error {
 		logEnabled(disableResourceManager)
 		cfg.DisableResourceManager = true
 	}
+	if ctx.IsSet(enableForkChoiceRecorder.Name) {
+		logEnabled(enableForkChoiceRecorder)
+		cfg.EnableForkChoiceRecorder = true
+	}
+	cfg.ForkChoiceRecorderSize = ctx.Int(forkChoiceRecorderSize.Name)
 	cfg.AggregateIntervals = [3]time.Duration{aggregateFirstInterval.Value, aggregateSecondInterval.Value, aggregateThirdInterval.Value}
 	Init(cfg)
 	return nil
@@ -269,6 +284,10 @@ func ConfigureValidator(ctx *cli.Context) error {
 		logEnabled(enableSlashingProtectionPruning)
 		cfg.EnableSlashingProtectionPruning = true
 	}
+	if ctx.Bool(enablePerformanceHistory.Name) {
+		logEnabled(enablePerformanceHistory)
+		cfg.EnablePerformanceHistory = true
+	}
 	if ctx.Bool(enableDoppelGangerProtection.Name) {
 		logEnabled(enableDoppelGangerProtection)
 		cfg.EnableDoppelGanger = true
@@ -277,6 +296,11 @@ func ConfigureValidator(ctx *cli.Context) error {
 		logEnabled(EnableBeaconRESTApi)
 		cfg.EnableBeaconRESTApi = true
 	}
+	if ctx.Bool(enableDutyLookahead.Name) {
+		logEnabled(enableDutyLookahead)
+		cfg.EnableDutyLookahead = true
+	}
+	cfg.DutyLookaheadWorkers = ctx.Int(dutyLookaheadWorkers.Name)
 	cfg.KeystoreImportDebounceInterval = ctx.Duration(dynamicKeyReloadDebounceInterval.Name)
 	Init(cfg)
 	return nil
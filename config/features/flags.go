@@ -94,6 +94,11 @@ var (
 		Name:  "enable-slashing-protection-history-pruning",
 		Usage: "Enables the pruning of the validator client's slashing protection database",
 	}
+	enablePerformanceHistory = &cli.BoolFlag{
+		Name: "enable-validator-performance-history",
+		Usage: "Enables the validator client to record the per-epoch performance of each of its keys in its " +
+			"database, which can be queried and exported through the validator web API",
+	}
 	enableDoppelGangerProtection = &cli.BoolFlag{
 		Name: "enable-doppelganger",
 		Usage: "Enables the validator to perform a doppelganger check. (Warning): This is not " +
@@ -159,6 +164,27 @@ var (
 		Name:  "aggregate-parallel",
 		Usage: "Enables parallel aggregation of attestations",
 	}
+	enableDutyLookahead = &cli.BoolFlag{
+		Name: "enable-duty-lookahead",
+		Usage: "Enables the validator to fetch the next epoch's duties during the last slot of an epoch, " +
+			"pre-compute selection proofs and domain data ahead of time, and run duties on a bounded " +
+			"worker pool ordered by their deadlines",
+	}
+	dutyLookaheadWorkers = &cli.IntFlag{
+		Name: "duty-lookahead-workers",
+		Usage: "(Advanced): Specifies the maximum number of duties the validator performs concurrently " +
+			"when --enable-duty-lookahead is set. Defaults to the number of available CPUs.",
+	}
+	enableForkChoiceRecorder = &cli.BoolFlag{
+		Name: "enable-forkchoice-recorder",
+		Usage: "Records head changes, tip weight updates, proposer boosts and late block reorg decisions " +
+			"of fork choice, and serves them over the debug API",
+	}
+	forkChoiceRecorderSize = &cli.IntFlag{
+		Name:  "forkchoice-recorder-size",
+		Usage: "(Advanced): Specifies the number of latest events kept by --enable-forkchoice-recorder",
+		Value: 4096,
+	}
 )
 
 // devModeFlags holds list of flags that are set when development mode is on.
@@ -177,8 +203,11 @@ var ValidatorFlags = append(deprecatedFlags, []cli.Flag{
 	dynamicKeyReloadDebounceInterval,
 	attestTimely,
 	enableSlashingProtectionPruning,
+	enablePerformanceHistory,
 	enableDoppelGangerProtection,
 	EnableBeaconRESTApi,
+	enableDutyLookahead,
+	dutyLookaheadWorkers,
 }...)
 
 // E2EValidatorFlags contains a list of the validator feature flags to be tested in E2E.
@@ -213,6 +242,8 @@ var BeaconChainFlags = append(deprecatedBeaconFlags, append(deprecatedFlags, []c
 	disableResourceManager,
 	DisableRegistrationCache,
 	aggregateParallel,
+	enableForkChoiceRecorder,
+	forkChoiceRecorderSize,
 }...)...)
 
 // E2EBeaconChainFlags contains a list of the beacon chain feature flags to be tested in E2E.
@@ -263,3 +263,85 @@ func (m *SparseMerkleTrie) NumOfItems() int {
 	}
 	return len(m.originalItems)
 }
+
+// CompleteSubtreeRoots returns the roots of the largest complete subtrees covering the first count
+// items of the trie, from the highest to the lowest subtree. They are the only nodes needed to
+// insert the items after count.
+func (m *SparseMerkleTrie) CompleteSubtreeRoots(count uint64) ([][]byte, error) {
+	if count > uint64(len(m.originalItems)) {
+		return nil, fmt.Errorf("count %d exceeds the number of items in the trie %d", count, len(m.originalItems))
+	}
+	var roots [][]byte
+	for i := int(m.depth) - 1; i >= 0; i-- {
+		if count&(1<<uint(i)) == 0 {
+			continue
+		}
+		idx := (count >> uint(i)) - 1
+		if idx >= uint64(len(m.branches[i])) {
+			return nil, fmt.Errorf("missing node %d at layer %d", idx, i)
+		}
+		root := bytesutil.ToBytes32(m.branches[i][idx])
+		roots = append(roots, root[:])
+	}
+	return roots, nil
+}
+
+// GenerateTrieFromCompleteSubtreeRoots constructs a trie of count items from the roots of the
+// largest complete subtrees covering them, as returned by CompleteSubtreeRoots. The items themselves
+// are unknown, so that only the items inserted after count can be proven.
+func GenerateTrieFromCompleteSubtreeRoots(roots [][]byte, count uint64, depth uint64) (*SparseMerkleTrie, error) {
+	if depth >= 63 {
+		return nil, errors.New("supported merkle trie depth exceeded (max uint64 depth is 63, " +
+			"theoretical max sparse merkle trie depth is 64)") // PowerOf2 would overflow
+	}
+	if count == 0 {
+		return NewTrie(depth)
+	}
+	if count >= math.PowerOf2(depth) {
+		return nil, fmt.Errorf("count %d exceeds the capacity of a trie of depth %d", count, depth)
+	}
+	var zeroBytes [32]byte
+	layers := make([][][]byte, depth+1)
+	for i := uint64(0); i <= depth; i++ {
+		size := (count + (1 << i) - 1) >> i
+		layers[i] = make([][]byte, size)
+		for j := range layers[i] {
+			layers[i][j] = zeroBytes[:]
+		}
+	}
+	next := 0
+	for i := int(depth) - 1; i >= 0; i-- {
+		if count&(1<<uint(i)) == 0 {
+			continue
+		}
+		if next >= len(roots) {
+			return nil, fmt.Errorf("expected more than %d subtree roots for %d items", len(roots), count)
+		}
+		root := bytesutil.ToBytes32(roots[next])
+		layers[i][(count>>uint(i))-1] = root[:]
+		next++
+	}
+	if next != len(roots) {
+		return nil, fmt.Errorf("expected %d subtree roots for %d items, got %d", next, count, len(roots))
+	}
+	// Compute the incomplete nodes above the last item, the other nodes being either complete subtree
+	// roots or never needed again.
+	for i := uint64(0); i < depth; i++ {
+		parent := (count - 1) >> (i + 1)
+		if (parent+1)<<(i+1) <= count {
+			continue
+		}
+		left := layers[i][2*parent]
+		right := ZeroHashes[i][:]
+		if 2*parent+1 < uint64(len(layers[i])) {
+			right = layers[i][2*parent+1]
+		}
+		node := hash.Hash(append(append([]byte{}, left...), right...))
+		layers[i+1][parent] = node[:]
+	}
+	return &SparseMerkleTrie{
+		branches:      layers,
+		originalItems: bytesutil.SafeCopy2dBytes(layers[0]),
+		depth:         uint(depth),
+	}, nil
+}
@@ -409,3 +409,56 @@ func BenchmarkVerifyMerkleProofWithDepth(b *testing.B) {
 		}
 	}
 }
+
+func TestGenerateTrieFromCompleteSubtreeRoots(t *testing.T) {
+	depth := params.BeaconConfig().DepositContractTreeDepth
+	items := make([][]byte, 23)
+	for i := range items {
+		h := hash.Hash([]byte(strconv.Itoa(i)))
+		items[i] = h[:]
+	}
+	for _, count := range []int{1, 2, 3, 4, 8, 13, 16} {
+		t.Run(strconv.Itoa(count), func(t *testing.T) {
+			full, err := trie.GenerateTrieFromItems(items[:count], depth)
+			require.NoError(t, err)
+			roots, err := full.CompleteSubtreeRoots(uint64(count))
+			require.NoError(t, err)
+			pruned, err := trie.GenerateTrieFromCompleteSubtreeRoots(roots, uint64(count), depth)
+			require.NoError(t, err)
+			assert.Equal(t, count, pruned.NumOfItems())
+			wantRoot, err := full.HashTreeRoot()
+			require.NoError(t, err)
+			gotRoot, err := pruned.HashTreeRoot()
+			require.NoError(t, err)
+			assert.Equal(t, wantRoot, gotRoot)
+
+			for i := count; i < len(items); i++ {
+				require.NoError(t, full.Insert(items[i], i))
+				require.NoError(t, pruned.Insert(items[i], i))
+				wantRoot, err := full.HashTreeRoot()
+				require.NoError(t, err)
+				gotRoot, err := pruned.HashTreeRoot()
+				require.NoError(t, err)
+				require.Equal(t, wantRoot, gotRoot)
+				wantProof, err := full.MerkleProof(i)
+				require.NoError(t, err)
+				gotProof, err := pruned.MerkleProof(i)
+				require.NoError(t, err)
+				require.DeepEqual(t, wantProof, gotProof)
+			}
+		})
+	}
+}
+
+func TestGenerateTrieFromCompleteSubtreeRoots_Invalid(t *testing.T) {
+	h := hash.Hash([]byte("hi"))
+	_, err := trie.GenerateTrieFromCompleteSubtreeRoots([][]byte{h[:]}, 3, 32)
+	assert.ErrorContains(t, "expected more than 1 subtree roots for 3 items", err)
+	_, err = trie.GenerateTrieFromCompleteSubtreeRoots([][]byte{h[:], h[:]}, 2, 32)
+	assert.ErrorContains(t, "expected 1 subtree roots for 2 items, got 2", err)
+
+	full, err := trie.GenerateTrieFromItems([][]byte{h[:]}, 32)
+	require.NoError(t, err)
+	_, err = full.CompleteSubtreeRoots(2)
+	assert.ErrorContains(t, "count 2 exceeds the number of items in the trie 1", err)
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/pkg/errors"
 	ssz "github.com/prysmaticlabs/fastssz"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
 	"github.com/theQRL/qrysm/v4/beacon-chain/state"
 	fieldparams "github.com/theQRL/qrysm/v4/config/fieldparams"
 	"github.com/theQRL/qrysm/v4/config/params"
@@ -133,8 +134,8 @@ func (cf *VersionedUnmarshaler) UnmarshalBeaconState(marshaled []byte) (s state.
 var beaconBlockSlot = fieldSpec{
 	// ssz variable length offset (not to be confused with the fieldSpec offset) is a uint32
 	// variable length. Offsets come before fixed length data, so that's 4 bytes at the beginning
-	// then signature is 96 bytes, 4+96 = 100
-	offset: 100,
+	// then the dilithium signature, 4+4595 = 4599
+	offset: 4 + dilithium2.CryptoBytes,
 	t:      typeUint64,
 }
 
@@ -146,6 +147,21 @@ func slotFromBlock(marshaled []byte) (primitives.Slot, error) {
 	return primitives.Slot(slot), nil
 }
 
+// FromBlock reads the slot of a marshaled ReadOnlySignedBeaconBlock, blinded or not, and looks up the fork version
+// scheduled at that slot by the active BeaconChainConfig to obtain the VersionedUnmarshaler of the block.
+func FromBlock(marshaled []byte) (*VersionedUnmarshaler, error) {
+	slot, err := slotFromBlock(marshaled)
+	if err != nil {
+		return nil, err
+	}
+	fs := forks.NewOrderedSchedule(params.BeaconConfig())
+	cv, err := fs.VersionForEpoch(slots.ToEpoch(slot))
+	if err != nil {
+		return nil, err
+	}
+	return FromForkVersion(cv)
+}
+
 var errBlockForkMismatch = errors.New("fork or config detected in unmarshaler is different than block")
 
 // UnmarshalBeaconBlock uses internal knowledge in the VersionedUnmarshaler to pick the right concrete ReadOnlySignedBeaconBlock type,
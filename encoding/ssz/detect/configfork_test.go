@@ -288,6 +288,42 @@ func TestUnmarshalBlock(t *testing.T) {
 	}
 }
 
+func TestFromBlock(t *testing.T) {
+	undo, err := hackCapellaMaxuint()
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, undo())
+	}()
+	altairS, err := slots.EpochStart(params.BeaconConfig().AltairForkEpoch)
+	require.NoError(t, err)
+	bellaS, err := slots.EpochStart(params.BeaconConfig().BellatrixForkEpoch)
+	require.NoError(t, err)
+	cases := []struct {
+		name string
+		b    func(*testing.T, primitives.Slot) interfaces.ReadOnlySignedBeaconBlock
+		slot primitives.Slot
+		fork int
+	}{
+		{name: "genesis", b: signedTestBlockGenesis, fork: version.Phase0},
+		{name: "last slot of phase 0", b: signedTestBlockGenesis, slot: altairS - 1, fork: version.Phase0},
+		{name: "first slot of altair", b: signedTestBlockAltair, slot: altairS, fork: version.Altair},
+		{name: "first slot of bellatrix", b: signedTestBlockBellatrix, slot: bellaS, fork: version.Bellatrix},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := c.b(t, c.slot)
+			marshaled, err := b.MarshalSSZ()
+			require.NoError(t, err)
+			cf, err := FromBlock(marshaled)
+			require.NoError(t, err)
+			require.Equal(t, c.fork, cf.Fork)
+			bcf, err := cf.UnmarshalBeaconBlock(marshaled)
+			require.NoError(t, err)
+			require.Equal(t, c.slot, bcf.Block().Slot())
+		})
+	}
+}
+
 func TestUnmarshalBlindedBlock(t *testing.T) {
 	undo, err := hackCapellaMaxuint()
 	require.NoError(t, err)
@@ -0,0 +1,242 @@
+package proof
+
+import (
+	"math/bits"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/theQRL/qrysm/v4/crypto/hash"
+)
+
+// LengthPathElement is the path element which refers to the length of a list, as in the
+// get_generalized_index function of the consensus specification.
+const LengthPathElement = "__len__"
+
+// Multiproof is a Merkle multiproof of the leaves at the given generalized indices. The
+// hashes are the roots of the helper indices, in the order of HelperIndices.
+type Multiproof struct {
+	Root    [32]byte
+	Indices []uint64
+	Leaves  [][32]byte
+	Hashes  [][32]byte
+}
+
+// Prove crafts a Merkle multiproof of the generalized indices in the tree below the node.
+func Prove(root Node, indices []uint64) (*Multiproof, error) {
+	if len(indices) == 0 {
+		return nil, errors.New("no generalized indices to prove")
+	}
+	p := &Multiproof{
+		Indices: indices,
+		Leaves:  make([][32]byte, len(indices)),
+	}
+	for i, gindex := range indices {
+		leaf, err := rootAt(root, gindex)
+		if err != nil {
+			return nil, err
+		}
+		p.Leaves[i] = leaf
+	}
+	helpers := HelperIndices(indices)
+	p.Hashes = make([][32]byte, len(helpers))
+	for i, gindex := range helpers {
+		h, err := rootAt(root, gindex)
+		if err != nil {
+			return nil, err
+		}
+		p.Hashes[i] = h
+	}
+	r, err := root.Root()
+	if err != nil {
+		return nil, err
+	}
+	p.Root = r
+	return p, nil
+}
+
+func rootAt(root Node, gindex uint64) ([32]byte, error) {
+	n, err := Get(root, gindex)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return n.Root()
+}
+
+// HelperIndices returns the generalized indices of the nodes needed, besides the leaves
+// themselves, to compute the root from the leaves at the given generalized indices. The
+// indices are sorted in decreasing order.
+func HelperIndices(indices []uint64) []uint64 {
+	helpers := make(map[uint64]bool)
+	paths := make(map[uint64]bool)
+	for _, gindex := range indices {
+		for i := gindex; i > 1; i /= 2 {
+			helpers[i^1] = true
+			paths[i] = true
+		}
+	}
+	result := make([]uint64, 0, len(helpers))
+	for i := range helpers {
+		if !paths[i] {
+			result = append(result, i)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i] > result[j]
+	})
+	return result
+}
+
+// Verify checks that the leaves at the generalized indices, along with the hashes of the
+// helper indices, add up to the root.
+func Verify(root [32]byte, indices []uint64, leaves, hashes [][32]byte) (bool, error) {
+	if len(indices) != len(leaves) {
+		return false, errors.Errorf("got %d leaves for %d generalized indices", len(leaves), len(indices))
+	}
+	helpers := HelperIndices(indices)
+	if len(hashes) != len(helpers) {
+		return false, errors.Errorf("got %d hashes instead of %d", len(hashes), len(helpers))
+	}
+	objects := make(map[uint64][32]byte, len(indices)+len(helpers))
+	for i, gindex := range indices {
+		if gindex == 0 {
+			return false, errors.New("generalized index must be greater than zero")
+		}
+		objects[gindex] = leaves[i]
+	}
+	for i, gindex := range helpers {
+		objects[gindex] = hashes[i]
+	}
+	keys := make([]uint64, 0, len(objects))
+	for k := range objects {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] > keys[j]
+	})
+	for pos := 0; pos < len(keys); pos++ {
+		k := keys[pos]
+		_, hasSibling := objects[k^1]
+		_, hasParent := objects[k/2]
+		if k > 1 && hasSibling && !hasParent {
+			left, right := objects[k&^1], objects[k|1]
+			objects[k/2] = hash.Hash(append(left[:], right[:]...))
+			keys = append(keys, k/2)
+		}
+	}
+	computed, ok := objects[1]
+	if !ok {
+		return false, errors.New("proof does not reach the root")
+	}
+	return computed == root, nil
+}
+
+// GeneralizedIndex returns the generalized index of the value at the path in the Merkle
+// tree of an SSZ container, such as a generated protobuf message. Path elements are the
+// JSON or specification names of container fields, indices into vectors and lists, or
+// LengthPathElement. Indices into lists must be below the length of the list in obj.
+// Indexing into a vector or list of basic values resolves to the chunk holding the value.
+func GeneralizedIndex(obj interface{}, path ...string) (uint64, error) {
+	v := reflect.ValueOf(obj)
+	typ, err := describe(v.Type(), nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	gindex := uint64(1)
+	for _, p := range path {
+		var pos uint64
+		switch typ.kind {
+		case kindBasic:
+			return 0, errors.Errorf("cannot resolve %s in a basic value", p)
+		case kindContainer:
+			found := false
+			for i, f := range typ.fields {
+				if f.name == p || (f.specName != "" && f.specName == p) {
+					pos, found = uint64(i), true
+					gindex, err = descend(gindex, 1, typ.chunkCount(), pos)
+					v = fieldValue(v, f.index)
+					typ = f.typ
+					break
+				}
+			}
+			if !found {
+				return 0, errors.Errorf("no field %s in container", p)
+			}
+		default:
+			isList := typ.kind == kindList || typ.kind == kindBitlist
+			if p == LengthPathElement {
+				if !isList {
+					return 0, errors.New("only lists have a length")
+				}
+				gindex, err = descend(gindex, 1, 2, 1)
+				typ = &sszType{kind: kindBasic, size: 8}
+				break
+			}
+			i, parseErr := strconv.ParseUint(p, 10, 64)
+			if parseErr != nil {
+				return 0, errors.Errorf("%s is not an index", p)
+			}
+			length := typ.length
+			if isList {
+				length = listLength(v)
+			}
+			if i >= length {
+				return 0, errors.Errorf("index %d is out of bounds of length %d", i, length)
+			}
+			base := uint64(1)
+			if isList {
+				base = 2
+			}
+			chunks := typ.chunkCount()
+			switch {
+			case typ.kind == kindBitlist:
+				pos = i / 256
+				typ = &sszType{kind: kindBasic, size: 1}
+			case typ.elem.kind == kindBasic:
+				pos = i * typ.elem.size / bytesPerChunk
+				typ = typ.elem
+			default:
+				pos = i
+				v = v.Index(int(i))
+				typ = typ.elem
+			}
+			gindex, err = descend(gindex, base, chunks, pos)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return gindex, nil
+}
+
+// fieldValue returns the field at the index of a struct, or of the struct pointed to. The
+// fields of a nil pointer are zero values.
+func fieldValue(v reflect.Value, index int) reflect.Value {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v = reflect.New(v.Type().Elem())
+		}
+		v = v.Elem()
+	}
+	return v.Field(index)
+}
+
+// listLength returns the number of elements of a list, or of bits of a bitlist.
+func listLength(v reflect.Value) uint64 {
+	if bl, ok := v.Interface().(bitfield.Bitlist); ok {
+		return bl.Len()
+	}
+	return uint64(v.Len())
+}
+
+// descend returns the generalized index of the chunk at the position in a tree with the
+// given number of chunks below the generalized index, mixing in a length when base is 2.
+func descend(gindex, base, chunks, pos uint64) (uint64, error) {
+	d := depth(chunks)
+	if bits.Len64(gindex)+bits.Len64(base)-1+int(d) > 64 {
+		return 0, errors.New("generalized index does not fit in 64 bits")
+	}
+	return (gindex*base)<<d + pos, nil
+}
@@ -0,0 +1,173 @@
+package proof_test
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/encoding/ssz/proof"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/util"
+)
+
+func testState(t *testing.T, numValidators int) state.BeaconState {
+	st, err := util.NewBeaconStateCapella()
+	require.NoError(t, err)
+	validators := make([]*zondpb.Validator, numValidators)
+	balances := make([]uint64, numValidators)
+	for i := range validators {
+		pubkey := make([]byte, dilithium2.CryptoPublicKeyBytes)
+		pubkey[0] = byte(i)
+		validators[i] = &zondpb.Validator{
+			PublicKey:             pubkey,
+			WithdrawalCredentials: make([]byte, 32),
+			EffectiveBalance:      params.BeaconConfig().MaxEffectiveBalance - uint64(i),
+			ExitEpoch:             params.BeaconConfig().FarFutureEpoch,
+			WithdrawableEpoch:     params.BeaconConfig().FarFutureEpoch,
+		}
+		balances[i] = params.BeaconConfig().MaxEffectiveBalance + uint64(i)
+	}
+	require.NoError(t, st.SetValidators(validators))
+	require.NoError(t, st.SetBalances(balances))
+	require.NoError(t, st.SetInactivityScores(make([]uint64, numValidators)))
+	require.NoError(t, st.SetCurrentParticipationBits(make([]byte, numValidators)))
+	require.NoError(t, st.SetPreviousParticipationBits(make([]byte, numValidators)))
+	pubkeys := make([][]byte, params.BeaconConfig().SyncCommitteeSize)
+	for i := range pubkeys {
+		pubkeys[i] = validators[i%numValidators].PublicKey
+	}
+	committee := &zondpb.SyncCommittee{
+		Pubkeys:         pubkeys,
+		AggregatePubkey: make([]byte, len(pubkeys)*dilithium2.CryptoPublicKeyBytes),
+	}
+	require.NoError(t, st.SetCurrentSyncCommittee(committee))
+	require.NoError(t, st.SetNextSyncCommittee(committee))
+	return st
+}
+
+func TestFromObject_MatchesHashTreeRoot(t *testing.T) {
+	st := testState(t, 16)
+	htr, err := st.HashTreeRoot(context.Background())
+	require.NoError(t, err)
+
+	n, err := proof.FromObject(st.ToProtoUnsafe())
+	require.NoError(t, err)
+	root, err := n.Root()
+	require.NoError(t, err)
+	assert.Equal(t, htr, root)
+
+	header := &zondpb.BeaconBlockHeader{
+		Slot:          3,
+		ProposerIndex: 7,
+		ParentRoot:    make([]byte, 32),
+		StateRoot:     make([]byte, 32),
+		BodyRoot:      make([]byte, 32),
+	}
+	htr, err = header.HashTreeRoot()
+	require.NoError(t, err)
+	n, err = proof.FromObject(header)
+	require.NoError(t, err)
+	root, err = n.Root()
+	require.NoError(t, err)
+	assert.Equal(t, htr, root)
+}
+
+func TestGeneralizedIndex(t *testing.T) {
+	pb := testState(t, 8).ToProtoUnsafe()
+	tests := []struct {
+		path   []string
+		gindex uint64
+	}{
+		{path: nil, gindex: 1},
+		{path: []string{"slot"}, gindex: 34},
+		{path: []string{"finalized_checkpoint", "root"}, gindex: 105},
+		{path: []string{"validators", proof.LengthPathElement}, gindex: 87},
+		{path: []string{"validators", "3"}, gindex: 86<<40 + 3},
+		{path: []string{"validators", "3", "effective_balance"}, gindex: (86<<40+3)<<3 + 2},
+		{path: []string{"validators", "3", "pubkey"}, gindex: (86<<40 + 3) << 3},
+		// Four balances share a chunk.
+		{path: []string{"balances", "5"}, gindex: 88<<38 + 1},
+		{path: []string{"block_roots", "10"}, gindex: 37<<13 + 10},
+	}
+	for _, tt := range tests {
+		gindex, err := proof.GeneralizedIndex(pb, tt.path...)
+		require.NoError(t, err)
+		assert.Equal(t, tt.gindex, gindex, "path %v", tt.path)
+	}
+
+	_, err := proof.GeneralizedIndex(pb, "foo")
+	assert.ErrorContains(t, "no field foo", err)
+	_, err = proof.GeneralizedIndex(pb, "block_roots", "8192")
+	assert.ErrorContains(t, "out of bounds", err)
+	// Lists are bounded by their length, not their limit.
+	_, err = proof.GeneralizedIndex(pb, "validators", "8")
+	assert.ErrorContains(t, "index 8 is out of bounds of length 8", err)
+	_, err = proof.GeneralizedIndex(&zondpb.BeaconStateCapella{}, "validators", "0")
+	assert.ErrorContains(t, "out of bounds of length 0", err)
+	_, err = proof.GeneralizedIndex(pb, "slot", "0")
+	assert.ErrorContains(t, "basic value", err)
+	_, err = proof.GeneralizedIndex(pb, "block_roots", proof.LengthPathElement)
+	assert.ErrorContains(t, "only lists have a length", err)
+}
+
+func TestHelperIndices(t *testing.T) {
+	assert.DeepEqual(t, []uint64{15, 6, 5}, proof.HelperIndices([]uint64{8, 9, 14}))
+	assert.DeepEqual(t, []uint64{104, 53, 27, 12, 7, 2}, proof.HelperIndices([]uint64{105}))
+}
+
+func TestProveAndVerify(t *testing.T) {
+	st := testState(t, 16)
+	htr, err := st.HashTreeRoot(context.Background())
+	require.NoError(t, err)
+	pb := st.ToProtoUnsafe()
+	n, err := proof.FromObject(pb)
+	require.NoError(t, err)
+
+	effectiveBalance, err := proof.GeneralizedIndex(pb, "validators", "5", "effective_balance")
+	require.NoError(t, err)
+	balance, err := proof.GeneralizedIndex(pb, "balances", "5")
+	require.NoError(t, err)
+	numValidators, err := proof.GeneralizedIndex(pb, "validators", proof.LengthPathElement)
+	require.NoError(t, err)
+	summary, err := proof.GeneralizedIndex(pb, "latest_execution_payload_header", "block_hash")
+	require.NoError(t, err)
+	indices := []uint64{effectiveBalance, balance, numValidators, summary}
+
+	p, err := proof.Prove(n, indices)
+	require.NoError(t, err)
+	assert.Equal(t, htr, p.Root)
+	assert.Equal(t, params.BeaconConfig().MaxEffectiveBalance-5, binary.LittleEndian.Uint64(p.Leaves[0][:8]))
+	// The chunk of the fifth balance holds the balances four to seven.
+	assert.Equal(t, params.BeaconConfig().MaxEffectiveBalance+5, binary.LittleEndian.Uint64(p.Leaves[1][8:16]))
+	assert.Equal(t, uint64(16), binary.LittleEndian.Uint64(p.Leaves[2][:8]))
+
+	ok, err := proof.Verify(htr, p.Indices, p.Leaves, p.Hashes)
+	require.NoError(t, err)
+	assert.Equal(t, true, ok)
+
+	p.Leaves[0][0]++
+	ok, err = proof.Verify(htr, p.Indices, p.Leaves, p.Hashes)
+	require.NoError(t, err)
+	assert.Equal(t, false, ok)
+
+	_, err = proof.Verify(htr, p.Indices, p.Leaves, p.Hashes[1:])
+	assert.ErrorContains(t, "hashes instead of", err)
+}
+
+func TestProve_OutOfRange(t *testing.T) {
+	header := &zondpb.BeaconBlockHeader{
+		ParentRoot: make([]byte, 32),
+		StateRoot:  make([]byte, 32),
+		BodyRoot:   make([]byte, 32),
+	}
+	n, err := proof.FromObject(header)
+	require.NoError(t, err)
+	// The slot is a leaf, so nothing lies below it.
+	_, err = proof.Prove(n, []uint64{16})
+	assert.ErrorContains(t, "out of range", err)
+}
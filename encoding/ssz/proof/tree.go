@@ -0,0 +1,400 @@
+package proof
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/theQRL/qrysm/v4/container/trie"
+	"github.com/theQRL/qrysm/v4/crypto/hash"
+)
+
+// Node is a node of the Merkle tree of an SSZ object. Trees are expanded lazily, so that
+// only the nodes on the paths to the proven generalized indices are built. Nodes are not
+// safe for concurrent use.
+type Node interface {
+	// Root returns the hash tree root of the subtree below the node.
+	Root() ([32]byte, error)
+	// Children returns the left and right children of the node.
+	Children() (Node, Node, error)
+}
+
+var errLeaf = errors.New("node is a leaf")
+
+type hashRooter interface {
+	HashTreeRoot() ([32]byte, error)
+}
+
+// FromObject returns the root node of the Merkle tree of an SSZ container, such as a
+// generated protobuf message.
+func FromObject(obj interface{}) (Node, error) {
+	v := reflect.ValueOf(obj)
+	typ, err := describe(v.Type(), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if typ.kind != kindContainer {
+		return nil, errors.Errorf("%s is not a container", v.Type())
+	}
+	return build(v, typ)
+}
+
+// FromObjectWithFieldRoots is like FromObject, but uses the given roots of the fields of the
+// container instead of computing them. This avoids hashing large fields which are not
+// part of a proof when their roots are already known, as with the Merkle layers of a state.
+// The list layers are the Merkle layers of the contents of list fields, by field position,
+// from the roots of the elements up to the root of the contents, as kept by the field tries
+// of a state. Proofs into these lists take the roots of the other elements from the layers
+// instead of hashing every element again.
+func FromObjectWithFieldRoots(obj interface{}, fieldRoots [][]byte, listLayers map[int][][]*[32]byte) (Node, error) {
+	v := reflect.ValueOf(obj)
+	typ, err := describe(v.Type(), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if typ.kind != kindContainer {
+		return nil, errors.Errorf("%s is not a container", v.Type())
+	}
+	if len(fieldRoots) < len(typ.fields) {
+		return nil, errors.Errorf("got %d field roots for a container with %d fields", len(fieldRoots), len(typ.fields))
+	}
+	v = reflect.Indirect(v)
+	nodes := make([]Node, len(typ.fields))
+	for i, f := range typ.fields {
+		fieldValue, fieldType := v.Field(f.index), f.typ
+		expand := func() (Node, error) { return build(fieldValue, fieldType) }
+		if layers, ok := listLayers[i]; ok {
+			if err := checkListLayers(fieldValue, fieldType, layers); err != nil {
+				return nil, errors.Wrapf(err, "field %s", f.name)
+			}
+			expand = func() (Node, error) { return buildFromLayers(fieldValue, fieldType, layers), nil }
+		}
+		nodes[i] = &lazyNode{
+			root:     bytesToRoot(fieldRoots[i]),
+			hasRoot:  true,
+			expandFn: expand,
+		}
+	}
+	return subtree(nodes, depth(uint64(len(nodes)))), nil
+}
+
+func checkListLayers(v reflect.Value, typ *sszType, layers [][]*[32]byte) error {
+	if typ.kind != kindList || typ.elem.kind == kindBasic {
+		return errors.New("layers are only supported for lists of composite values")
+	}
+	if uint64(len(layers)) != depth(typ.chunkCount())+1 {
+		return errors.Errorf("got %d layers for a list of depth %d", len(layers), depth(typ.chunkCount()))
+	}
+	if len(layers[0]) != v.Len() {
+		return errors.Errorf("got %d leaves for a list of length %d", len(layers[0]), v.Len())
+	}
+	return nil
+}
+
+// buildFromLayers returns the node of a list of composite values whose Merkle layers are known.
+func buildFromLayers(v reflect.Value, typ *sszType, layers [][]*[32]byte) Node {
+	length := uint64(v.Len())
+	elem := func(i uint64) (Node, error) {
+		if i >= length {
+			return zeroNode(0), nil
+		}
+		return build(v.Index(int(i)), typ.elem)
+	}
+	content := &layersNode{layers: layers, level: uint64(len(layers) - 1), elem: elem}
+	return &branchNode{left: content, right: uint64Leaf(length)}
+}
+
+// Get returns the node at the generalized index in the tree below the node.
+func Get(n Node, gindex uint64) (Node, error) {
+	if gindex == 0 {
+		return nil, errors.New("generalized index must be greater than zero")
+	}
+	for i := bits.Len64(gindex) - 2; i >= 0; i-- {
+		left, right, err := n.Children()
+		if err != nil {
+			return nil, errors.Wrapf(err, "generalized index %d is out of range", gindex)
+		}
+		if gindex&(uint64(1)<<uint(i)) != 0 {
+			n = right
+		} else {
+			n = left
+		}
+	}
+	return n, nil
+}
+
+func build(v reflect.Value, typ *sszType) (Node, error) {
+	switch typ.kind {
+	case kindBasic:
+		return basicLeaf(v), nil
+	case kindContainer:
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			v = reflect.New(v.Type().Elem())
+		}
+		expand := func() (Node, error) { return buildContainer(reflect.Indirect(v), typ) }
+		if r, ok := v.Interface().(hashRooter); ok {
+			return &lazyNode{rootFn: r.HashTreeRoot, expandFn: expand}, nil
+		}
+		return expand()
+	case kindBitlist:
+		bl, ok := v.Interface().(bitfield.Bitlist)
+		if !ok {
+			return nil, errors.Errorf("%s is not a bitlist", v.Type())
+		}
+		if bl.Len() > typ.length {
+			return nil, errors.Errorf("bitlist of length %d exceeds its limit of %d", bl.Len(), typ.length)
+		}
+		content := subtree(packBytes(bl.Bytes()), depth(typ.chunkCount()))
+		return &branchNode{left: content, right: uint64Leaf(bl.Len())}, nil
+	case kindVector, kindList:
+		length := uint64(v.Len())
+		if typ.kind == kindVector && length != typ.length {
+			return nil, errors.Errorf("vector has %d elements instead of %d", length, typ.length)
+		}
+		if typ.kind == kindList && length > typ.length {
+			return nil, errors.Errorf("list of length %d exceeds its limit of %d", length, typ.length)
+		}
+		var chunks []Node
+		if typ.elem.kind == kindBasic {
+			chunks = packBasic(v, typ.elem.size)
+		} else {
+			chunks = make([]Node, length)
+			for i := range chunks {
+				n, err := build(v.Index(i), typ.elem)
+				if err != nil {
+					return nil, errors.Wrapf(err, "element %d", i)
+				}
+				chunks[i] = n
+			}
+		}
+		content := subtree(chunks, depth(typ.chunkCount()))
+		if typ.kind == kindVector {
+			return content, nil
+		}
+		return &branchNode{left: content, right: uint64Leaf(length)}, nil
+	default:
+		return nil, errors.Errorf("unsupported ssz kind %d", typ.kind)
+	}
+}
+
+func buildContainer(v reflect.Value, typ *sszType) (Node, error) {
+	nodes := make([]Node, len(typ.fields))
+	for i, f := range typ.fields {
+		n, err := build(v.Field(f.index), f.typ)
+		if err != nil {
+			return nil, errors.Wrapf(err, "field %s", f.name)
+		}
+		nodes[i] = n
+	}
+	return subtree(nodes, depth(uint64(len(nodes)))), nil
+}
+
+func basicLeaf(v reflect.Value) Node {
+	var leaf leafNode
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			leaf[0] = 1
+		}
+	default:
+		binary.LittleEndian.PutUint64(leaf[:8], v.Uint())
+	}
+	return leaf
+}
+
+func uint64Leaf(i uint64) Node {
+	var leaf leafNode
+	binary.LittleEndian.PutUint64(leaf[:8], i)
+	return leaf
+}
+
+// packBasic serializes a vector or list of basic values and splits it into chunks.
+func packBasic(v reflect.Value, size uint64) []Node {
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		return packBytes(v.Bytes())
+	}
+	buf := make([]byte, uint64(v.Len())*size)
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		offset := uint64(i) * size
+		switch elem.Kind() {
+		case reflect.Bool:
+			if elem.Bool() {
+				buf[offset] = 1
+			}
+		case reflect.Uint8:
+			buf[offset] = uint8(elem.Uint())
+		case reflect.Uint16:
+			binary.LittleEndian.PutUint16(buf[offset:], uint16(elem.Uint()))
+		case reflect.Uint32:
+			binary.LittleEndian.PutUint32(buf[offset:], uint32(elem.Uint()))
+		default:
+			binary.LittleEndian.PutUint64(buf[offset:], elem.Uint())
+		}
+	}
+	return packBytes(buf)
+}
+
+func packBytes(b []byte) []Node {
+	chunks := make([]Node, (len(b)+bytesPerChunk-1)/bytesPerChunk)
+	for i := range chunks {
+		var leaf leafNode
+		copy(leaf[:], b[i*bytesPerChunk:])
+		chunks[i] = leaf
+	}
+	return chunks
+}
+
+func bytesToRoot(b []byte) [32]byte {
+	var root [32]byte
+	copy(root[:], b)
+	return root
+}
+
+// subtree returns the root of a tree of the given depth whose first leaves are the nodes and
+// whose remaining leaves are zero.
+func subtree(nodes []Node, d uint64) Node {
+	if len(nodes) == 0 {
+		return zeroNode(d)
+	}
+	if d == 0 {
+		return nodes[0]
+	}
+	return &vectorNode{nodes: nodes, depth: d}
+}
+
+type leafNode [32]byte
+
+func (n leafNode) Root() ([32]byte, error) {
+	return n, nil
+}
+
+func (leafNode) Children() (Node, Node, error) {
+	return nil, nil, errLeaf
+}
+
+// zeroNode is the root of a tree of zero leaves of the given depth.
+type zeroNode uint64
+
+func (n zeroNode) Root() ([32]byte, error) {
+	if uint64(n) >= uint64(len(trie.ZeroHashes)) {
+		return [32]byte{}, errors.Errorf("no zero hash for depth %d", n)
+	}
+	return trie.ZeroHashes[n], nil
+}
+
+func (n zeroNode) Children() (Node, Node, error) {
+	if n == 0 {
+		return nil, nil, errLeaf
+	}
+	return n - 1, n - 1, nil
+}
+
+type branchNode struct {
+	left, right Node
+}
+
+func (n *branchNode) Root() ([32]byte, error) {
+	return hashChildren(n.left, n.right)
+}
+
+func (n *branchNode) Children() (Node, Node, error) {
+	return n.left, n.right, nil
+}
+
+type vectorNode struct {
+	nodes []Node
+	depth uint64
+}
+
+func (n *vectorNode) Root() ([32]byte, error) {
+	left, right, err := n.Children()
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return hashChildren(left, right)
+}
+
+func (n *vectorNode) Children() (Node, Node, error) {
+	half := uint64(1) << (n.depth - 1)
+	if uint64(len(n.nodes)) <= half {
+		return subtree(n.nodes, n.depth-1), zeroNode(n.depth - 1), nil
+	}
+	return subtree(n.nodes[:half], n.depth-1), subtree(n.nodes[half:], n.depth-1), nil
+}
+
+// layersNode is a node of a tree whose Merkle layers are known, with the roots of the elements
+// in the first layer. Nodes past the end of a layer are roots of zero subtrees. The subtrees of
+// the elements are only built when their children are needed.
+type layersNode struct {
+	layers [][]*[32]byte
+	level  uint64
+	pos    uint64
+	elem   func(i uint64) (Node, error)
+}
+
+func (n *layersNode) Root() ([32]byte, error) {
+	if layer := n.layers[n.level]; n.pos < uint64(len(layer)) && layer[n.pos] != nil {
+		return *layer[n.pos], nil
+	}
+	return zeroNode(n.level).Root()
+}
+
+func (n *layersNode) Children() (Node, Node, error) {
+	if n.level == 0 {
+		elem, err := n.elem(n.pos)
+		if err != nil {
+			return nil, nil, err
+		}
+		return elem.Children()
+	}
+	left := &layersNode{layers: n.layers, level: n.level - 1, pos: 2 * n.pos, elem: n.elem}
+	right := &layersNode{layers: n.layers, level: n.level - 1, pos: 2*n.pos + 1, elem: n.elem}
+	return left, right, nil
+}
+
+// lazyNode is the root of a subtree which is only built when its children are needed.
+// Its root is either known up front or computed by rootFn.
+type lazyNode struct {
+	root     [32]byte
+	hasRoot  bool
+	rootFn   func() ([32]byte, error)
+	expandFn func() (Node, error)
+	expanded Node
+}
+
+func (n *lazyNode) Root() ([32]byte, error) {
+	if !n.hasRoot {
+		root, err := n.rootFn()
+		if err != nil {
+			return [32]byte{}, err
+		}
+		n.root, n.hasRoot = root, true
+	}
+	return n.root, nil
+}
+
+func (n *lazyNode) Children() (Node, Node, error) {
+	if n.expanded == nil {
+		expanded, err := n.expandFn()
+		if err != nil {
+			return nil, nil, err
+		}
+		n.expanded = expanded
+	}
+	return n.expanded.Children()
+}
+
+func hashChildren(left, right Node) ([32]byte, error) {
+	l, err := left.Root()
+	if err != nil {
+		return [32]byte{}, err
+	}
+	r, err := right.Root()
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return hash.Hash(append(l[:], r[:]...)), nil
+}
@@ -0,0 +1,159 @@
+package proof
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-bitfield"
+)
+
+type sszKind int
+
+const (
+	kindBasic sszKind = iota
+	kindContainer
+	kindVector
+	kindList
+	kindBitlist
+)
+
+const bytesPerChunk = 32
+
+var bitlistType = reflect.TypeOf(bitfield.Bitlist{})
+
+// sszType describes how a Go type is merkleized. It is derived from the Go type and the
+// ssz-size and ssz-max struct tags of the generated protobuf types.
+type sszType struct {
+	kind sszKind
+	// size is the byte size of a basic type.
+	size uint64
+	// length is the length of a vector or the limit of a list or bitlist.
+	length uint64
+	elem   *sszType
+	fields []sszField
+}
+
+type sszField struct {
+	name     string
+	specName string
+	index    int
+	typ      *sszType
+}
+
+// describe derives the SSZ type of t. The sizes and limits are the comma separated
+// dimensions of the ssz-size and ssz-max tags of the field holding the value.
+func describe(t reflect.Type, sizes, limits []string) (*sszType, error) {
+	if t == bitlistType {
+		if len(limits) == 0 {
+			return nil, errors.New("bitlist has no ssz-max tag")
+		}
+		limit, err := strconv.ParseUint(limits[0], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid bitlist limit %s", limits[0])
+		}
+		return &sszType{kind: kindBitlist, length: limit}, nil
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Uint8:
+		return &sszType{kind: kindBasic, size: 1}, nil
+	case reflect.Uint16:
+		return &sszType{kind: kindBasic, size: 2}, nil
+	case reflect.Uint32:
+		return &sszType{kind: kindBasic, size: 4}, nil
+	case reflect.Uint64:
+		return &sszType{kind: kindBasic, size: 8}, nil
+	case reflect.Ptr:
+		return describe(t.Elem(), sizes, limits)
+	case reflect.Struct:
+		return describeContainer(t)
+	case reflect.Slice, reflect.Array:
+		var innerSizes, innerLimits []string
+		if len(sizes) > 1 {
+			innerSizes = sizes[1:]
+		}
+		if len(limits) > 1 {
+			innerLimits = limits[1:]
+		}
+		elem, err := describe(t.Elem(), innerSizes, innerLimits)
+		if err != nil {
+			return nil, err
+		}
+		if len(sizes) > 0 && sizes[0] != "?" {
+			length, err := strconv.ParseUint(sizes[0], 10, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid vector length %s", sizes[0])
+			}
+			return &sszType{kind: kindVector, length: length, elem: elem}, nil
+		}
+		if len(limits) > 0 {
+			limit, err := strconv.ParseUint(limits[0], 10, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid list limit %s", limits[0])
+			}
+			return &sszType{kind: kindList, length: limit, elem: elem}, nil
+		}
+		return nil, errors.Errorf("no ssz-size or ssz-max tag for %s", t)
+	default:
+		return nil, errors.Errorf("unsupported type %s", t)
+	}
+}
+
+func describeContainer(t reflect.Type) (*sszType, error) {
+	typ := &sszType{kind: kindContainer}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		// Unexported fields hold the protobuf internal state.
+		if f.PkgPath != "" {
+			continue
+		}
+		fieldType, err := describe(f.Type, tagDimensions(f.Tag.Get("ssz-size")), tagDimensions(f.Tag.Get("ssz-max")))
+		if err != nil {
+			return nil, errors.Wrapf(err, "field %s of %s", f.Name, t)
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = f.Name
+		}
+		typ.fields = append(typ.fields, sszField{name: name, specName: f.Tag.Get("spec-name"), index: i, typ: fieldType})
+	}
+	if len(typ.fields) == 0 {
+		return nil, errors.Errorf("container %s has no fields", t)
+	}
+	return typ, nil
+}
+
+func tagDimensions(tag string) []string {
+	if tag == "" {
+		return nil
+	}
+	return strings.Split(tag, ",")
+}
+
+// chunkCount returns the number of leaves of the Merkle tree of the type, before padding and
+// without the length mix-in of lists.
+func (t *sszType) chunkCount() uint64 {
+	switch t.kind {
+	case kindContainer:
+		return uint64(len(t.fields))
+	case kindVector, kindList:
+		if t.elem.kind == kindBasic {
+			return (t.length*t.elem.size + bytesPerChunk - 1) / bytesPerChunk
+		}
+		return t.length
+	case kindBitlist:
+		return (t.length + 255) / 256
+	default:
+		return 1
+	}
+}
+
+// depth returns the depth of a Merkle tree with the given number of leaves.
+func depth(leaves uint64) uint64 {
+	d := uint64(0)
+	for (uint64(1) << d) < leaves {
+		d++
+	}
+	return d
+}
@@ -256,6 +256,12 @@ func (s *PremineGenesisConfig) populate(g state.BeaconState) error {
 	if err := s.setInactivityScores(g); err != nil {
 		return err
 	}
+	if err := s.setCurrentEpochParticipation(g); err != nil {
+		return err
+	}
+	if err := s.setPrevEpochParticipation(g); err != nil {
+		return err
+	}
 	if err := s.setSyncCommittees(g); err != nil {
 		return err
 	}
@@ -316,6 +322,38 @@ func (s *PremineGenesisConfig) setInactivityScores(g state.BeaconState) error {
 	return g.SetInactivityScores(scores)
 }
 
+func (s *PremineGenesisConfig) setCurrentEpochParticipation(g state.BeaconState) error {
+	if s.Version < version.Altair {
+		return nil
+	}
+
+	p, err := g.CurrentEpochParticipation()
+	if err != nil {
+		return err
+	}
+	missing := len(g.Validators()) - len(p)
+	if missing > 0 {
+		p = append(p, make([]byte, missing)...)
+	}
+	return g.SetCurrentParticipationBits(p)
+}
+
+func (s *PremineGenesisConfig) setPrevEpochParticipation(g state.BeaconState) error {
+	if s.Version < version.Altair {
+		return nil
+	}
+
+	p, err := g.PreviousEpochParticipation()
+	if err != nil {
+		return err
+	}
+	missing := len(g.Validators()) - len(p)
+	if missing > 0 {
+		p = append(p, make([]byte, missing)...)
+	}
+	return g.SetPreviousParticipationBits(p)
+}
+
 func (s *PremineGenesisConfig) setSyncCommittees(g state.BeaconState) error {
 	if s.Version < version.Altair {
 		return nil
@@ -128,7 +128,7 @@ func validatorsParticipating(_ *types.EvaluationContext, conns ...*grpc.ClientCo
 		expected = 0.95
 	}
 	if partRate < expected {
-		st, err := debugClient.GetBeaconStateV2(context.Background(), &eth.BeaconStateRequestV2{StateId: []byte("head")})
+		st, err := debugClient.GetBeaconStateV2(context.Background(), &zond.BeaconStateRequestV2{StateId: []byte("head")})
 		if err != nil {
 			return errors.Wrap(err, "failed to get beacon state")
 		}
@@ -136,19 +136,19 @@ func validatorsParticipating(_ *types.EvaluationContext, conns ...*grpc.ClientCo
 		var missTgtVals []uint64
 		var missHeadVals []uint64
 		switch obj := st.Data.State.(type) {
-		case *eth.BeaconStateContainer_Phase0State:
+		case *zond.BeaconStateContainer_Phase0State:
 		// Do Nothing
-		case *eth.BeaconStateContainer_AltairState:
+		case *zond.BeaconStateContainer_AltairState:
 			missSrcVals, missTgtVals, missHeadVals, err = findMissingValidators(obj.AltairState.PreviousEpochParticipation)
 			if err != nil {
 				return errors.Wrap(err, "failed to get missing validators")
 			}
-		case *eth.BeaconStateContainer_BellatrixState:
+		case *zond.BeaconStateContainer_BellatrixState:
 			missSrcVals, missTgtVals, missHeadVals, err = findMissingValidators(obj.BellatrixState.PreviousEpochParticipation)
 			if err != nil {
 				return errors.Wrap(err, "failed to get missing validators")
 			}
-		case *eth.BeaconStateContainer_CapellaState:
+		case *zond.BeaconStateContainer_CapellaState:
 			missSrcVals, missTgtVals, missHeadVals, err = findMissingValidators(obj.CapellaState.PreviousEpochParticipation)
 			if err != nil {
 				return errors.Wrap(err, "failed to get missing validators")
@@ -0,0 +1,53 @@
+package simulator
+
+import (
+	"sync"
+	"time"
+
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+)
+
+// Clock is a controllable clock shared by all the nodes of a simulation. Time only moves when the simulator
+// advances it, so slots last as long as it takes the nodes to process them rather than SecondsPerSlot.
+type Clock struct {
+	sync.RWMutex
+	genesis time.Time
+	now     time.Time
+}
+
+// NewClock returns a clock for the given genesis time, set to genesis.
+func NewClock(genesis time.Time) *Clock {
+	return &Clock{genesis: genesis, now: genesis}
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.RLock()
+	defer c.RUnlock()
+	return c.now
+}
+
+// Set moves the clock to t. The clock never goes backwards, so earlier times are ignored.
+func (c *Clock) Set(t time.Time) {
+	c.Lock()
+	defer c.Unlock()
+	if t.After(c.now) {
+		c.now = t
+	}
+}
+
+// SlotTime returns the start time of the slot, offset by d.
+func (c *Clock) SlotTime(slot primitives.Slot, d time.Duration) time.Time {
+	secs := time.Duration(uint64(slot)*params.BeaconConfig().SecondsPerSlot) * time.Second
+	return c.genesis.Add(secs + d)
+}
+
+// CurrentSlot returns the slot of the current time of the clock.
+func (c *Clock) CurrentSlot() primitives.Slot {
+	elapsed := c.Now().Sub(c.genesis)
+	if elapsed < 0 {
+		return 0
+	}
+	return primitives.Slot(uint64(elapsed/time.Second) / params.BeaconConfig().SecondsPerSlot)
+}
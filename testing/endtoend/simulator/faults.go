@@ -0,0 +1,150 @@
+package simulator
+
+import (
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+)
+
+// meshTimeout bounds the real time the nodes take to notice peers joining or leaving the gossip topics.
+const meshTimeout = 10 * time.Second
+
+// Partition splits the network into the given groups of node indices. Nodes only exchange gossip and blocks
+// with the nodes of their group, and the nodes left out of every group form a group of their own.
+func (s *Simulator) Partition(groups ...[]int) error {
+	assigned := make([]int, len(s.nodes))
+	for i := range assigned {
+		assigned[i] = len(groups)
+	}
+	for g, nodes := range groups {
+		for _, n := range nodes {
+			if n < 0 || n >= len(s.nodes) {
+				return errors.Errorf("unknown node %d", n)
+			}
+			assigned[n] = g
+		}
+	}
+	s.lock.Lock()
+	s.groups = assigned
+	s.lock.Unlock()
+	return s.relink()
+}
+
+// Heal reconnects all the nodes after a partition.
+func (s *Simulator) Heal() error {
+	s.lock.Lock()
+	s.groups = make([]int, len(s.nodes))
+	s.lock.Unlock()
+	return s.relink()
+}
+
+// SetOffline stops the validators from proposing and attesting.
+func (s *Simulator) SetOffline(validators ...primitives.ValidatorIndex) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, v := range validators {
+		s.offline[v] = true
+	}
+}
+
+// SetOnline resumes the duties of validators set offline.
+func (s *Simulator) SetOnline(validators ...primitives.ValidatorIndex) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, v := range validators {
+		delete(s.offline, v)
+	}
+}
+
+// SetDelay delays the gossip of the blocks and attestations of the validators of the node by d of clock time.
+// The node itself processes them on time. A delay past the attestation deadline makes the blocks of the node
+// late for the other nodes.
+func (s *Simulator) SetDelay(node int, d time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.delays[node] = d
+}
+
+// reachable returns the other nodes of the group of the node.
+func (s *Simulator) reachable(node int) []int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var nodes []int
+	for i, g := range s.groups {
+		if i != node && g == s.groups[node] {
+			nodes = append(nodes, i)
+		}
+	}
+	return nodes
+}
+
+// relink links and connects the nodes of the same group, unlinks the others, and waits for the gossip topics to
+// reflect it.
+func (s *Simulator) relink() error {
+	for i, a := range s.nodes {
+		reachable := make(map[int]bool)
+		for _, j := range s.reachable(i) {
+			reachable[j] = true
+		}
+		for j := i + 1; j < len(s.nodes); j++ {
+			b := s.nodes[j]
+			linked := len(s.net.LinksBetweenPeers(a.host.ID(), b.host.ID())) > 0
+			switch {
+			case reachable[j] && !linked:
+				if _, err := s.net.LinkPeers(a.host.ID(), b.host.ID()); err != nil {
+					return err
+				}
+				if _, err := s.net.ConnectPeers(a.host.ID(), b.host.ID()); err != nil {
+					return err
+				}
+			case !reachable[j] && linked:
+				if err := s.net.DisconnectPeers(a.host.ID(), b.host.ID()); err != nil {
+					return err
+				}
+				if err := s.net.UnlinkPeers(a.host.ID(), b.host.ID()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return s.waitForMesh()
+}
+
+// waitForMesh waits until every node sees exactly the nodes of its group on the gossip topics.
+func (s *Simulator) waitForMesh() error {
+	deadline := time.Now().Add(meshTimeout)
+	for {
+		ready := true
+		for _, n := range s.nodes {
+			want := make(map[peer.ID]bool)
+			for _, j := range s.reachable(n.index) {
+				want[s.nodes[j].host.ID()] = true
+			}
+			if !samePeers(want, n.blocks.ListPeers()) || !samePeers(want, n.atts.ListPeers()) {
+				ready = false
+				break
+			}
+		}
+		if ready {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.New("nodes did not see the expected gossip peers in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func samePeers(want map[peer.ID]bool, got []peer.ID) bool {
+	if len(want) != len(got) {
+		return false
+	}
+	for _, p := range got {
+		if !want[p] {
+			return false
+		}
+	}
+	return true
+}
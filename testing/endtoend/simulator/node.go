@@ -0,0 +1,376 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/theQRL/qrysm/v4/async/event"
+	"github.com/theQRL/qrysm/v4/beacon-chain/blockchain"
+	"github.com/theQRL/qrysm/v4/beacon-chain/cache/depositcache"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/blocks"
+	"github.com/theQRL/qrysm/v4/beacon-chain/db"
+	testDB "github.com/theQRL/qrysm/v4/beacon-chain/db/testing"
+	mockExecution "github.com/theQRL/qrysm/v4/beacon-chain/execution/testing"
+	doublylinkedtree "github.com/theQRL/qrysm/v4/beacon-chain/forkchoice/doubly-linked-tree"
+	"github.com/theQRL/qrysm/v4/beacon-chain/operations/attestations"
+	"github.com/theQRL/qrysm/v4/beacon-chain/operations/blstoexec"
+	"github.com/theQRL/qrysm/v4/beacon-chain/operations/slashings"
+	"github.com/theQRL/qrysm/v4/beacon-chain/operations/voluntaryexits"
+	beaconv1alpha1 "github.com/theQRL/qrysm/v4/beacon-chain/rpc/prysm/v1alpha1/beacon"
+	"github.com/theQRL/qrysm/v4/beacon-chain/startup"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state/stategen"
+	consensusblocks "github.com/theQRL/qrysm/v4/consensus-types/blocks"
+	"github.com/theQRL/qrysm/v4/consensus-types/interfaces"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	blockTopic       = "/qrysm/simulator/beacon_block"
+	attestationTopic = "/qrysm/simulator/beacon_attestation"
+
+	// blocksByRootProtocol serves the blocks a node is missing the parent of, one root per stream.
+	blocksByRootProtocol = "/qrysm/simulator/blocks_by_root/1"
+
+	bufconnSize = 1 << 20
+)
+
+// Node is a beacon node of the simulation. It runs the blockchain service of a real beacon node on its own
+// database, and exchanges blocks and attestations with the other nodes over the in-memory libp2p network.
+type Node struct {
+	index      int
+	sim        *Simulator
+	host       host.Host
+	blocks     *pubsub.Topic
+	atts       *pubsub.Topic
+	db         db.Database
+	chain      *blockchain.Service
+	attPool    attestations.Pool
+	stateFeed  *event.Feed
+	grpcServer *grpc.Server
+	conn       *grpc.ClientConn
+}
+
+// StateFeed satisfies the state notifier interface of the blockchain service.
+func (n *Node) StateFeed() *event.Feed {
+	return n.stateFeed
+}
+
+// Index returns the index of the node in the simulation.
+func (n *Node) Index() int {
+	return n.index
+}
+
+// Chain returns the blockchain service of the node.
+func (n *Node) Chain() *blockchain.Service {
+	return n.chain
+}
+
+// Conn returns a gRPC connection to the beacon chain service of the node, as used by the e2e evaluators.
+func (n *Node) Conn() *grpc.ClientConn {
+	return n.conn
+}
+
+func newNode(ctx context.Context, sim *Simulator, index int, h host.Host, genesis state.BeaconState, engine *mockExecution.EngineClient) (*Node, error) {
+	// The database registers its metrics with the default registerer, which only takes one open database.
+	defaultRegisterer := prometheus.DefaultRegisterer
+	prometheus.DefaultRegisterer = prometheus.NewRegistry()
+	beaconDB := testDB.SetupDB(sim.t)
+	prometheus.DefaultRegisterer = defaultRegisterer
+	if err := beaconDB.SaveGenesisData(ctx, genesis.Copy()); err != nil {
+		return nil, errors.Wrap(err, "could not save genesis data")
+	}
+	fcs := doublylinkedtree.New()
+	fcs.SetNower(sim.clock.Now)
+	sg := stategen.New(beaconDB, fcs)
+	fcs.SetBalancesByRooter(sg.ActiveNonSlashedBalancesByRoot)
+	attPool := attestations.NewPool()
+	attSrv, err := attestations.NewService(ctx, &attestations.Config{Pool: attPool})
+	if err != nil {
+		return nil, err
+	}
+	dc, err := depositcache.New()
+	if err != nil {
+		return nil, err
+	}
+	n := &Node{
+		index:     index,
+		sim:       sim,
+		host:      h,
+		db:        beaconDB,
+		attPool:   attPool,
+		stateFeed: new(event.Feed),
+	}
+	n.chain, err = blockchain.NewService(ctx,
+		blockchain.WithDatabase(beaconDB),
+		blockchain.WithStateNotifier(n),
+		blockchain.WithStateGen(sg),
+		blockchain.WithForkChoiceStore(fcs),
+		blockchain.WithClockSynchronizer(startup.NewClockSynchronizer()),
+		blockchain.WithAttestationPool(attPool),
+		blockchain.WithAttestationService(attSrv),
+		blockchain.WithExitPool(voluntaryexits.NewPool()),
+		blockchain.WithSlashingPool(slashings.NewPool()),
+		blockchain.WithDilithiumToExecPool(blstoexec.NewPool()),
+		blockchain.WithDepositCache(dc),
+		blockchain.WithExecutionEngineCaller(engine),
+		blockchain.WithNower(sim.clock.Now),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create blockchain service")
+	}
+	if err := n.chain.StartFromSavedState(genesis.Copy()); err != nil {
+		return nil, errors.Wrap(err, "could not start blockchain service")
+	}
+	if err := n.startRPC(ctx, beaconDB, sg); err != nil {
+		return nil, err
+	}
+	h.SetStreamHandler(blocksByRootProtocol, n.handleBlocksByRoot)
+	return n, nil
+}
+
+// startRPC serves the v1alpha1 beacon chain service of the node over an in-memory listener.
+func (n *Node) startRPC(ctx context.Context, beaconDB db.Database, sg *stategen.State) error {
+	lis := bufconn.Listen(bufconnSize)
+	n.grpcServer = grpc.NewServer()
+	zondpb.RegisterBeaconChainServer(n.grpcServer, &beaconv1alpha1.Server{
+		Ctx:                   ctx,
+		BeaconDB:              beaconDB,
+		AttestationsPool:      n.attPool,
+		HeadFetcher:           n.chain,
+		FinalizationFetcher:   n.chain,
+		CanonicalFetcher:      n.chain,
+		OptimisticModeFetcher: n.chain,
+		GenesisTimeFetcher:    n.chain,
+		StateNotifier:         n,
+		StateGen:              sg,
+		ReplayerBuilder:       stategen.NewCanonicalHistory(beaconDB, n.chain, n.chain),
+	})
+	go func() {
+		if err := n.grpcServer.Serve(lis); err != nil {
+			log.WithError(err).WithField("node", n.index).Debug("gRPC server stopped")
+		}
+	}()
+	conn, err := grpc.DialContext(ctx, fmt.Sprintf("node-%d", n.index),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not dial gRPC server")
+	}
+	n.conn = conn
+	return nil
+}
+
+// join subscribes the node to the gossip topics and starts handling the messages it receives.
+func (n *Node) join(ctx context.Context, ps *pubsub.PubSub) error {
+	var err error
+	if n.blocks, err = ps.Join(blockTopic); err != nil {
+		return err
+	}
+	if n.atts, err = ps.Join(attestationTopic); err != nil {
+		return err
+	}
+	blockSub, err := n.blocks.Subscribe()
+	if err != nil {
+		return err
+	}
+	attSub, err := n.atts.Subscribe()
+	if err != nil {
+		return err
+	}
+	go n.handle(ctx, blockSub, n.receiveGossipBlock)
+	go n.handle(ctx, attSub, n.receiveGossipAttestation)
+	return nil
+}
+
+func (n *Node) handle(ctx context.Context, sub *pubsub.Subscription, receive func(context.Context, []byte, peer.ID) error) {
+	defer sub.Cancel()
+	for {
+		msg, err := sub.Next(ctx)
+		if err != nil {
+			return
+		}
+		if msg.ReceivedFrom == n.host.ID() {
+			continue
+		}
+		if err := receive(ctx, msg.Data, msg.ReceivedFrom); err != nil {
+			log.WithError(err).WithField("node", n.index).Warn("Could not process gossip message")
+		}
+		n.sim.delivered(n.index, msg.Data)
+	}
+}
+
+func (n *Node) receiveGossipBlock(ctx context.Context, data []byte, from peer.ID) error {
+	blk, err := unmarshalBlock(data)
+	if err != nil {
+		return err
+	}
+	return n.receiveBlock(ctx, blk, from)
+}
+
+// receiveBlock imports the block into the chain of the node, after requesting its missing ancestors from
+// the peer that sent it.
+func (n *Node) receiveBlock(ctx context.Context, blk interfaces.ReadOnlySignedBeaconBlock, from peer.ID) error {
+	root, err := blk.Block().HashTreeRoot()
+	if err != nil {
+		return err
+	}
+	if n.chain.HasBlock(ctx, root) {
+		return nil
+	}
+	parentRoot := blk.Block().ParentRoot()
+	if !n.chain.HasBlock(ctx, parentRoot) {
+		parent, err := n.requestBlock(ctx, from, parentRoot)
+		if err != nil {
+			return errors.Wrapf(err, "could not request parent %#x of block at slot %d", parentRoot, blk.Block().Slot())
+		}
+		if err := n.receiveBlock(ctx, parent, from); err != nil {
+			return err
+		}
+	}
+	if err := n.chain.ReceiveBlock(ctx, blk, root); err != nil {
+		return errors.Wrapf(err, "could not receive block at slot %d", blk.Block().Slot())
+	}
+	return nil
+}
+
+// importBlock imports a block proposed by a validator of the node.
+func (n *Node) importBlock(ctx context.Context, blk interfaces.ReadOnlySignedBeaconBlock) error {
+	return n.receiveBlock(ctx, blk, "")
+}
+
+func (n *Node) requestBlock(ctx context.Context, p peer.ID, root [32]byte) (interfaces.ReadOnlySignedBeaconBlock, error) {
+	if p == "" {
+		return nil, errors.New("no peer to request the block from")
+	}
+	s, err := n.host.NewStream(ctx, p, blocksByRootProtocol)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := s.Close(); err != nil {
+			log.WithError(err).Debug("Could not close stream")
+		}
+	}()
+	if _, err := s.Write(root[:]); err != nil {
+		return nil, err
+	}
+	if err := s.CloseWrite(); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("peer does not have the block")
+	}
+	return unmarshalBlock(data)
+}
+
+func (n *Node) handleBlocksByRoot(s network.Stream) {
+	defer func() {
+		if err := s.Close(); err != nil {
+			log.WithError(err).Debug("Could not close stream")
+		}
+	}()
+	var root [32]byte
+	if _, err := io.ReadFull(s, root[:]); err != nil {
+		log.WithError(err).Debug("Could not read blocks by root request")
+		return
+	}
+	blk, err := n.db.Block(n.sim.ctx, root)
+	if err != nil || blk == nil || blk.IsNil() {
+		return
+	}
+	pb, err := blk.Proto()
+	if err != nil {
+		return
+	}
+	data, err := marshalSSZ(pb)
+	if err != nil {
+		return
+	}
+	if _, err := s.Write(data); err != nil {
+		log.WithError(err).Debug("Could not write block")
+	}
+}
+
+func (n *Node) receiveGossipAttestation(ctx context.Context, data []byte, _ peer.ID) error {
+	att := &zondpb.Attestation{}
+	if err := att.UnmarshalSSZ(data); err != nil {
+		return err
+	}
+	return n.receiveAttestation(ctx, att)
+}
+
+// receiveAttestation verifies the signature of the attestation against the state of its target, and saves it
+// to the pools used by fork choice and block production. Attestations for unknown blocks are dropped.
+func (n *Node) receiveAttestation(ctx context.Context, att *zondpb.Attestation) error {
+	if !n.chain.HasBlock(ctx, bytesutil.ToBytes32(att.Data.BeaconBlockRoot)) ||
+		!n.chain.HasBlock(ctx, bytesutil.ToBytes32(att.Data.Target.Root)) {
+		return nil
+	}
+	st, err := n.chain.AttestationTargetState(ctx, att.Data.Target)
+	if err != nil {
+		return errors.Wrap(err, "could not get attestation target state")
+	}
+	set, err := blocks.AttestationSignatureBatch(ctx, st, []*zondpb.Attestation{att})
+	if err != nil {
+		return err
+	}
+	valid, err := set.Verify()
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return errors.New("invalid attestation signature")
+	}
+	if err := n.attPool.SaveForkchoiceAttestation(att); err != nil {
+		return err
+	}
+	return n.attPool.SaveUnaggregatedAttestation(att)
+}
+
+// stop stops serving the node. The blockchain service stops with the context of the simulation.
+func (n *Node) stop() {
+	if err := n.conn.Close(); err != nil {
+		log.WithError(err).Debug("Could not close gRPC connection")
+	}
+	n.grpcServer.Stop()
+}
+
+type sszMarshaler interface {
+	MarshalSSZ() ([]byte, error)
+}
+
+func marshalSSZ(v interface{}) ([]byte, error) {
+	m, ok := v.(sszMarshaler)
+	if !ok {
+		return nil, errors.Errorf("%T is not ssz encodable", v)
+	}
+	return m.MarshalSSZ()
+}
+
+// unmarshalBlock decodes a signed Capella block, the only fork the simulation runs.
+func unmarshalBlock(data []byte) (interfaces.ReadOnlySignedBeaconBlock, error) {
+	pb := &zondpb.SignedBeaconBlockCapella{}
+	if err := pb.UnmarshalSSZ(data); err != nil {
+		return nil, errors.Wrap(err, "could not decode block")
+	}
+	return consensusblocks.NewSignedBeaconBlock(pb)
+}
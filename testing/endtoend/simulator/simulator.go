@@ -0,0 +1,452 @@
+// Package simulator runs networks of beacon nodes and validators in a single process, so that fork and finality
+// scenarios can be exercised with the e2e evaluators in an ordinary go test, without the beacon, validator and
+// execution client binaries the e2e tests launch.
+//
+// Each node runs the blockchain service of a real beacon node on its own database, with the mock engine client
+// as its execution layer. Nodes gossip blocks and attestations over an in-memory libp2p network, and the
+// validators attached to them propose and attest according to the duties computed from their head state. Time
+// is a controllable clock the simulator advances one event at a time, so an epoch takes as long as it takes to
+// process rather than SlotsPerEpoch * SecondsPerSlot.
+package simulator
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/pkg/errors"
+	mockExecution "github.com/theQRL/qrysm/v4/beacon-chain/execution/testing"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	fieldparams "github.com/theQRL/qrysm/v4/config/fieldparams"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/crypto/dilithium"
+	"github.com/theQRL/qrysm/v4/crypto/hash"
+	"github.com/theQRL/qrysm/v4/runtime/interop"
+	"github.com/theQRL/qrysm/v4/runtime/version"
+	e2etypes "github.com/theQRL/qrysm/v4/testing/endtoend/types"
+	"github.com/theQRL/qrysm/v4/time/slots"
+	"google.golang.org/grpc"
+)
+
+const (
+	defaultNodeCount      = 4
+	defaultValidatorCount = 64
+
+	// defaultDeliveryTimeout bounds the real time a gossip message takes to reach and be processed by the nodes.
+	defaultDeliveryTimeout = 30 * time.Second
+)
+
+type config struct {
+	nodes           int
+	validators      uint64
+	beaconConfig    *params.BeaconChainConfig
+	deliveryTimeout time.Duration
+}
+
+// Option configures a simulation.
+type Option func(*config)
+
+// WithNodes sets the number of beacon nodes of the simulation.
+func WithNodes(n int) Option {
+	return func(c *config) {
+		c.nodes = n
+	}
+}
+
+// WithValidators sets the number of genesis validators of the simulation. Validator i is attached to node
+// i modulo the number of nodes.
+func WithValidators(n uint64) Option {
+	return func(c *config) {
+		c.validators = n
+	}
+}
+
+// WithBeaconConfig sets the beacon chain config of the simulation. The simulation starts at Capella, so the
+// fork epochs up to Capella are set to 0.
+func WithBeaconConfig(cfg *params.BeaconChainConfig) Option {
+	return func(c *config) {
+		c.beaconConfig = cfg
+	}
+}
+
+// WithDeliveryTimeout sets the real time a gossip message may take to reach and be processed by the nodes.
+func WithDeliveryTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.deliveryTimeout = d
+	}
+}
+
+// action is an action of the simulation scheduled at a time of the clock.
+type action struct {
+	at  time.Time
+	seq uint64
+	run func() error
+}
+
+// Simulator runs a network of beacon nodes and validators in a single process.
+type Simulator struct {
+	t       testing.TB
+	ctx     context.Context
+	cancel  context.CancelFunc
+	cfg     *config
+	clock   *Clock
+	net     mocknet.Mocknet
+	nodes   []*Node
+	keys    []dilithium.DilithiumKey
+	genesis state.BeaconState
+	evalCtx *e2etypes.EvaluationContext
+
+	events []*action
+	seq    uint64
+	slot   primitives.Slot
+
+	lock    sync.Mutex
+	seen    []map[[32]byte]bool
+	groups  []int
+	offline map[primitives.ValidatorIndex]bool
+	delays  []time.Duration
+}
+
+// New starts a simulation at genesis. It overrides the beacon chain config until the end of the test, so
+// simulations must not run in parallel.
+func New(t testing.TB, opts ...Option) (*Simulator, error) {
+	cfg := &config{
+		nodes:           defaultNodeCount,
+		validators:      defaultValidatorCount,
+		beaconConfig:    params.E2EMainnetTestConfig(),
+		deliveryTimeout: defaultDeliveryTimeout,
+	}
+	for _, o := range opts {
+		o(cfg)
+	}
+	if cfg.nodes < 1 {
+		return nil, errors.New("a simulation needs at least one node")
+	}
+	if cfg.validators < uint64(cfg.nodes) {
+		return nil, errors.New("a simulation needs at least one validator per node")
+	}
+	if uint64(cfg.beaconConfig.EpochsPerHistoricalVector) != fieldparams.RandaoMixesLength ||
+		uint64(cfg.beaconConfig.SlotsPerHistoricalRoot) != fieldparams.BlockRootsLength {
+		return nil, errors.Errorf("beacon config does not match the %s preset the simulation is built with", fieldparams.Preset)
+	}
+	beaconConfig := e2etypes.StartAt(version.Capella, cfg.beaconConfig)
+	beaconConfig.MinGenesisActiveValidatorCount = cfg.validators
+	params.SetupTestConfigCleanup(t)
+	params.OverrideBeaconConfig(beaconConfig)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Simulator{
+		t:       t,
+		ctx:     ctx,
+		cancel:  cancel,
+		cfg:     cfg,
+		net:     mocknet.New(),
+		evalCtx: e2etypes.NewEvaluationContext(nil),
+		seen:    make([]map[[32]byte]bool, cfg.nodes),
+		groups:  make([]int, cfg.nodes),
+		offline: make(map[primitives.ValidatorIndex]bool),
+		delays:  make([]time.Duration, cfg.nodes),
+	}
+	t.Cleanup(s.stop)
+
+	genesisTime := uint64(time.Now().Unix())
+	s.clock = NewClock(time.Unix(int64(genesisTime), 0))
+
+	keys, _, err := interop.DeterministicallyGenerateKeys(0, cfg.validators)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not generate validator keys")
+	}
+	s.keys = keys
+	gb := interop.GethTestnetGenesis(genesisTime, params.BeaconConfig()).ToBlock()
+	s.genesis, err = interop.NewPreminedGenesis(ctx, genesisTime, cfg.validators, 0, version.Capella, gb)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not generate genesis state")
+	}
+
+	engine := &mockExecution.EngineClient{}
+	for i := 0; i < cfg.nodes; i++ {
+		h, err := s.net.GenPeer()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create libp2p host")
+		}
+		n, err := newNode(ctx, s, i, h, s.genesis, engine)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not start node %d", i)
+		}
+		s.nodes = append(s.nodes, n)
+		s.seen[i] = make(map[[32]byte]bool)
+	}
+	if err := s.net.LinkAll(); err != nil {
+		return nil, err
+	}
+	if err := s.net.ConnectAllButSelf(); err != nil {
+		return nil, err
+	}
+	for _, n := range s.nodes {
+		ps, err := pubsub.NewFloodSub(ctx, n.host)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not start pubsub")
+		}
+		if err := n.join(ctx, ps); err != nil {
+			return nil, errors.Wrap(err, "could not join gossip topics")
+		}
+	}
+	if err := s.waitForMesh(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Nodes returns the beacon nodes of the simulation.
+func (s *Simulator) Nodes() []*Node {
+	return s.nodes
+}
+
+// Clock returns the clock of the simulation.
+func (s *Simulator) Clock() *Clock {
+	return s.clock
+}
+
+// Slot returns the last slot the simulation ran.
+func (s *Simulator) Slot() primitives.Slot {
+	return s.slot
+}
+
+// Conns returns gRPC connections to the nodes with the given indices, or to all nodes when none is given.
+func (s *Simulator) Conns(nodes ...int) []*grpc.ClientConn {
+	if len(nodes) == 0 {
+		for i := range s.nodes {
+			nodes = append(nodes, i)
+		}
+	}
+	conns := make([]*grpc.ClientConn, len(nodes))
+	for i, n := range nodes {
+		conns[i] = s.nodes[n].conn
+	}
+	return conns
+}
+
+// Evaluate runs the evaluators against the given connections, or against all nodes when none is given.
+func (s *Simulator) Evaluate(evaluators []e2etypes.Evaluator, conns ...*grpc.ClientConn) error {
+	if len(conns) == 0 {
+		conns = s.Conns()
+	}
+	for _, e := range evaluators {
+		if err := e.Evaluation(s.evalCtx, conns...); err != nil {
+			return errors.Wrapf(err, "evaluator %s failed", e.Name)
+		}
+	}
+	return nil
+}
+
+// RunSlots runs the next n slots of the simulation.
+func (s *Simulator) RunSlots(n primitives.Slot) error {
+	for i := primitives.Slot(0); i < n; i++ {
+		if err := s.runSlot(s.slot + 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunEpochs runs the simulation to the end of the epoch. At the end of each epoch, like the e2e tests do, it
+// runs the evaluators whose policy applies to the epoch against all nodes.
+func (s *Simulator) RunEpochs(end primitives.Epoch, evaluators ...e2etypes.Evaluator) error {
+	for {
+		next := s.slot + 1
+		if slots.ToEpoch(next) > end {
+			return nil
+		}
+		if err := s.runSlot(next); err != nil {
+			return err
+		}
+		if !slots.IsEpochEnd(next) {
+			continue
+		}
+		epoch := slots.ToEpoch(next)
+		var due []e2etypes.Evaluator
+		for _, e := range evaluators {
+			if e.Policy(epoch) {
+				due = append(due, e)
+			}
+		}
+		if err := s.Evaluate(due); err != nil {
+			return errors.Wrapf(err, "epoch %d", epoch)
+		}
+	}
+}
+
+// runSlot runs the duties of the slot: fork choice updates and block proposals at the start of the slot, and
+// attestations a third of the way into it. Gossip delayed by a fault runs when its time comes, possibly in a
+// later slot.
+func (s *Simulator) runSlot(slot primitives.Slot) error {
+	interval := time.Duration(params.BeaconConfig().SecondsPerSlot/params.BeaconConfig().IntervalsPerSlot) * time.Second
+	s.schedule(s.clock.SlotTime(slot, 0), func() error {
+		return s.startSlot(slot)
+	})
+	s.schedule(s.clock.SlotTime(slot, interval), func() error {
+		return s.attestSlot(slot)
+	})
+	if err := s.runUntil(s.clock.SlotTime(slot+1, -time.Nanosecond)); err != nil {
+		return errors.Wrapf(err, "slot %d", slot)
+	}
+	s.slot = slot
+	return nil
+}
+
+func (s *Simulator) startSlot(slot primitives.Slot) error {
+	for _, n := range s.nodes {
+		if err := n.chain.NewSlot(s.ctx, slot); err != nil {
+			return errors.Wrapf(err, "node %d could not process new slot", n.index)
+		}
+		n.chain.UpdateHead(s.ctx, slot)
+	}
+	for _, n := range s.nodes {
+		proposer, err := n.proposer(s.ctx, slot)
+		if err != nil {
+			return errors.Wrapf(err, "node %d could not compute proposer", n.index)
+		}
+		if !s.attachedTo(proposer, n.index) || s.isOffline(proposer) {
+			continue
+		}
+		blk, err := n.buildBlock(s.ctx, slot, proposer)
+		if err != nil {
+			return errors.Wrapf(err, "node %d could not build block", n.index)
+		}
+		if err := n.importBlock(s.ctx, blk); err != nil {
+			return errors.Wrapf(err, "node %d could not import its block", n.index)
+		}
+		pb, err := blk.Proto()
+		if err != nil {
+			return err
+		}
+		data, err := marshalSSZ(pb)
+		if err != nil {
+			return err
+		}
+		s.gossip(n, n.blocks.Publish, data)
+	}
+	return nil
+}
+
+func (s *Simulator) attestSlot(slot primitives.Slot) error {
+	for _, n := range s.nodes {
+		atts, err := n.attest(s.ctx, slot)
+		if err != nil {
+			return errors.Wrapf(err, "node %d could not attest", n.index)
+		}
+		for _, att := range atts {
+			if err := n.receiveAttestation(s.ctx, att); err != nil {
+				return errors.Wrapf(err, "node %d could not save its attestation", n.index)
+			}
+			data, err := att.MarshalSSZ()
+			if err != nil {
+				return err
+			}
+			s.gossip(n, n.atts.Publish, data)
+		}
+	}
+	return nil
+}
+
+// gossip publishes the message of the node after the delay of the node, and waits for the nodes it can reach
+// to process it.
+func (s *Simulator) gossip(n *Node, publish func(context.Context, []byte, ...pubsub.PubOpt) error, data []byte) {
+	s.lock.Lock()
+	delay := s.delays[n.index]
+	s.lock.Unlock()
+	s.schedule(s.clock.Now().Add(delay), func() error {
+		id := hash.Hash(data)
+		recipients := s.reachable(n.index)
+		if err := publish(s.ctx, data); err != nil {
+			return errors.Wrapf(err, "node %d could not publish", n.index)
+		}
+		return s.waitDelivered(id, recipients)
+	})
+}
+
+func (s *Simulator) schedule(at time.Time, run func() error) {
+	s.seq++
+	s.events = append(s.events, &action{at: at, seq: s.seq, run: run})
+}
+
+// runUntil runs the scheduled events up to the time in order, moving the clock to the time of each.
+func (s *Simulator) runUntil(t time.Time) error {
+	for {
+		sort.Slice(s.events, func(i, j int) bool {
+			if s.events[i].at.Equal(s.events[j].at) {
+				return s.events[i].seq < s.events[j].seq
+			}
+			return s.events[i].at.Before(s.events[j].at)
+		})
+		if len(s.events) == 0 || s.events[0].at.After(t) {
+			s.clock.Set(t)
+			return nil
+		}
+		e := s.events[0]
+		s.events = s.events[1:]
+		s.clock.Set(e.at)
+		if err := e.run(); err != nil {
+			return err
+		}
+	}
+}
+
+// delivered records that the node processed the gossip message.
+func (s *Simulator) delivered(node int, data []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.seen[node][hash.Hash(data)] = true
+}
+
+func (s *Simulator) waitDelivered(id [32]byte, nodes []int) error {
+	deadline := time.Now().Add(s.cfg.deliveryTimeout)
+	for {
+		s.lock.Lock()
+		pending := 0
+		for _, n := range nodes {
+			if !s.seen[n][id] {
+				pending++
+			}
+		}
+		if pending == 0 {
+			for _, n := range nodes {
+				delete(s.seen[n], id)
+			}
+		}
+		s.lock.Unlock()
+		if pending == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("gossip message %#x not processed by %d nodes in time", id[:4], pending)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// attachedTo returns whether the validator is attached to the node.
+func (s *Simulator) attachedTo(v primitives.ValidatorIndex, node int) bool {
+	return int(uint64(v)%uint64(len(s.nodes))) == node
+}
+
+func (s *Simulator) isOffline(v primitives.ValidatorIndex) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.offline[v]
+}
+
+func (s *Simulator) stop() {
+	s.cancel()
+	for _, n := range s.nodes {
+		n.stop()
+	}
+	if err := s.net.Close(); err != nil {
+		log.WithError(err).Debug("Could not close network")
+	}
+}
@@ -0,0 +1,61 @@
+package simulator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/endtoend/evaluators"
+	e2etypes "github.com/theQRL/qrysm/v4/testing/endtoend/types"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func chainHead(t *testing.T, n *Node) *zondpb.ChainHead {
+	head, err := zondpb.NewBeaconChainClient(n.Conn()).GetChainHead(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+	return head
+}
+
+func TestSimulator_Finalizes(t *testing.T) {
+	s, err := New(t)
+	require.NoError(t, err)
+	require.NoError(t, s.RunEpochs(4, evaluators.FinalizationOccurs(3), evaluators.AllNodesHaveSameHead))
+	for _, n := range s.Nodes() {
+		require.Equal(t, primitives.Epoch(2), chainHead(t, n).FinalizedEpoch)
+	}
+}
+
+func TestSimulator_PartitionAndHeal(t *testing.T) {
+	s, err := New(t)
+	require.NoError(t, err)
+	require.NoError(t, s.RunEpochs(1))
+	require.NoError(t, s.Partition([]int{0, 1}, []int{2, 3}))
+	require.NoError(t, s.RunEpochs(2))
+	// Each half holds half of the stake, so neither can justify its own fork.
+	require.NotEqual(t, chainHead(t, s.Nodes()[0]).HeadBlockRoot, chainHead(t, s.Nodes()[2]).HeadBlockRoot)
+	require.NoError(t, s.Evaluate([]e2etypes.Evaluator{evaluators.AllNodesHaveSameHead}, s.Conns(0, 1)...))
+	require.NoError(t, s.Evaluate([]e2etypes.Evaluator{evaluators.AllNodesHaveSameHead}, s.Conns(2, 3)...))
+
+	require.NoError(t, s.Heal())
+	require.NoError(t, s.RunEpochs(6, evaluators.AllNodesHaveSameHead))
+	require.NoError(t, s.Evaluate([]e2etypes.Evaluator{evaluators.FinalizationOccurs(0)}))
+}
+
+func TestSimulator_OfflineValidatorsStopFinality(t *testing.T) {
+	s, err := New(t, WithNodes(2), WithValidators(32))
+	require.NoError(t, err)
+	// Half of the validators are attached to node 1, leaving less than two thirds of the stake online.
+	for v := primitives.ValidatorIndex(1); v < 32; v += 2 {
+		s.SetOffline(v)
+	}
+	require.NoError(t, s.RunEpochs(4, evaluators.AllNodesHaveSameHead))
+	require.Equal(t, primitives.Epoch(0), chainHead(t, s.Nodes()[0]).FinalizedEpoch)
+
+	for v := primitives.ValidatorIndex(1); v < 32; v += 2 {
+		s.SetOnline(v)
+	}
+	require.NoError(t, s.RunEpochs(8, evaluators.AllNodesHaveSameHead))
+	require.Equal(t, true, chainHead(t, s.Nodes()[0]).FinalizedEpoch > 0)
+}
@@ -0,0 +1,263 @@
+package simulator
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-bitfield"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/blocks"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/helpers"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/signing"
+	coreTime "github.com/theQRL/qrysm/v4/beacon-chain/core/time"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/transition"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	fieldparams "github.com/theQRL/qrysm/v4/config/fieldparams"
+	"github.com/theQRL/qrysm/v4/config/params"
+	consensusblocks "github.com/theQRL/qrysm/v4/consensus-types/blocks"
+	"github.com/theQRL/qrysm/v4/consensus-types/interfaces"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/crypto/hash"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	enginev1 "github.com/theQRL/qrysm/v4/proto/engine/v1"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/time/slots"
+)
+
+// gasLimit is the gas limit of the execution payloads built by the simulation.
+const gasLimit = 30_000_000
+
+// headStateAt returns the head root of the node and a copy of its head state advanced to the slot.
+func (n *Node) headStateAt(ctx context.Context, slot primitives.Slot) (state.BeaconState, [32]byte, error) {
+	headRoot, err := n.chain.HeadRoot(ctx)
+	if err != nil {
+		return nil, [32]byte{}, err
+	}
+	st, err := n.chain.HeadState(ctx)
+	if err != nil {
+		return nil, [32]byte{}, err
+	}
+	if st.Slot() < slot {
+		st, err = transition.ProcessSlots(ctx, st, slot)
+		if err != nil {
+			return nil, [32]byte{}, errors.Wrapf(err, "could not process slots up to %d", slot)
+		}
+	}
+	return st, bytesutil.ToBytes32(headRoot), nil
+}
+
+// proposer returns the proposer of the slot on the chain of the node.
+func (n *Node) proposer(ctx context.Context, slot primitives.Slot) (primitives.ValidatorIndex, error) {
+	st, _, err := n.headStateAt(ctx, slot)
+	if err != nil {
+		return 0, err
+	}
+	return helpers.BeaconProposerIndex(ctx, st)
+}
+
+// buildBlock builds and signs the block of the proposer for the slot on top of the head of the node. It packs
+// the attestations of the pool of the node and an execution payload extending the one of the head.
+func (n *Node) buildBlock(ctx context.Context, slot primitives.Slot, proposer primitives.ValidatorIndex) (interfaces.ReadOnlySignedBeaconBlock, error) {
+	headState, err := n.chain.HeadState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	st, headRoot, err := n.headStateAt(ctx, slot)
+	if err != nil {
+		return nil, err
+	}
+	key := n.sim.keys[proposer]
+	epoch := slots.ToEpoch(slot)
+	sszEpoch := primitives.SSZUint64(epoch)
+	randaoReveal, err := signing.ComputeDomainAndSign(st, epoch, &sszEpoch, params.BeaconConfig().DomainRandao, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not sign randao reveal")
+	}
+	atts, err := n.packAttestations(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := executionPayload(st, slot)
+	if err != nil {
+		return nil, err
+	}
+	blk := &zondpb.BeaconBlockCapella{
+		Slot:          slot,
+		ProposerIndex: proposer,
+		ParentRoot:    headRoot[:],
+		StateRoot:     make([]byte, fieldparams.RootLength),
+		Body: &zondpb.BeaconBlockBodyCapella{
+			RandaoReveal:      randaoReveal,
+			Eth1Data:          st.Eth1Data(),
+			Graffiti:          make([]byte, fieldparams.RootLength),
+			ProposerSlashings: []*zondpb.ProposerSlashing{},
+			AttesterSlashings: []*zondpb.AttesterSlashing{},
+			Attestations:      atts,
+			Deposits:          []*zondpb.Deposit{},
+			VoluntaryExits:    []*zondpb.SignedVoluntaryExit{},
+			SyncAggregate: &zondpb.SyncAggregate{
+				SyncCommitteeBits:      bitfield.NewBitvector512(),
+				SyncCommitteeSignature: []byte{},
+			},
+			ExecutionPayload:            payload,
+			DilithiumToExecutionChanges: []*zondpb.SignedDilithiumToExecutionChange{},
+		},
+	}
+	wsb, err := consensusblocks.NewSignedBeaconBlock(&zondpb.SignedBeaconBlockCapella{
+		Block:     blk,
+		Signature: make([]byte, dilithium2.CryptoBytes),
+	})
+	if err != nil {
+		return nil, err
+	}
+	stateRoot, err := transition.CalculateStateRoot(ctx, headState, wsb)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not calculate state root")
+	}
+	blk.StateRoot = stateRoot[:]
+	sig, err := signing.ComputeDomainAndSign(st, epoch, blk, params.BeaconConfig().DomainBeaconProposer, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not sign block")
+	}
+	return consensusblocks.NewSignedBeaconBlock(&zondpb.SignedBeaconBlockCapella{Block: blk, Signature: sig})
+}
+
+// executionPayload returns a payload extending the latest one of the state, standing in for the execution client.
+func executionPayload(st state.BeaconState, slot primitives.Slot) (*enginev1.ExecutionPayloadCapella, error) {
+	header, err := st.LatestExecutionPayloadHeader()
+	if err != nil {
+		return nil, err
+	}
+	random, err := helpers.RandaoMix(st, coreTime.CurrentEpoch(st))
+	if err != nil {
+		return nil, err
+	}
+	t, err := slots.ToTime(st.GenesisTime(), slot)
+	if err != nil {
+		return nil, err
+	}
+	withdrawals, err := st.ExpectedWithdrawals()
+	if err != nil {
+		return nil, err
+	}
+	blockHash := hash.Hash(append(bytesutil.SafeCopyBytes(header.BlockHash()), bytesutil.Bytes8(uint64(slot))...))
+	return &enginev1.ExecutionPayloadCapella{
+		ParentHash:    header.BlockHash(),
+		FeeRecipient:  make([]byte, fieldparams.FeeRecipientLength),
+		StateRoot:     make([]byte, fieldparams.RootLength),
+		ReceiptsRoot:  make([]byte, fieldparams.RootLength),
+		LogsBloom:     make([]byte, fieldparams.LogsBloomLength),
+		PrevRandao:    random,
+		BlockNumber:   header.BlockNumber() + 1,
+		GasLimit:      gasLimit,
+		Timestamp:     uint64(t.Unix()),
+		ExtraData:     []byte{},
+		BaseFeePerGas: make([]byte, fieldparams.RootLength),
+		BlockHash:     blockHash[:],
+		Transactions:  [][]byte{},
+		Withdrawals:   withdrawals,
+	}, nil
+}
+
+// packAttestations aggregates the attestations of the pool of the node that can be included in a block on top of
+// the state. The signatures of attestations with the same data are concatenated, as dilithium signatures do not
+// aggregate.
+func (n *Node) packAttestations(ctx context.Context, st state.BeaconState) ([]*zondpb.Attestation, error) {
+	pooled, err := n.attPool.UnaggregatedAttestations()
+	if err != nil {
+		return nil, err
+	}
+	var packed []*zondpb.Attestation
+	byData := make(map[[32]byte]*zondpb.Attestation)
+	for _, att := range pooled {
+		if att.Data.Slot+params.BeaconConfig().SlotsPerEpoch < st.Slot() {
+			if err := n.attPool.DeleteUnaggregatedAttestation(att); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := blocks.VerifyAttestationNoVerifySignature(ctx, st, att); err != nil {
+			continue
+		}
+		root, err := att.Data.HashTreeRoot()
+		if err != nil {
+			return nil, err
+		}
+		agg, ok := byData[root]
+		if !ok {
+			if uint64(len(packed)) == params.BeaconConfig().MaxAttestations {
+				continue
+			}
+			agg = zondpb.CopyAttestation(att)
+			byData[root] = agg
+			packed = append(packed, agg)
+			continue
+		}
+		overlaps, err := agg.AggregationBits.Overlaps(att.AggregationBits)
+		if err != nil || overlaps {
+			continue
+		}
+		if agg.AggregationBits, err = agg.AggregationBits.Or(att.AggregationBits); err != nil {
+			return nil, err
+		}
+		agg.Signature = append(agg.Signature, att.Signature...)
+		agg.SignatureValidatorIndex = append(agg.SignatureValidatorIndex, att.SignatureValidatorIndex...)
+	}
+	return packed, nil
+}
+
+// attest signs the attestations of the online validators of the node that are in a committee of the slot,
+// voting for the head of the node.
+func (n *Node) attest(ctx context.Context, slot primitives.Slot) ([]*zondpb.Attestation, error) {
+	st, headRoot, err := n.headStateAt(ctx, slot)
+	if err != nil {
+		return nil, err
+	}
+	epoch := slots.ToEpoch(slot)
+	targetRoot := headRoot[:]
+	epochStart, err := slots.EpochStart(epoch)
+	if err != nil {
+		return nil, err
+	}
+	if epochStart < st.Slot() {
+		if targetRoot, err = helpers.BlockRootAtSlot(st, epochStart); err != nil {
+			return nil, err
+		}
+	}
+	activeCount, err := helpers.ActiveValidatorCount(ctx, st, epoch)
+	if err != nil {
+		return nil, err
+	}
+	var atts []*zondpb.Attestation
+	for i := uint64(0); i < helpers.SlotCommitteeCount(activeCount); i++ {
+		committee, err := helpers.BeaconCommitteeFromState(ctx, st, slot, primitives.CommitteeIndex(i))
+		if err != nil {
+			return nil, err
+		}
+		data := &zondpb.AttestationData{
+			Slot:            slot,
+			CommitteeIndex:  primitives.CommitteeIndex(i),
+			BeaconBlockRoot: headRoot[:],
+			Source:          st.CurrentJustifiedCheckpoint(),
+			Target:          &zondpb.Checkpoint{Epoch: epoch, Root: bytesutil.SafeCopyBytes(targetRoot)},
+		}
+		for pos, v := range committee {
+			if !n.sim.attachedTo(v, n.index) || n.sim.isOffline(v) {
+				continue
+			}
+			sig, err := signing.ComputeDomainAndSign(st, epoch, data, params.BeaconConfig().DomainBeaconAttester, n.sim.keys[v])
+			if err != nil {
+				return nil, errors.Wrap(err, "could not sign attestation")
+			}
+			bits := bitfield.NewBitlist(uint64(len(committee)))
+			bits.SetBitAt(uint64(pos), true)
+			atts = append(atts, &zondpb.Attestation{
+				Data:                    data,
+				AggregationBits:         bits,
+				Signature:               sig,
+				SignatureValidatorIndex: []uint64{uint64(v)},
+			})
+		}
+	}
+	return atts, nil
+}
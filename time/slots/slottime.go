@@ -130,6 +130,11 @@ func SinceEpochStarts(slot primitives.Slot) primitives.Slot {
 
 // VerifyTime validates the input slot is not from the future.
 func VerifyTime(genesisTime uint64, slot primitives.Slot, timeTolerance time.Duration) error {
+	return VerifyTimeAt(genesisTime, slot, timeTolerance, prysmTime.Now())
+}
+
+// VerifyTimeAt validates the input slot is not from the future at the given current time.
+func VerifyTimeAt(genesisTime uint64, slot primitives.Slot, timeTolerance time.Duration, currentTime time.Time) error {
 	slotTime, err := ToTime(genesisTime, slot)
 	if err != nil {
 		return err
@@ -137,11 +142,10 @@ func VerifyTime(genesisTime uint64, slot primitives.Slot, timeTolerance time.Dur
 
 	// Defensive check to ensure unreasonable slots are rejected
 	// straight away.
-	if err := ValidateClock(slot, genesisTime); err != nil {
+	if err := ValidateClockAt(slot, genesisTime, currentTime); err != nil {
 		return err
 	}
 
-	currentTime := prysmTime.Now()
 	diff := slotTime.Sub(currentTime)
 
 	if diff > timeTolerance {
@@ -190,7 +194,13 @@ func Duration(start, end time.Time) primitives.Slot {
 // clock to ensure slots that are unreasonable are returned with
 // an error.
 func ValidateClock(slot primitives.Slot, genesisTimeSec uint64) error {
-	maxPossibleSlot := CurrentSlot(genesisTimeSec).Add(MaxSlotBuffer)
+	return ValidateClockAt(slot, genesisTimeSec, prysmTime.Now())
+}
+
+// ValidateClockAt validates a provided slot against the given
+// current time, like ValidateClock does against the local clock.
+func ValidateClockAt(slot primitives.Slot, genesisTimeSec uint64, currentTime time.Time) error {
+	maxPossibleSlot := Duration(time.Unix(int64(genesisTimeSec), 0), currentTime).Add(MaxSlotBuffer)
 	// Defensive check to ensure that we only process slots up to a hard limit
 	// from our local clock.
 	if slot > maxPossibleSlot {
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/theQRL/qrysm/v4/beacon-chain/db/filters"
+	"github.com/theQRL/qrysm/v4/beacon-chain/db/kv"
+	"github.com/theQRL/qrysm/v4/consensus-types/interfaces"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/runtime/version"
+	"github.com/theQRL/qrysm/v4/time/slots"
+)
+
+// blockSummary is the part of a block shown when browsing the chain.
+type blockSummary struct {
+	Root           string
+	Slot           primitives.Slot
+	Epoch          primitives.Epoch
+	Fork           string
+	ProposerIndex  primitives.ValidatorIndex
+	ParentRoot     string
+	StateRoot      string
+	Attestations   int
+	Deposits       int
+	VoluntaryExits int
+	Blinded        bool
+	PayloadNumber  uint64
+	PayloadHash    string
+	Finalized      bool
+	HasState       bool
+	HasSummary     bool
+}
+
+func (b *blockSummary) String() string {
+	s := fmt.Sprintf("root = %s, slot = %d, epoch = %d, fork = %s, proposer = %d, parent = %s, state_root = %s, attestations = %d",
+		b.Root, b.Slot, b.Epoch, b.Fork, b.ProposerIndex, b.ParentRoot, b.StateRoot, b.Attestations)
+	if b.PayloadHash != "" {
+		s += fmt.Sprintf(", payload = %d %s", b.PayloadNumber, b.PayloadHash)
+	}
+	return s
+}
+
+// summarizeBlock summarizes the block with the given root, and looks up what the database knows about it.
+func summarizeBlock(ctx context.Context, db *kv.Store, root [32]byte, blk interfaces.ReadOnlySignedBeaconBlock) (*blockSummary, error) {
+	b := blk.Block()
+	parentRoot, stateRoot := b.ParentRoot(), b.StateRoot()
+	s := &blockSummary{
+		Root:           fmt.Sprintf("%#x", root),
+		Slot:           b.Slot(),
+		Epoch:          slots.ToEpoch(b.Slot()),
+		Fork:           version.String(blk.Version()),
+		ProposerIndex:  b.ProposerIndex(),
+		ParentRoot:     fmt.Sprintf("%#x", parentRoot),
+		StateRoot:      fmt.Sprintf("%#x", stateRoot),
+		Attestations:   len(b.Body().Attestations()),
+		Deposits:       len(b.Body().Deposits()),
+		VoluntaryExits: len(b.Body().VoluntaryExits()),
+		Blinded:        blk.IsBlinded(),
+		Finalized:      db.IsFinalizedBlock(ctx, root),
+		HasState:       db.HasState(ctx, root),
+		HasSummary:     db.HasStateSummary(ctx, root),
+	}
+	if blk.Version() >= version.Bellatrix {
+		payload, err := b.Body().Execution()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not get execution payload")
+		}
+		s.PayloadNumber = payload.BlockNumber()
+		s.PayloadHash = fmt.Sprintf("%#x", payload.BlockHash())
+	}
+	return s, nil
+}
+
+// blocksAtSlot summarizes the blocks the database has at the slot.
+func blocksAtSlot(ctx context.Context, db *kv.Store, slot primitives.Slot) ([]*blockSummary, error) {
+	blks, roots, err := db.Blocks(ctx, filters.NewFilter().SetStartSlot(slot).SetEndSlot(slot))
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]*blockSummary, len(blks))
+	for i, blk := range blks {
+		if summaries[i], err = summarizeBlock(ctx, db, roots[i], blk); err != nil {
+			return nil, err
+		}
+	}
+	return summaries, nil
+}
+
+// blockByRoot summarizes the block with the given root, and the blocks the database has on top of it.
+func blockByRoot(ctx context.Context, db *kv.Store, root [32]byte) (*blockSummary, []*blockSummary, error) {
+	blk, err := db.Block(ctx, root)
+	if err != nil {
+		return nil, nil, err
+	}
+	if blk == nil || blk.IsNil() {
+		return nil, nil, nil
+	}
+	summary, err := summarizeBlock(ctx, db, root, blk)
+	if err != nil {
+		return nil, nil, err
+	}
+	blks, roots, err := db.Blocks(ctx, filters.NewFilter().SetParentRoot(root[:]))
+	if err != nil {
+		return nil, nil, err
+	}
+	children := make([]*blockSummary, len(blks))
+	for i, child := range blks {
+		if children[i], err = summarizeBlock(ctx, db, roots[i], child); err != nil {
+			return nil, nil, err
+		}
+	}
+	return summary, children, nil
+}
+
+// chainInfo is the head, genesis and checkpoints of the chain in the database.
+type chainInfo struct {
+	Head            *blockSummary
+	GenesisRoot     string
+	OriginRoot      string
+	JustifiedEpoch  primitives.Epoch
+	JustifiedRoot   string
+	FinalizedEpoch  primitives.Epoch
+	FinalizedRoot   string
+	LastArchived    primitives.Slot
+	ValidatedEpoch  primitives.Epoch
+	ValidatedRoot   string
+	HasValidatedCpt bool
+}
+
+func readChainInfo(ctx context.Context, db *kv.Store) (*chainInfo, error) {
+	info := &chainInfo{}
+	head, err := db.HeadBlock(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get head block")
+	}
+	if head != nil && !head.IsNil() {
+		root, err := head.Block().HashTreeRoot()
+		if err != nil {
+			return nil, err
+		}
+		if info.Head, err = summarizeBlock(ctx, db, root, head); err != nil {
+			return nil, err
+		}
+	}
+	genesisRoot, err := db.GenesisBlockRoot(ctx)
+	switch {
+	case err == nil:
+		info.GenesisRoot = fmt.Sprintf("%#x", genesisRoot)
+	case !errors.Is(err, kv.ErrNotFoundGenesisBlockRoot):
+		return nil, errors.Wrap(err, "could not get genesis block root")
+	}
+	originRoot, err := db.OriginCheckpointBlockRoot(ctx)
+	switch {
+	case err == nil:
+		info.OriginRoot = fmt.Sprintf("%#x", originRoot)
+	case !errors.Is(err, kv.ErrNotFoundOriginBlockRoot):
+		return nil, errors.Wrap(err, "could not get origin checkpoint block root")
+	}
+	justified, err := db.JustifiedCheckpoint(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get justified checkpoint")
+	}
+	info.JustifiedEpoch, info.JustifiedRoot = justified.Epoch, fmt.Sprintf("%#x", justified.Root)
+	finalized, err := db.FinalizedCheckpoint(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get finalized checkpoint")
+	}
+	info.FinalizedEpoch, info.FinalizedRoot = finalized.Epoch, fmt.Sprintf("%#x", finalized.Root)
+	if info.LastArchived, err = db.LastArchivedSlot(ctx); err != nil {
+		return nil, errors.Wrap(err, "could not get last archived slot")
+	}
+	validated, err := db.LastValidatedCheckpoint(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get last validated checkpoint")
+	}
+	if validated != nil {
+		info.HasValidatedCpt = true
+		info.ValidatedEpoch, info.ValidatedRoot = validated.Epoch, fmt.Sprintf("%#x", validated.Root)
+	}
+	return info, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	bolt "go.etcd.io/bbolt"
+)
+
+// copyDatabase copies the database file into a new directory under copyDir, keeping its file name so that the
+// database packages can open the copy, and returns the path of the copy and a function removing it. Every
+// command works on such a copy, so that neither the database nor the node using it can be affected.
+//
+// If no other process holds the database, the copy is a consistent snapshot taken in a read-only transaction.
+// Otherwise the file is copied as is, and the copy may be inconsistent if the node writes to it meanwhile.
+func copyDatabase(dbNameWithPath, copyDir string) (string, func(), error) {
+	// bolt creates the file when opening a missing database, even in read-only mode.
+	if _, err := os.Stat(dbNameWithPath); err != nil {
+		return "", nil, err
+	}
+	dir, err := os.MkdirTemp(copyDir, "exploredb-")
+	if err != nil {
+		return "", nil, errors.Wrap(err, "could not create directory for the database copy")
+	}
+	cleanup := func() {
+		if err := os.RemoveAll(dir); err != nil {
+			log.WithError(err).WithField("path", dir).Error("could not remove database copy")
+		}
+	}
+	copyPath := filepath.Join(dir, filepath.Base(dbNameWithPath))
+	start := time.Now()
+	if err := snapshotDatabase(dbNameWithPath, copyPath); err != nil {
+		if !errors.Is(err, bolt.ErrTimeout) {
+			cleanup()
+			return "", nil, err
+		}
+		log.WithField("path", dbNameWithPath).Warn("Database is in use, copying the file as is. The copy may be inconsistent")
+		if err := copyFile(dbNameWithPath, copyPath); err != nil {
+			cleanup()
+			return "", nil, err
+		}
+	}
+	log.WithFields(log.Fields{
+		"path":     copyPath,
+		"duration": time.Since(start),
+	}).Info("Copied database")
+	return copyPath, cleanup, nil
+}
+
+// snapshotDatabase writes a consistent copy of the database, which must not be in use, to copyPath.
+func snapshotDatabase(dbNameWithPath, copyPath string) error {
+	db, err := bolt.Open(dbNameWithPath, 0600, &bolt.Options{Timeout: 1 * time.Second, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.WithError(err).Error("could not close database after copying it")
+		}
+	}()
+	return db.View(func(tx *bolt.Tx) error {
+		return tx.CopyFile(copyPath, 0600)
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src) // #nosec G304
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := in.Close(); err != nil {
+			log.WithError(err).Error("could not close database file")
+		}
+	}()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600) // #nosec G304
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	logTest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/theQRL/qrysm/v4/beacon-chain/db/kv"
+	"github.com/theQRL/qrysm/v4/beacon-chain/db/slasherkv"
+	slashertypes "github.com/theQRL/qrysm/v4/beacon-chain/slasher/types"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/util"
+	validatorkv "github.com/theQRL/qrysm/v4/validator/db/kv"
+)
+
+var genesisValidatorsRoot = bytesutil.PadTo([]byte("genesis"), 32)
+
+// saveValidatorDB writes a validator database holding a genesis validators root into dir.
+func saveValidatorDB(t *testing.T, dir string) *validatorkv.Store {
+	db, err := validatorkv.NewKVStore(context.Background(), dir, &validatorkv.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.SaveGenesisValidatorsRoot(context.Background(), genesisValidatorsRoot))
+	return db
+}
+
+// checkValidatorDB checks that the validator database under dir holds the genesis validators root.
+func checkValidatorDB(t *testing.T, dir string) {
+	db, err := validatorkv.NewKVStore(context.Background(), dir, &validatorkv.Config{})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, db.Close())
+	}()
+	root, err := db.GenesisValidatorsRoot(context.Background())
+	require.NoError(t, err)
+	assert.DeepEqual(t, genesisValidatorsRoot, root)
+}
+
+func TestCopyDatabase(t *testing.T) {
+	ctx := context.Background()
+	blk := util.NewBeaconBlock()
+	blk.Block.Slot = 3
+	blockRoot, err := blk.Block.HashTreeRoot()
+	require.NoError(t, err)
+	att := slasherAttestation(1, 2, 1, [32]byte{'a'})
+
+	tests := []struct {
+		name     string
+		fileName string
+		save     func(t *testing.T, dir string)
+		check    func(t *testing.T, dir string)
+	}{
+		{
+			name:     "beacon",
+			fileName: kv.DatabaseFileName,
+			save: func(t *testing.T, dir string) {
+				db, err := kv.NewKVStore(ctx, dir)
+				require.NoError(t, err)
+				util.SaveBlock(t, ctx, db, blk)
+				require.NoError(t, db.Close())
+			},
+			check: func(t *testing.T, dir string) {
+				db, err := kv.NewKVStore(ctx, dir)
+				require.NoError(t, err)
+				defer func() {
+					require.NoError(t, db.Close())
+				}()
+				saved, err := db.Block(ctx, blockRoot)
+				require.NoError(t, err)
+				require.Equal(t, blk.Block.Slot, saved.Block().Slot())
+			},
+		},
+		{
+			name:     "slasher",
+			fileName: slasherkv.DatabaseFileName,
+			save: func(t *testing.T, dir string) {
+				db, err := slasherkv.NewKVStore(ctx, dir)
+				require.NoError(t, err)
+				require.NoError(t, db.SaveAttestationRecordsForValidators(ctx, []*slashertypes.IndexedAttestationWrapper{att}))
+				require.NoError(t, db.Close())
+			},
+			check: func(t *testing.T, dir string) {
+				db, err := slasherkv.NewKVStore(ctx, dir)
+				require.NoError(t, err)
+				defer func() {
+					require.NoError(t, db.Close())
+				}()
+				record, err := db.AttestationRecordForValidator(ctx, 1, 2)
+				require.NoError(t, err)
+				require.NotNil(t, record)
+				assert.Equal(t, att.SigningRoot, record.SigningRoot)
+			},
+		},
+		{
+			name:     "validator",
+			fileName: validatorkv.ProtectionDbFileName,
+			save: func(t *testing.T, dir string) {
+				require.NoError(t, saveValidatorDB(t, dir).Close())
+			},
+			check: checkValidatorDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := logTest.NewGlobal()
+			dir := t.TempDir()
+			tt.save(t, dir)
+			path := filepath.Join(dir, tt.fileName)
+			before, err := os.ReadFile(path)
+			require.NoError(t, err)
+
+			copyPath, cleanup, err := copyDatabase(path, t.TempDir())
+			require.NoError(t, err)
+			require.LogsDoNotContain(t, hook, "Database is in use")
+			assert.Equal(t, tt.fileName, filepath.Base(copyPath))
+			tt.check(t, filepath.Dir(copyPath))
+
+			after, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.DeepEqual(t, before, after, "The source database was modified")
+
+			cleanup()
+			_, err = os.Stat(filepath.Dir(copyPath))
+			assert.Equal(t, true, os.IsNotExist(err))
+		})
+	}
+}
+
+func TestCopyDatabase_InUse(t *testing.T) {
+	hook := logTest.NewGlobal()
+	dir := t.TempDir()
+	// The open store holds the file lock, as a running validator client would.
+	db := saveValidatorDB(t, dir)
+	copyPath, cleanup, err := copyDatabase(filepath.Join(dir, validatorkv.ProtectionDbFileName), t.TempDir())
+	require.NoError(t, db.Close())
+	require.NoError(t, err)
+	defer cleanup()
+	require.LogsContain(t, hook, "Database is in use, copying the file as is")
+	checkValidatorDB(t, filepath.Dir(copyPath))
+}
+
+func TestCopyDatabase_Missing(t *testing.T) {
+	copyDir := t.TempDir()
+	path := filepath.Join(t.TempDir(), validatorkv.ProtectionDbFileName)
+	_, _, err := copyDatabase(path, copyDir)
+	require.NotNil(t, err)
+	assert.Equal(t, true, os.IsNotExist(err))
+	_, err = os.Stat(path)
+	assert.Equal(t, true, os.IsNotExist(err), "The missing database was created")
+
+	// The directory of the failed copy is removed.
+	entries, err := os.ReadDir(copyDir)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(entries))
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/beacon-chain/db/slasherkv"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	validatorkv "github.com/theQRL/qrysm/v4/validator/db/kv"
+)
+
+// printSlasherAttestations prints the attestations the slasher database holds for the validator, from its
+// highest target epoch down to at most epochLimit epochs back.
+func printSlasherAttestations(ctx context.Context, dbNameWithPath string, validatorIdx primitives.ValidatorIndex, epochLimit uint64) error {
+	if filepath.Base(dbNameWithPath) != slasherkv.DatabaseFileName {
+		return fmt.Errorf("slasher database file must be named %s", slasherkv.DatabaseFileName)
+	}
+	db, err := slasherkv.NewKVStore(ctx, filepath.Dir(dbNameWithPath))
+	if err != nil {
+		return errors.Wrap(err, "could not open slasher db")
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.WithError(err).Error("could not close slasher db")
+		}
+	}()
+
+	highest, err := db.HighestAttestations(ctx, []primitives.ValidatorIndex{validatorIdx})
+	if err != nil {
+		return errors.Wrap(err, "could not get highest attestation")
+	}
+	if len(highest) == 0 {
+		log.Infof("no attestations of validator %d", validatorIdx)
+		return nil
+	}
+	log.Infof("------ validator %d ---------", validatorIdx)
+	log.Infof("highest source epoch = %d, highest target epoch = %d", highest[0].HighestSourceEpoch, highest[0].HighestTargetEpoch)
+	count := 0
+	for i := uint64(0); i < epochLimit && uint64(highest[0].HighestTargetEpoch) >= i; i++ {
+		target := highest[0].HighestTargetEpoch - primitives.Epoch(i)
+		record, err := db.AttestationRecordForValidator(ctx, validatorIdx, target)
+		if err != nil {
+			return errors.Wrapf(err, "could not get attestation with target epoch %d", target)
+		}
+		if record == nil {
+			continue
+		}
+		data := record.IndexedAttestation.Data
+		log.Infof("source = %d, target = %d, slot = %d, head = %#x, signing_root = %#x",
+			data.Source.Epoch, data.Target.Epoch, data.Slot, data.BeaconBlockRoot, record.SigningRoot)
+		count++
+	}
+	log.Infof("%d attestations in the last %d epochs", count, epochLimit)
+	return nil
+}
+
+// printValidatorHistory prints the attestation and proposal history the validator database holds for the
+// public key, or for every public key if pubKey is empty, at most rowLimit records of each per key.
+func printValidatorHistory(ctx context.Context, dbNameWithPath string, pubKey []byte, rowLimit uint64) error {
+	if filepath.Base(dbNameWithPath) != validatorkv.ProtectionDbFileName {
+		return fmt.Errorf("validator database file must be named %s", validatorkv.ProtectionDbFileName)
+	}
+	if len(pubKey) != 0 && len(pubKey) != dilithium2.CryptoPublicKeyBytes {
+		return fmt.Errorf("public key is %d bytes long, wanted %d", len(pubKey), dilithium2.CryptoPublicKeyBytes)
+	}
+	db, err := validatorkv.NewKVStore(ctx, filepath.Dir(dbNameWithPath), &validatorkv.Config{})
+	if err != nil {
+		return errors.Wrap(err, "could not open validator db")
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.WithError(err).Error("could not close validator db")
+		}
+	}()
+
+	genesisValidatorsRoot, err := db.GenesisValidatorsRoot(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not get genesis validators root")
+	}
+	log.Infof("genesis_validators_root = %#x", genesisValidatorsRoot)
+
+	var keys [][dilithium2.CryptoPublicKeyBytes]byte
+	if len(pubKey) != 0 {
+		keys = append(keys, bytesutil.ToBytes2592(pubKey))
+	} else {
+		attested, err := db.AttestedPublicKeys(ctx)
+		if err != nil {
+			return errors.Wrap(err, "could not get attested public keys")
+		}
+		proposed, err := db.ProposedPublicKeys(ctx)
+		if err != nil {
+			return errors.Wrap(err, "could not get proposed public keys")
+		}
+		seen := make(map[[dilithium2.CryptoPublicKeyBytes]byte]bool)
+		for _, k := range append(attested, proposed...) {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	for _, k := range keys {
+		log.Infof("------ pubkey %#x ---------", bytesutil.Trunc(k[:]))
+		attestations, err := db.AttestationHistoryForPubKey(ctx, k)
+		if err != nil {
+			return errors.Wrap(err, "could not get attestation history")
+		}
+		log.Infof("attestations = %d", len(attestations))
+		for i, att := range attestations {
+			if uint64(i) >= rowLimit {
+				break
+			}
+			log.Infof("attestation : source = %d, target = %d, signing_root = %#x", att.Source, att.Target, att.SigningRoot)
+		}
+		proposals, err := db.ProposalHistoryForPubKey(ctx, k)
+		if err != nil {
+			return errors.Wrap(err, "could not get proposal history")
+		}
+		log.Infof("proposals = %d", len(proposals))
+		for i, p := range proposals {
+			if uint64(i) >= rowLimit {
+				break
+			}
+			log.Infof("proposal : slot = %d, signing_root = %#x", p.Slot, p.SigningRoot)
+		}
+	}
+	return nil
+}
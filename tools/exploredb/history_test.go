@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	logTest "github.com/sirupsen/logrus/hooks/test"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/beacon-chain/db/slasherkv"
+	slashertypes "github.com/theQRL/qrysm/v4/beacon-chain/slasher/types"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	validatorkv "github.com/theQRL/qrysm/v4/validator/db/kv"
+)
+
+// slasherAttestation returns an attestation of the validator, as stored by the slasher.
+func slasherAttestation(source, target primitives.Epoch, idx uint64, signingRoot [32]byte) *slashertypes.IndexedAttestationWrapper {
+	zeroHash := params.BeaconConfig().ZeroHash
+	return &slashertypes.IndexedAttestationWrapper{
+		IndexedAttestation: &zondpb.IndexedAttestation{
+			AttestingIndices: []uint64{idx},
+			Data: &zondpb.AttestationData{
+				BeaconBlockRoot: zeroHash[:],
+				Source:          &zondpb.Checkpoint{Epoch: source, Root: zeroHash[:]},
+				Target:          &zondpb.Checkpoint{Epoch: target, Root: zeroHash[:]},
+			},
+			Signature: make([]byte, dilithium2.CryptoBytes*int(params.BeaconConfig().MaxValidatorsPerCommittee)),
+		},
+		SigningRoot: signingRoot,
+	}
+}
+
+func TestPrintSlasherAttestations(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := slasherkv.NewKVStore(ctx, dir)
+	require.NoError(t, err)
+	require.NoError(t, db.SaveAttestationRecordsForValidators(ctx, []*slashertypes.IndexedAttestationWrapper{
+		slasherAttestation(1, 2, 1, [32]byte{'a'}),
+		slasherAttestation(2, 3, 1, [32]byte{'b'}),
+	}))
+	require.NoError(t, db.Close())
+	path := filepath.Join(dir, slasherkv.DatabaseFileName)
+
+	hook := logTest.NewGlobal()
+	require.NoError(t, printSlasherAttestations(ctx, path, 1, 10))
+	require.LogsContain(t, hook, "highest source epoch = 2, highest target epoch = 3")
+	require.LogsContain(t, hook, fmt.Sprintf("source = 1, target = 2, slot = 0, head = %#x, signing_root = %#x", params.BeaconConfig().ZeroHash, [32]byte{'a'}))
+	require.LogsContain(t, hook, "source = 2, target = 3")
+	require.LogsContain(t, hook, "2 attestations in the last 10 epochs")
+
+	// Only the attestations of the last epochs within the limit are printed.
+	hook.Reset()
+	require.NoError(t, printSlasherAttestations(ctx, path, 1, 1))
+	require.LogsDoNotContain(t, hook, "source = 1, target = 2")
+	require.LogsContain(t, hook, "1 attestations in the last 1 epochs")
+
+	hook.Reset()
+	require.NoError(t, printSlasherAttestations(ctx, path, 2, 10))
+	require.LogsContain(t, hook, "no attestations of validator 2")
+
+	require.ErrorContains(t, "must be named", printSlasherAttestations(ctx, filepath.Join(dir, "other.db"), 1, 10))
+}
+
+func TestPrintValidatorHistory(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	attester := [dilithium2.CryptoPublicKeyBytes]byte{1}
+	proposer := [dilithium2.CryptoPublicKeyBytes]byte{2}
+	db, err := validatorkv.NewKVStore(ctx, dir, &validatorkv.Config{
+		PubKeys: [][dilithium2.CryptoPublicKeyBytes]byte{attester, proposer},
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.SaveGenesisValidatorsRoot(ctx, genesisValidatorsRoot))
+	for target := primitives.Epoch(1); target <= 3; target++ {
+		require.NoError(t, db.SaveAttestationForPubKey(ctx, attester, [32]byte{byte(target)}, &zondpb.IndexedAttestation{
+			Data: &zondpb.AttestationData{
+				Source: &zondpb.Checkpoint{Epoch: target - 1},
+				Target: &zondpb.Checkpoint{Epoch: target},
+			},
+		}))
+	}
+	require.NoError(t, db.SaveProposalHistoryForSlot(ctx, proposer, 5, []byte{'p'}))
+	require.NoError(t, db.Close())
+	path := filepath.Join(dir, validatorkv.ProtectionDbFileName)
+
+	hook := logTest.NewGlobal()
+	require.NoError(t, printValidatorHistory(ctx, path, nil, 2))
+	require.LogsContain(t, hook, fmt.Sprintf("genesis_validators_root = %#x", genesisValidatorsRoot))
+	require.LogsContain(t, hook, "attestations = 3")
+	require.LogsContain(t, hook, "attestation : source = 0, target = 1")
+	require.LogsContain(t, hook, "attestation : source = 1, target = 2")
+	// Only rowLimit records are printed per key.
+	require.LogsDoNotContain(t, hook, "attestation : source = 2, target = 3")
+	require.LogsContain(t, hook, "proposals = 1")
+	require.LogsContain(t, hook, "proposal : slot = 5")
+
+	// A single key can be printed.
+	hook.Reset()
+	require.NoError(t, printValidatorHistory(ctx, path, proposer[:], 10))
+	require.LogsContain(t, hook, "attestations = 0")
+	require.LogsContain(t, hook, "proposals = 1")
+
+	require.ErrorContains(t, "public key is 1 bytes long", printValidatorHistory(ctx, path, []byte{1}, 10))
+	require.ErrorContains(t, "must be named", printValidatorHistory(ctx, filepath.Join(dir, "other.db"), nil, 10))
+}
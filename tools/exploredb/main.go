@@ -3,7 +3,13 @@
  *
  * Given a beacon-chain DB, This tool provides many option to
  * inspect and explore it. For every non-empty bucket, print
- * the number of rows, bucket size,min/average/max size of values
+ * the number of rows, bucket size,min/average/max size of values.
+ * It decodes the rows of the buckets of the beacon-chain DB schema,
+ * serves a local web UI to browse the chain by slot or root, and
+ * prints the attestation histories of slasher and validator DBs.
+ *
+ * Every command works on a copy of the DB file, so it is safe to
+ * run against the DB of a running node.
  */
 
 package main
@@ -13,6 +19,7 @@ import (
 	"context"
 	"flag"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"sync"
 	"time"
@@ -20,6 +27,7 @@ import (
 	"github.com/dustin/go-humanize"
 	log "github.com/sirupsen/logrus"
 	"github.com/status-im/keycard-go/hexutils"
+	"github.com/theQRL/go-zond/common/hexutil"
 	"github.com/theQRL/qrysm/v4/beacon-chain/db/kv"
 	"github.com/theQRL/qrysm/v4/beacon-chain/state"
 	"github.com/theQRL/qrysm/v4/config/params"
@@ -42,6 +50,11 @@ var (
 	rowLimit      = flag.Uint64("limit", 10, "limit to rows.")
 	migrationName = flag.String("migration", "", "migration to cross check.")
 	destDatadir   = flag.String("dest-datadir", "", "Path to destination data directory.")
+	copyDir       = flag.String("copy-dir", os.TempDir(), "Directory the database is copied to before being read.")
+	validatorIdx  = flag.Uint64("validator-index", 0, "validator to show the slasher attestations of.")
+	pubKey        = flag.String("pubkey", "", "hex encoded public key to show the validator history of, all keys if empty.")
+	httpHost      = flag.String("http-host", "127.0.0.1", "host the web UI listens on.")
+	httpPort      = flag.Int("http-port", 8080, "port the web UI listens on.")
 )
 
 // used to parallelize all the bucket stats
@@ -90,28 +103,177 @@ func main() {
 		log.WithError(err).WithField("path", dbNameWithPath).Fatal("could not locate database file")
 	}
 
+	// never read the database itself, which may be in use by a node.
+	dbCopy, cleanup := mustCopyDatabase(dbNameWithPath)
+	defer cleanup()
+
 	switch *command {
 	case "bucket-stats":
-		printBucketStats(dbNameWithPath)
+		printBucketStats(dbCopy)
 	case "bucket-content":
 		switch *bucketName {
 		case "state",
 			"state-summary":
-			printBucketContents(dbNameWithPath, *rowLimit, *bucketName)
+			printBucketContents(dbCopy, *rowLimit, *bucketName)
 		default:
-			log.Fatal("Oops, given bucket is supported for now.")
+			if schemaForBucket(*bucketName) == nil {
+				log.Fatal("Oops, given bucket is not in the beacon-chain DB schema.")
+			}
+			printDecodedRows(dbCopy, *rowLimit, *bucketName)
+		}
+	case "schema":
+		printSchema(dbCopy)
+	case "chain":
+		printChain(dbCopy)
+	case "serve":
+		serve(dbCopy)
+	case "slasher-attestations":
+		if err := printSlasherAttestations(context.Background(), dbCopy, primitives.ValidatorIndex(*validatorIdx), *rowLimit); err != nil {
+			log.WithError(err).Fatal("could not print slasher attestations")
+		}
+	case "validator-history":
+		var key []byte
+		if *pubKey != "" {
+			var err error
+			if key, err = hexutil.Decode(*pubKey); err != nil {
+				log.WithError(err).Fatal("could not decode public key")
+			}
+		}
+		if err := printValidatorHistory(context.Background(), dbCopy, key, *rowLimit); err != nil {
+			log.WithError(err).Fatal("could not print validator history")
 		}
 	case "migration-check":
 		destDbNameWithPath := filepath.Join(*destDatadir, *dbName)
 		if _, err := os.Stat(destDbNameWithPath); os.IsNotExist(err) {
 			log.WithError(err).WithField("path", destDbNameWithPath).Fatal("could not locate database file")
 		}
+		destDbCopy, destCleanup := mustCopyDatabase(destDbNameWithPath)
+		defer destCleanup()
 		switch *migrationName {
 		case "validator-entries":
-			checkValidatorMigration(dbNameWithPath, destDbNameWithPath)
+			checkValidatorMigration(dbCopy, destDbCopy)
 		default:
 			log.Fatal("Oops, given migration is not supported for now.")
 		}
+	default:
+		log.Fatal("Oops, given command is not supported.")
+	}
+}
+
+// mustCopyDatabase copies the database file to the copy directory. The copy is removed when the program
+// returns or exits through log.Fatal.
+func mustCopyDatabase(dbNameWithPath string) (string, func()) {
+	dbCopy, cleanup, err := copyDatabase(dbNameWithPath, *copyDir)
+	if err != nil {
+		log.WithError(err).WithField("path", dbNameWithPath).Fatal("could not copy database file")
+	}
+	log.RegisterExitHandler(cleanup)
+	return dbCopy, cleanup
+}
+
+func openBeaconDB(dbNameWithPath string) *kv.Store {
+	db, openErr := kv.NewKVStore(context.Background(), filepath.Dir(dbNameWithPath))
+	if openErr != nil {
+		log.WithError(openErr).Fatal("could not open db")
+	}
+	return db
+}
+
+func closeBeaconDB(db *kv.Store) {
+	if closeErr := db.Close(); closeErr != nil {
+		log.WithError(closeErr).Fatal("could not close db")
+	}
+}
+
+// printSchema prints every bucket of the beacon-chain DB schema with its number of rows, followed by the
+// buckets of the database which are not in the schema.
+func printSchema(dbNameWithPath string) {
+	counts := make(map[string]int)
+	db, openErr := bolt.Open(dbNameWithPath, 0600, &bolt.Options{Timeout: 1 * time.Second, ReadOnly: true})
+	if openErr != nil {
+		log.WithError(openErr).Fatal("could not open db to show schema")
+	}
+	if viewErr := db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			counts[string(name)] = b.Stats().KeyN
+			return nil
+		})
+	}); viewErr != nil {
+		log.WithError(viewErr).Fatal("could not read buckets from db")
+	}
+	if closeErr := db.Close(); closeErr != nil {
+		log.WithError(closeErr).Fatal("could not close db after showing schema")
+	}
+
+	for _, s := range beaconDBSchema {
+		count, ok := counts[s.name]
+		if !ok {
+			log.Infof("%-36s : missing, %s", s.name, s.description)
+			continue
+		}
+		log.Infof("%-36s : %8d rows, %s", s.name, count, s.description)
+		delete(counts, s.name)
+	}
+	for name, count := range counts {
+		log.Infof("%-36s : %8d rows, not in the beacon-chain DB schema", name, count)
+	}
+}
+
+// printDecodedRows prints the rows of the bucket, within the limit, as decoded by the bucket's schema.
+func printDecodedRows(dbNameWithPath string, rowLimit uint64, bucketName string) {
+	keys, values := rowsOfBucket(dbNameWithPath, []byte(bucketName), rowLimit)
+	db := openBeaconDB(dbNameWithPath)
+	defer closeBeaconDB(db)
+
+	ctx := context.Background()
+	decode := schemaForBucket(bucketName).decode
+	for i, k := range keys {
+		row, err := decode(ctx, db, k, values[i])
+		if err != nil {
+			log.WithError(err).Errorf("could not decode row %04d, key = %s", i, hexutils.BytesToHex(k))
+			continue
+		}
+		log.Infof("row : %04d, %s", i, row)
+	}
+}
+
+// printChain prints the head, genesis and checkpoints of the chain.
+func printChain(dbNameWithPath string) {
+	db := openBeaconDB(dbNameWithPath)
+	defer closeBeaconDB(db)
+
+	info, err := readChainInfo(context.Background(), db)
+	if err != nil {
+		log.WithError(err).Fatal("could not read chain")
+	}
+	if info.Head != nil {
+		log.Infof("head               : %s", info.Head)
+	} else {
+		log.Info("head               : none")
+	}
+	if info.GenesisRoot != "" {
+		log.Infof("genesis            : %s", info.GenesisRoot)
+	}
+	if info.OriginRoot != "" {
+		log.Infof("origin checkpoint  : %s", info.OriginRoot)
+	}
+	log.Infof("justified          : epoch = %d, root = %s", info.JustifiedEpoch, info.JustifiedRoot)
+	log.Infof("finalized          : epoch = %d, root = %s", info.FinalizedEpoch, info.FinalizedRoot)
+	if info.HasValidatedCpt {
+		log.Infof("last validated     : epoch = %d, root = %s", info.ValidatedEpoch, info.ValidatedRoot)
+	}
+	log.Infof("last archived slot : %d", info.LastArchived)
+}
+
+// serve serves the web UI until the program is interrupted.
+func serve(dbNameWithPath string) {
+	db := openBeaconDB(dbNameWithPath)
+	defer closeBeaconDB(db)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := serveUI(ctx, db, *httpHost, *httpPort); err != nil {
+		log.WithError(err).Fatal("could not serve web UI")
 	}
 }
 
@@ -493,6 +655,39 @@ func keysOfBucket(dbNameWithPath string, bucketName []byte, rowLimit uint64) ([]
 	return keys, sizes
 }
 
+func rowsOfBucket(dbNameWithPath string, bucketName []byte, rowLimit uint64) ([][]byte, [][]byte) {
+	// open the raw database file. If the file is busy, then exit.
+	db, openErr := bolt.Open(dbNameWithPath, 0600, &bolt.Options{Timeout: 1 * time.Second, ReadOnly: true})
+	if openErr != nil {
+		log.WithError(openErr).Fatal("could not open db while getting rows of a bucket")
+	}
+
+	// make sure we close the database before ejecting out of this function.
+	defer func() {
+		closeErr := db.Close()
+		if closeErr != nil {
+			log.WithError(closeErr).Fatal("could not close db while getting rows of a bucket")
+		}
+	}()
+
+	var keys, values [][]byte
+	if viewErr := db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketName)
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+		for k, v := c.First(); k != nil && uint64(len(keys)) < rowLimit; k, v = c.Next() {
+			keys = append(keys, bytesutil.SafeCopyBytes(k))
+			values = append(values, bytesutil.SafeCopyBytes(v))
+		}
+		return nil
+	}); viewErr != nil {
+		log.WithError(viewErr).Fatal("could not read rows of bucket from db")
+	}
+	return keys, values
+}
+
 func sizeAndCountOfByteList(list [][]byte) (uint64, uint64) {
 	size := uint64(0)
 	count := uint64(0)
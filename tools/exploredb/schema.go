@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/golang/snappy"
+	"github.com/theQRL/qrysm/v4/beacon-chain/db/kv"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"google.golang.org/protobuf/proto"
+)
+
+// rowDecoder describes a row of a bucket. Rows which can only be read through the store, such as blocks and
+// states, are looked up in db, every other row is decoded from its raw key and value.
+type rowDecoder func(ctx context.Context, db *kv.Store, k, v []byte) (string, error)
+
+// bucketSchema is what the beacon database stores in a bucket, as defined in beacon-chain/db/kv/schema.go.
+type bucketSchema struct {
+	name        string
+	description string
+	decode      rowDecoder
+}
+
+// Keys stored next to the rows of the blocks, finalized-block-roots-index and other buckets. They are
+// unexported by the kv package and so are repeated here.
+var (
+	blockRootKeys = map[string]bool{
+		"head-root":                    true,
+		"genesis-root":                 true,
+		"origin-checkpoint-block-root": true,
+		"backfill-block-root":          true,
+	}
+	previousFinalizedCheckpointKey    = "previous-finalized-checkpoint"
+	containerFinalizedButNotCanonical = "recent block needs reindexing to determine canonical"
+)
+
+// beaconDBSchema lists the buckets of the beacon database, in the order of beacon-chain/db/kv/schema.go.
+var beaconDBSchema = []*bucketSchema{
+	{name: "attestations", description: "deprecated, no longer written", decode: decodeRaw},
+	{name: "blocks", description: "block root -> signed block, and the head, genesis, origin and backfill block roots", decode: decodeBlock},
+	{name: "state", description: "block root -> full state", decode: decodeState},
+	{name: "state-summary", description: "block root -> slot and root of the state", decode: decodeStateSummary},
+	{name: "proposer-slashings", description: "deprecated, no longer written", decode: decodeRaw},
+	{name: "attester-slashings", description: "deprecated, no longer written", decode: decodeRaw},
+	{name: "voluntary-exits", description: "deprecated, no longer written", decode: decodeRaw},
+	{name: "chain-metadata", description: "deposit contract address, block storage type and other chain wide values", decode: decodeChainMetadata},
+	{name: "check-point", description: "justified, finalized and last validated checkpoints", decode: decodeCheckpoint},
+	{name: "powchain", description: "execution chain data and deposit snapshot", decode: decodeSize},
+	{name: "state-validators", description: "validator hash -> validator, shared by the stored states", decode: decodeValidator},
+	{name: "fee-recipient", description: "validator index -> fee recipient address", decode: decodeFeeRecipient},
+	{name: "registration", description: "validator index -> builder registration", decode: decodeRegistration},
+	{name: "state-diff", description: "block root -> base state root and state diff", decode: decodeStateDiff},
+	{name: "state-diff-bases", description: "block root of a full state some state diffs are based on", decode: decodeKeyOnly},
+	{name: "monitored-validator-indices", description: "index of a validator tracked by the validator monitor", decode: decodeKeyOnly},
+	{name: "monitored-validator-pubkeys", description: "public key of a validator tracked by the validator monitor", decode: decodeKeyOnly},
+	{name: "slots-has-objects", description: "deprecated, migrated away", decode: decodeRaw},
+	{name: "archived-index-root", description: "deprecated, migrated away", decode: decodeRaw},
+	{name: "block-parent-root-indices", description: "parent root -> roots of its child blocks", decode: decodeRootList},
+	{name: "block-slot-indices", description: "slot -> roots of the blocks at the slot", decode: decodeSlotIndex},
+	{name: "state-slot-indices", description: "slot -> block roots of the states at the slot", decode: decodeSlotIndex},
+	{name: "attestation-head-block-root-indices", description: "deprecated, no longer written", decode: decodeRaw},
+	{name: "attestation-source-root-indices", description: "deprecated, no longer written", decode: decodeRaw},
+	{name: "attestation-source-epoch-indices", description: "deprecated, no longer written", decode: decodeRaw},
+	{name: "attestation-target-root-indices", description: "deprecated, no longer written", decode: decodeRaw},
+	{name: "attestation-target-epoch-indices", description: "deprecated, no longer written", decode: decodeRaw},
+	{name: "finalized-block-roots-index", description: "finalized block root -> parent and child in the canonical chain", decode: decodeFinalizedBlockRoot},
+	{name: "block-root-validator-hashes", description: "block root -> hashes of the validators of its state", decode: decodeRootList},
+	{name: "new-state-compatible", description: "deprecated, no longer written", decode: decodeRaw},
+	{name: "migrations", description: "completed migrations", decode: decodeRaw},
+}
+
+// schemaForBucket returns the schema of the bucket, or nil if the beacon database has no such bucket.
+func schemaForBucket(name string) *bucketSchema {
+	for _, s := range beaconDBSchema {
+		if s.name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+func decodeRaw(_ context.Context, _ *kv.Store, k, v []byte) (string, error) {
+	return fmt.Sprintf("key = %s, value = %#x", formatKey(k), v), nil
+}
+
+func decodeKeyOnly(_ context.Context, _ *kv.Store, k, _ []byte) (string, error) {
+	return fmt.Sprintf("key = %s", formatKey(k)), nil
+}
+
+func decodeSize(_ context.Context, _ *kv.Store, k, v []byte) (string, error) {
+	return fmt.Sprintf("key = %s, compressed size = %d", formatKey(k), len(v)), nil
+}
+
+func decodeBlock(ctx context.Context, db *kv.Store, k, v []byte) (string, error) {
+	if blockRootKeys[string(k)] {
+		return fmt.Sprintf("%s = %#x", k, v), nil
+	}
+	root := bytesutil.ToBytes32(k)
+	blk, err := db.Block(ctx, root)
+	if err != nil {
+		return "", err
+	}
+	if blk == nil || blk.IsNil() {
+		return "", fmt.Errorf("no block with root %#x", k)
+	}
+	s, err := summarizeBlock(ctx, db, root, blk)
+	if err != nil {
+		return "", err
+	}
+	return s.String(), nil
+}
+
+func decodeState(ctx context.Context, db *kv.Store, k, v []byte) (string, error) {
+	st, err := db.State(ctx, bytesutil.ToBytes32(k))
+	if err != nil {
+		return "", err
+	}
+	if st == nil || st.IsNil() {
+		return "", fmt.Errorf("no state with root %#x", k)
+	}
+	return fmt.Sprintf("root = %#x, slot = %d, validators = %d, compressed size = %d", k, st.Slot(), st.NumValidators(), len(v)), nil
+}
+
+func decodeStateSummary(_ context.Context, _ *kv.Store, k, v []byte) (string, error) {
+	summary := &zondpb.StateSummary{}
+	if err := decodeProto(v, summary); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("root = %#x, slot = %d", k, summary.Slot), nil
+}
+
+func decodeChainMetadata(_ context.Context, _ *kv.Store, k, v []byte) (string, error) {
+	switch string(k) {
+	case "save-blinded-beacon-blocks":
+		return fmt.Sprintf("%s = %t", k, len(v) == 1 && v[0] == 1), nil
+	default:
+		return fmt.Sprintf("%s = %#x", k, v), nil
+	}
+}
+
+func decodeCheckpoint(_ context.Context, _ *kv.Store, k, v []byte) (string, error) {
+	cp := &zondpb.Checkpoint{}
+	if err := decodeProto(v, cp); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s = epoch %d, root %#x", k, cp.Epoch, cp.Root), nil
+}
+
+func decodeValidator(_ context.Context, _ *kv.Store, k, v []byte) (string, error) {
+	val := &zondpb.Validator{}
+	if err := decodeProto(v, val); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("hash = %#x, pubkey = %#x, effective_balance = %d, slashed = %t, activation_epoch = %d, exit_epoch = %d",
+		k, bytesutil.Trunc(val.PublicKey), val.EffectiveBalance, val.Slashed, val.ActivationEpoch, val.ExitEpoch), nil
+}
+
+func decodeFeeRecipient(_ context.Context, _ *kv.Store, k, v []byte) (string, error) {
+	return fmt.Sprintf("validator = %s, fee_recipient = %#x", formatKey(k), v), nil
+}
+
+func decodeRegistration(_ context.Context, _ *kv.Store, k, v []byte) (string, error) {
+	enc, err := snappy.Decode(nil, v)
+	if err != nil {
+		return "", err
+	}
+	reg := &zondpb.ValidatorRegistrationV1{}
+	if err := reg.UnmarshalSSZ(enc); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("validator = %s, fee_recipient = %#x, gas_limit = %d, timestamp = %d",
+		formatKey(k), reg.FeeRecipient, reg.GasLimit, reg.Timestamp), nil
+}
+
+func decodeStateDiff(_ context.Context, _ *kv.Store, k, v []byte) (string, error) {
+	if len(v) < 32 {
+		return "", fmt.Errorf("state diff of %#x is only %d bytes long", k, len(v))
+	}
+	return fmt.Sprintf("root = %#x, base = %#x, diff size = %d", k, v[:32], len(v)-32), nil
+}
+
+func decodeRootList(_ context.Context, _ *kv.Store, k, v []byte) (string, error) {
+	return fmt.Sprintf("key = %s, roots = %s", formatKey(k), formatRoots(v)), nil
+}
+
+func decodeSlotIndex(_ context.Context, _ *kv.Store, k, v []byte) (string, error) {
+	if len(k) != 8 {
+		return "", fmt.Errorf("slot index key %#x is not 8 bytes long", k)
+	}
+	return fmt.Sprintf("slot = %d, roots = %s", binary.BigEndian.Uint64(k), formatRoots(v)), nil
+}
+
+func decodeFinalizedBlockRoot(_ context.Context, _ *kv.Store, k, v []byte) (string, error) {
+	switch {
+	case string(k) == previousFinalizedCheckpointKey:
+		return decodeCheckpoint(context.Background(), nil, k, v)
+	case string(v) == containerFinalizedButNotCanonical:
+		return fmt.Sprintf("root = %#x, finalized but not yet indexed as canonical", k), nil
+	}
+	ctr := &zondpb.FinalizedBlockRootContainer{}
+	if err := decodeProto(v, ctr); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("root = %#x, parent = %#x, child = %#x", k, ctr.ParentRoot, ctr.ChildRoot), nil
+}
+
+// decodeProto decodes a value the kv package stores as a snappy compressed protobuf message.
+func decodeProto(v []byte, dst proto.Message) error {
+	enc, err := snappy.Decode(nil, v)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(enc, dst)
+}
+
+// formatKey formats a key as text if it is one of the named keys, as a number if it is a big endian slot or
+// validator index, and as hex otherwise.
+func formatKey(k []byte) string {
+	if len(k) == 8 {
+		return fmt.Sprintf("%d", binary.BigEndian.Uint64(k))
+	}
+	printable := len(k) > 0
+	for _, c := range k {
+		if c < 0x20 || c > 0x7e {
+			printable = false
+			break
+		}
+	}
+	if printable {
+		return string(k)
+	}
+	return fmt.Sprintf("%#x", k)
+}
+
+// formatRoots formats a value holding concatenated 32 byte roots.
+func formatRoots(v []byte) string {
+	if len(v)%32 != 0 {
+		return fmt.Sprintf("%#x", v)
+	}
+	roots := make([]string, 0, len(v)/32)
+	for i := 0; i < len(v); i += 32 {
+		roots = append(roots, fmt.Sprintf("%#x", v[i:i+32]))
+	}
+	return "[" + strings.Join(roots, ", ") + "]"
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/theQRL/qrysm/v4/beacon-chain/db/kv"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/util"
+)
+
+func TestSchemaForBucket_Decode(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := kv.NewKVStore(ctx, dir)
+	require.NoError(t, err)
+
+	parent := util.NewBeaconBlock()
+	parent.Block.Slot = 1
+	parentRoot, err := parent.Block.HashTreeRoot()
+	require.NoError(t, err)
+	util.SaveBlock(t, ctx, db, parent)
+	child := util.NewBeaconBlock()
+	child.Block.Slot = 2
+	child.Block.ParentRoot = parentRoot[:]
+	childRoot, err := child.Block.HashTreeRoot()
+	require.NoError(t, err)
+	util.SaveBlock(t, ctx, db, child)
+	require.NoError(t, db.SaveGenesisBlockRoot(ctx, parentRoot))
+
+	st, err := util.NewBeaconState()
+	require.NoError(t, err)
+	require.NoError(t, st.SetSlot(2))
+	require.NoError(t, db.SaveState(ctx, st, childRoot))
+	require.NoError(t, db.SaveStateSummary(ctx, &zondpb.StateSummary{Slot: 2, Root: childRoot[:]}))
+	require.NoError(t, db.SaveJustifiedCheckpoint(ctx, &zondpb.Checkpoint{Epoch: 1, Root: childRoot[:]}))
+	require.NoError(t, db.Close())
+
+	tests := []struct {
+		bucket string
+		want   []string
+	}{
+		{
+			bucket: "blocks",
+			want: []string{
+				fmt.Sprintf("root = %#x, slot = 1, epoch = 0,", parentRoot),
+				fmt.Sprintf("root = %#x, slot = 2, epoch = 0,", childRoot),
+				fmt.Sprintf("genesis-root = %#x", parentRoot),
+			},
+		},
+		{
+			bucket: "state-summary",
+			want:   []string{fmt.Sprintf("root = %#x, slot = 2", childRoot)},
+		},
+		{
+			bucket: "check-point",
+			want:   []string{fmt.Sprintf("justified-checkpoint = epoch 1, root %#x", childRoot)},
+		},
+		{
+			bucket: "block-slot-indices",
+			want: []string{
+				fmt.Sprintf("slot = 1, roots = [%#x]", parentRoot),
+				fmt.Sprintf("slot = 2, roots = [%#x]", childRoot),
+			},
+		},
+		{
+			bucket: "state-slot-indices",
+			want:   []string{fmt.Sprintf("slot = 2, roots = [%#x]", childRoot)},
+		},
+	}
+	// The rows are read from the raw file, which the store must not hold open.
+	path := filepath.Join(dir, kv.DatabaseFileName)
+	keys := make(map[string][][]byte)
+	values := make(map[string][][]byte)
+	for _, tt := range tests {
+		keys[tt.bucket], values[tt.bucket] = rowsOfBucket(path, []byte(tt.bucket), 100)
+	}
+
+	db, err = kv.NewKVStore(ctx, dir)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, db.Close())
+	}()
+	for _, tt := range tests {
+		t.Run(tt.bucket, func(t *testing.T) {
+			schema := schemaForBucket(tt.bucket)
+			require.NotNil(t, schema)
+			var rows []string
+			for i, k := range keys[tt.bucket] {
+				row, err := schema.decode(ctx, db, k, values[tt.bucket][i])
+				require.NoError(t, err)
+				rows = append(rows, row)
+			}
+			require.Equal(t, len(tt.want), len(rows), rows)
+			for _, want := range tt.want {
+				found := false
+				for _, row := range rows {
+					if strings.HasPrefix(row, want) {
+						found = true
+						break
+					}
+				}
+				assert.Equal(t, true, found, "No row starting with %q in %v", want, rows)
+			}
+		})
+	}
+
+	assert.Equal(t, (*bucketSchema)(nil), schemaForBucket("unknown"))
+}
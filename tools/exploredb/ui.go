@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"html/template"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/theQRL/go-zond/common/hexutil"
+	"github.com/theQRL/qrysm/v4/beacon-chain/db/kv"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+)
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>exploredb</title>
+<style>body{font-family:monospace} td{padding:0 1em 0 0}</style></head>
+<body>
+<p><a href="/">chain</a></p>
+<form action="/search" method="get"><input name="q" size="70" placeholder="slot or block root"> <input type="submit" value="go"></form>
+{{with .Chain}}
+<h2>Chain</h2>
+<table>
+<tr><td>head</td><td>{{with .Head}}<a href="/block/{{.Root}}">{{.Root}}</a> at slot <a href="/slot/{{.Slot}}">{{.Slot}}</a>{{else}}none{{end}}</td></tr>
+<tr><td>genesis</td><td>{{with .GenesisRoot}}<a href="/block/{{.}}">{{.}}</a>{{else}}none{{end}}</td></tr>
+{{if .OriginRoot}}<tr><td>origin checkpoint</td><td><a href="/block/{{.OriginRoot}}">{{.OriginRoot}}</a></td></tr>{{end}}
+<tr><td>justified</td><td>epoch {{.JustifiedEpoch}} <a href="/block/{{.JustifiedRoot}}">{{.JustifiedRoot}}</a></td></tr>
+<tr><td>finalized</td><td>epoch {{.FinalizedEpoch}} <a href="/block/{{.FinalizedRoot}}">{{.FinalizedRoot}}</a></td></tr>
+{{if .HasValidatedCpt}}<tr><td>last validated</td><td>epoch {{.ValidatedEpoch}} <a href="/block/{{.ValidatedRoot}}">{{.ValidatedRoot}}</a></td></tr>{{end}}
+<tr><td>last archived slot</td><td>{{.LastArchived}}</td></tr>
+</table>
+{{end}}
+{{if .IsSlot}}
+<h2>Slot {{.Slot}}</h2>
+<p>{{if .Slot}}<a href="/slot/{{.PrevSlot}}">&lt; slot {{.PrevSlot}}</a> {{end}}<a href="/slot/{{.NextSlot}}">slot {{.NextSlot}} &gt;</a></p>
+{{if not .Blocks}}<p>no block at this slot</p>{{end}}
+{{end}}
+{{with .Block}}
+<h2>Block {{.Root}}</h2>
+{{end}}
+{{range .Blocks}}
+<table>
+<tr><td>root</td><td><a href="/block/{{.Root}}">{{.Root}}</a></td></tr>
+<tr><td>slot</td><td><a href="/slot/{{.Slot}}">{{.Slot}}</a> (epoch {{.Epoch}})</td></tr>
+<tr><td>fork</td><td>{{.Fork}}{{if .Blinded}} (blinded){{end}}</td></tr>
+<tr><td>proposer</td><td>{{.ProposerIndex}}</td></tr>
+<tr><td>parent</td><td><a href="/block/{{.ParentRoot}}">{{.ParentRoot}}</a></td></tr>
+<tr><td>state root</td><td>{{.StateRoot}}</td></tr>
+<tr><td>attestations</td><td>{{.Attestations}}</td></tr>
+<tr><td>deposits</td><td>{{.Deposits}}</td></tr>
+<tr><td>voluntary exits</td><td>{{.VoluntaryExits}}</td></tr>
+{{if .PayloadHash}}<tr><td>execution payload</td><td>{{.PayloadNumber}} {{.PayloadHash}}</td></tr>{{end}}
+<tr><td>finalized</td><td>{{.Finalized}}</td></tr>
+<tr><td>state saved</td><td>{{.HasState}}</td></tr>
+<tr><td>state summary saved</td><td>{{.HasSummary}}</td></tr>
+</table>
+<br>
+{{end}}
+{{if .Block}}
+<h3>Children</h3>
+{{range .Children}}<p><a href="/block/{{.Root}}">{{.Root}}</a> at slot {{.Slot}}</p>{{else}}<p>none</p>{{end}}
+{{end}}
+</body>
+</html>
+`))
+
+// page is what pageTemplate shows: the chain, the blocks at a slot, or a block and its children.
+type page struct {
+	Chain    *chainInfo
+	IsSlot   bool
+	Slot     primitives.Slot
+	PrevSlot primitives.Slot
+	NextSlot primitives.Slot
+	Block    *blockSummary
+	Blocks   []*blockSummary
+	Children []*blockSummary
+}
+
+// uiServer serves pages browsing the chain in the database. It never writes to the database.
+type uiServer struct {
+	db *kv.Store
+}
+
+func (s *uiServer) router() *mux.Router {
+	r := mux.NewRouter()
+	r.HandleFunc("/", s.handleChain).Methods(http.MethodGet)
+	r.HandleFunc("/search", s.handleSearch).Methods(http.MethodGet)
+	r.HandleFunc("/slot/{slot:[0-9]+}", s.handleSlot).Methods(http.MethodGet)
+	r.HandleFunc("/block/{root}", s.handleBlock).Methods(http.MethodGet)
+	return r
+}
+
+func (s *uiServer) handleChain(w http.ResponseWriter, r *http.Request) {
+	info, err := readChainInfo(r.Context(), s.db)
+	if err != nil {
+		httpError(w, err, http.StatusInternalServerError)
+		return
+	}
+	render(w, &page{Chain: info})
+}
+
+// handleSearch redirects to the page of the slot or block root being searched for.
+func (s *uiServer) handleSearch(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if _, err := strconv.ParseUint(q, 10, 64); err == nil {
+		http.Redirect(w, r, "/slot/"+q, http.StatusFound)
+		return
+	}
+	if _, err := parseRoot(q); err != nil {
+		httpError(w, errors.Errorf("%q is neither a slot nor a block root", q), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, "/block/"+q, http.StatusFound)
+}
+
+func (s *uiServer) handleSlot(w http.ResponseWriter, r *http.Request) {
+	slot, err := strconv.ParseUint(mux.Vars(r)["slot"], 10, 64)
+	if err != nil {
+		httpError(w, err, http.StatusBadRequest)
+		return
+	}
+	blocks, err := blocksAtSlot(r.Context(), s.db, primitives.Slot(slot))
+	if err != nil {
+		httpError(w, err, http.StatusInternalServerError)
+		return
+	}
+	p := &page{IsSlot: true, Slot: primitives.Slot(slot), NextSlot: primitives.Slot(slot + 1), Blocks: blocks}
+	if slot > 0 {
+		p.PrevSlot = primitives.Slot(slot - 1)
+	}
+	render(w, p)
+}
+
+func (s *uiServer) handleBlock(w http.ResponseWriter, r *http.Request) {
+	root, err := parseRoot(mux.Vars(r)["root"])
+	if err != nil {
+		httpError(w, err, http.StatusBadRequest)
+		return
+	}
+	blk, children, err := blockByRoot(r.Context(), s.db, root)
+	if err != nil {
+		httpError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if blk == nil {
+		httpError(w, errors.Errorf("no block with root %#x", root), http.StatusNotFound)
+		return
+	}
+	render(w, &page{Block: blk, Blocks: []*blockSummary{blk}, Children: children})
+}
+
+func parseRoot(s string) ([32]byte, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	if len(b) != 32 {
+		return [32]byte{}, errors.Errorf("root is %d bytes long, wanted 32", len(b))
+	}
+	return bytesutil.ToBytes32(b), nil
+}
+
+func render(w http.ResponseWriter, p *page) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := pageTemplate.Execute(w, p); err != nil {
+		log.WithError(err).Error("could not render page")
+	}
+}
+
+func httpError(w http.ResponseWriter, err error, code int) {
+	http.Error(w, err.Error(), code)
+}
+
+// serveUI serves the pages browsing the chain in the database on host:port until ctx is done. It is meant to
+// be reached from the local machine only, so it listens on the loopback interface unless told otherwise.
+func serveUI(ctx context.Context, db *kv.Store, host string, port int) error {
+	srv := &http.Server{
+		Addr:              net.JoinHostPort(host, strconv.Itoa(port)),
+		Handler:           (&uiServer{db: db}).router(),
+		ReadHeaderTimeout: time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		if err := srv.Close(); err != nil {
+			log.WithError(err).Error("could not close HTTP server")
+		}
+	}()
+	log.Infof("Browse the database at http://%s", srv.Addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return errors.Wrap(err, "could not serve HTTP")
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/theQRL/qrysm/v4/beacon-chain/db/kv"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/util"
+)
+
+func setupUI(t *testing.T) (http.Handler, [32]byte, [32]byte) {
+	ctx := context.Background()
+	db, err := kv.NewKVStore(ctx, t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	parent := util.NewBeaconBlock()
+	parent.Block.Slot = 1
+	parentRoot, err := parent.Block.HashTreeRoot()
+	require.NoError(t, err)
+	util.SaveBlock(t, ctx, db, parent)
+
+	child := util.NewBeaconBlock()
+	child.Block.Slot = 2
+	child.Block.ParentRoot = parentRoot[:]
+	childRoot, err := child.Block.HashTreeRoot()
+	require.NoError(t, err)
+	util.SaveBlock(t, ctx, db, child)
+
+	require.NoError(t, db.SaveGenesisBlockRoot(ctx, parentRoot))
+	require.NoError(t, db.SaveStateSummary(ctx, &zondpb.StateSummary{Slot: 2, Root: childRoot[:]}))
+	require.NoError(t, db.SaveHeadBlockRoot(ctx, childRoot))
+
+	return (&uiServer{db: db}).router(), parentRoot, childRoot
+}
+
+func get(t *testing.T, h http.Handler, url string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+	return rec
+}
+
+func TestUI_Chain(t *testing.T) {
+	h, parentRoot, childRoot := setupUI(t)
+
+	rec := get(t, h, "/")
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.StringContains(t, fmt.Sprintf(`<a href="/block/%#x">%#x</a> at slot <a href="/slot/2">2</a>`, childRoot, childRoot), body)
+	assert.StringContains(t, fmt.Sprintf(`<tr><td>genesis</td><td><a href="/block/%#x">`, parentRoot), body)
+}
+
+func TestUI_Slot(t *testing.T) {
+	h, parentRoot, _ := setupUI(t)
+
+	rec := get(t, h, "/slot/1")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.StringContains(t, fmt.Sprintf("%#x", parentRoot), rec.Body.String())
+
+	rec = get(t, h, "/slot/3")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.StringContains(t, "no block at this slot", rec.Body.String())
+}
+
+func TestUI_Block(t *testing.T) {
+	h, parentRoot, childRoot := setupUI(t)
+
+	rec := get(t, h, fmt.Sprintf("/block/%#x", parentRoot))
+	require.Equal(t, http.StatusOK, rec.Code)
+	// The child is listed under the parent.
+	assert.StringContains(t, fmt.Sprintf(`<a href="/block/%#x">%#x</a> at slot 2`, childRoot, childRoot), rec.Body.String())
+
+	rec = get(t, h, fmt.Sprintf("/block/%#x", [32]byte{'a'}))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = get(t, h, "/block/0x01")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestUI_Search(t *testing.T) {
+	h, parentRoot, _ := setupUI(t)
+
+	rec := get(t, h, "/search?q=12")
+	require.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "/slot/12", rec.Header().Get("Location"))
+
+	rec = get(t, h, fmt.Sprintf("/search?q=%%20%#x", parentRoot))
+	require.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, fmt.Sprintf("/block/%#x", parentRoot), rec.Header().Get("Location"))
+
+	rec = get(t, h, "/search?q=head")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestUI_OnlyGet(t *testing.T) {
+	h, _, _ := setupUI(t)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/slot/1", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
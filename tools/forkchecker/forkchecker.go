@@ -6,6 +6,8 @@
  * Example: 2 beacon nodes with 2 gRPC end points, 127.0.0.1:4000 and 127.0.0.1:4001
  * For logging heads: forkchecker --endpoint 127.0.0.1:4000 --endpoint 127.0.0.1:4001
  * For comparing heads: forkchecker --endpoint 127.0.0.1:4000 --endpoint 127.0.0.1:4001 --compare
+ *
+ * To watch nodes for diverging heads, conflicting finality and deep reorgs over time, see tools/forkwatcher.
  */
 package main
 
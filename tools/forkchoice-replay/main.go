@@ -0,0 +1,79 @@
+/*
+*
+  - Fork choice replay
+    *
+  - Given a capture file written by a beacon node started with --forkchoice-capture-file,
+  - this tool replays the blocks and attestations the node received over gossip, each at
+  - its arrival time, through a blockchain service with a mocked clock and execution engine,
+  - and reports the head of fork choice at every slot.
+*/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/theQRL/qrysm/v4/beacon-chain/forkchoice/replay"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/io/file"
+)
+
+var (
+	capturePath     = flag.String("capture", "", "Path to the fork choice capture file.")
+	chainConfigFile = flag.String("chain-config-file", "", "Path to the chain config the capture was recorded with. Defaults to the named config recorded in the capture.")
+	outputPath      = flag.String("output", "", "Path to write the JSON report to. The heads are printed to stdout if empty.")
+)
+
+func main() {
+	flag.Parse()
+	if *capturePath == "" {
+		logrus.Fatal("--capture is required")
+	}
+	c, err := replay.ReadFile(*capturePath)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not read capture")
+	}
+	if *chainConfigFile != "" {
+		if err := params.LoadChainConfigFile(*chainConfigFile, nil); err != nil {
+			logrus.WithError(err).Fatal("Could not load chain config")
+		}
+	} else {
+		cfg, err := params.ByName(c.ConfigName)
+		if err != nil {
+			logrus.WithError(err).Fatalf("Unknown chain config %s, use --chain-config-file", c.ConfigName)
+		}
+		if err := params.SetActive(cfg); err != nil {
+			logrus.WithError(err).Fatal("Could not set chain config")
+		}
+	}
+	logrus.WithFields(logrus.Fields{
+		"anchorSlot": c.AnchorState.Slot(),
+		"arrivals":   len(c.Arrivals),
+	}).Info("Replaying capture")
+
+	report, err := replay.Replay(context.Background(), c)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not replay capture")
+	}
+
+	if *outputPath != "" {
+		enc, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			logrus.WithError(err).Fatal("Could not marshal report")
+		}
+		if err := file.WriteFile(*outputPath, enc); err != nil {
+			logrus.WithError(err).Fatal("Could not write report")
+		}
+		return
+	}
+	for _, h := range report.Slots {
+		fmt.Printf("slot=%d head=%s headSlot=%d justified=%d finalized=%d blocks=%d attestations=%d errors=%d\n",
+			h.Slot, h.HeadRoot, h.HeadSlot, h.JustifiedEpoch, h.FinalizedEpoch, h.Blocks, h.Attestations, len(h.Errors))
+		for _, e := range h.Errors {
+			fmt.Printf("  %s\n", e)
+		}
+	}
+}
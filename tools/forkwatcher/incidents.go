@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+)
+
+// Kinds of incidents.
+const (
+	headDivergence       = "head_divergence"
+	finalityDisagreement = "finality_disagreement"
+	deepReorg            = "deep_reorg"
+)
+
+// nodeView is what a beacon node reported when an incident was raised.
+type nodeView struct {
+	HeadSlot       primitives.Slot  `json:"head_slot"`
+	HeadRoot       string           `json:"head_root"`
+	FinalizedEpoch primitives.Epoch `json:"finalized_epoch"`
+	FinalizedRoot  string           `json:"finalized_root"`
+}
+
+// incident is an entry of the incident log.
+type incident struct {
+	Time    time.Time            `json:"time"`
+	Kind    string               `json:"kind"`
+	Slot    primitives.Slot      `json:"slot"`
+	Message string               `json:"message"`
+	Nodes   map[string]*nodeView `json:"nodes"`
+	// CommonAncestorSlot is the slot of the latest block all the diverging heads descend from, if known.
+	CommonAncestorSlot *primitives.Slot `json:"common_ancestor_slot,omitempty"`
+	// ReorgDepth is the number of slots between the old head and the common ancestor of the old and new heads.
+	ReorgDepth *uint64 `json:"reorg_depth,omitempty"`
+}
+
+// incidentLog writes incidents as JSON, one per line.
+type incidentLog struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// openIncidentLog opens the incident log at the path for appending, or writes to stdout if the path is empty.
+func openIncidentLog(path string) (*incidentLog, func() error, error) {
+	if path == "" {
+		return &incidentLog{w: os.Stdout}, func() error { return nil }, nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600) // #nosec G304
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not open incident log")
+	}
+	return &incidentLog{w: f}, f.Close, nil
+}
+
+func (l *incidentLog) write(i *incident) error {
+	b, err := json.Marshal(i)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.w.Write(append(b, '\n'))
+	return err
+}
@@ -0,0 +1,181 @@
+/**
+ * Fork and finality watcher
+ *
+ * Follows the event streams of several beacon nodes over the beacon API, merges the blocks they report into one
+ * block tree, and raises an incident when their heads differ for more than --divergence-slots slots, when they
+ * finalize conflicting checkpoints, or when one of them reorgs deeper than --reorg-depth slots. Incidents are
+ * appended as JSON lines to --incident-log, and metrics are served on --metrics-port.
+ *
+ * Example: forkwatcher --beacon-node a=http://127.0.0.1:3500 --beacon-node b=http://127.0.0.1:3501 --incident-log incidents.json
+ */
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+	"github.com/theQRL/qrysm/v4/api/client"
+	"github.com/theQRL/qrysm/v4/api/client/beacon"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/time/slots"
+)
+
+var log = logrus.WithField("prefix", "forkwatcher")
+
+type beaconNodes []string
+
+func (_ *beaconNodes) String() string {
+	return "beacon API endpoints"
+}
+
+// Set adds a beacon node to the list.
+func (b *beaconNodes) Set(value string) error {
+	*b = append(*b, value)
+	return nil
+}
+
+var (
+	divergenceSlots = flag.Uint64("divergence-slots", 2, "Number of slots the heads of the beacon nodes may differ for before it is an incident")
+	reorgDepth      = flag.Uint64("reorg-depth", 2, "Depth in slots a reorg may have before it is an incident")
+	maxFetchDepth   = flag.Int("max-fetch-depth", 64, "Number of ancestors of a head fetched at most to link it to the block tree")
+	incidentLogPath = flag.String("incident-log", "", "File the incidents are appended to as JSON lines, stdout if empty")
+	metricsPort     = flag.Int("metrics-port", 8085, "Port to serve /metrics on")
+	chainConfigFile = flag.String("chain-config-file", "", "Path to the chain config of the network, if not mainnet")
+	timeout         = flag.Duration("timeout", 10*time.Second, "Timeout of the requests to the beacon nodes")
+)
+
+func main() {
+	var endpoints beaconNodes
+	flag.Var(&endpoints, "beacon-node", "Beacon API endpoint of a beacon node to watch, as [name=]url. Repeat for every node")
+	flag.Parse()
+
+	if len(endpoints) < 2 {
+		log.Fatal("Please specify at least two --beacon-node endpoints")
+	}
+	if *chainConfigFile != "" {
+		if err := params.LoadChainConfigFile(*chainConfigFile, nil); err != nil {
+			log.WithError(err).Fatal("Could not load chain config file")
+		}
+	}
+
+	incidents, closeIncidents, err := openIncidentLog(*incidentLogPath)
+	if err != nil {
+		log.WithError(err).Fatal("Could not open incident log")
+	}
+	defer func() {
+		if err := closeIncidents(); err != nil {
+			log.WithError(err).Error("Could not close incident log")
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	clients := make(map[string]*beacon.Client, len(endpoints))
+	var names []string
+	for _, e := range endpoints {
+		name, host, err := parseEndpoint(e)
+		if err != nil {
+			log.WithError(err).Fatal("Invalid --beacon-node")
+		}
+		if _, ok := clients[name]; ok {
+			log.Fatalf("Beacon node name %s is used twice", name)
+		}
+		c, err := beacon.NewClient(host, client.WithTimeout(*timeout))
+		if err != nil {
+			log.WithError(err).Fatalf("Could not create client for %s", host)
+		}
+		clients[name] = c
+		names = append(names, name)
+	}
+
+	genesisTime, err := genesisTime(ctx, clients[names[0]])
+	if err != nil {
+		log.WithError(err).Fatal("Could not get genesis time")
+	}
+	w := newWatcher(watcherConfig{
+		divergenceSlots: primitives.Slot(*divergenceSlots),
+		reorgDepth:      *reorgDepth,
+		maxFetchDepth:   *maxFetchDepth,
+		reconnectDelay:  time.Duration(params.BeaconConfig().SecondsPerSlot) * time.Second,
+	}, incidents, func() primitives.Slot {
+		return slots.CurrentSlot(uint64(genesisTime.Unix()))
+	})
+	for _, name := range names {
+		go w.run(ctx, w.addNode(name, clients[name]))
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%d", *metricsPort),
+		ReadHeaderTimeout: 3 * time.Second,
+		Handler:           mux,
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Fatal("Failed to start metrics server")
+		}
+	}()
+
+	log.WithField("nodes", names).Info("Watching beacon nodes")
+	ticker := slots.NewSlotTicker(genesisTime, params.BeaconConfig().SecondsPerSlot)
+	defer ticker.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := srv.Close(); err != nil {
+				log.WithError(err).Error("Could not close metrics server")
+			}
+			return
+		case <-ticker.C():
+			w.onSlot()
+		}
+	}
+}
+
+// parseEndpoint splits a --beacon-node value into the name of the node and its URL. The name defaults to the
+// host of the URL.
+func parseEndpoint(e string) (string, string, error) {
+	name, host, named := strings.Cut(e, "=")
+	if !named {
+		host = e
+	}
+	u, err := url.Parse(host)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Host == "" {
+		return "", "", fmt.Errorf("%s is not a URL", host)
+	}
+	if !named {
+		name = u.Host
+	}
+	return name, host, nil
+}
+
+func genesisTime(ctx context.Context, c *beacon.Client) (time.Time, error) {
+	g, err := c.GetGenesis(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if g.Data == nil {
+		return time.Time{}, fmt.Errorf("no genesis in response")
+	}
+	sec, err := strconv.ParseInt(g.Data.GenesisTime, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0), nil
+}
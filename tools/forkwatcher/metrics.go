@@ -0,0 +1,42 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	nodeUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "forkwatcher_node_up",
+		Help: "1 if the event stream of the beacon node is connected, 0 otherwise",
+	}, []string{"node"})
+	nodeHeadSlot = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "forkwatcher_node_head_slot",
+		Help: "Slot of the head of the beacon node",
+	}, []string{"node"})
+	nodeFinalizedEpoch = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "forkwatcher_node_finalized_epoch",
+		Help: "Finalized epoch of the beacon node",
+	}, []string{"node"})
+	distinctHeads = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "forkwatcher_distinct_heads",
+		Help: "Number of distinct heads among the connected beacon nodes",
+	})
+	headDivergenceSlots = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "forkwatcher_divergence_slots",
+		Help: "Number of slots the heads of the beacon nodes have been differing for, 0 if they agree",
+	})
+	reorgDepths = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "forkwatcher_reorg_depth",
+		Help:    "Depth in slots of the reorgs of the beacon nodes",
+		Buckets: []float64{1, 2, 3, 4, 8, 16, 32, 64},
+	}, []string{"node"})
+	incidentsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "forkwatcher_incidents_total",
+		Help: "Number of incidents raised, by kind",
+	}, []string{"kind"})
+	blockTreeSize = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "forkwatcher_block_tree_size",
+		Help: "Number of blocks in the merged block tree",
+	})
+)
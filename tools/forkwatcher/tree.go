@@ -0,0 +1,92 @@
+package main
+
+import (
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+)
+
+// blockNode is a block of the merged block tree.
+type blockNode struct {
+	root   [32]byte
+	parent [32]byte
+	slot   primitives.Slot
+	// seenBy are the beacon nodes that reported the block as their head or as an ancestor of it.
+	seenBy map[string]bool
+}
+
+// blockTree is the tree of the blocks the watched beacon nodes reported, merged across nodes. Blocks whose
+// parent is unknown are kept as roots of their own subtree, so the tree is a forest until the missing blocks
+// are fetched. It is not safe for concurrent use.
+type blockTree struct {
+	nodes map[[32]byte]*blockNode
+}
+
+func newBlockTree() *blockTree {
+	return &blockTree{nodes: make(map[[32]byte]*blockNode)}
+}
+
+// insert adds the block to the tree, or records that the beacon node saw it if it is already there.
+func (t *blockTree) insert(root, parent [32]byte, slot primitives.Slot, seenBy string) {
+	n, ok := t.nodes[root]
+	if !ok {
+		n = &blockNode{root: root, parent: parent, slot: slot, seenBy: make(map[string]bool)}
+		t.nodes[root] = n
+	}
+	n.seenBy[seenBy] = true
+}
+
+func (t *blockTree) block(root [32]byte) (*blockNode, bool) {
+	n, ok := t.nodes[root]
+	return n, ok
+}
+
+// commonAncestor returns the latest block that is an ancestor of both blocks, or a block itself if it is an
+// ancestor of the other. It returns false if the tree does not link the blocks to a common ancestor.
+func (t *blockTree) commonAncestor(a, b [32]byte) (*blockNode, bool) {
+	na, ok := t.nodes[a]
+	if !ok {
+		return nil, false
+	}
+	nb, ok := t.nodes[b]
+	if !ok {
+		return nil, false
+	}
+	for na.root != nb.root {
+		// Walk back from the later block, both when the slots differ and when they are equal.
+		if na.slot >= nb.slot {
+			if na, ok = t.nodes[na.parent]; !ok {
+				return nil, false
+			}
+		} else {
+			if nb, ok = t.nodes[nb.parent]; !ok {
+				return nil, false
+			}
+		}
+	}
+	return na, true
+}
+
+// isAncestor returns whether the ancestor block is the descendant block or one of its ancestors, and whether
+// the tree links them.
+func (t *blockTree) isAncestor(ancestor, descendant [32]byte) (isAncestor bool, known bool) {
+	c, ok := t.commonAncestor(ancestor, descendant)
+	if !ok {
+		return false, false
+	}
+	return c.root == ancestor, true
+}
+
+// prune removes the blocks before the slot, which are of no use once every beacon node finalized past it.
+func (t *blockTree) prune(slot primitives.Slot) int {
+	pruned := 0
+	for root, n := range t.nodes {
+		if n.slot < slot {
+			delete(t.nodes, root)
+			pruned++
+		}
+	}
+	return pruned
+}
+
+func (t *blockTree) size() int {
+	return len(t.nodes)
+}
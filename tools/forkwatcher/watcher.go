@@ -0,0 +1,480 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/theQRL/go-zond/common/hexutil"
+	"github.com/theQRL/qrysm/v4/api/client/beacon"
+	"github.com/theQRL/qrysm/v4/beacon-chain/rpc/apimiddleware"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	prysmTime "github.com/theQRL/qrysm/v4/time"
+)
+
+// beaconNode is the part of the beacon API client the watcher uses.
+type beaconNode interface {
+	StreamEvents(ctx context.Context, topics []string, handler func(*beacon.Event) error) error
+	GetBlockHeader(ctx context.Context, blockId beacon.StateOrBlockId) (*apimiddleware.BlockHeaderResponseJson, error)
+	GetFinalityCheckpoints(ctx context.Context, stateId beacon.StateOrBlockId) (*apimiddleware.StateFinalityCheckpointResponseJson, error)
+}
+
+var eventTopics = []string{"head", "finalized_checkpoint"}
+
+type watcherConfig struct {
+	// divergenceSlots is the number of slots the heads of the nodes may differ for before it is an incident.
+	divergenceSlots primitives.Slot
+	// reorgDepth is the depth in slots a reorg may have before it is an incident.
+	reorgDepth uint64
+	// maxFetchDepth is the number of ancestors of a new head fetched at most to link it to the block tree.
+	maxFetchDepth int
+	// reconnectDelay is the time waited before subscribing again to the events of a node whose stream ended.
+	reconnectDelay time.Duration
+}
+
+// nodeState is what the watcher knows of a beacon node.
+type nodeState struct {
+	name           string
+	client         beaconNode
+	up             bool
+	hasHead        bool
+	headRoot       [32]byte
+	headSlot       primitives.Slot
+	hasFinalized   bool
+	finalizedEpoch primitives.Epoch
+	finalizedRoot  [32]byte
+}
+
+// watcher follows the heads and finalized checkpoints of several beacon nodes, merges the blocks they report
+// into one block tree, and raises an incident when the heads differ for too long, when the nodes disagree on
+// finality, or when a node reorgs too deep.
+type watcher struct {
+	cfg         watcherConfig
+	incidents   *incidentLog
+	currentSlot func() primitives.Slot
+
+	mu    sync.Mutex
+	nodes []*nodeState
+	tree  *blockTree
+	// pruneSlot is the slot before which blocks have been pruned from the tree.
+	pruneSlot primitives.Slot
+	// divergingSince is the slot the heads started differing at, if they currently differ.
+	divergingSince     *primitives.Slot
+	divergenceReported bool
+	// finalityReported are the pairs of finalized roots which have already been reported as conflicting.
+	finalityReported map[[64]byte]bool
+}
+
+func newWatcher(cfg watcherConfig, incidents *incidentLog, currentSlot func() primitives.Slot) *watcher {
+	return &watcher{
+		cfg:              cfg,
+		incidents:        incidents,
+		currentSlot:      currentSlot,
+		tree:             newBlockTree(),
+		finalityReported: make(map[[64]byte]bool),
+	}
+}
+
+func (w *watcher) addNode(name string, client beaconNode) *nodeState {
+	n := &nodeState{name: name, client: client}
+	w.nodes = append(w.nodes, n)
+	nodeUp.WithLabelValues(name).Set(0)
+	return n
+}
+
+// run follows the node until the context is canceled, subscribing again to its events whenever the stream ends.
+func (w *watcher) run(ctx context.Context, n *nodeState) {
+	for {
+		err := w.follow(ctx, n)
+		w.setUp(n, false)
+		if ctx.Err() != nil {
+			return
+		}
+		log.WithError(err).WithField("node", n.name).Warn("Lost event stream of beacon node, reconnecting")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.cfg.reconnectDelay):
+		}
+	}
+}
+
+// follow reads the current head and finalized checkpoint of the node, then applies its events as they come.
+func (w *watcher) follow(ctx context.Context, n *nodeState) error {
+	header, err := n.client.GetBlockHeader(ctx, beacon.IdHead)
+	if err != nil {
+		return err
+	}
+	root, slot, _, err := parseHeader(header)
+	if err != nil {
+		return err
+	}
+	checkpoints, err := n.client.GetFinalityCheckpoints(ctx, beacon.IdHead)
+	if err != nil {
+		return err
+	}
+	if checkpoints.Data == nil || checkpoints.Data.Finalized == nil {
+		return errors.New("no finalized checkpoint in response")
+	}
+	epoch, finalizedRoot, err := parseCheckpoint(checkpoints.Data.Finalized.Epoch, checkpoints.Data.Finalized.Root)
+	if err != nil {
+		return err
+	}
+	w.setUp(n, true)
+	if err := w.onHead(ctx, n, root, slot); err != nil {
+		return err
+	}
+	w.onFinalized(n, epoch, finalizedRoot)
+
+	return n.client.StreamEvents(ctx, eventTopics, func(e *beacon.Event) error {
+		return w.handleEvent(ctx, n, e)
+	})
+}
+
+func (w *watcher) handleEvent(ctx context.Context, n *nodeState, e *beacon.Event) error {
+	data, err := e.Decode()
+	if err != nil {
+		return err
+	}
+	switch d := data.(type) {
+	case *apimiddleware.EventHeadJson:
+		slot, err := strconv.ParseUint(d.Slot, 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "could not parse head slot")
+		}
+		root, err := parseRoot(d.Block)
+		if err != nil {
+			return err
+		}
+		return w.onHead(ctx, n, root, primitives.Slot(slot))
+	case *apimiddleware.EventFinalizedCheckpointJson:
+		epoch, root, err := parseCheckpoint(d.Epoch, d.Block)
+		if err != nil {
+			return err
+		}
+		w.onFinalized(n, epoch, root)
+	}
+	return nil
+}
+
+func (w *watcher) setUp(n *nodeState, up bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n.up = up
+	if up {
+		nodeUp.WithLabelValues(n.name).Set(1)
+	} else {
+		nodeUp.WithLabelValues(n.name).Set(0)
+	}
+	w.checkDivergence()
+}
+
+// onSlot checks whether the heads have been differing for too long, in case no node reported a new head.
+func (w *watcher) onSlot() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.checkDivergence()
+}
+
+// onHead links the new head of the node to the block tree, fetching its missing ancestors from the node, and
+// checks whether the node reorged and whether the heads of the nodes differ.
+func (w *watcher) onHead(ctx context.Context, n *nodeState, root [32]byte, slot primitives.Slot) error {
+	if err := w.fetchAncestors(ctx, n, root, slot); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	hadHead, oldRoot, oldSlot := n.hasHead, n.headRoot, n.headSlot
+	n.hasHead, n.headRoot, n.headSlot = true, root, slot
+	nodeHeadSlot.WithLabelValues(n.name).Set(float64(slot))
+	if hadHead && oldRoot != root {
+		w.checkReorg(n, oldRoot, oldSlot, root)
+	}
+	w.checkDivergence()
+	return nil
+}
+
+// fetchAncestors adds the block and the ancestors the tree is missing, up to maxFetchDepth of them.
+func (w *watcher) fetchAncestors(ctx context.Context, n *nodeState, root [32]byte, slot primitives.Slot) error {
+	for i := 0; i < w.cfg.maxFetchDepth; i++ {
+		w.mu.Lock()
+		b, known := w.tree.block(root)
+		if known {
+			b.seenBy[n.name] = true
+		}
+		pruned := slot < w.pruneSlot
+		w.mu.Unlock()
+		if known || pruned {
+			return nil
+		}
+
+		header, err := n.client.GetBlockHeader(ctx, beacon.IdFromRoot(root))
+		if err != nil {
+			return errors.Wrapf(err, "could not get header of block %#x", root)
+		}
+		headerRoot, headerSlot, parent, err := parseHeader(header)
+		if err != nil {
+			return err
+		}
+		if headerRoot != root {
+			return fmt.Errorf("requested header of block %#x, got %#x", root, headerRoot)
+		}
+		w.mu.Lock()
+		w.tree.insert(root, parent, headerSlot, n.name)
+		blockTreeSize.Set(float64(w.tree.size()))
+		w.mu.Unlock()
+		if headerSlot == 0 {
+			return nil
+		}
+		root = parent
+		// The parent is at an earlier slot, which is all that matters to stop at the pruned blocks.
+		slot = headerSlot - 1
+	}
+	log.WithFields(logrus.Fields{
+		"node": n.name,
+		"root": fmt.Sprintf("%#x", root),
+	}).Debug("Stopped fetching ancestors of head before linking it to the block tree")
+	return nil
+}
+
+// checkReorg raises an incident if the node moved its head from a block which is not an ancestor of the new one,
+// and the common ancestor of the two is more than reorgDepth slots before the old head.
+func (w *watcher) checkReorg(n *nodeState, oldRoot [32]byte, oldSlot primitives.Slot, newRoot [32]byte) {
+	ancestor, known := w.tree.commonAncestor(oldRoot, newRoot)
+	if !known {
+		log.WithFields(logrus.Fields{
+			"node":    n.name,
+			"oldHead": fmt.Sprintf("%#x", oldRoot),
+			"newHead": fmt.Sprintf("%#x", newRoot),
+		}).Debug("Could not link old and new head in the block tree")
+		return
+	}
+	if ancestor.root == oldRoot {
+		return
+	}
+	depth := uint64(oldSlot - ancestor.slot)
+	reorgDepths.WithLabelValues(n.name).Observe(float64(depth))
+	log.WithFields(logrus.Fields{
+		"node":    n.name,
+		"depth":   depth,
+		"oldHead": fmt.Sprintf("%#x", oldRoot),
+		"newHead": fmt.Sprintf("%#x", newRoot),
+	}).Info("Beacon node reorged")
+	if depth <= w.cfg.reorgDepth {
+		return
+	}
+	ancestorSlot := ancestor.slot
+	w.raise(&incident{
+		Kind:               deepReorg,
+		Slot:               oldSlot,
+		Message:            fmt.Sprintf("%s reorged %d slots deep, more than %d", n.name, depth, w.cfg.reorgDepth),
+		CommonAncestorSlot: &ancestorSlot,
+		ReorgDepth:         &depth,
+	})
+}
+
+// checkDivergence raises an incident once the connected nodes have had different heads for more than
+// divergenceSlots slots. A node lagging behind the others counts as having a different head.
+func (w *watcher) checkDivergence() {
+	heads := make(map[[32]byte]bool)
+	for _, n := range w.nodes {
+		if n.up && n.hasHead {
+			heads[n.headRoot] = true
+		}
+	}
+	distinctHeads.Set(float64(len(heads)))
+	if len(heads) <= 1 {
+		if w.divergingSince != nil {
+			log.WithField("slots", w.currentSlot()-*w.divergingSince).Info("Heads of beacon nodes agree again")
+		}
+		w.divergingSince, w.divergenceReported = nil, false
+		headDivergenceSlots.Set(0)
+		return
+	}
+	current := w.currentSlot()
+	if w.divergingSince == nil {
+		w.divergingSince = &current
+	}
+	var diverging primitives.Slot
+	if current > *w.divergingSince {
+		diverging = current - *w.divergingSince
+	}
+	headDivergenceSlots.Set(float64(diverging))
+	if diverging <= w.cfg.divergenceSlots || w.divergenceReported {
+		return
+	}
+	w.divergenceReported = true
+	i := &incident{
+		Kind:    headDivergence,
+		Slot:    current,
+		Message: fmt.Sprintf("%d distinct heads for %d slots, more than %d", len(heads), diverging, w.cfg.divergenceSlots),
+	}
+	if ancestor, ok := w.commonAncestorOf(heads); ok {
+		i.CommonAncestorSlot = &ancestor
+	}
+	w.raise(i)
+}
+
+// commonAncestorOf returns the slot of the latest block all the heads descend from, if the tree links them.
+func (w *watcher) commonAncestorOf(heads map[[32]byte]bool) (primitives.Slot, bool) {
+	var ancestor *blockNode
+	for h := range heads {
+		var ok bool
+		if ancestor == nil {
+			ancestor, ok = w.tree.block(h)
+		} else {
+			ancestor, ok = w.tree.commonAncestor(ancestor.root, h)
+		}
+		if !ok {
+			return 0, false
+		}
+	}
+	if ancestor == nil {
+		return 0, false
+	}
+	return ancestor.slot, true
+}
+
+// onFinalized records the finalized checkpoint of the node, and raises an incident if another node finalized
+// a different block at the same epoch, or a block the checkpoint does not descend from or lead to.
+func (w *watcher) onFinalized(n *nodeState, epoch primitives.Epoch, root [32]byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if n.hasFinalized && n.finalizedEpoch == epoch && n.finalizedRoot == root {
+		return
+	}
+	n.hasFinalized, n.finalizedEpoch, n.finalizedRoot = true, epoch, root
+	nodeFinalizedEpoch.WithLabelValues(n.name).Set(float64(epoch))
+
+	for _, other := range w.nodes {
+		if other == n || !other.hasFinalized || other.finalizedRoot == root {
+			continue
+		}
+		conflicting := other.finalizedEpoch == epoch
+		if !conflicting {
+			low, high := other.finalizedRoot, root
+			if other.finalizedEpoch > epoch {
+				low, high = root, other.finalizedRoot
+			}
+			isAncestor, known := w.tree.isAncestor(low, high)
+			conflicting = known && !isAncestor
+		}
+		if !conflicting {
+			continue
+		}
+		var pair [64]byte
+		a, b := root, other.finalizedRoot
+		if string(a[:]) > string(b[:]) {
+			a, b = b, a
+		}
+		copy(pair[:32], a[:])
+		copy(pair[32:], b[:])
+		if w.finalityReported[pair] {
+			continue
+		}
+		w.finalityReported[pair] = true
+		w.raise(&incident{
+			Kind: finalityDisagreement,
+			Slot: w.currentSlot(),
+			Message: fmt.Sprintf("%s finalized %#x at epoch %d, %s finalized %#x at epoch %d, which are on different chains",
+				n.name, root, epoch, other.name, other.finalizedRoot, other.finalizedEpoch),
+		})
+	}
+	w.pruneFinalized()
+}
+
+// pruneFinalized removes the blocks before the epoch before the lowest finalized epoch of the nodes.
+func (w *watcher) pruneFinalized() {
+	var lowest *primitives.Epoch
+	for _, n := range w.nodes {
+		if !n.hasFinalized {
+			return
+		}
+		if lowest == nil || n.finalizedEpoch < *lowest {
+			e := n.finalizedEpoch
+			lowest = &e
+		}
+	}
+	if lowest == nil || *lowest < 2 {
+		return
+	}
+	slot := primitives.Slot(*lowest-1) * params.BeaconConfig().SlotsPerEpoch
+	if slot <= w.pruneSlot {
+		return
+	}
+	w.pruneSlot = slot
+	if pruned := w.tree.prune(slot); pruned > 0 {
+		log.WithFields(logrus.Fields{"slot": slot, "blocks": pruned}).Debug("Pruned block tree")
+	}
+	blockTreeSize.Set(float64(w.tree.size()))
+}
+
+// raise logs and records the incident, along with what every node reported at the time.
+func (w *watcher) raise(i *incident) {
+	i.Time = prysmTime.Now()
+	i.Nodes = make(map[string]*nodeView, len(w.nodes))
+	for _, n := range w.nodes {
+		if !n.up {
+			continue
+		}
+		i.Nodes[n.name] = &nodeView{
+			HeadSlot:       n.headSlot,
+			HeadRoot:       fmt.Sprintf("%#x", n.headRoot),
+			FinalizedEpoch: n.finalizedEpoch,
+			FinalizedRoot:  fmt.Sprintf("%#x", n.finalizedRoot),
+		}
+	}
+	incidentsTotal.WithLabelValues(i.Kind).Inc()
+	names := make([]string, 0, len(i.Nodes))
+	for name := range i.Nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	log.WithFields(logrus.Fields{"kind": i.Kind, "nodes": names}).Warn(i.Message)
+	if err := w.incidents.write(i); err != nil {
+		log.WithError(err).Error("Could not write incident log")
+	}
+}
+
+func parseHeader(h *apimiddleware.BlockHeaderResponseJson) (root [32]byte, slot primitives.Slot, parent [32]byte, err error) {
+	if h == nil || h.Data == nil || h.Data.Header == nil || h.Data.Header.Message == nil {
+		return root, 0, parent, errors.New("empty block header response")
+	}
+	if root, err = parseRoot(h.Data.Root); err != nil {
+		return
+	}
+	s, err := strconv.ParseUint(h.Data.Header.Message.Slot, 10, 64)
+	if err != nil {
+		return root, 0, parent, errors.Wrap(err, "could not parse block slot")
+	}
+	parent, err = parseRoot(h.Data.Header.Message.ParentRoot)
+	return root, primitives.Slot(s), parent, err
+}
+
+func parseCheckpoint(epoch, root string) (primitives.Epoch, [32]byte, error) {
+	e, err := strconv.ParseUint(epoch, 10, 64)
+	if err != nil {
+		return 0, [32]byte{}, errors.Wrap(err, "could not parse checkpoint epoch")
+	}
+	r, err := parseRoot(root)
+	return primitives.Epoch(e), r, err
+}
+
+func parseRoot(s string) ([32]byte, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return [32]byte{}, errors.Wrapf(err, "could not decode root %s", s)
+	}
+	if len(b) != 32 {
+		return [32]byte{}, fmt.Errorf("root %s is %d bytes long, wanted 32", s, len(b))
+	}
+	return bytesutil.ToBytes32(b), nil
+}
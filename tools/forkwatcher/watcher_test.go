@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/theQRL/qrysm/v4/api/client/beacon"
+	"github.com/theQRL/qrysm/v4/beacon-chain/rpc/apimiddleware"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+)
+
+// fakeNode serves the headers of the blocks of a test chain.
+type fakeNode struct {
+	headers map[string]*apimiddleware.BlockHeaderResponseJson
+}
+
+func (f *fakeNode) StreamEvents(context.Context, []string, func(*beacon.Event) error) error {
+	return nil
+}
+
+func (f *fakeNode) GetBlockHeader(_ context.Context, id beacon.StateOrBlockId) (*apimiddleware.BlockHeaderResponseJson, error) {
+	h, ok := f.headers[string(id)]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return h, nil
+}
+
+func (f *fakeNode) GetFinalityCheckpoints(context.Context, beacon.StateOrBlockId) (*apimiddleware.StateFinalityCheckpointResponseJson, error) {
+	return nil, errors.New("not implemented")
+}
+
+func testRoot(b byte) [32]byte {
+	return [32]byte{b}
+}
+
+// chain is a fakeNode serving the blocks, given as root -> parent and slot.
+func chain(blocks map[byte]struct {
+	parent byte
+	slot   primitives.Slot
+}) *fakeNode {
+	f := &fakeNode{headers: make(map[string]*apimiddleware.BlockHeaderResponseJson)}
+	for root, b := range blocks {
+		r, p := testRoot(root), testRoot(b.parent)
+		f.headers[string(beacon.IdFromRoot(r))] = &apimiddleware.BlockHeaderResponseJson{
+			Data: &apimiddleware.BlockHeaderContainerJson{
+				Root: fmt.Sprintf("%#x", r),
+				Header: &apimiddleware.BeaconBlockHeaderContainerJson{
+					Message: &apimiddleware.BeaconBlockHeaderJson{
+						Slot:       fmt.Sprintf("%d", b.slot),
+						ParentRoot: fmt.Sprintf("%#x", p),
+					},
+				},
+			},
+		}
+	}
+	return f
+}
+
+// testChain is genesis 0 <- 1 <- 2 <- 3, with a fork 0 <- 11 <- 14 and a fork 2 <- 13.
+func testChain() *fakeNode {
+	return chain(map[byte]struct {
+		parent byte
+		slot   primitives.Slot
+	}{
+		0:  {0, 0},
+		1:  {0, 1},
+		2:  {1, 2},
+		3:  {2, 3},
+		11: {0, 1},
+		14: {11, 4},
+		13: {2, 3},
+	})
+}
+
+func setupWatcher(t *testing.T, slot *primitives.Slot, names ...string) (*watcher, []*nodeState, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	w := newWatcher(watcherConfig{divergenceSlots: 2, reorgDepth: 2, maxFetchDepth: 64}, &incidentLog{w: buf}, func() primitives.Slot {
+		return *slot
+	})
+	var nodes []*nodeState
+	for _, name := range names {
+		n := w.addNode(name, testChain())
+		w.setUp(n, true)
+		nodes = append(nodes, n)
+	}
+	return w, nodes, buf
+}
+
+func readIncidents(t *testing.T, buf *bytes.Buffer) []*incident {
+	var incidents []*incident
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		i := &incident{}
+		require.NoError(t, json.Unmarshal([]byte(line), i))
+		incidents = append(incidents, i)
+	}
+	return incidents
+}
+
+func TestWatcher_Reorg(t *testing.T) {
+	ctx := context.Background()
+	slot := primitives.Slot(4)
+	w, nodes, buf := setupWatcher(t, &slot, "a")
+
+	require.NoError(t, w.onHead(ctx, nodes[0], testRoot(3), 3))
+	// 3 -> 13 reorgs 1 slot deep, to their common ancestor 2.
+	require.NoError(t, w.onHead(ctx, nodes[0], testRoot(13), 3))
+	assert.Equal(t, 0, len(readIncidents(t, buf)))
+
+	// 13 -> 14 reorgs 3 slots deep, to genesis.
+	require.NoError(t, w.onHead(ctx, nodes[0], testRoot(14), 4))
+	incidents := readIncidents(t, buf)
+	require.Equal(t, 1, len(incidents))
+	assert.Equal(t, deepReorg, incidents[0].Kind)
+	assert.Equal(t, uint64(3), *incidents[0].ReorgDepth)
+	assert.Equal(t, primitives.Slot(0), *incidents[0].CommonAncestorSlot)
+	assert.Equal(t, fmt.Sprintf("%#x", testRoot(14)), incidents[0].Nodes["a"].HeadRoot)
+}
+
+func TestWatcher_Divergence(t *testing.T) {
+	ctx := context.Background()
+	slot := primitives.Slot(3)
+	w, nodes, buf := setupWatcher(t, &slot, "a", "b")
+
+	require.NoError(t, w.onHead(ctx, nodes[0], testRoot(3), 3))
+	require.NoError(t, w.onHead(ctx, nodes[1], testRoot(13), 3))
+	slot = 5
+	w.onSlot()
+	assert.Equal(t, 0, len(readIncidents(t, buf)), "heads may differ for 2 slots")
+
+	slot = 6
+	w.onSlot()
+	incidents := readIncidents(t, buf)
+	require.Equal(t, 1, len(incidents))
+	assert.Equal(t, headDivergence, incidents[0].Kind)
+	assert.Equal(t, primitives.Slot(2), *incidents[0].CommonAncestorSlot)
+	assert.Equal(t, 2, len(incidents[0].Nodes))
+
+	slot = 7
+	w.onSlot()
+	assert.Equal(t, 1, len(readIncidents(t, buf)), "a divergence is reported once")
+
+	// The heads agree again, then differ again.
+	require.NoError(t, w.onHead(ctx, nodes[1], testRoot(3), 3))
+	require.NoError(t, w.onHead(ctx, nodes[1], testRoot(13), 3))
+	slot = 10
+	w.onSlot()
+	assert.Equal(t, 2, len(readIncidents(t, buf)))
+}
+
+func TestWatcher_DivergenceIgnoresDisconnectedNodes(t *testing.T) {
+	ctx := context.Background()
+	slot := primitives.Slot(3)
+	w, nodes, buf := setupWatcher(t, &slot, "a", "b")
+
+	require.NoError(t, w.onHead(ctx, nodes[0], testRoot(3), 3))
+	require.NoError(t, w.onHead(ctx, nodes[1], testRoot(13), 3))
+	w.setUp(nodes[1], false)
+	slot = 10
+	w.onSlot()
+	assert.Equal(t, 0, len(readIncidents(t, buf)))
+}
+
+func TestWatcher_FinalityDisagreement(t *testing.T) {
+	ctx := context.Background()
+	slot := primitives.Slot(100)
+	w, nodes, buf := setupWatcher(t, &slot, "a", "b", "c")
+	require.NoError(t, w.onHead(ctx, nodes[0], testRoot(3), 3))
+	require.NoError(t, w.onHead(ctx, nodes[1], testRoot(14), 4))
+
+	// Finalizing an ancestor of the other checkpoint is no disagreement.
+	w.onFinalized(nodes[0], 1, testRoot(2))
+	w.onFinalized(nodes[2], 0, testRoot(1))
+	assert.Equal(t, 0, len(readIncidents(t, buf)))
+
+	// 11 is not an ancestor of 2.
+	w.onFinalized(nodes[1], 0, testRoot(11))
+	incidents := readIncidents(t, buf)
+	require.Equal(t, 2, len(incidents))
+	for _, i := range incidents {
+		assert.Equal(t, finalityDisagreement, i.Kind)
+	}
+
+	// The same checkpoints are not reported again.
+	w.onFinalized(nodes[1], 1, testRoot(2))
+	w.onFinalized(nodes[1], 0, testRoot(11))
+	assert.Equal(t, 2, len(readIncidents(t, buf)))
+}
+
+func TestBlockTree(t *testing.T) {
+	tree := newBlockTree()
+	tree.insert(testRoot(1), testRoot(0), 1, "a")
+	tree.insert(testRoot(2), testRoot(1), 2, "a")
+	tree.insert(testRoot(3), testRoot(1), 3, "b")
+	tree.insert(testRoot(5), testRoot(4), 5, "b")
+
+	c, ok := tree.commonAncestor(testRoot(2), testRoot(3))
+	require.Equal(t, true, ok)
+	assert.Equal(t, testRoot(1), c.root)
+
+	isAncestor, known := tree.isAncestor(testRoot(1), testRoot(3))
+	assert.Equal(t, true, known)
+	assert.Equal(t, true, isAncestor)
+	isAncestor, known = tree.isAncestor(testRoot(2), testRoot(3))
+	assert.Equal(t, true, known)
+	assert.Equal(t, false, isAncestor)
+	_, known = tree.isAncestor(testRoot(2), testRoot(5))
+	assert.Equal(t, false, known, "5 is not linked to the tree")
+
+	assert.Equal(t, 2, tree.prune(3))
+	assert.Equal(t, 2, tree.size())
+}
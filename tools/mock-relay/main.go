@@ -0,0 +1,133 @@
+/*
+*
+  - Mock relay
+    *
+  - Serves the builder API on a local port, so that beacon nodes started with --mev-relay-endpoint
+  - can propose blinded blocks offline. Bids are built from the payloads of a mock execution
+  - engine and signed with a Dilithium builder key. Faults can be injected at startup with
+  - --fault, or at runtime by posting to /relay/v1/fault/{fault}.
+    *
+  - The mock engine ignores the payload attributes, so its payload is rewritten to build on the
+  - requested parent and given the hash of the rewritten execution block header. No execution
+  - client knows that block: the beacon nodes must use a mock execution engine too. See README.md.
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/theQRL/go-zond/common/hexutil"
+	mockExecution "github.com/theQRL/qrysm/v4/beacon-chain/execution/testing"
+	fieldparams "github.com/theQRL/qrysm/v4/config/fieldparams"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/crypto/dilithium"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	enginev1 "github.com/theQRL/qrysm/v4/proto/engine/v1"
+	"github.com/theQRL/qrysm/v4/tools/mock-relay/relay"
+)
+
+var (
+	host            = flag.String("host", "127.0.0.1", "Host to serve the builder API on.")
+	port            = flag.Int("port", 18550, "Port to serve the builder API on.")
+	builderKeySeed  = flag.String("builder-key-seed", "", "Hex encoded seed of the Dilithium builder key. A random key is used if empty.")
+	fault           = flag.String("fault", string(relay.FaultNone), "Fault to inject, one of "+faultNames()+".")
+	gvr             = flag.String("genesis-validators-root", "", "Hex encoded genesis validators root of the chain, used to verify the proposer signature of blinded blocks. Required.")
+	genesisTime     = flag.Int64("genesis-time", 0, "Genesis time of the chain as a unix timestamp, used to set the timestamp of the payloads.")
+	bidValue        = flag.Uint64("bid-value", 0, "Value of every bid in Gwei. Defaults to the value of the payload reported by the engine.")
+	timeoutDelay    = flag.Duration("timeout-delay", 3*time.Second, "Delay of the header responses when the timeout fault is injected.")
+	chainConfigFile = flag.String("chain-config-file", "", "Path to the chain config of the beacon nodes. Defaults to the mainnet config.")
+)
+
+func faultNames() string {
+	names := make([]string, len(relay.Faults))
+	for i, f := range relay.Faults {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage of %s:\n", os.Args[0])
+		flag.PrintDefaults()
+		fmt.Fprintln(flag.CommandLine.Output(), "\nThe payloads of the bids are rewritten to build on the requested parent, with block hashes no "+
+			"execution client knows. Use the relay with beacon nodes running against a mock execution engine only.")
+	}
+	flag.Parse()
+	if *chainConfigFile != "" {
+		if err := params.LoadChainConfigFile(*chainConfigFile, nil); err != nil {
+			logrus.WithError(err).Fatal("Could not load chain config")
+		}
+	}
+	f, err := relay.ParseFault(*fault)
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid fault")
+	}
+	root, err := hexutil.Decode(*gvr)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not decode genesis validators root")
+	}
+	opts := []relay.Option{
+		relay.WithGenesisValidatorsRoot(root),
+		relay.WithHost(*host),
+		relay.WithPort(*port),
+		relay.WithEngine(mockEngine()),
+		relay.WithFault(f),
+		relay.WithBidValue(*bidValue),
+		relay.WithTimeoutDelay(*timeoutDelay),
+	}
+	if *genesisTime != 0 {
+		opts = append(opts, relay.WithGenesisTime(time.Unix(*genesisTime, 0)))
+	}
+	if *builderKeySeed != "" {
+		seed, err := hexutil.Decode(*builderKeySeed)
+		if err != nil {
+			logrus.WithError(err).Fatal("Could not decode builder key seed")
+		}
+		key, err := dilithium.SecretKeyFromBytes(seed)
+		if err != nil {
+			logrus.WithError(err).Fatal("Could not create builder key")
+		}
+		opts = append(opts, relay.WithBuilderKey(key))
+	}
+	r, err := relay.New(opts...)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not create relay")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if err := r.Start(ctx); err != nil {
+		logrus.WithError(err).Fatal("Relay stopped")
+	}
+}
+
+// mockEngine returns an engine serving an empty capella payload, which the relay rebuilds on top
+// of the parent hash of every header request.
+func mockEngine() *mockExecution.EngineClient {
+	return &mockExecution.EngineClient{
+		PayloadIDBytes: &enginev1.PayloadIDBytes{1},
+		ExecutionPayloadCapella: &enginev1.ExecutionPayloadCapella{
+			ParentHash:    make([]byte, fieldparams.RootLength),
+			FeeRecipient:  make([]byte, fieldparams.FeeRecipientLength),
+			StateRoot:     make([]byte, fieldparams.RootLength),
+			ReceiptsRoot:  make([]byte, fieldparams.RootLength),
+			LogsBloom:     make([]byte, fieldparams.LogsBloomLength),
+			PrevRandao:    make([]byte, fieldparams.RootLength),
+			BaseFeePerGas: make([]byte, fieldparams.RootLength),
+			BlockHash:     bytesutil.PadTo([]byte("mock-relay"), fieldparams.RootLength),
+			GasLimit:      30000000,
+			Transactions:  make([][]byte, 0),
+			Withdrawals:   make([]*enginev1.Withdrawal, 0),
+		},
+		BlockValue: 1,
+	}
+}
@@ -0,0 +1,37 @@
+package relay
+
+import "github.com/pkg/errors"
+
+// Fault is a misbehavior the relay can be told to inject.
+type Fault string
+
+const (
+	// FaultNone makes the relay behave.
+	FaultNone Fault = "none"
+	// FaultWithhold makes the relay bid, but fail to reveal the payload of submitted blocks.
+	FaultWithhold Fault = "withhold"
+	// FaultInvalidSignature makes the relay sign its bids with an invalid signature.
+	FaultInvalidSignature Fault = "invalid-signature"
+	// FaultWrongParent makes the relay bid on a header which does not build on the requested parent.
+	FaultWrongParent Fault = "wrong-parent"
+	// FaultTimeout delays the header responses of the relay past the deadline of proposers.
+	FaultTimeout Fault = "timeout"
+	// FaultNoBid makes the relay answer header requests without a bid.
+	FaultNoBid Fault = "no-bid"
+)
+
+// Faults lists every fault the relay can inject.
+var Faults = []Fault{FaultNone, FaultWithhold, FaultInvalidSignature, FaultWrongParent, FaultTimeout, FaultNoBid}
+
+// ParseFault returns the fault with the given name.
+func ParseFault(name string) (Fault, error) {
+	if name == "" {
+		return FaultNone, nil
+	}
+	for _, f := range Faults {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+	return FaultNone, errors.Errorf("unknown fault %q", name)
+}
@@ -0,0 +1,112 @@
+package relay
+
+import (
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/theQRL/qrysm/v4/beacon-chain/execution"
+	"github.com/theQRL/qrysm/v4/crypto/dilithium"
+)
+
+type Option func(r *Relay) error
+
+// WithHost sets the relay server host.
+func WithHost(host string) Option {
+	return func(r *Relay) error {
+		r.cfg.host = host
+		return nil
+	}
+}
+
+// WithPort sets the relay server port.
+func WithPort(port int) Option {
+	return func(r *Relay) error {
+		r.cfg.port = port
+		return nil
+	}
+}
+
+// WithEngine sets the execution engine the payloads of the bids are taken from.
+func WithEngine(engine execution.EngineCaller) Option {
+	return func(r *Relay) error {
+		r.cfg.engine = engine
+		return nil
+	}
+}
+
+// WithBuilderKey sets the Dilithium key signing the bids. A random key is used by default.
+func WithBuilderKey(key dilithium.DilithiumKey) Option {
+	return func(r *Relay) error {
+		if key == nil {
+			return errors.New("nil builder key")
+		}
+		r.cfg.key = key
+		return nil
+	}
+}
+
+// WithGenesisTime sets the genesis time used to compute the timestamp of the payloads.
+func WithGenesisTime(t time.Time) Option {
+	return func(r *Relay) error {
+		r.cfg.genesisTime = t
+		return nil
+	}
+}
+
+// WithGenesisValidatorsRoot sets the genesis validators root of the chain, used to verify the
+// proposer signature of the submitted blinded blocks.
+func WithGenesisValidatorsRoot(root []byte) Option {
+	return func(r *Relay) error {
+		if len(root) != 32 {
+			return errors.Errorf("genesis validators root is %d bytes, expected 32", len(root))
+		}
+		r.cfg.gvr = root
+		return nil
+	}
+}
+
+// WithBidValue sets the value of every bid in Gwei, instead of the value of the payload
+// reported by the engine.
+func WithBidValue(gwei uint64) Option {
+	return func(r *Relay) error {
+		r.cfg.bidValueGwei = gwei
+		return nil
+	}
+}
+
+// WithFault injects a fault from the start.
+func WithFault(f Fault) Option {
+	return func(r *Relay) error {
+		r.fault = f
+		return nil
+	}
+}
+
+// WithTimeoutDelay sets how long header requests are delayed by FaultTimeout.
+func WithTimeoutDelay(d time.Duration) Option {
+	return func(r *Relay) error {
+		r.cfg.timeoutDelay = d
+		return nil
+	}
+}
+
+// WithLogger sets a custom logger for the relay.
+func WithLogger(l *logrus.Logger) Option {
+	return func(r *Relay) error {
+		r.cfg.logger = l
+		return nil
+	}
+}
+
+// WithLogFile specifies a log file to write the relay output to.
+func WithLogFile(f *os.File) Option {
+	return func(r *Relay) error {
+		if r.cfg.logger == nil {
+			return errors.New("nil logger provided")
+		}
+		r.cfg.logger.SetOutput(f)
+		return nil
+	}
+}
@@ -0,0 +1,622 @@
+// Package relay implements a local builder relay serving the builder API over HTTP. It takes its
+// payloads from an execution engine, usually a mock one, signs its bids with a Dilithium builder
+// key, and reveals the payload of the blinded blocks submitted to it. Faults can be injected to
+// exercise the fallback logic of proposers end to end without a real relay.
+package relay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	gMux "github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/go-zond/common"
+	"github.com/theQRL/go-zond/common/hexutil"
+	zondTypes "github.com/theQRL/go-zond/core/types"
+	"github.com/theQRL/go-zond/trie"
+	builderAPI "github.com/theQRL/qrysm/v4/api/client/builder"
+	"github.com/theQRL/qrysm/v4/api/gateway/apimiddleware"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/signing"
+	"github.com/theQRL/qrysm/v4/beacon-chain/execution"
+	rpcmiddleware "github.com/theQRL/qrysm/v4/beacon-chain/rpc/apimiddleware"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/blocks"
+	"github.com/theQRL/qrysm/v4/consensus-types/interfaces"
+	payloadattribute "github.com/theQRL/qrysm/v4/consensus-types/payload-attribute"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/crypto/dilithium"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	"github.com/theQRL/qrysm/v4/network"
+	enginev1 "github.com/theQRL/qrysm/v4/proto/engine/v1"
+	"github.com/theQRL/qrysm/v4/proto/migration"
+	zond "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	zondpbv2 "github.com/theQRL/qrysm/v4/proto/zond/v2"
+	"github.com/theQRL/qrysm/v4/runtime/version"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	statusPath   = "/eth/v1/builder/status"
+	registerPath = "/eth/v1/builder/validators"
+	headerPath   = "/eth/v1/builder/header/{slot:[0-9]+}/{parent_hash:0x[a-fA-F0-9]+}/{pubkey:0x[a-fA-F0-9]+}"
+	blindedPath  = "/eth/v1/builder/blinded_blocks"
+	faultPath    = "/relay/v1/fault/{fault}"
+)
+
+var (
+	defaultHost         = "127.0.0.1"
+	defaultPort         = 18550
+	defaultTimeoutDelay = 3 * time.Second
+)
+
+// dilithiumPubkeyLength is the length of the validator public keys.
+const dilithiumPubkeyLength = dilithium2.CryptoPublicKeyBytes
+
+type bidPayload struct {
+	slot primitives.Slot
+	// pubkey is the key of the proposer which requested the bid, and must sign the block.
+	pubkey  [dilithiumPubkeyLength]byte
+	payload *enginev1.ExecutionPayloadCapella
+}
+
+type config struct {
+	host         string
+	port         int
+	engine       execution.EngineCaller
+	key          dilithium.DilithiumKey
+	genesisTime  time.Time
+	gvr          []byte
+	bidValueGwei uint64
+	timeoutDelay time.Duration
+	logger       *logrus.Logger
+}
+
+// Relay is a local builder relay.
+type Relay struct {
+	cfg *config
+	srv *http.Server
+
+	sync.RWMutex
+	fault         Fault
+	feeRecipients map[[dilithiumPubkeyLength]byte][]byte
+	// payloads are the payloads of the bids, by block hash, until their block is submitted.
+	payloads map[[32]byte]*bidPayload
+	// revealed counts the payloads revealed to proposers.
+	revealed int
+}
+
+// New creates a relay. An execution engine to take payloads from is required.
+func New(opts ...Option) (*Relay, error) {
+	r := &Relay{
+		cfg: &config{
+			host:         defaultHost,
+			port:         defaultPort,
+			timeoutDelay: defaultTimeoutDelay,
+			logger:       logrus.New(),
+		},
+		fault:         FaultNone,
+		feeRecipients: make(map[[dilithiumPubkeyLength]byte][]byte),
+		payloads:      make(map[[32]byte]*bidPayload),
+	}
+	for _, o := range opts {
+		if err := o(r); err != nil {
+			return nil, err
+		}
+	}
+	if r.cfg.engine == nil {
+		return nil, errors.New("must provide an execution engine to take payloads from")
+	}
+	if len(r.cfg.gvr) != 32 {
+		return nil, errors.New("must provide the genesis validators root to verify the signature of blinded blocks")
+	}
+	if r.cfg.key == nil {
+		key, err := dilithium.RandKey()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not generate builder key")
+		}
+		r.cfg.key = key
+	}
+	r.srv = &http.Server{
+		Addr:              fmt.Sprintf("%s:%d", r.cfg.host, r.cfg.port),
+		Handler:           r.Handler(),
+		ReadHeaderTimeout: time.Second,
+	}
+	return r, nil
+}
+
+// Handler returns the HTTP handler serving the builder API.
+func (r *Relay) Handler() http.Handler {
+	router := gMux.NewRouter()
+	router.HandleFunc(statusPath, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}).Methods(http.MethodGet)
+	router.HandleFunc(registerPath, r.handleRegisterValidators).Methods(http.MethodPost)
+	router.HandleFunc(headerPath, r.handleHeader).Methods(http.MethodGet)
+	router.HandleFunc(blindedPath, r.handleBlindedBlock).Methods(http.MethodPost)
+	router.HandleFunc(faultPath, r.handleFault).Methods(http.MethodPost)
+	return router
+}
+
+// Address of the relay.
+func (r *Relay) Address() string {
+	return r.srv.Addr
+}
+
+// PublicKey of the builder signing the bids.
+func (r *Relay) PublicKey() []byte {
+	return r.cfg.key.PublicKey().Marshal()
+}
+
+// Start serves the builder API until the context is canceled.
+func (r *Relay) Start(ctx context.Context) error {
+	r.srv.BaseContext = func(net.Listener) context.Context {
+		return ctx
+	}
+	r.cfg.logger.WithFields(logrus.Fields{
+		"builderPubkey": fmt.Sprintf("%#x", bytesutil.Trunc(r.PublicKey())),
+		"fault":         r.Fault(),
+	}).Infof("Relay now listening on address %s", r.srv.Addr)
+	errs := make(chan error, 1)
+	go func() {
+		if err := r.srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- err
+		}
+	}()
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		return r.srv.Shutdown(context.Background())
+	}
+}
+
+// Fault returns the fault currently injected by the relay.
+func (r *Relay) Fault() Fault {
+	r.RLock()
+	defer r.RUnlock()
+	return r.fault
+}
+
+// SetFault injects a fault, or stops injecting faults with FaultNone.
+func (r *Relay) SetFault(f Fault) {
+	r.Lock()
+	defer r.Unlock()
+	r.fault = f
+}
+
+// Revealed returns the number of payloads revealed to proposers.
+func (r *Relay) Revealed() int {
+	r.RLock()
+	defer r.RUnlock()
+	return r.revealed
+}
+
+func (r *Relay) handleFault(w http.ResponseWriter, req *http.Request) {
+	f, err := ParseFault(gMux.Vars(req)["fault"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	r.SetFault(f)
+	r.cfg.logger.WithField("fault", f).Info("Injecting relay fault")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *Relay) handleRegisterValidators(w http.ResponseWriter, req *http.Request) {
+	var registrations []*builderAPI.SignedValidatorRegistration
+	if err := json.NewDecoder(req.Body).Decode(&registrations); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	domain, err := signing.ComputeDomain(params.BeaconConfig().DomainApplicationBuilder, nil, nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, reg := range registrations {
+		if reg == nil || reg.SignedValidatorRegistrationV1 == nil || reg.Message == nil {
+			writeError(w, http.StatusBadRequest, "nil registration")
+			return
+		}
+		if err := signing.VerifySigningRoot(reg.Message, reg.Message.Pubkey, reg.Signature, domain); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid registration signature for pubkey %#x", bytesutil.Trunc(reg.Message.Pubkey)))
+			return
+		}
+	}
+	r.Lock()
+	for _, reg := range registrations {
+		r.feeRecipients[bytesutil.ToBytes2592(reg.Message.Pubkey)] = reg.Message.FeeRecipient
+	}
+	r.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *Relay) handleHeader(w http.ResponseWriter, req *http.Request) {
+	vars := gMux.Vars(req)
+	s, err := strconv.ParseUint(vars["slot"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid slot")
+		return
+	}
+	slot := primitives.Slot(s)
+	parentHash, err := hexutil.Decode(vars["parent_hash"])
+	if err != nil || len(parentHash) != 32 {
+		writeError(w, http.StatusBadRequest, "invalid parent hash")
+		return
+	}
+	pubkey, err := hexutil.Decode(vars["pubkey"])
+	if err != nil || len(pubkey) != dilithiumPubkeyLength {
+		writeError(w, http.StatusBadRequest, "invalid pubkey")
+		return
+	}
+
+	fault := r.Fault()
+	switch fault {
+	case FaultNoBid:
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case FaultTimeout:
+		select {
+		case <-time.After(r.cfg.timeoutDelay):
+		case <-req.Context().Done():
+			return
+		}
+	}
+
+	resp, err := r.bid(req.Context(), slot, bytesutil.ToBytes32(parentHash), bytesutil.ToBytes2592(pubkey), fault)
+	if err != nil {
+		r.cfg.logger.WithError(err).Error("Could not build bid")
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	network.WriteJson(w, resp)
+}
+
+// headerResponse is a capella header response along with its version, which the client reads first.
+type headerResponse struct {
+	Version string `json:"version"`
+	builderAPI.ExecHeaderResponseCapella
+}
+
+// bid takes a payload from the engine on top of the parent hash, and returns a signed bid for it.
+func (r *Relay) bid(
+	ctx context.Context, slot primitives.Slot, parentHash [32]byte, pubkey [dilithiumPubkeyLength]byte, fault Fault,
+) (*headerResponse, error) {
+	if slots := params.BeaconConfig().SlotsPerEpoch; primitives.Epoch(slot/slots) < params.BeaconConfig().CapellaForkEpoch {
+		return nil, errors.New("the relay only bids on capella payloads")
+	}
+	r.RLock()
+	feeRecipient := r.feeRecipients[pubkey]
+	r.RUnlock()
+	if feeRecipient == nil {
+		feeRecipient = make([]byte, 20)
+	}
+	timestamp := uint64(0)
+	if !r.cfg.genesisTime.IsZero() {
+		timestamp = uint64(r.cfg.genesisTime.Unix()) + uint64(slot)*params.BeaconConfig().SecondsPerSlot
+	}
+
+	attrs, err := payloadattribute.New(&enginev1.PayloadAttributesV2{
+		Timestamp:             timestamp,
+		PrevRandao:            make([]byte, 32),
+		SuggestedFeeRecipient: feeRecipient,
+		Withdrawals:           []*enginev1.Withdrawal{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	fcs := &enginev1.ForkchoiceState{
+		HeadBlockHash:      parentHash[:],
+		SafeBlockHash:      parentHash[:],
+		FinalizedBlockHash: parentHash[:],
+	}
+	id, _, err := r.cfg.engine.ForkchoiceUpdated(ctx, fcs, attrs)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not request payload")
+	}
+	if id == nil {
+		return nil, errors.New("engine did not start building a payload")
+	}
+	data, err := r.cfg.engine.GetPayload(ctx, *id, slot)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get payload")
+	}
+	if data.IsNil() {
+		return nil, errors.New("engine returned a nil payload")
+	}
+	pb, err := data.PbCapella()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get capella payload")
+	}
+	payload, err := stamp(pb, parentHash, timestamp, feeRecipient)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not build payload on parent")
+	}
+
+	valueGwei := r.cfg.bidValueGwei
+	if valueGwei == 0 {
+		if valueGwei, err = data.ValueInGwei(); err != nil {
+			return nil, errors.Wrap(err, "could not get payload value")
+		}
+	}
+	if valueGwei == 0 {
+		valueGwei = 1
+	}
+	value := builderAPI.Uint256{Int: new(big.Int).Mul(new(big.Int).SetUint64(valueGwei), big.NewInt(1e9))}
+
+	wrapped, err := blocks.WrappedExecutionPayloadCapella(payload, valueGwei)
+	if err != nil {
+		return nil, err
+	}
+	header, err := blocks.PayloadToHeaderCapella(wrapped)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not make payload into header")
+	}
+	if fault == FaultWrongParent {
+		header.ParentHash = bytesutil.SafeCopyBytes(header.BlockHash)
+	}
+	sszBid := &zond.BuilderBidCapella{
+		Header: header,
+		Value:  value.SSZBytes(),
+		Pubkey: r.PublicKey(),
+	}
+	domain, err := signing.ComputeDomain(params.BeaconConfig().DomainApplicationBuilder, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	root, err := signing.ComputeSigningRoot(sszBid, domain)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not compute signing root")
+	}
+	sig := r.cfg.key.Sign(root[:]).Marshal()
+	if fault == FaultInvalidSignature {
+		sig[0] ^= 0xff
+	}
+
+	r.Lock()
+	// Payloads of bids older than an epoch will no longer be requested.
+	for h, p := range r.payloads {
+		if p.slot+params.BeaconConfig().SlotsPerEpoch < slot {
+			delete(r.payloads, h)
+		}
+	}
+	r.payloads[bytesutil.ToBytes32(payload.BlockHash)] = &bidPayload{slot: slot, pubkey: pubkey, payload: payload}
+	r.Unlock()
+	r.cfg.logger.WithFields(logrus.Fields{
+		"slot":       slot,
+		"blockHash":  fmt.Sprintf("%#x", payload.BlockHash),
+		"valueGwei":  valueGwei,
+		"parentHash": fmt.Sprintf("%#x", parentHash),
+	}).Info("Bid on payload")
+
+	resp := &headerResponse{Version: version.String(version.Capella)}
+	resp.Data.Signature = sig
+	resp.Data.Message = &builderAPI.BuilderBidCapella{
+		Header: &builderAPI.ExecutionPayloadHeaderCapella{ExecutionPayloadHeaderCapella: header},
+		Value:  value,
+		Pubkey: r.PublicKey(),
+	}
+	return resp, nil
+}
+
+// stamp returns the payload of the engine built on the requested parent. The payload of an
+// engine which honored the payload attributes is bid on as is, with its real block hash. A mock
+// engine ignores them, so its payload is rewritten to build on the parent at the time of the
+// slot, and given the hash of the rewritten execution block header. No execution client knows
+// that block, so a rewritten payload can only be used with a mock execution engine.
+func stamp(p *enginev1.ExecutionPayloadCapella, parentHash [32]byte, timestamp uint64, feeRecipient []byte) (*enginev1.ExecutionPayloadCapella, error) {
+	if bytesutil.ToBytes32(p.ParentHash) == parentHash && bytes.Equal(p.FeeRecipient, feeRecipient) && (timestamp == 0 || p.Timestamp == timestamp) {
+		return p, nil
+	}
+	payload := zond.CopyExecutionPayloadCapella(p)
+	payload.ParentHash = bytesutil.SafeCopyBytes(parentHash[:])
+	payload.FeeRecipient = bytesutil.SafeCopyBytes(feeRecipient)
+	if timestamp != 0 {
+		payload.Timestamp = timestamp
+	}
+	blockHash, err := executionBlockHash(payload)
+	if err != nil {
+		return nil, err
+	}
+	payload.BlockHash = blockHash[:]
+	return payload, nil
+}
+
+// executionBlockHash returns the hash of the execution block header of the payload.
+func executionBlockHash(p *enginev1.ExecutionPayloadCapella) (common.Hash, error) {
+	txs := make([]*zondTypes.Transaction, len(p.Transactions))
+	for i, enc := range p.Transactions {
+		tx := &zondTypes.Transaction{}
+		if err := tx.UnmarshalBinary(enc); err != nil {
+			return common.Hash{}, errors.Wrapf(err, "invalid transaction %d", i)
+		}
+		txs[i] = tx
+	}
+	withdrawals := make([]*zondTypes.Withdrawal, len(p.Withdrawals))
+	for i, w := range p.Withdrawals {
+		withdrawals[i] = &zondTypes.Withdrawal{
+			Index:     w.Index,
+			Validator: uint64(w.ValidatorIndex),
+			Address:   common.BytesToAddress(w.Address),
+			Amount:    w.Amount,
+		}
+	}
+	withdrawalsHash := zondTypes.DeriveSha(zondTypes.Withdrawals(withdrawals), trie.NewStackTrie(nil))
+	header := &zondTypes.Header{
+		ParentHash:      common.BytesToHash(p.ParentHash),
+		UncleHash:       zondTypes.EmptyUncleHash,
+		Coinbase:        common.BytesToAddress(p.FeeRecipient),
+		Root:            common.BytesToHash(p.StateRoot),
+		TxHash:          zondTypes.DeriveSha(zondTypes.Transactions(txs), trie.NewStackTrie(nil)),
+		ReceiptHash:     common.BytesToHash(p.ReceiptsRoot),
+		Bloom:           zondTypes.BytesToBloom(p.LogsBloom),
+		Difficulty:      common.Big0,
+		Number:          new(big.Int).SetUint64(p.BlockNumber),
+		GasLimit:        p.GasLimit,
+		GasUsed:         p.GasUsed,
+		Time:            p.Timestamp,
+		Extra:           p.ExtraData,
+		MixDigest:       common.BytesToHash(p.PrevRandao),
+		BaseFee:         bytesutil.LittleEndianBytesToBigInt(p.BaseFeePerGas),
+		WithdrawalsHash: &withdrawalsHash,
+	}
+	return header.Hash(), nil
+}
+
+func (r *Relay) handleBlindedBlock(w http.ResponseWriter, req *http.Request) {
+	blk, err := decodeBlindedBlock(req.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	header, err := blk.Block().Body().Execution()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	blockHash := bytesutil.ToBytes32(header.BlockHash())
+	slot := blk.Block().Slot()
+
+	r.RLock()
+	bp, ok := r.payloads[blockHash]
+	r.RUnlock()
+	if !ok {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown payload %#x", blockHash))
+		return
+	}
+	if slot != bp.slot {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("block of slot %d for a payload bid on at slot %d", slot, bp.slot))
+		return
+	}
+	if err := r.verifyProposerSignature(blk, bp.pubkey); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if r.Fault() == FaultWithhold {
+		r.cfg.logger.WithField("blockHash", fmt.Sprintf("%#x", blockHash)).Warn("Withholding payload")
+		writeError(w, http.StatusInternalServerError, "payload withheld")
+		return
+	}
+
+	r.Lock()
+	_, ok = r.payloads[blockHash]
+	if ok {
+		delete(r.payloads, blockHash)
+		r.revealed++
+	}
+	r.Unlock()
+	if !ok {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown payload %#x", blockHash))
+		return
+	}
+	converted, err := builderAPI.FromProtoCapella(bp.payload)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp := &builderAPI.ExecPayloadResponseCapella{
+		Version: version.String(version.Capella),
+		Data:    converted,
+	}
+	network.WriteJson(w, resp)
+	r.cfg.logger.WithFields(logrus.Fields{
+		"slot":      slot,
+		"blockHash": fmt.Sprintf("%#x", blockHash),
+	}).Info("Revealed payload")
+}
+
+// decodeBlindedBlock decodes a blinded block in the JSON format of the builder API, which is
+// the one of the beacon API.
+func decodeBlindedBlock(body io.Reader) (interfaces.ReadOnlySignedBeaconBlock, error) {
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read blinded block")
+	}
+	container := &rpcmiddleware.SignedBlindedBeaconBlockCapellaContainerJson{}
+	if err := json.Unmarshal(raw, container); err != nil {
+		return nil, errors.Wrap(err, "could not decode blinded block")
+	}
+	if container.Message == nil || container.Message.Body == nil {
+		return nil, errors.New("could not decode blinded block: nil message")
+	}
+	if errJson := apimiddleware.ProcessRequestContainerFields(container); errJson != nil {
+		return nil, errors.Errorf("could not decode blinded block: %s", errJson.Msg())
+	}
+	b, err := json.Marshal(container)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode blinded block")
+	}
+	v2Blk := &zondpbv2.SignedBlindedBeaconBlockCapella{}
+	if err := protojson.Unmarshal(b, v2Blk); err != nil {
+		return nil, errors.Wrap(err, "could not decode blinded block")
+	}
+	v1Blk, err := migration.BlindedCapellaToV1Alpha1SignedBlock(v2Blk)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode blinded block")
+	}
+	if err := setSignatureValidatorIndices(raw, v1Blk.Block.Body.Attestations); err != nil {
+		return nil, err
+	}
+	return blocks.NewSignedBeaconBlock(v1Blk)
+}
+
+// setSignatureValidatorIndices sets the indices of the signers of the attestations, which the
+// attestations of the beacon API do not carry but the block root commits to.
+func setSignatureValidatorIndices(raw []byte, atts []*zond.Attestation) error {
+	container := &struct {
+		Message struct {
+			Body struct {
+				Attestations []struct {
+					SignatureValidatorIndex []string `json:"signature_validator_index"`
+				} `json:"attestations"`
+			} `json:"body"`
+		} `json:"message"`
+	}{}
+	if err := json.Unmarshal(raw, container); err != nil {
+		return errors.Wrap(err, "could not decode blinded block")
+	}
+	if len(container.Message.Body.Attestations) != len(atts) {
+		return errors.New("could not decode blinded block: attestation count mismatch")
+	}
+	for i, att := range container.Message.Body.Attestations {
+		indices := make([]uint64, len(att.SignatureValidatorIndex))
+		for j, idx := range att.SignatureValidatorIndex {
+			index, err := strconv.ParseUint(idx, 10, 64)
+			if err != nil {
+				return errors.Wrapf(err, "could not decode signature validator index of attestation %d", i)
+			}
+			indices[j] = index
+		}
+		atts[i].SignatureValidatorIndex = indices
+	}
+	return nil
+}
+
+// verifyProposerSignature checks the block is signed by the proposer which requested the bid.
+func (r *Relay) verifyProposerSignature(blk interfaces.ReadOnlySignedBeaconBlock, pubkey [dilithiumPubkeyLength]byte) error {
+	domain, err := signing.ComputeDomain(params.BeaconConfig().DomainBeaconProposer, params.BeaconConfig().CapellaForkVersion, r.cfg.gvr)
+	if err != nil {
+		return err
+	}
+	sig := blk.Signature()
+	if err := signing.VerifySigningRoot(blk.Block(), pubkey[:], sig[:], domain); err != nil {
+		return errors.Errorf("invalid proposer signature for pubkey %#x", bytesutil.Trunc(pubkey[:]))
+	}
+	return nil
+}
+
+// writeError writes an error in the format builder API clients decode.
+func writeError(w http.ResponseWriter, code int, msg string) {
+	network.WriteError(w, &network.DefaultErrorJson{Message: msg, Code: code})
+}
@@ -0,0 +1,279 @@
+package relay
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	builderAPI "github.com/theQRL/qrysm/v4/api/client/builder"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/signing"
+	mockExecution "github.com/theQRL/qrysm/v4/beacon-chain/execution/testing"
+	fieldparams "github.com/theQRL/qrysm/v4/config/fieldparams"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/blocks"
+	"github.com/theQRL/qrysm/v4/consensus-types/interfaces"
+	"github.com/theQRL/qrysm/v4/crypto/dilithium"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	enginev1 "github.com/theQRL/qrysm/v4/proto/engine/v1"
+	zond "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/util"
+)
+
+var genesisValidatorsRoot = bytesutil.PadTo([]byte("genesis validators root"), fieldparams.RootLength)
+
+func setupRelay(t *testing.T, opts ...Option) (*Relay, *builderAPI.Client) {
+	params.SetupTestConfigCleanup(t)
+	cfg := params.BeaconConfig().Copy()
+	cfg.CapellaForkEpoch = 0
+	params.OverrideBeaconConfig(cfg)
+
+	engine := &mockExecution.EngineClient{
+		PayloadIDBytes: &enginev1.PayloadIDBytes{1},
+		ExecutionPayloadCapella: &enginev1.ExecutionPayloadCapella{
+			ParentHash:    make([]byte, fieldparams.RootLength),
+			FeeRecipient:  make([]byte, fieldparams.FeeRecipientLength),
+			StateRoot:     make([]byte, fieldparams.RootLength),
+			ReceiptsRoot:  make([]byte, fieldparams.RootLength),
+			LogsBloom:     make([]byte, fieldparams.LogsBloomLength),
+			PrevRandao:    make([]byte, fieldparams.RootLength),
+			BaseFeePerGas: make([]byte, fieldparams.RootLength),
+			BlockHash:     bytesutil.PadTo([]byte("payload"), fieldparams.RootLength),
+			Transactions:  make([][]byte, 0),
+			Withdrawals:   make([]*enginev1.Withdrawal, 0),
+		},
+		BlockValue: 2,
+	}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	r, err := New(append([]Option{WithEngine(engine), WithLogger(logger), WithGenesisValidatorsRoot(genesisValidatorsRoot)}, opts...)...)
+	require.NoError(t, err)
+	srv := httptest.NewServer(r.Handler())
+	t.Cleanup(srv.Close)
+	c, err := builderAPI.NewClient(srv.URL)
+	require.NoError(t, err)
+	return r, c
+}
+
+func register(t *testing.T, c *builderAPI.Client, feeRecipient []byte) (dilithium.DilithiumKey, [dilithiumPubkeyLength]byte) {
+	key, err := dilithium.RandKey()
+	require.NoError(t, err)
+	reg := &zond.ValidatorRegistrationV1{
+		FeeRecipient: feeRecipient,
+		GasLimit:     30000000,
+		Timestamp:    1,
+		Pubkey:       key.PublicKey().Marshal(),
+	}
+	domain, err := signing.ComputeDomain(params.BeaconConfig().DomainApplicationBuilder, nil, nil)
+	require.NoError(t, err)
+	root, err := signing.ComputeSigningRoot(reg, domain)
+	require.NoError(t, err)
+	signed := &zond.SignedValidatorRegistrationV1{Message: reg, Signature: key.Sign(root[:]).Marshal()}
+	require.NoError(t, c.RegisterValidator(context.Background(), []*zond.SignedValidatorRegistrationV1{signed}))
+	return key, bytesutil.ToBytes2592(reg.Pubkey)
+}
+
+// blindedBlockForBid returns the blinded block of the bid at slot 1, signed by the proposer key.
+func blindedBlockForBid(t *testing.T, bid builderAPI.SignedBid, key dilithium.DilithiumKey) interfaces.ReadOnlySignedBeaconBlock {
+	msg, err := bid.Message()
+	require.NoError(t, err)
+	header, err := msg.Header()
+	require.NoError(t, err)
+	h, ok := header.Proto().(*enginev1.ExecutionPayloadHeaderCapella)
+	require.Equal(t, true, ok)
+	b := util.NewBlindedBeaconBlockCapella()
+	b.Block.Slot = 1
+	b.Block.Body.ExecutionPayloadHeader = h
+	// The signers of an attestation are part of the signed root, so they must survive the
+	// encoding of the block.
+	att := util.HydrateAttestation(&zond.Attestation{SignatureValidatorIndex: []uint64{4}})
+	b.Block.Body.Attestations = []*zond.Attestation{att}
+	domain, err := signing.ComputeDomain(params.BeaconConfig().DomainBeaconProposer, params.BeaconConfig().CapellaForkVersion, genesisValidatorsRoot)
+	require.NoError(t, err)
+	root, err := signing.ComputeSigningRoot(b.Block, domain)
+	require.NoError(t, err)
+	b.Signature = key.Sign(root[:]).Marshal()
+	sb, err := blocks.NewSignedBeaconBlock(b)
+	require.NoError(t, err)
+	return sb
+}
+
+func verifyBid(t *testing.T, bid builderAPI.SignedBid) error {
+	msg, err := bid.Message()
+	require.NoError(t, err)
+	domain, err := signing.ComputeDomain(params.BeaconConfig().DomainApplicationBuilder, nil, nil)
+	require.NoError(t, err)
+	return signing.VerifySigningRoot(msg, msg.Pubkey(), bid.Signature(), domain)
+}
+
+func TestRelay_BidAndReveal(t *testing.T) {
+	r, c := setupRelay(t, WithBidValue(5))
+	ctx := context.Background()
+	require.NoError(t, c.Status(ctx))
+	feeRecipient := bytesutil.PadTo([]byte("recipient"), fieldparams.FeeRecipientLength)
+	key, pubkey := register(t, c, feeRecipient)
+
+	parentHash := bytesutil.ToBytes32([]byte("parent"))
+	bid, err := c.GetHeader(ctx, 1, parentHash, pubkey)
+	require.NoError(t, err)
+	require.NoError(t, verifyBid(t, bid))
+	msg, err := bid.Message()
+	require.NoError(t, err)
+	assert.DeepEqual(t, r.PublicKey(), msg.Pubkey())
+	assert.Equal(t, uint64(5e9), bytesutil.LittleEndianBytesToBigInt(msg.Value()).Uint64())
+	header, err := msg.Header()
+	require.NoError(t, err)
+	assert.DeepEqual(t, parentHash[:], header.ParentHash())
+	assert.DeepEqual(t, feeRecipient, header.FeeRecipient())
+
+	// The payload is only revealed to the proposer which requested the bid.
+	other, err := dilithium.RandKey()
+	require.NoError(t, err)
+	_, err = c.SubmitBlindedBlock(ctx, blindedBlockForBid(t, bid, other))
+	require.ErrorContains(t, "invalid proposer signature", err)
+	assert.Equal(t, 0, r.Revealed())
+
+	sb := blindedBlockForBid(t, bid, key)
+	payload, err := c.SubmitBlindedBlock(ctx, sb)
+	require.NoError(t, err)
+	assert.DeepEqual(t, header.BlockHash(), payload.BlockHash())
+	assert.Equal(t, 1, r.Revealed())
+	pb, ok := payload.Proto().(*enginev1.ExecutionPayloadCapella)
+	require.Equal(t, true, ok)
+	blockHash, err := executionBlockHash(pb)
+	require.NoError(t, err)
+	assert.DeepEqual(t, blockHash[:], payload.BlockHash())
+
+	// A payload is only revealed once.
+	_, err = c.SubmitBlindedBlock(ctx, sb)
+	require.ErrorContains(t, "unknown payload", err)
+}
+
+func TestRelay_Faults(t *testing.T) {
+	ctx := context.Background()
+	parentHash := bytesutil.ToBytes32([]byte("parent"))
+
+	t.Run("invalid signature", func(t *testing.T) {
+		_, c := setupRelay(t, WithFault(FaultInvalidSignature))
+		_, pubkey := register(t, c, make([]byte, fieldparams.FeeRecipientLength))
+		bid, err := c.GetHeader(ctx, 1, parentHash, pubkey)
+		require.NoError(t, err)
+		require.NotNil(t, verifyBid(t, bid))
+	})
+	t.Run("wrong parent", func(t *testing.T) {
+		_, c := setupRelay(t, WithFault(FaultWrongParent))
+		_, pubkey := register(t, c, make([]byte, fieldparams.FeeRecipientLength))
+		bid, err := c.GetHeader(ctx, 1, parentHash, pubkey)
+		require.NoError(t, err)
+		require.NoError(t, verifyBid(t, bid))
+		msg, err := bid.Message()
+		require.NoError(t, err)
+		header, err := msg.Header()
+		require.NoError(t, err)
+		assert.NotEqual(t, parentHash, bytesutil.ToBytes32(header.ParentHash()))
+	})
+	t.Run("withhold", func(t *testing.T) {
+		r, c := setupRelay(t, WithFault(FaultWithhold))
+		key, pubkey := register(t, c, make([]byte, fieldparams.FeeRecipientLength))
+		bid, err := c.GetHeader(ctx, 1, parentHash, pubkey)
+		require.NoError(t, err)
+		sb := blindedBlockForBid(t, bid, key)
+		_, err = c.SubmitBlindedBlock(ctx, sb)
+		require.ErrorContains(t, "payload withheld", err)
+		assert.Equal(t, 0, r.Revealed())
+
+		// The payload is still revealed once the fault is cleared.
+		r.SetFault(FaultNone)
+		_, err = c.SubmitBlindedBlock(ctx, sb)
+		require.NoError(t, err)
+		assert.Equal(t, 1, r.Revealed())
+	})
+	t.Run("timeout", func(t *testing.T) {
+		_, c := setupRelay(t, WithFault(FaultTimeout), WithTimeoutDelay(time.Minute))
+		_, pubkey := register(t, c, make([]byte, fieldparams.FeeRecipientLength))
+		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		_, err := c.GetHeader(ctx, 1, parentHash, pubkey)
+		require.ErrorContains(t, "deadline exceeded", err)
+	})
+	t.Run("no bid", func(t *testing.T) {
+		_, c := setupRelay(t, WithFault(FaultNoBid))
+		_, pubkey := register(t, c, make([]byte, fieldparams.FeeRecipientLength))
+		_, err := c.GetHeader(ctx, 1, parentHash, pubkey)
+		require.NotNil(t, err)
+	})
+}
+
+func TestRelay_FaultEndpoint(t *testing.T) {
+	r, _ := setupRelay(t)
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/relay/v1/fault/withhold", "", nil)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, FaultWithhold, r.Fault())
+
+	resp, err = http.Post(srv.URL+"/relay/v1/fault/unknown", "", nil)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, FaultWithhold, r.Fault())
+}
+
+func TestRelay_RejectsInvalidRegistration(t *testing.T) {
+	_, c := setupRelay(t)
+	key, err := dilithium.RandKey()
+	require.NoError(t, err)
+	reg := &zond.SignedValidatorRegistrationV1{
+		Message: &zond.ValidatorRegistrationV1{
+			FeeRecipient: make([]byte, fieldparams.FeeRecipientLength),
+			Pubkey:       key.PublicKey().Marshal(),
+		},
+		Signature: make([]byte, dilithium2.CryptoBytes),
+	}
+	err = c.RegisterValidator(context.Background(), []*zond.SignedValidatorRegistrationV1{reg})
+	require.ErrorContains(t, "invalid registration signature", err)
+}
+
+func TestStamp(t *testing.T) {
+	parentHash := bytesutil.ToBytes32([]byte("parent"))
+	feeRecipient := bytesutil.PadTo([]byte("recipient"), fieldparams.FeeRecipientLength)
+	p := &enginev1.ExecutionPayloadCapella{
+		ParentHash:    parentHash[:],
+		FeeRecipient:  feeRecipient,
+		StateRoot:     make([]byte, fieldparams.RootLength),
+		ReceiptsRoot:  make([]byte, fieldparams.RootLength),
+		LogsBloom:     make([]byte, fieldparams.LogsBloomLength),
+		PrevRandao:    make([]byte, fieldparams.RootLength),
+		BaseFeePerGas: make([]byte, fieldparams.RootLength),
+		BlockHash:     bytesutil.PadTo([]byte("real hash"), fieldparams.RootLength),
+		Timestamp:     12,
+		Transactions:  make([][]byte, 0),
+		Withdrawals:   make([]*enginev1.Withdrawal, 0),
+	}
+
+	// The payload of an engine which honored the payload attributes keeps its real hash.
+	got, err := stamp(p, parentHash, 12, feeRecipient)
+	require.NoError(t, err)
+	assert.DeepEqual(t, p.BlockHash, got.BlockHash)
+
+	// Otherwise the payload is rebuilt on the parent, with the hash of its header.
+	otherParent := bytesutil.ToBytes32([]byte("other parent"))
+	got, err = stamp(p, otherParent, 24, feeRecipient)
+	require.NoError(t, err)
+	assert.DeepEqual(t, otherParent[:], got.ParentHash)
+	assert.Equal(t, uint64(24), got.Timestamp)
+	want, err := executionBlockHash(got)
+	require.NoError(t, err)
+	assert.DeepEqual(t, want[:], got.BlockHash)
+	assert.DeepNotEqual(t, p.BlockHash, got.BlockHash)
+	assert.DeepEqual(t, parentHash[:], p.ParentHash)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/interfaces"
+	"github.com/theQRL/qrysm/v4/encoding/ssz/detect"
+	"google.golang.org/protobuf/proto"
+)
+
+// loadChainConfig activates the chain config file, if any, so that blocks of custom networks are decoded
+// with the fork schedule of their network.
+func loadChainConfig(path string) error {
+	if path == "" {
+		return nil
+	}
+	return errors.Wrap(params.LoadChainConfigFile(path, nil), "could not load chain config file")
+}
+
+// readState reads an SSZ encoded beacon state of any fork. The fork and network are detected from the fork
+// version of the state.
+func readState(path string) (state.BeaconState, error) {
+	b, err := os.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+	vu, err := detect.FromState(b)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not detect the fork of state %s", path)
+	}
+	return vu.UnmarshalBeaconState(b)
+}
+
+// readBlock reads an SSZ encoded signed block, blinded or not, of the fork scheduled at its slot by the
+// active chain config.
+func readBlock(path string, blinded bool) (interfaces.ReadOnlySignedBeaconBlock, error) {
+	b, err := os.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+	vu, err := detect.FromBlock(b)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not detect the fork of block %s", path)
+	}
+	if blinded {
+		return vu.UnmarshalBlindedBeaconBlock(b)
+	}
+	return vu.UnmarshalBeaconBlock(b)
+}
+
+// decodeSSZ writes the SSZ encoded block, blinded block or state at the path as JSON.
+func decodeSSZ(w io.Writer, path string, dataType string) error {
+	var m proto.Message
+	switch dataType {
+	case "block", "blinded_block":
+		blk, err := readBlock(path, dataType == "blinded_block")
+		if err != nil {
+			return err
+		}
+		if m, err = blk.Proto(); err != nil {
+			return err
+		}
+	case "state":
+		st, err := readState(path)
+		if err != nil {
+			return err
+		}
+		pb, ok := st.ToProtoUnsafe().(proto.Message)
+		if !ok {
+			return errors.New("state is not a protobuf message")
+		}
+		m = pb
+	default:
+		return fmt.Errorf("invalid data type %s, wanted block, blinded_block or state", dataType)
+	}
+	b, err := marshalJSON(m.ProtoReflect())
+	if err != nil {
+		return errors.Wrap(err, "could not encode JSON")
+	}
+	_, err = w.Write(b)
+	return err
+}
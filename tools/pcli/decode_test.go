@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/theQRL/go-zond/common/hexutil"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// readJSON decodes JSON written by marshalJSON into the message, so that decoding can be checked to lose nothing.
+func readJSON(data []byte, m protoreflect.Message) error {
+	var v map[string]interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	return readMessage(v, m)
+}
+
+func readMessage(v map[string]interface{}, m protoreflect.Message) error {
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		fv, ok := v[string(fd.Name())]
+		if !ok {
+			return fmt.Errorf("missing field %s", fd.Name())
+		}
+		switch {
+		case fd.IsList():
+			items, ok := fv.([]interface{})
+			if !ok {
+				return fmt.Errorf("field %s is not a list", fd.Name())
+			}
+			l := m.Mutable(fd).List()
+			for _, item := range items {
+				e, err := readValue(fd, item, l.NewElement)
+				if err != nil {
+					return err
+				}
+				l.Append(e)
+			}
+		case fv != nil:
+			e, err := readValue(fd, fv, func() protoreflect.Value { return m.NewField(fd) })
+			if err != nil {
+				return err
+			}
+			m.Set(fd, e)
+		}
+	}
+	return nil
+}
+
+func readValue(fd protoreflect.FieldDescriptor, v interface{}, newMessage func() protoreflect.Value) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return protoreflect.Value{}, fmt.Errorf("field %s is not an object", fd.Name())
+		}
+		e := newMessage()
+		return e, readMessage(obj, e.Message())
+	case protoreflect.BoolKind:
+		b, ok := v.(bool)
+		if !ok {
+			return protoreflect.Value{}, fmt.Errorf("field %s is not a bool", fd.Name())
+		}
+		return protoreflect.ValueOfBool(b), nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return protoreflect.Value{}, fmt.Errorf("field %s is not a string", fd.Name())
+	}
+	switch fd.Kind() {
+	case protoreflect.BytesKind:
+		b, err := hexutil.Decode(s)
+		return protoreflect.ValueOfBytes(b), err
+	case protoreflect.Uint64Kind:
+		u, err := strconv.ParseUint(s, 10, 64)
+		return protoreflect.ValueOfUint64(u), err
+	case protoreflect.Uint32Kind:
+		u, err := strconv.ParseUint(s, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(u)), err
+	default:
+		return protoreflect.Value{}, fmt.Errorf("field %s has unsupported kind %s", fd.Name(), fd.Kind())
+	}
+}
+
+func TestDecodeSSZ_RoundTrip(t *testing.T) {
+	setCapellaGenesisConfig(t)
+	st, _, blk := signedTestBlock(t)
+	stateSSZ, err := st.MarshalSSZ()
+	require.NoError(t, err)
+	blockSSZ, err := blk.MarshalSSZ()
+	require.NoError(t, err)
+
+	tests := []struct {
+		dataType string
+		ssz      []byte
+		want     interface{}
+		decoded  protoreflect.ProtoMessage
+	}{
+		{dataType: "state", ssz: stateSSZ, want: st.ToProtoUnsafe(), decoded: &zondpb.BeaconStateCapella{}},
+		{dataType: "block", ssz: blockSSZ, want: blk, decoded: &zondpb.SignedBeaconBlockCapella{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dataType, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.dataType+".ssz")
+			require.NoError(t, os.WriteFile(path, tt.ssz, 0600))
+			var buf bytes.Buffer
+			require.NoError(t, decodeSSZ(&buf, path, tt.dataType))
+			require.NoError(t, readJSON(buf.Bytes(), tt.decoded.ProtoReflect()))
+			require.DeepSSZEqual(t, tt.want, tt.decoded)
+		})
+	}
+}
+
+func TestDecodeSSZ_InvalidDataType(t *testing.T) {
+	var buf bytes.Buffer
+	require.ErrorContains(t, "invalid data type", decodeSSZ(&buf, "unused.ssz", "header"))
+}
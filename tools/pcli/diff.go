@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	"github.com/theQRL/qrysm/v4/runtime/version"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// missingValue stands for the value of a field or list element one of the compared states does not have.
+const missingValue = "<none>"
+
+// fieldDiff is a field, list element or nested field whose value differs between two states.
+type fieldDiff struct {
+	path string
+	a    string
+	b    string
+}
+
+func (d fieldDiff) String() string {
+	return fmt.Sprintf("%s: %s -> %s", d.path, d.a, d.b)
+}
+
+// diffStates compares two states field by field. Lists such as the validators, balances and participation
+// flags are compared element by element, and containers field by field, so that a difference is reported at
+// the most specific path, e.g. validators[12].exit_epoch. States of different forks are compared on the fields
+// they have in common, and the fields only one of them has are reported as missing in the other.
+func diffStates(a, b state.BeaconState) ([]fieldDiff, error) {
+	pa, ok := a.ToProtoUnsafe().(proto.Message)
+	if !ok {
+		return nil, errors.New("first state is not a protobuf message")
+	}
+	pb, ok := b.ToProtoUnsafe().(proto.Message)
+	if !ok {
+		return nil, errors.New("second state is not a protobuf message")
+	}
+	var diffs []fieldDiff
+	if a.Version() != b.Version() {
+		diffs = append(diffs, fieldDiff{path: "fork", a: version.String(a.Version()), b: version.String(b.Version())})
+	}
+	diffMessages("", pa.ProtoReflect(), pb.ProtoReflect(), &diffs)
+	return diffs, nil
+}
+
+// writeStateDiff writes the differences between the SSZ encoded states at the paths, one per line.
+func writeStateDiff(w io.Writer, pathA, pathB string) (int, error) {
+	a, err := readState(pathA)
+	if err != nil {
+		return 0, err
+	}
+	b, err := readState(pathB)
+	if err != nil {
+		return 0, err
+	}
+	diffs, err := diffStates(a, b)
+	if err != nil {
+		return 0, err
+	}
+	for _, d := range diffs {
+		if _, err := fmt.Fprintln(w, d); err != nil {
+			return 0, err
+		}
+	}
+	return len(diffs), nil
+}
+
+func diffMessages(path string, a, b protoreflect.Message, diffs *[]fieldDiff) {
+	fa, fb := a.Descriptor().Fields(), b.Descriptor().Fields()
+	for i := 0; i < fa.Len(); i++ {
+		fd := fa.Get(i)
+		p := joinPath(path, string(fd.Name()))
+		other := fb.ByName(fd.Name())
+		if other == nil {
+			*diffs = append(*diffs, fieldDiff{path: p, a: formatField(fd, a.Get(fd)), b: missingValue})
+			continue
+		}
+		if fd.IsList() && other.IsList() {
+			diffLists(p, fd, other, a.Get(fd).List(), b.Get(other).List(), diffs)
+			continue
+		}
+		diffValues(p, fd, other, a.Get(fd), b.Get(other), diffs)
+	}
+	for i := 0; i < fb.Len(); i++ {
+		fd := fb.Get(i)
+		if fa.ByName(fd.Name()) == nil {
+			*diffs = append(*diffs, fieldDiff{path: joinPath(path, string(fd.Name())), a: missingValue, b: formatField(fd, b.Get(fd))})
+		}
+	}
+}
+
+func diffLists(path string, fa, fb protoreflect.FieldDescriptor, a, b protoreflect.List, diffs *[]fieldDiff) {
+	for i := 0; i < a.Len() || i < b.Len(); i++ {
+		p := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= b.Len():
+			*diffs = append(*diffs, fieldDiff{path: p, a: formatValue(fa, a.Get(i)), b: missingValue})
+		case i >= a.Len():
+			*diffs = append(*diffs, fieldDiff{path: p, a: missingValue, b: formatValue(fb, b.Get(i))})
+		default:
+			diffValues(p, fa, fb, a.Get(i), b.Get(i), diffs)
+		}
+	}
+}
+
+func diffValues(path string, fa, fb protoreflect.FieldDescriptor, a, b protoreflect.Value, diffs *[]fieldDiff) {
+	if fa.Kind() == protoreflect.MessageKind && fb.Kind() == protoreflect.MessageKind &&
+		a.Message().IsValid() && b.Message().IsValid() {
+		diffMessages(path, a.Message(), b.Message(), diffs)
+		return
+	}
+	va, vb := formatValue(fa, a), formatValue(fb, b)
+	if va != vb {
+		*diffs = append(*diffs, fieldDiff{path: path, a: va, b: vb})
+	}
+}
+
+// formatField formats the value of a field, a list or a single value, as compact JSON.
+func formatField(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	var buf bytes.Buffer
+	if err := writeField(&buf, fd, v); err != nil {
+		return err.Error()
+	}
+	return buf.String()
+}
+
+// formatValue formats a single value, containers as compact JSON.
+func formatValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	if fd.Kind() != protoreflect.MessageKind {
+		return fmt.Sprint(formatScalar(fd, v))
+	}
+	var buf bytes.Buffer
+	if err := writeValue(&buf, fd, v); err != nil {
+		return err.Error()
+	}
+	return buf.String()
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/util"
+)
+
+func TestDiffStates(t *testing.T) {
+	a, _ := util.DeterministicGenesisStateCapella(t, 4)
+	b := a.Copy()
+	require.NoError(t, b.UpdateBalancesAtIndex(1, 123))
+	val, err := b.ValidatorAtIndex(2)
+	require.NoError(t, err)
+	val.ExitEpoch = 10
+	require.NoError(t, b.UpdateValidatorAtIndex(2, val))
+	require.NoError(t, b.SetSlot(5))
+
+	diffs, err := diffStates(a, b)
+	require.NoError(t, err)
+	paths := make(map[string]fieldDiff)
+	for _, d := range diffs {
+		paths[d.path] = d
+	}
+	require.Equal(t, 3, len(paths))
+	require.Equal(t, "0", paths["slot"].a)
+	require.Equal(t, "5", paths["slot"].b)
+	require.Equal(t, "123", paths["balances[1]"].b)
+	require.Equal(t, "10", paths["validators[2].exit_epoch"].b)
+}
+
+func TestDiffStates_DifferentLengths(t *testing.T) {
+	a, _ := util.DeterministicGenesisStateCapella(t, 4)
+	b := a.Copy()
+	require.NoError(t, b.AppendBalance(1))
+
+	diffs, err := diffStates(a, b)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(diffs))
+	require.Equal(t, "balances[4]", diffs[0].path)
+	require.Equal(t, missingValue, diffs[0].a)
+	require.Equal(t, "1", diffs[0].b)
+}
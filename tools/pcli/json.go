@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/theQRL/go-zond/common/hexutil"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// marshalJSON encodes a consensus type as indented JSON, in the format of the beacon API: fields keep their
+// SSZ order and snake case names, byte fields are 0x prefixed hex strings and integers are decimal strings.
+func marshalJSON(m protoreflect.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeMessage(&buf, m); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+func writeMessage(buf *bytes.Buffer, m protoreflect.Message) error {
+	buf.WriteByte('{')
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(strconv.Quote(string(fd.Name())))
+		buf.WriteByte(':')
+		if err := writeField(buf, fd, m.Get(fd)); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+func writeField(buf *bytes.Buffer, fd protoreflect.FieldDescriptor, v protoreflect.Value) error {
+	if !fd.IsList() {
+		return writeValue(buf, fd, v)
+	}
+	l := v.List()
+	buf.WriteByte('[')
+	for i := 0; i < l.Len(); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := writeValue(buf, fd, l.Get(i)); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte(']')
+	return nil
+}
+
+func writeValue(buf *bytes.Buffer, fd protoreflect.FieldDescriptor, v protoreflect.Value) error {
+	if fd.Kind() == protoreflect.MessageKind {
+		if !v.Message().IsValid() {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeMessage(buf, v.Message())
+	}
+	b, err := json.Marshal(formatScalar(fd, v))
+	if err != nil {
+		return err
+	}
+	buf.Write(b)
+	return nil
+}
+
+// formatScalar formats a non message value the way marshalJSON encodes it.
+func formatScalar(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch fd.Kind() {
+	case protoreflect.BytesKind:
+		return hexutil.Encode(v.Bytes())
+	case protoreflect.BoolKind:
+		return v.Bool()
+	case protoreflect.StringKind:
+		return v.String()
+	case protoreflect.EnumKind:
+		return strconv.FormatInt(int64(v.Enum()), 10)
+	case protoreflect.Uint32Kind, protoreflect.Uint64Kind, protoreflect.Fixed32Kind, protoreflect.Fixed64Kind:
+		return strconv.FormatUint(v.Uint(), 10)
+	case protoreflect.Int32Kind, protoreflect.Int64Kind, protoreflect.Sint32Kind, protoreflect.Sint64Kind,
+		protoreflect.Sfixed32Kind, protoreflect.Sfixed64Kind:
+		return strconv.FormatInt(v.Int(), 10)
+	default:
+		return v.String()
+	}
+}
@@ -29,6 +29,9 @@ func main() {
 	var expectedPostStatePath string
 	var sszPath string
 	var sszType string
+	var chainConfigPath string
+	var statePathA string
+	var statePathB string
 
 	customFormatter := new(prefixed.TextFormatter)
 	customFormatter.TimestampFormat = "2006-01-02 15:04:05"
@@ -136,6 +139,115 @@ func main() {
 				return nil
 			},
 		},
+		{
+			Name:  "decode",
+			Usage: "decode an SSZ block or state of any fork into JSON",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:        "ssz-path",
+					Usage:       "Path to file(ssz)",
+					Required:    true,
+					Destination: &sszPath,
+				},
+				&cli.StringFlag{
+					Name:        "data-type",
+					Usage:       "ssz file data type: block|blinded_block|state",
+					Required:    true,
+					Destination: &sszType,
+				},
+				&cli.StringFlag{
+					Name:        "chain-config-file",
+					Usage:       "Path to the chain config of the network of the block, to detect its fork from its slot",
+					Destination: &chainConfigPath,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if err := loadChainConfig(chainConfigPath); err != nil {
+					return err
+				}
+				return decodeSSZ(os.Stdout, sszPath, sszType)
+			},
+		},
+		{
+			Name:  "state-diff",
+			Usage: "diff two SSZ states field by field",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:        "state-a-path",
+					Usage:       "Path to the first state file(ssz)",
+					Required:    true,
+					Destination: &statePathA,
+				},
+				&cli.StringFlag{
+					Name:        "state-b-path",
+					Usage:       "Path to the second state file(ssz)",
+					Required:    true,
+					Destination: &statePathB,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				n, err := writeStateDiff(os.Stdout, statePathA, statePathB)
+				if err != nil {
+					return err
+				}
+				log.Infof("Found %d differing fields", n)
+				return nil
+			},
+		},
+		{
+			Name:  "verify-signatures",
+			Usage: "verify every Dilithium signature of an SSZ block against its pre-state",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:        "block-path",
+					Usage:       "Path to block file(ssz)",
+					Required:    true,
+					Destination: &blockPath,
+				},
+				&cli.StringFlag{
+					Name:        "pre-state-path",
+					Usage:       "Path to pre state file(ssz), at or before the slot of the block",
+					Required:    true,
+					Destination: &preStatePath,
+				},
+				&cli.StringFlag{
+					Name:        "chain-config-file",
+					Usage:       "Path to the chain config of the network of the block, to detect its fork from its slot",
+					Destination: &chainConfigPath,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if err := loadChainConfig(chainConfigPath); err != nil {
+					return err
+				}
+				blk, err := readBlock(blockPath, false)
+				if err != nil {
+					return err
+				}
+				st, err := readState(preStatePath)
+				if err != nil {
+					return err
+				}
+				checks, err := verifyBlockSignatures(context.Background(), st, blk)
+				if err != nil {
+					return err
+				}
+				invalid := 0
+				for _, check := range checks {
+					if check.err != nil {
+						invalid++
+						log.WithError(check.err).Errorf("Invalid %s", check.name)
+						continue
+					}
+					log.Infof("Valid %s", check.name)
+				}
+				if invalid > 0 {
+					return fmt.Errorf("%d of %d signatures are invalid", invalid, len(checks))
+				}
+				log.Infof("All (total:%d) signatures are valid", len(checks))
+				return nil
+			},
+		},
 		{
 			Name:     "state-transition",
 			Category: "state-transition",
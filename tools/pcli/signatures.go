@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/altair"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/blocks"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/helpers"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/signing"
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/transition"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/interfaces"
+	"github.com/theQRL/qrysm/v4/crypto/dilithium"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/runtime/version"
+)
+
+// signatureCheck is the outcome of the verification of one signature of a block.
+type signatureCheck struct {
+	name string
+	err  error
+}
+
+// verifyBlockSignatures checks every signature of the block against the pre-state: the proposer signature, the
+// RANDAO reveal, the slashings, the attestations, the sync aggregate, the voluntary exits and the withdrawal
+// credential changes. The pre-state is advanced to the slot of the block first, so it may be the post-state of
+// the parent block. Every signature is checked on its own, so that all the invalid ones are reported.
+func verifyBlockSignatures(ctx context.Context, preState state.BeaconState, blk interfaces.ReadOnlySignedBeaconBlock) ([]signatureCheck, error) {
+	slot := blk.Block().Slot()
+	if preState.Slot() > slot {
+		return nil, fmt.Errorf("pre-state slot %d is after block slot %d", preState.Slot(), slot)
+	}
+	st := preState.Copy()
+	if st.Slot() < slot {
+		var err error
+		st, err = transition.ProcessSlots(ctx, st, slot)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not process slots up to %d", slot)
+		}
+	}
+	body := blk.Block().Body()
+	var checks []signatureCheck
+
+	checks = append(checks, signatureCheck{name: signing.BlockSignature, err: verifyProposerSignature(ctx, st, blk)})
+	reveal := body.RandaoReveal()
+	checks = append(checks, signatureCheck{name: signing.RandaoSignature, err: verifyBatch(blocks.RandaoSignatureBatch(ctx, st, reveal[:]))})
+	for i, s := range body.ProposerSlashings() {
+		checks = append(checks, signatureCheck{
+			name: fmt.Sprintf("proposer slashing %d", i),
+			err:  blocks.VerifyProposerSlashing(st, s),
+		})
+	}
+	for i, s := range body.AttesterSlashings() {
+		checks = append(checks, signatureCheck{
+			name: fmt.Sprintf("attester slashing %d", i),
+			err:  blocks.VerifyAttesterSlashing(ctx, st, s),
+		})
+	}
+	for i, att := range body.Attestations() {
+		checks = append(checks, signatureCheck{
+			name: fmt.Sprintf("%s %d (slot %d, committee %d)", signing.AttestationSignature, i, att.Data.Slot, att.Data.CommitteeIndex),
+			err:  verifyBatch(blocks.AttestationSignatureBatch(ctx, st, []*zondpb.Attestation{att})),
+		})
+	}
+	if blk.Version() >= version.Altair {
+		check, err := verifySyncAggregate(st, body)
+		if err != nil {
+			return nil, err
+		}
+		if check != nil {
+			checks = append(checks, *check)
+		}
+	}
+	for i, exit := range body.VoluntaryExits() {
+		checks = append(checks, signatureCheck{
+			name: fmt.Sprintf("voluntary exit %d (validator %d)", i, exit.Exit.ValidatorIndex),
+			err:  verifyExitSignature(st, exit),
+		})
+	}
+	if blk.Version() >= version.Capella {
+		changes, err := body.DilithiumToExecutionChanges()
+		if err != nil {
+			return nil, err
+		}
+		for i, change := range changes {
+			checks = append(checks, signatureCheck{
+				name: fmt.Sprintf("%s %d (validator %d)", signing.DilithiumChangeSignature, i, change.Message.ValidatorIndex),
+				err:  verifyDilithiumChange(st, change),
+			})
+		}
+	}
+	return checks, nil
+}
+
+// verifyProposerSignature checks that the block is signed by the proposer of its slot.
+func verifyProposerSignature(ctx context.Context, st state.BeaconState, blk interfaces.ReadOnlySignedBeaconBlock) error {
+	proposer, err := helpers.BeaconProposerIndex(ctx, st)
+	if err != nil {
+		return errors.Wrap(err, "could not compute proposer")
+	}
+	if proposer != blk.Block().ProposerIndex() {
+		return fmt.Errorf("block proposer %d is not the proposer %d of slot %d", blk.Block().ProposerIndex(), proposer, blk.Block().Slot())
+	}
+	sig := blk.Signature()
+	return verifyBatch(blocks.BlockSignatureBatch(st, proposer, sig[:], blk.Block().HashTreeRoot))
+}
+
+// verifySyncAggregate checks the signatures of the sync committee members who took part in the sync
+// aggregate. It returns no check if none did.
+func verifySyncAggregate(st state.BeaconState, body interfaces.ReadOnlyBeaconBlockBody) (*signatureCheck, error) {
+	agg, err := body.SyncAggregate()
+	if err != nil {
+		return nil, err
+	}
+	committee, err := st.CurrentSyncCommittee()
+	if err != nil {
+		return nil, err
+	}
+	if agg.SyncCommitteeBits.Len() > uint64(len(committee.Pubkeys)) {
+		return &signatureCheck{name: signing.SyncAggregateSignature, err: errors.New("bits length exceeds committee length")}, nil
+	}
+	var keys []dilithium.PublicKey
+	for i := uint64(0); i < agg.SyncCommitteeBits.Len(); i++ {
+		if !agg.SyncCommitteeBits.BitAt(i) {
+			continue
+		}
+		key, err := dilithium.PublicKeyFromBytes(committee.Pubkeys[i])
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	return &signatureCheck{
+		name: fmt.Sprintf("%s (%d participants)", signing.SyncAggregateSignature, len(keys)),
+		err:  altair.VerifySyncCommitteeSig(st, keys, agg.SyncCommitteeSignature),
+	}, nil
+}
+
+// verifyExitSignature checks that the exit is signed by its validator with the fork version of its epoch.
+func verifyExitSignature(st state.BeaconState, signed *zondpb.SignedVoluntaryExit) error {
+	val, err := st.ValidatorAtIndexReadOnly(signed.Exit.ValidatorIndex)
+	if err != nil {
+		return err
+	}
+	domain, err := signing.Domain(st.Fork(), signed.Exit.Epoch, params.BeaconConfig().DomainVoluntaryExit, st.GenesisValidatorsRoot())
+	if err != nil {
+		return err
+	}
+	pubKey := val.PublicKey()
+	return signing.VerifySigningRoot(signed.Exit, pubKey[:], signed.Signature, domain)
+}
+
+// verifyDilithiumChange checks that the change is signed by the key its validator's withdrawal credentials
+// commit to.
+func verifyDilithiumChange(st state.BeaconState, signed *zondpb.SignedDilithiumToExecutionChange) error {
+	if _, err := blocks.ValidateDilithiumToExecutionChange(st, signed); err != nil {
+		return err
+	}
+	return verifyBatch(blocks.DilithiumChangesSignatureBatch(st, []*zondpb.SignedDilithiumToExecutionChange{signed}))
+}
+
+func verifyBatch(batch *dilithium.SignatureBatch, err error) error {
+	if err != nil {
+		return err
+	}
+	valid, err := batch.Verify()
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return signing.ErrSigFailedToVerify
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/theQRL/qrysm/v4/beacon-chain/core/signing"
+	"github.com/theQRL/qrysm/v4/beacon-chain/state"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/blocks"
+	"github.com/theQRL/qrysm/v4/crypto/bls"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/util"
+)
+
+// setCapellaGenesisConfig activates a config with every fork at genesis, registered under its own fork versions,
+// so that the blocks built on a Capella genesis state are detected as Capella blocks.
+func setCapellaGenesisConfig(t *testing.T) {
+	cfg := params.MainnetConfig().Copy()
+	params.FillTestVersions(cfg, 127)
+	cfg.AltairForkEpoch = 0
+	cfg.BellatrixForkEpoch = 0
+	cfg.CapellaForkEpoch = 0
+	cfg.ConfigName = "pcli-capella-genesis-test"
+	undo, err := params.SetActiveWithUndo(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, undo())
+	})
+}
+
+// signedTestBlock returns a Capella genesis state and a block with an attestation for the next slot, signed
+// with the keys of the genesis validators.
+func signedTestBlock(t *testing.T) (state.BeaconState, []bls.SecretKey, *zondpb.SignedBeaconBlockCapella) {
+	st, privs := util.DeterministicGenesisStateCapella(t, 64)
+	blk, err := util.GenerateFullBlockCapella(st, privs, util.DefaultBlockGenConfig(), 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(blk.Block.Body.Attestations))
+	return st, privs, blk
+}
+
+func TestVerifyBlockSignatures(t *testing.T) {
+	setCapellaGenesisConfig(t)
+	st, _, blk := signedTestBlock(t)
+	wsb, err := blocks.NewSignedBeaconBlock(blk)
+	require.NoError(t, err)
+
+	checks, err := verifyBlockSignatures(context.Background(), st, wsb)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(checks))
+	require.Equal(t, signing.BlockSignature, checks[0].name)
+	require.Equal(t, signing.RandaoSignature, checks[1].name)
+	require.Equal(t, true, strings.HasPrefix(checks[2].name, signing.AttestationSignature))
+	for _, c := range checks {
+		require.NoError(t, c.err, c.name)
+	}
+}
+
+func TestVerifyBlockSignatures_Corrupted(t *testing.T) {
+	setCapellaGenesisConfig(t)
+	tests := []struct {
+		name    string
+		corrupt func(*zondpb.BeaconBlockCapella)
+		failing string
+	}{
+		{
+			name: "attestation",
+			corrupt: func(b *zondpb.BeaconBlockCapella) {
+				b.Body.Attestations[0].Signature[0] ^= 0xff
+			},
+			failing: signing.AttestationSignature,
+		},
+		{
+			name: "randao reveal",
+			corrupt: func(b *zondpb.BeaconBlockCapella) {
+				b.Body.RandaoReveal[0] ^= 0xff
+			},
+			failing: signing.RandaoSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, privs, blk := signedTestBlock(t)
+			tt.corrupt(blk.Block)
+			// Sign the block again, so that only the corrupted signature is invalid.
+			sig, err := util.BlockSignature(st, blk.Block, privs)
+			require.NoError(t, err)
+			blk.Signature = sig.Marshal()
+			wsb, err := blocks.NewSignedBeaconBlock(blk)
+			require.NoError(t, err)
+
+			checks, err := verifyBlockSignatures(context.Background(), st, wsb)
+			require.NoError(t, err)
+			require.Equal(t, 3, len(checks))
+			for _, c := range checks {
+				if strings.HasPrefix(c.name, tt.failing) {
+					require.NotNil(t, c.err, c.name)
+				} else {
+					require.NoError(t, c.err, c.name)
+				}
+			}
+		})
+	}
+}
@@ -135,6 +135,10 @@ func (_ *MockValidator) RolesAt(_ context.Context, _ primitives.Slot) (map[[dili
 	panic("implement me")
 }
 
+func (_ *MockValidator) WaitToPerformRole(_ context.Context, _ primitives.Slot, _ iface2.ValidatorRole) {
+	panic("implement me")
+}
+
 func (_ *MockValidator) SubmitAttestation(_ context.Context, _ primitives.Slot, _ [dilithium2.CryptoPublicKeyBytes]byte) {
 	panic("implement me")
 }
@@ -163,6 +167,10 @@ func (_ *MockValidator) UpdateDomainDataCaches(_ context.Context, _ primitives.S
 	panic("implement me")
 }
 
+func (_ *MockValidator) ProcessScheduledExits(_ context.Context, _ primitives.Slot) {
+	panic("implement me")
+}
+
 func (_ *MockValidator) WaitForKeymanagerInitialization(_ context.Context) error {
 	panic("implement me")
 }
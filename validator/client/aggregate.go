@@ -10,6 +10,7 @@ import (
 	"github.com/theQRL/qrysm/v4/config/params"
 	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
 	"github.com/theQRL/qrysm/v4/crypto/dilithium"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
 	"github.com/theQRL/qrysm/v4/monitoring/tracing"
 	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
 	validatorpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1/validator-client"
@@ -116,7 +117,16 @@ func (v *validator) SubmitAggregateAndProof(ctx context.Context, slot primitives
 }
 
 // Signs input slot with domain selection proof. This is used to create the signature for aggregator selection.
+// Selection proofs are cached, as the same proof is needed to subscribe to subnets, to determine the roles at
+// the slot and to submit the aggregate.
 func (v *validator) signSlotWithSelectionProof(ctx context.Context, pubKey [dilithium2.CryptoPublicKeyBytes]byte, slot primitives.Slot) (signature []byte, err error) {
+	key := selectionProofKey{slot: slot, pubKey: pubKey}
+	if v.selectionProofCache != nil {
+		if cached, ok := v.selectionProofCache.Get(key); ok {
+			return bytesutil.SafeCopyBytes(cached.([]byte)), nil
+		}
+	}
+
 	domain, err := v.domainData(ctx, slots.ToEpoch(slot), params.BeaconConfig().DomainSelectionProof[:])
 	if err != nil {
 		return nil, err
@@ -139,7 +149,11 @@ func (v *validator) signSlotWithSelectionProof(ctx context.Context, pubKey [dili
 		return nil, err
 	}
 
-	return sig.Marshal(), nil
+	signature = sig.Marshal()
+	if v.selectionProofCache != nil {
+		v.selectionProofCache.Add(key, bytesutil.SafeCopyBytes(signature))
+	}
+	return signature, nil
 }
 
 // waitToSlotTwoThirds waits until two third through the current slot period
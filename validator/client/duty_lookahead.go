@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/config/features"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/time/slots"
+	"go.opencensus.io/trace"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// selectionProofCacheSize bounds the number of cached selection proofs. A validator
+// key needs at most one selection proof per epoch, and proofs are computed for the
+// current and the next epoch.
+const selectionProofCacheSize = 1 << 14
+
+// prefetchedDuties are the duties of an upcoming epoch, fetched ahead of the epoch start.
+type prefetchedDuties struct {
+	req  *zondpb.DutiesRequest
+	resp *zondpb.DutiesResponse
+	// dependentRoot is the head block root the duties were computed from. See dutiesDependentRoot.
+	dependentRoot [32]byte
+}
+
+type selectionProofKey struct {
+	slot   primitives.Slot
+	pubKey [dilithium2.CryptoPublicKeyBytes]byte
+}
+
+// prefetchDuties fetches the duties of the given epoch ahead of its start, and
+// pre-computes everything the duties need that does not depend on the head of the chain.
+// The proposer duties of the epoch depend on the block of the last slot before it, so the
+// duties are only fetched once that block was processed, or a third into the slot if it is late.
+func (v *validator) prefetchDuties(ctx context.Context, epoch primitives.Epoch) {
+	ctx, span := trace.StartSpan(ctx, "validator.prefetchDuties")
+	defer span.End()
+
+	start, err := slots.EpochStart(epoch)
+	if err != nil {
+		log.WithError(err).Error("Could not compute start slot of epoch to prefetch duties for")
+		return
+	}
+	ctx, cancel := context.WithDeadline(ctx, v.SlotDeadline(start))
+	defer cancel()
+	if start > 0 {
+		v.waitOneThirdOrValidBlock(ctx, start-1)
+	}
+
+	req, err := v.dutiesRequest(ctx, epoch)
+	if err != nil {
+		log.WithError(err).Debug("Could not build request to prefetch duties")
+		return
+	}
+	root, ok, err := v.dutiesDependentRoot(ctx, epoch)
+	if err != nil || !ok {
+		log.WithError(err).WithField("epoch", epoch).Debug("Could not get the head the duties to prefetch depend on")
+		return
+	}
+	resp, err := v.validatorClient.GetDuties(ctx, req)
+	if err != nil {
+		log.WithError(err).WithField("epoch", epoch).Debug("Could not prefetch duties")
+		return
+	}
+	// The head may have changed while the duties were computed, in which case it is unknown
+	// which head they were computed from.
+	after, ok, err := v.dutiesDependentRoot(ctx, epoch)
+	if err != nil || !ok || after != root {
+		log.WithError(err).WithField("epoch", epoch).Debug("Head changed while prefetching duties")
+		return
+	}
+
+	v.prefetchedDutiesLock.Lock()
+	v.prefetchedDuties = &prefetchedDuties{req: req, resp: resp, dependentRoot: root}
+	v.prefetchedDutiesLock.Unlock()
+
+	v.precomputeDuties(ctx, resp)
+}
+
+// takePrefetchedDuties returns the prefetched duties if they were fetched with the
+// same request, that is for the same epoch and the same set of validating keys, and
+// the head they depend on did not change since, for instance after a reorg or a late block.
+// Prefetched duties are only ever used once.
+func (v *validator) takePrefetchedDuties(ctx context.Context, req *zondpb.DutiesRequest) (*zondpb.DutiesResponse, bool) {
+	v.prefetchedDutiesLock.Lock()
+	prefetched := v.prefetchedDuties
+	v.prefetchedDuties = nil
+	v.prefetchedDutiesLock.Unlock()
+	if prefetched == nil || !proto.Equal(prefetched.req, req) {
+		return nil, false
+	}
+	root, ok, err := v.dutiesDependentRoot(ctx, req.Epoch)
+	if err != nil || !ok || root != prefetched.dependentRoot {
+		log.WithError(err).WithField("epoch", req.Epoch).Debug("Discarding prefetched duties of a previous head")
+		return nil, false
+	}
+	return prefetched.resp, true
+}
+
+// dutiesDependentRoot returns the root of the head block before the start of the given epoch.
+// The proposer duties of the epoch depend on it, and the attester duties depend on one of its
+// ancestors, so duties computed from the same head are the same. It returns false if the head
+// is already in the epoch, as the head before the epoch is then unknown.
+func (v *validator) dutiesDependentRoot(ctx context.Context, epoch primitives.Epoch) ([32]byte, bool, error) {
+	start, err := slots.EpochStart(epoch)
+	if err != nil {
+		return [32]byte{}, false, err
+	}
+	head, err := v.beaconClient.GetChainHead(ctx, &emptypb.Empty{})
+	if err != nil {
+		return [32]byte{}, false, err
+	}
+	if head.HeadSlot >= start {
+		return [32]byte{}, false, nil
+	}
+	return bytesutil.ToBytes32(head.HeadBlockRoot), true, nil
+}
+
+// precomputeDuties warms the domain data cache for the epochs of the given duties and
+// signs the aggregation selection proof of every attester duty ahead of its slot. This
+// leaves only the signatures over data known at the slot itself on the critical path.
+func (v *validator) precomputeDuties(ctx context.Context, resp *zondpb.DutiesResponse) {
+	ctx, span := trace.StartSpan(ctx, "validator.precomputeDuties")
+	defer span.End()
+
+	duties := make([]*zondpb.DutiesResponse_Duty, 0, len(resp.CurrentEpochDuties)+len(resp.NextEpochDuties))
+	for _, epochDuties := range [][]*zondpb.DutiesResponse_Duty{resp.CurrentEpochDuties, resp.NextEpochDuties} {
+		for _, duty := range epochDuties {
+			if duty == nil || (duty.Status != zondpb.ValidatorStatus_ACTIVE && duty.Status != zondpb.ValidatorStatus_EXITING) {
+				continue
+			}
+			duties = append(duties, duty)
+		}
+	}
+	if len(duties) == 0 {
+		return
+	}
+
+	updatedEpochs := make(map[primitives.Epoch]bool)
+	for _, duty := range duties {
+		epoch := slots.ToEpoch(duty.AttesterSlot)
+		if !updatedEpochs[epoch] {
+			v.UpdateDomainDataCaches(ctx, duty.AttesterSlot)
+			updatedEpochs[epoch] = true
+		}
+	}
+
+	workers := features.Get().DutyLookaheadWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for _, duty := range duties {
+		if ctx.Err() != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(duty *zondpb.DutiesResponse_Duty) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if _, err := v.signSlotWithSelectionProof(ctx, bytesutil.ToBytes2592(duty.PublicKey), duty.AttesterSlot); err != nil {
+				log.WithError(err).WithField("slot", duty.AttesterSlot).Debug("Could not pre-compute selection proof")
+				ValidatorSelectionProofPrecomputedVec.WithLabelValues("failure").Inc()
+				return
+			}
+			ValidatorSelectionProofPrecomputedVec.WithLabelValues("success").Inc()
+		}(duty)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	lruwrpr "github.com/theQRL/qrysm/v4/cache/lru"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	validatormock "github.com/theQRL/qrysm/v4/testing/validator-mock"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestTakePrefetchedDuties(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	beaconClient := validatormock.NewMockBeaconChainClient(ctrl)
+	head := &zondpb.ChainHead{HeadSlot: params.BeaconConfig().SlotsPerEpoch*2 - 1, HeadBlockRoot: []byte{'a'}}
+	beaconClient.EXPECT().GetChainHead(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(context.Context, *emptypb.Empty, ...grpc.CallOption) (*zondpb.ChainHead, error) {
+			return head, nil
+		}).AnyTimes()
+
+	ctx := context.Background()
+	req := &zondpb.DutiesRequest{Epoch: 2, PublicKeys: [][]byte{{1}, {2}}}
+	resp := &zondpb.DutiesResponse{CurrentEpochDuties: []*zondpb.DutiesResponse_Duty{{ValidatorIndex: 1}}}
+	prefetched := func() *prefetchedDuties {
+		return &prefetchedDuties{req: req, resp: resp, dependentRoot: [32]byte{'a'}}
+	}
+	v := &validator{beaconClient: beaconClient}
+
+	_, ok := v.takePrefetchedDuties(ctx, req)
+	assert.Equal(t, false, ok, "Expected no prefetched duties")
+
+	v.prefetchedDuties = prefetched()
+	_, ok = v.takePrefetchedDuties(ctx, &zondpb.DutiesRequest{Epoch: 3, PublicKeys: [][]byte{{1}, {2}}})
+	assert.Equal(t, false, ok, "Expected prefetched duties of another epoch not to be used")
+	assert.Equal(t, (*prefetchedDuties)(nil), v.prefetchedDuties, "Expected prefetched duties to be cleared")
+
+	v.prefetchedDuties = prefetched()
+	_, ok = v.takePrefetchedDuties(ctx, &zondpb.DutiesRequest{Epoch: 2, PublicKeys: [][]byte{{1}}})
+	assert.Equal(t, false, ok, "Expected prefetched duties of other keys not to be used")
+
+	v.prefetchedDuties = prefetched()
+	got, ok := v.takePrefetchedDuties(ctx, &zondpb.DutiesRequest{Epoch: 2, PublicKeys: [][]byte{{1}, {2}}})
+	require.Equal(t, true, ok, "Expected prefetched duties to be used")
+	assert.DeepEqual(t, resp, got)
+
+	// A late block or a reorg changed the head the duties depend on.
+	head = &zondpb.ChainHead{HeadSlot: params.BeaconConfig().SlotsPerEpoch*2 - 1, HeadBlockRoot: []byte{'b'}}
+	v.prefetchedDuties = prefetched()
+	_, ok = v.takePrefetchedDuties(ctx, req)
+	assert.Equal(t, false, ok, "Expected prefetched duties of another head not to be used")
+
+	// The head is in the epoch of the duties, so the head before it is unknown.
+	head = &zondpb.ChainHead{HeadSlot: params.BeaconConfig().SlotsPerEpoch * 2, HeadBlockRoot: []byte{'a'}}
+	v.prefetchedDuties = prefetched()
+	_, ok = v.takePrefetchedDuties(ctx, req)
+	assert.Equal(t, false, ok, "Expected prefetched duties not to be used when the head they depend on is unknown")
+}
+
+func TestUpdateDuties_UsesPrefetchedDuties(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := validatormock.NewMockValidatorClient(ctrl)
+
+	kp := randKeypair(t)
+	slot := params.BeaconConfig().SlotsPerEpoch
+	resp := &zondpb.DutiesResponse{
+		CurrentEpochDuties: []*zondpb.DutiesResponse_Duty{
+			{
+				AttesterSlot:   slot,
+				ValidatorIndex: 200,
+				CommitteeIndex: 100,
+				PublicKey:      kp.pub[:],
+				Status:         zondpb.ValidatorStatus_ACTIVE,
+			},
+		},
+	}
+	beaconClient := validatormock.NewMockBeaconChainClient(ctrl)
+	beaconClient.EXPECT().GetChainHead(gomock.Any(), gomock.Any()).
+		Return(&zondpb.ChainHead{HeadSlot: slot - 1, HeadBlockRoot: []byte{'a'}}, nil)
+	v := validator{
+		keyManager:      newMockKeymanager(t, kp),
+		validatorClient: client,
+		beaconClient:    beaconClient,
+		prefetchedDuties: &prefetchedDuties{
+			req:           &zondpb.DutiesRequest{Epoch: 1, PublicKeys: [][]byte{kp.pub[:]}},
+			resp:          resp,
+			dependentRoot: [32]byte{'a'},
+		},
+	}
+	client.EXPECT().GetDuties(gomock.Any(), gomock.Any()).Times(0)
+	client.EXPECT().DomainData(gomock.Any(), gomock.Any()).Return(&zondpb.DomainResponse{SignatureDomain: make([]byte, 32)}, nil).AnyTimes()
+	client.EXPECT().SubscribeCommitteeSubnets(gomock.Any(), gomock.Any(), gomock.Any()).Return(&emptypb.Empty{}, nil).AnyTimes()
+
+	require.NoError(t, v.UpdateDuties(context.Background(), slot))
+	assert.DeepEqual(t, resp, v.duties)
+}
+
+func TestSignSlotWithSelectionProof_Cached(t *testing.T) {
+	v, m, validatorKey, finish := setup(t)
+	defer finish()
+	v.selectionProofCache = lruwrpr.New(selectionProofCacheSize)
+	var pubKey [dilithium2.CryptoPublicKeyBytes]byte
+	copy(pubKey[:], validatorKey.PublicKey().Marshal())
+
+	m.validatorClient.EXPECT().DomainData(gomock.Any(), gomock.Any()).
+		Return(&zondpb.DomainResponse{SignatureDomain: make([]byte, 32)}, nil).Times(1)
+
+	first, err := v.signSlotWithSelectionProof(context.Background(), pubKey, primitives.Slot(3))
+	require.NoError(t, err)
+	second, err := v.signSlotWithSelectionProof(context.Background(), pubKey, primitives.Slot(3))
+	require.NoError(t, err)
+	assert.DeepEqual(t, first, second)
+}
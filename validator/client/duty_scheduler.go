@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	prysmTime "github.com/theQRL/qrysm/v4/time"
+	"github.com/theQRL/qrysm/v4/time/slots"
+	"github.com/theQRL/qrysm/v4/validator/client/iface"
+)
+
+// dutyJob is a single duty of a validator key at a slot.
+type dutyJob struct {
+	role     iface.ValidatorRole
+	slot     primitives.Slot
+	pubKey   [dilithium2.CryptoPublicKeyBytes]byte
+	deadline time.Time
+}
+
+// dutyScheduler performs validator duties on a bounded pool of workers. Signing with
+// Dilithium is CPU bound, so running every duty of a slot at once only makes all of them
+// late. Instead, duties are started in order of their deadline, at most workers at a time.
+// A duty only takes a worker once it can be performed, so that duties waiting for their
+// time in the slot do not hold workers other duties could sign with.
+type dutyScheduler struct {
+	workers chan struct{}
+	wait    func(ctx context.Context, v iface.Validator, role iface.ValidatorRole, slot primitives.Slot)
+	perform func(ctx context.Context, v iface.Validator, role iface.ValidatorRole, slot primitives.Slot, pubKey [dilithium2.CryptoPublicKeyBytes]byte)
+}
+
+// newDutyScheduler creates a scheduler running at most the given number of duties
+// concurrently. A non-positive number of workers defaults to the number of available CPUs.
+func newDutyScheduler(workers int) *dutyScheduler {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return &dutyScheduler{
+		workers: make(chan struct{}, workers),
+		wait:    waitToPerformRole,
+		perform: performRole,
+	}
+}
+
+// dutyDeadline returns the time by which a duty of the given role should have been performed
+// to be useful to the network, given the end of the slot the duty is assigned to.
+//
+// Blocks need to be broadcast before attesters vote at one third of the slot, attestations
+// and sync committee messages need to be broadcast before aggregation starts at two thirds
+// of the slot, and aggregates need to be broadcast before the end of the slot.
+func dutyDeadline(slotEnd time.Time, role iface.ValidatorRole) time.Time {
+	start := slotEnd.Add(-time.Duration(params.BeaconConfig().SecondsPerSlot) * time.Second)
+	switch role {
+	case iface.RoleProposer:
+		return start.Add(slots.DivideSlotBy(3))
+	case iface.RoleAttester, iface.RoleSyncCommittee:
+		return start.Add(2 * slots.DivideSlotBy(3))
+	default:
+		return slotEnd
+	}
+}
+
+// dutyJobs flattens the roles of every validator key at a slot into jobs sorted by deadline.
+func dutyJobs(slotEnd time.Time, slot primitives.Slot, allRoles map[[dilithium2.CryptoPublicKeyBytes]byte][]iface.ValidatorRole) []dutyJob {
+	jobs := make([]dutyJob, 0, len(allRoles))
+	for pubKey, roles := range allRoles {
+		for _, role := range roles {
+			if role == iface.RoleUnknown {
+				log.WithField("pubKey", fmt.Sprintf("%#x", bytesutil.Trunc(pubKey[:]))).Trace("No active roles, doing nothing")
+				continue
+			}
+			jobs = append(jobs, dutyJob{
+				role:     role,
+				slot:     slot,
+				pubKey:   pubKey,
+				deadline: dutyDeadline(slotEnd, role),
+			})
+		}
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].deadline.Before(jobs[j].deadline)
+	})
+	return jobs
+}
+
+// schedule queues the jobs for execution and returns immediately. Each job is added to the
+// wait group, which is marked done once the job finished or was dropped. Jobs are sorted by
+// deadline, and their roles can be performed in the same order within the slot, so each job
+// waits for its time in the slot before taking a worker. Jobs which could not start before the
+// slot context is done are dropped.
+func (s *dutyScheduler) schedule(slotCtx context.Context, v iface.Validator, jobs []dutyJob, wg *sync.WaitGroup) {
+	wg.Add(len(jobs))
+	go func() {
+		for i, job := range jobs {
+			s.wait(slotCtx, v, job.role, job.slot)
+			acquired := false
+			if slotCtx.Err() == nil {
+				select {
+				case s.workers <- struct{}{}:
+					acquired = true
+				case <-slotCtx.Done():
+				}
+			}
+			if !acquired {
+				for _, dropped := range jobs[i:] {
+					log.WithFields(logrus.Fields{
+						"slot":   dropped.slot,
+						"role":   roleName(dropped.role),
+						"pubKey": fmt.Sprintf("%#x", bytesutil.Trunc(dropped.pubKey[:])),
+					}).Warn("Dropped duty that could not be started before the end of the slot")
+					ValidatorDutyDeadlineMissedVec.WithLabelValues(roleName(dropped.role)).Inc()
+					wg.Done()
+				}
+				return
+			}
+			go func(job dutyJob) {
+				defer func() {
+					<-s.workers
+					wg.Done()
+				}()
+				s.perform(slotCtx, v, job.role, job.slot, job.pubKey)
+				recordDutyDeadlineMargin(job.role, job.deadline)
+			}(job)
+		}
+	}()
+}
+
+func waitToPerformRole(ctx context.Context, v iface.Validator, role iface.ValidatorRole, slot primitives.Slot) {
+	v.WaitToPerformRole(ctx, slot, role)
+}
+
+// recordDutyDeadlineMargin records how long before its deadline a duty was completed.
+func recordDutyDeadlineMargin(role iface.ValidatorRole, deadline time.Time) {
+	margin := deadline.Sub(prysmTime.Now())
+	if margin < 0 {
+		ValidatorDutyDeadlineMissedVec.WithLabelValues(roleName(role)).Inc()
+		return
+	}
+	ValidatorDutyDeadlineMarginHistogram.WithLabelValues(roleName(role)).Observe(margin.Seconds())
+}
+
+func roleName(role iface.ValidatorRole) string {
+	switch role {
+	case iface.RoleAttester:
+		return "attester"
+	case iface.RoleProposer:
+		return "proposer"
+	case iface.RoleAggregator:
+		return "aggregator"
+	case iface.RoleSyncCommittee:
+		return "sync_committee"
+	case iface.RoleSyncCommitteeAggregator:
+		return "sync_committee_aggregator"
+	default:
+		return "unknown"
+	}
+}
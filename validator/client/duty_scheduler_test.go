@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/validator/client/iface"
+	"github.com/theQRL/qrysm/v4/validator/client/testutil"
+)
+
+func TestDutyDeadline(t *testing.T) {
+	slotEnd := time.Unix(1000, 0)
+	slotDuration := time.Duration(params.BeaconConfig().SecondsPerSlot) * time.Second
+	slotStart := slotEnd.Add(-slotDuration)
+
+	assert.Equal(t, slotStart.Add(slotDuration/3), dutyDeadline(slotEnd, iface.RoleProposer))
+	assert.Equal(t, slotStart.Add(2*slotDuration/3), dutyDeadline(slotEnd, iface.RoleAttester))
+	assert.Equal(t, slotStart.Add(2*slotDuration/3), dutyDeadline(slotEnd, iface.RoleSyncCommittee))
+	assert.Equal(t, slotEnd, dutyDeadline(slotEnd, iface.RoleAggregator))
+	assert.Equal(t, slotEnd, dutyDeadline(slotEnd, iface.RoleSyncCommitteeAggregator))
+}
+
+func TestDutyJobs_SortedByDeadline(t *testing.T) {
+	slotEnd := time.Unix(1000, 0)
+	allRoles := map[[dilithium2.CryptoPublicKeyBytes]byte][]iface.ValidatorRole{
+		{1}: {iface.RoleAggregator, iface.RoleAttester},
+		{2}: {iface.RoleUnknown},
+		{3}: {iface.RoleSyncCommitteeAggregator, iface.RoleProposer},
+	}
+
+	jobs := dutyJobs(slotEnd, 5, allRoles)
+	require.Equal(t, 4, len(jobs))
+	assert.Equal(t, iface.RoleProposer, jobs[0].role)
+	assert.Equal(t, iface.RoleAttester, jobs[1].role)
+	for i, job := range jobs {
+		assert.Equal(t, primitives.Slot(5), job.slot)
+		if i > 0 {
+			assert.Equal(t, false, job.deadline.Before(jobs[i-1].deadline), "jobs are not sorted by deadline")
+		}
+	}
+}
+
+func TestDutyScheduler_BoundsConcurrency(t *testing.T) {
+	var lock sync.Mutex
+	running, maxRunning, performed := 0, 0, 0
+	s := newDutyScheduler(2)
+	s.perform = func(_ context.Context, _ iface.Validator, _ iface.ValidatorRole, _ primitives.Slot, _ [dilithium2.CryptoPublicKeyBytes]byte) {
+		lock.Lock()
+		running++
+		performed++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		lock.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		lock.Lock()
+		running--
+		lock.Unlock()
+	}
+
+	jobs := make([]dutyJob, 6)
+	for i := range jobs {
+		jobs[i] = dutyJob{role: iface.RoleAttester, pubKey: [dilithium2.CryptoPublicKeyBytes]byte{byte(i)}, deadline: time.Now().Add(time.Minute)}
+	}
+	var wg sync.WaitGroup
+	s.schedule(context.Background(), &testutil.FakeValidator{}, jobs, &wg)
+	wg.Wait()
+
+	assert.Equal(t, 6, performed)
+	assert.Equal(t, 2, maxRunning)
+}
+
+func TestDutyScheduler_DropsJobsAfterSlotEnds(t *testing.T) {
+	performed := false
+	s := newDutyScheduler(1)
+	s.perform = func(_ context.Context, _ iface.Validator, _ iface.ValidatorRole, _ primitives.Slot, _ [dilithium2.CryptoPublicKeyBytes]byte) {
+		performed = true
+	}
+	// Occupy the only worker, so that no job can start before the slot ends.
+	s.workers <- struct{}{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var wg sync.WaitGroup
+	s.schedule(ctx, &testutil.FakeValidator{}, []dutyJob{{role: iface.RoleAttester}, {role: iface.RoleAggregator}}, &wg)
+	wg.Wait()
+
+	assert.Equal(t, false, performed)
+}
+
+func TestDutyScheduler_WaitsBeforeTakingWorker(t *testing.T) {
+	var lock sync.Mutex
+	var performed []iface.ValidatorRole
+	waiting, release := make(chan struct{}), make(chan struct{})
+	s := newDutyScheduler(1)
+	s.wait = func(_ context.Context, _ iface.Validator, role iface.ValidatorRole, _ primitives.Slot) {
+		if role == iface.RoleAggregator {
+			close(waiting)
+			<-release
+		}
+	}
+	s.perform = func(_ context.Context, _ iface.Validator, role iface.ValidatorRole, _ primitives.Slot, _ [dilithium2.CryptoPublicKeyBytes]byte) {
+		lock.Lock()
+		performed = append(performed, role)
+		lock.Unlock()
+	}
+
+	var wg sync.WaitGroup
+	s.schedule(context.Background(), &testutil.FakeValidator{}, []dutyJob{{role: iface.RoleProposer}, {role: iface.RoleAggregator}}, &wg)
+	<-waiting
+	// The aggregator waits for its time in the slot without holding the only worker.
+	select {
+	case s.workers <- struct{}{}:
+		<-s.workers
+	case <-time.After(5 * time.Second):
+		t.Fatal("Worker held by a duty waiting for its time in the slot")
+	}
+	close(release)
+	wg.Wait()
+
+	assert.DeepEqual(t, []iface.ValidatorRole{iface.RoleProposer, iface.RoleAggregator}, performed)
+}
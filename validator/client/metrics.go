@@ -8,6 +8,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/sirupsen/logrus"
 	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/config/features"
 	"github.com/theQRL/qrysm/v4/config/params"
 	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
 	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
@@ -212,6 +213,51 @@ var (
 			"pubkey",
 		},
 	)
+	// ValidatorDutyDeadlineMarginHistogram used to track how long before its deadline a duty was performed.
+	ValidatorDutyDeadlineMarginHistogram = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "validator",
+			Name:      "duty_deadline_margin_seconds",
+			Help:      "Time remaining before the deadline of a duty when it was performed, by role",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 4, 8, 16, 32},
+		},
+		[]string{
+			"role",
+		},
+	)
+	// ValidatorDutyDeadlineMissedVec used to count duties performed after, or dropped at, their deadline.
+	ValidatorDutyDeadlineMissedVec = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "validator",
+			Name:      "duty_deadline_missed_total",
+			Help:      "Count of duties performed after their deadline or dropped, by role",
+		},
+		[]string{
+			"role",
+		},
+	)
+	// ValidatorSelectionProofPrecomputedVec used to count selection proofs signed ahead of their slot.
+	ValidatorSelectionProofPrecomputedVec = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "validator",
+			Name:      "selection_proofs_precomputed_total",
+			Help:      "Count of aggregation selection proofs signed ahead of their slot, by result",
+		},
+		[]string{
+			"result",
+		},
+	)
+	// ValidatorScheduledExitsVec used to count scheduled voluntary exits which were submitted or failed.
+	ValidatorScheduledExitsVec = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "validator",
+			Name:      "scheduled_exits_total",
+			Help:      "Count of scheduled voluntary exits processed when due, by status",
+		},
+		[]string{
+			"status",
+		},
+	)
 )
 
 // LogValidatorGainsAndLosses logs important metrics related to this validator client's
@@ -223,7 +269,7 @@ func (v *validator) LogValidatorGainsAndLosses(ctx context.Context, slot primiti
 		// Do nothing unless we are at the end of the epoch, and not in the first epoch.
 		return nil
 	}
-	if !v.logValidatorBalances {
+	if !v.logValidatorBalances && !features.Get().EnablePerformanceHistory {
 		return nil
 	}
 
@@ -257,6 +303,15 @@ func (v *validator) LogValidatorGainsAndLosses(ctx context.Context, slot primiti
 			v.voteStats.startEpoch = prevEpoch
 		}
 	}
+	if features.Get().EnablePerformanceHistory {
+		if err := v.savePerformanceHistory(ctx, resp, prevEpoch); err != nil {
+			log.WithError(err).Error("Could not save validator performance history")
+		}
+	}
+	if !v.logValidatorBalances {
+		return nil
+	}
+
 	v.prevBalanceLock.Lock()
 	for i, pubKey := range resp.PublicKeys {
 		v.logForEachValidator(i, pubKey, resp, slot, prevEpoch)
@@ -0,0 +1,200 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prysmaticlabs/go-bitfield"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/config/features"
+	"github.com/theQRL/qrysm/v4/consensus-types/interfaces"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/time/slots"
+	"github.com/theQRL/qrysm/v4/validator/db/kv"
+)
+
+// performanceHistoryRetention is the number of epochs of performance records kept in the
+// validator database, which is about a year of history.
+const performanceHistoryRetention = primitives.Epoch(82125)
+
+// dutyOutcomes counts the duties a validator key performed or missed during an epoch, which
+// the beacon node does not report as part of the validator performance.
+type dutyOutcomes struct {
+	attestation           *submittedAttestation
+	inclusionDistance     primitives.Slot
+	proposalsMade         uint64
+	proposalsMissed       uint64
+	syncCommitteeMessages uint64
+	syncCommitteeMissed   uint64
+}
+
+// submittedAttestation identifies the attestation submitted by a validator key, so that the first
+// block including it can be found.
+type submittedAttestation struct {
+	slot             primitives.Slot
+	dataRoot         [32]byte
+	indexInCommittee uint64
+}
+
+// performanceTracker collects the duty outcomes of validator keys until the performance
+// records of their epoch are saved.
+type performanceTracker struct {
+	sync.Mutex
+	outcomes map[primitives.Epoch]map[[dilithium2.CryptoPublicKeyBytes]byte]*dutyOutcomes
+}
+
+// recordProposal records whether a block was proposed by the key at its assigned slot.
+func (v *validator) recordProposal(slot primitives.Slot, pubKey [dilithium2.CryptoPublicKeyBytes]byte, proposed bool) {
+	v.recordDutyOutcome(slot, pubKey, func(o *dutyOutcomes) {
+		if proposed {
+			o.proposalsMade++
+		} else {
+			o.proposalsMissed++
+		}
+	})
+}
+
+// recordSyncCommitteeMessage records whether a sync committee message was submitted by the key at a slot.
+func (v *validator) recordSyncCommitteeMessage(slot primitives.Slot, pubKey [dilithium2.CryptoPublicKeyBytes]byte, submitted bool) {
+	v.recordDutyOutcome(slot, pubKey, func(o *dutyOutcomes) {
+		if submitted {
+			o.syncCommitteeMessages++
+		} else {
+			o.syncCommitteeMissed++
+		}
+	})
+}
+
+// recordAttestation records the attestation submitted by the key, whose bit is set at its index in
+// the committee.
+func (v *validator) recordAttestation(data *zondpb.AttestationData, pubKey [dilithium2.CryptoPublicKeyBytes]byte, indexInCommittee uint64) {
+	if !features.Get().EnablePerformanceHistory {
+		return
+	}
+	root, err := data.HashTreeRoot()
+	if err != nil {
+		log.WithError(err).Debug("Could not hash attestation data for performance history")
+		return
+	}
+	v.recordDutyOutcome(data.Slot, pubKey, func(o *dutyOutcomes) {
+		o.attestation = &submittedAttestation{slot: data.Slot, dataRoot: root, indexInCommittee: indexInCommittee}
+	})
+}
+
+// recordAttestationInclusions records the inclusion distance of the submitted attestations included
+// in the block for the first time, which is the slot of the block minus the slot of the attestation.
+func (v *validator) recordAttestationInclusions(blk interfaces.ReadOnlySignedBeaconBlock) {
+	if !features.Get().EnablePerformanceHistory {
+		return
+	}
+	included := make(map[[32]byte][]bitfield.Bitlist)
+	for _, att := range blk.Block().Body().Attestations() {
+		root, err := att.Data.HashTreeRoot()
+		if err != nil {
+			log.WithError(err).Debug("Could not hash attestation data for performance history")
+			continue
+		}
+		included[root] = append(included[root], att.AggregationBits)
+	}
+	if len(included) == 0 {
+		return
+	}
+	slot := blk.Block().Slot()
+	v.performance.Lock()
+	defer v.performance.Unlock()
+	for _, byPubKey := range v.performance.outcomes {
+		for _, o := range byPubKey {
+			if o.attestation == nil || o.inclusionDistance != 0 || o.attestation.slot >= slot {
+				continue
+			}
+			for _, bits := range included[o.attestation.dataRoot] {
+				if o.attestation.indexInCommittee < bits.Len() && bits.BitAt(o.attestation.indexInCommittee) {
+					o.inclusionDistance = slot - o.attestation.slot
+					break
+				}
+			}
+		}
+	}
+}
+
+func (v *validator) recordDutyOutcome(slot primitives.Slot, pubKey [dilithium2.CryptoPublicKeyBytes]byte, update func(*dutyOutcomes)) {
+	if !features.Get().EnablePerformanceHistory {
+		return
+	}
+	epoch := slots.ToEpoch(slot)
+	v.performance.Lock()
+	defer v.performance.Unlock()
+	if v.performance.outcomes == nil {
+		v.performance.outcomes = make(map[primitives.Epoch]map[[dilithium2.CryptoPublicKeyBytes]byte]*dutyOutcomes)
+	}
+	byPubKey, ok := v.performance.outcomes[epoch]
+	if !ok {
+		byPubKey = make(map[[dilithium2.CryptoPublicKeyBytes]byte]*dutyOutcomes)
+		v.performance.outcomes[epoch] = byPubKey
+	}
+	o, ok := byPubKey[pubKey]
+	if !ok {
+		o = &dutyOutcomes{}
+		byPubKey[pubKey] = o
+	}
+	update(o)
+}
+
+// takeDutyOutcomes returns the duty outcomes recorded for an epoch, and discards the
+// outcomes of that epoch and of any earlier epoch.
+func (v *validator) takeDutyOutcomes(epoch primitives.Epoch) map[[dilithium2.CryptoPublicKeyBytes]byte]*dutyOutcomes {
+	v.performance.Lock()
+	defer v.performance.Unlock()
+	outcomes := v.performance.outcomes[epoch]
+	for e := range v.performance.outcomes {
+		if e <= epoch {
+			delete(v.performance.outcomes, e)
+		}
+	}
+	return outcomes
+}
+
+// savePerformanceHistory combines the validator performance reported by the beacon node with
+// the duty outcomes recorded by the validator client into a performance record of each key
+// for the epoch, saves them to the database and prunes records past the retention period.
+func (v *validator) savePerformanceHistory(ctx context.Context, resp *zondpb.ValidatorPerformanceResponse, epoch primitives.Epoch) error {
+	outcomes := v.takeDutyOutcomes(epoch)
+	pubKeys := make([][dilithium2.CryptoPublicKeyBytes]byte, len(resp.PublicKeys))
+	records := make([]*kv.PerformanceRecord, len(resp.PublicKeys))
+	for i, pk := range resp.PublicKeys {
+		pubKeys[i] = bytesutil.ToBytes2592(pk)
+		record := &kv.PerformanceRecord{Epoch: epoch}
+		if i < len(resp.CorrectlyVotedSource) {
+			record.CorrectlyVotedSource = resp.CorrectlyVotedSource[i]
+		}
+		if i < len(resp.CorrectlyVotedTarget) {
+			record.CorrectlyVotedTarget = resp.CorrectlyVotedTarget[i]
+		}
+		if i < len(resp.CorrectlyVotedHead) {
+			record.CorrectlyVotedHead = resp.CorrectlyVotedHead[i]
+		}
+		if i < len(resp.BalancesBeforeEpochTransition) {
+			record.BalanceBefore = resp.BalancesBeforeEpochTransition[i]
+		}
+		if i < len(resp.BalancesAfterEpochTransition) {
+			record.BalanceAfter = resp.BalancesAfterEpochTransition[i]
+		}
+		if o, ok := outcomes[pubKeys[i]]; ok {
+			record.InclusionDistance = o.inclusionDistance
+			record.ProposalsMade = o.proposalsMade
+			record.ProposalsMissed = o.proposalsMissed
+			record.SyncCommitteeMessages = o.syncCommitteeMessages
+			record.SyncCommitteeMissed = o.syncCommitteeMissed
+		}
+		records[i] = record
+	}
+	if err := v.db.SavePerformanceRecords(ctx, pubKeys, records); err != nil {
+		return err
+	}
+	if epoch > performanceHistoryRetention {
+		return v.db.PrunePerformanceHistory(ctx, epoch-performanceHistoryRetention)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prysmaticlabs/go-bitfield"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/config/features"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/blocks"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/testing/util"
+	dbTest "github.com/theQRL/qrysm/v4/validator/db/testing"
+)
+
+func TestRecordDutyOutcome_Disabled(t *testing.T) {
+	v := &validator{}
+	v.recordProposal(1, [dilithium2.CryptoPublicKeyBytes]byte{1}, true)
+	assert.Equal(t, 0, len(v.performance.outcomes))
+}
+
+func TestTakeDutyOutcomes(t *testing.T) {
+	reset := features.InitWithReset(&features.Flags{EnablePerformanceHistory: true})
+	defer reset()
+	slotsPerEpoch := params.BeaconConfig().SlotsPerEpoch
+	pubKey := [dilithium2.CryptoPublicKeyBytes]byte{1}
+
+	v := &validator{}
+	v.recordProposal(1, pubKey, true)
+	v.recordProposal(2, pubKey, false)
+	v.recordSyncCommitteeMessage(3, pubKey, true)
+	v.recordSyncCommitteeMessage(slotsPerEpoch, pubKey, false)
+	v.recordSyncCommitteeMessage(2*slotsPerEpoch, pubKey, true)
+
+	outcomes := v.takeDutyOutcomes(1)
+	require.NotNil(t, outcomes[pubKey])
+	assert.Equal(t, uint64(0), outcomes[pubKey].proposalsMade)
+	assert.Equal(t, uint64(1), outcomes[pubKey].syncCommitteeMissed)
+
+	// Outcomes of the epoch and earlier epochs are discarded.
+	assert.Equal(t, 0, len(v.takeDutyOutcomes(0)))
+	outcomes = v.takeDutyOutcomes(2)
+	require.NotNil(t, outcomes[pubKey])
+	assert.Equal(t, uint64(1), outcomes[pubKey].syncCommitteeMessages)
+	assert.Equal(t, 0, len(v.performance.outcomes))
+}
+
+func TestRecordAttestationInclusions(t *testing.T) {
+	reset := features.InitWithReset(&features.Flags{EnablePerformanceHistory: true})
+	defer reset()
+	slotsPerEpoch := params.BeaconConfig().SlotsPerEpoch
+	pubKeys := [][dilithium2.CryptoPublicKeyBytes]byte{{1}, {2}}
+	data := util.HydrateAttestationData(&zondpb.AttestationData{Slot: slotsPerEpoch + 1, CommitteeIndex: 3})
+	v := &validator{}
+	v.recordAttestation(data, pubKeys[0], 2)
+	v.recordAttestation(data, pubKeys[1], 5)
+
+	blockWithAttestation := func(slot primitives.Slot, indicesInCommittee ...uint64) {
+		bits := bitfield.NewBitlist(8)
+		for _, i := range indicesInCommittee {
+			bits.SetBitAt(i, true)
+		}
+		b := util.NewBeaconBlockCapella()
+		b.Block.Slot = slot
+		b.Block.Body.Attestations = []*zondpb.Attestation{util.HydrateAttestation(&zondpb.Attestation{
+			Data:            data,
+			AggregationBits: bits,
+		})}
+		blk, err := blocks.NewSignedBeaconBlock(b)
+		require.NoError(t, err)
+		v.recordAttestationInclusions(blk)
+	}
+	blockWithAttestation(slotsPerEpoch+2, 5)
+	blockWithAttestation(slotsPerEpoch+4, 2, 5)
+
+	outcomes := v.takeDutyOutcomes(1)
+	require.NotNil(t, outcomes[pubKeys[0]])
+	require.NotNil(t, outcomes[pubKeys[1]])
+	assert.Equal(t, primitives.Slot(3), outcomes[pubKeys[0]].inclusionDistance)
+	// The distance is the one to the first block including the attestation.
+	assert.Equal(t, primitives.Slot(1), outcomes[pubKeys[1]].inclusionDistance)
+}
+
+func TestSavePerformanceHistory(t *testing.T) {
+	reset := features.InitWithReset(&features.Flags{EnablePerformanceHistory: true})
+	defer reset()
+	ctx := context.Background()
+	pubKeys := [][dilithium2.CryptoPublicKeyBytes]byte{{1}, {2}}
+	v := &validator{db: dbTest.SetupDB(t, pubKeys)}
+	v.recordProposal(params.BeaconConfig().SlotsPerEpoch*3, pubKeys[1], true)
+	v.recordDutyOutcome(params.BeaconConfig().SlotsPerEpoch*3, pubKeys[0], func(o *dutyOutcomes) {
+		o.inclusionDistance = 2
+	})
+
+	resp := &zondpb.ValidatorPerformanceResponse{
+		PublicKeys:                    [][]byte{pubKeys[0][:], pubKeys[1][:]},
+		CorrectlyVotedSource:          []bool{true, true},
+		CorrectlyVotedTarget:          []bool{true, false},
+		CorrectlyVotedHead:            []bool{true, false},
+		BalancesBeforeEpochTransition: []uint64{32, 32},
+		BalancesAfterEpochTransition:  []uint64{33, 31},
+	}
+	require.NoError(t, v.savePerformanceHistory(ctx, resp, 3))
+
+	records, err := v.db.PerformanceHistoryForPubKey(ctx, pubKeys[0], 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(records))
+	assert.Equal(t, true, records[0].CorrectlyVotedHead)
+	assert.Equal(t, primitives.Slot(2), records[0].InclusionDistance)
+	assert.Equal(t, int64(1), records[0].BalanceChange())
+	assert.Equal(t, uint64(0), records[0].ProposalsMade)
+
+	records, err = v.db.PerformanceHistoryForPubKey(ctx, pubKeys[1], 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(records))
+	assert.Equal(t, false, records[0].CorrectlyVotedTarget)
+	assert.Equal(t, primitives.Slot(0), records[0].InclusionDistance)
+	assert.Equal(t, int64(-1), records[0].BalanceChange())
+	assert.Equal(t, uint64(1), records[0].ProposalsMade)
+}
@@ -49,6 +49,11 @@ func (v *validator) ProposeBlock(ctx context.Context, slot primitives.Slot, pubK
 	lock.Lock()
 	defer lock.Unlock()
 
+	proposed := false
+	defer func() {
+		v.recordProposal(slot, pubKey, proposed)
+	}()
+
 	fmtKey := fmt.Sprintf("%#x", pubKey[:])
 	span.AddAttributes(trace.StringAttribute("validator", fmtKey))
 	log := log.WithField("pubKey", fmt.Sprintf("%#x", bytesutil.Trunc(pubKey[:])))
@@ -138,6 +143,7 @@ func (v *validator) ProposeBlock(ctx context.Context, slot primitives.Slot, pubK
 		}
 		return
 	}
+	proposed = true
 
 	span.AddAttributes(
 		trace.StringAttribute("blockRoot", fmt.Sprintf("%#x", blkResp.BlockRoot)),
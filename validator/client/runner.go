@@ -9,6 +9,7 @@ import (
 	"github.com/pkg/errors"
 	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
 	"github.com/theQRL/qrysm/v4/cmd/validator/flags"
+	"github.com/theQRL/qrysm/v4/config/features"
 	"github.com/theQRL/qrysm/v4/config/params"
 	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
 	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
@@ -71,6 +72,11 @@ func run(ctx context.Context, v iface.Validator) {
 			" and will continue to use settings provided in the beacon node.")
 	}
 
+	var scheduler *dutyScheduler
+	if features.Get().EnableDutyLookahead {
+		scheduler = newDutyScheduler(features.Get().DutyLookaheadWorkers)
+	}
+
 	for {
 		_, cancel := context.WithCancel(ctx)
 		ctx, span := trace.StartSpan(ctx, "validator.processSlot")
@@ -124,6 +130,11 @@ func run(ctx context.Context, v iface.Validator) {
 				go v.UpdateDomainDataCaches(ctx, slot+1)
 			}
 
+			// Submit the voluntary exits which are due in this epoch.
+			if slots.IsEpochStart(slot) {
+				go v.ProcessScheduledExits(ctx, slot)
+			}
+
 			var wg sync.WaitGroup
 
 			allRoles, err := v.RolesAt(ctx, slot)
@@ -133,7 +144,12 @@ func run(ctx context.Context, v iface.Validator) {
 				span.End()
 				continue
 			}
-			performRoles(slotCtx, allRoles, v, slot, &wg, span)
+			if scheduler != nil {
+				scheduler.schedule(slotCtx, v, dutyJobs(deadline, slot, allRoles), &wg)
+				logSlotReport(slotCtx, v, slot, &wg, span)
+			} else {
+				performRoles(slotCtx, allRoles, v, slot, &wg, span)
+			}
 		}
 	}
 }
@@ -224,26 +240,36 @@ func performRoles(slotCtx context.Context, allRoles map[[dilithium2.CryptoPublic
 		for _, role := range roles {
 			go func(role iface.ValidatorRole, pubKey [dilithium2.CryptoPublicKeyBytes]byte) {
 				defer wg.Done()
-				switch role {
-				case iface.RoleAttester:
-					v.SubmitAttestation(slotCtx, slot, pubKey)
-				case iface.RoleProposer:
-					v.ProposeBlock(slotCtx, slot, pubKey)
-				case iface.RoleAggregator:
-					v.SubmitAggregateAndProof(slotCtx, slot, pubKey)
-				case iface.RoleSyncCommittee:
-					v.SubmitSyncCommitteeMessage(slotCtx, slot, pubKey)
-				case iface.RoleSyncCommitteeAggregator:
-					v.SubmitSignedContributionAndProof(slotCtx, slot, pubKey)
-				case iface.RoleUnknown:
-					log.WithField("pubKey", fmt.Sprintf("%#x", bytesutil.Trunc(pubKey[:]))).Trace("No active roles, doing nothing")
-				default:
-					log.Warnf("Unhandled role %v", role)
-				}
+				performRole(slotCtx, v, role, slot, pubKey)
 			}(role, pubKey)
 		}
 	}
+	logSlotReport(slotCtx, v, slot, wg, span)
+}
+
+// performRole performs a single duty of the validator key at the given slot.
+func performRole(ctx context.Context, v iface.Validator, role iface.ValidatorRole, slot primitives.Slot, pubKey [dilithium2.CryptoPublicKeyBytes]byte) {
+	switch role {
+	case iface.RoleAttester:
+		v.SubmitAttestation(ctx, slot, pubKey)
+	case iface.RoleProposer:
+		v.ProposeBlock(ctx, slot, pubKey)
+	case iface.RoleAggregator:
+		v.SubmitAggregateAndProof(ctx, slot, pubKey)
+	case iface.RoleSyncCommittee:
+		v.SubmitSyncCommitteeMessage(ctx, slot, pubKey)
+	case iface.RoleSyncCommitteeAggregator:
+		v.SubmitSignedContributionAndProof(ctx, slot, pubKey)
+	case iface.RoleUnknown:
+		log.WithField("pubKey", fmt.Sprintf("%#x", bytesutil.Trunc(pubKey[:]))).Trace("No active roles, doing nothing")
+	default:
+		log.Warnf("Unhandled role %v", role)
+	}
+}
 
+// logSlotReport waits for every duty of the slot to complete, then logs the
+// performance of the validator client and ends the slot span.
+func logSlotReport(slotCtx context.Context, v iface.Validator, slot primitives.Slot, wg *sync.WaitGroup, span *trace.Span) {
 	// Wait for all processes to complete, then report span complete.
 	go func() {
 		wg.Wait()
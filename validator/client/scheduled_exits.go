@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	emptypb "github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/time/slots"
+	"github.com/theQRL/qrysm/v4/validator/client/iface"
+	"github.com/theQRL/qrysm/v4/validator/db/kv"
+	"go.opencensus.io/trace"
+)
+
+// maxScheduledExitAttempts is the number of epochs a due exit is retried before it is marked as failed.
+const maxScheduledExitAttempts = 3
+
+// ScheduleExits plans the voluntary exits of the given keys, starting at the given epoch and exiting
+// at most perEpoch keys per epoch, so that a large batch of exits is spread out according to the
+// churn limit instead of filling the exit queue at once. The pending and signed exits already
+// scheduled count against the limit of their epoch, so only the capacity left in each epoch is
+// filled. A perEpoch of zero schedules every exit at the start epoch.
+func ScheduleExits(
+	pubKeys [][dilithium2.CryptoPublicKeyBytes]byte,
+	start primitives.Epoch,
+	perEpoch uint64,
+	existing []*kv.ScheduledExit,
+) []*kv.ScheduledExit {
+	scheduled := make(map[primitives.Epoch]uint64)
+	for _, exit := range existing {
+		if exit.Status == kv.ExitPending || exit.Status == kv.ExitSigned {
+			scheduled[exit.Epoch]++
+		}
+	}
+	exits := make([]*kv.ScheduledExit, len(pubKeys))
+	epoch := start
+	for i, pubKey := range pubKeys {
+		if perEpoch > 0 {
+			for scheduled[epoch] >= perEpoch {
+				epoch++
+			}
+			scheduled[epoch]++
+		}
+		exits[i] = &kv.ScheduledExit{
+			PublicKey: pubKey,
+			Epoch:     epoch,
+			Status:    kv.ExitPending,
+		}
+	}
+	return exits
+}
+
+// ExitChurnLimit returns the number of validators which can exit per epoch according to the beacon
+// node, or the minimum churn limit if the beacon node cannot be reached.
+func ExitChurnLimit(ctx context.Context, beaconClient iface.BeaconChainClient) uint64 {
+	queue, err := beaconClient.GetValidatorQueue(ctx, &emptypb.Empty{})
+	if err != nil || queue.ChurnLimit == 0 {
+		log.WithError(err).Debug("Could not get churn limit from beacon node, using the minimum churn limit")
+		return params.BeaconConfig().MinPerEpochChurnLimit
+	}
+	return queue.ChurnLimit
+}
+
+// SignScheduledExits signs the pending exits ahead of their epoch, for example to hand the signed
+// exits over to a third party. Signed exits are submitted as is when due.
+func SignScheduledExits(
+	ctx context.Context,
+	validatorClient iface.ValidatorClient,
+	signer iface.SigningFunc,
+	exits []*kv.ScheduledExit,
+) error {
+	for _, exit := range exits {
+		if exit.Status != kv.ExitPending {
+			continue
+		}
+		sve, err := CreateSignedVoluntaryExit(ctx, validatorClient, signer, exit.PublicKey[:], exit.Epoch)
+		if err != nil {
+			return errors.Wrapf(err, "could not sign exit of %#x", bytesutil.Trunc(exit.PublicKey[:]))
+		}
+		enc, err := sve.MarshalSSZ()
+		if err != nil {
+			return errors.Wrap(err, "could not encode signed voluntary exit")
+		}
+		exit.SignedExit = enc
+		exit.Status = kv.ExitSigned
+	}
+	return nil
+}
+
+// ProcessScheduledExits signs, if needed, and submits the scheduled exits which are due at the
+// epoch of the given slot, and records their status in the validator database. The scheduled exits
+// stay locked until their status is saved, so that they cannot be cancelled while being submitted.
+func (v *validator) ProcessScheduledExits(ctx context.Context, slot primitives.Slot) {
+	ctx, span := trace.StartSpan(ctx, "validator.ProcessScheduledExits")
+	defer span.End()
+
+	unlock := v.db.LockScheduledExits()
+	defer unlock()
+	exits, err := v.db.ScheduledExits(ctx)
+	if err != nil {
+		log.WithError(err).Error("Could not get scheduled exits")
+		return
+	}
+	epoch := slots.ToEpoch(slot)
+	updated := make([]*kv.ScheduledExit, 0)
+	for _, exit := range exits {
+		if exit.Done() || exit.Epoch > epoch {
+			continue
+		}
+		log := log.WithFields(logrus.Fields{
+			"pubKey": fmt.Sprintf("%#x", bytesutil.Trunc(exit.PublicKey[:])),
+			"epoch":  exit.Epoch,
+		})
+		if err := v.submitScheduledExit(ctx, exit); err != nil {
+			exit.Attempts++
+			exit.Error = err.Error()
+			if exit.Attempts >= maxScheduledExitAttempts {
+				exit.Status = kv.ExitFailed
+				log.WithError(err).Error("Could not submit scheduled exit, giving up")
+				ValidatorScheduledExitsVec.WithLabelValues(string(kv.ExitFailed)).Inc()
+			} else {
+				log.WithError(err).Warn("Could not submit scheduled exit, retrying next epoch")
+			}
+		} else {
+			exit.Status = kv.ExitSubmitted
+			exit.Error = ""
+			log.Info("Submitted scheduled voluntary exit")
+			ValidatorScheduledExitsVec.WithLabelValues(string(kv.ExitSubmitted)).Inc()
+		}
+		updated = append(updated, exit)
+	}
+	if len(updated) == 0 {
+		return
+	}
+	if err := v.db.SaveScheduledExits(ctx, updated); err != nil {
+		log.WithError(err).Error("Could not save status of scheduled exits")
+	}
+}
+
+func (v *validator) submitScheduledExit(ctx context.Context, exit *kv.ScheduledExit) error {
+	var sve *zondpb.SignedVoluntaryExit
+	if exit.SignedExit != nil {
+		sve = &zondpb.SignedVoluntaryExit{}
+		if err := sve.UnmarshalSSZ(exit.SignedExit); err != nil {
+			return errors.Wrap(err, "could not decode pre-signed voluntary exit")
+		}
+	} else {
+		if v.keyManager == nil {
+			return errors.New("no keymanager to sign the exit with")
+		}
+		var err error
+		sve, err = CreateSignedVoluntaryExit(ctx, v.validatorClient, v.keyManager.Sign, exit.PublicKey[:], exit.Epoch)
+		if err != nil {
+			return err
+		}
+		enc, err := sve.MarshalSSZ()
+		if err != nil {
+			return errors.Wrap(err, "could not encode signed voluntary exit")
+		}
+		exit.SignedExit = enc
+	}
+	if _, err := v.validatorClient.ProposeExit(ctx, sve); err != nil {
+		return errors.Wrap(err, "failed to propose voluntary exit")
+	}
+	return nil
+}
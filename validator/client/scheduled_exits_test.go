@@ -0,0 +1,193 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/validator/db/kv"
+)
+
+func TestScheduleExits(t *testing.T) {
+	pubKeys := [][dilithium2.CryptoPublicKeyBytes]byte{{1}, {2}, {3}, {4}, {5}}
+
+	exits := ScheduleExits(pubKeys, 10, 2, nil)
+	require.Equal(t, len(pubKeys), len(exits))
+	for i, want := range []primitives.Epoch{10, 10, 11, 11, 12} {
+		assert.Equal(t, pubKeys[i], exits[i].PublicKey)
+		assert.Equal(t, want, exits[i].Epoch)
+		assert.Equal(t, kv.ExitPending, exits[i].Status)
+	}
+
+	for _, exit := range ScheduleExits(pubKeys, 10, 0, nil) {
+		assert.Equal(t, primitives.Epoch(10), exit.Epoch)
+	}
+}
+
+func TestScheduleExits_FillsRemainingCapacity(t *testing.T) {
+	pubKeys := [][dilithium2.CryptoPublicKeyBytes]byte{{1}, {2}, {3}, {4}}
+	existing := []*kv.ScheduledExit{
+		{PublicKey: [dilithium2.CryptoPublicKeyBytes]byte{10}, Epoch: 10, Status: kv.ExitPending},
+		{PublicKey: [dilithium2.CryptoPublicKeyBytes]byte{11}, Epoch: 11, Status: kv.ExitSigned},
+		{PublicKey: [dilithium2.CryptoPublicKeyBytes]byte{12}, Epoch: 11, Status: kv.ExitPending},
+		// Submitted and failed exits no longer take up a slot of their epoch.
+		{PublicKey: [dilithium2.CryptoPublicKeyBytes]byte{13}, Epoch: 12, Status: kv.ExitSubmitted},
+		{PublicKey: [dilithium2.CryptoPublicKeyBytes]byte{14}, Epoch: 12, Status: kv.ExitFailed},
+	}
+
+	exits := ScheduleExits(pubKeys, 10, 2, existing)
+	require.Equal(t, len(pubKeys), len(exits))
+	for i, want := range []primitives.Epoch{10, 12, 12, 13} {
+		assert.Equal(t, pubKeys[i], exits[i].PublicKey)
+		assert.Equal(t, want, exits[i].Epoch)
+	}
+}
+
+func TestProcessScheduledExits_SubmitsDueExits(t *testing.T) {
+	v, m, validatorKey, finish := setup(t)
+	defer finish()
+	ctx := context.Background()
+	var pubKey [dilithium2.CryptoPublicKeyBytes]byte
+	copy(pubKey[:], validatorKey.PublicKey().Marshal())
+	later := [dilithium2.CryptoPublicKeyBytes]byte{1}
+	require.NoError(t, v.db.SaveScheduledExits(ctx, []*kv.ScheduledExit{
+		{PublicKey: pubKey, Epoch: 1, Status: kv.ExitPending},
+		{PublicKey: later, Epoch: 5, Status: kv.ExitPending},
+	}))
+
+	m.validatorClient.EXPECT().
+		ValidatorIndex(gomock.Any(), gomock.Any()).
+		Return(&zondpb.ValidatorIndexResponse{Index: 1}, nil)
+	m.validatorClient.EXPECT().
+		DomainData(gomock.Any(), gomock.Any()).
+		Return(&zondpb.DomainResponse{SignatureDomain: make([]byte, 32)}, nil)
+	m.validatorClient.EXPECT().
+		ProposeExit(gomock.Any(), gomock.AssignableToTypeOf(&zondpb.SignedVoluntaryExit{})).
+		Return(&zondpb.ProposeExitResponse{}, nil)
+
+	v.ProcessScheduledExits(ctx, params.BeaconConfig().SlotsPerEpoch*2)
+
+	exits, err := v.db.ScheduledExits(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(exits))
+	for _, exit := range exits {
+		if exit.PublicKey == pubKey {
+			assert.Equal(t, kv.ExitSubmitted, exit.Status)
+			sve := &zondpb.SignedVoluntaryExit{}
+			require.NoError(t, sve.UnmarshalSSZ(exit.SignedExit))
+			assert.Equal(t, primitives.Epoch(1), sve.Exit.Epoch)
+			assert.Equal(t, primitives.ValidatorIndex(1), sve.Exit.ValidatorIndex)
+		} else {
+			assert.Equal(t, kv.ExitPending, exit.Status)
+		}
+	}
+}
+
+func TestProcessScheduledExits_LocksUntilStatusSaved(t *testing.T) {
+	v, m, validatorKey, finish := setup(t)
+	defer finish()
+	ctx := context.Background()
+	var pubKey [dilithium2.CryptoPublicKeyBytes]byte
+	copy(pubKey[:], validatorKey.PublicKey().Marshal())
+	require.NoError(t, v.db.SaveScheduledExits(ctx, []*kv.ScheduledExit{
+		{PublicKey: pubKey, Epoch: 0, Status: kv.ExitPending},
+	}))
+
+	m.validatorClient.EXPECT().
+		ValidatorIndex(gomock.Any(), gomock.Any()).
+		Return(&zondpb.ValidatorIndexResponse{Index: 1}, nil)
+	m.validatorClient.EXPECT().
+		DomainData(gomock.Any(), gomock.Any()).
+		Return(&zondpb.DomainResponse{SignatureDomain: make([]byte, 32)}, nil)
+	// A cancellation racing with the submission only sees the exit once its status is saved.
+	seen := make(chan kv.ExitStatus, 1)
+	m.validatorClient.EXPECT().
+		ProposeExit(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, *zondpb.SignedVoluntaryExit) (*zondpb.ProposeExitResponse, error) {
+			go func() {
+				unlock := v.db.LockScheduledExits()
+				defer unlock()
+				exits, err := v.db.ScheduledExits(ctx)
+				if err != nil || len(exits) != 1 {
+					seen <- ""
+					return
+				}
+				seen <- exits[0].Status
+			}()
+			return &zondpb.ProposeExitResponse{}, nil
+		})
+
+	v.ProcessScheduledExits(ctx, 0)
+	assert.Equal(t, kv.ExitSubmitted, <-seen)
+}
+
+func TestProcessScheduledExits_GivesUpAfterAttempts(t *testing.T) {
+	v, m, validatorKey, finish := setup(t)
+	defer finish()
+	ctx := context.Background()
+	var pubKey [dilithium2.CryptoPublicKeyBytes]byte
+	copy(pubKey[:], validatorKey.PublicKey().Marshal())
+	require.NoError(t, v.db.SaveScheduledExits(ctx, []*kv.ScheduledExit{
+		{PublicKey: pubKey, Epoch: 0, Status: kv.ExitPending},
+	}))
+
+	// The exit is signed once, and the signed exit is reused on later attempts.
+	m.validatorClient.EXPECT().
+		ValidatorIndex(gomock.Any(), gomock.Any()).
+		Return(&zondpb.ValidatorIndexResponse{Index: 1}, nil)
+	m.validatorClient.EXPECT().
+		DomainData(gomock.Any(), gomock.Any()).
+		Return(&zondpb.DomainResponse{SignatureDomain: make([]byte, 32)}, nil)
+	m.validatorClient.EXPECT().
+		ProposeExit(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("beacon node unavailable")).
+		Times(maxScheduledExitAttempts)
+
+	for i := uint64(0); i < maxScheduledExitAttempts; i++ {
+		v.ProcessScheduledExits(ctx, params.BeaconConfig().SlotsPerEpoch*primitives.Slot(i))
+		exits, err := v.db.ScheduledExits(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(exits))
+		assert.Equal(t, i+1, exits[0].Attempts)
+		assert.StringContains(t, "beacon node unavailable", exits[0].Error)
+	}
+	exits, err := v.db.ScheduledExits(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, kv.ExitFailed, exits[0].Status)
+
+	// Failed exits are not retried.
+	v.ProcessScheduledExits(ctx, params.BeaconConfig().SlotsPerEpoch*10)
+}
+
+func TestSignScheduledExits(t *testing.T) {
+	v, m, validatorKey, finish := setup(t)
+	defer finish()
+	var pubKey [dilithium2.CryptoPublicKeyBytes]byte
+	copy(pubKey[:], validatorKey.PublicKey().Marshal())
+
+	m.validatorClient.EXPECT().
+		ValidatorIndex(gomock.Any(), gomock.Any()).
+		Return(&zondpb.ValidatorIndexResponse{Index: 7}, nil)
+	m.validatorClient.EXPECT().
+		DomainData(gomock.Any(), gomock.Any()).
+		Return(&zondpb.DomainResponse{SignatureDomain: make([]byte, 32)}, nil)
+
+	exits := []*kv.ScheduledExit{
+		{PublicKey: pubKey, Epoch: 3, Status: kv.ExitPending},
+		{PublicKey: pubKey, Epoch: 3, Status: kv.ExitSubmitted},
+	}
+	require.NoError(t, SignScheduledExits(context.Background(), m.validatorClient, v.keyManager.Sign, exits))
+	assert.Equal(t, kv.ExitSigned, exits[0].Status)
+	sve := &zondpb.SignedVoluntaryExit{}
+	require.NoError(t, sve.UnmarshalSSZ(exits[0].SignedExit))
+	assert.Equal(t, primitives.ValidatorIndex(7), sve.Exit.ValidatorIndex)
+	assert.Equal(t, kv.ExitSubmitted, exits[1].Status)
+	assert.Equal(t, 0, len(exits[1].SignedExit))
+}
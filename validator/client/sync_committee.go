@@ -27,6 +27,11 @@ func (v *validator) SubmitSyncCommitteeMessage(ctx context.Context, slot primiti
 	defer span.End()
 	span.AddAttributes(trace.StringAttribute("validator", fmt.Sprintf("%#x", pubKey)))
 
+	submitted := false
+	defer func() {
+		v.recordSyncCommitteeMessage(slot, pubKey, submitted)
+	}()
+
 	v.waitOneThirdOrValidBlock(ctx, slot)
 
 	res, err := v.validatorClient.GetSyncMessageBlockRoot(ctx, &emptypb.Empty{})
@@ -78,6 +83,7 @@ func (v *validator) SubmitSyncCommitteeMessage(ctx context.Context, slot primiti
 		log.WithError(err).Error("Could not submit sync committee message")
 		return
 	}
+	submitted = true
 
 	msgSlot := msg.Slot
 	slotTime := time.Unix(int64(v.genesisTime+uint64(msgSlot)*params.BeaconConfig().SecondsPerSlot), 0)
@@ -161,6 +161,10 @@ func (fv *FakeValidator) RolesAt(_ context.Context, slot primitives.Slot) (map[[
 	return vr, nil
 }
 
+// WaitToPerformRole for mocking.
+func (_ *FakeValidator) WaitToPerformRole(_ context.Context, _ primitives.Slot, _ iface.ValidatorRole) {
+}
+
 // SubmitAttestation for mocking.
 func (fv *FakeValidator) SubmitAttestation(_ context.Context, slot primitives.Slot, _ [dilithium2.CryptoPublicKeyBytes]byte) {
 	fv.AttestToBlockHeadCalled = true
@@ -187,6 +191,9 @@ func (_ *FakeValidator) LogAttestationsSubmitted() {}
 // UpdateDomainDataCaches for mocking.
 func (_ *FakeValidator) UpdateDomainDataCaches(context.Context, primitives.Slot) {}
 
+// ProcessScheduledExits for mocking.
+func (_ *FakeValidator) ProcessScheduledExits(context.Context, primitives.Slot) {}
+
 // BalancesByPubkeys for mocking.
 func (fv *FakeValidator) BalancesByPubkeys(_ context.Context) map[[dilithium2.CryptoPublicKeyBytes]byte]uint64 {
 	return fv.Balances
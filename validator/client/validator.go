@@ -74,6 +74,7 @@ type validator struct {
 	aggregatedSlotCommitteeIDCacheLock sync.Mutex
 	highestValidSlotLock               sync.Mutex
 	prevBalanceLock                    sync.RWMutex
+	prefetchedDutiesLock               sync.Mutex
 	slashableKeysLock                  sync.RWMutex
 	eipImportBlacklistedPublicKeys     map[[dilithium2.CryptoPublicKeyBytes]byte]bool
 	walletInitializedFeed              *event.Feed
@@ -85,6 +86,8 @@ type validator struct {
 	signedValidatorRegistrations       map[[dilithium2.CryptoPublicKeyBytes]byte]*zondpb.SignedValidatorRegistrationV1
 	graffitiOrderedIndex               uint64
 	aggregatedSlotCommitteeIDCache     *lru.Cache
+	selectionProofCache                *lru.Cache
+	prefetchedDuties                   *prefetchedDuties
 	domainDataCache                    *ristretto.Cache
 	highestValidSlot                   primitives.Slot
 	genesisTime                        uint64
@@ -102,6 +105,7 @@ type validator struct {
 	graffiti                           []byte
 	voteStats                          voteStats
 	syncCommitteeStats                 syncCommitteeStats
+	performance                        performanceTracker
 	Web3SignerConfig                   *remoteweb3signer.SetupConfig
 	proposerSettings                   *validatorserviceconfig.ProposerSettings
 	walletInitializedChannel           chan *wallet.Wallet
@@ -365,6 +369,7 @@ func (v *validator) ReceiveBlocks(ctx context.Context, connectionErrorChannel ch
 		}
 		v.highestValidSlotLock.Unlock()
 		v.blockFeed.Send(blk)
+		v.recordAttestationInclusions(blk)
 	}
 }
 
@@ -558,6 +563,9 @@ func retrieveLatestRecord(recs []*kv.AttestationRecord) *kv.AttestationRecord {
 // list of upcoming assignments needs to be updated. For example, at the
 // beginning of a new epoch.
 func (v *validator) UpdateDuties(ctx context.Context, slot primitives.Slot) error {
+	if features.Get().EnableDutyLookahead && slots.IsEpochEnd(slot) && v.duties != nil {
+		go v.prefetchDuties(detachContext(ctx), slots.ToEpoch(slot)+1)
+	}
 	if slot%params.BeaconConfig().SlotsPerEpoch != 0 && v.duties != nil {
 		// Do nothing if not epoch start AND assignments already exist.
 		return nil
@@ -572,37 +580,20 @@ func (v *validator) UpdateDuties(ctx context.Context, slot primitives.Slot) erro
 	ctx, span := trace.StartSpan(ctx, "validator.UpdateAssignments")
 	defer span.End()
 
-	validatingKeys, err := v.keyManager.FetchValidatingPublicKeys(ctx)
+	req, err := v.dutiesRequest(ctx, slots.ToEpoch(slot))
 	if err != nil {
 		return err
 	}
 
-	// Filter out the slashable public keys from the duties request.
-	filteredKeys := make([][dilithium2.CryptoPublicKeyBytes]byte, 0, len(validatingKeys))
-	v.slashableKeysLock.RLock()
-	for _, pubKey := range validatingKeys {
-		if ok := v.eipImportBlacklistedPublicKeys[pubKey]; !ok {
-			filteredKeys = append(filteredKeys, pubKey)
-		} else {
-			log.WithField(
-				"publicKey", fmt.Sprintf("%#x", bytesutil.Trunc(pubKey[:])),
-			).Warn("Not including slashable public key from slashing protection import " +
-				"in request to update validator duties")
-		}
-	}
-	v.slashableKeysLock.RUnlock()
-
-	req := &zondpb.DutiesRequest{
-		Epoch:      primitives.Epoch(slot / params.BeaconConfig().SlotsPerEpoch),
-		PublicKeys: bytesutil.FromBytes2592Array(filteredKeys),
-	}
-
 	// If duties is nil it means we have had no prior duties and just started up.
-	resp, err := v.validatorClient.GetDuties(ctx, req)
-	if err != nil {
-		v.duties = nil // Clear assignments so we know to retry the request.
-		log.Error(err)
-		return err
+	resp, ok := v.takePrefetchedDuties(ctx, req)
+	if !ok {
+		resp, err = v.validatorClient.GetDuties(ctx, req)
+		if err != nil {
+			v.duties = nil // Clear assignments so we know to retry the request.
+			log.Error(err)
+			return err
+		}
 	}
 
 	allExitedCounter := 0
@@ -619,13 +610,11 @@ func (v *validator) UpdateDuties(ctx context.Context, slot primitives.Slot) erro
 	v.logDuties(slot, v.duties.CurrentEpochDuties)
 
 	// Non-blocking call for beacon node to start subscriptions for aggregators.
-	// Make sure to copy metadata into a new context
-	md, exists := metadata.FromOutgoingContext(ctx)
-	ctx = context.Background()
-	if exists {
-		ctx = metadata.NewOutgoingContext(ctx, md)
-	}
+	ctx = detachContext(ctx)
 	go func() {
+		if features.Get().EnableDutyLookahead {
+			v.precomputeDuties(ctx, resp)
+		}
 		if err := v.subscribeToSubnets(ctx, resp); err != nil {
 			log.WithError(err).Error("Failed to subscribe to subnets")
 		}
@@ -634,6 +623,45 @@ func (v *validator) UpdateDuties(ctx context.Context, slot primitives.Slot) erro
 	return nil
 }
 
+// dutiesRequest builds a request for the duties at the given epoch of every validating
+// key, except for the slashable public keys imported through slashing protection.
+func (v *validator) dutiesRequest(ctx context.Context, epoch primitives.Epoch) (*zondpb.DutiesRequest, error) {
+	validatingKeys, err := v.keyManager.FetchValidatingPublicKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Filter out the slashable public keys from the duties request.
+	filteredKeys := make([][dilithium2.CryptoPublicKeyBytes]byte, 0, len(validatingKeys))
+	v.slashableKeysLock.RLock()
+	for _, pubKey := range validatingKeys {
+		if ok := v.eipImportBlacklistedPublicKeys[pubKey]; !ok {
+			filteredKeys = append(filteredKeys, pubKey)
+		} else {
+			log.WithField(
+				"publicKey", fmt.Sprintf("%#x", bytesutil.Trunc(pubKey[:])),
+			).Warn("Not including slashable public key from slashing protection import " +
+				"in request to update validator duties")
+		}
+	}
+	v.slashableKeysLock.RUnlock()
+
+	return &zondpb.DutiesRequest{
+		Epoch:      epoch,
+		PublicKeys: bytesutil.FromBytes2592Array(filteredKeys),
+	}, nil
+}
+
+// detachContext returns a context which is not canceled together with the given
+// context, but which carries over its outgoing gRPC metadata.
+func detachContext(ctx context.Context) context.Context {
+	md, exists := metadata.FromOutgoingContext(ctx)
+	if exists {
+		return metadata.NewOutgoingContext(context.Background(), md)
+	}
+	return context.Background()
+}
+
 // subscribeToSubnets iterates through each validator duty, signs each slot, and asks beacon node
 // to eagerly subscribe to subnets so that the aggregator has attestations to aggregate.
 func (v *validator) subscribeToSubnets(ctx context.Context, res *zondpb.DutiesResponse) error {
@@ -708,6 +736,18 @@ func (v *validator) subscribeToSubnets(ctx context.Context, res *zondpb.DutiesRe
 	return err
 }
 
+// WaitToPerformRole waits until the given role can be performed at the slot: attestations and
+// sync committee messages are made once the block of the slot was processed or a third into the
+// slot, and aggregates two thirds into the slot. Blocks are proposed at the start of the slot.
+func (v *validator) WaitToPerformRole(ctx context.Context, slot primitives.Slot, role iface.ValidatorRole) {
+	switch role {
+	case iface.RoleAttester, iface.RoleSyncCommittee:
+		v.waitOneThirdOrValidBlock(ctx, slot)
+	case iface.RoleAggregator, iface.RoleSyncCommitteeAggregator:
+		v.waitToSlotTwoThirds(ctx, slot)
+	}
+}
+
 // RolesAt slot returns the validator roles at the given slot. Returns nil if the
 // validator is known to not have a roles at the slot. Returns UNKNOWN if the
 // validator assignments are unknown. Otherwise returns a valid ValidatorRole map.
@@ -70,4 +70,16 @@ type ValidatorDB interface {
 	UpdateProposerSettingsDefault(context.Context, *validatorServiceConfig.ProposerOption) error
 	UpdateProposerSettingsForPubkey(context.Context, [dilithium2.CryptoPublicKeyBytes]byte, *validatorServiceConfig.ProposerOption) error
 	SaveProposerSettings(ctx context.Context, settings *validatorServiceConfig.ProposerSettings) error
+
+	// Performance history related methods
+	SavePerformanceRecords(ctx context.Context, pubKeys [][dilithium2.CryptoPublicKeyBytes]byte, records []*kv.PerformanceRecord) error
+	PerformanceHistoryForPubKey(ctx context.Context, pubKey [dilithium2.CryptoPublicKeyBytes]byte, start, end primitives.Epoch) ([]*kv.PerformanceRecord, error)
+	PerformancePublicKeys(ctx context.Context) ([][dilithium2.CryptoPublicKeyBytes]byte, error)
+	PrunePerformanceHistory(ctx context.Context, before primitives.Epoch) error
+
+	// Scheduled voluntary exits related methods
+	LockScheduledExits() (unlock func())
+	ScheduledExits(ctx context.Context) ([]*kv.ScheduledExit, error)
+	SaveScheduledExits(ctx context.Context, exits []*kv.ScheduledExit) error
+	DeleteScheduledExit(ctx context.Context, pubKey [dilithium2.CryptoPublicKeyBytes]byte) error
 }
@@ -5,6 +5,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -67,6 +68,7 @@ type Store struct {
 	batchedAttestationsChan            chan *AttestationRecordSaveRequest
 	batchAttestationsFlushedFeed       *event.Feed
 	batchedAttestationsFlushInProgress abool.AtomicBool
+	scheduledExitsLock                 sync.Mutex
 }
 
 // Close closes the underlying boltdb database.
@@ -153,6 +155,8 @@ func NewKVStore(ctx context.Context, dirPath string, config *Config) (*Store, er
 			migrationsBucket,
 			graffitiBucket,
 			proposerSettingsBucket,
+			performanceBucket,
+			scheduledExitsBucket,
 		)
 	}); err != nil {
 		return nil, err
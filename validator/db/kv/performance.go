@@ -0,0 +1,139 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	bolt "go.etcd.io/bbolt"
+	"go.opencensus.io/trace"
+)
+
+// PerformanceRecord summarizes how a validator performed its duties during an epoch.
+type PerformanceRecord struct {
+	Epoch primitives.Epoch `json:"epoch"`
+	// InclusionDistance is the number of slots between the attestation of the epoch and the first block
+	// including it. Zero means no block including the attestation was seen.
+	InclusionDistance     primitives.Slot `json:"inclusion_distance"`
+	CorrectlyVotedSource  bool            `json:"correctly_voted_source"`
+	CorrectlyVotedTarget  bool            `json:"correctly_voted_target"`
+	CorrectlyVotedHead    bool            `json:"correctly_voted_head"`
+	ProposalsMade         uint64          `json:"proposals_made"`
+	ProposalsMissed       uint64          `json:"proposals_missed"`
+	SyncCommitteeMessages uint64          `json:"sync_committee_messages"`
+	SyncCommitteeMissed   uint64          `json:"sync_committee_missed"`
+	BalanceBefore         uint64          `json:"balance_before"`
+	BalanceAfter          uint64          `json:"balance_after"`
+}
+
+// BalanceChange returns the change in balance of the validator over the epoch, in gwei.
+func (r *PerformanceRecord) BalanceChange() int64 {
+	return int64(r.BalanceAfter) - int64(r.BalanceBefore)
+}
+
+// SavePerformanceRecords saves the performance records of several public keys for an epoch.
+// An existing record of a public key at the same epoch is overwritten.
+func (s *Store) SavePerformanceRecords(
+	ctx context.Context, pubKeys [][dilithium2.CryptoPublicKeyBytes]byte, records []*PerformanceRecord,
+) error {
+	_, span := trace.StartSpan(ctx, "Validator.SavePerformanceRecords")
+	defer span.End()
+	if len(pubKeys) != len(records) {
+		return errors.Errorf("number of public keys %d does not match number of records %d", len(pubKeys), len(records))
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(performanceBucket)
+		for i, record := range records {
+			if record == nil {
+				continue
+			}
+			pkBucket, err := bucket.CreateBucketIfNotExists(pubKeys[i][:])
+			if err != nil {
+				return err
+			}
+			enc, err := json.Marshal(record)
+			if err != nil {
+				return errors.Wrap(err, "could not encode performance record")
+			}
+			if err := pkBucket.Put(bytesutil.EpochToBytesBigEndian(record.Epoch), enc); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// PerformanceHistoryForPubKey returns the performance records of a public key between
+// the start and end epochs, inclusive, sorted by epoch.
+func (s *Store) PerformanceHistoryForPubKey(
+	ctx context.Context, pubKey [dilithium2.CryptoPublicKeyBytes]byte, start, end primitives.Epoch,
+) ([]*PerformanceRecord, error) {
+	_, span := trace.StartSpan(ctx, "Validator.PerformanceHistoryForPubKey")
+	defer span.End()
+	records := make([]*PerformanceRecord, 0)
+	err := s.view(func(tx *bolt.Tx) error {
+		pkBucket := tx.Bucket(performanceBucket).Bucket(pubKey[:])
+		if pkBucket == nil {
+			return nil
+		}
+		endKey := bytesutil.EpochToBytesBigEndian(end)
+		c := pkBucket.Cursor()
+		for k, v := c.Seek(bytesutil.EpochToBytesBigEndian(start)); k != nil && string(k) <= string(endKey); k, v = c.Next() {
+			record := &PerformanceRecord{}
+			if err := json.Unmarshal(v, record); err != nil {
+				return errors.Wrapf(err, "could not decode performance record at epoch %d", bytesutil.BytesToEpochBigEndian(k))
+			}
+			records = append(records, record)
+		}
+		return nil
+	})
+	return records, err
+}
+
+// PerformancePublicKeys returns the public keys for which performance records are stored.
+func (s *Store) PerformancePublicKeys(ctx context.Context) ([][dilithium2.CryptoPublicKeyBytes]byte, error) {
+	_, span := trace.StartSpan(ctx, "Validator.PerformancePublicKeys")
+	defer span.End()
+	publicKeys := make([][dilithium2.CryptoPublicKeyBytes]byte, 0)
+	err := s.view(func(tx *bolt.Tx) error {
+		return tx.Bucket(performanceBucket).ForEach(func(k []byte, v []byte) error {
+			// Nested buckets have a nil value.
+			if v == nil {
+				publicKeys = append(publicKeys, bytesutil.ToBytes2592(k))
+			}
+			return nil
+		})
+	})
+	return publicKeys, err
+}
+
+// PrunePerformanceHistory deletes the performance records of every public key older than the given epoch.
+func (s *Store) PrunePerformanceHistory(ctx context.Context, before primitives.Epoch) error {
+	_, span := trace.StartSpan(ctx, "Validator.PrunePerformanceHistory")
+	defer span.End()
+	return s.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(performanceBucket)
+		beforeKey := bytesutil.EpochToBytesBigEndian(before)
+		return bucket.ForEach(func(pubKey []byte, v []byte) error {
+			pkBucket := bucket.Bucket(pubKey)
+			if v != nil || pkBucket == nil {
+				return nil
+			}
+			// Keys are collected first, as deleting while iterating with a cursor skips entries.
+			var toDelete [][]byte
+			c := pkBucket.Cursor()
+			for k, _ := c.First(); k != nil && string(k) < string(beforeKey); k, _ = c.Next() {
+				toDelete = append(toDelete, k)
+			}
+			for _, k := range toDelete {
+				if err := pkBucket.Delete(k); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
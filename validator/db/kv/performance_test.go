@@ -0,0 +1,67 @@
+package kv
+
+import (
+	"context"
+	"testing"
+
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+)
+
+func TestStore_PerformanceHistory_SaveAndRetrieve(t *testing.T) {
+	ctx := context.Background()
+	pubKeys := [][dilithium2.CryptoPublicKeyBytes]byte{{1}, {2}}
+	db := setupDB(t, pubKeys)
+
+	records, err := db.PerformanceHistoryForPubKey(ctx, pubKeys[0], 0, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(records))
+
+	for epoch := primitives.Epoch(1); epoch <= 5; epoch++ {
+		err := db.SavePerformanceRecords(ctx, pubKeys, []*PerformanceRecord{
+			{Epoch: epoch, CorrectlyVotedHead: true, BalanceBefore: 100, BalanceAfter: 101},
+			{Epoch: epoch, ProposalsMissed: 1, BalanceBefore: 100, BalanceAfter: 99},
+		})
+		require.NoError(t, err)
+	}
+
+	records, err = db.PerformanceHistoryForPubKey(ctx, pubKeys[0], 2, 4)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(records))
+	for i, record := range records {
+		assert.Equal(t, primitives.Epoch(i+2), record.Epoch)
+		assert.Equal(t, true, record.CorrectlyVotedHead)
+		assert.Equal(t, int64(1), record.BalanceChange())
+	}
+
+	records, err = db.PerformanceHistoryForPubKey(ctx, pubKeys[1], 0, 100)
+	require.NoError(t, err)
+	require.Equal(t, 5, len(records))
+	assert.Equal(t, uint64(1), records[0].ProposalsMissed)
+	assert.Equal(t, int64(-1), records[0].BalanceChange())
+
+	keys, err := db.PerformancePublicKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(keys))
+
+	err = db.SavePerformanceRecords(ctx, pubKeys[:1], nil)
+	require.ErrorContains(t, "does not match", err)
+}
+
+func TestStore_PrunePerformanceHistory(t *testing.T) {
+	ctx := context.Background()
+	pubKeys := [][dilithium2.CryptoPublicKeyBytes]byte{{1}}
+	db := setupDB(t, pubKeys)
+
+	for epoch := primitives.Epoch(0); epoch < 10; epoch++ {
+		require.NoError(t, db.SavePerformanceRecords(ctx, pubKeys, []*PerformanceRecord{{Epoch: epoch}}))
+	}
+	require.NoError(t, db.PrunePerformanceHistory(ctx, 6))
+
+	records, err := db.PerformanceHistoryForPubKey(ctx, pubKeys[0], 0, 100)
+	require.NoError(t, err)
+	require.Equal(t, 4, len(records))
+	assert.Equal(t, primitives.Epoch(6), records[0].Epoch)
+}
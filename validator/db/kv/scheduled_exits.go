@@ -0,0 +1,98 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	bolt "go.etcd.io/bbolt"
+	"go.opencensus.io/trace"
+)
+
+// ExitStatus is the status of a scheduled voluntary exit.
+type ExitStatus string
+
+const (
+	// ExitPending is the status of an exit which is not signed yet.
+	ExitPending ExitStatus = "pending"
+	// ExitSigned is the status of an exit which was signed ahead of time, and is not submitted yet.
+	ExitSigned ExitStatus = "signed"
+	// ExitSubmitted is the status of an exit which was submitted to the beacon node.
+	ExitSubmitted ExitStatus = "submitted"
+	// ExitFailed is the status of an exit which could not be signed or submitted when due.
+	ExitFailed ExitStatus = "failed"
+)
+
+// ScheduledExit is a voluntary exit of a validator key which is due at an epoch.
+type ScheduledExit struct {
+	PublicKey [dilithium2.CryptoPublicKeyBytes]byte `json:"-"`
+	Epoch     primitives.Epoch                      `json:"epoch"`
+	Status    ExitStatus                            `json:"status"`
+	// SignedExit is the SSZ encoded signed voluntary exit, once signed.
+	SignedExit []byte `json:"signed_exit,omitempty"`
+	// Attempts is the number of times the exit was due but could not be signed or submitted.
+	Attempts uint64 `json:"attempts,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Done returns true if the exit needs no further processing.
+func (e *ScheduledExit) Done() bool {
+	return e.Status == ExitSubmitted || e.Status == ExitFailed
+}
+
+// LockScheduledExits locks the scheduled voluntary exits until the returned function is called. It must be
+// held across reading, processing and saving exits, so that an exit cancelled in the meantime, for
+// example through the API while the validator client submits due exits, is not saved back or submitted.
+func (s *Store) LockScheduledExits() (unlock func()) {
+	s.scheduledExitsLock.Lock()
+	return s.scheduledExitsLock.Unlock
+}
+
+// ScheduledExits returns every scheduled voluntary exit, sorted by public key.
+func (s *Store) ScheduledExits(ctx context.Context) ([]*ScheduledExit, error) {
+	_, span := trace.StartSpan(ctx, "Validator.ScheduledExits")
+	defer span.End()
+	exits := make([]*ScheduledExit, 0)
+	err := s.view(func(tx *bolt.Tx) error {
+		return tx.Bucket(scheduledExitsBucket).ForEach(func(k, v []byte) error {
+			exit := &ScheduledExit{}
+			if err := json.Unmarshal(v, exit); err != nil {
+				return errors.Wrapf(err, "could not decode scheduled exit of %#x", k)
+			}
+			copy(exit.PublicKey[:], k)
+			exits = append(exits, exit)
+			return nil
+		})
+	})
+	return exits, err
+}
+
+// SaveScheduledExits saves scheduled voluntary exits, replacing any exit already scheduled for the same public key.
+func (s *Store) SaveScheduledExits(ctx context.Context, exits []*ScheduledExit) error {
+	_, span := trace.StartSpan(ctx, "Validator.SaveScheduledExits")
+	defer span.End()
+	return s.update(func(tx *bolt.Tx) error {
+		bkt := tx.Bucket(scheduledExitsBucket)
+		for _, exit := range exits {
+			enc, err := json.Marshal(exit)
+			if err != nil {
+				return errors.Wrap(err, "could not encode scheduled exit")
+			}
+			if err := bkt.Put(exit.PublicKey[:], enc); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteScheduledExit removes the scheduled voluntary exit of a public key, if any.
+func (s *Store) DeleteScheduledExit(ctx context.Context, pubKey [dilithium2.CryptoPublicKeyBytes]byte) error {
+	_, span := trace.StartSpan(ctx, "Validator.DeleteScheduledExit")
+	defer span.End()
+	return s.update(func(tx *bolt.Tx) error {
+		return tx.Bucket(scheduledExitsBucket).Delete(pubKey[:])
+	})
+}
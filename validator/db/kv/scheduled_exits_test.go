@@ -0,0 +1,42 @@
+package kv
+
+import (
+	"context"
+	"testing"
+
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+)
+
+func TestStore_ScheduledExits(t *testing.T) {
+	ctx := context.Background()
+	db := setupDB(t, nil)
+
+	exits, err := db.ScheduledExits(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(exits))
+
+	pubKeys := [][dilithium2.CryptoPublicKeyBytes]byte{{1}, {2}}
+	require.NoError(t, db.SaveScheduledExits(ctx, []*ScheduledExit{
+		{PublicKey: pubKeys[0], Epoch: 10, Status: ExitPending},
+		{PublicKey: pubKeys[1], Epoch: 11, Status: ExitSigned, SignedExit: []byte{1, 2, 3}},
+	}))
+	exits, err = db.ScheduledExits(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(exits))
+	assert.Equal(t, pubKeys[0], exits[0].PublicKey)
+	assert.Equal(t, ExitPending, exits[0].Status)
+	assert.DeepEqual(t, []byte{1, 2, 3}, exits[1].SignedExit)
+
+	// Saving an exit for the same key replaces it.
+	require.NoError(t, db.SaveScheduledExits(ctx, []*ScheduledExit{
+		{PublicKey: pubKeys[0], Epoch: 10, Status: ExitSubmitted},
+	}))
+	require.NoError(t, db.DeleteScheduledExit(ctx, pubKeys[1]))
+	exits, err = db.ScheduledExits(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(exits))
+	assert.Equal(t, ExitSubmitted, exits[0].Status)
+	assert.Equal(t, true, exits[0].Done())
+}
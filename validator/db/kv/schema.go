@@ -41,4 +41,10 @@ var (
 	// ProposerSettings stores the encoded proposer settings file
 	proposerSettingsBucket = []byte("proposer-settings-bucket")
 	proposerSettingsKey    = []byte("proposer-settings")
+
+	// Per-epoch performance records of each validator, stored in a nested bucket per public key.
+	performanceBucket = []byte("validator-performance-bucket")
+
+	// Voluntary exits scheduled for future epochs, keyed by public key.
+	scheduledExitsBucket = []byte("scheduled-exits-bucket")
 )
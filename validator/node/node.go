@@ -76,6 +76,7 @@ type ValidatorClient struct {
 	lock              sync.RWMutex
 	wallet            *wallet.Wallet
 	walletInitialized *event.Feed
+	router            *mux.Router
 	stop              chan struct{} // Channel to wait for termination notifications.
 }
 
@@ -110,6 +111,7 @@ func NewValidatorClient(cliCtx *cli.Context) (*ValidatorClient, error) {
 		cancel:            cancel,
 		services:          registry,
 		walletInitialized: new(event.Feed),
+		router:            mux.NewRouter(),
 		stop:              make(chan struct{}),
 	}
 
@@ -751,6 +753,7 @@ func (c *ValidatorClient) registerRPCService(cliCtx *cli.Context) error {
 		ClientGrpcRetryDelay:     grpcRetryDelay,
 		ClientGrpcHeaders:        strings.Split(grpcHeaders, ","),
 		ClientWithCert:           clientCert,
+		Router:                   c.router,
 	})
 	return c.services.RegisterService(server)
 }
@@ -830,7 +833,7 @@ func (c *ValidatorClient) registerRPCGatewayService(cliCtx *cli.Context) error {
 		Mux:           gwmux,
 	}
 	opts := []gateway.Option{
-		gateway.WithRouter(mux.NewRouter()),
+		gateway.WithRouter(c.router),
 		gateway.WithRemoteAddr(rpcAddr),
 		gateway.WithGatewayAddr(gatewayAddress),
 		gateway.WithMaxCallRecvMsgSize(maxCallSize),
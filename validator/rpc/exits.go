@@ -0,0 +1,289 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/go-zond/common/hexutil"
+	"github.com/theQRL/qrysm/v4/beacon-chain/rpc/apimiddleware"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	"github.com/theQRL/qrysm/v4/network"
+	zondpb "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/time/slots"
+	"github.com/theQRL/qrysm/v4/validator/client"
+	beacon_api "github.com/theQRL/qrysm/v4/validator/client/beacon-api"
+	"github.com/theQRL/qrysm/v4/validator/db/kv"
+)
+
+// ScheduledExitsResponse lists the voluntary exits scheduled in the validator client.
+type ScheduledExitsResponse struct {
+	Data []*ScheduledExitJson `json:"data"`
+}
+
+// ScheduledExitJson is a voluntary exit scheduled for a validator key.
+type ScheduledExitJson struct {
+	Pubkey   string `json:"pubkey"`
+	Epoch    string `json:"epoch"`
+	Status   string `json:"status"`
+	Attempts string `json:"attempts"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ScheduleExitsRequest schedules the voluntary exits of validator keys. The exits start at the
+// given epoch, which defaults to the current epoch, and at most per_epoch keys exit per epoch,
+// which defaults to the churn limit reported by the beacon node.
+type ScheduleExitsRequest struct {
+	Pubkeys  []string `json:"pubkeys"`
+	Epoch    string   `json:"epoch"`
+	PerEpoch string   `json:"per_epoch"`
+}
+
+// SignedScheduledExitsResponse contains the signed voluntary exits, in the same format as the exits
+// written by the accounts exit command.
+type SignedScheduledExitsResponse struct {
+	Data []*apimiddleware.SignedVoluntaryExitJson `json:"data"`
+}
+
+// ListScheduledExits returns the voluntary exits scheduled in the validator client along with their status.
+func (s *Server) ListScheduledExits(w http.ResponseWriter, r *http.Request) {
+	if !s.checkScheduledExitsRequest(w, r) {
+		return
+	}
+	exits, err := s.valDB.ScheduledExits(r.Context())
+	if err != nil {
+		handleHTTPError(w, "Could not get scheduled exits: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	network.WriteJson(w, scheduledExitsResponse(exits))
+}
+
+// ScheduleExits plans the voluntary exits of validator keys of the validator client. The exits are
+// signed and submitted by the validator client once their epoch is reached. Keys with an exit
+// which has not failed cannot be scheduled again.
+func (s *Server) ScheduleExits(w http.ResponseWriter, r *http.Request) {
+	if !s.checkScheduledExitsRequest(w, r) {
+		return
+	}
+	ctx := r.Context()
+
+	req := &ScheduleExitsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		handleHTTPError(w, "Could not decode request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Pubkeys) == 0 {
+		handleHTTPError(w, "No public keys specified", http.StatusBadRequest)
+		return
+	}
+	if s.validatorService == nil {
+		handleHTTPError(w, "Validator service not ready", http.StatusServiceUnavailable)
+		return
+	}
+	km, err := s.validatorService.Keymanager()
+	if err != nil {
+		handleHTTPError(w, "Could not get keymanager: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	managedKeys, err := km.FetchValidatingPublicKeys(ctx)
+	if err != nil {
+		handleHTTPError(w, "Could not get validating public keys: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	managed := make(map[[dilithium2.CryptoPublicKeyBytes]byte]bool, len(managedKeys))
+	for _, pubKey := range managedKeys {
+		managed[pubKey] = true
+	}
+	unlock := s.valDB.LockScheduledExits()
+	defer unlock()
+	existing, err := s.valDB.ScheduledExits(ctx)
+	if err != nil {
+		handleHTTPError(w, "Could not get scheduled exits: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	scheduled := make(map[[dilithium2.CryptoPublicKeyBytes]byte]bool, len(existing))
+	for _, exit := range existing {
+		if exit.Status != kv.ExitFailed {
+			scheduled[exit.PublicKey] = true
+		}
+	}
+
+	pubKeys := make([][dilithium2.CryptoPublicKeyBytes]byte, 0, len(req.Pubkeys))
+	for _, hexKey := range req.Pubkeys {
+		pubKey, ok := decodePubKey(hexKey)
+		if !ok {
+			handleHTTPError(w, fmt.Sprintf("Invalid public key %s", hexKey), http.StatusBadRequest)
+			return
+		}
+		if !managed[pubKey] {
+			handleHTTPError(w, fmt.Sprintf("Public key %s is not managed by the validator client", hexKey), http.StatusBadRequest)
+			return
+		}
+		if scheduled[pubKey] {
+			handleHTTPError(w, fmt.Sprintf("Exit of public key %s is already scheduled", hexKey), http.StatusConflict)
+			return
+		}
+		pubKeys = append(pubKeys, pubKey)
+	}
+
+	var epoch primitives.Epoch
+	if req.Epoch != "" {
+		e, err := strconv.ParseUint(req.Epoch, 10, 64)
+		if err != nil {
+			handleHTTPError(w, fmt.Sprintf("Invalid epoch %s", req.Epoch), http.StatusBadRequest)
+			return
+		}
+		epoch = primitives.Epoch(e)
+	} else {
+		if s.genesisFetcher == nil {
+			handleHTTPError(w, "Genesis time is not known, an epoch must be specified", http.StatusServiceUnavailable)
+			return
+		}
+		genesis, err := s.genesisFetcher.GenesisInfo(ctx)
+		if err != nil || genesis.GenesisTime == nil {
+			handleHTTPError(w, "Genesis time is not known, an epoch must be specified", http.StatusServiceUnavailable)
+			return
+		}
+		epoch = slots.EpochsSinceGenesis(genesis.GenesisTime.AsTime())
+	}
+	var perEpoch uint64
+	if req.PerEpoch != "" {
+		perEpoch, err = strconv.ParseUint(req.PerEpoch, 10, 64)
+		if err != nil || perEpoch == 0 {
+			handleHTTPError(w, fmt.Sprintf("Invalid number of exits per epoch %s", req.PerEpoch), http.StatusBadRequest)
+			return
+		}
+	} else if s.beaconChainClient != nil {
+		perEpoch = client.ExitChurnLimit(ctx, s.beaconChainClient)
+	}
+
+	exits := client.ScheduleExits(pubKeys, epoch, perEpoch, existing)
+	if err := s.valDB.SaveScheduledExits(ctx, exits); err != nil {
+		handleHTTPError(w, "Could not save scheduled exits: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	network.WriteJson(w, scheduledExitsResponse(exits))
+}
+
+// CancelScheduledExit removes the scheduled exit of a validator key, unless it has already been submitted.
+func (s *Server) CancelScheduledExit(w http.ResponseWriter, r *http.Request) {
+	if !s.checkScheduledExitsRequest(w, r) {
+		return
+	}
+	ctx := r.Context()
+	hexKey := mux.Vars(r)["pubkey"]
+	pubKey, ok := decodePubKey(hexKey)
+	if !ok {
+		handleHTTPError(w, fmt.Sprintf("Invalid public key %s", hexKey), http.StatusBadRequest)
+		return
+	}
+	unlock := s.valDB.LockScheduledExits()
+	defer unlock()
+	exits, err := s.valDB.ScheduledExits(ctx)
+	if err != nil {
+		handleHTTPError(w, "Could not get scheduled exits: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, exit := range exits {
+		if exit.PublicKey != pubKey {
+			continue
+		}
+		if exit.Status == kv.ExitSubmitted {
+			handleHTTPError(w, fmt.Sprintf("Exit of public key %s has already been submitted", hexKey), http.StatusConflict)
+			return
+		}
+		if err := s.valDB.DeleteScheduledExit(ctx, pubKey); err != nil {
+			handleHTTPError(w, "Could not delete scheduled exit: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	handleHTTPError(w, fmt.Sprintf("No exit scheduled for public key %s", hexKey), http.StatusNotFound)
+}
+
+// SignScheduledExits signs the pending scheduled exits ahead of their epoch and returns every signed
+// exit which has not been submitted yet, so that the signed exits can be kept in escrow by a third party.
+// Signing an exit does not change when the validator client submits it.
+func (s *Server) SignScheduledExits(w http.ResponseWriter, r *http.Request) {
+	if !s.checkScheduledExitsRequest(w, r) {
+		return
+	}
+	ctx := r.Context()
+	if s.validatorService == nil || s.beaconNodeValidatorClient == nil {
+		handleHTTPError(w, "Validator service not ready", http.StatusServiceUnavailable)
+		return
+	}
+	km, err := s.validatorService.Keymanager()
+	if err != nil {
+		handleHTTPError(w, "Could not get keymanager: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	unlock := s.valDB.LockScheduledExits()
+	defer unlock()
+	exits, err := s.valDB.ScheduledExits(ctx)
+	if err != nil {
+		handleHTTPError(w, "Could not get scheduled exits: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := client.SignScheduledExits(ctx, s.beaconNodeValidatorClient, km.Sign, exits); err != nil {
+		handleHTTPError(w, "Could not sign scheduled exits: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := s.valDB.SaveScheduledExits(ctx, exits); err != nil {
+		handleHTTPError(w, "Could not save scheduled exits: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	signed := make([]*zondpb.SignedVoluntaryExit, 0, len(exits))
+	for _, exit := range exits {
+		if exit.Status != kv.ExitSigned {
+			continue
+		}
+		sve := &zondpb.SignedVoluntaryExit{}
+		if err := sve.UnmarshalSSZ(exit.SignedExit); err != nil {
+			handleHTTPError(w, "Could not decode signed exit: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		signed = append(signed, sve)
+	}
+	network.WriteJson(w, &SignedScheduledExitsResponse{Data: beacon_api.JsonifySignedVoluntaryExits(signed)})
+}
+
+func (s *Server) checkScheduledExitsRequest(w http.ResponseWriter, r *http.Request) bool {
+	if err := s.authorizeHTTP(r); err != nil {
+		handleHTTPError(w, err.Error(), http.StatusUnauthorized)
+		return false
+	}
+	if s.valDB == nil {
+		handleHTTPError(w, "Validator database not available", http.StatusServiceUnavailable)
+		return false
+	}
+	return true
+}
+
+func scheduledExitsResponse(exits []*kv.ScheduledExit) *ScheduledExitsResponse {
+	resp := &ScheduledExitsResponse{Data: make([]*ScheduledExitJson, len(exits))}
+	for i, exit := range exits {
+		resp.Data[i] = &ScheduledExitJson{
+			Pubkey:   hexutil.Encode(exit.PublicKey[:]),
+			Epoch:    strconv.FormatUint(uint64(exit.Epoch), 10),
+			Status:   string(exit.Status),
+			Attempts: strconv.FormatUint(exit.Attempts, 10),
+			Error:    exit.Error,
+		}
+	}
+	return resp
+}
+
+func decodePubKey(hexKey string) ([dilithium2.CryptoPublicKeyBytes]byte, bool) {
+	pubKey, err := hexutil.Decode(hexKey)
+	if err != nil || len(pubKey) != dilithium2.CryptoPublicKeyBytes {
+		return [dilithium2.CryptoPublicKeyBytes]byte{}, false
+	}
+	return bytesutil.ToBytes2592(pubKey), true
+}
@@ -0,0 +1,193 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/go-zond/common/hexutil"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	zond "github.com/theQRL/qrysm/v4/proto/prysm/v1alpha1"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	validatormock "github.com/theQRL/qrysm/v4/testing/validator-mock"
+	mock "github.com/theQRL/qrysm/v4/validator/accounts/testing"
+	"github.com/theQRL/qrysm/v4/validator/client"
+	"github.com/theQRL/qrysm/v4/validator/db/kv"
+	dbtest "github.com/theQRL/qrysm/v4/validator/db/testing"
+	"github.com/theQRL/qrysm/v4/validator/keymanager/local"
+)
+
+func setupScheduledExitsServer(t *testing.T, ctrl *gomock.Controller) (*Server, [][dilithium2.CryptoPublicKeyBytes]byte, *validatormock.MockValidatorClient, string) {
+	ctx := context.Background()
+	km, err := local.NewInteropKeymanager(ctx, 0, 3)
+	require.NoError(t, err)
+	pubKeys, err := km.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	vs, err := client.NewValidatorService(ctx, &client.Config{
+		Validator: &mock.MockValidator{Km: km},
+	})
+	require.NoError(t, err)
+
+	jwtSecret, err := createRandomJWTSecret()
+	require.NoError(t, err)
+	token, err := createTokenString(jwtSecret)
+	require.NoError(t, err)
+	validatorClient := validatormock.NewMockValidatorClient(ctrl)
+	s := &Server{
+		valDB:                     dbtest.SetupDB(t, pubKeys),
+		jwtSecret:                 jwtSecret,
+		genesisFetcher:            &mockGenesisFetcher{},
+		validatorService:          vs,
+		beaconNodeValidatorClient: validatorClient,
+	}
+	return s, pubKeys, validatorClient, token
+}
+
+func scheduledExitsRequest(t *testing.T, handler http.HandlerFunc, method, target, token string, body interface{}) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+	req := httptest.NewRequest(method, "http://example.com"+target, &buf)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/v2/validator/exits/{pubkey}", handler)
+	router.HandleFunc("/v2/validator/exits", handler)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestServer_ScheduledExits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	s, pubKeys, validatorClient, token := setupScheduledExitsServer(t, ctrl)
+	ctx := context.Background()
+
+	t.Run("unauthorized", func(t *testing.T) {
+		w := scheduledExitsRequest(t, s.ListScheduledExits, http.MethodGet, "/v2/validator/exits", "", nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+	t.Run("unknown key", func(t *testing.T) {
+		w := scheduledExitsRequest(t, s.ScheduleExits, http.MethodPost, "/v2/validator/exits", token, &ScheduleExitsRequest{
+			Pubkeys: []string{hexutil.Encode(make([]byte, dilithium2.CryptoPublicKeyBytes))},
+		})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.StringContains(t, "is not managed", w.Body.String())
+	})
+	t.Run("schedule", func(t *testing.T) {
+		w := scheduledExitsRequest(t, s.ScheduleExits, http.MethodPost, "/v2/validator/exits", token, &ScheduleExitsRequest{
+			Pubkeys:  []string{hexutil.Encode(pubKeys[0][:]), hexutil.Encode(pubKeys[1][:]), hexutil.Encode(pubKeys[2][:])},
+			Epoch:    "100",
+			PerEpoch: "2",
+		})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		w = scheduledExitsRequest(t, s.ListScheduledExits, http.MethodGet, "/v2/validator/exits", token, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		resp := &ScheduledExitsResponse{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), resp))
+		require.Equal(t, 3, len(resp.Data))
+		epochs := make(map[string]string)
+		for _, exit := range resp.Data {
+			epochs[exit.Pubkey] = exit.Epoch
+			assert.Equal(t, string(kv.ExitPending), exit.Status)
+		}
+		assert.Equal(t, "100", epochs[hexutil.Encode(pubKeys[0][:])])
+		assert.Equal(t, "100", epochs[hexutil.Encode(pubKeys[1][:])])
+		assert.Equal(t, "101", epochs[hexutil.Encode(pubKeys[2][:])])
+	})
+	t.Run("already scheduled", func(t *testing.T) {
+		w := scheduledExitsRequest(t, s.ScheduleExits, http.MethodPost, "/v2/validator/exits", token, &ScheduleExitsRequest{
+			Pubkeys: []string{hexutil.Encode(pubKeys[0][:])},
+			Epoch:   "100",
+		})
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+	t.Run("cancel", func(t *testing.T) {
+		w := scheduledExitsRequest(t, s.CancelScheduledExit, http.MethodDelete, "/v2/validator/exits/"+hexutil.Encode(pubKeys[2][:]), token, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		w = scheduledExitsRequest(t, s.CancelScheduledExit, http.MethodDelete, "/v2/validator/exits/"+hexutil.Encode(pubKeys[2][:]), token, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		exits, err := s.valDB.ScheduledExits(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(exits))
+		exits[1].Status = kv.ExitSubmitted
+		require.NoError(t, s.valDB.SaveScheduledExits(ctx, exits[1:]))
+		w = scheduledExitsRequest(t, s.CancelScheduledExit, http.MethodDelete, "/v2/validator/exits/"+hexutil.Encode(exits[1].PublicKey[:]), token, nil)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+	t.Run("sign", func(t *testing.T) {
+		validatorClient.EXPECT().
+			ValidatorIndex(gomock.Any(), gomock.Any()).
+			Return(&zond.ValidatorIndexResponse{Index: 2}, nil)
+		validatorClient.EXPECT().
+			DomainData(gomock.Any(), gomock.Any()).
+			Return(&zond.DomainResponse{SignatureDomain: make([]byte, 32)}, nil)
+
+		w := scheduledExitsRequest(t, s.SignScheduledExits, http.MethodPost, "/v2/validator/exits", token, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		resp := &SignedScheduledExitsResponse{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), resp))
+		require.Equal(t, 1, len(resp.Data))
+		assert.Equal(t, "100", resp.Data[0].Exit.Epoch)
+		assert.Equal(t, "2", resp.Data[0].Exit.ValidatorIndex)
+
+		exits, err := s.valDB.ScheduledExits(ctx)
+		require.NoError(t, err)
+		statuses := make(map[kv.ExitStatus]int)
+		for _, exit := range exits {
+			statuses[exit.Status]++
+			if exit.Status == kv.ExitSigned {
+				sve := &zond.SignedVoluntaryExit{}
+				require.NoError(t, sve.UnmarshalSSZ(exit.SignedExit))
+				assert.Equal(t, primitives.Epoch(100), sve.Exit.Epoch)
+			}
+		}
+		assert.Equal(t, 1, statuses[kv.ExitSigned])
+		assert.Equal(t, 1, statuses[kv.ExitSubmitted])
+	})
+}
+
+func TestServer_ScheduleExits_RespectsChurnLimitAcrossRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	s, pubKeys, _, token := setupScheduledExitsServer(t, ctrl)
+	ctx := context.Background()
+
+	for _, keys := range [][][dilithium2.CryptoPublicKeyBytes]byte{pubKeys[:2], pubKeys[2:]} {
+		hexKeys := make([]string, len(keys))
+		for i, pubKey := range keys {
+			hexKeys[i] = hexutil.Encode(pubKey[:])
+		}
+		w := scheduledExitsRequest(t, s.ScheduleExits, http.MethodPost, "/v2/validator/exits", token, &ScheduleExitsRequest{
+			Pubkeys:  hexKeys,
+			Epoch:    "100",
+			PerEpoch: "2",
+		})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+
+	exits, err := s.valDB.ScheduledExits(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(exits))
+	perEpoch := make(map[primitives.Epoch]int)
+	for _, exit := range exits {
+		perEpoch[exit.Epoch]++
+	}
+	for epoch, n := range perEpoch {
+		assert.Equal(t, true, n <= 2, "epoch %d has %d exits", epoch, n)
+	}
+	assert.Equal(t, 2, perEpoch[100])
+	assert.Equal(t, 1, perEpoch[101])
+}
@@ -3,9 +3,11 @@ package rpc
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -55,6 +57,19 @@ func (s *Server) authorize(ctx context.Context) error {
 	return nil
 }
 
+// Authorize the token of an HTTP request which is not served through the gRPC gateway.
+func (s *Server) authorizeHTTP(r *http.Request) error {
+	authHeader := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return errors.New("Invalid auth header, needs Bearer {token}")
+	}
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+	if _, err := jwt.Parse(token, s.validateJWT); err != nil {
+		return fmt.Errorf("Could not parse JWT token: %v", err)
+	}
+	return nil
+}
+
 func (s *Server) validateJWT(token *jwt.Token) (interface{}, error) {
 	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 		return nil, fmt.Errorf("unexpected JWT signing method: %v", token.Header["alg"])
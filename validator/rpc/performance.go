@@ -0,0 +1,257 @@
+package rpc
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/go-zond/common/hexutil"
+	"github.com/theQRL/qrysm/v4/config/params"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/encoding/bytesutil"
+	"github.com/theQRL/qrysm/v4/network"
+	"github.com/theQRL/qrysm/v4/time/slots"
+	"github.com/theQRL/qrysm/v4/validator/db/kv"
+)
+
+const (
+	performanceFormatJSON = "json"
+	performanceFormatCSV  = "csv"
+	performanceDateLayout = "2006-01-02"
+)
+
+// PerformanceHistoryResponse is the JSON representation of the performance history of validator keys.
+type PerformanceHistoryResponse struct {
+	Data []*PerformanceHistoryJson `json:"data"`
+}
+
+// PerformanceHistoryJson is the performance history of a single validator key.
+type PerformanceHistoryJson struct {
+	Pubkey  string                   `json:"pubkey"`
+	Records []*PerformanceRecordJson `json:"records"`
+}
+
+// PerformanceRecordJson is a performance record of an epoch, along with the derived balance change
+// and the time at which the epoch started.
+type PerformanceRecordJson struct {
+	*kv.PerformanceRecord
+	BalanceChange  int64  `json:"balance_change"`
+	EpochStartTime string `json:"epoch_start_time,omitempty"`
+}
+
+// PerformanceHistory returns the per-epoch performance records of the validator keys, as saved by the
+// validator client when --enable-validator-performance-history is set. The history can be filtered by
+// public key with the repeatable pubkey parameter, and limited to a range either of epochs with the
+// start_epoch and end_epoch parameters, or of dates with the start_time and end_time parameters, which
+// accept RFC 3339 timestamps or YYYY-MM-DD dates. Setting the format parameter to csv returns a CSV file
+// instead of JSON.
+func (s *Server) PerformanceHistory(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorizeHTTP(r); err != nil {
+		handleHTTPError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if s.valDB == nil {
+		handleHTTPError(w, "Validator database not available", http.StatusServiceUnavailable)
+		return
+	}
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	outputFormat := query.Get("format")
+	if outputFormat == "" {
+		outputFormat = performanceFormatJSON
+	}
+	if outputFormat != performanceFormatJSON && outputFormat != performanceFormatCSV {
+		handleHTTPError(w, fmt.Sprintf("Unsupported format %s, wanted %s or %s", outputFormat, performanceFormatJSON, performanceFormatCSV), http.StatusBadRequest)
+		return
+	}
+
+	var genesisTime time.Time
+	if s.genesisFetcher != nil {
+		genesis, err := s.genesisFetcher.GenesisInfo(ctx)
+		if err == nil && genesis.GenesisTime != nil {
+			genesisTime = genesis.GenesisTime.AsTime()
+		}
+	}
+	start, end, err := performanceEpochRange(query.Get("start_epoch"), query.Get("end_epoch"), query.Get("start_time"), query.Get("end_time"), genesisTime)
+	if err != nil {
+		handleHTTPError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var pubKeys [][dilithium2.CryptoPublicKeyBytes]byte
+	if len(query["pubkey"]) > 0 {
+		for _, hexKey := range query["pubkey"] {
+			pubKey, err := hexutil.Decode(hexKey)
+			if err != nil || len(pubKey) != dilithium2.CryptoPublicKeyBytes {
+				handleHTTPError(w, fmt.Sprintf("Invalid public key %s", hexKey), http.StatusBadRequest)
+				return
+			}
+			pubKeys = append(pubKeys, bytesutil.ToBytes2592(pubKey))
+		}
+	} else {
+		pubKeys, err = s.valDB.PerformancePublicKeys(ctx)
+		if err != nil {
+			handleHTTPError(w, "Could not get public keys with performance history: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	resp := &PerformanceHistoryResponse{Data: make([]*PerformanceHistoryJson, 0, len(pubKeys))}
+	for _, pubKey := range pubKeys {
+		records, err := s.valDB.PerformanceHistoryForPubKey(ctx, pubKey, start, end)
+		if err != nil {
+			handleHTTPError(w, "Could not get performance history: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		history := &PerformanceHistoryJson{
+			Pubkey:  hexutil.Encode(pubKey[:]),
+			Records: make([]*PerformanceRecordJson, len(records)),
+		}
+		for i, record := range records {
+			history.Records[i] = &PerformanceRecordJson{
+				PerformanceRecord: record,
+				BalanceChange:     record.BalanceChange(),
+			}
+			if !genesisTime.IsZero() {
+				history.Records[i].EpochStartTime = epochStartTime(genesisTime, record.Epoch).Format(time.RFC3339)
+			}
+		}
+		resp.Data = append(resp.Data, history)
+	}
+
+	if outputFormat == performanceFormatCSV {
+		writePerformanceHistoryCSV(w, resp)
+		return
+	}
+	network.WriteJson(w, resp)
+}
+
+// performanceEpochRange determines the inclusive range of epochs to return, either from epoch
+// or from time parameters. Time parameters require the genesis time of the chain.
+func performanceEpochRange(startEpoch, endEpoch, startTime, endTime string, genesisTime time.Time) (primitives.Epoch, primitives.Epoch, error) {
+	start, end := primitives.Epoch(0), primitives.Epoch(^uint64(0))
+	if (startTime != "" || endTime != "") && (startEpoch != "" || endEpoch != "") {
+		return 0, 0, errors.New("Only one of epoch or time ranges can be specified")
+	}
+	if startEpoch != "" {
+		e, err := strconv.ParseUint(startEpoch, 10, 64)
+		if err != nil {
+			return 0, 0, errors.Errorf("Invalid start epoch %s", startEpoch)
+		}
+		start = primitives.Epoch(e)
+	}
+	if endEpoch != "" {
+		e, err := strconv.ParseUint(endEpoch, 10, 64)
+		if err != nil {
+			return 0, 0, errors.Errorf("Invalid end epoch %s", endEpoch)
+		}
+		end = primitives.Epoch(e)
+	}
+	if startTime != "" || endTime != "" {
+		if genesisTime.IsZero() {
+			return 0, 0, errors.New("Genesis time is not known, cannot filter by time")
+		}
+		if startTime != "" {
+			t, err := parsePerformanceTime(startTime)
+			if err != nil {
+				return 0, 0, err
+			}
+			start = epochAtTime(genesisTime, t)
+		}
+		if endTime != "" {
+			t, err := parsePerformanceTime(endTime)
+			if err != nil {
+				return 0, 0, err
+			}
+			// A date without a time covers the whole day.
+			if len(endTime) == len(performanceDateLayout) {
+				t = t.Add(24*time.Hour - time.Nanosecond)
+			}
+			if t.Before(genesisTime) {
+				return 0, 0, errors.New("End time is before genesis")
+			}
+			end = epochAtTime(genesisTime, t)
+		}
+	}
+	if start > end {
+		return 0, 0, errors.New("Start of range is after its end")
+	}
+	return start, end, nil
+}
+
+func parsePerformanceTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(performanceDateLayout, s)
+	if err != nil {
+		return time.Time{}, errors.Errorf("Invalid time %s, wanted an RFC 3339 timestamp or a YYYY-MM-DD date", s)
+	}
+	return t, nil
+}
+
+// epochAtTime returns the epoch at the given time, or the genesis epoch for times before genesis.
+func epochAtTime(genesisTime, t time.Time) primitives.Epoch {
+	if t.Before(genesisTime) {
+		return 0
+	}
+	slot := primitives.Slot(uint64(t.Sub(genesisTime).Seconds()) / params.BeaconConfig().SecondsPerSlot)
+	return slots.ToEpoch(slot)
+}
+
+func epochStartTime(genesisTime time.Time, epoch primitives.Epoch) time.Time {
+	slot, err := slots.EpochStart(epoch)
+	if err != nil {
+		return time.Time{}
+	}
+	return genesisTime.Add(time.Duration(uint64(slot)*params.BeaconConfig().SecondsPerSlot) * time.Second).UTC()
+}
+
+func writePerformanceHistoryCSV(w http.ResponseWriter, resp *PerformanceHistoryResponse) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="performance_history.csv"`)
+	w.WriteHeader(http.StatusOK)
+	writer := csv.NewWriter(w)
+	rows := [][]string{{
+		"pubkey", "epoch", "epoch_start_time", "inclusion_distance",
+		"correctly_voted_source", "correctly_voted_target", "correctly_voted_head",
+		"proposals_made", "proposals_missed", "sync_committee_messages", "sync_committee_missed",
+		"balance_before", "balance_after", "balance_change",
+	}}
+	for _, history := range resp.Data {
+		for _, r := range history.Records {
+			rows = append(rows, []string{
+				history.Pubkey,
+				strconv.FormatUint(uint64(r.Epoch), 10),
+				r.EpochStartTime,
+				strconv.FormatUint(uint64(r.InclusionDistance), 10),
+				strconv.FormatBool(r.CorrectlyVotedSource),
+				strconv.FormatBool(r.CorrectlyVotedTarget),
+				strconv.FormatBool(r.CorrectlyVotedHead),
+				strconv.FormatUint(r.ProposalsMade, 10),
+				strconv.FormatUint(r.ProposalsMissed, 10),
+				strconv.FormatUint(r.SyncCommitteeMessages, 10),
+				strconv.FormatUint(r.SyncCommitteeMissed, 10),
+				strconv.FormatUint(r.BalanceBefore, 10),
+				strconv.FormatUint(r.BalanceAfter, 10),
+				strconv.FormatInt(r.BalanceChange, 10),
+			})
+		}
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		log.WithError(err).Error("Could not write performance history CSV")
+	}
+}
+
+func handleHTTPError(w http.ResponseWriter, message string, code int) {
+	errJson := &network.DefaultErrorJson{
+		Message: message,
+		Code:    code,
+	}
+	network.WriteError(w, errJson)
+}
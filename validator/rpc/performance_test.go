@@ -0,0 +1,130 @@
+package rpc
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/go-zond/common/hexutil"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/validator/db/kv"
+	dbtest "github.com/theQRL/qrysm/v4/validator/db/testing"
+)
+
+func setupPerformanceHistoryServer(t *testing.T) (*Server, [][dilithium2.CryptoPublicKeyBytes]byte, string) {
+	ctx := context.Background()
+	pubKeys := [][dilithium2.CryptoPublicKeyBytes]byte{{1}, {2}}
+	valDB := dbtest.SetupDB(t, pubKeys)
+	for epoch := primitives.Epoch(0); epoch < 10; epoch++ {
+		require.NoError(t, valDB.SavePerformanceRecords(ctx, pubKeys, []*kv.PerformanceRecord{
+			{Epoch: epoch, InclusionDistance: 1, CorrectlyVotedHead: true, BalanceBefore: 10, BalanceAfter: 12},
+			{Epoch: epoch, ProposalsMissed: 1, BalanceBefore: 10, BalanceAfter: 9},
+		}))
+	}
+	jwtSecret, err := createRandomJWTSecret()
+	require.NoError(t, err)
+	token, err := createTokenString(jwtSecret)
+	require.NoError(t, err)
+	s := &Server{
+		valDB:          valDB,
+		jwtSecret:      jwtSecret,
+		genesisFetcher: &mockGenesisFetcher{},
+	}
+	return s, pubKeys, token
+}
+
+func performanceHistoryRequest(s *Server, token string, query url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/v2/validator/performance/history?"+query.Encode(), nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.PerformanceHistory(w, req)
+	return w
+}
+
+func TestServer_PerformanceHistory(t *testing.T) {
+	s, pubKeys, token := setupPerformanceHistoryServer(t)
+
+	t.Run("unauthorized", func(t *testing.T) {
+		w := performanceHistoryRequest(s, "", url.Values{})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+	t.Run("all keys", func(t *testing.T) {
+		w := performanceHistoryRequest(s, token, url.Values{})
+		require.Equal(t, http.StatusOK, w.Code)
+		resp := &PerformanceHistoryResponse{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), resp))
+		require.Equal(t, 2, len(resp.Data))
+		assert.Equal(t, 10, len(resp.Data[0].Records))
+		assert.Equal(t, 10, len(resp.Data[1].Records))
+	})
+	t.Run("pubkey and epoch range", func(t *testing.T) {
+		w := performanceHistoryRequest(s, token, url.Values{
+			"pubkey":      []string{hexutil.Encode(pubKeys[1][:])},
+			"start_epoch": []string{"3"},
+			"end_epoch":   []string{"5"},
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+		resp := &PerformanceHistoryResponse{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), resp))
+		require.Equal(t, 1, len(resp.Data))
+		require.Equal(t, 3, len(resp.Data[0].Records))
+		record := resp.Data[0].Records[0]
+		assert.Equal(t, primitives.Epoch(3), record.Epoch)
+		assert.Equal(t, uint64(1), record.ProposalsMissed)
+		assert.Equal(t, int64(-1), record.BalanceChange)
+		assert.Equal(t, epochStartTime(time.Unix(0, 0), 3).Format(time.RFC3339), record.EpochStartTime)
+	})
+	t.Run("time range", func(t *testing.T) {
+		w := performanceHistoryRequest(s, token, url.Values{
+			"pubkey":     []string{hexutil.Encode(pubKeys[0][:])},
+			"start_time": []string{epochStartTime(time.Unix(0, 0), 2).Format(time.RFC3339)},
+			"end_time":   []string{epochStartTime(time.Unix(0, 0), 4).Format(time.RFC3339)},
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+		resp := &PerformanceHistoryResponse{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), resp))
+		require.Equal(t, 3, len(resp.Data[0].Records))
+		assert.Equal(t, primitives.Epoch(2), resp.Data[0].Records[0].Epoch)
+		assert.Equal(t, primitives.Slot(1), resp.Data[0].Records[0].InclusionDistance)
+	})
+	t.Run("csv", func(t *testing.T) {
+		w := performanceHistoryRequest(s, token, url.Values{
+			"pubkey":    []string{hexutil.Encode(pubKeys[0][:])},
+			"end_epoch": []string{"1"},
+			"format":    []string{"csv"},
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+		rows, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+		require.NoError(t, err)
+		require.Equal(t, 3, len(rows))
+		assert.Equal(t, "pubkey", rows[0][0])
+		assert.Equal(t, hexutil.Encode(pubKeys[0][:]), rows[1][0])
+		assert.Equal(t, "inclusion_distance", rows[0][3])
+		assert.Equal(t, "1", rows[1][3])
+		assert.Equal(t, "2", rows[1][len(rows[1])-1])
+	})
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, query := range []url.Values{
+			{"format": []string{"xml"}},
+			{"pubkey": []string{"0x01"}},
+			{"start_epoch": []string{"5"}, "end_epoch": []string{"3"}},
+			{"start_epoch": []string{"1"}, "start_time": []string{"2023-01-01"}},
+			{"start_time": []string{"yesterday"}},
+		} {
+			w := performanceHistoryRequest(s, token, query)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query.Encode())
+		}
+	})
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http"
 	"path/filepath"
 	"time"
 
+	"github.com/gorilla/mux"
 	middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
 	grpcopentracing "github.com/grpc-ecosystem/go-grpc-middleware/tracing/opentracing"
@@ -52,6 +54,7 @@ type Config struct {
 	WalletInitializedFeed    *event.Feed
 	NodeGatewayEndpoint      string
 	Wallet                   *wallet.Wallet
+	Router                   *mux.Router
 }
 
 // Server defining a gRPC server for the remote signer API.
@@ -93,12 +96,13 @@ type Server struct {
 	validatorGatewayPort      int
 	beaconApiEndpoint         string
 	beaconApiTimeout          time.Duration
+	router                    *mux.Router
 }
 
 // NewServer instantiates a new gRPC server.
 func NewServer(ctx context.Context, cfg *Config) *Server {
 	ctx, cancel := context.WithCancel(ctx)
-	return &Server{
+	server := &Server{
 		ctx:                      ctx,
 		cancel:                   cancel,
 		logsStreamer:             logs.NewStreamServer(),
@@ -126,7 +130,17 @@ func NewServer(ctx context.Context, cfg *Config) *Server {
 		validatorMonitoringPort:  cfg.ValidatorMonitoringPort,
 		validatorGatewayHost:     cfg.ValidatorGatewayHost,
 		validatorGatewayPort:     cfg.ValidatorGatewayPort,
+		router:                   cfg.Router,
 	}
+	// Routes are registered before the gateway starts, so that they take precedence over its catch-all handlers.
+	if server.router != nil {
+		server.router.HandleFunc("/v2/validator/performance/history", server.PerformanceHistory).Methods(http.MethodGet)
+		server.router.HandleFunc("/v2/validator/exits", server.ListScheduledExits).Methods(http.MethodGet)
+		server.router.HandleFunc("/v2/validator/exits", server.ScheduleExits).Methods(http.MethodPost)
+		server.router.HandleFunc("/v2/validator/exits/sign", server.SignScheduledExits).Methods(http.MethodPost)
+		server.router.HandleFunc("/v2/validator/exits/{pubkey}", server.CancelScheduledExit).Methods(http.MethodDelete)
+	}
+	return server
 }
 
 // Start the gRPC server.
@@ -1,4 +1,4 @@
-// Package history defines methods to parse, import, and export slashing protection data
-// from a standard JSON file according to EIP-3076 https://eips.ethereum.org/EIPS/eip-3076. This format
-// is critical to allow safe interoperability between Ethereum consensus clients.
+// Package history defines methods to parse, import, export, merge and minify slashing protection data
+// from a standard JSON file according to EIP-3076 https://eips.ethereum.org/EIPS/eip-3076, adapted to
+// Dilithium public keys. This format is critical to allow safe migration of validators between machines.
 package history
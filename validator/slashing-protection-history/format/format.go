@@ -1,8 +1,19 @@
 package format
 
-// InterchangeFormatVersion specified by https://eips.ethereum.org/EIPS/eip-3076.
-// The version Prysm supports is version 5.
-const InterchangeFormatVersion = "5"
+// InterchangeFormatVersion of the Zond slashing protection interchange format written by Qrysm.
+// The format follows the layout of https://eips.ethereum.org/EIPS/eip-3076, with public keys
+// being 2592-byte Dilithium public keys instead of 48-byte BLS public keys.
+const InterchangeFormatVersion = "zond-1"
+
+// EIPInterchangeFormatVersion specified by https://eips.ethereum.org/EIPS/eip-3076, which the
+// Zond format is based on. Files of this version are still accepted, provided their public keys
+// are Dilithium public keys.
+const EIPInterchangeFormatVersion = "5"
+
+// IsSupportedVersion returns true if interchange files of the given version can be read.
+func IsSupportedVersion(version string) bool {
+	return version == InterchangeFormatVersion || version == EIPInterchangeFormatVersion
+}
 
 // EIPSlashingProtectionFormat string representation of a standard
 // format for representing validator slashing protection db data.
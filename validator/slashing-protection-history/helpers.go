@@ -53,28 +53,21 @@ func SlotFromString(str string) (primitives.Slot, error) {
 	return primitives.Slot(s), nil
 }
 
-// PubKeyFromHex takes in a hex string, verifies its length as 48 bytes, and converts that representation.
+// PubKeyFromHex takes in a hex string, verifies its length as 2592 bytes, and converts that representation.
 func PubKeyFromHex(str string) ([dilithium2.CryptoPublicKeyBytes]byte, error) {
-	pubKeyBytes, err := hex.DecodeString(strings.TrimPrefix(str, "0x"))
-	if err != nil {
-		return [dilithium2.CryptoPublicKeyBytes]byte{}, err
-	}
-	if len(pubKeyBytes) != 48 {
-		return [dilithium2.CryptoPublicKeyBytes]byte{}, fmt.Errorf("public key is not correct, 48-byte length: %s", str)
-	}
-	var pk [dilithium2.CryptoPublicKeyBytes]byte
-	copy(pk[:], pubKeyBytes[:dilithium2.CryptoPublicKeyBytes])
-	return pk, nil
+	return DilithiumPubKeyFromHex(str)
 }
 
-// DilithiumPubKeyFromHex takes in a hex string, verifies its length as 48 bytes, and converts that representation.
+// DilithiumPubKeyFromHex takes in a hex string, verifies its length as 2592 bytes, and converts that representation.
 func DilithiumPubKeyFromHex(str string) ([dilithium2.CryptoPublicKeyBytes]byte, error) {
 	pubKeyBytes, err := hex.DecodeString(strings.TrimPrefix(str, "0x"))
 	if err != nil {
 		return [dilithium2.CryptoPublicKeyBytes]byte{}, err
 	}
 	if len(pubKeyBytes) != dilithium2.CryptoPublicKeyBytes {
-		return [dilithium2.CryptoPublicKeyBytes]byte{}, fmt.Errorf("public key is not correct, 48-byte length: %s", str)
+		return [dilithium2.CryptoPublicKeyBytes]byte{}, fmt.Errorf(
+			"public key is not correct, %d-byte length: %s", dilithium2.CryptoPublicKeyBytes, str,
+		)
 	}
 	var pk [dilithium2.CryptoPublicKeyBytes]byte
 	copy(pk[:], pubKeyBytes[:dilithium2.CryptoPublicKeyBytes])
@@ -107,8 +100,8 @@ func rootToHexString(root []byte) (string, error) {
 }
 
 func pubKeyToHexString(pubKey []byte) (string, error) {
-	if len(pubKey) != 48 {
-		return "", fmt.Errorf("wanted length 48, received %d", len(pubKey))
+	if len(pubKey) != dilithium2.CryptoPublicKeyBytes {
+		return "", fmt.Errorf("wanted length %d, received %d", dilithium2.CryptoPublicKeyBytes, len(pubKey))
 	}
 	return fmt.Sprintf("%#x", pubKey), nil
 }
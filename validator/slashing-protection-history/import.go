@@ -140,7 +140,7 @@ func ImportStandardProtectionJSON(ctx context.Context, validatorDB db.Database,
 func validateMetadata(ctx context.Context, validatorDB db.Database, interchangeJSON *format.EIPSlashingProtectionFormat) error {
 	// We need to ensure the version in the metadata field matches the one we support.
 	version := interchangeJSON.Metadata.InterchangeFormatVersion
-	if version != format.InterchangeFormatVersion {
+	if !format.IsSupportedVersion(version) {
 		return fmt.Errorf(
 			"slashing protection JSON version '%s' is not supported, wanted '%s'",
 			version,
@@ -265,9 +265,33 @@ func filterSlashablePubKeysFromAttestations(
 	validatorDB db.Database,
 	signedAttsByPubKey map[[dilithium2.CryptoPublicKeyBytes]byte][]*kv.AttestationRecord,
 ) ([][dilithium2.CryptoPublicKeyBytes]byte, error) {
-	slashablePubKeys := make([][dilithium2.CryptoPublicKeyBytes]byte, 0)
 	// First we need to find attestations that are slashable with respect to other
 	// attestations within the same JSON import.
+	slashablePubKeys := filterSlashablePubKeysWithinAttestations(signedAttsByPubKey)
+	// Then, we need to find attestations that are slashable with respect to our database.
+	for pubKey, signedAtts := range signedAttsByPubKey {
+		for _, att := range signedAtts {
+			indexedAtt := createAttestation(att.Source, att.Target)
+			slashable, err := validatorDB.CheckSlashableAttestation(ctx, pubKey, att.SigningRoot, indexedAtt)
+			if err != nil {
+				return nil, err
+			}
+			// Malformed data should not prevent us from completing this function.
+			if slashable != kv.NotSlashable {
+				slashablePubKeys = append(slashablePubKeys, pubKey)
+				break
+			}
+		}
+	}
+	return slashablePubKeys, nil
+}
+
+// filterSlashablePubKeysWithinAttestations returns the public keys with double or surround
+// votes among their own attestations.
+func filterSlashablePubKeysWithinAttestations(
+	signedAttsByPubKey map[[dilithium2.CryptoPublicKeyBytes]byte][]*kv.AttestationRecord,
+) [][dilithium2.CryptoPublicKeyBytes]byte {
+	slashablePubKeys := make([][dilithium2.CryptoPublicKeyBytes]byte, 0)
 	for pubKey, signedAtts := range signedAttsByPubKey {
 		signingRootsByTarget := make(map[primitives.Epoch][32]byte)
 		targetEpochsBySource := make(map[primitives.Epoch][]primitives.Epoch)
@@ -295,22 +319,7 @@ func filterSlashablePubKeysFromAttestations(
 			targetEpochsBySource[att.Source] = append(targetEpochsBySource[att.Source], att.Target)
 		}
 	}
-	// Then, we need to find attestations that are slashable with respect to our database.
-	for pubKey, signedAtts := range signedAttsByPubKey {
-		for _, att := range signedAtts {
-			indexedAtt := createAttestation(att.Source, att.Target)
-			slashable, err := validatorDB.CheckSlashableAttestation(ctx, pubKey, att.SigningRoot, indexedAtt)
-			if err != nil {
-				return nil, err
-			}
-			// Malformed data should not prevent us from completing this function.
-			if slashable != kv.NotSlashable {
-				slashablePubKeys = append(slashablePubKeys, pubKey)
-				break
-			}
-		}
-	}
-	return slashablePubKeys, nil
+	return slashablePubKeys
 }
 
 func transformSignedBlocks(_ context.Context, signedBlocks []*format.SignedBlock) (*kv.ProposalHistoryForPubkey, error) {
@@ -0,0 +1,353 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/consensus-types/primitives"
+	"github.com/theQRL/qrysm/v4/validator/db/kv"
+	"github.com/theQRL/qrysm/v4/validator/slashing-protection-history/format"
+)
+
+// ValidateStandardProtectionJSON checks that an interchange file is of a supported version and
+// that every public key, slot, epoch and signing root within it is well-formed. Public keys
+// must be 2592-byte Dilithium public keys.
+func ValidateStandardProtectionJSON(ctx context.Context, interchangeJSON *format.EIPSlashingProtectionFormat) error {
+	if interchangeJSON == nil {
+		return errors.New("nil slashing protection JSON")
+	}
+	version := interchangeJSON.Metadata.InterchangeFormatVersion
+	if !format.IsSupportedVersion(version) {
+		return fmt.Errorf(
+			"slashing protection JSON version '%s' is not supported, wanted '%s'",
+			version,
+			format.InterchangeFormatVersion,
+		)
+	}
+	if _, err := RootFromHex(interchangeJSON.Metadata.GenesisValidatorsRoot); err != nil {
+		return fmt.Errorf("%s is not a valid root: %w", interchangeJSON.Metadata.GenesisValidatorsRoot, err)
+	}
+	for _, validatorData := range interchangeJSON.Data {
+		if validatorData == nil {
+			continue
+		}
+		pubKey, err := DilithiumPubKeyFromHex(validatorData.Pubkey)
+		if err != nil {
+			return fmt.Errorf("%s is not a valid public key: %w", validatorData.Pubkey, err)
+		}
+		if _, err := transformSignedBlocks(ctx, nonNilBlocks(validatorData.SignedBlocks)); err != nil {
+			return errors.Wrapf(err, "could not parse signed blocks for key %#x", pubKey)
+		}
+		atts, err := transformSignedAttestations(pubKey, nonNilAttestations(validatorData.SignedAttestations))
+		if err != nil {
+			return errors.Wrapf(err, "could not parse signed attestations for key %#x", pubKey)
+		}
+		for _, att := range atts {
+			if att.Source > att.Target {
+				return fmt.Errorf(
+					"attestation for key %#x has source epoch %d greater than target epoch %d",
+					pubKey, att.Source, att.Target,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// MergeStandardProtectionJSONs merges several interchange files of the same chain, for
+// example exported from two machines which ran the same keys, into a single interchange file.
+//
+// The history of every public key in the result is the union of its histories in each file,
+// so the result never allows signing a message that one of the inputs would have refused.
+// Public keys whose combined history is slashable in itself, for example because two
+// machines signed different blocks at the same slot, are returned alongside the result.
+// Such keys will be refused by import, but can still be imported after minification.
+func MergeStandardProtectionJSONs(
+	ctx context.Context,
+	interchangeJSONs ...*format.EIPSlashingProtectionFormat,
+) (*format.EIPSlashingProtectionFormat, [][dilithium2.CryptoPublicKeyBytes]byte, error) {
+	if len(interchangeJSONs) == 0 {
+		return nil, nil, errors.New("no slashing protection JSON to merge")
+	}
+	var genesisValidatorsRoot [32]byte
+	for i, interchangeJSON := range interchangeJSONs {
+		if err := ValidateStandardProtectionJSON(ctx, interchangeJSON); err != nil {
+			return nil, nil, errors.Wrapf(err, "slashing protection JSON %d is invalid", i)
+		}
+		gvr, err := RootFromHex(interchangeJSON.Metadata.GenesisValidatorsRoot)
+		if err != nil {
+			return nil, nil, err
+		}
+		if i == 0 {
+			genesisValidatorsRoot = gvr
+		} else if gvr != genesisValidatorsRoot {
+			return nil, nil, fmt.Errorf(
+				"slashing protection JSON %d has genesis validators root %#x, which differs from %#x. "+
+					"Only histories of the same chain can be merged",
+				i, gvr, genesisValidatorsRoot,
+			)
+		}
+	}
+
+	data := make([]*format.ProtectionData, 0)
+	for _, interchangeJSON := range interchangeJSONs {
+		data = append(data, interchangeJSON.Data...)
+	}
+	signedBlocksByPubKey, err := parseBlocksForUniquePublicKeys(data)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not parse unique entries for blocks by public key")
+	}
+	signedAttsByPubKey, err := parseAttestationsForUniquePublicKeys(data)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not parse unique entries for attestations by public key")
+	}
+
+	proposalHistoryByPubKey := make(map[[dilithium2.CryptoPublicKeyBytes]byte]kv.ProposalHistoryForPubkey)
+	attestingHistoryByPubKey := make(map[[dilithium2.CryptoPublicKeyBytes]byte][]*kv.AttestationRecord)
+	merged := make(map[[dilithium2.CryptoPublicKeyBytes]byte]*format.ProtectionData)
+	entryFor := func(pubKey [dilithium2.CryptoPublicKeyBytes]byte) *format.ProtectionData {
+		if _, ok := merged[pubKey]; !ok {
+			merged[pubKey] = &format.ProtectionData{
+				Pubkey:             fmt.Sprintf("%#x", pubKey),
+				SignedBlocks:       make([]*format.SignedBlock, 0),
+				SignedAttestations: make([]*format.SignedAttestation, 0),
+			}
+		}
+		return merged[pubKey]
+	}
+	for pubKey, signedBlocks := range signedBlocksByPubKey {
+		proposalHistory, err := transformSignedBlocks(ctx, signedBlocks)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "could not parse signed blocks for key %#x", pubKey)
+		}
+		proposals := uniqueProposals(proposalHistory.Proposals)
+		proposalHistoryByPubKey[pubKey] = kv.ProposalHistoryForPubkey{Proposals: proposals}
+		entry := entryFor(pubKey)
+		for _, proposal := range proposals {
+			entry.SignedBlocks = append(entry.SignedBlocks, &format.SignedBlock{
+				Slot:        fmt.Sprintf("%d", proposal.Slot),
+				SigningRoot: optionalRootToHexString(proposal.SigningRoot),
+			})
+		}
+	}
+	for pubKey, signedAtts := range signedAttsByPubKey {
+		attestations, err := transformSignedAttestations(pubKey, signedAtts)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "could not parse signed attestations for key %#x", pubKey)
+		}
+		attestations = uniqueAttestations(attestations)
+		attestingHistoryByPubKey[pubKey] = attestations
+		entry := entryFor(pubKey)
+		for _, att := range attestations {
+			entry.SignedAttestations = append(entry.SignedAttestations, &format.SignedAttestation{
+				SourceEpoch: fmt.Sprintf("%d", att.Source),
+				TargetEpoch: fmt.Sprintf("%d", att.Target),
+				SigningRoot: optionalRootToHexString(att.SigningRoot[:]),
+			})
+		}
+	}
+	// Keys without any history are kept, so that the result lists every key of the inputs.
+	for _, validatorData := range data {
+		if validatorData == nil {
+			continue
+		}
+		pubKey, err := DilithiumPubKeyFromHex(validatorData.Pubkey)
+		if err != nil {
+			return nil, nil, err
+		}
+		entryFor(pubKey)
+	}
+
+	slashable := make(map[[dilithium2.CryptoPublicKeyBytes]byte]bool)
+	for _, pubKey := range filterSlashablePubKeysFromBlocks(ctx, proposalHistoryByPubKey) {
+		slashable[pubKey] = true
+	}
+	for _, pubKey := range filterSlashablePubKeysWithinAttestations(attestingHistoryByPubKey) {
+		slashable[pubKey] = true
+	}
+	slashablePubKeys := make([][dilithium2.CryptoPublicKeyBytes]byte, 0, len(slashable))
+	for pubKey := range slashable {
+		slashablePubKeys = append(slashablePubKeys, pubKey)
+	}
+	sort.Slice(slashablePubKeys, func(i, j int) bool {
+		return string(slashablePubKeys[i][:]) < string(slashablePubKeys[j][:])
+	})
+
+	result := &format.EIPSlashingProtectionFormat{Data: sortedProtectionData(merged)}
+	result.Metadata.InterchangeFormatVersion = format.InterchangeFormatVersion
+	result.Metadata.GenesisValidatorsRoot = fmt.Sprintf("%#x", genesisValidatorsRoot)
+	return result, slashablePubKeys, nil
+}
+
+// MinifyStandardProtectionJSON reduces the history of every public key in an interchange file
+// to its latest safe watermarks, as described in the minimal format of EIP-3076:
+// a single block at the highest signed slot, and a single attestation with the highest
+// signed source and target epochs. Signing roots are omitted, which prevents signing
+// anything at or below the watermarks again.
+func MinifyStandardProtectionJSON(ctx context.Context, interchangeJSON *format.EIPSlashingProtectionFormat) (*format.EIPSlashingProtectionFormat, error) {
+	if err := ValidateStandardProtectionJSON(ctx, interchangeJSON); err != nil {
+		return nil, errors.Wrap(err, "slashing protection JSON is invalid")
+	}
+	type watermarks struct {
+		hasBlock, hasAttestation bool
+		slot                     primitives.Slot
+		source, target           primitives.Epoch
+	}
+	watermarksByPubKey := make(map[[dilithium2.CryptoPublicKeyBytes]byte]*watermarks)
+	for _, validatorData := range interchangeJSON.Data {
+		if validatorData == nil {
+			continue
+		}
+		pubKey, err := DilithiumPubKeyFromHex(validatorData.Pubkey)
+		if err != nil {
+			return nil, err
+		}
+		w, ok := watermarksByPubKey[pubKey]
+		if !ok {
+			w = &watermarks{}
+			watermarksByPubKey[pubKey] = w
+		}
+		for _, block := range nonNilBlocks(validatorData.SignedBlocks) {
+			slot, err := SlotFromString(block.Slot)
+			if err != nil {
+				return nil, fmt.Errorf("%s is not a valid slot: %w", block.Slot, err)
+			}
+			if !w.hasBlock || slot > w.slot {
+				w.slot = slot
+			}
+			w.hasBlock = true
+		}
+		for _, att := range nonNilAttestations(validatorData.SignedAttestations) {
+			source, err := EpochFromString(att.SourceEpoch)
+			if err != nil {
+				return nil, fmt.Errorf("%s is not a valid epoch: %w", att.SourceEpoch, err)
+			}
+			target, err := EpochFromString(att.TargetEpoch)
+			if err != nil {
+				return nil, fmt.Errorf("%s is not a valid epoch: %w", att.TargetEpoch, err)
+			}
+			if !w.hasAttestation || source > w.source {
+				w.source = source
+			}
+			if !w.hasAttestation || target > w.target {
+				w.target = target
+			}
+			w.hasAttestation = true
+		}
+	}
+
+	minified := make(map[[dilithium2.CryptoPublicKeyBytes]byte]*format.ProtectionData, len(watermarksByPubKey))
+	for pubKey, w := range watermarksByPubKey {
+		entry := &format.ProtectionData{
+			Pubkey:             fmt.Sprintf("%#x", pubKey),
+			SignedBlocks:       make([]*format.SignedBlock, 0, 1),
+			SignedAttestations: make([]*format.SignedAttestation, 0, 1),
+		}
+		if w.hasBlock {
+			entry.SignedBlocks = append(entry.SignedBlocks, &format.SignedBlock{
+				Slot: fmt.Sprintf("%d", w.slot),
+			})
+		}
+		if w.hasAttestation {
+			entry.SignedAttestations = append(entry.SignedAttestations, &format.SignedAttestation{
+				SourceEpoch: fmt.Sprintf("%d", w.source),
+				TargetEpoch: fmt.Sprintf("%d", w.target),
+			})
+		}
+		minified[pubKey] = entry
+	}
+
+	result := &format.EIPSlashingProtectionFormat{Data: sortedProtectionData(minified)}
+	result.Metadata.InterchangeFormatVersion = format.InterchangeFormatVersion
+	result.Metadata.GenesisValidatorsRoot = interchangeJSON.Metadata.GenesisValidatorsRoot
+	return result, nil
+}
+
+// uniqueProposals removes duplicate proposals and sorts the remaining ones by slot.
+func uniqueProposals(proposals []kv.Proposal) []kv.Proposal {
+	seen := make(map[string]bool, len(proposals))
+	unique := make([]kv.Proposal, 0, len(proposals))
+	for _, proposal := range proposals {
+		key := fmt.Sprintf("%d-%#x", proposal.Slot, proposal.SigningRoot)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, proposal)
+	}
+	sort.SliceStable(unique, func(i, j int) bool {
+		return unique[i].Slot < unique[j].Slot
+	})
+	return unique
+}
+
+// uniqueAttestations removes duplicate attestations and sorts the remaining ones
+// by target, then source epoch.
+func uniqueAttestations(atts []*kv.AttestationRecord) []*kv.AttestationRecord {
+	type key struct {
+		source, target primitives.Epoch
+		signingRoot    [32]byte
+	}
+	seen := make(map[key]bool, len(atts))
+	unique := make([]*kv.AttestationRecord, 0, len(atts))
+	for _, att := range atts {
+		k := key{source: att.Source, target: att.Target, signingRoot: att.SigningRoot}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		unique = append(unique, att)
+	}
+	sort.SliceStable(unique, func(i, j int) bool {
+		if unique[i].Target == unique[j].Target {
+			return unique[i].Source < unique[j].Source
+		}
+		return unique[i].Target < unique[j].Target
+	})
+	return unique
+}
+
+func sortedProtectionData(dataByPubKey map[[dilithium2.CryptoPublicKeyBytes]byte]*format.ProtectionData) []*format.ProtectionData {
+	dataList := make([]*format.ProtectionData, 0, len(dataByPubKey))
+	for _, item := range dataByPubKey {
+		dataList = append(dataList, item)
+	}
+	sort.Slice(dataList, func(i, j int) bool {
+		return strings.Compare(dataList[i].Pubkey, dataList[j].Pubkey) < 0
+	})
+	return dataList
+}
+
+// optionalRootToHexString converts a signing root into its hex representation. Zero
+// roots, which stand for an unknown signing root, are omitted.
+func optionalRootToHexString(root []byte) string {
+	var zero [32]byte
+	if len(root) != 32 || string(root) == string(zero[:]) {
+		return ""
+	}
+	return fmt.Sprintf("%#x", root)
+}
+
+func nonNilBlocks(blocks []*format.SignedBlock) []*format.SignedBlock {
+	result := make([]*format.SignedBlock, 0, len(blocks))
+	for _, b := range blocks {
+		if b != nil {
+			result = append(result, b)
+		}
+	}
+	return result
+}
+
+func nonNilAttestations(atts []*format.SignedAttestation) []*format.SignedAttestation {
+	result := make([]*format.SignedAttestation, 0, len(atts))
+	for _, a := range atts {
+		if a != nil {
+			result = append(result, a)
+		}
+	}
+	return result
+}
@@ -0,0 +1,210 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	dilithium2 "github.com/theQRL/go-qrllib/dilithium"
+	"github.com/theQRL/qrysm/v4/testing/assert"
+	"github.com/theQRL/qrysm/v4/testing/require"
+	"github.com/theQRL/qrysm/v4/validator/slashing-protection-history/format"
+	valtest "github.com/theQRL/qrysm/v4/validator/testing"
+)
+
+func mockInterchangeJSON(gvr string, data ...*format.ProtectionData) *format.EIPSlashingProtectionFormat {
+	interchangeJSON := &format.EIPSlashingProtectionFormat{Data: data}
+	interchangeJSON.Metadata.InterchangeFormatVersion = format.InterchangeFormatVersion
+	interchangeJSON.Metadata.GenesisValidatorsRoot = gvr
+	return interchangeJSON
+}
+
+func TestValidateStandardProtectionJSON(t *testing.T) {
+	ctx := context.Background()
+	gvr := fmt.Sprintf("%#x", [32]byte{1})
+	pubKeys, err := valtest.CreateRandomPubKeys(1)
+	require.NoError(t, err)
+
+	require.NoError(t, ValidateStandardProtectionJSON(ctx, mockInterchangeJSON(gvr, &format.ProtectionData{
+		Pubkey:             fmt.Sprintf("%#x", pubKeys[0]),
+		SignedBlocks:       []*format.SignedBlock{{Slot: "1"}},
+		SignedAttestations: []*format.SignedAttestation{{SourceEpoch: "1", TargetEpoch: "2"}},
+	})))
+
+	err = ValidateStandardProtectionJSON(ctx, mockInterchangeJSON(gvr, &format.ProtectionData{
+		Pubkey: fmt.Sprintf("%#x", [48]byte{1}),
+	}))
+	require.ErrorContains(t, "is not a valid public key", err)
+
+	err = ValidateStandardProtectionJSON(ctx, mockInterchangeJSON(gvr, &format.ProtectionData{
+		Pubkey:             fmt.Sprintf("%#x", pubKeys[0]),
+		SignedAttestations: []*format.SignedAttestation{{SourceEpoch: "3", TargetEpoch: "2"}},
+	}))
+	require.ErrorContains(t, "greater than target epoch", err)
+
+	unsupported := mockInterchangeJSON(gvr)
+	unsupported.Metadata.InterchangeFormatVersion = "4"
+	err = ValidateStandardProtectionJSON(ctx, unsupported)
+	require.ErrorContains(t, "is not supported", err)
+}
+
+func TestMergeStandardProtectionJSONs(t *testing.T) {
+	ctx := context.Background()
+	gvr := fmt.Sprintf("%#x", [32]byte{1})
+	pubKeys, err := valtest.CreateRandomPubKeys(3)
+	require.NoError(t, err)
+	root := func(b byte) string {
+		return fmt.Sprintf("%#x", [32]byte{b})
+	}
+
+	first := mockInterchangeJSON(gvr,
+		&format.ProtectionData{
+			Pubkey:             fmt.Sprintf("%#x", pubKeys[0]),
+			SignedBlocks:       []*format.SignedBlock{{Slot: "1", SigningRoot: root(1)}},
+			SignedAttestations: []*format.SignedAttestation{{SourceEpoch: "0", TargetEpoch: "1", SigningRoot: root(1)}},
+		},
+		&format.ProtectionData{
+			Pubkey: fmt.Sprintf("%#x", pubKeys[2]),
+		},
+	)
+	second := mockInterchangeJSON(gvr,
+		&format.ProtectionData{
+			Pubkey: fmt.Sprintf("%#x", pubKeys[0]),
+			SignedBlocks: []*format.SignedBlock{
+				{Slot: "1", SigningRoot: root(1)},
+				{Slot: "2", SigningRoot: root(2)},
+			},
+			SignedAttestations: []*format.SignedAttestation{
+				{SourceEpoch: "0", TargetEpoch: "1", SigningRoot: root(1)},
+				{SourceEpoch: "1", TargetEpoch: "2", SigningRoot: root(2)},
+			},
+		},
+		&format.ProtectionData{
+			Pubkey:       fmt.Sprintf("%#x", pubKeys[1]),
+			SignedBlocks: []*format.SignedBlock{{Slot: "5", SigningRoot: root(5)}},
+		},
+	)
+
+	merged, slashable, err := MergeStandardProtectionJSONs(ctx, first, second)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(slashable))
+	assert.Equal(t, format.InterchangeFormatVersion, merged.Metadata.InterchangeFormatVersion)
+	assert.Equal(t, gvr, merged.Metadata.GenesisValidatorsRoot)
+	require.Equal(t, 3, len(merged.Data))
+
+	byPubKey := make(map[string]*format.ProtectionData)
+	for _, item := range merged.Data {
+		byPubKey[item.Pubkey] = item
+	}
+	item := byPubKey[fmt.Sprintf("%#x", pubKeys[0])]
+	require.NotNil(t, item)
+	require.Equal(t, 2, len(item.SignedBlocks))
+	assert.Equal(t, "1", item.SignedBlocks[0].Slot)
+	assert.Equal(t, "2", item.SignedBlocks[1].Slot)
+	require.Equal(t, 2, len(item.SignedAttestations))
+	assert.Equal(t, "1", item.SignedAttestations[0].TargetEpoch)
+	assert.Equal(t, "2", item.SignedAttestations[1].TargetEpoch)
+
+	item = byPubKey[fmt.Sprintf("%#x", pubKeys[1])]
+	require.NotNil(t, item)
+	assert.Equal(t, 1, len(item.SignedBlocks))
+
+	item = byPubKey[fmt.Sprintf("%#x", pubKeys[2])]
+	require.NotNil(t, item)
+	assert.Equal(t, 0, len(item.SignedBlocks))
+	assert.Equal(t, 0, len(item.SignedAttestations))
+}
+
+func TestMergeStandardProtectionJSONs_SlashableHistory(t *testing.T) {
+	ctx := context.Background()
+	gvr := fmt.Sprintf("%#x", [32]byte{1})
+	pubKeys, err := valtest.CreateRandomPubKeys(2)
+	require.NoError(t, err)
+
+	first := mockInterchangeJSON(gvr,
+		&format.ProtectionData{
+			Pubkey:       fmt.Sprintf("%#x", pubKeys[0]),
+			SignedBlocks: []*format.SignedBlock{{Slot: "1", SigningRoot: fmt.Sprintf("%#x", [32]byte{1})}},
+		},
+		&format.ProtectionData{
+			Pubkey:             fmt.Sprintf("%#x", pubKeys[1]),
+			SignedAttestations: []*format.SignedAttestation{{SourceEpoch: "1", TargetEpoch: "2"}},
+		},
+	)
+	second := mockInterchangeJSON(gvr,
+		&format.ProtectionData{
+			Pubkey:       fmt.Sprintf("%#x", pubKeys[0]),
+			SignedBlocks: []*format.SignedBlock{{Slot: "1", SigningRoot: fmt.Sprintf("%#x", [32]byte{2})}},
+		},
+		&format.ProtectionData{
+			Pubkey:             fmt.Sprintf("%#x", pubKeys[1]),
+			SignedAttestations: []*format.SignedAttestation{{SourceEpoch: "0", TargetEpoch: "3"}},
+		},
+	)
+
+	_, slashable, err := MergeStandardProtectionJSONs(ctx, first, second)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(slashable))
+	found := make(map[[dilithium2.CryptoPublicKeyBytes]byte]bool)
+	for _, pubKey := range slashable {
+		found[pubKey] = true
+	}
+	assert.Equal(t, true, found[pubKeys[0]])
+	assert.Equal(t, true, found[pubKeys[1]])
+}
+
+func TestMergeStandardProtectionJSONs_DifferentGenesisValidatorsRoot(t *testing.T) {
+	ctx := context.Background()
+	first := mockInterchangeJSON(fmt.Sprintf("%#x", [32]byte{1}))
+	second := mockInterchangeJSON(fmt.Sprintf("%#x", [32]byte{2}))
+	_, _, err := MergeStandardProtectionJSONs(ctx, first, second)
+	require.ErrorContains(t, "Only histories of the same chain can be merged", err)
+}
+
+func TestMinifyStandardProtectionJSON(t *testing.T) {
+	ctx := context.Background()
+	gvr := fmt.Sprintf("%#x", [32]byte{1})
+	pubKeys, err := valtest.CreateRandomPubKeys(2)
+	require.NoError(t, err)
+
+	interchangeJSON := mockInterchangeJSON(gvr,
+		&format.ProtectionData{
+			Pubkey: fmt.Sprintf("%#x", pubKeys[0]),
+			SignedBlocks: []*format.SignedBlock{
+				{Slot: "3", SigningRoot: fmt.Sprintf("%#x", [32]byte{3})},
+				{Slot: "10", SigningRoot: fmt.Sprintf("%#x", [32]byte{10})},
+			},
+			SignedAttestations: []*format.SignedAttestation{
+				{SourceEpoch: "4", TargetEpoch: "5"},
+				{SourceEpoch: "2", TargetEpoch: "7"},
+			},
+		},
+		&format.ProtectionData{
+			Pubkey: fmt.Sprintf("%#x", pubKeys[1]),
+		},
+	)
+
+	minified, err := MinifyStandardProtectionJSON(ctx, interchangeJSON)
+	require.NoError(t, err)
+	assert.Equal(t, gvr, minified.Metadata.GenesisValidatorsRoot)
+	require.Equal(t, 2, len(minified.Data))
+
+	byPubKey := make(map[string]*format.ProtectionData)
+	for _, item := range minified.Data {
+		byPubKey[item.Pubkey] = item
+	}
+	item := byPubKey[fmt.Sprintf("%#x", pubKeys[0])]
+	require.NotNil(t, item)
+	require.Equal(t, 1, len(item.SignedBlocks))
+	assert.Equal(t, "10", item.SignedBlocks[0].Slot)
+	assert.Equal(t, "", item.SignedBlocks[0].SigningRoot)
+	require.Equal(t, 1, len(item.SignedAttestations))
+	assert.Equal(t, "4", item.SignedAttestations[0].SourceEpoch)
+	assert.Equal(t, "7", item.SignedAttestations[0].TargetEpoch)
+	assert.Equal(t, "", item.SignedAttestations[0].SigningRoot)
+
+	item = byPubKey[fmt.Sprintf("%#x", pubKeys[1])]
+	require.NotNil(t, item)
+	assert.Equal(t, 0, len(item.SignedBlocks))
+	assert.Equal(t, 0, len(item.SignedAttestations))
+}